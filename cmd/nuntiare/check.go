@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/preflight"
+	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/urfave/cli/v2"
+)
+
+// checkCommand validates the configuration and, with --deep, the external integrations
+var checkCommand = &cli.Command{
+	Name:  "check",
+	Usage: "Validate the configuration",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{Name: "deep", Usage: "Also verify the Telegram token, SMTP login, RPC network and CTN contract"},
+	},
+	Action: checkConfig,
+}
+
+func checkConfig(c *cli.Context) error {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %v", err)
+	}
+	fmt.Println("ok   config")
+	if !c.Bool("deep") {
+		return nil
+	}
+
+	log, err := logger.NewLogger(cfg.Development)
+	if err != nil {
+		return fmt.Errorf("failed to initialize logger: %v", err)
+	}
+
+	failed := 0
+	results := preflight.Run(cfg, log)
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Printf("FAIL %s: %v\n", result.Name, result.Err)
+		case result.Skipped:
+			fmt.Printf("skip %s: %s\n", result.Name, result.Detail)
+		default:
+			fmt.Printf("ok   %s: %s\n", result.Name, result.Detail)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// runPreflight verifies the external integrations on startup. Misconfigurations stop the service, integrations
+// that can't be reached are only logged since they are retried once running.
+func runPreflight(cfg *config.Config, log *logger.Logger) error {
+	var misconfigured []string
+	for _, result := range preflight.Run(cfg, log) {
+		switch {
+		case result.Err == nil && result.Skipped:
+			log.Debug("Preflight check skipped", "check", result.Name, "reason", result.Detail)
+		case result.Err == nil:
+			log.Info("Preflight check passed", "check", result.Name, "detail", result.Detail)
+		case errors.Is(result.Err, preflight.ErrUnreachable):
+			log.Warn("Preflight check could not reach the integration", "check", result.Name, "error", result.Err)
+		default:
+			log.Error("Preflight check failed", "check", result.Name, "error", result.Err)
+			misconfigured = append(misconfigured, fmt.Sprintf("%s: %v", result.Name, result.Err))
+		}
+	}
+
+	if len(misconfigured) > 0 {
+		return fmt.Errorf("preflight checks failed (set PREFLIGHT_CHECKS=false to skip them):\n%s", strings.Join(misconfigured, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/blockchain/fixtures"
+	"github.com/urfave/cli/v2"
+)
+
+// fixturesCommand records and verifies transfer detection fixtures
+var fixturesCommand = &cli.Command{
+	Name:  "fixtures",
+	Usage: "Record and verify transfer detection fixtures",
+	Subcommands: []*cli.Command{
+		{
+			Name:  "record",
+			Usage: "Record a fixture from a live transaction",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "tx", Usage: "Transaction hash", Required: true},
+				&cli.StringFlag{Name: "token-address", Usage: "Token contract address", Required: true},
+				&cli.StringFlag{Name: "token-symbol", Usage: "Token symbol", Required: true},
+				&cli.StringFlag{Name: "token-type", Usage: "Token type (CBC20 or CBC721)", Value: "CBC20"},
+				&cli.IntFlag{Name: "token-decimals", Usage: "Token decimals", Value: 18},
+				&cli.StringFlag{Name: "name", Usage: "Fixture name (used as file name)"},
+				&cli.StringFlag{Name: "description", Usage: "What edge case the fixture covers"},
+				&cli.StringFlag{Name: "dir", Usage: "Fixture directory", Value: fixtures.DefaultDir},
+				&cli.StringFlag{Name: "blockchain-service-url", Aliases: []string{"b"}, Usage: "Blockchain service URL", Value: "http://localhost:8545", EnvVars: []string{"BLOCKCHAIN_SERVICE_URL"}},
+				&cli.Int64Flag{Name: "network-id", Aliases: []string{"n"}, Usage: "Network ID", Value: 1, EnvVars: []string{"NETWORK_ID"}},
+			},
+			Action: recordFixture,
+		},
+		{
+			Name:      "verify",
+			Usage:     "Check that detection still produces the expected transfers for all fixtures",
+			ArgsUsage: "[dir]",
+			Action:    verifyFixtures,
+		},
+	},
+}
+
+func recordFixture(c *cli.Context) error {
+	token := fixtures.Token{
+		Address:  c.String("token-address"),
+		Symbol:   c.String("token-symbol"),
+		Type:     strings.ToUpper(c.String("token-type")),
+		Decimals: c.Int("token-decimals"),
+	}
+
+	fixture, err := fixtures.Record(c.String("blockchain-service-url"), c.String("tx"), c.Int64("network-id"), token)
+	if err != nil {
+		return err
+	}
+	if c.IsSet("name") {
+		fixture.Name = c.String("name")
+	}
+	fixture.Description = c.String("description")
+
+	path := filepath.Join(c.String("dir"), fixture.Name+".json")
+	if err := fixture.Save(path); err != nil {
+		return err
+	}
+
+	fmt.Printf("Recorded %s with %d expected transfers, review them before committing\n", path, len(fixture.Expected))
+	return nil
+}
+
+func verifyFixtures(c *cli.Context) error {
+	dir := fixtures.DefaultDir
+	if c.Args().Present() {
+		dir = c.Args().First()
+	}
+
+	loaded, err := fixtures.LoadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, fixture := range loaded {
+		if err := fixture.Verify(); err != nil {
+			failed++
+			fmt.Printf("FAIL %s: %v\n", fixture.Name, err)
+			continue
+		}
+		fmt.Printf("ok   %s\n", fixture.Name)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d fixtures failed", failed, len(loaded))
+	}
+	fmt.Printf("All %d fixtures passed\n", len(loaded))
+	return nil
+}
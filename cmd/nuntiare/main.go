@@ -7,14 +7,17 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/core-coin/nuntiare/internal/blockchain"
 	"github.com/core-coin/nuntiare/internal/config"
 	"github.com/core-coin/nuntiare/internal/http_api"
+	"github.com/core-coin/nuntiare/internal/models"
 	"github.com/core-coin/nuntiare/internal/notificator"
 	"github.com/core-coin/nuntiare/internal/nuntiare"
 	"github.com/core-coin/nuntiare/internal/repository"
 	"github.com/core-coin/nuntiare/internal/wellknown"
+	"github.com/core-coin/nuntiare/pkg/faults"
 	"github.com/core-coin/nuntiare/pkg/logger"
 	"github.com/urfave/cli/v2"
 )
@@ -50,6 +53,10 @@ func main() {
 		Action: func(c *cli.Context) error {
 			return run(c)
 		},
+		Commands: []*cli.Command{
+			fixturesCommand,
+			checkCommand,
+		},
 	}
 
 	err := app.Run(os.Args)
@@ -125,40 +132,122 @@ func run(c *cli.Context) error {
 		return fmt.Errorf("failed to initialize logger: %v", err)
 	}
 
+	// A shadow instance must not take over Telegram updates from production
+	if cfg.ShadowMode {
+		cfg.TelegramBotToken = ""
+		cfg.TelegramWebhookURL = ""
+	}
+
+	// Fail fast on integrations that reject the configuration
+	if cfg.PreflightChecks {
+		if err := runPreflight(cfg, log); err != nil {
+			return err
+		}
+	}
+
 	// Initialize database
 	db, err := repository.NewPostgresDB(cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresDB, cfg.PostgresHost, cfg.PostgresPort, log)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %v", err)
 	}
 
+	if cfg.WalletCacheSize > 0 {
+		if pg, ok := db.(*repository.PostgresDB); ok {
+			pg.EnableWalletCache(cfg.WalletCacheSize, time.Duration(cfg.WalletCacheTTLSeconds)*time.Second)
+			log.Info("Wallet cache enabled", "size", cfg.WalletCacheSize, "ttl_seconds", cfg.WalletCacheTTLSeconds)
+		}
+	}
+
+	// Development only: inject dependency failures to exercise retries, fallbacks and alerts
+	faultInjector, err := faults.NewInjector(cfg.FaultInjection)
+	if err != nil {
+		return fmt.Errorf("invalid fault injection config: %v", err)
+	}
+	if faultInjector != nil {
+		log.Warn("Fault injection enabled", "faults", cfg.FaultInjection)
+		if pg, ok := db.(*repository.PostgresDB); ok {
+			if err := pg.InjectFaults(faultInjector); err != nil {
+				return fmt.Errorf("failed to enable database fault injection: %v", err)
+			}
+		}
+	}
+
 	// Initialize well-known service to fetch and update token list
 	wellKnownService := wellknown.NewWellKnownService(log, cfg)
 	log.Info("Starting well-known token service for periodic updates")
 	wellKnownService.StartPeriodicUpdate()
 
 	// Initialize blockchain service (connection will be established in background)
-	blockchainService := blockchain.NewGocore(cfg.BlockchainServiceURL, log, cfg)
+	var blockchainService models.BlockchainService = blockchain.NewGocore(cfg.BlockchainServiceURL, log, cfg)
+	if faultInjector != nil {
+		blockchainService = blockchain.NewFaultInjectingService(blockchainService, faultInjector)
+	}
 
 	// Initialize notificators
 	webhookMode := cfg.TelegramWebhookURL != ""
 	telegramNotificator := notificator.NewTelegramNotificator(log, cfg.TelegramBotToken, db, webhookMode)
 
-	// Set webhook if URL is configured
+	// Set webhook if URL is configured, fall back to polling if it can't be set
 	if webhookMode && telegramNotificator != nil {
-		if err := telegramNotificator.SetWebhook(cfg.TelegramWebhookURL); err != nil {
-			log.Error("Failed to set Telegram webhook", "error", err)
+		if err := telegramNotificator.SetWebhook(cfg.TelegramWebhookURL, cfg.TelegramWebhookSecret); err != nil {
+			log.Error("Failed to set Telegram webhook, falling back to polling", "error", err)
+			telegramNotificator.StartPolling()
 		} else {
 			log.Info("Telegram webhook configured successfully", "url", cfg.TelegramWebhookURL)
 		}
 	}
 
 	emailNotificator := notificator.NewEmailNotificator(log, cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPAlternativePort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPSender, db)
-	notificatorService := notificator.NewNotificator(log, db, telegramNotificator, emailNotificator)
+	emailNotificator.SetSMTPPoolSize(cfg.SMTPPoolSize)
+	emailAPISender, err := notificator.NewEmailAPISender(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize email provider: %v", err)
+	}
+	if emailAPISender != nil {
+		emailNotificator.SetAPISender(emailAPISender)
+		log.Info("Email notifications will be sent via provider API", "provider", cfg.EmailProvider)
+	}
+	dkimSigner, err := notificator.NewDKIMSigner(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize DKIM signing: %v", err)
+	}
+	if dkimSigner != nil {
+		emailNotificator.SetDKIMSigner(dkimSigner)
+		log.Info("Emails will be DKIM signed", "domain", cfg.DKIMSigningDomain(), "selector", cfg.DKIMSelector)
+	}
+	if len(cfg.EmailRoutes) > 0 {
+		if err := emailNotificator.SetRoutes(cfg); err != nil {
+			return fmt.Errorf("failed to initialize email routes: %v", err)
+		}
+		log.Info("Emails of matching recipients will be routed", "routes", len(cfg.EmailRoutes))
+	}
+	if faultInjector != nil {
+		telegramNotificator.SetFaultInjector(faultInjector)
+		emailNotificator.SetFaultInjector(faultInjector)
+	}
+	fcmNotificator, err := notificator.NewFCMNotificator(log, cfg.FCMCredentialsFile, db)
+	if err != nil {
+		return fmt.Errorf("failed to initialize FCM: %v", err)
+	}
+	smsSender, err := notificator.NewSMSSender(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize SMS provider: %v", err)
+	}
+	var smsNotificator *notificator.SMSNotificator
+	if smsSender != nil {
+		smsNotificator = notificator.NewSMSNotificator(log, smsSender, db, cfg.SMSMaxPerWalletPerHour, cfg.SMSMaxPerHour)
+		log.Info("SMS notifications will be sent via provider API", "provider", cfg.SMSProvider)
+	}
+	webPushNotificator, err := notificator.NewWebPushNotificator(log, cfg, db)
+	if err != nil {
+		return fmt.Errorf("failed to initialize Web Push: %v", err)
+	}
+	notificatorService := notificator.NewNotificator(log, cfg, db, telegramNotificator, emailNotificator, fcmNotificator, smsNotificator, webPushNotificator)
 	// Initialize API server
 	// Create Nuntiare instance
-	nuntiareApp := nuntiare.NewNuntiare(db, blockchainService, notificatorService, wellKnownService, log, cfg)
+	nuntiareApp := nuntiare.NewNuntiare(db, blockchainService, notificatorService, wellKnownService, telegramNotificator, log, cfg)
 
-	apiServer := http_api.NewHTTPServer(nuntiareApp, cfg.APIPort, log)
+	apiServer := http_api.NewHTTPServer(nuntiareApp, cfg, log)
 
 	// Setup signal handling for graceful shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -187,6 +276,12 @@ func run(c *cli.Context) error {
 	// Stop the Nuntiare instance (this will cancel context and wait for goroutines)
 	nuntiareApp.Stop()
 
+	// Stop the Telegram bot and its send queues
+	telegramNotificator.Stop()
+
+	// Close the pooled SMTP connections
+	emailNotificator.Close()
+
 	// Close blockchain service connection
 	if err := blockchainService.Close(); err != nil {
 		log.Error("Error closing blockchain service", "error", err)
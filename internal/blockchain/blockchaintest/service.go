@@ -0,0 +1,306 @@
+// Package blockchaintest provides a scriptable in-memory models.BlockchainService, so the
+// block processing pipeline can be exercised deterministically without a Core node.
+package blockchaintest
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-coin/go-core/v2"
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/go-core/v2/event"
+	"github.com/core-coin/go-core/v2/trie"
+
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+var _ models.BlockchainService = (*Service)(nil)
+
+// Service is a fake BlockchainService. Blocks, receipts, call traces, CTN balances and token URIs are
+// added by the caller, and headers are only emitted to subscribers when the caller says so.
+type Service struct {
+	mu        sync.Mutex
+	head      uint64
+	blocks    map[uint64]*types.Block
+	receipts  map[string]*types.Receipt // Transaction hash -> receipt
+	balances  map[string]*big.Int       // Normalized address -> CTN balance
+	tokenURIs map[string]string         // Normalized token address/token ID -> URI
+	views     map[string]*big.Int       // Normalized contract address/method -> uint256 view result
+	failures  map[chan error]struct{}   // Error channels of the active subscriptions
+
+	traces map[string]*models.CallFrame // Transaction hash -> call trace
+
+	feed event.Feed
+
+	pending     map[common.Hash]*types.Transaction // Transactions announced as pending and not mined yet
+	pendingFeed event.Feed
+
+	// RunErr and SubscribeErr make Run and NewHeaderSubscription fail while set
+	RunErr       error
+	SubscribeErr error
+}
+
+// New creates an empty fake blockchain whose next block is number 1
+func New() *Service {
+	return &Service{
+		blocks:    make(map[uint64]*types.Block),
+		pending:   make(map[common.Hash]*types.Transaction),
+		receipts:  make(map[string]*types.Receipt),
+		balances:  make(map[string]*big.Int),
+		tokenURIs: make(map[string]string),
+		views:     make(map[string]*big.Int),
+		failures:  make(map[chan error]struct{}),
+		traces:    make(map[string]*models.CallFrame),
+	}
+}
+
+// AddBlock appends a block with the transactions to the chain without announcing it.
+// Receipts are optional; receipts without a transaction hash get the hash of the transaction at the same index.
+func (s *Service) AddBlock(txs []*types.Transaction, receipts []*types.Receipt) *types.Block {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.head++
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(s.head),
+		Time:   uint64(time.Now().Unix()),
+	}
+	if parent, ok := s.blocks[s.head-1]; ok {
+		header.ParentHash = parent.Hash()
+	}
+
+	for i, receipt := range receipts {
+		if (receipt.TxHash == [32]byte{}) && i < len(txs) {
+			receipt.TxHash = txs[i].Hash()
+		}
+		s.receipts[receipt.TxHash.Hex()] = receipt
+	}
+
+	block := types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil))
+	s.blocks[s.head] = block
+	for _, tx := range txs {
+		delete(s.pending, tx.Hash())
+	}
+	return block
+}
+
+// EmitPending adds the transaction to the pool of pending transactions and announces its hash to all
+// pending transaction subscribers. Returns the number of subscribers the hash was delivered to.
+func (s *Service) EmitPending(tx *types.Transaction) int {
+	s.mu.Lock()
+	s.pending[tx.Hash()] = tx
+	s.mu.Unlock()
+	return s.pendingFeed.Send(tx.Hash())
+}
+
+// EmitBlock appends a block and announces its header to all subscribers
+func (s *Service) EmitBlock(txs []*types.Transaction, receipts []*types.Receipt) *types.Block {
+	block := s.AddBlock(txs, receipts)
+	s.Emit(block.Header())
+	return block
+}
+
+// Emit announces a header to all subscribers, blocking until each of them received it.
+// Returns the number of subscribers the header was delivered to.
+func (s *Service) Emit(header *types.Header) int {
+	return s.feed.Send(header)
+}
+
+// FailSubscriptions reports the error on all active subscriptions, as if the node connection dropped
+func (s *Service) FailSubscriptions(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for failure := range s.failures {
+		select {
+		case failure <- err:
+		default:
+		}
+	}
+}
+
+// SetReceipt stores the receipt returned for its transaction hash
+func (s *Service) SetReceipt(receipt *types.Receipt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[receipt.TxHash.Hex()] = receipt
+}
+
+// SetCTNBalance sets the CTN balance returned for the address
+func (s *Service) SetCTNBalance(address string, balance *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balances[validation.NormalizeAddress(address)] = new(big.Int).Set(balance)
+}
+
+// SetTokenURI sets the token URI returned for a CBC721 token
+func (s *Service) SetTokenURI(tokenAddress string, tokenID *big.Int, uri string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokenURIs[tokenURIKey(tokenAddress, tokenID)] = uri
+}
+
+// SetUint256 sets the value returned by a uint256 view function of a contract
+func (s *Service) SetUint256(contractAddress, method string, value *big.Int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.views[validation.NormalizeAddress(contractAddress)+"/"+method] = new(big.Int).Set(value)
+}
+
+// Head returns the number of the latest block
+func (s *Service) Head() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.head
+}
+
+func (s *Service) Run() error {
+	return s.RunErr
+}
+
+func (s *Service) NewHeaderSubscription() (core.Subscription, <-chan *types.Header, error) {
+	if s.SubscribeErr != nil {
+		return nil, nil, s.SubscribeErr
+	}
+
+	headers := make(chan *types.Header, blockchain.BlockHeaderChannelBuffer)
+	failure := make(chan error, 1)
+	s.mu.Lock()
+	s.failures[failure] = struct{}{}
+	s.mu.Unlock()
+
+	feedSub := s.feed.Subscribe(headers)
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		defer func() {
+			feedSub.Unsubscribe()
+			s.mu.Lock()
+			delete(s.failures, failure)
+			s.mu.Unlock()
+		}()
+
+		select {
+		case err := <-failure:
+			return err
+		case <-quit:
+			return nil
+		}
+	})
+	return sub, headers, nil
+}
+
+func (s *Service) NewPendingTransactionSubscription() (core.Subscription, <-chan common.Hash, error) {
+	if s.SubscribeErr != nil {
+		return nil, nil, s.SubscribeErr
+	}
+
+	hashes := make(chan common.Hash, blockchain.PendingTransactionChannelBuffer)
+	return s.pendingFeed.Subscribe(hashes), hashes, nil
+}
+
+func (s *Service) GetTransaction(txHash string) (*types.Transaction, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := common.HexToHash(txHash)
+	if tx, ok := s.pending[hash]; ok {
+		return tx, true, nil
+	}
+	for _, block := range s.blocks {
+		if tx := block.Transaction(hash); tx != nil {
+			return tx, false, nil
+		}
+	}
+	return nil, false, fmt.Errorf("transaction %s: %w", txHash, core.NotFound)
+}
+
+func (s *Service) GetBlockNumber() (uint64, error) {
+	return s.Head(), nil
+}
+
+func (s *Service) GetBlockByNumber(number uint64) (*types.Block, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	block, ok := s.blocks[number]
+	if !ok {
+		return nil, fmt.Errorf("block %d: %w", number, core.NotFound)
+	}
+	return block, nil
+}
+
+func (s *Service) GetAddressCTNBalance(address string) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if balance, ok := s.balances[validation.NormalizeAddress(address)]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return big.NewInt(0), nil
+}
+
+func (s *Service) GetTransactionReceipt(txHash string) (*types.Receipt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, receipt := range s.receipts {
+		if strings.EqualFold(hash, txHash) {
+			return receipt, nil
+		}
+	}
+	return nil, fmt.Errorf("receipt %s: %w", txHash, core.NotFound)
+}
+
+func (s *Service) GetCBC721TokenURI(tokenAddress string, tokenID *big.Int) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	uri, ok := s.tokenURIs[tokenURIKey(tokenAddress, tokenID)]
+	if !ok {
+		return "", fmt.Errorf("token URI %s/%s: %w", tokenAddress, tokenID, core.NotFound)
+	}
+	return uri, nil
+}
+
+func (s *Service) CallUint256(contractAddress, method string) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.views[validation.NormalizeAddress(contractAddress)+"/"+method]
+	if !ok {
+		return nil, fmt.Errorf("view %s/%s: %w", contractAddress, method, core.NotFound)
+	}
+	return new(big.Int).Set(value), nil
+}
+
+// SetTrace stores the call trace returned for the transaction when its block is traced
+func (s *Service) SetTrace(txHash string, trace *models.CallFrame) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traces[strings.ToLower(txHash)] = trace
+}
+
+// TraceBlockCalls returns the stored traces of the block's transactions, transactions without one have a nil trace
+func (s *Service) TraceBlockCalls(block *types.Block) ([]*models.CallFrame, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	traces := make([]*models.CallFrame, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		traces[i] = s.traces[strings.ToLower(tx.Hash().Hex())]
+	}
+	return traces, nil
+}
+
+func (s *Service) Close() error {
+	return nil
+}
+
+func tokenURIKey(tokenAddress string, tokenID *big.Int) string {
+	return validation.NormalizeAddress(tokenAddress) + "/" + tokenID.String()
+}
@@ -1,79 +1,47 @@
 package blockchain
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
+	"slices"
 	"strings"
 
+	"github.com/core-coin/go-core/v2/accounts/abi"
 	"github.com/core-coin/go-core/v2/common"
 	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/pkg/validation"
 )
 
 // CTNABI is the ABI of the Core Token contract (CBC20 standard)
 const CTNABI = `[{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"owner","type":"address"},{"indexed":true,"internalType":"address","name":"spender","type":"address"},{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],"name":"Approval","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"from","type":"address"},{"indexed":true,"internalType":"address","name":"to","type":"address"},{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"address","name":"spender","type":"address"}],"name":"allowance","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}],"name":"approve","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address[]","name":"recipients","type":"address[]"},{"internalType":"uint256[]","name":"amounts","type":"uint256[]"}],"name":"batchTransfer","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"subtractedValue","type":"uint256"}],"name":"decreaseAllowance","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"addedValue","type":"uint256"}],"name":"increaseAllowance","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"totalSupply","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"recipient","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}],"name":"transfer","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"sender","type":"address"},{"internalType":"address","name":"recipient","type":"address"},{"internalType":"uint256","name":"amount","type":"uint256"}],"name":"transferFrom","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
 
-// ABI encoding offsets and lengths (all values in hex characters, not bytes)
-// Standard Ethereum/Core ABI encoding uses 32-byte (64 hex char) slots
-const (
-	// Method selector is the first 4 bytes (8 hex chars) of the Keccak-256 hash of the function signature
-	methodSelectorLength = 8
+// ctnABI decodes the arguments of CBC20 transfer calls
+var ctnABI = mustParseABI(CTNABI)
 
-	// Standard ABI slot size: 32 bytes = 64 hex characters
-	abiSlotSize = 64
-
-	// Address encoding offsets
-	// Format: 4 bytes method selector + 12 bytes padding + 20 bytes address = 44 bytes = 88 hex chars per address
-	addressStartOffset = 28  // Skip method selector (8) + padding (20) = 28
-	addressEndOffset   = 72  // Start (28) + address (44) = 72
-
-	// Amount/value encoding offsets (second parameter slot)
-	amountStartOffset = 72  // After first address slot
-	amountEndOffset   = 136 // 72 + 64 = 136
-
-	// Count/third parameter offset (for batch transfers)
-	countStartOffset = 136 // After two slots (method + address + amount)
-	countEndOffset   = 200 // 136 + 64 = 200
-
-	// transferFrom "from" address offset (first parameter)
-	transferFromFromStart = 28 // Same as regular address
-	transferFromFromEnd   = 72
-
-	// transferFrom "to" address offset (second parameter)
-	transferFromToStart = 92  // After first slot + 20 padding
-	transferFromToEnd   = 136 // 92 + 44 = 136
-
-	// Minimum input lengths for validation
-	minTransferInputLength     = 136 // method(8) + to_address(64) + amount(64) = 136
-	minBatchTransferLength     = 200 // method(8) + recipients_offset(64) + amounts_offset(64) + count(64) = 200
-	minTransferFromInputLength = 200 // method(8) + from(64) + to(64) + amount(64) = 200
-)
+// maxBatchTransferRecipients limits the transfers decoded from a single batchTransfer call
+const maxBatchTransferRecipients = 1000
 
 // CBC721ABI is the ABI for CBC721 (ERC721) tokens
-const CBC721ABI = `[{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"from","type":"address"},{"indexed":true,"internalType":"address","name":"to","type":"address"},{"indexed":true,"internalType":"uint256","name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"}]`
+const CBC721ABI = `[{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"from","type":"address"},{"indexed":true,"internalType":"address","name":"to","type":"address"},{"indexed":true,"internalType":"uint256","name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"internalType":"uint256","name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
 
 // CBC721 Transfer event signature: keccak256("Transfer(address,address,uint256)")
 // Core blockchain uses: 0xc17a9d92b89f27cb79cc390f23a1a5d302fefab8c7911075ede952ac2b5607a1
 const cbc721TransferEventSignature = "c17a9d92b89f27cb79cc390f23a1a5d302fefab8c7911075ede952ac2b5607a1"
 
-const (
-	// transfer(address,uint256)
-	transfer = "4b40e901"
-	// batchTransfer(address[],uint256[])
-	batchTransfer = "e86e7c5f"
-	// transferFrom(address,address,uint256)
-	transferFrom = "31f2e679"
-)
-
 type Transfer struct {
-	From         string
-	To           string
-	Amount       float64
-	TokenAddress string // Contract address for the token
-	TokenSymbol  string // Token symbol (e.g., CTN, USDT)
-	TokenType    string // Token type (CBC20, CBC721)
-	TokenID      string // For CBC721 NFTs
-	TxHash       string // Transaction hash
-	NetworkID    int64  // Network ID (1 for mainnet, 3 for devnet)
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Amount       float64 `json:"amount"`
+	Value        string  `json:"value,omitempty"`       // Exact amount in the token's base units (decimal integer), Amount is rounded
+	TokenAddress string  `json:"token_address"`         // Contract address for the token
+	TokenSymbol  string  `json:"token_symbol"`          // Token symbol (e.g., CTN, USDT)
+	TokenType    string  `json:"token_type"`            // Token type (CBC20, CBC721)
+	TokenID      string  `json:"token_id,omitempty"`    // For CBC721 NFTs
+	TxHash       string  `json:"tx_hash"`               // Transaction hash
+	NetworkID    int64   `json:"network_id"`            // Network ID (1 for mainnet, 3 for devnet)
+	Timestamp    int64   `json:"timestamp,omitempty"`   // Timestamp of the block the transfer was mined in (0 while pending)
+	Designation  string  `json:"designation,omitempty"` // Wallet a subscription payment is made for, appended to the call data
 }
 
 // CheckForCTNTransfer checks if a transaction is a CTN transfer
@@ -82,171 +50,158 @@ func CheckForCTNTransfer(tx *types.Transaction, CTNAddress string, networkID int
 	return CheckForCBC20Transfer(tx, CTNAddress, "CTN", 18, networkID)
 }
 
+// TransactionSender recovers the sender address of a signed transaction.
+// The signer uses the transaction's own network ID so the signature check can't fail on a network mismatch.
+func TransactionSender(tx *types.Transaction) (string, error) {
+	signer := types.NewNucleusSigner(big.NewInt(int64(tx.NetworkID())))
+	sender, err := signer.Sender(tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get sender: %w", err)
+	}
+	return validation.NormalizeAddress(sender.Hex()), nil
+}
+
 // CheckForCBC20Transfer checks if a transaction is a CBC20 token transfer
 func CheckForCBC20Transfer(tx *types.Transaction, tokenAddress, tokenSymbol string, decimals int, networkID int64) ([]*Transfer, error) {
 	txHash := tx.Hash().String()
-	signer := types.NewNucleusSigner(big.NewInt(int64(common.DefaultNetworkID)))
 
 	receiver := tx.To().Hex()
-	sender, err := signer.Sender(tx)
+	sender, err := TransactionSender(tx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sender: %w", err)
+		return nil, err
 	}
-	input := common.Bytes2Hex(tx.Data())
-	if receiver != tokenAddress {
+	if validation.NormalizeAddress(receiver) != validation.NormalizeAddress(tokenAddress) {
 		return nil, nil
 	}
 
-	// Validate minimum input length for method selector
-	if len(input) < methodSelectorLength {
-		return nil, nil // Not enough data for method selector
+	method, args, err := decodeCall(tx.Data(), "transfer", "batchTransfer", "transferFrom")
+	if method == nil || err != nil {
+		return nil, err
 	}
 
 	// Calculate the divisor based on decimals
 	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
-
-	switch input[:methodSelectorLength] {
-	case transfer:
-		if len(input) < minTransferInputLength {
-			return nil, fmt.Errorf("invalid transfer input length: %d, expected at least %d", len(input), minTransferInputLength)
-		}
-		// Parse: transfer(address to, uint256 amount)
-		recipientAddr := input[addressStartOffset:addressEndOffset]
-		amountHex := input[amountStartOffset:amountEndOffset]
-		amount, _ := big.NewFloat(0).Quo(new(big.Float).SetInt(big.NewInt(0).SetBytes(common.Hex2Bytes(amountHex))), divisor).Float64()
-		return []*Transfer{
-			{
-				From:         sender.Hex(),
-				To:           recipientAddr,
-				Amount:       amount,
-				TokenAddress: tokenAddress,
-				TokenSymbol:  tokenSymbol,
-				TokenType:    "CBC20",
-				TxHash:       txHash,
-				NetworkID:    networkID,
-			},
-		}, nil
-	case batchTransfer:
-		if len(input) < minBatchTransferLength {
-			return nil, fmt.Errorf("invalid batchTransfer input length: %d, expected at least %d", len(input), minBatchTransferLength)
-		}
-		transfers := []*Transfer{}
-		offset := countStartOffset
-		count, ok := new(big.Int).SetString(input[countStartOffset:countEndOffset], 16)
-		if !ok {
-			return nil, fmt.Errorf("cannot convert batch transfer count to big.Int: %s", input[countStartOffset:countEndOffset])
+	newTransfer := func(from string, to common.Address, value *big.Int) *Transfer {
+		amount, _ := new(big.Float).Quo(new(big.Float).SetInt(value), divisor).Float64()
+		return &Transfer{
+			From:         from,
+			To:           validation.NormalizeAddress(to.Hex()),
+			Amount:       amount,
+			Value:        value.String(),
+			TokenAddress: tokenAddress,
+			TokenSymbol:  tokenSymbol,
+			TokenType:    "CBC20",
+			TxHash:       txHash,
+			NetworkID:    networkID,
 		}
+	}
 
-		// Validate count to prevent out-of-bounds access
-		countInt := int(count.Int64())
-		if countInt < 0 || countInt > 1000 {
-			return nil, fmt.Errorf("invalid batch transfer count: %d (must be between 0 and 1000)", countInt)
+	switch method.RawName {
+	case "transfer":
+		// transfer(address recipient, uint256 amount)
+		transfer := newTransfer(sender, args[0].(common.Address), args[1].(*big.Int))
+		transfer.Designation = designation(tx.Data(), method)
+		return []*Transfer{transfer}, nil
+	case "batchTransfer":
+		// batchTransfer(address[] recipients, uint256[] amounts)
+		recipients := args[0].([]common.Address)
+		amounts := args[1].([]*big.Int)
+		if len(recipients) != len(amounts) {
+			return nil, fmt.Errorf("invalid batchTransfer input: %d recipients but %d amounts", len(recipients), len(amounts))
 		}
-
-		// Validate that we have enough data for all transfers
-		requiredLength := offset + 192 + countInt*64 + countInt*64
-		if len(input) < requiredLength {
-			return nil, fmt.Errorf("insufficient data for batch transfer: got %d, need %d for %d transfers", len(input), requiredLength, countInt)
+		if len(recipients) > maxBatchTransferRecipients {
+			return nil, fmt.Errorf("invalid batch transfer count: %d (must be between 0 and %d)", len(recipients), maxBatchTransferRecipients)
 		}
-
-		for i := 0; i < countInt; i++ {
-			toStart := offset + 84 + i*64
-			toEnd := offset + 128 + i*64
-			valueStart := offset + 128 + countInt*64 + i*64
-			valueEnd := offset + 192 + countInt*64 + i*64
-
-			// Additional bounds check for safety
-			if toEnd > len(input) || valueEnd > len(input) {
-				return nil, fmt.Errorf("array index out of bounds in batch transfer at index %d", i)
-			}
-
-			to := input[toStart:toEnd]
-			value := input[valueStart:valueEnd]
-			amount, _ := big.NewFloat(0).Quo(new(big.Float).SetInt(big.NewInt(0).SetBytes(common.Hex2Bytes(value))), divisor).Float64()
-			transfers = append(transfers, &Transfer{
-				From:         sender.Hex(),
-				To:           to,
-				Amount:       amount,
-				TokenAddress: tokenAddress,
-				TokenSymbol:  tokenSymbol,
-				TokenType:    "CBC20",
-				TxHash:       txHash,
-				NetworkID:    networkID,
-			})
+		transfers := make([]*Transfer, 0, len(recipients))
+		for i, to := range recipients {
+			transfers = append(transfers, newTransfer(sender, to, amounts[i]))
 		}
 		return transfers, nil
-	case transferFrom:
-		if len(input) < minTransferFromInputLength {
-			return nil, fmt.Errorf("invalid transferFrom input length: %d, expected at least %d", len(input), minTransferFromInputLength)
-		}
-		// Parse: transferFrom(address from, address to, uint256 amount)
-		fromAddr := input[transferFromFromStart:transferFromFromEnd]
-		toAddr := input[transferFromToStart:transferFromToEnd]
-		amountHex := input[amountEndOffset:countEndOffset]
-		amount, _ := big.NewFloat(0).Quo(new(big.Float).SetInt(big.NewInt(0).SetBytes(common.Hex2Bytes(amountHex))), divisor).Float64()
-		return []*Transfer{
-			{
-				From:         fromAddr,
-				To:           toAddr,
-				Amount:       amount,
-				TokenAddress: tokenAddress,
-				TokenSymbol:  tokenSymbol,
-				TokenType:    "CBC20",
-				TxHash:       txHash,
-				NetworkID:    networkID,
-			},
-		}, nil
+	case "transferFrom":
+		// transferFrom(address sender, address recipient, uint256 amount)
+		transfer := newTransfer(validation.NormalizeAddress(args[0].(common.Address).Hex()), args[1].(common.Address), args[2].(*big.Int))
+		transfer.Designation = designation(tx.Data(), method)
+		return []*Transfer{transfer}, nil
 	}
 
 	return nil, nil
 }
 
+// designation returns the wallet address appended to the call data of a method with static arguments, either
+// as a 32-byte word like an address argument or as the address bytes alone. Payers sharing a subscription
+// address use it to designate the wallet a payment is made for. Returns "" without a designation.
+func designation(input []byte, method *abi.Method) string {
+	trailing := input[min(len(input), 4+32*len(method.Inputs)):]
+	switch {
+	case len(trailing) == common.AddressLength:
+		return validation.NormalizeAddress(common.BytesToAddress(trailing).Hex())
+	case len(trailing) >= 32:
+		word := trailing[:32]
+		if !bytes.Equal(word[:32-common.AddressLength], make([]byte, 32-common.AddressLength)) {
+			return ""
+		}
+		return validation.NormalizeAddress(common.BytesToAddress(word[32-common.AddressLength:]).Hex())
+	}
+	return ""
+}
+
 // CheckForCBC721Transfer checks if a transaction is a CBC721 (NFT) transfer
 // This function is kept for backward compatibility and for detecting transfers from input data
 // For proper event-based detection, use CheckForCBC721TransferFromReceipt instead
 func CheckForCBC721Transfer(tx *types.Transaction, tokenAddress, tokenSymbol string, networkID int64) ([]*Transfer, error) {
 	txHash := tx.Hash().String()
 	receiver := tx.To().Hex()
-	if receiver != tokenAddress {
+	if validation.NormalizeAddress(receiver) != validation.NormalizeAddress(tokenAddress) {
 		return nil, nil
 	}
 
-	// Parse input data for transferFrom calls
-	input := common.Bytes2Hex(tx.Data())
-
-	// Validate minimum input length for method selector
-	if len(input) < methodSelectorLength {
-		return nil, nil // Not enough data for method selector
+	// For CBC721, we look for transferFrom(address from, address to, uint256 tokenId), which has the
+	// same selector and argument types as the CBC20 transferFrom. safeTransferFrom isn't detected here.
+	method, args, err := decodeCall(tx.Data(), "transferFrom")
+	if method == nil || err != nil {
+		return nil, err
 	}
 
-	// For CBC721, we look for transferFrom or safeTransferFrom
-	// transferFrom(address from, address to, uint256 tokenId) = 0x31f2e679
-	// safeTransferFrom would have a different signature
-	switch input[:methodSelectorLength] {
-	case transferFrom:
-		if len(input) < minTransferFromInputLength {
-			return nil, fmt.Errorf("invalid CBC721 transferFrom input length: %d, expected at least %d", len(input), minTransferFromInputLength)
-		}
-		// For NFTs, the third parameter is tokenId (not amount)
-		fromAddr := input[transferFromFromStart:transferFromFromEnd]
-		toAddr := input[transferFromToStart:transferFromToEnd]
-		tokenID := input[amountEndOffset:countEndOffset] // TokenID is in the amount slot
-		return []*Transfer{
-			{
-				From:         fromAddr,
-				To:           toAddr,
-				Amount:       1, // NFTs are always 1 unit
-				TokenAddress: tokenAddress,
-				TokenSymbol:  tokenSymbol,
-				TokenType:    "CBC721",
-				TokenID:      tokenID,
-				TxHash:       txHash,
-				NetworkID:    networkID,
-			},
-		}, nil
+	// For NFTs, the third parameter is tokenId (not amount), reported as a 32-byte hex value
+	return []*Transfer{
+		{
+			From:         validation.NormalizeAddress(args[0].(common.Address).Hex()),
+			To:           validation.NormalizeAddress(args[1].(common.Address).Hex()),
+			Amount:       1, // NFTs are always 1 unit
+			TokenAddress: tokenAddress,
+			TokenSymbol:  tokenSymbol,
+			TokenType:    "CBC721",
+			TokenID:      fmt.Sprintf("%064x", args[2].(*big.Int)),
+			TxHash:       txHash,
+			NetworkID:    networkID,
+		},
+	}, nil
+}
+
+// decodeCall decodes the arguments of a call to one of the given token contract methods. Returns a nil
+// method for input that calls another method, and an error when the arguments can't be decoded.
+func decodeCall(input []byte, methods ...string) (*abi.Method, []interface{}, error) {
+	if len(input) < 4 {
+		return nil, nil, nil // Not enough data for method selector
+	}
+	method, err := ctnABI.MethodById(input[:4])
+	if err != nil || !slices.Contains(methods, method.RawName) {
+		return nil, nil, nil
 	}
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s input: %w", method.RawName, err)
+	}
+	return method, args, nil
+}
 
-	return nil, nil
+// mustParseABI parses a contract ABI defined in this package
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid contract ABI: %v", err))
+	}
+	return parsed
 }
 
 // CheckForCBC721TransferFromReceipt parses transaction receipt logs for CBC721 Transfer events
@@ -262,8 +217,8 @@ func CheckForCBC721TransferFromReceipt(receipt *types.Receipt, tokenAddress, tok
 	for _, log := range receipt.Logs {
 		// Check if log is from the token contract
 		// Compare by matching the raw address bytes (last N chars of token address)
-		logAddr := strings.TrimPrefix(strings.ToLower(log.Address.Hex()), "0x")
-		tokenAddr := strings.ToLower(tokenAddress)
+		logAddr := validation.NormalizeAddress(log.Address.Hex())
+		tokenAddr := validation.NormalizeAddress(tokenAddress)
 
 		// Compare raw address: if token address is longer, compare with its suffix
 		tokenAddrToCompare := tokenAddr
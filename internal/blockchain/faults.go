@@ -0,0 +1,100 @@
+package blockchain
+
+import (
+	"math/big"
+
+	"github.com/core-coin/go-core/v2"
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/core/types"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/faults"
+)
+
+// FaultInjectingService wraps a BlockchainService and injects RPC failures and latency (development only)
+type FaultInjectingService struct {
+	models.BlockchainService
+	faults *faults.Injector
+}
+
+// NewFaultInjectingService wraps the service with the injector's RPC faults
+func NewFaultInjectingService(service models.BlockchainService, injector *faults.Injector) *FaultInjectingService {
+	return &FaultInjectingService{BlockchainService: service, faults: injector}
+}
+
+func (f *FaultInjectingService) Run() error {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return err
+	}
+	return f.BlockchainService.Run()
+}
+
+func (f *FaultInjectingService) NewHeaderSubscription() (core.Subscription, <-chan *types.Header, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, nil, err
+	}
+	return f.BlockchainService.NewHeaderSubscription()
+}
+
+func (f *FaultInjectingService) NewPendingTransactionSubscription() (core.Subscription, <-chan common.Hash, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, nil, err
+	}
+	return f.BlockchainService.NewPendingTransactionSubscription()
+}
+
+func (f *FaultInjectingService) GetTransaction(txHash string) (*types.Transaction, bool, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, false, err
+	}
+	return f.BlockchainService.GetTransaction(txHash)
+}
+
+func (f *FaultInjectingService) GetBlockNumber() (uint64, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return 0, err
+	}
+	return f.BlockchainService.GetBlockNumber()
+}
+
+func (f *FaultInjectingService) GetBlockByNumber(number uint64) (*types.Block, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, err
+	}
+	return f.BlockchainService.GetBlockByNumber(number)
+}
+
+func (f *FaultInjectingService) GetAddressCTNBalance(address string) (*big.Int, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, err
+	}
+	return f.BlockchainService.GetAddressCTNBalance(address)
+}
+
+func (f *FaultInjectingService) GetTransactionReceipt(txHash string) (*types.Receipt, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, err
+	}
+	return f.BlockchainService.GetTransactionReceipt(txHash)
+}
+
+func (f *FaultInjectingService) GetCBC721TokenURI(tokenAddress string, tokenID *big.Int) (string, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return "", err
+	}
+	return f.BlockchainService.GetCBC721TokenURI(tokenAddress, tokenID)
+}
+
+func (f *FaultInjectingService) TraceBlockCalls(block *types.Block) ([]*models.CallFrame, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, err
+	}
+	return f.BlockchainService.TraceBlockCalls(block)
+}
+
+func (f *FaultInjectingService) CallUint256(contractAddress, method string) (*big.Int, error) {
+	if err := f.faults.Inject(faults.RPC); err != nil {
+		return nil, err
+	}
+	return f.BlockchainService.CallUint256(contractAddress, method)
+}
@@ -0,0 +1,171 @@
+// Package fixtures loads recorded transactions and receipts from JSON files and checks
+// that transfer detection still produces the expected transfers.
+// Used as regression fixtures for calldata and event parsing edge cases.
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/go-core/v2/xcbclient"
+	"github.com/core-coin/nuntiare/internal/blockchain"
+)
+
+// DefaultDir is where fixtures are stored in the repository
+const DefaultDir = "internal/blockchain/fixtures/testdata"
+
+// Token describes the watched token the transaction is checked against
+type Token struct {
+	Address  string `json:"address"`
+	Symbol   string `json:"symbol"`
+	Type     string `json:"type"` // CBC20 or CBC721
+	Decimals int    `json:"decimals"`
+}
+
+// Fixture is a recorded transaction with the transfers detection is expected to produce
+type Fixture struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	NetworkID   int64                  `json:"network_id"`
+	Token       Token                  `json:"token"`
+	Transaction *types.Transaction     `json:"transaction"`
+	Receipt     *types.Receipt         `json:"receipt,omitempty"` // Required for CBC721
+	Expected    []*blockchain.Transfer `json:"expected"`
+}
+
+// Load reads a fixture from a JSON file
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("failed to decode fixture %s: %w", path, err)
+	}
+	if fixture.Transaction == nil {
+		return nil, fmt.Errorf("fixture %s has no transaction", path)
+	}
+	if fixture.Name == "" {
+		fixture.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return &fixture, nil
+}
+
+// LoadDir reads all *.json fixtures in a directory, sorted by file name
+func LoadDir(dir string) ([]*Fixture, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list fixtures: %w", err)
+	}
+	sort.Strings(paths)
+
+	fixtures := make([]*Fixture, 0, len(paths))
+	for _, path := range paths {
+		fixture, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// Save writes the fixture to a JSON file
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// Detect runs the detection rule for the fixture's token type, the same way block processing does.
+// It sets the default network ID of go-core, which controls address formatting and signature checks.
+func (f *Fixture) Detect() ([]*blockchain.Transfer, error) {
+	common.DefaultNetworkID = common.NetworkID(f.NetworkID)
+
+	switch f.Token.Type {
+	case "CBC20":
+		return blockchain.CheckForCBC20Transfer(f.Transaction, f.Token.Address, f.Token.Symbol, f.Token.Decimals, f.NetworkID)
+	case "CBC721":
+		if f.Receipt == nil {
+			return nil, fmt.Errorf("CBC721 fixture requires a receipt")
+		}
+		return blockchain.CheckForCBC721TransferFromReceipt(f.Receipt, f.Token.Address, f.Token.Symbol, f.Transaction.Hash().String(), f.NetworkID)
+	}
+	return nil, fmt.Errorf("unsupported token type: %s", f.Token.Type)
+}
+
+// Verify checks that detection produces exactly the expected transfers
+func (f *Fixture) Verify() error {
+	transfers, err := f.Detect()
+	if err != nil {
+		return fmt.Errorf("detection failed: %w", err)
+	}
+
+	if len(transfers) != len(f.Expected) {
+		return fmt.Errorf("expected %d transfers, got %d", len(f.Expected), len(transfers))
+	}
+	for i, expected := range f.Expected {
+		if !reflect.DeepEqual(expected, transfers[i]) {
+			want, _ := json.Marshal(expected)
+			got, _ := json.Marshal(transfers[i])
+			return fmt.Errorf("transfer %d mismatch:\n  want %s\n  got  %s", i, want, got)
+		}
+	}
+	return nil
+}
+
+// Record fetches a transaction and its receipt from a Core RPC endpoint and builds a fixture.
+// Expected transfers are filled with the current detection output and should be reviewed before committing.
+func Record(rpcURL, txHash string, networkID int64, token Token) (*Fixture, error) {
+	client, err := xcbclient.Dial(rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	hash := common.HexToHash(txHash)
+	tx, pending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	if pending {
+		return nil, fmt.Errorf("transaction %s is still pending", txHash)
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+
+	fixture := &Fixture{
+		Name:        txHash,
+		NetworkID:   networkID,
+		Token:       token,
+		Transaction: tx,
+		Receipt:     receipt,
+	}
+	fixture.Expected, err = fixture.Detect()
+	if err != nil {
+		return nil, fmt.Errorf("detection failed: %w", err)
+	}
+	return fixture, nil
+}
@@ -0,0 +1,74 @@
+package fixtures
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestFixtures verifies the recorded fixtures like "nuntiare fixtures verify". Detection sets the default
+// network ID of go-core, so fixtures aren't verified in parallel.
+func TestFixtures(t *testing.T) {
+	loaded, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	if len(loaded) == 0 {
+		t.Fatal("no fixtures in testdata")
+	}
+
+	for _, fixture := range loaded {
+		t.Run(fixture.Name, func(t *testing.T) {
+			if len(fixture.Expected) == 0 {
+				t.Fatal("fixture expects no transfers")
+			}
+			if err := fixture.Verify(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestVerifyDetectsMismatch(t *testing.T) {
+	loaded, err := LoadDir("testdata")
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+
+	for _, fixture := range loaded {
+		t.Run(fixture.Name, func(t *testing.T) {
+			expected := *fixture.Expected[0]
+			expected.To = expected.From
+			fixture.Expected[0] = &expected
+			if err := fixture.Verify(); err == nil {
+				t.Fatal("Verify accepted a transfer to the wrong address")
+			}
+
+			fixture.Expected = fixture.Expected[1:]
+			if err := fixture.Verify(); err == nil {
+				t.Fatal("Verify accepted a missing transfer")
+			}
+		})
+	}
+}
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	fixture, err := Load(filepath.Join("testdata", "cbc20_transfer.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := fixture.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load saved fixture: %v", err)
+	}
+	if saved.Name != fixture.Name || saved.Transaction.Hash() != fixture.Transaction.Hash() {
+		t.Fatalf("saved fixture %s (tx %s), want %s (tx %s)", saved.Name, saved.Transaction.Hash(), fixture.Name, fixture.Transaction.Hash())
+	}
+	if err := saved.Verify(); err != nil {
+		t.Fatalf("Verify saved fixture: %v", err)
+	}
+}
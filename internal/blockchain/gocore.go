@@ -2,10 +2,13 @@ package blockchain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/core-coin/go-core/v2"
@@ -13,8 +16,11 @@ import (
 	"github.com/core-coin/go-core/v2/accounts/abi/bind"
 	"github.com/core-coin/go-core/v2/common"
 	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/go-core/v2/rpc"
 	"github.com/core-coin/go-core/v2/xcbclient"
 	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/discovery"
 	"github.com/core-coin/nuntiare/pkg/logger"
 )
 
@@ -22,18 +28,78 @@ const (
 	// BlockHeaderChannelBuffer is the buffer size for the block header channel
 	// Sized to handle ~1.5 minute of blocks assuming ~7s block time
 	BlockHeaderChannelBuffer = 15
+
+	// PendingTransactionChannelBuffer is the buffer size for the pending transaction hash channel
+	PendingTransactionChannelBuffer = 256
+
+	// RPCHealthCheckTimeout bounds resolving the RPC endpoints and connecting to and checking a single endpoint
+	RPCHealthCheckTimeout = 10 * time.Second
+
+	// TraceBlockTimeout bounds tracing a block, which re-executes all its transactions.
+	// Kept below the 30 second block processing lock.
+	TraceBlockTimeout = 20 * time.Second
 )
 
+var errNotConnected = errors.New("not connected to the core RPC server")
+
 type Gocore struct {
-	logger       *logger.Logger
-	config       *config.Config
-	apiURL       string
-	client       *xcbclient.Client
+	logger *logger.Logger
+	config *config.Config
+	apiURL string
+
+	conn      atomic.Pointer[rpcConnection] // Active connection, replaced on reconnects and failovers
+	connectMu sync.Mutex                    // Serializes connecting, so concurrent failed requests fail over once
 
-	mu           sync.RWMutex
-	subscription core.Subscription
+	mu                  sync.RWMutex
+	subscription        core.Subscription
+	pendingSubscription core.Subscription
 
-	ctnContract *bind.BoundContract
+	ctnAddress common.Address
+	ctnABI     *abi.ABI
+}
+
+// rpcConnection is a connection to one RPC endpoint. Requests register on the connection while they use it,
+// a connection replaced by another one is closed once its last request returned.
+type rpcConnection struct {
+	endpoint  string
+	client    *xcbclient.Client
+	rpcClient *rpc.Client // Raw client of the xcbclient, for subscriptions and calls xcbclient doesn't provide
+
+	mu      sync.Mutex
+	calls   int
+	retired bool
+}
+
+// acquire registers a request on the connection, false if the connection has been replaced
+func (c *rpcConnection) acquire() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.retired {
+		return false
+	}
+	c.calls++
+	return true
+}
+
+// release unregisters a request, closing the connection if it was the last request of a replaced connection
+func (c *rpcConnection) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls--
+	if c.retired && c.calls == 0 {
+		c.client.Close()
+	}
+}
+
+// retire marks the connection as replaced and closes it once no request uses it. Subscriptions on the
+// connection end when it is closed.
+func (c *rpcConnection) retire() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retired = true
+	if c.calls == 0 {
+		c.client.Close()
+	}
 }
 
 // NewGocore creates a new Gocore instance.
@@ -53,15 +119,122 @@ func (g *Gocore) Run() error {
 	return nil
 }
 
+// ConnectToRPC connects to the first healthy RPC endpoint. The API URL is a comma-separated list of URLs or
+// "srv+" SRV URLs, resolved again on every connect so reconnects follow service discovery changes.
+// An endpoint is healthy when it answers a block number request. The previous connection is closed once
+// the requests using it returned.
 func (g *Gocore) ConnectToRPC() error {
-	client, err := xcbclient.Dial(g.apiURL)
+	g.connectMu.Lock()
+	defer g.connectMu.Unlock()
+	return g.connect("")
+}
+
+// connect replaces the active connection by a connection to the first healthy endpoint, trying the failed
+// endpoint last. Must be called with connectMu held.
+func (g *Gocore) connect(failedEndpoint string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), RPCHealthCheckTimeout)
+	urls, err := discovery.ResolveURLs(ctx, g.apiURL)
+	cancel()
 	if err != nil {
-		return fmt.Errorf("failed to connect to the core RPC server: %w", err)
+		return fmt.Errorf("failed to resolve the core RPC endpoints: %w", err)
 	}
-	g.client = client
-	return nil
+	if i := slices.Index(urls, failedEndpoint); i >= 0 {
+		urls = append(slices.Delete(urls, i, i+1), failedEndpoint)
+	}
+
+	for _, url := range urls {
+		rpcClient, err := g.dialHealthy(url)
+		if err != nil {
+			g.logger.Warn("Core RPC endpoint unavailable, trying the next one", "endpoint", url, "error", err)
+			continue
+		}
+		g.logger.Info("Selected core RPC endpoint", "endpoint", url, "candidates", len(urls))
+		conn := &rpcConnection{endpoint: url, client: xcbclient.NewClient(rpcClient), rpcClient: rpcClient}
+		if previous := g.conn.Swap(conn); previous != nil {
+			previous.retire()
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to connect to the core RPC server: no healthy endpoint among %d", len(urls))
+}
+
+// failover connects to another endpoint after a request on the connection failed, unless a concurrent
+// request already replaced the connection
+func (g *Gocore) failover(failed *rpcConnection, cause error) error {
+	g.connectMu.Lock()
+	defer g.connectMu.Unlock()
+
+	if g.conn.Load() != failed {
+		return nil
+	}
+	g.logger.Warn("Core RPC request failed, failing over", "endpoint", failed.endpoint, "error", cause)
+	return g.connect(failed.endpoint)
+}
+
+// acquire returns the active connection with a request registered on it
+func (g *Gocore) acquire() (*rpcConnection, error) {
+	for {
+		conn := g.conn.Load()
+		if conn == nil {
+			return nil, errNotConnected
+		}
+		if conn.acquire() {
+			return conn, nil
+		}
+		// Replaced between loading and registering, load the new connection
+	}
+}
+
+// call runs the request on the active connection. When the endpoint fails to answer, the request is retried
+// once on the next healthy endpoint, which becomes the active connection.
+func (g *Gocore) call(request func(conn *rpcConnection) error) error {
+	conn, err := g.acquire()
+	if err != nil {
+		return err
+	}
+	err = request(conn)
+	conn.release()
+	if err == nil || !isEndpointError(err) {
+		return err
+	}
+
+	if failoverErr := g.failover(conn, err); failoverErr != nil {
+		g.logger.Error("Core RPC failover failed", "error", failoverErr)
+		return err
+	}
+	conn, err = g.acquire()
+	if err != nil {
+		return err
+	}
+	defer conn.release()
+	return request(conn)
+}
+
+// isEndpointError reports whether a request failed because of the endpoint rather than the request, i.e.
+// the node didn't answer with a result or an error
+func isEndpointError(err error) bool {
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr) && !errors.Is(err, core.NotFound) && !errors.Is(err, bind.ErrNoCode)
 }
 
+// dialHealthy connects to the endpoint and checks that it serves requests
+func (g *Gocore) dialHealthy(url string) (*rpc.Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RPCHealthCheckTimeout)
+	defer cancel()
+
+	rpcClient, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := xcbclient.NewClient(rpcClient).BlockNumber(ctx); err != nil {
+		rpcClient.Close()
+		return nil, fmt.Errorf("health check failed: %w", err)
+	}
+	return rpcClient, nil
+}
+
+// BuildBindings parses the Core Token contract address and ABI. The contract is bound to the active
+// connection on every call.
 func (g *Gocore) BuildBindings() error {
 	ctnAddress, err := common.HexToAddress(g.config.SmartContractAddress)
 	if err != nil {
@@ -73,8 +246,10 @@ func (g *Gocore) BuildBindings() error {
 		return fmt.Errorf("failed to parse Core Token ABI: %w", err)
 	}
 
-	contract := bind.NewBoundContract(ctnAddress, parsedABI, g.client, g.client, g.client)
-	g.ctnContract = contract
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.ctnAddress = ctnAddress
+	g.ctnABI = &parsedABI
 
 	return nil
 }
@@ -89,9 +264,14 @@ func (g *Gocore) NewHeaderSubscription() (core.Subscription, <-chan *types.Heade
 		g.subscription = nil
 	}
 
+	conn := g.conn.Load()
+	if conn == nil {
+		return nil, nil, errNotConnected
+	}
+
 	channel := make(chan *types.Header, BlockHeaderChannelBuffer)
 
-	subscription, err := g.client.SubscribeNewHead(context.Background(), channel)
+	subscription, err := conn.client.SubscribeNewHead(context.Background(), channel)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to subscribe to new head: %w", err)
 	}
@@ -100,6 +280,32 @@ func (g *Gocore) NewHeaderSubscription() (core.Subscription, <-chan *types.Heade
 	return subscription, channel, nil
 }
 
+// NewPendingTransactionSubscription subscribes to the hashes of transactions entering the node's transaction pool.
+// Requires a WebSocket or IPC endpoint.
+func (g *Gocore) NewPendingTransactionSubscription() (core.Subscription, <-chan common.Hash, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	conn := g.conn.Load()
+	if conn == nil {
+		return nil, nil, errNotConnected
+	}
+	if g.pendingSubscription != nil {
+		g.pendingSubscription.Unsubscribe()
+		g.pendingSubscription = nil
+	}
+
+	channel := make(chan common.Hash, PendingTransactionChannelBuffer)
+
+	subscription, err := conn.rpcClient.XcbSubscribe(context.Background(), channel, "newPendingTransactions")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to subscribe to pending transactions: %w", err)
+	}
+	g.pendingSubscription = subscription
+
+	return subscription, channel, nil
+}
+
 func (g *Gocore) Close() error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
@@ -108,18 +314,44 @@ func (g *Gocore) Close() error {
 		g.subscription.Unsubscribe()
 		g.subscription = nil
 	}
-	if g.client != nil {
-		g.client.Close()
+	if g.pendingSubscription != nil {
+		g.pendingSubscription.Unsubscribe()
+		g.pendingSubscription = nil
+	}
+	if conn := g.conn.Swap(nil); conn != nil {
+		conn.retire()
 	}
 
 	return nil
 }
 
+// GetBlockNumber returns the number of the node's head block
+func (g *Gocore) GetBlockNumber() (uint64, error) {
+	var number uint64
+	err := g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		number, err = conn.client.BlockNumber(ctx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get block number: %w", err)
+	}
+	return number, nil
+}
+
 func (g *Gocore) GetBlockByNumber(number uint64) (*types.Block, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	var block *types.Block
+	err := g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
 
-	block, err := g.client.BlockByNumber(ctx, big.NewInt(int64(number)))
+		var err error
+		block, err = conn.client.BlockByNumber(ctx, big.NewInt(int64(number)))
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get block by number: %w", err)
 	}
@@ -128,8 +360,18 @@ func (g *Gocore) GetBlockByNumber(number uint64) (*types.Block, error) {
 }
 
 func (g *Gocore) GetAddressCTNBalance(wallet string) (*big.Int, error) {
+	g.mu.RLock()
+	ctnAddress, ctnABI := g.ctnAddress, g.ctnABI
+	g.mu.RUnlock()
+	if ctnABI == nil {
+		return nil, fmt.Errorf("failed to get balance: Core Token bindings not built")
+	}
+
 	results := []interface{}{}
-	err := g.ctnContract.Call(nil, &results, "balanceOf", wallet)
+	err := g.call(func(conn *rpcConnection) error {
+		contract := bind.NewBoundContract(ctnAddress, *ctnABI, conn.client, conn.client, conn.client)
+		return contract.Call(nil, &results, "balanceOf", wallet)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
@@ -137,14 +379,167 @@ func (g *Gocore) GetAddressCTNBalance(wallet string) (*big.Int, error) {
 	return balance, nil
 }
 
-func (g *Gocore) GetTransactionReceipt(txHash string) (*types.Receipt, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// GetCBC721TokenURI returns the metadata URI of a CBC721 token
+func (g *Gocore) GetCBC721TokenURI(tokenAddress string, tokenID *big.Int) (string, error) {
+	address, err := common.HexToAddress(tokenAddress)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse token address: %w", err)
+	}
 
+	parsedABI, err := abi.JSON(strings.NewReader(CBC721ABI))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse CBC721 ABI: %w", err)
+	}
+
+	results := []interface{}{}
+	err = g.call(func(conn *rpcConnection) error {
+		contract := bind.NewBoundContract(address, parsedABI, conn.client, conn.client, conn.client)
+		return contract.Call(nil, &results, "tokenURI", tokenID)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get token URI: %w", err)
+	}
+	if len(results) == 0 {
+		return "", fmt.Errorf("empty token URI response")
+	}
+	uri, ok := results[0].(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected token URI type %T", results[0])
+	}
+	return uri, nil
+}
+
+// CallUint256 calls a view function without arguments that returns a uint256
+func (g *Gocore) CallUint256(contractAddress, method string) (*big.Int, error) {
+	address, err := common.HexToAddress(contractAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse contract address: %w", err)
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(fmt.Sprintf(
+		`[{"inputs":[],"name":%q,"outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`, method)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ABI for %s: %w", method, err)
+	}
+
+	results := []interface{}{}
+	err = g.call(func(conn *rpcConnection) error {
+		contract := bind.NewBoundContract(address, parsedABI, conn.client, conn.client, conn.client)
+		return contract.Call(nil, &results, method)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("empty %s response", method)
+	}
+	value, ok := results[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s type %T", method, results[0])
+	}
+	return value, nil
+}
+
+// NetworkID returns the network ID of the connected node
+func (g *Gocore) NetworkID() (*big.Int, error) {
+	var networkID *big.Int
+	err := g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		networkID, err = conn.client.NetworkID(ctx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network ID: %w", err)
+	}
+	return networkID, nil
+}
+
+// HasCode reports whether a contract is deployed at the address
+func (g *Gocore) HasCode(contractAddress string) (bool, error) {
+	address, err := common.HexToAddress(contractAddress)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse contract address: %w", err)
+	}
+
+	var code []byte
+	err = g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		code, err = conn.client.CodeAt(ctx, address, nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get contract code: %w", err)
+	}
+	return len(code) > 0, nil
+}
+
+func (g *Gocore) GetTransactionReceipt(txHash string) (*types.Receipt, error) {
 	hash := common.HexToHash(txHash)
-	receipt, err := g.client.TransactionReceipt(ctx, hash)
+	var receipt *types.Receipt
+	err := g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		receipt, err = conn.client.TransactionReceipt(ctx, hash)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
 	}
 	return receipt, nil
 }
+
+func (g *Gocore) GetTransaction(txHash string) (*types.Transaction, bool, error) {
+	var tx *types.Transaction
+	var pending bool
+	err := g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		var err error
+		tx, pending, err = conn.client.TransactionByHash(ctx, common.HexToHash(txHash))
+		return err
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return tx, pending, nil
+}
+
+// TraceBlockCalls traces the block's transactions with the node's callTracer (debug_traceBlockByHash).
+// Requires the debug API on the node. Transactions the tracer failed on have a nil trace.
+func (g *Gocore) TraceBlockCalls(block *types.Block) ([]*models.CallFrame, error) {
+	var results []struct {
+		Result *models.CallFrame `json:"result"`
+		Error  string            `json:"error"`
+	}
+	config := map[string]string{"tracer": "callTracer", "timeout": TraceBlockTimeout.String()}
+	err := g.call(func(conn *rpcConnection) error {
+		ctx, cancel := context.WithTimeout(context.Background(), TraceBlockTimeout)
+		defer cancel()
+
+		return conn.rpcClient.CallContext(ctx, &results, "debug_traceBlockByHash", block.Hash(), config)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace block: %w", err)
+	}
+	if len(results) != len(block.Transactions()) {
+		return nil, fmt.Errorf("failed to trace block: %d traces for %d transactions", len(results), len(block.Transactions()))
+	}
+
+	traces := make([]*models.CallFrame, len(results))
+	for i, result := range results {
+		if result.Error != "" {
+			g.logger.Warn("Failed to trace transaction", "tx", block.Transactions()[i].Hash().String(), "error", result.Error)
+			continue
+		}
+		traces[i] = result.Result
+	}
+	return traces, nil
+}
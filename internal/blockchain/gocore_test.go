@@ -0,0 +1,150 @@
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/core-coin/go-core/v2/common/hexutil"
+	"github.com/core-coin/go-core/v2/rpc"
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+// testNode serves xcb_blockNumber over WebSocket, failing with a node error while failing is set
+type testNode struct {
+	number  uint64
+	failing atomic.Bool
+	rpc     *rpc.Server
+	server  *httptest.Server
+	url     string
+}
+
+func (n *testNode) BlockNumber() (hexutil.Uint64, error) {
+	if n.failing.Load() {
+		return 0, errors.New("node unavailable")
+	}
+	return hexutil.Uint64(n.number), nil
+}
+
+func newTestNode(t *testing.T, number uint64) *testNode {
+	node := &testNode{number: number, rpc: rpc.NewServer()}
+	if err := node.rpc.RegisterName("xcb", node); err != nil {
+		t.Fatal(err)
+	}
+	node.server = httptest.NewServer(node.rpc.WebsocketHandler([]string{"*"}))
+	node.url = "ws" + strings.TrimPrefix(node.server.URL, "http")
+	t.Cleanup(node.stop)
+	return node
+}
+
+// stop closes the node's connections and stops accepting new ones
+func (n *testNode) stop() {
+	n.rpc.Stop()
+	n.server.Close()
+}
+
+func newTestGocore(t *testing.T, nodes ...*testNode) *Gocore {
+	log, err := logger.NewLogger(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiURL := ""
+	for _, node := range nodes {
+		apiURL += node.url + ","
+	}
+	g := NewGocore(apiURL, log, &config.Config{})
+	t.Cleanup(func() { g.Close() })
+	return g
+}
+
+func activeEndpoint(g *Gocore) string {
+	if conn := g.conn.Load(); conn != nil {
+		return conn.endpoint
+	}
+	return ""
+}
+
+func TestConnectToRPCSkipsUnhealthyEndpoints(t *testing.T) {
+	unhealthy, healthy := newTestNode(t, 1), newTestNode(t, 2)
+	unhealthy.failing.Store(true)
+	g := newTestGocore(t, unhealthy, healthy)
+
+	if err := g.ConnectToRPC(); err != nil {
+		t.Fatalf("ConnectToRPC: %v", err)
+	}
+	if endpoint := activeEndpoint(g); endpoint != healthy.url {
+		t.Fatalf("active endpoint = %q, want %q", endpoint, healthy.url)
+	}
+
+	healthy.failing.Store(true)
+	if err := g.ConnectToRPC(); err == nil {
+		t.Fatal("ConnectToRPC succeeded without a healthy endpoint")
+	}
+}
+
+func TestRequestFailsOverToNextEndpoint(t *testing.T) {
+	primary, secondary := newTestNode(t, 1), newTestNode(t, 2)
+	g := newTestGocore(t, primary, secondary)
+	if err := g.ConnectToRPC(); err != nil {
+		t.Fatalf("ConnectToRPC: %v", err)
+	}
+
+	primary.stop()
+	number, err := g.GetBlockNumber()
+	if err != nil {
+		t.Fatalf("GetBlockNumber: %v", err)
+	}
+	if number != 2 {
+		t.Fatalf("block number = %d, want 2 from the secondary endpoint", number)
+	}
+	if endpoint := activeEndpoint(g); endpoint != secondary.url {
+		t.Fatalf("active endpoint = %q, want %q", endpoint, secondary.url)
+	}
+}
+
+func TestNodeErrorDoesNotFailOver(t *testing.T) {
+	primary, secondary := newTestNode(t, 1), newTestNode(t, 2)
+	g := newTestGocore(t, primary, secondary)
+	if err := g.ConnectToRPC(); err != nil {
+		t.Fatalf("ConnectToRPC: %v", err)
+	}
+
+	primary.failing.Store(true)
+	if _, err := g.GetBlockNumber(); err == nil {
+		t.Fatal("GetBlockNumber succeeded, want the node error")
+	}
+	if endpoint := activeEndpoint(g); endpoint != primary.url {
+		t.Fatalf("active endpoint = %q, want %q", endpoint, primary.url)
+	}
+}
+
+func TestReplacedConnectionClosedAfterLastRequest(t *testing.T) {
+	node := newTestNode(t, 1)
+	g := newTestGocore(t, node)
+	if err := g.ConnectToRPC(); err != nil {
+		t.Fatalf("ConnectToRPC: %v", err)
+	}
+
+	conn, err := g.acquire()
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if err := g.ConnectToRPC(); err != nil {
+		t.Fatalf("ConnectToRPC: %v", err)
+	}
+	if g.conn.Load() == conn {
+		t.Fatal("reconnecting kept the previous connection")
+	}
+
+	if _, err := conn.client.BlockNumber(context.Background()); err != nil {
+		t.Fatalf("replaced connection closed while in use: %v", err)
+	}
+	conn.release()
+	if _, err := conn.client.BlockNumber(context.Background()); !errors.Is(err, rpc.ErrClientQuit) {
+		t.Fatalf("replaced connection after its last request: err = %v, want %v", err, rpc.ErrClientQuit)
+	}
+}
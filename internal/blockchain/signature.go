@@ -0,0 +1,40 @@
+package blockchain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/accounts"
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/crypto"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// VerifySignedMessage checks that the message was signed with the key of the address, as wallets sign messages
+// (prefixed with "\x19Core Signed Message:\n" and its length). The signature is hex encoded with the public key
+// appended. Addresses are compared without their network prefix and checksum, so wallets of another network
+// than the service's can be verified. Returns ErrInvalidSignature when the signature doesn't match.
+func VerifySignedMessage(address, message, signature string) error {
+	sig, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(signature, "0x"), "0X"))
+	if err != nil {
+		return fmt.Errorf("%w: not hex encoded", models.ErrInvalidSignature)
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidSignature, err)
+	}
+	expected, err := hex.DecodeString(validation.NormalizeAddress(address))
+	if err != nil || len(expected) != common.AddressLength {
+		return fmt.Errorf("invalid address: %s", address)
+	}
+
+	// The address ends with the hash of the public key, after the network prefix and checksum
+	signer := crypto.PubkeyToAddress(pub)
+	if !bytes.Equal(signer[2:], expected[2:]) {
+		return fmt.Errorf("%w: signed with the key of another address", models.ErrInvalidSignature)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package blockchain
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// CheckForContractXCBTransfers returns the XCB sent by contracts during the transaction (internal transactions),
+// found in its call trace. The transaction's own value is not included, and calls that were reverted are skipped.
+func CheckForContractXCBTransfers(tx *types.Transaction, trace *models.CallFrame, networkID int64) []*Transfer {
+	// A reverted transaction moved no value
+	if trace == nil || trace.Error != "" {
+		return nil
+	}
+
+	var transfers []*Transfer
+	var walk func(calls []*models.CallFrame)
+	walk = func(calls []*models.CallFrame) {
+		for _, call := range calls {
+			if call.Error != "" {
+				continue
+			}
+			// Only CALL and SELFDESTRUCT move value to another account, CALLCODE keeps it in the caller
+			if call.Type == "CALL" || call.Type == "SELFDESTRUCT" {
+				if value := traceValue(call.Value); value.Sign() > 0 {
+					amount, _ := new(big.Float).Quo(new(big.Float).SetInt(value), big.NewFloat(1e18)).Float64()
+					transfers = append(transfers, &Transfer{
+						From:        validation.NormalizeAddress(call.From),
+						To:          validation.NormalizeAddress(call.To),
+						Amount:      amount,
+						Value:       value.String(),
+						TokenSymbol: "XCB",
+						TxHash:      tx.Hash().String(),
+						NetworkID:   networkID,
+					})
+				}
+			}
+			walk(call.Calls)
+		}
+	}
+	walk(trace.Calls)
+	return transfers
+}
+
+// traceValue parses a hex encoded call value, empty or malformed values are zero
+func traceValue(value string) *big.Int {
+	parsed, ok := new(big.Int).SetString(strings.TrimPrefix(value, "0x"), 16)
+	if !ok {
+		return new(big.Int)
+	}
+	return parsed
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EmailRoute sends the emails of matching recipients through its own SMTP relay or provider API, e.g. an EU
+// relay for EU users. A route matches recipients by email domain or by a tag of their wallet.
+type EmailRoute struct {
+	Name    string   `json:"name"`
+	Domains []string `json:"domains"` // Recipient email domains, e.g. gmx.de
+	Tags    []string `json:"tags"`    // Wallet tags, e.g. eu
+	// Provider is smtp, sendgrid, ses or mailgun (default smtp)
+	Provider string `json:"provider"`
+	// Sender is the From address, defaults to SMTP_SENDER
+	Sender string `json:"sender"`
+
+	SMTPHost     string `json:"smtp_host"`
+	SMTPPort     int    `json:"smtp_port"` // Default 587
+	SMTPUser     string `json:"smtp_user"`
+	SMTPPassword string `json:"smtp_password"`
+
+	APIKey             string `json:"api_key"` // SendGrid/Mailgun API key
+	MailgunDomain      string `json:"mailgun_domain"`
+	MailgunAPIBase     string `json:"mailgun_api_base"` // Default https://api.mailgun.net
+	SESRegion          string `json:"ses_region"`       // Default us-east-1
+	SESAccessKeyID     string `json:"ses_access_key_id"`
+	SESSecretAccessKey string `json:"ses_secret_access_key"`
+}
+
+// loadEmailRoutes reads the JSON array of email routes, normalizing domains and tags and applying the defaults
+func loadEmailRoutes(path string) ([]EmailRoute, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read EMAIL_ROUTES_FILE: %w", err)
+	}
+	var routes []EmailRoute
+	if err := json.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("failed to parse EMAIL_ROUTES_FILE: %w", err)
+	}
+
+	for i := range routes {
+		route := &routes[i]
+		for j, domain := range route.Domains {
+			route.Domains[j] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		}
+		for j, tag := range route.Tags {
+			route.Tags[j] = strings.ToLower(strings.TrimSpace(tag))
+		}
+		route.Provider = strings.ToLower(route.Provider)
+		if route.Provider == "" {
+			route.Provider = "smtp"
+		}
+		if route.SMTPPort == 0 {
+			route.SMTPPort = 587
+		}
+		if route.MailgunAPIBase == "" {
+			route.MailgunAPIBase = "https://api.mailgun.net"
+		}
+		if route.SESRegion == "" {
+			route.SESRegion = "us-east-1"
+		}
+	}
+	return routes, nil
+}
+
+// validate checks that the route matches recipients and has the credentials of its provider
+func (r *EmailRoute) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(r.Domains) == 0 && len(r.Tags) == 0 {
+		return fmt.Errorf("at least one domain or tag is required")
+	}
+	for _, domain := range r.Domains {
+		if domain == "" || strings.Contains(domain, "@") {
+			return fmt.Errorf("invalid domain %q", domain)
+		}
+	}
+	for _, tag := range r.Tags {
+		if tag == "" {
+			return fmt.Errorf("tags must not be empty")
+		}
+	}
+
+	switch r.Provider {
+	case "smtp":
+		if r.SMTPHost == "" {
+			return fmt.Errorf("smtp_host is required for the smtp provider")
+		}
+	case "sendgrid":
+		if r.APIKey == "" {
+			return fmt.Errorf("api_key is required for the sendgrid provider")
+		}
+	case "mailgun":
+		if r.APIKey == "" || r.MailgunDomain == "" {
+			return fmt.Errorf("api_key and mailgun_domain are required for the mailgun provider")
+		}
+	case "ses":
+		if r.SESAccessKeyID == "" || r.SESSecretAccessKey == "" {
+			return fmt.Errorf("ses_access_key_id and ses_secret_access_key are required for the ses provider")
+		}
+	default:
+		return fmt.Errorf("provider must be one of smtp, sendgrid, ses, mailgun, got %q", r.Provider)
+	}
+	return nil
+}
@@ -0,0 +1,454 @@
+package http_api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// TemplatePreviewRequest represents the JSON body for template previews
+type TemplatePreviewRequest struct {
+	Template string   `json:"template" binding:"required"`
+	Channels []string `json:"channels" binding:"omitempty,dive,oneof=telegram email sms push discord matrix ntfy pushover"`
+}
+
+// MinWalletSearchLength is the minimum length of a wallet search query
+const MinWalletSearchLength = 3
+
+// WalletSearchResult represents a wallet in the admin list and search with masked contact data
+type WalletSearchResult struct {
+	Address               string `json:"address"`
+	SubscriptionAddress   string `json:"subscription_address"`
+	Originator            string `json:"originator"`
+	Network               string `json:"network"`
+	CreatedAt             int64  `json:"created_at"`
+	Active                bool   `json:"active"`
+	Paid                  bool   `json:"paid"`
+	Whitelisted           bool   `json:"whitelisted"`
+	SubscriptionExpiresAt int64  `json:"subscription_expires_at"`
+	Telegram              string `json:"telegram,omitempty"` // Masked username
+	TelegramConnected     bool   `json:"telegram_connected"`
+	TelegramDisabled      bool   `json:"telegram_disabled"`
+	Email                 string `json:"email,omitempty"` // Masked email
+	EmailBounced          bool   `json:"email_bounced"`
+}
+
+// ReprocessRequest represents the JSON body for scheduling a block range re-scan
+type ReprocessRequest struct {
+	From   *uint64 `json:"from" binding:"required"`
+	To     *uint64 `json:"to" binding:"required"`
+	DryRun bool    `json:"dry_run"`
+}
+
+// previewTemplate is a handler for the /admin/templates/preview endpoint.
+// It renders the given template with sample notifications for each channel and validates its syntax.
+func (s *HTTPServer) previewTemplate(c *gin.Context) {
+	var req TemplatePreviewRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	preview := s.nuntiare.PreviewTemplate(req.Template, req.Channels)
+	if !preview.Valid {
+		c.JSON(http.StatusUnprocessableEntity, preview)
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
+
+// reprocessBlocks is a handler for the /admin/reprocess endpoint.
+// It schedules a background re-scan of the block range and returns the job.
+func (s *HTTPServer) reprocessBlocks(c *gin.Context) {
+	var req ReprocessRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	job, err := s.nuntiare.ReprocessBlocks(*req.From, *req.To, req.DryRun)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidBlockRange) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		s.logger.Error("Failed to schedule reprocess job", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to schedule reprocess job"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// getReprocessJob is a handler for the /admin/reprocess/:id endpoint.
+// It returns the status and progress of a reprocess job.
+func (s *HTTPServer) getReprocessJob(c *gin.Context) {
+	id := c.Param("id")
+
+	job, err := s.nuntiare.GetReprocessJob(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "reprocess job not found"})
+		} else {
+			s.logger.Error("Failed to get reprocess job", "error", err, "id", id)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get reprocess job"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// listWallets is a handler for the /admin/wallets endpoint.
+// It returns a page of registered wallets with masked contact data, like the search.
+func (s *HTTPServer) listWallets(c *gin.Context) {
+	opts, fieldErr := parseListOptions(c, models.WalletListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	page, err := s.nuntiare.ListWallets(opts)
+	if err != nil {
+		s.logger.Error("Failed to list wallets", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list wallets"})
+		return
+	}
+
+	results := &models.Page[WalletSearchResult]{NextCursor: page.NextCursor}
+	for _, wallet := range page.Items {
+		results.Items = append(results.Items, newWalletSearchResult(wallet))
+	}
+	c.JSON(http.StatusOK, newListResponse(results, opts))
+}
+
+// listNotifications is a handler for the /admin/notifications endpoint.
+// It returns a page of stored notifications.
+func (s *HTTPServer) listNotifications(c *gin.Context) {
+	opts, fieldErr := parseListOptions(c, models.NotificationListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	page, err := s.nuntiare.ListNotifications(opts)
+	if err != nil {
+		s.logger.Error("Failed to list notifications", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(page, opts))
+}
+
+// listPayments is a handler for the /admin/payments endpoint.
+// It returns a page of subscription payments.
+func (s *HTTPServer) listPayments(c *gin.Context) {
+	opts, fieldErr := parseListOptions(c, models.PaymentListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	page, err := s.nuntiare.ListSubscriptionPayments(opts)
+	if err != nil {
+		s.logger.Error("Failed to list subscription payments", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list payments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(page, opts))
+}
+
+// DefaultStatsRange is the time range of the notification stats when no from/to is given
+const DefaultStatsRange = 30 * 24 * time.Hour
+
+// NotificationStatsResponse represents notification counts per time bucket
+type NotificationStatsResponse struct {
+	Success   bool                         `json:"success"`
+	Period    string                       `json:"period"`
+	Dimension string                       `json:"dimension"`
+	From      int64                        `json:"from"`
+	To        int64                        `json:"to"`
+	Data      []*models.NotificationRollup `json:"data"`
+}
+
+// notificationStats is a handler for the /admin/stats/notifications endpoint.
+// It returns hourly or daily notification counts per channel, token or origin from the rollup tables.
+func (s *HTTPServer) notificationStats(c *gin.Context) {
+	period := c.DefaultQuery("period", models.RollupDay)
+	if _, ok := models.RollupPeriods[period]; !ok {
+		message := "period must be one of: hour day"
+		respondValidationErrors(c, message, FieldError{Field: "period", Code: CodeInvalidValue, Message: message})
+		return
+	}
+
+	dimension := c.Query("dimension")
+	switch dimension {
+	case models.RollupByChannel, models.RollupByToken, models.RollupByOrigin, models.RollupByEventType, models.RollupByTag:
+	default:
+		message := "dimension must be one of: channel token origin event_type tag"
+		respondValidationErrors(c, message, FieldError{Field: "dimension", Code: CodeInvalidValue, Message: message})
+		return
+	}
+
+	now := time.Now()
+	to, fieldErr := unixQuery(c, "to", now.Unix())
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	from, fieldErr := unixQuery(c, "from", to-int64(DefaultStatsRange.Seconds()))
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	rollups, err := s.nuntiare.GetNotificationRollups(period, dimension, from, to)
+	if err != nil {
+		s.logger.Error("Failed to get notification rollups", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get notification stats"})
+		return
+	}
+	if rollups == nil {
+		rollups = []*models.NotificationRollup{}
+	}
+
+	c.JSON(http.StatusOK, NotificationStatsResponse{
+		Success:   true,
+		Period:    period,
+		Dimension: dimension,
+		From:      from,
+		To:        to,
+		Data:      rollups,
+	})
+}
+
+// PaymentInflowResponse represents the subscription payment totals per time bucket
+type PaymentInflowResponse struct {
+	Success   bool                    `json:"success"`
+	Period    string                  `json:"period"`
+	From      int64                   `json:"from"`
+	To        int64                   `json:"to"`
+	Total     float64                 `json:"total"`             // CTN received in [from, to)
+	Balance   *float64                `json:"balance,omitempty"` // Current CTN balance of the receiving address, omitted if unavailable
+	Threshold float64                 `json:"threshold"`         // Sweep alert threshold (0 = disabled)
+	Data      []*models.PaymentInflow `json:"data"`
+}
+
+// paymentInflow is a handler for the /admin/stats/inflow endpoint.
+// It returns hourly or daily subscription payment totals and the current receiving address balance.
+func (s *HTTPServer) paymentInflow(c *gin.Context) {
+	period := c.DefaultQuery("period", models.RollupDay)
+	if _, ok := models.RollupPeriods[period]; !ok {
+		message := "period must be one of: hour day"
+		respondValidationErrors(c, message, FieldError{Field: "period", Code: CodeInvalidValue, Message: message})
+		return
+	}
+
+	to, fieldErr := unixQuery(c, "to", time.Now().Unix())
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	from, fieldErr := unixQuery(c, "from", to-int64(DefaultStatsRange.Seconds()))
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	inflow, err := s.nuntiare.GetPaymentInflow(period, from, to)
+	if err != nil {
+		s.logger.Error("Failed to get payment inflow", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get payment inflow"})
+		return
+	}
+	if inflow == nil {
+		inflow = []*models.PaymentInflow{}
+	}
+
+	response := PaymentInflowResponse{
+		Success:   true,
+		Period:    period,
+		From:      from,
+		To:        to,
+		Threshold: s.balanceThreshold,
+		Data:      inflow,
+	}
+	for _, bucket := range inflow {
+		response.Total += bucket.Amount
+	}
+	// The stored totals are still useful when the node is unreachable
+	if balance, err := s.nuntiare.GetReceivingBalance(); err != nil {
+		s.logger.Warn("Failed to get receiving address balance", "error", err)
+	} else {
+		response.Balance = &balance
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DefaultShadowReportRange is the time range of the shadow report when no from/to is given
+const DefaultShadowReportRange = 24 * time.Hour
+
+// shadowReport is a handler for the /admin/shadow/report endpoint.
+// It compares the notifications recorded by shadow instances with the ones production sent.
+func (s *HTTPServer) shadowReport(c *gin.Context) {
+	to, fieldErr := unixQuery(c, "to", time.Now().Unix())
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	from, fieldErr := unixQuery(c, "from", to-int64(DefaultShadowReportRange.Seconds()))
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	report, err := s.nuntiare.CompareShadowNotifications(from, to)
+	if err != nil {
+		s.logger.Error("Failed to compare shadow notifications", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to compare shadow notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "report": report})
+}
+
+// unixQuery reads a Unix timestamp query parameter, returning the default if it is missing
+func unixQuery(c *gin.Context, name string, def int64) (int64, *FieldError) {
+	value := c.Query(name)
+	if value == "" {
+		return def, nil
+	}
+	timestamp, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || timestamp < 0 {
+		return 0, &FieldError{Field: name, Code: CodeInvalidValue, Message: name + " must be a Unix timestamp"}
+	}
+	return timestamp, nil
+}
+
+// searchWallets is a handler for the /admin/wallets/search endpoint.
+// It finds wallets by partial address, originator, email or Telegram username and masks contact data.
+func (s *HTTPServer) searchWallets(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if len(query) < MinWalletSearchLength {
+		message := fmt.Sprintf("q must be at least %d characters", MinWalletSearchLength)
+		respondValidationErrors(c, message, FieldError{Field: "q", Code: CodeTooShort, Message: message})
+		return
+	}
+
+	limit := models.DefaultListLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 {
+			respondValidationErrors(c, "limit must be a positive integer",
+				FieldError{Field: "limit", Code: CodeInvalidValue, Message: "limit must be a positive integer"})
+			return
+		}
+		limit = min(l, models.MaxListLimit)
+	}
+
+	wallets, err := s.nuntiare.SearchWallets(query, limit)
+	if err != nil {
+		s.logger.Error("Failed to search wallets", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to search wallets"})
+		return
+	}
+
+	results := make([]*WalletSearchResult, 0, len(wallets))
+	for _, wallet := range wallets {
+		results = append(results, newWalletSearchResult(wallet))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "data": results})
+}
+
+// newWalletSearchResult returns the wallet with masked contact data
+func newWalletSearchResult(wallet *models.Wallet) *WalletSearchResult {
+	tg := wallet.NotificationProvider.TelegramProvider
+	email := wallet.NotificationProvider.EmailProvider
+	return &WalletSearchResult{
+		Address:               wallet.Address,
+		SubscriptionAddress:   wallet.SubscriptionAddress,
+		Originator:            wallet.Originator,
+		Network:               wallet.Network,
+		CreatedAt:             wallet.CreatedAt,
+		Active:                wallet.Active,
+		Paid:                  wallet.Paid,
+		Whitelisted:           wallet.Whitelisted,
+		SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+		Telegram:              maskUsername(tg.Username),
+		TelegramConnected:     tg.ChatID != "",
+		TelegramDisabled:      tg.Disabled,
+		Email:                 maskEmail(email.Email),
+		EmailBounced:          email.Bounced,
+	}
+}
+
+// maskUsername keeps the first and last two characters of a username ("alice_core" -> "al***re")
+func maskUsername(username string) string {
+	runes := []rune(username)
+	switch {
+	case len(runes) == 0:
+		return ""
+	case len(runes) <= 4:
+		return string(runes[:1]) + "***"
+	default:
+		return string(runes[:2]) + "***" + string(runes[len(runes)-2:])
+	}
+}
+
+// maskEmail masks the local part of an email address ("alice@example.com" -> "a***e@example.com")
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok {
+		return maskUsername(email)
+	}
+	runes := []rune(local)
+	if len(runes) <= 2 {
+		return string(runes[:min(len(runes), 1)]) + "***@" + domain
+	}
+	return string(runes[:1]) + "***" + string(runes[len(runes)-1:]) + "@" + domain
+}
+
+// PanicReportsResponse represents the panics recovered by the instance
+type PanicReportsResponse struct {
+	Success bool                  `json:"success"`
+	Total   int64                 `json:"total"`
+	Reports []*models.PanicReport `json:"reports"`
+}
+
+// panicReports is a handler for the /admin/stats/panics endpoint.
+// It returns the panics recovered by the instance handling the request, grouped by stack signature.
+func (s *HTTPServer) panicReports(c *gin.Context) {
+	reports := s.nuntiare.GetPanicReports()
+	var total int64
+	for _, report := range reports {
+		total += report.Count
+	}
+	c.JSON(http.StatusOK, PanicReportsResponse{Success: true, Total: total, Reports: reports})
+}
+
+// PipelineStatsResponse represents the block pipeline stages of the instance
+type PipelineStatsResponse struct {
+	Success bool                         `json:"success"`
+	Stages  []*models.PipelineStageStats `json:"stages"`
+}
+
+// pipelineStats is a handler for the /admin/stats/pipeline endpoint.
+// It returns the queue lengths and timings of the block pipeline of the instance handling the request.
+func (s *HTTPServer) pipelineStats(c *gin.Context) {
+	c.JSON(http.StatusOK, PipelineStatsResponse{Success: true, Stages: s.nuntiare.PipelineStats()})
+}
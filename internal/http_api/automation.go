@@ -0,0 +1,153 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AutomationHookRequest represents the JSON body an automation platform subscribes a REST hook with
+type AutomationHookRequest struct {
+	Address   string `json:"address" binding:"required"`
+	TargetURL string `json:"target_url" binding:"required"`
+	Event     string `json:"event"` // Event type the hook receives, empty for all
+}
+
+// AutomationHooksResponse represents the automation hooks of a wallet
+type AutomationHooksResponse struct {
+	Success bool                     `json:"success"`
+	Hooks   []*models.AutomationHook `json:"hooks"`
+}
+
+// automationNotifications is a handler for the GET /automation/notifications endpoint.
+// It is the polling trigger of automation platforms: the latest notifications of the wallet, newest first,
+// as a bare array (Zapier) or wrapped in "data" with format=ifttt.
+func (s *HTTPServer) automationNotifications(c *gin.Context) {
+	format := c.Query("format")
+	if format != "" && format != "zapier" && format != "ifttt" {
+		message := "format must be zapier or ifttt"
+		respondValidationErrors(c, message, FieldError{Field: "format", Code: CodeInvalidValue, Message: message})
+		return
+	}
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 {
+			respondValidationErrors(c, "limit must be a positive integer",
+				FieldError{Field: "limit", Code: CodeInvalidValue, Message: "limit must be a positive integer"})
+			return
+		}
+		limit = l
+	}
+
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	items, err := s.nuntiare.GetAutomationItems(wallet.Address, c.Query("event"), limit)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidEventType) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "event", Code: CodeInvalidValue, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to get automation notifications", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get notifications"})
+		return
+	}
+
+	if format == "ifttt" {
+		c.JSON(http.StatusOK, gin.H{"data": items})
+		return
+	}
+	c.JSON(http.StatusOK, items)
+}
+
+// subscribeAutomationHook is a handler for the POST /automation/hooks endpoint.
+// It subscribes an automation platform's REST hook to the notifications of the wallet.
+func (s *HTTPServer) subscribeAutomationHook(c *gin.Context) {
+	var req AutomationHookRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	hook, err := s.nuntiare.AddAutomationHook(wallet.Address, req.TargetURL, req.Event)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidWebhookURL):
+			respondValidationErrors(c, err.Error(), FieldError{Field: "target_url", Code: CodeInvalid, Message: err.Error()})
+		case errors.Is(err, models.ErrInvalidEventType):
+			respondValidationErrors(c, err.Error(), FieldError{Field: "event", Code: CodeInvalidValue, Message: err.Error()})
+		case errors.Is(err, models.ErrInvalidAutomationHook):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+		default:
+			s.logger.Error("Failed to add automation hook", "error", err, "address", wallet.Address)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to add automation hook"})
+		}
+		return
+	}
+
+	// The hook fields are at the top level, platforms read the id to unsubscribe with
+	c.JSON(http.StatusCreated, gin.H{
+		"success":    true,
+		"id":         hook.ID,
+		"target_url": hook.TargetURL,
+		"event":      hook.EventType,
+		"created_at": hook.CreatedAt,
+		"secret":     hook.Secret,
+	})
+}
+
+// listAutomationHooks is a handler for the GET /automation/hooks endpoint.
+// It returns the automation hooks of the wallet.
+func (s *HTTPServer) listAutomationHooks(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	hooks, err := s.nuntiare.GetAutomationHooks(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to get automation hooks", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get automation hooks"})
+		return
+	}
+	if hooks == nil {
+		hooks = []*models.AutomationHook{}
+	}
+
+	c.JSON(http.StatusOK, AutomationHooksResponse{Success: true, Hooks: hooks})
+}
+
+// unsubscribeAutomationHook is a handler for the DELETE /automation/hooks/:id endpoint.
+// It unsubscribes an automation hook of the wallet, e.g. when the Zap is turned off.
+func (s *HTTPServer) unsubscribeAutomationHook(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	removed, err := s.nuntiare.RemoveAutomationHook(wallet.Address, c.Param("id"))
+	if err != nil {
+		s.logger.Error("Failed to remove automation hook", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to remove automation hook"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Automation hook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
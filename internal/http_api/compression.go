@@ -0,0 +1,74 @@
+package http_api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/core-coin/nuntiare/pkg/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// CompressionMinSize is the smallest response body that is worth compressing
+const CompressionMinSize = 1024
+
+// compressionMiddleware compresses response bodies of at least CompressionMinSize bytes with Brotli,
+// or with gzip for clients that don't accept Brotli
+func compressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := responseEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		// Restore the writer even if a handler panics so the recovery middleware can respond
+		defer func() { c.Writer = original }()
+		c.Next()
+
+		original.Header().Add("Vary", "Accept-Encoding")
+		if writer.body.Len() < CompressionMinSize || original.Header().Get("Content-Encoding") != "" {
+			original.WriteHeader(writer.status)
+			_, _ = original.Write(writer.body.Bytes())
+			return
+		}
+
+		original.Header().Set("Content-Encoding", encoding)
+		original.Header().Del("Content-Length")
+		original.WriteHeader(writer.status)
+		if encoding == "br" {
+			_, _ = original.Write(brotli.Encode(writer.body.Bytes()))
+			return
+		}
+		gz := gzip.NewWriter(original)
+		_, _ = gz.Write(writer.body.Bytes())
+		_ = gz.Close()
+	}
+}
+
+// responseEncoding returns the content encoding of responses to a request with the Accept-Encoding header:
+// br when it allows Brotli, gzip when it allows gzip, or empty
+func responseEncoding(acceptEncoding string) string {
+	if acceptsEncoding(acceptEncoding, "br") {
+		return "br"
+	}
+	if acceptsEncoding(acceptEncoding, "gzip") {
+		return "gzip"
+	}
+	return ""
+}
+
+// acceptsEncoding reports whether an Accept-Encoding header allows the encoding
+func acceptsEncoding(acceptEncoding, name string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		encodingName, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.TrimSpace(encodingName) != name {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
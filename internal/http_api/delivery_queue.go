@@ -0,0 +1,111 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// listDeliveryJobs is a handler for the /admin/deliveries/queue endpoint.
+// It returns a page of the failed deliveries waiting for a retry.
+func (s *HTTPServer) listDeliveryJobs(c *gin.Context) {
+	opts, fieldErr := parseListOptions(c, models.DeliveryJobListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	page, err := s.nuntiare.ListDeliveryJobs(opts)
+	if err != nil {
+		s.logger.Error("Failed to list delivery jobs", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list delivery queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(page, opts))
+}
+
+// listDeadLetters is a handler for the /admin/deliveries/dead endpoint.
+// It returns a page of the deliveries that ran out of attempts.
+func (s *HTTPServer) listDeadLetters(c *gin.Context) {
+	opts, fieldErr := parseListOptions(c, models.DeadLetterListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	page, err := s.nuntiare.ListDeadLetters(opts)
+	if err != nil {
+		s.logger.Error("Failed to list dead letters", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list dead letters"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(page, opts))
+}
+
+// getDeadLetter is a handler for the /admin/deliveries/dead/:id endpoint.
+// It returns a dead letter with the notification that wasn't delivered.
+func (s *HTTPServer) getDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	letter, err := s.nuntiare.GetDeadLetter(id)
+	if err != nil {
+		s.logger.Error("Failed to get dead letter", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get dead letter"})
+		return
+	}
+	if letter == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "dead letter not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, letter)
+}
+
+// replayDeadLetter is a handler for POST /admin/deliveries/dead/:id/replay.
+// It sends a dead letter through its channel once more and returns the delivery result.
+func (s *HTTPServer) replayDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	replay, err := s.nuntiare.ReplayDeadLetter(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrAlreadyReplayed):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": "dead letter already delivered by a replay"})
+		case errors.Is(err, models.ErrChannelDisabled):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": "the wallet no longer uses the channel"})
+		default:
+			s.logger.Error("Failed to replay dead letter", "error", err, "id", id)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to replay dead letter"})
+		}
+		return
+	}
+	if replay == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "dead letter not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": replay.Error == "", "replay": replay})
+}
+
+// removeDeadLetter is a handler for DELETE /admin/deliveries/dead/:id.
+// It discards a dead letter that shouldn't be replayed.
+func (s *HTTPServer) removeDeadLetter(c *gin.Context) {
+	id := c.Param("id")
+
+	removed, err := s.nuntiare.RemoveDeadLetter(id)
+	if err != nil {
+		s.logger.Error("Failed to remove dead letter", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to remove dead letter"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "dead letter not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,105 @@
+package http_api
+
+import (
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceRequest represents the JSON body for registering or refreshing a device
+type DeviceRequest struct {
+	Address    string `json:"address" binding:"required"`
+	DeviceID   string `json:"device_id" binding:"required,max=128"` // App-generated installation ID
+	OS         string `json:"os" binding:"max=32"`
+	PushToken  string `json:"push_token" binding:"max=4096"`
+	AppVersion string `json:"app_version" binding:"max=64"`
+	Notify     *bool  `json:"notify"` // Route notifications to this device (default true)
+}
+
+// DevicesResponse represents the devices of a wallet
+type DevicesResponse struct {
+	Success bool             `json:"success"`
+	Devices []*models.Device `json:"devices"`
+}
+
+// registerDevice is a handler for the PUT /devices endpoint.
+// It registers a device of the wallet or refreshes its details and last seen time.
+// Apps should call it on every start so active devices aren't removed as stale.
+func (s *HTTPServer) registerDevice(c *gin.Context) {
+	var req DeviceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	if !s.checkAppVersion(c, req.OS, req.AppVersion) {
+		return
+	}
+
+	device := &models.Device{
+		WalletAddress: wallet.Address,
+		DeviceID:      req.DeviceID,
+		OS:            req.OS,
+		PushToken:     req.PushToken,
+		AppVersion:    req.AppVersion,
+		Notify:        req.Notify == nil || *req.Notify,
+	}
+	if err := s.nuntiare.RegisterDevice(device); err != nil {
+		s.logger.Error("Failed to register device", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to register device"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "device": device})
+}
+
+// listDevices is a handler for the GET /devices endpoint.
+// It returns the devices registered for the wallet.
+func (s *HTTPServer) listDevices(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	devices, err := s.nuntiare.GetDevices(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to get devices", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get devices"})
+		return
+	}
+	if devices == nil {
+		devices = []*models.Device{}
+	}
+
+	c.JSON(http.StatusOK, DevicesResponse{Success: true, Devices: devices})
+}
+
+// removeDevice is a handler for the DELETE /devices/:device_id endpoint.
+// It unregisters a device of the wallet, e.g. on logout.
+func (s *HTTPServer) removeDevice(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	removed, err := s.nuntiare.RemoveDevice(wallet.Address, c.Param("device_id"))
+	if err != nil {
+		s.logger.Error("Failed to remove device", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to remove device"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Device not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
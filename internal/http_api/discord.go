@@ -0,0 +1,44 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// DiscordVerifyRequest represents the JSON body for verifying the Discord channel of a wallet
+type DiscordVerifyRequest struct {
+	Address string `json:"address" binding:"required"`
+}
+
+// verifyDiscord is a handler for the POST /wallet/discord/verify endpoint.
+// It checks the verification code was posted to the wallet's Discord channel, the bot posts there from then on.
+func (s *HTTPServer) verifyDiscord(c *gin.Context) {
+	var req DiscordVerifyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	if err := s.nuntiare.VerifyDiscord(wallet.Address); err != nil {
+		if errors.Is(err, models.ErrDiscordNotVerified) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		s.logger.Error("Failed to verify discord channel", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to verify discord channel"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,96 @@
+package http_api
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// emailVerificationPageTemplate renders the pages of the email verification link
+var emailVerificationPageTemplate = template.Must(template.New("email_verification").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f4f5f7; color: #1d1f23; margin: 0; padding: 24px; }
+.card { max-width: 560px; margin: 0 auto; background: #fff; border-radius: 12px; padding: 24px; box-shadow: 0 1px 4px rgba(0,0,0,.08); }
+h1 { font-size: 20px; margin: 0 0 16px; }
+button { background: #2563eb; color: #fff; border: 0; border-radius: 6px; padding: 12px 24px; font-size: 15px; cursor: pointer; }
+</style>
+</head>
+<body>
+<div class="card">
+<h1>{{.Title}}</h1>
+<p>{{.Message}}</p>
+{{if .Token}}<form method="post" action="">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Confirm email</button>
+</form>{{end}}
+</div>
+</body>
+</html>
+`))
+
+// emailVerificationPage is the data passed to emailVerificationPageTemplate
+type emailVerificationPage struct {
+	Title   string
+	Message string
+	Token   string // Set on the confirmation page
+}
+
+// emailVerificationForm is a handler for GET /api/v1/email/verify, the link in verification emails.
+// It only shows a confirmation button: link scanners of mail providers open links, which must not verify the email.
+func (s *HTTPServer) emailVerificationForm(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		s.renderEmailVerificationPage(c, http.StatusBadRequest, emailVerificationPage{
+			Title:   "Invalid link",
+			Message: "The verification link is incomplete. Copy the whole link from the email.",
+		})
+		return
+	}
+	s.renderEmailVerificationPage(c, http.StatusOK, emailVerificationPage{
+		Title:   "Confirm your email",
+		Message: "Confirm that you want to receive wallet transfer notifications at this email address.",
+		Token:   token,
+	})
+}
+
+// verifyEmail is a handler for POST /api/v1/email/verify.
+// It confirms the email of the verification token, notifications are emailed from then on.
+func (s *HTTPServer) verifyEmail(c *gin.Context) {
+	if _, err := s.nuntiare.VerifyEmail(c.PostForm("token")); err != nil {
+		if errors.Is(err, models.ErrInvalidVerificationToken) {
+			s.renderEmailVerificationPage(c, http.StatusBadRequest, emailVerificationPage{
+				Title:   "Link expired",
+				Message: "The verification link is invalid or expired. Register the email in your wallet app again to get a new link.",
+			})
+			return
+		}
+		s.logger.Error("Failed to verify email", "error", err)
+		s.renderEmailVerificationPage(c, http.StatusInternalServerError, emailVerificationPage{
+			Title:   "Verification failed",
+			Message: "The email couldn't be verified. Please try again later.",
+		})
+		return
+	}
+
+	s.renderEmailVerificationPage(c, http.StatusOK, emailVerificationPage{
+		Title:   "Email confirmed",
+		Message: "You will now receive wallet transfer notifications at this email address.",
+	})
+}
+
+func (s *HTTPServer) renderEmailVerificationPage(c *gin.Context, status int, page emailVerificationPage) {
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(status)
+	if err := emailVerificationPageTemplate.Execute(c.Writer, page); err != nil {
+		s.logger.Error("Failed to render email verification page", "error", err)
+	}
+}
@@ -0,0 +1,261 @@
+package http_api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// exchangeKey is the context key of the exchange authenticated by exchangeMiddleware
+	exchangeKey = "exchange"
+	// MaxExchangeReconciliationRange is the longest time range of a reconciliation report
+	MaxExchangeReconciliationRange = 90 * 24 * time.Hour
+)
+
+// AddExchangeRequest represents the JSON body for adding an exchange
+type AddExchangeRequest struct {
+	Name          string `json:"name" binding:"required"`
+	WebhookURL    string `json:"webhook_url" binding:"required"`
+	Confirmations int    `json:"confirmations"` // 0 for the default
+}
+
+// ExchangeAddressesRequest represents the JSON body for registering exchange deposit addresses in bulk
+type ExchangeAddressesRequest struct {
+	Addresses []models.ExchangeAddressRow `json:"addresses" binding:"required"`
+}
+
+// CreditExchangeDepositRequest represents the JSON body for reporting a deposit credited
+type CreditExchangeDepositRequest struct {
+	Amount *float64 `json:"amount" binding:"required"`
+}
+
+// addExchange is a handler for POST /admin/exchanges.
+// It adds an exchange in deposit-confirmation mode and returns its API key and webhook secret once.
+func (s *HTTPServer) addExchange(c *gin.Context) {
+	var req AddExchangeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	credentials, err := s.nuntiare.AddExchange(req.Name, req.WebhookURL, req.Confirmations)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidExchange) || errors.Is(err, models.ErrInvalidWebhookURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		s.logger.Error("Failed to add exchange", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to add exchange"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, credentials)
+}
+
+// listExchanges is a handler for the /admin/exchanges endpoint.
+// It returns the exchanges in deposit-confirmation mode.
+func (s *HTTPServer) listExchanges(c *gin.Context) {
+	exchanges, err := s.nuntiare.ListExchanges()
+	if err != nil {
+		s.logger.Error("Failed to list exchanges", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to list exchanges"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "exchanges": exchanges})
+}
+
+// removeExchange is a handler for DELETE /admin/exchanges/:id.
+// It removes an exchange and its deposit addresses, its deposits are kept.
+func (s *HTTPServer) removeExchange(c *gin.Context) {
+	id := c.Param("id")
+
+	removed, err := s.nuntiare.RemoveExchange(id)
+	if err != nil {
+		s.logger.Error("Failed to remove exchange", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to remove exchange"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "exchange not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// exchangeMiddleware authenticates exchanges by the API key returned when they were added
+func (s *HTTPServer) exchangeMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		exchange, err := s.nuntiare.ExchangeByAPIKey(key)
+		if err != nil {
+			s.logger.Error("Failed to authenticate exchange", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to authenticate exchange"})
+			return
+		}
+		if exchange == nil {
+			s.logger.Warn("Invalid exchange key", "path", c.FullPath(), "ip", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid exchange key"})
+			return
+		}
+
+		c.Set(exchangeKey, exchange)
+		c.Next()
+	}
+}
+
+// authenticatedExchange returns the exchange set by exchangeMiddleware
+func authenticatedExchange(c *gin.Context) *models.Exchange {
+	return c.MustGet(exchangeKey).(*models.Exchange)
+}
+
+// registerExchangeAddresses is a handler for POST /exchange/addresses.
+// It registers deposit addresses of the exchange in bulk and reports the outcome of every row.
+func (s *HTTPServer) registerExchangeAddresses(c *gin.Context) {
+	exchange := authenticatedExchange(c)
+	var req ExchangeAddressesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	report, err := s.nuntiare.RegisterExchangeAddresses(exchange, req.Addresses)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidExchange) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "addresses", Code: CodeInvalidValue, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to register exchange addresses", "error", err, "exchange", exchange.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to register addresses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": report.Failed == 0, "report": report})
+}
+
+// removeExchangeAddress is a handler for DELETE /exchange/addresses/:address.
+// It stops watching a deposit address of the exchange.
+func (s *HTTPServer) removeExchangeAddress(c *gin.Context) {
+	exchange := authenticatedExchange(c)
+	address := c.Param("address")
+
+	removed, err := s.nuntiare.RemoveExchangeAddress(exchange, address)
+	if err != nil {
+		s.logger.Error("Failed to remove exchange address", "error", err, "exchange", exchange.ID, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to remove address"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Address not registered"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// listExchangeDeposits is a handler for GET /exchange/deposits.
+// It returns a page of the deposits detected on the exchange's addresses.
+func (s *HTTPServer) listExchangeDeposits(c *gin.Context) {
+	exchange := authenticatedExchange(c)
+	opts, fieldErr := parseListOptions(c, models.ExchangeDepositListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	page, err := s.nuntiare.ListExchangeDeposits(exchange, opts)
+	if err != nil {
+		s.logger.Error("Failed to list exchange deposits", "error", err, "exchange", exchange.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list deposits"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(page, opts))
+}
+
+// creditExchangeDeposit is a handler for POST /exchange/deposits/:id/credit.
+// It records that the exchange credited a confirmed deposit to its customer, for the reconciliation.
+func (s *HTTPServer) creditExchangeDeposit(c *gin.Context) {
+	exchange := authenticatedExchange(c)
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		respondValidationErrors(c, "id must be a deposit ID", FieldError{Field: "id", Code: CodeInvalidType, Message: "id must be a deposit ID"})
+		return
+	}
+	var req CreditExchangeDepositRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	deposit, err := s.nuntiare.CreditExchangeDeposit(exchange, id, *req.Amount)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidExchange):
+			respondValidationErrors(c, err.Error(), FieldError{Field: "amount", Code: CodeInvalidValue, Message: err.Error()})
+		case errors.Is(err, models.ErrDepositNotConfirmed):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": "Deposit is not confirmed or already credited"})
+		default:
+			s.logger.Error("Failed to credit exchange deposit", "error", err, "exchange", exchange.ID, "deposit", id)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to credit deposit"})
+		}
+		return
+	}
+	if deposit == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Deposit not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "deposit": deposit, "mismatch": deposit.CreditMismatch()})
+}
+
+// exchangeReconciliation is a handler for GET /exchange/reconciliation.
+// It compares the deposits detected in a time range with the ones the exchange credited.
+func (s *HTTPServer) exchangeReconciliation(c *gin.Context) {
+	exchange := authenticatedExchange(c)
+
+	now := time.Now()
+	to, fieldErr := unixQuery(c, "to", now.Unix())
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	from, fieldErr := unixQuery(c, "from", to-int64(DefaultStatsRange.Seconds()))
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	if from >= to {
+		message := "from must be before to"
+		respondValidationErrors(c, message, FieldError{Field: "from", Code: CodeInvalidValue, Message: message})
+		return
+	}
+	if to-from > int64(MaxExchangeReconciliationRange.Seconds()) {
+		message := fmt.Sprintf("time range must not exceed %d days", int(MaxExchangeReconciliationRange.Hours()/24))
+		respondValidationErrors(c, message, FieldError{Field: "from", Code: CodeInvalidValue, Message: message})
+		return
+	}
+
+	report, err := s.nuntiare.GetExchangeReconciliation(exchange, from, to)
+	if err != nil {
+		s.logger.Error("Failed to get exchange reconciliation", "error", err, "exchange", exchange.ID)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get reconciliation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "reconciliation": report})
+}
@@ -1,6 +1,9 @@
 package http_api
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
@@ -8,8 +11,12 @@ import (
 	"github.com/core-coin/nuntiare/internal/models"
 	"github.com/core-coin/nuntiare/pkg/validation"
 	"github.com/gin-gonic/gin"
+	tgModels "github.com/go-telegram/bot/models"
 )
 
+// MaxWebhookBodySize limits the size of incoming provider webhook payloads
+const MaxWebhookBodySize = 1 << 20 // 1 MB
+
 // RegisterRequest represents the JSON body for wallet registration
 type RegisterRequest struct {
 	Origin      string `json:"origin" binding:"required"`
@@ -17,10 +24,28 @@ type RegisterRequest struct {
 	Subscriber  string `json:"subscriber" binding:"required"`
 	Destination string `json:"destination" binding:"required"`
 	Network     string `json:"network" binding:"required,oneof=xcb xab"`
-	OS          string `json:"os"`   // Operating system (ios, android, web, etc.)
-	Lang        string `json:"lang"` // Language (en, es, fr, etc.)
+	OS          string `json:"os"`                           // Operating system (ios, android, web, etc.)
+	Lang        string `json:"lang"`                         // Language (en, es, fr, etc.)
+	AppVersion  string `json:"app_version" binding:"max=64"` // Wallet app version (e.g. 2.1.0)
 	Telegram    string `json:"telegram"`
 	Email       string `json:"email" binding:"omitempty,email"`
+	FCMToken    string `json:"fcm_token" binding:"max=4096"` // Firebase Cloud Messaging registration token of the app
+	WebhookURL  string `json:"webhook_url"`                  // HTTPS URL notifications are POSTed to as signed JSON
+	// Discord channel webhook URL, or channel ID the bot posts to
+	DiscordWebhookURL string `json:"discord_webhook_url" binding:"max=2048"`
+	DiscordChannelID  string `json:"discord_channel_id" binding:"max=20"`
+	Phone             string `json:"phone" binding:"max=16"` // E.164 phone number SMS notifications are sent to
+	MatrixRoomID      string `json:"matrix_room_id"`         // Matrix room the bot posts to (e.g. !abc:example.org)
+	NtfyTopicURL      string `json:"ntfy_topic_url"`         // ntfy topic notifications are published to (e.g. https://ntfy.sh/my-wallet)
+	PushoverUserKey   string `json:"pushover_user_key"`      // Pushover user or group key notifications are sent to
+	// Subscriber ownership challenge and its signature, required to share the subscriber with another user's wallets
+	SubscriberChallenge string `json:"subscriber_challenge"`
+	SubscriberSignature string `json:"subscriber_signature" binding:"max=512"`
+	// Event types the wallet is not notified about. Omit to keep the current list, [] unmutes all.
+	MutedEvents []string `json:"muted_events" binding:"omitempty,max=16"`
+	// Minimum amount per currency (e.g. {"XCB": 0.5}) of transfers the wallet is notified about.
+	// Omit to keep the current thresholds, {} removes all.
+	MinAmount map[string]float64 `json:"min_amount" binding:"omitempty,max=32"`
 }
 
 // RegisterResponse represents the success response for registration
@@ -29,6 +54,11 @@ type RegisterResponse struct {
 	Message             string `json:"message"`
 	Address             string `json:"address"`
 	SubscriptionAddress string `json:"subscription_address"`
+	// SubscriptionExpiresAt is the subscription expiration of a wallet registered before (0 = unpaid)
+	SubscriptionExpiresAt int64  `json:"subscription_expires_at"`
+	WebhookSecret         string `json:"webhook_secret,omitempty"` // HMAC key of the webhook signatures, returned when webhook_url is set
+	// DiscordVerificationCode must be posted to the channel before notifications are posted there, returned when discord_channel_id is set
+	DiscordVerificationCode string `json:"discord_verification_code,omitempty"`
 }
 
 // CancelRequest represents the JSON body for canceling notifications
@@ -44,6 +74,116 @@ type SubscriptionResponse struct {
 	Active     bool  `json:"active"`                // Whether notifications are enabled
 }
 
+// BatchSubscriptionRequest represents the JSON body for checking the subscription status of many addresses
+type BatchSubscriptionRequest struct {
+	Addresses []string `json:"addresses" binding:"required,min=1,max=100"`
+}
+
+// BatchSubscriptionResult represents the subscription status of a single address in a batch
+type BatchSubscriptionResult struct {
+	Address    string `json:"address"`
+	Found      bool   `json:"found"` // Whether the address is registered
+	Subscribed bool   `json:"subscribed"`
+	ExpiresAt  int64  `json:"expires_at,omitempty"` // Unix timestamp, only if subscribed
+	Active     bool   `json:"active"`
+}
+
+// BatchSubscriptionResponse represents the subscription statuses of a batch, in request order
+type BatchSubscriptionResponse struct {
+	Success bool                      `json:"success"`
+	Results []BatchSubscriptionResult `json:"results"`
+}
+
+// WalletDetailsResponse represents the wallet details including notification channel state
+type WalletDetailsResponse struct {
+	Address             string                  `json:"address"`
+	SubscriptionAddress string                  `json:"subscription_address"`
+	Network             string                  `json:"network"`
+	OS                  string                  `json:"os"`
+	Lang                string                  `json:"lang"`
+	Active              bool                    `json:"active"`
+	Subscribed          bool                    `json:"subscribed"`
+	OwnershipProven     bool                    `json:"ownership_proven"`
+	ExpiresAt           int64                   `json:"expires_at,omitempty"`
+	Telegram            *TelegramChannelDetails `json:"telegram,omitempty"`
+	Email               *EmailChannelDetails    `json:"email,omitempty"`
+	Push                *PushChannelDetails     `json:"push,omitempty"`
+	Webhook             *WebhookChannelDetails  `json:"webhook,omitempty"`
+	Discord             *DiscordChannelDetails  `json:"discord,omitempty"`
+	SMS                 *SMSChannelDetails      `json:"sms,omitempty"`
+	Matrix              *MatrixChannelDetails   `json:"matrix,omitempty"`
+	Ntfy                *NtfyChannelDetails     `json:"ntfy,omitempty"`
+	Pushover            *PushoverChannelDetails `json:"pushover,omitempty"`
+	MutedEvents         []string                `json:"muted_events"`
+	MinAmount           map[string]float64      `json:"min_amount"`
+}
+
+// TelegramChannelDetails represents the state of the Telegram channel
+type TelegramChannelDetails struct {
+	Username       string `json:"username"`
+	Connected      bool   `json:"connected"` // User sent /start to the bot
+	Disabled       bool   `json:"disabled"`  // Bot was blocked or chat no longer exists
+	DisabledReason string `json:"disabled_reason,omitempty"`
+	DisabledAt     int64  `json:"disabled_at,omitempty"`
+}
+
+// EmailChannelDetails represents the state of the email channel
+type EmailChannelDetails struct {
+	Email    string `json:"email"`
+	Bounced  bool   `json:"bounced"`
+	Verified bool   `json:"verified"` // Notifications are only emailed once the verification link was opened
+}
+
+// DiscordChannelDetails represents the state of the Discord channel. The webhook URL is a credential and not returned.
+type DiscordChannelDetails struct {
+	Webhook        bool   `json:"webhook"`
+	ChannelID      string `json:"channel_id,omitempty"`
+	Unverified     bool   `json:"unverified,omitempty"` // The verification code wasn't found in the channel yet
+	Disabled       bool   `json:"disabled"`
+	DisabledReason string `json:"disabled_reason,omitempty"`
+}
+
+// SMSChannelDetails represents the state of the SMS channel
+type SMSChannelDetails struct {
+	Phone          string `json:"phone"`
+	Disabled       bool   `json:"disabled"` // The SMS provider reported the number as invalid or opted out
+	DisabledReason string `json:"disabled_reason,omitempty"`
+}
+
+// MatrixChannelDetails represents the state of the Matrix channel
+type MatrixChannelDetails struct {
+	RoomID         string `json:"room_id"`
+	Disabled       bool   `json:"disabled"` // The bot can't join or post to the room
+	DisabledReason string `json:"disabled_reason,omitempty"`
+	InvitedBy      string `json:"invited_by,omitempty"` // The room member whose invite the bot accepted
+	JoinedAt       int64  `json:"joined_at,omitempty"`
+}
+
+// NtfyChannelDetails represents the state of the ntfy channel
+type NtfyChannelDetails struct {
+	TopicURL       string `json:"topic_url"`
+	Disabled       bool   `json:"disabled"` // The server refuses to publish to the topic
+	DisabledReason string `json:"disabled_reason,omitempty"`
+}
+
+// PushoverChannelDetails represents the state of the Pushover channel
+type PushoverChannelDetails struct {
+	UserKey        string `json:"user_key"`
+	Disabled       bool   `json:"disabled"` // Pushover rejected the user key
+	DisabledReason string `json:"disabled_reason,omitempty"`
+}
+
+// WebhookChannelDetails represents the state of the webhook channel
+type WebhookChannelDetails struct {
+	URL string `json:"url"`
+}
+
+// PushChannelDetails represents the state of the FCM push channel
+type PushChannelDetails struct {
+	Disabled       bool   `json:"disabled"` // FCM reported the token as unregistered or invalid
+	DisabledReason string `json:"disabled_reason,omitempty"`
+}
+
 // register is a handler for the /register endpoint.
 func (s *HTTPServer) register(c *gin.Context) {
 	var req RegisterRequest
@@ -51,77 +191,107 @@ func (s *HTTPServer) register(c *gin.Context) {
 	// Parse and validate JSON request body
 	if err := c.ShouldBindJSON(&req); err != nil {
 		s.logger.Debug("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body: " + err.Error(),
-		})
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
 		return
 	}
 
-	// Validate address formats
+	s.registerWallet(c, &req)
+}
+
+// registerWallet validates a bound registration request and registers or updates the wallet
+func (s *HTTPServer) registerWallet(c *gin.Context, req *RegisterRequest) {
+	// Validate address formats, reporting both fields at once
+	var addressErrs []FieldError
 	if err := validation.ValidateAddress(req.Subscriber); err != nil {
 		s.logger.Debug("Invalid subscriber address", "error", err, "address", req.Subscriber)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid subscriber address: " + err.Error(),
-		})
-		return
+		addressErrs = append(addressErrs, addressError(fieldName(c, "subscriber"), err))
 	}
-
 	if err := validation.ValidateAddress(req.Destination); err != nil {
 		s.logger.Debug("Invalid destination address", "error", err, "address", req.Destination)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid destination address: " + err.Error(),
-		})
+		addressErrs = append(addressErrs, addressError(fieldName(c, "destination"), err))
+	}
+	if len(addressErrs) > 0 {
+		respondValidationErrors(c, "Invalid "+addressLabel(addressErrs[0].Field)+": "+addressErrs[0].Message, addressErrs...)
+		return
+	}
+
+	// Store and look up addresses in canonical form (lowercase, no 0x prefix)
+	req.Subscriber = validation.NormalizeAddress(req.Subscriber)
+	req.Destination = validation.NormalizeAddress(req.Destination)
+
+	if !s.checkAppVersion(c, req.OS, req.AppVersion) {
 		return
 	}
 
 	// Require at least one notification method
-	if req.Telegram == "" && req.Email == "" {
+	if req.Telegram == "" && req.Email == "" && req.FCMToken == "" && req.WebhookURL == "" &&
+		req.DiscordWebhookURL == "" && req.DiscordChannelID == "" && req.Phone == "" &&
+		req.MatrixRoomID == "" && req.NtfyTopicURL == "" && req.PushoverUserKey == "" {
 		s.logger.Debug("No notification method provided", "destination", req.Destination)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "At least one notification method (telegram or email) is required",
-		})
+		message := "At least one notification method (telegram, email, fcm_token, webhook_url, discord_webhook_url, discord_channel_id, phone, matrix_room_id, ntfy_topic_url or pushover_user_key) is required"
+		respondValidationErrors(c, message,
+			FieldError{Field: "telegram", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "email", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "fcm_token", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "webhook_url", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "discord_webhook_url", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "discord_channel_id", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "phone", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "matrix_room_id", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "ntfy_topic_url", Code: CodeMissingMethod, Message: message},
+			FieldError{Field: "pushover_user_key", Code: CodeMissingMethod, Message: message})
 		return
 	}
 
-	existingWallet, err := s.nuntiare.GetWallet(req.Destination)
-	if err == nil && existingWallet != nil {
-		// Wallet exists - verify OriginID for authentication
-		if existingWallet.OriginID != req.OriginID {
-			s.logger.Warn("OriginID mismatch for wallet update", "destination", req.Destination)
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"success": false,
-				"error":   "Invalid originid",
-			})
+	if req.WebhookURL != "" {
+		if err := s.nuntiare.ValidateWebhookURL(req.WebhookURL); err != nil {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "webhook_url", Code: CodeInvalid, Message: err.Error()})
 			return
 		}
-
-		// Update notification providers and re-activate if cancelled
-		s.logger.Info("Wallet already exists, updating notification providers and reactivating", "destination", req.Destination)
-
-		err = s.nuntiare.UpdateNotificationProviderAndReactivate(req.Destination, req.Telegram, req.Email)
-		if err != nil {
-			s.logger.Error("Failed to update notification provider", "error", err, "destination", req.Destination)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"success": false,
-				"error":   "Failed to update notification provider",
-			})
+	}
+	for _, eventType := range req.MutedEvents {
+		if !models.IsEventType(eventType) {
+			message := "muted_events must only contain: " + strings.Join(models.EventTypes, " ")
+			respondValidationErrors(c, message, FieldError{Field: "muted_events", Code: CodeInvalidValue, Message: message})
 			return
 		}
-
-		s.logger.Info("Notification providers updated and wallet reactivated", "destination", req.Destination)
-		c.JSON(http.StatusOK, RegisterResponse{
-			Success:             true,
-			Message:             "Notification providers updated successfully",
-			Address:             req.Destination,
-			SubscriptionAddress: existingWallet.SubscriptionAddress,
-		})
+	}
+	if err := s.nuntiare.ValidateMinAmounts(req.MinAmount); err != nil {
+		respondValidationErrors(c, err.Error(), FieldError{Field: "min_amount", Code: CodeInvalidValue, Message: err.Error()})
 		return
 	}
-
+	if err := s.nuntiare.ValidateDiscord(req.DiscordWebhookURL, req.DiscordChannelID); err != nil {
+		field := "discord_webhook_url"
+		if req.DiscordWebhookURL == "" {
+			field = "discord_channel_id"
+		}
+		respondValidationErrors(c, err.Error(), FieldError{Field: field, Code: CodeInvalid, Message: err.Error()})
+		return
+	}
+	if req.Phone != "" {
+		if err := s.nuntiare.ValidatePhone(req.Phone); err != nil {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "phone", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+	}
+	if req.MatrixRoomID != "" {
+		if err := s.nuntiare.ValidateMatrixRoom(req.MatrixRoomID); err != nil {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "matrix_room_id", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+	}
+	if req.NtfyTopicURL != "" {
+		if err := s.nuntiare.ValidateNtfyTopic(req.NtfyTopicURL); err != nil {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "ntfy_topic_url", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+	}
+	if req.PushoverUserKey != "" {
+		if err := s.nuntiare.ValidatePushoverUserKey(req.PushoverUserKey); err != nil {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "pushover_user_key", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+	}
 
 	// Create notification provider for new wallet
 	notificationProvider := models.NotificationProvider{
@@ -131,11 +301,25 @@ func (s *HTTPServer) register(c *gin.Context) {
 		EmailProvider: models.EmailProvider{
 			Email: req.Email,
 		},
+		FCMProvider: models.FCMProvider{
+			Token: req.FCMToken,
+		},
 		Address: req.Destination,
 	}
 
-	// Register new wallet
-	err = s.nuntiare.RegisterNewWallet(&models.Wallet{
+	// The subscriber's ownership is proven with a challenge from /subscription_address/challenge
+	var subscriberProof *models.OwnershipProof
+	if req.SubscriberChallenge != "" || req.SubscriberSignature != "" {
+		address, err := s.sessions.verifyFor(tokenPurposeOwnership, req.SubscriberChallenge, time.Now())
+		if err != nil || address != req.Subscriber {
+			c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid or expired " + fieldName(c, "subscriber_challenge")})
+			return
+		}
+		subscriberProof = &models.OwnershipProof{Message: ownershipMessage(address, req.SubscriberChallenge), Signature: req.SubscriberSignature}
+	}
+
+	// Register new wallet, a registered one is returned as it is
+	wallet, created, err := s.nuntiare.RegisterNewWallet(&models.Wallet{
 		Address:              req.Destination,
 		SubscriptionAddress:  req.Subscriber,
 		OriginID:             req.OriginID,
@@ -144,13 +328,35 @@ func (s *HTTPServer) register(c *gin.Context) {
 		Network:              req.Network,
 		OS:                   req.OS,
 		Lang:                 req.Lang,
-		CreatedAt:            time.Now().Unix(),
+		AppVersion:           req.AppVersion,
 		Active:               true,
 		Paid:                 false,
 		NotificationProvider: notificationProvider,
-	})
-
-	if err != nil {
+	}, subscriberProof)
+	switch {
+	case errors.Is(err, models.ErrOriginMismatch):
+		// Apps linked to the wallet update the same registration, other apps have to be linked first (see /wallet/origins)
+		s.logger.Warn("OriginID mismatch for wallet update", "destination", req.Destination)
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "Wallet is registered by another app, invalid " + fieldName(c, "originid"),
+		})
+		return
+	case errors.Is(err, models.ErrSubscriptionAddressTaken):
+		// Another user's wallets are paid from the address, joining them needs a proof of owning it
+		s.logger.Debug("Subscriber address used by another user's wallets", "destination", req.Destination, "subscriber", req.Subscriber)
+		c.JSON(http.StatusConflict, gin.H{
+			"success": false,
+			"error":   "The " + addressLabel(fieldName(c, "subscriber")) + " is used by another wallet, sign a challenge to prove you own it",
+			"code":    CodeSubscriberNotProven,
+		})
+		return
+	case errors.Is(err, models.ErrInvalidSignature):
+		s.logger.Debug("Invalid subscriber ownership proof", "error", err, "subscriber", req.Subscriber)
+		field := fieldName(c, "subscriber_signature")
+		respondValidationErrors(c, err.Error(), FieldError{Field: field, Code: CodeInvalid, Message: err.Error()})
+		return
+	case err != nil:
 		s.logger.Error("Failed to register wallet", "error", err, "destination", req.Destination)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"success": false,
@@ -159,14 +365,165 @@ func (s *HTTPServer) register(c *gin.Context) {
 		return
 	}
 
+	if !created {
+		// Registering again is a no-op, the stored wallet is returned as it is
+		s.logger.Info("Wallet already registered", "destination", req.Destination)
+		c.JSON(http.StatusOK, RegisterResponse{
+			Success:               true,
+			Message:               "Wallet already registered",
+			Address:               wallet.Address,
+			SubscriptionAddress:   wallet.SubscriptionAddress,
+			SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+		})
+		return
+	}
+
+	webhookSecret, discordCode, ok := s.setOptionalChannels(c, req)
+	if !ok {
+		return
+	}
+
 	// Success response
 	s.logger.Info("Wallet registered successfully", "destination", req.Destination, "origin", req.Origin)
 	c.JSON(http.StatusCreated, RegisterResponse{
 		Success:             true,
 		Message:             "Wallet registered successfully",
-		Address:             req.Destination,
-		SubscriptionAddress: req.Subscriber,
+		Address:             wallet.Address,
+		SubscriptionAddress: wallet.SubscriptionAddress,
+		WebhookSecret:       webhookSecret,
+
+		DiscordVerificationCode: discordCode,
+	})
+}
+
+// setOptionalChannels stores the webhook, Discord, SMS, Matrix, ntfy and Pushover destinations and the muted event types
+// of a registration request and returns the webhook signing secret and the Discord channel verification code.
+// It writes the error response and returns false on failure.
+func (s *HTTPServer) setOptionalChannels(c *gin.Context, req *RegisterRequest) (string, string, bool) {
+	var secret, discordCode string
+	if req.WebhookURL != "" {
+		var err error
+		secret, err = s.nuntiare.SetWebhook(req.Destination, req.WebhookURL)
+		if err != nil {
+			s.logger.Error("Failed to set webhook", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set webhook",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.DiscordWebhookURL != "" || req.DiscordChannelID != "" {
+		var err error
+		discordCode, err = s.nuntiare.SetDiscord(req.Destination, req.DiscordWebhookURL, req.DiscordChannelID)
+		if err != nil {
+			s.logger.Error("Failed to set discord", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set discord",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.Phone != "" {
+		if err := s.nuntiare.SetPhone(req.Destination, req.Phone); err != nil {
+			s.logger.Error("Failed to set phone", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set phone",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.MatrixRoomID != "" {
+		if err := s.nuntiare.SetMatrix(req.Destination, req.MatrixRoomID); err != nil {
+			s.logger.Error("Failed to set matrix room", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set matrix room",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.NtfyTopicURL != "" {
+		if err := s.nuntiare.SetNtfy(req.Destination, req.NtfyTopicURL); err != nil {
+			s.logger.Error("Failed to set ntfy topic", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set ntfy topic",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.PushoverUserKey != "" {
+		if err := s.nuntiare.SetPushover(req.Destination, req.PushoverUserKey); err != nil {
+			s.logger.Error("Failed to set Pushover user key", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set Pushover user key",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.MutedEvents != nil {
+		if err := s.nuntiare.SetMutedEventTypes(req.Destination, req.MutedEvents); err != nil {
+			s.logger.Error("Failed to set muted event types", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set muted event types",
+			})
+			return "", "", false
+		}
+	}
+
+	if req.MinAmount != nil {
+		if err := s.nuntiare.SetMinAmounts(req.Destination, req.MinAmount); err != nil {
+			s.logger.Error("Failed to set minimum amounts", "error", err, "destination", req.Destination)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"success": false,
+				"error":   "Failed to set minimum amounts",
+			})
+			return "", "", false
+		}
+	}
+	return secret, discordCode, true
+}
+
+// checkAppVersion responds with 426 Upgrade Required if the client app version is below
+// the minimum supported for its OS. Returns false if the request must not proceed.
+func (s *HTTPServer) checkAppVersion(c *gin.Context, os, appVersion string) bool {
+	err := s.nuntiare.CheckAppVersion(os, appVersion)
+	if err == nil {
+		return true
+	}
+	s.logger.Debug("Outdated app version", "os", os, "app_version", appVersion)
+	c.JSON(http.StatusUpgradeRequired, gin.H{
+		"success": false,
+		"error":   err.Error(),
+		"code":    CodeUpgradeRequired,
 	})
+	return false
+}
+
+// isSubscribedVersion is the conditionalGET version of an /is_subscribed response: the wallet fields it is built
+// from and whether the subscription is active now. The wallet is kept for the handler.
+func (s *HTTPServer) isSubscribedVersion(c *gin.Context) string {
+	address := c.Query("address")
+	if validation.ValidateAddress(address) != nil {
+		return ""
+	}
+	wallet, err := s.nuntiare.GetWallet(validation.NormalizeAddress(address))
+	if err != nil {
+		return ""
+	}
+	c.Set(cachedWalletKey, wallet)
+	return fmt.Sprintf("%s:%t:%d:%t", wallet.Address, wallet.Active, wallet.SubscriptionExpiresAt, s.nuntiare.SubscriptionActive(wallet))
 }
 
 // isSubscribed is a handler for the /is_subscribed endpoint.
@@ -174,26 +531,34 @@ func (s *HTTPServer) register(c *gin.Context) {
 func (s *HTTPServer) isSubscribed(c *gin.Context) {
 	address := c.Query("address")
 	if address == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "address is required"})
+		respondValidationErrors(c, "address is required",
+			FieldError{Field: "address", Code: validation.CodeRequired, Message: "address is required"})
 		return
 	}
 
 	// Validate address format
 	if err := validation.ValidateAddress(address); err != nil {
 		s.logger.Debug("Invalid address", "error", err, "address", address)
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid address format: " + err.Error()})
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
 		return
 	}
+	address = validation.NormalizeAddress(address)
 
-	wallet, err := s.nuntiare.GetWallet(address)
-	if err != nil {
-		// Check if it's a "not found" error
-		if strings.Contains(err.Error(), "record not found") {
-			c.JSON(http.StatusNotFound, gin.H{"error": "wallet not found"})
-		} else {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get wallet"})
+	// The wallet was read by isSubscribedVersion already
+	value, _ := c.Get(cachedWalletKey)
+	wallet, _ := value.(*models.Wallet)
+	if wallet == nil {
+		var err error
+		wallet, err = s.nuntiare.GetWallet(address)
+		if err != nil {
+			// Check if it's a "not found" error
+			if strings.Contains(err.Error(), "record not found") {
+				c.JSON(http.StatusNotFound, gin.H{"error": "wallet not found"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get wallet"})
+			}
+			return
 		}
-		return
 	}
 
 	// Wallet should never be nil here, but defensive check
@@ -221,9 +586,220 @@ func (s *HTTPServer) isSubscribed(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// status is a handler for the /status endpoint.
+// It returns coarse service health so client apps can show a "service degraded" banner. No auth required.
+func (s *HTTPServer) status(c *gin.Context) {
+	c.JSON(http.StatusOK, s.nuntiare.Status())
+}
+
+// pricing is a handler for the /pricing endpoint.
+// It returns the current subscription price so apps can show how long a payment lasts. No auth required.
+// The optional network query parameter (xcb or xab) selects the network, defaulting to the service's network.
+func (s *HTTPServer) pricing(c *gin.Context) {
+	network := c.Query("network")
+	if network != "" && network != "xcb" && network != "xab" {
+		respondValidationErrors(c, "Invalid network", FieldError{Field: "network", Code: CodeInvalid, Message: "must be xcb or xab"})
+		return
+	}
+	c.JSON(http.StatusOK, s.nuntiare.GetSubscriptionPricing(network))
+}
+
+// isSubscribedBatch is a handler for the /is_subscribed/batch endpoint.
+// It returns the subscription status of up to 100 addresses in one call.
+func (s *HTTPServer) isSubscribedBatch(c *gin.Context) {
+	var req BatchSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	var addressErrs []FieldError
+	for i, address := range req.Addresses {
+		if err := validation.ValidateAddress(address); err != nil {
+			addressErrs = append(addressErrs, addressError(fmt.Sprintf("addresses[%d]", i), err))
+			continue
+		}
+		req.Addresses[i] = validation.NormalizeAddress(address)
+	}
+	if len(addressErrs) > 0 {
+		respondValidationErrors(c, "Invalid address in "+addressErrs[0].Field+": "+addressErrs[0].Message, addressErrs...)
+		return
+	}
+
+	wallets, err := s.nuntiare.GetWallets(req.Addresses)
+	if err != nil {
+		s.logger.Error("Failed to get wallets", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get wallets"})
+		return
+	}
+	byAddress := make(map[string]*models.Wallet, len(wallets))
+	for _, wallet := range wallets {
+		byAddress[wallet.Address] = wallet
+	}
+
+	results := make([]BatchSubscriptionResult, 0, len(req.Addresses))
+	for _, address := range req.Addresses {
+		result := BatchSubscriptionResult{Address: address}
+		if wallet, ok := byAddress[address]; ok {
+			subscribed, err := s.nuntiare.CheckWalletSubscription(wallet)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get subscription"})
+				return
+			}
+			result.Found = true
+			result.Subscribed = subscribed
+			result.Active = wallet.Active
+			if subscribed {
+				result.ExpiresAt = wallet.SubscriptionExpiresAt
+			}
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, BatchSubscriptionResponse{Success: true, Results: results})
+}
+
+// walletDetails is a handler for the /wallet endpoint.
+// It returns wallet, subscription and notification channel state.
+// Requires the X-Origin-ID header or a session token (Authorization: Bearer).
+func (s *HTTPServer) walletDetails(c *gin.Context) {
+	address := c.Query("address")
+	if address == "" {
+		respondValidationErrors(c, "address is required",
+			FieldError{Field: "address", Code: validation.CodeRequired, Message: "address is required"})
+		return
+	}
+
+	if err := validation.ValidateAddress(address); err != nil {
+		s.logger.Debug("Invalid address", "error", err, "address", address)
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
+		return
+	}
+	address = validation.NormalizeAddress(address)
+
+	wallet, err := s.nuntiare.GetWallet(address)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "wallet not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get wallet"})
+		}
+		return
+	}
+
+	if !s.authorizedForWallet(c, wallet) {
+		s.logger.Warn("Unauthorized wallet details request", "address", address)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid originid or session token"})
+		return
+	}
+
+	subscribed, err := s.nuntiare.CheckWalletSubscription(wallet)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get subscription"})
+		return
+	}
+
+	provider, err := s.nuntiare.GetNotificationProvider(address)
+	if err != nil {
+		s.logger.Error("Failed to get notification provider", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get notification provider"})
+		return
+	}
+
+	response := WalletDetailsResponse{
+		Address:             wallet.Address,
+		SubscriptionAddress: wallet.SubscriptionAddress,
+		Network:             wallet.Network,
+		OS:                  wallet.OS,
+		Lang:                wallet.Lang,
+		Active:              wallet.Active,
+		Subscribed:          subscribed,
+		OwnershipProven:     wallet.OwnershipProven,
+		MutedEvents:         []string{},
+		MinAmount:           map[string]float64{},
+	}
+	for currency, amount := range wallet.MinAmounts {
+		response.MinAmount[currency] = amount
+	}
+	if provider.MutedEventTypes != "" {
+		response.MutedEvents = strings.Split(provider.MutedEventTypes, ",")
+	}
+	if subscribed {
+		response.ExpiresAt = wallet.SubscriptionExpiresAt
+	}
+	if tg := provider.TelegramProvider; tg.Username != "" {
+		response.Telegram = &TelegramChannelDetails{
+			Username:       tg.Username,
+			Connected:      tg.ChatID != "",
+			Disabled:       tg.Disabled,
+			DisabledReason: tg.DisabledReason,
+			DisabledAt:     tg.DisabledAt,
+		}
+	}
+	if email := provider.EmailProvider; email.Email != "" {
+		response.Email = &EmailChannelDetails{
+			Email:    email.Email,
+			Bounced:  email.Bounced,
+			Verified: email.Verified,
+		}
+	}
+	if fcm := provider.FCMProvider; fcm.Token != "" {
+		response.Push = &PushChannelDetails{
+			Disabled:       fcm.Disabled,
+			DisabledReason: fcm.DisabledReason,
+		}
+	}
+	if webhook := provider.WebhookProvider; webhook.URL != "" {
+		response.Webhook = &WebhookChannelDetails{URL: webhook.URL}
+	}
+	if discord := provider.DiscordProvider; discord.WebhookURL != "" || discord.ChannelID != "" {
+		response.Discord = &DiscordChannelDetails{
+			Webhook:        discord.WebhookURL != "",
+			ChannelID:      discord.ChannelID,
+			Unverified:     discord.WebhookURL == "" && !discord.ChannelVerified,
+			Disabled:       discord.Disabled,
+			DisabledReason: discord.DisabledReason,
+		}
+	}
+	if phone := provider.PhoneProvider; phone.Phone != "" {
+		response.SMS = &SMSChannelDetails{
+			Phone:          phone.Phone,
+			Disabled:       phone.Disabled,
+			DisabledReason: phone.DisabledReason,
+		}
+	}
+	if matrix := provider.MatrixProvider; matrix.RoomID != "" {
+		response.Matrix = &MatrixChannelDetails{
+			RoomID:         matrix.RoomID,
+			Disabled:       matrix.Disabled,
+			DisabledReason: matrix.DisabledReason,
+			InvitedBy:      matrix.InvitedBy,
+			JoinedAt:       matrix.JoinedAt,
+		}
+	}
+	if ntfy := provider.NtfyProvider; ntfy.TopicURL != "" {
+		response.Ntfy = &NtfyChannelDetails{
+			TopicURL:       ntfy.TopicURL,
+			Disabled:       ntfy.Disabled,
+			DisabledReason: ntfy.DisabledReason,
+		}
+	}
+	if pushover := provider.PushoverProvider; pushover.UserKey != "" {
+		response.Pushover = &PushoverChannelDetails{
+			UserKey:        pushover.UserKey,
+			Disabled:       pushover.Disabled,
+			DisabledReason: pushover.DisabledReason,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // handleTelegramWebhook processes incoming Telegram webhook updates
 func (s *HTTPServer) handleTelegramWebhook(c *gin.Context) {
-	var update interface{}
+	var update tgModels.Update
 
 	if err := c.ShouldBindJSON(&update); err != nil {
 		s.logger.Debug("Invalid webhook payload", "error", err)
@@ -231,7 +807,12 @@ func (s *HTTPServer) handleTelegramWebhook(c *gin.Context) {
 		return
 	}
 
-	if err := s.nuntiare.ProcessTelegramWebhook(update); err != nil {
+	if err := s.nuntiare.ProcessTelegramWebhook(c.GetHeader("X-Telegram-Bot-Api-Secret-Token"), &update); err != nil {
+		if errors.Is(err, models.ErrUnauthorized) {
+			s.logger.Warn("Invalid Telegram webhook secret token", "ip", c.ClientIP())
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid secret token"})
+			return
+		}
 		s.logger.Error("Failed to process Telegram update", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "processing failed"})
 		return
@@ -240,6 +821,31 @@ func (s *HTTPServer) handleTelegramWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
+// handleEmailWebhook ingests delivery/bounce events from email providers (sendgrid, ses, mailgun)
+func (s *HTTPServer) handleEmailWebhook(c *gin.Context) {
+	provider := c.Param("provider")
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, MaxWebhookBodySize))
+	if err != nil {
+		s.logger.Debug("Failed to read email webhook body", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	if err := s.nuntiare.ProcessEmailWebhook(provider, c.Query("token"), body); err != nil {
+		if errors.Is(err, models.ErrUnauthorized) {
+			s.logger.Warn("Email webhook with invalid token", "provider", provider)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+			return
+		}
+		s.logger.Error("Failed to process email webhook", "provider", provider, "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "processing failed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"ok": true})
+}
+
 // cancel is a handler for the /cancel endpoint.
 // It deactivates notifications while keeping the subscription active.
 func (s *HTTPServer) cancel(c *gin.Context) {
@@ -247,22 +853,23 @@ func (s *HTTPServer) cancel(c *gin.Context) {
 
 	if err := c.ShouldBindJSON(&req); err != nil {
 		s.logger.Debug("Invalid request body", "error", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid request body: " + err.Error(),
-		})
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
 		return
 	}
 
+	s.cancelWallet(c, &req)
+}
+
+// cancelWallet validates a bound cancel request and deactivates the wallet's notifications
+func (s *HTTPServer) cancelWallet(c *gin.Context, req *CancelRequest) {
 	// Validate address format
 	if err := validation.ValidateAddress(req.Destination); err != nil {
 		s.logger.Debug("Invalid destination address", "error", err, "address", req.Destination)
-		c.JSON(http.StatusBadRequest, gin.H{
-			"success": false,
-			"error":   "Invalid destination address: " + err.Error(),
-		})
+		field := fieldName(c, "destination")
+		respondValidationErrors(c, "Invalid "+addressLabel(field)+": "+err.Error(), addressError(field, err))
 		return
 	}
+	req.Destination = validation.NormalizeAddress(req.Destination)
 
 	// Get wallet
 	wallet, err := s.nuntiare.GetWallet(req.Destination)
@@ -282,15 +889,21 @@ func (s *HTTPServer) cancel(c *gin.Context) {
 	}
 
 	// Verify OriginID
-	if wallet.OriginID != req.OriginID {
+	if !s.nuntiare.IsWalletOrigin(wallet, req.OriginID) {
 		s.logger.Warn("OriginID mismatch for wallet cancel", "destination", req.Destination)
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"success": false,
-			"error":   "Invalid originid",
+			"error":   "Invalid " + fieldName(c, "originid"),
 		})
 		return
 	}
 
+	// A linked app cancelling only unlinks itself, the notifications keep going to the other apps
+	if req.OriginID != wallet.OriginID {
+		s.unlinkCancellingOrigin(c, wallet, req.OriginID)
+		return
+	}
+
 	// Cancel (deactivate) wallet
 	err = s.nuntiare.CancelWallet(req.Destination)
 	if err != nil {
@@ -0,0 +1,96 @@
+package http_api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// UnreadCountResponse represents the number of unread notifications of a wallet
+type UnreadCountResponse struct {
+	Success     bool  `json:"success"`
+	UnreadCount int64 `json:"unread_count"`
+}
+
+// markNotificationRead is a handler for the POST /notifications/:id/read endpoint.
+// It marks a notification as read in the app inbox of the wallet it was sent to.
+func (s *HTTPServer) markNotificationRead(c *gin.Context) {
+	id := c.Param("id")
+
+	notification, err := s.nuntiare.GetNotification(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Notification not found"})
+		} else {
+			s.logger.Error("Failed to get notification", "error", err, "id", id)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get notification"})
+		}
+		return
+	}
+
+	wallet := s.authorizedWallet(c, notification.Wallet)
+	if wallet == nil {
+		return
+	}
+
+	found, err := s.nuntiare.MarkNotificationRead(wallet.Address, id)
+	if err != nil {
+		s.logger.Error("Failed to mark notification read", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to mark notification read"})
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Notification not found"})
+		return
+	}
+
+	s.respondUnreadCount(c, wallet.Address)
+}
+
+// unreadCount is a handler for the GET /notifications/unread_count endpoint.
+// It returns the number of notifications of the wallet that haven't been read in the app.
+func (s *HTTPServer) unreadCount(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	s.respondUnreadCount(c, wallet.Address)
+}
+
+func (s *HTTPServer) respondUnreadCount(c *gin.Context, address string) {
+	count, err := s.nuntiare.CountUnreadNotifications(address)
+	if err != nil {
+		s.logger.Error("Failed to count unread notifications", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to count unread notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, UnreadCountResponse{Success: true, UnreadCount: count})
+}
+
+// notificationHistory is a handler for the GET /notifications endpoint.
+// It returns a page of the notifications sent to the wallet, newest first, one entry per channel.
+func (s *HTTPServer) notificationHistory(c *gin.Context) {
+	opts, fieldErr := parseListOptions(c, models.NotificationHistoryListFields)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	page, err := s.nuntiare.ListNotificationHistory(wallet.Address, opts)
+	if err != nil {
+		s.logger.Error("Failed to list notification history", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to list notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newListResponse(page, opts))
+}
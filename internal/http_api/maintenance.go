@@ -0,0 +1,67 @@
+package http_api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readOnlyAllowedRoutes are the routes with a mutating method that only read, or that switch read-only mode
+// itself, and are served while read-only
+var readOnlyAllowedRoutes = map[string]bool{
+	"/api/v1/is_subscribed/batch":     true,
+	"/api/v2/is_subscribed/batch":     true,
+	"/api/v2/session":                 true,
+	"/api/v1/admin/templates/preview": true,
+	"/api/v1/admin/maintenance":       true,
+}
+
+// MaintenanceRequest represents the JSON body for switching read-only mode
+type MaintenanceRequest struct {
+	ReadOnly *bool `json:"read_only" binding:"required"`
+}
+
+// readOnlyMiddleware rejects requests that change data with 503 and a Retry-After header while the API is
+// read-only. Queries keep working, block processing is not affected.
+func (s *HTTPServer) readOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.nuntiare.IsReadOnly() || readOnlyAllowedRoutes[c.FullPath()] {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(int(s.readOnlyRetryAfter.Seconds())))
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"success": false, "error": "service is in read-only maintenance mode, try again later"})
+	}
+}
+
+// getMaintenance is a handler for the GET /admin/maintenance endpoint.
+// It returns whether the API is read-only.
+func (s *HTTPServer) getMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"success": true, "read_only": s.nuntiare.IsReadOnly()})
+}
+
+// setMaintenance is a handler for the PUT /admin/maintenance endpoint.
+// It switches read-only mode of all instances.
+func (s *HTTPServer) setMaintenance(c *gin.Context) {
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	if err := s.nuntiare.SetReadOnly(*req.ReadOnly); err != nil {
+		s.logger.Error("Failed to switch maintenance mode", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to switch maintenance mode"})
+		return
+	}
+	s.logger.Warn("Read-only maintenance mode switched", "read_only", *req.ReadOnly, "ip", c.ClientIP())
+	c.JSON(http.StatusOK, gin.H{"success": true, "read_only": s.nuntiare.IsReadOnly()})
+}
@@ -0,0 +1,72 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// MessageTemplateRequest represents the JSON body for overriding a message template
+type MessageTemplateRequest struct {
+	Body string `json:"body" binding:"required,max=8192"`
+}
+
+// listMessageTemplates is a handler for the GET /admin/templates endpoint.
+// It returns the stored message template overrides.
+func (s *HTTPServer) listMessageTemplates(c *gin.Context) {
+	messageTemplates, err := s.nuntiare.GetMessageTemplates()
+	if err != nil {
+		s.logger.Error("Failed to get message templates", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get message templates"})
+		return
+	}
+	if messageTemplates == nil {
+		messageTemplates = []*models.MessageTemplate{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "templates": messageTemplates})
+}
+
+// setMessageTemplate is a handler for the PUT /admin/templates/:lang/:name endpoint.
+// It overrides the message template of a language after checking it renders all sample notifications.
+func (s *HTTPServer) setMessageTemplate(c *gin.Context) {
+	var req MessageTemplateRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	messageTemplate, err := s.nuntiare.SetMessageTemplate(c.Param("lang"), c.Param("name"), req.Body)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidMessageTemplate) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "body", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to set message template", "error", err, "lang", c.Param("lang"), "name", c.Param("name"))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to set message template"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "template": messageTemplate})
+}
+
+// deleteMessageTemplate is a handler for the DELETE /admin/templates/:lang/:name endpoint.
+// It removes the override so the embedded template is used again.
+func (s *HTTPServer) deleteMessageTemplate(c *gin.Context) {
+	deleted, err := s.nuntiare.DeleteMessageTemplate(c.Param("lang"), c.Param("name"))
+	if err != nil {
+		s.logger.Error("Failed to delete message template", "error", err, "lang", c.Param("lang"), "name", c.Param("name"))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to delete message template"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "message template override not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,48 @@
+package http_api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/pkg/nats"
+)
+
+// NATSRegistrationPath is the endpoint registration requests received from NATS are handled by
+const NATSRegistrationPath = "/api/v2/subscription"
+
+// newRegistrationConsumer returns the NATS client registration requests are consumed with, or nil when it
+// isn't configured. Shadow instances don't consume, the requests are left to production.
+func (s *HTTPServer) newRegistrationConsumer(cfg *config.Config) *nats.Client {
+	if cfg.NATSServers == "" || cfg.NATSRegistrationSubject == "" || cfg.ShadowMode {
+		return nil
+	}
+	natsConfig := cfg.NATSConfig()
+	natsConfig.ErrorHandler = func(err error) {
+		s.logger.Warn("NATS connection lost, reconnecting", "error", err)
+	}
+	client := nats.NewClient(natsConfig)
+	client.Subscribe(cfg.NATSRegistrationSubject, cfg.NATSQueueGroup, s.handleRegistrationMessage)
+	return client
+}
+
+// handleRegistrationMessage handles a registration request received from NATS like a POST to the v2
+// /subscription endpoint, so it is validated the same way, and replies with the response body
+func (s *HTTPServer) handleRegistrationMessage(msg *nats.Msg) {
+	request, err := http.NewRequest(http.MethodPost, NATSRegistrationPath, bytes.NewReader(msg.Data))
+	if err != nil {
+		s.logger.Error("Failed to create NATS registration request", "error", err)
+		return
+	}
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	s.router.ServeHTTP(response, request)
+
+	if response.Code >= http.StatusBadRequest {
+		s.logger.Warn("NATS registration request rejected", "subject", msg.Subject, "status", response.Code)
+	}
+	if err := s.registrations.Respond(msg, response.Body.Bytes()); err != nil {
+		s.logger.Warn("Failed to reply to NATS registration request", "error", err, "reply", msg.Reply)
+	}
+}
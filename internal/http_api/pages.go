@@ -0,0 +1,217 @@
+package http_api
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// notificationPageTemplate renders the hosted notification detail page
+var notificationPageTemplate = template.Must(template.New("notification").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f4f5f7; color: #1d1f23; margin: 0; padding: 24px; }
+.card { max-width: 560px; margin: 0 auto; background: #fff; border-radius: 12px; padding: 24px; box-shadow: 0 1px 4px rgba(0,0,0,.08); }
+h1 { font-size: 20px; margin: 0 0 16px; }
+dl { margin: 0; }
+dt { font-size: 12px; color: #6b7280; text-transform: uppercase; margin-top: 12px; }
+dd { margin: 4px 0 0; word-break: break-all; }
+a { color: #2563eb; }
+img { max-width: 100%; border-radius: 8px; margin-bottom: 16px; }
+.verified { color: #0e7c3a; font-weight: 600; }
+.caution { background: #fff8e1; border: 1px solid #f0c36d; border-radius: 6px; color: #8a5a00; padding: 8px 10px; margin-bottom: 16px; }
+</style>
+</head>
+<body>
+<div class="card">
+<h1>{{.Title}}</h1>
+{{if .N.LookalikeToken}}<div class="caution">&#9888; Caution: this {{.N.Currency}} is not the verified token with this symbol. Check the token contract before trusting it.</div>
+{{end}}{{if .ImageURL}}<img src="{{.ImageURL}}" alt="{{.N.Currency}} #{{.N.DisplayTokenID}}">{{end}}
+<dl>
+{{if gt (len .N.Transfers) 1}}<dt>Transfers</dt>{{range .N.Transfers}}
+<dd>{{if eq .TokenType "CBC721"}}{{.Currency}} #{{.DisplayTokenID}}{{else}}{{.FormattedAmount}} {{.Currency}}{{end}}{{if .LookalikeToken}} (&#9888; not the verified {{.Currency}}){{end}}{{if .From}} from {{if .VerifiedSender}}{{.VerifiedSender}} <span class="verified">&#10003;</span> {{end}}<a href="{{$.N.AddressLink .From}}">{{.From}}</a>{{end}}</dd>{{end}}
+{{else if .IsNFT}}<dt>Token</dt><dd>{{.N.Currency}} #{{.N.DisplayTokenID}}</dd>
+{{else}}<dt>Amount</dt><dd>{{.N.FormattedAmount}} {{.N.Currency}}</dd>
+{{end}}{{if and .N.TokenAddress (le (len .N.Transfers) 1)}}<dt>Token contract</dt><dd><a href="{{.N.AddressLink .N.TokenAddress}}">{{.N.TokenAddress}}</a></dd>
+{{end}}{{if .N.From}}<dt>From</dt><dd>{{if .N.VerifiedSender}}{{.N.VerifiedSender}} <span class="verified">&#10003; Verified</span><br>{{end}}<a href="{{.N.AddressLink .N.From}}">{{.N.From}}</a></dd>
+{{end}}<dt>To</dt><dd><a href="{{.N.AddressLink .N.Wallet}}">{{.N.Wallet}}</a></dd>
+<dt>Transaction</dt><dd><a href="{{.N.TxLink}}">{{.N.TxHash}}</a></dd>
+<dt>Time</dt><dd>{{.Time}}</dd>
+{{if .N.Reference}}<dt>Reference</dt><dd>{{.N.Reference}}</dd>
+{{end}}</dl>
+</div>
+</body>
+</html>
+`))
+
+// notificationPage is the data passed to notificationPageTemplate
+type notificationPage struct {
+	N        *models.Notification
+	Title    string
+	IsNFT    bool
+	ImageURL string
+	Time     string
+}
+
+// notificationDetails is a handler for the /n/:id endpoint.
+// It renders a minimal HTML page with full transfer details, linked from short-form channels.
+func (s *HTTPServer) notificationDetails(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.String(http.StatusNotFound, "Notification not found")
+		return
+	}
+
+	notification, err := s.nuntiare.GetNotification(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.String(http.StatusNotFound, "Notification not found")
+		} else {
+			s.logger.Error("Failed to get notification", "error", err, "id", id)
+			c.String(http.StatusInternalServerError, "Failed to get notification")
+		}
+		return
+	}
+
+	page := notificationPage{
+		N:     notification,
+		IsNFT: notification.TokenType == "CBC721",
+		Time:  time.Unix(notification.CreatedAt, 0).UTC().Format("2006-01-02 15:04:05 MST"),
+	}
+
+	if len(notification.Transfers) > 1 {
+		page.Title = fmt.Sprintf("Received %d transfers", len(notification.Transfers))
+	} else if page.IsNFT {
+		page.Title = "Received NFT " + notification.Currency
+		imageURL, err := s.nuntiare.GetNFTImageURL(notification.TokenAddress, notification.TokenID)
+		if err != nil {
+			s.logger.Debug("Failed to get NFT image", "error", err, "token", notification.TokenAddress)
+		}
+		page.ImageURL = imageURL
+	} else {
+		page.Title = "Received " + notification.FormattedAmount() + " " + notification.Currency
+	}
+	if notification.Internal && len(notification.Transfers) <= 1 {
+		page.Title = strings.Replace(page.Title, "Received", "Internal transfer of", 1)
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := notificationPageTemplate.Execute(c.Writer, page); err != nil {
+		s.logger.Error("Failed to render notification page", "error", err, "id", id)
+	}
+}
+
+// paymentRequestPageTemplate renders the shareable payment request page
+var paymentRequestPageTemplate = template.Must(template.New("payment_request").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; background: #f4f5f7; color: #1d1f23; margin: 0; padding: 24px; }
+.card { max-width: 560px; margin: 0 auto; background: #fff; border-radius: 12px; padding: 24px; box-shadow: 0 1px 4px rgba(0,0,0,.08); }
+h1 { font-size: 20px; margin: 0 0 16px; }
+dl { margin: 0; }
+dt { font-size: 12px; color: #6b7280; text-transform: uppercase; margin-top: 12px; }
+dd { margin: 4px 0 0; word-break: break-all; }
+.status { border-radius: 6px; padding: 8px 10px; margin-bottom: 16px; }
+.paid { background: #e7f6ec; color: #0e7c3a; }
+.closed { background: #f3f4f6; color: #6b7280; }
+</style>
+</head>
+<body>
+<div class="card">
+<h1>{{.Title}}</h1>
+{{if eq .R.Status "fulfilled"}}<div class="status paid">&#10003; Paid {{.PaidAt}}</div>
+{{else if .Closed}}<div class="status closed">This payment request is {{.Closed}}.</div>
+{{end}}<dl>
+{{if .R.Memo}}<dt>For</dt><dd>{{.R.Memo}}</dd>
+{{end}}<dt>Amount</dt><dd>{{if .R.FormattedAmount}}{{.R.FormattedAmount}} {{.R.Currency}}{{else}}Any amount of {{.R.Currency}}{{end}}</dd>
+{{if ne .R.Token "xcb"}}<dt>Token contract</dt><dd>{{.R.Token}}</dd>
+{{end}}<dt>Pay to</dt><dd>{{.R.WalletAddress}}</dd>
+{{if .ExpiresAt}}<dt>Expires</dt><dd>{{.ExpiresAt}}</dd>
+{{end}}</dl>
+</div>
+</body>
+</html>
+`))
+
+// paymentRequestPage is the data passed to paymentRequestPageTemplate
+type paymentRequestPage struct {
+	R         *models.PaymentRequest
+	Title     string
+	Closed    string
+	PaidAt    string
+	ExpiresAt string
+}
+
+// paymentRequestDetails is a handler for the /pay/:id endpoint.
+// It renders the payment request shared with the payer.
+func (s *HTTPServer) paymentRequestDetails(c *gin.Context) {
+	id := c.Param("id")
+
+	request, err := s.nuntiare.GetPaymentRequest(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.String(http.StatusNotFound, "Payment request not found")
+		} else {
+			s.logger.Error("Failed to get payment request", "error", err, "id", id)
+			c.String(http.StatusInternalServerError, "Failed to get payment request")
+		}
+		return
+	}
+
+	page := paymentRequestPage{R: request, Title: "Payment request"}
+	if request.FormattedAmount() != "" {
+		page.Title = "Payment request: " + request.FormattedAmount() + " " + request.Currency
+	}
+	switch {
+	case request.Status == models.PaymentRequestFulfilled:
+		page.PaidAt = time.Unix(request.PaidAt, 0).UTC().Format("2006-01-02 15:04:05 MST")
+	case request.Status == models.PaymentRequestCancelled:
+		page.Closed = "cancelled"
+	case request.Expired(time.Now()):
+		page.Closed = "expired"
+	}
+	if request.ExpiresAt > 0 {
+		page.ExpiresAt = time.Unix(request.ExpiresAt, 0).UTC().Format("2006-01-02 15:04:05 MST")
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := paymentRequestPageTemplate.Execute(c.Writer, page); err != nil {
+		s.logger.Error("Failed to render payment request page", "error", err, "id", id)
+	}
+}
+
+// shortLinkRedirect is a handler for the /s/:code endpoint.
+// It redirects to the target URL of the short link and counts the click.
+func (s *HTTPServer) shortLinkRedirect(c *gin.Context) {
+	code := c.Param("code")
+
+	url, err := s.nuntiare.ResolveShortLink(code)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.String(http.StatusNotFound, "Link not found")
+		} else {
+			s.logger.Error("Failed to resolve short link", "error", err, "code", code)
+			c.String(http.StatusInternalServerError, "Failed to resolve link")
+		}
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
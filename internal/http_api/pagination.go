@@ -0,0 +1,103 @@
+package http_api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// PaginationInfo describes the position of a page in a list response
+type PaginationInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// ListResponse is the response envelope of list endpoints
+type ListResponse[T any] struct {
+	Success    bool           `json:"success"`
+	Data       []*T           `json:"data"`
+	Pagination PaginationInfo `json:"pagination"`
+}
+
+// newListResponse wraps a page into the list response envelope
+func newListResponse[T any](page *models.Page[T], opts models.ListOptions) ListResponse[T] {
+	data := page.Items
+	if data == nil {
+		data = []*T{}
+	}
+	return ListResponse[T]{
+		Success: true,
+		Data:    data,
+		Pagination: PaginationInfo{
+			Limit:      opts.Limit,
+			NextCursor: page.NextCursor,
+			HasMore:    page.NextCursor != "",
+		},
+	}
+}
+
+// parseListOptions reads the limit, cursor, sort and filter query parameters of a list request.
+// Limits above MaxListLimit are capped; unknown sort fields and malformed values are rejected.
+func parseListOptions(c *gin.Context, fields models.ListFields) (models.ListOptions, *FieldError) {
+	opts := models.ListOptions{
+		Limit:   models.DefaultListLimit,
+		Filters: make(map[string]any),
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return opts, &FieldError{Field: "limit", Code: CodeInvalidValue, Message: "limit must be a positive integer"}
+		}
+		opts.Limit = min(limit, models.MaxListLimit)
+	}
+
+	sort := c.DefaultQuery("sort", fields.DefaultSort)
+	opts.Sort = strings.TrimPrefix(sort, "-")
+	opts.Desc = strings.HasPrefix(sort, "-")
+	if _, ok := fields.Sort[opts.Sort]; !ok {
+		return opts, &FieldError{Field: "sort", Code: CodeInvalidValue, Message: fmt.Sprintf("unsupported sort field %q", opts.Sort)}
+	}
+
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		cursor, err := models.DecodeCursor(cursorStr)
+		if err == nil && cursor.Sort != sort {
+			err = models.ErrInvalidCursor
+		}
+		if err != nil {
+			return opts, &FieldError{Field: "cursor", Code: CodeInvalidValue, Message: err.Error()}
+		}
+		opts.Cursor = cursor
+	}
+
+	for name, filter := range fields.Filters {
+		value, ok := c.GetQuery(name)
+		if !ok {
+			continue
+		}
+		switch filter.Type {
+		case models.FilterBool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, &FieldError{Field: name, Code: CodeInvalidValue, Message: name + " must be true or false"}
+			}
+			opts.Filters[name] = b
+		case models.FilterAddress:
+			address, err := validation.ValidateAndNormalizeAddress(value)
+			if err != nil {
+				fieldErr := addressError(name, err)
+				return opts, &fieldErr
+			}
+			opts.Filters[name] = address
+		default:
+			opts.Filters[name] = value
+		}
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,94 @@
+package http_api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// partnerOriginKey is the context key of the originator authenticated by partnerMiddleware
+	partnerOriginKey = "partner_origin"
+	// MaxPartnerMetricsRange is the longest time range of a partner metrics request
+	MaxPartnerMetricsRange = 90 * 24 * time.Hour
+)
+
+// PartnerMetricsResponse represents the delivery metrics and quota consumption of a partner's wallets
+type PartnerMetricsResponse struct {
+	Success bool                  `json:"success"`
+	Origin  string                `json:"origin"`
+	From    int64                 `json:"from"`
+	To      int64                 `json:"to"`
+	Metrics *models.OriginMetrics `json:"metrics"`
+	Quota   *models.OriginQuota   `json:"quota,omitempty"` // Omitted when no quota is configured for the originator
+}
+
+// partnerMiddleware authenticates partner apps by the PARTNER_API_KEYS key of their originator
+func (s *HTTPServer) partnerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		origin, ok := s.nuntiare.PartnerOrigin(key)
+		if !ok {
+			s.logger.Warn("Invalid partner key", "path", c.FullPath(), "ip", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid partner key"})
+			return
+		}
+
+		c.Set(partnerOriginKey, origin)
+		c.Next()
+	}
+}
+
+// partnerMetrics is a handler for the /partner/metrics endpoint.
+// It returns the delivery metrics of the wallets registered by the partner's originator and its quota consumption.
+func (s *HTTPServer) partnerMetrics(c *gin.Context) {
+	origin := c.GetString(partnerOriginKey)
+
+	now := time.Now()
+	to, fieldErr := unixQuery(c, "to", now.Unix())
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	from, fieldErr := unixQuery(c, "from", to-int64(DefaultStatsRange.Seconds()))
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+	if from >= to {
+		message := "from must be before to"
+		respondValidationErrors(c, message, FieldError{Field: "from", Code: CodeInvalidValue, Message: message})
+		return
+	}
+	if to-from > int64(MaxPartnerMetricsRange.Seconds()) {
+		message := fmt.Sprintf("time range must not exceed %d days", int(MaxPartnerMetricsRange.Hours()/24))
+		respondValidationErrors(c, message, FieldError{Field: "from", Code: CodeInvalidValue, Message: message})
+		return
+	}
+
+	metrics, err := s.nuntiare.GetOriginMetrics(origin, from, to)
+	if err != nil {
+		s.logger.Error("Failed to get origin metrics", "error", err, "origin", origin)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get metrics"})
+		return
+	}
+	quota, err := s.nuntiare.GetOriginQuota(origin)
+	if err != nil {
+		s.logger.Error("Failed to get origin quota", "error", err, "origin", origin)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get metrics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, PartnerMetricsResponse{
+		Success: true,
+		Origin:  origin,
+		From:    from,
+		To:      to,
+		Metrics: metrics,
+		Quota:   quota,
+	})
+}
@@ -0,0 +1,136 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentRequestRequest represents the JSON body for creating a payment request
+type PaymentRequestRequest struct {
+	Address   string  `json:"address" binding:"required"`
+	Token     string  `json:"token"`      // "xcb" (default) or the CBC20 contract address
+	Amount    float64 `json:"amount"`     // 0 for any amount
+	Memo      string  `json:"memo"`       // Shown to the payer and in the notifications
+	ExpiresIn int64   `json:"expires_in"` // Seconds, 0 for never
+}
+
+// PaymentRequestResponse represents a payment request and its link
+type PaymentRequestResponse struct {
+	Success bool                   `json:"success"`
+	Request *models.PaymentRequest `json:"request"`
+	URL     string                 `json:"url"`
+}
+
+// PaymentRequestsResponse represents the payment requests of a wallet
+type PaymentRequestsResponse struct {
+	Success  bool                     `json:"success"`
+	Requests []*models.PaymentRequest `json:"requests"`
+}
+
+// createPaymentRequest is a handler for the POST /payment_requests endpoint.
+// It creates a shareable request to pay the wallet, fulfilled by the matching transfer.
+func (s *HTTPServer) createPaymentRequest(c *gin.Context) {
+	var req PaymentRequestRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+	if req.Token == "" {
+		req.Token = models.NativeTokenPreference
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	request, err := s.nuntiare.CreatePaymentRequest(wallet.Address, req.Token, req.Amount, req.Memo, req.ExpiresIn)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidPaymentRequest) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: paymentRequestErrorField(err), Code: CodeInvalidValue, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to create payment request", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to create payment request"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, PaymentRequestResponse{Success: true, Request: request, URL: s.nuntiare.PaymentRequestURL(request)})
+}
+
+// paymentRequestErrorField returns the request field an ErrInvalidPaymentRequest error is about
+func paymentRequestErrorField(err error) string {
+	message := err.Error()
+	for _, field := range []string{"token", "amount", "memo", "expires_in"} {
+		if strings.Contains(message, ": "+field) {
+			return field
+		}
+	}
+	return "address"
+}
+
+// listPaymentRequests is a handler for the GET /payment_requests endpoint.
+// It returns the latest payment requests of the wallet.
+func (s *HTTPServer) listPaymentRequests(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	requests, err := s.nuntiare.GetPaymentRequests(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to get payment requests", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get payment requests"})
+		return
+	}
+	if requests == nil {
+		requests = []*models.PaymentRequest{}
+	}
+
+	c.JSON(http.StatusOK, PaymentRequestsResponse{Success: true, Requests: requests})
+}
+
+// getPaymentRequest is a handler for the GET /payment_requests/:id endpoint.
+// It returns a payment request and whether it was fulfilled. The ID is the capability, no auth is needed.
+func (s *HTTPServer) getPaymentRequest(c *gin.Context) {
+	request, err := s.nuntiare.GetPaymentRequest(c.Param("id"))
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Payment request not found"})
+		} else {
+			s.logger.Error("Failed to get payment request", "error", err, "id", c.Param("id"))
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get payment request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, PaymentRequestResponse{Success: true, Request: request, URL: s.nuntiare.PaymentRequestURL(request)})
+}
+
+// cancelPaymentRequest is a handler for the DELETE /payment_requests/:id endpoint.
+// It cancels an open payment request of the wallet.
+func (s *HTTPServer) cancelPaymentRequest(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	cancelled, err := s.nuntiare.CancelPaymentRequest(wallet.Address, c.Param("id"))
+	if err != nil {
+		s.logger.Error("Failed to cancel payment request", "error", err, "id", c.Param("id"))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to cancel payment request"})
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Open payment request not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -2,8 +2,137 @@ package http_api
 
 // routes sets up the routes for the HTTP server.
 func (s *HTTPServer) routes() {
-	s.router.POST("/api/v1/subscription", s.register)
-	s.router.GET("/api/v1/is_subscribed", s.isSubscribed)
-	s.router.POST("/api/v1/cancel", s.cancel)
+	// v1 public endpoints are deprecated in favour of v2
+	v1 := s.router.Group("/api/v1", deprecationMiddleware(s.v1Sunset))
+	v1.POST("/subscription", s.register)
+	v1.GET("/is_subscribed", conditionalGET(IsSubscribedCacheMaxAge, s.isSubscribedVersion), s.isSubscribed)
+	v1.POST("/is_subscribed/batch", s.isSubscribedBatch)
+	v1.GET("/wallet", s.walletDetails)
+	v1.GET("/status", s.status)
+	v1.POST("/cancel", s.cancel)
+	v1.POST("/notifications/:id/read", s.markNotificationRead)
+	v1.GET("/notifications/unread_count", s.unreadCount)
+	v1.GET("/notifications", s.notificationHistory)
+
+	// v2 uses consistent field naming (origin_id, address, subscription_address)
+	v2 := s.router.Group("/api/v2", v2Fields())
+	v2.POST("/subscription", s.registerV2)
+	v2.GET("/is_subscribed", conditionalGET(IsSubscribedCacheMaxAge, s.isSubscribedVersion), s.isSubscribed)
+	v2.POST("/is_subscribed/batch", s.isSubscribedBatch)
+	v2.GET("/wallet", s.walletDetails)
+	v2.GET("/status", s.status)
+	// The price is kept in memory, the response is cheap to build and hashed for its ETag
+	v2.GET("/pricing", conditionalGET(PricingCacheMaxAge, nil), s.pricing)
+	v2.POST("/cancel", s.cancelV2)
+	v2.POST("/subscription/transfer", s.transferSubscription)
+	v2.POST("/wallet/link_token", s.createLinkToken)
+	v2.POST("/wallet/origins", s.linkOrigin)
+	v2.GET("/wallet/origins", s.listWalletOrigins)
+	v2.DELETE("/wallet/origins/:origin", s.unlinkOrigin)
+	v2.POST("/wallet/ownership/challenge", s.createOwnershipChallenge)
+	v2.POST("/wallet/ownership", s.proveOwnership)
+	v2.POST("/subscription_address/challenge", s.createSubscriberChallenge)
+	v2.GET("/wallet/export", s.exportWalletData)
+	v2.POST("/wallet/user", s.joinUser)
+	v2.GET("/wallet/user", s.getUser)
+	v2.DELETE("/wallet/user", s.leaveUser)
+	v2.PUT("/wallet/tokens", s.setTokenPreference)
+	v2.GET("/wallet/tokens", s.listTokenPreferences)
+	v2.DELETE("/wallet/tokens/:token", s.removeTokenPreference)
+	v2.GET("/wallet/webhook/events/:id", s.getWebhookEvent)
+	v2.POST("/wallet/webhook/events/:id/replay", s.replayWebhookEvent)
+	v2.GET("/automation/notifications", s.automationNotifications)
+	v2.POST("/automation/hooks", s.subscribeAutomationHook)
+	v2.GET("/automation/hooks", s.listAutomationHooks)
+	v2.DELETE("/automation/hooks/:id", s.unsubscribeAutomationHook)
+	v2.PUT("/wallet/widget", s.setWidgetFeed)
+	v2.GET("/wallet/widget", s.getWidgetFeed)
+	v2.DELETE("/wallet/widget", s.removeWidgetFeed)
+	v2.POST("/wallet/discord/verify", s.verifyDiscord)
+	v2.POST("/payment_requests", s.createPaymentRequest)
+	v2.GET("/payment_requests", s.listPaymentRequests)
+	v2.GET("/payment_requests/:id", s.getPaymentRequest)
+	v2.DELETE("/payment_requests/:id", s.cancelPaymentRequest)
+	v2.POST("/notifications/:id/read", s.markNotificationRead)
+	v2.GET("/notifications/unread_count", s.unreadCount)
+	v2.GET("/notifications", s.notificationHistory)
+	v2.POST("/session", s.createSession)
+	v2.PUT("/devices", s.registerDevice)
+	v2.GET("/devices", s.listDevices)
+	v2.DELETE("/devices/:device_id", s.removeDevice)
+	v2.GET("/webpush/key", s.webPushKey)
+	v2.PUT("/webpush/subscriptions", s.subscribeWebPush)
+	v2.GET("/webpush/subscriptions", s.listWebPushSubscriptions)
+	v2.DELETE("/webpush/subscriptions", s.unsubscribeWebPush)
+
+	// Partner endpoints (require the originator's PARTNER_API_KEYS key)
+	partner := v2.Group("/partner", s.partnerMiddleware())
+	partner.GET("/metrics", s.partnerMetrics)
+
+	// Exchange endpoints (require the exchange's API key)
+	exchange := v2.Group("/exchange", s.exchangeMiddleware())
+	exchange.POST("/addresses", s.registerExchangeAddresses)
+	exchange.DELETE("/addresses/:address", s.removeExchangeAddress)
+	exchange.GET("/deposits", s.listExchangeDeposits)
+	exchange.POST("/deposits/:id/credit", s.creditExchangeDeposit)
+	exchange.GET("/reconciliation", s.exchangeReconciliation)
+
+	// Provider webhooks
 	s.router.POST("/api/v1/telegram/webhook", s.handleTelegramWebhook)
-}
\ No newline at end of file
+	s.router.POST("/api/v1/email/webhook/:provider", s.handleEmailWebhook)
+
+	// Email double opt-in links
+	s.router.GET("/api/v1/email/verify", s.emailVerificationForm)
+	s.router.POST("/api/v1/email/verify", s.verifyEmail)
+
+	// Admin endpoints (require ADMIN_API_TOKEN)
+	admin := s.router.Group("/api/v1/admin", s.adminMiddleware())
+	admin.POST("/templates/preview", s.previewTemplate)
+	admin.GET("/templates", s.listMessageTemplates)
+	admin.PUT("/templates/:lang/:name", s.setMessageTemplate)
+	admin.DELETE("/templates/:lang/:name", s.deleteMessageTemplate)
+	admin.GET("/trusted_senders", s.listTrustedSenders)
+	admin.PUT("/trusted_senders/:address", s.setTrustedSender)
+	admin.DELETE("/trusted_senders/:address", s.deleteTrustedSender)
+	admin.POST("/reprocess", s.reprocessBlocks)
+	admin.GET("/reprocess/:id", s.getReprocessJob)
+	admin.POST("/scheduled_notifications", s.scheduleNotification)
+	admin.GET("/scheduled_notifications/:id", s.getScheduledNotification)
+	admin.DELETE("/scheduled_notifications/:id", s.cancelScheduledNotification)
+	admin.GET("/deliveries/queue", s.listDeliveryJobs)
+	admin.GET("/deliveries/dead", s.listDeadLetters)
+	admin.GET("/deliveries/dead/:id", s.getDeadLetter)
+	admin.POST("/deliveries/dead/:id/replay", s.replayDeadLetter)
+	admin.DELETE("/deliveries/dead/:id", s.removeDeadLetter)
+	admin.POST("/exchanges", s.addExchange)
+	admin.GET("/exchanges", s.listExchanges)
+	admin.DELETE("/exchanges/:id", s.removeExchange)
+	admin.GET("/wallets", s.listWallets)
+	admin.GET("/wallets/search", s.searchWallets)
+	admin.POST("/wallets/import", s.importWallets)
+	admin.GET("/wallets/:address/tags", s.listWalletTags)
+	admin.PUT("/wallets/:address/tags/:tag", s.addWalletTag)
+	admin.DELETE("/wallets/:address/tags/:tag", s.removeWalletTag)
+	admin.GET("/wallets/:address/events", s.listWalletEvents)
+	admin.GET("/wallets/:address/state", s.walletState)
+	admin.GET("/tags", s.listTags)
+	admin.GET("/notifications", s.listNotifications)
+	admin.GET("/payments", s.listPayments)
+	admin.GET("/subscription_transfers", s.listSubscriptionTransfers)
+	admin.GET("/stats/notifications", s.notificationStats)
+	admin.GET("/stats/inflow", s.paymentInflow)
+	admin.GET("/stats/panics", s.panicReports)
+	admin.GET("/stats/pipeline", s.pipelineStats)
+	admin.GET("/shadow/report", s.shadowReport)
+	admin.GET("/maintenance", s.getMaintenance)
+	admin.PUT("/maintenance", s.setMaintenance)
+
+	// Hosted notification detail pages (linked from short-form channels)
+	s.router.GET("/n/:id", s.notificationDetails)
+	// Short redirect links for explorer URLs
+	s.router.GET("/s/:code", s.shortLinkRedirect)
+	// Public widget feeds of recent transfers, embedded on wallet owners' sites
+	s.router.GET("/widget/:address", s.widgetFeed)
+	// Shareable payment request pages
+	s.router.GET("/pay/:id", s.paymentRequestDetails)
+}
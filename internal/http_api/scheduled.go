@@ -0,0 +1,92 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduledNotificationSourceAdmin is the source of notifications scheduled through the admin API
+const ScheduledNotificationSourceAdmin = "admin"
+
+// ScheduleNotificationRequest represents the JSON body for scheduling a notification
+type ScheduleNotificationRequest struct {
+	Wallet  string `json:"wallet"` // Recipient address, empty for all active wallets
+	Tag     string `json:"tag"`    // Only send to the active wallets with the tag, requires an empty wallet
+	Message string `json:"message" binding:"required,max=4096"`
+	SendAt  *int64 `json:"send_at" binding:"required"` // Unix timestamp
+}
+
+// scheduleNotification is a handler for the /admin/scheduled_notifications endpoint.
+// It schedules a message for delivery to a wallet (or all active wallets, optionally with a tag) at a later time.
+func (s *HTTPServer) scheduleNotification(c *gin.Context) {
+	var req ScheduleNotificationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	if req.Wallet != "" {
+		if err := validation.ValidateAddress(req.Wallet); err != nil {
+			respondValidationErrors(c, "Invalid wallet address: "+err.Error(), addressError("wallet", err))
+			return
+		}
+	}
+
+	scheduled, err := s.nuntiare.ScheduleNotification(req.Wallet, req.Tag, req.Message, *req.SendAt, ScheduledNotificationSourceAdmin)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidSchedule) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		s.logger.Error("Failed to schedule notification", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to schedule notification"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, scheduled)
+}
+
+// getScheduledNotification is a handler for the /admin/scheduled_notifications/:id endpoint.
+// It returns the status of a scheduled notification.
+func (s *HTTPServer) getScheduledNotification(c *gin.Context) {
+	id := c.Param("id")
+
+	scheduled, err := s.nuntiare.GetScheduledNotification(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "scheduled notification not found"})
+		} else {
+			s.logger.Error("Failed to get scheduled notification", "error", err, "id", id)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get scheduled notification"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, scheduled)
+}
+
+// cancelScheduledNotification is a handler for DELETE /admin/scheduled_notifications/:id.
+// It cancels a notification that hasn't been dispatched yet.
+func (s *HTTPServer) cancelScheduledNotification(c *gin.Context) {
+	id := c.Param("id")
+
+	cancelled, err := s.nuntiare.CancelScheduledNotification(id)
+	if err != nil {
+		s.logger.Error("Failed to cancel scheduled notification", "error", err, "id", id)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to cancel scheduled notification"})
+		return
+	}
+	if !cancelled {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "pending scheduled notification not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
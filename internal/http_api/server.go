@@ -2,18 +2,26 @@ package http_api
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/core-coin/nuntiare/internal/config"
 	"github.com/core-coin/nuntiare/internal/models"
 	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/core-coin/nuntiare/pkg/nats"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	// ShutdownTimeout is the maximum time to wait for graceful shutdown
 	ShutdownTimeout = 10 * time.Second
+	// ReadHeaderTimeout is the maximum time to read request headers
+	ReadHeaderTimeout = 5 * time.Second
 )
 
 // HTTPServer is the HTTP server struct that will serve the API
@@ -31,6 +39,31 @@ type HTTPServer struct {
 
 	// nuntiare is the main application struct
 	nuntiare models.NuntiareI
+
+	// adminToken is the bearer token required for admin endpoints (empty = admin API disabled)
+	adminToken string
+
+	// readTimeout, writeTimeout and idleTimeout configure the underlying HTTP server
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	idleTimeout  time.Duration
+
+	// sessions issues and verifies wallet-scoped session tokens
+	sessions *sessionSigner
+
+	// v1Sunset is announced in the Sunset header of deprecated v1 endpoints (zero = no header)
+	v1Sunset time.Time
+
+	// balanceThreshold is the receiving address balance sweep alerts are sent at (0 = alerts disabled)
+	balanceThreshold float64
+
+	// readOnlyRetryAfter is announced to the clients rejected in read-only maintenance mode
+	readOnlyRetryAfter time.Duration
+
+	// registrations consumes registration requests from NATS, nil when not configured
+	registrations     *nats.Client
+	stopRegistrations context.CancelFunc
+	registrationsWG   sync.WaitGroup
 }
 
 // corsMiddleware adds CORS headers to all responses
@@ -38,7 +71,8 @@ func corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Origin-ID, If-None-Match")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "ETag, Deprecation, Sunset, Link")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -50,23 +84,80 @@ func corsMiddleware() gin.HandlerFunc {
 	}
 }
 
+// bodyLimitMiddleware rejects request bodies larger than maxBytes
+func bodyLimitMiddleware(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"success": false, "error": "request body too large"})
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// adminMiddleware requires a valid admin bearer token
+func (s *HTTPServer) adminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if s.adminToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin API is disabled"})
+			return
+		}
+
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			s.logger.Warn("Invalid admin token", "path", c.FullPath(), "ip", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid admin token"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // NewHTTPServer creates a new HTTP server instance
-func NewHTTPServer(nuntiare models.NuntiareI, port int, logger *logger.Logger) models.APIServer {
+func NewHTTPServer(nuntiare models.NuntiareI, cfg *config.Config, logger *logger.Logger) models.APIServer {
 	router := gin.Default()
-
-	// Add CORS middleware
-	router.Use(corsMiddleware())
+	registerJSONFieldNames()
 
 	server := &HTTPServer{
-		router:   router,
-		port:     port,
-		nuntiare: nuntiare,
-		logger:   logger,
+		router:     router,
+		port:       cfg.APIPort,
+		nuntiare:   nuntiare,
+		logger:     logger,
+		adminToken: cfg.AdminAPIToken,
+
+		readTimeout:  time.Duration(cfg.HTTPReadTimeoutSeconds) * time.Second,
+		writeTimeout: time.Duration(cfg.HTTPWriteTimeoutSeconds) * time.Second,
+		idleTimeout:  time.Duration(cfg.HTTPIdleTimeoutSeconds) * time.Second,
+
+		balanceThreshold: cfg.ReceivingBalanceThreshold,
+
+		readOnlyRetryAfter: time.Duration(cfg.ReadOnlyRetryAfterSeconds) * time.Second,
+	}
+	secret := []byte(cfg.SessionTokenSecret)
+	if len(secret) == 0 {
+		logger.Warn("SESSION_TOKEN_SECRET is not set, session tokens will not survive restarts or work across instances")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			logger.Fatal("Failed to generate session token secret: ", err)
+		}
+	}
+	server.sessions = &sessionSigner{secret: secret, ttl: time.Duration(cfg.SessionTokenTTLMinutes) * time.Minute}
+
+	if cfg.APIV1Sunset != "" {
+		// Format is checked by config validation
+		server.v1Sunset, _ = time.Parse(time.DateOnly, cfg.APIV1Sunset)
 	}
 
+	// Add CORS, request body limit, compression and read-only middleware
+	router.Use(corsMiddleware(), bodyLimitMiddleware(cfg.MaxRequestBodyBytes), compressionMiddleware(), server.readOnlyMiddleware())
+
 	// Define routes
 	server.routes()
 
+	server.registrations = server.newRegistrationConsumer(cfg)
+
 	return server
 }
 
@@ -74,8 +165,23 @@ func NewHTTPServer(nuntiare models.NuntiareI, port int, logger *logger.Logger) m
 func (s *HTTPServer) Start() {
 	addr := fmt.Sprintf("0.0.0.0:%v", s.port)
 	s.server = &http.Server{
-		Addr:    addr,
-		Handler: s.router,
+		Addr:              addr,
+		Handler:           s.router,
+		ReadHeaderTimeout: ReadHeaderTimeout,
+		ReadTimeout:       s.readTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+	}
+
+	if s.registrations != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		s.stopRegistrations = cancel
+		s.registrationsWG.Add(1)
+		go func() {
+			defer s.registrationsWG.Done()
+			s.registrations.Run(ctx)
+		}()
+		s.logger.Info("Consuming registration requests from NATS")
 	}
 
 	s.logger.Info("Starting HTTP server", "address", addr)
@@ -86,6 +192,12 @@ func (s *HTTPServer) Start() {
 
 // Shutdown gracefully shuts down the HTTP server
 func (s *HTTPServer) Shutdown() error {
+	// Stop consuming registration requests and wait for the one being handled
+	if s.stopRegistrations != nil {
+		s.stopRegistrations()
+		s.registrationsWG.Wait()
+	}
+
 	if s.server == nil {
 		return nil
 	}
@@ -0,0 +1,165 @@
+package http_api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionRequest represents the JSON body for issuing a session token
+type SessionRequest struct {
+	Address  string `json:"address" binding:"required"`
+	OriginID string `json:"origin_id" binding:"required"`
+}
+
+// SessionResponse represents an issued session token
+type SessionResponse struct {
+	Success   bool   `json:"success"`
+	Token     string `json:"token"`
+	ExpiresAt int64  `json:"expires_at"` // Unix timestamp
+}
+
+// sessionClaims is the signed payload of a session token
+type sessionClaims struct {
+	Address   string `json:"a"`
+	ExpiresAt int64  `json:"e"`
+	Purpose   string `json:"p,omitempty"` // Empty for session tokens, tokenPurposeLink or tokenPurposeOwnership otherwise
+}
+
+// tokenPurposeLink marks tokens that authorize linking another app or another wallet's user to a wallet;
+// they aren't session tokens
+const tokenPurposeLink = "link"
+
+// tokenPurposeOwnership marks the challenges a wallet signs to prove its ownership
+const tokenPurposeOwnership = "ownership"
+
+// sessionSigner issues and verifies short-lived, wallet-scoped session tokens
+type sessionSigner struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// issue returns a session token for the wallet address and its expiration timestamp
+func (s *sessionSigner) issue(address string, now time.Time) (string, int64) {
+	return s.issueFor("", address, now.Add(s.ttl))
+}
+
+// issueFor returns a token of the purpose for the wallet address and its expiration timestamp
+func (s *sessionSigner) issueFor(purpose, address string, expiresAt time.Time) (string, int64) {
+	claims := sessionClaims{Address: address, ExpiresAt: expiresAt.Unix(), Purpose: purpose}
+	payload, _ := json.Marshal(claims)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.sign(encoded), claims.ExpiresAt
+}
+
+// verify checks the session token signature and expiration and returns the wallet address it is scoped to
+func (s *sessionSigner) verify(token string, now time.Time) (string, error) {
+	return s.verifyFor("", token, now)
+}
+
+// verifyFor checks the signature, expiration and purpose of a token and returns the wallet address it is scoped to
+func (s *sessionSigner) verifyFor(purpose, token string, now time.Time) (string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || subtle.ConstantTimeCompare([]byte(signature), []byte(s.sign(encoded))) != 1 {
+		return "", models.ErrUnauthorized
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", models.ErrUnauthorized
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ExpiresAt <= now.Unix() || claims.Purpose != purpose {
+		return "", models.ErrUnauthorized
+	}
+	return claims.Address, nil
+}
+
+func (s *sessionSigner) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// authorizedForWallet reports whether the request carries a session token for the wallet
+// (Authorization: Bearer) or the OriginID of the wallet or of a linked app (X-Origin-ID header)
+func (s *HTTPServer) authorizedForWallet(c *gin.Context, wallet *models.Wallet) bool {
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		address, err := s.sessions.verify(token, time.Now())
+		return err == nil && address == wallet.Address
+	}
+	return s.nuntiare.IsWalletOrigin(wallet, c.GetHeader("X-Origin-ID"))
+}
+
+// authorizedWallet validates the address, loads its wallet and checks the request is authorized for it.
+// It writes the error response and returns nil on failure.
+func (s *HTTPServer) authorizedWallet(c *gin.Context, address string) *models.Wallet {
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "Invalid address: "+err.Error(), addressError("address", err))
+		return nil
+	}
+	address = validation.NormalizeAddress(address)
+
+	wallet, err := s.nuntiare.GetWallet(address)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Wallet not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get wallet"})
+		}
+		return nil
+	}
+
+	if !s.authorizedForWallet(c, wallet) {
+		s.logger.Warn("Unauthorized wallet request", "address", address, "path", c.FullPath())
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid origin_id or session token"})
+		return nil
+	}
+
+	return wallet
+}
+
+// createSession is a handler for the /session endpoint.
+// It verifies the OriginID of the wallet or of a linked app and issues a short-lived session token scoped to the wallet.
+func (s *HTTPServer) createSession(c *gin.Context) {
+	var req SessionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	if err := validation.ValidateAddress(req.Address); err != nil {
+		respondValidationErrors(c, "Invalid address: "+err.Error(), addressError("address", err))
+		return
+	}
+	req.Address = validation.NormalizeAddress(req.Address)
+
+	wallet, err := s.nuntiare.GetWallet(req.Address)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Wallet not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get wallet"})
+		}
+		return
+	}
+
+	if !s.nuntiare.IsWalletOrigin(wallet, req.OriginID) {
+		s.logger.Warn("OriginID mismatch for session", "address", req.Address)
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid origin_id"})
+		return
+	}
+
+	token, expiresAt := s.sessions.issue(wallet.Address, time.Now())
+	c.JSON(http.StatusCreated, SessionResponse{Success: true, Token: token, ExpiresAt: expiresAt})
+}
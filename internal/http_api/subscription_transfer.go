@@ -0,0 +1,83 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionTransferRequest represents the JSON body for moving a subscription to another wallet
+type SubscriptionTransferRequest struct {
+	Address   string `json:"address" binding:"required"`    // Wallet the remaining time is taken from
+	ToAddress string `json:"to_address" binding:"required"` // Registered wallet of the same user receiving it
+}
+
+// SubscriptionTransferResponse represents a completed subscription transfer
+type SubscriptionTransferResponse struct {
+	Success  bool                         `json:"success"`
+	Transfer *models.SubscriptionTransfer `json:"transfer"`
+}
+
+// transferSubscription is a handler for the POST /subscription/transfer endpoint.
+// It moves the remaining subscription time of the wallet to another registered wallet of the same user,
+// e.g. after the user rotated wallets. Requires a session token or the X-Origin-ID of the source wallet.
+func (s *HTTPServer) transferSubscription(c *gin.Context) {
+	var req SubscriptionTransferRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	if err := validation.ValidateAddress(req.ToAddress); err != nil {
+		respondValidationErrors(c, "Invalid to_address: "+err.Error(), addressError("to_address", err))
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	transfer, err := s.nuntiare.TransferSubscription(wallet, validation.NormalizeAddress(req.ToAddress), c.ClientIP())
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidSubscriptionTransfer):
+			respondValidationErrors(c, err.Error(), FieldError{Field: "to_address", Code: CodeInvalid, Message: err.Error()})
+		case errors.Is(err, models.ErrNoActiveSubscription):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": "Wallet has no active subscription"})
+		case strings.Contains(err.Error(), "record not found"):
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Destination wallet not found"})
+		default:
+			s.logger.Error("Failed to transfer subscription", "error", err, "address", wallet.Address)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to transfer subscription"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, SubscriptionTransferResponse{Success: true, Transfer: transfer})
+}
+
+// listSubscriptionTransfers is a handler for the /admin/subscription_transfers endpoint.
+// It returns the subscription transfers from or to a wallet for auditing.
+func (s *HTTPServer) listSubscriptionTransfers(c *gin.Context) {
+	address := c.Query("address")
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "Invalid address: "+err.Error(), addressError("address", err))
+		return
+	}
+
+	transfers, err := s.nuntiare.GetSubscriptionTransfers(validation.NormalizeAddress(address))
+	if err != nil {
+		s.logger.Error("Failed to get subscription transfers", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get subscription transfers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "transfers": transfers})
+}
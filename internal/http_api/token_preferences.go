@@ -0,0 +1,99 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// TokenPreferenceRequest represents the JSON body for opting a wallet in or out of a token
+type TokenPreferenceRequest struct {
+	Address string `json:"address" binding:"required"`
+	Token   string `json:"token" binding:"required"` // "xcb" or the token contract address
+	Notify  *bool  `json:"notify" binding:"required"`
+}
+
+// TokenPreferencesResponse represents the token preferences of a wallet
+type TokenPreferencesResponse struct {
+	Success     bool                            `json:"success"`
+	Preferences []*models.WalletTokenPreference `json:"preferences"`
+}
+
+// setTokenPreference is a handler for the PUT /wallet/tokens endpoint.
+// It opts the wallet in or out of notifications about a token.
+func (s *HTTPServer) setTokenPreference(c *gin.Context) {
+	var req TokenPreferenceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	preference, err := s.nuntiare.SetTokenPreference(wallet.Address, req.Token, *req.Notify)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidTokenPreference) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "token", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to set token preference", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to set token preference"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "preference": preference})
+}
+
+// listTokenPreferences is a handler for the GET /wallet/tokens endpoint.
+// It returns the token preferences of the wallet.
+func (s *HTTPServer) listTokenPreferences(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	preferences, err := s.nuntiare.GetTokenPreferences(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to get token preferences", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get token preferences"})
+		return
+	}
+	if preferences == nil {
+		preferences = []*models.WalletTokenPreference{}
+	}
+
+	c.JSON(http.StatusOK, TokenPreferencesResponse{Success: true, Preferences: preferences})
+}
+
+// removeTokenPreference is a handler for the DELETE /wallet/tokens/:token endpoint.
+// It removes the preference of the wallet for a token.
+func (s *HTTPServer) removeTokenPreference(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	removed, err := s.nuntiare.RemoveTokenPreference(wallet.Address, c.Param("token"))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidTokenPreference) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "token", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to remove token preference", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to remove token preference"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Token preference not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
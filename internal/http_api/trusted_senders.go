@@ -0,0 +1,75 @@
+package http_api
+
+import (
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// TrustedSenderRequest represents the JSON body for registering a verified sender address
+type TrustedSenderRequest struct {
+	Name     string `json:"name" binding:"required,max=64"`
+	Category string `json:"category" binding:"required,oneof=exchange contract service"`
+}
+
+// listTrustedSenders is a handler for the GET /admin/trusted_senders endpoint.
+// It returns the verified sender addresses.
+func (s *HTTPServer) listTrustedSenders(c *gin.Context) {
+	senders, err := s.nuntiare.GetTrustedSenders()
+	if err != nil {
+		s.logger.Error("Failed to get trusted senders", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get trusted senders"})
+		return
+	}
+	if senders == nil {
+		senders = []*models.TrustedSender{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "trusted_senders": senders})
+}
+
+// setTrustedSender is a handler for the PUT /admin/trusted_senders/:address endpoint.
+// Notifications of transfers from the address show the name with a verified marker.
+func (s *HTTPServer) setTrustedSender(c *gin.Context) {
+	address := c.Param("address")
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
+		return
+	}
+
+	var req TrustedSenderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	sender, err := s.nuntiare.SetTrustedSender(validation.NormalizeAddress(address), req.Name, req.Category)
+	if err != nil {
+		s.logger.Error("Failed to set trusted sender", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to set trusted sender"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "trusted_sender": sender})
+}
+
+// deleteTrustedSender is a handler for the DELETE /admin/trusted_senders/:address endpoint.
+// Notifications of transfers from the address are no longer marked as verified.
+func (s *HTTPServer) deleteTrustedSender(c *gin.Context) {
+	address := validation.NormalizeAddress(c.Param("address"))
+	deleted, err := s.nuntiare.DeleteTrustedSender(address)
+	if err != nil {
+		s.logger.Error("Failed to delete trusted sender", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to delete trusted sender"})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "trusted sender not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
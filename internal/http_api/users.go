@@ -0,0 +1,113 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// JoinUserRequest represents the JSON body for adding a wallet to the user of another wallet
+type JoinUserRequest struct {
+	Address   string `json:"address" binding:"required"`
+	LinkToken string `json:"link_token" binding:"required"` // Issued to an app of the primary wallet
+}
+
+// UserResponse represents the user of a wallet and the addresses of its wallets
+type UserResponse struct {
+	Success bool         `json:"success"`
+	User    *models.User `json:"user"` // Null if the wallet has no user
+	Wallets []string     `json:"wallets"`
+}
+
+// joinUser is a handler for the POST /wallet/user endpoint.
+// The wallet joins the user of the wallet the link token was issued for and is notified through that
+// wallet's notification channels from then on.
+func (s *HTTPServer) joinUser(c *gin.Context) {
+	var req JoinUserRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	address, err := s.sessions.verifyFor(tokenPurposeLink, req.LinkToken, time.Now())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid or expired link_token"})
+		return
+	}
+	primary, err := s.nuntiare.GetWallet(address)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Wallet of the link_token not found"})
+		return
+	}
+
+	user, err := s.nuntiare.JoinUser(primary, wallet)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidUserLink) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "address", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to join user", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to join user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "user": user})
+}
+
+// getUser is a handler for the GET /wallet/user endpoint.
+// It returns the user of the wallet and all wallets of the user.
+func (s *HTTPServer) getUser(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	user, wallets, err := s.nuntiare.GetUserWallets(wallet)
+	if err != nil {
+		s.logger.Error("Failed to get user", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get user"})
+		return
+	}
+
+	addresses := make([]string, 0, len(wallets))
+	for _, w := range wallets {
+		addresses = append(addresses, w.Address)
+	}
+	c.JSON(http.StatusOK, UserResponse{Success: true, User: user, Wallets: addresses})
+}
+
+// leaveUser is a handler for the DELETE /wallet/user endpoint.
+// The wallet leaves its user and is notified through its own notification channels again.
+func (s *HTTPServer) leaveUser(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	left, err := s.nuntiare.LeaveUser(wallet)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidUserLink) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": err.Error()})
+			return
+		}
+		s.logger.Error("Failed to leave user", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to leave user"})
+		return
+	}
+	if !left {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Wallet has no user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
@@ -0,0 +1,150 @@
+package http_api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fieldNamesKey is the gin context key of the field name translation used in validation errors
+const fieldNamesKey = "validation_field_names"
+
+// v2FieldNames maps v1 request field names to their v2 equivalents
+var v2FieldNames = map[string]string{
+	"originid":    "origin_id",
+	"subscriber":  "subscription_address",
+	"destination": "address",
+
+	"subscriber_challenge": "subscription_address_challenge",
+	"subscriber_signature": "subscription_address_signature",
+}
+
+// RegisterRequestV2 represents the JSON body for wallet registration in API v2
+type RegisterRequestV2 struct {
+	Origin              string             `json:"origin" binding:"required"`
+	OriginID            string             `json:"origin_id" binding:"required,min=32,max=32"` // Alphanumeric UUID, 32 chars
+	SubscriptionAddress string             `json:"subscription_address" binding:"required"`
+	Address             string             `json:"address" binding:"required"`
+	Network             string             `json:"network" binding:"required,oneof=xcb xab"`
+	OS                  string             `json:"os"`                           // Operating system (ios, android, web, etc.)
+	Lang                string             `json:"lang"`                         // Language (en, es, fr, etc.)
+	AppVersion          string             `json:"app_version" binding:"max=64"` // Wallet app version (e.g. 2.1.0)
+	Telegram            string             `json:"telegram"`
+	Email               string             `json:"email" binding:"omitempty,email"`
+	FCMToken            string             `json:"fcm_token" binding:"max=4096"` // Firebase Cloud Messaging registration token of the app
+	WebhookURL          string             `json:"webhook_url"`                  // HTTPS URL notifications are POSTed to as signed JSON
+	DiscordWebhookURL   string             `json:"discord_webhook_url" binding:"max=2048"`
+	DiscordChannelID    string             `json:"discord_channel_id" binding:"max=20"`
+	Phone               string             `json:"phone" binding:"max=16"` // E.164 phone number SMS notifications are sent to
+	MatrixRoomID        string             `json:"matrix_room_id"`         // Matrix room the bot posts to (e.g. !abc:example.org)
+	NtfyTopicURL        string             `json:"ntfy_topic_url"`         // ntfy topic notifications are published to (e.g. https://ntfy.sh/my-wallet)
+	PushoverUserKey     string             `json:"pushover_user_key"`      // Pushover user or group key notifications are sent to
+	MutedEvents         []string           `json:"muted_events" binding:"omitempty,max=16"`
+	MinAmount           map[string]float64 `json:"min_amount" binding:"omitempty,max=32"`
+	SubscriberChallenge string             `json:"subscription_address_challenge"`
+	SubscriberSignature string             `json:"subscription_address_signature" binding:"max=512"`
+}
+
+// v1 converts the request to its v1 equivalent
+func (r *RegisterRequestV2) v1() *RegisterRequest {
+	return &RegisterRequest{
+		Origin:      r.Origin,
+		OriginID:    r.OriginID,
+		Subscriber:  r.SubscriptionAddress,
+		Destination: r.Address,
+		Network:     r.Network,
+		OS:          r.OS,
+		Lang:        r.Lang,
+		AppVersion:  r.AppVersion,
+		Telegram:    r.Telegram,
+		Email:       r.Email,
+		FCMToken:    r.FCMToken,
+		WebhookURL:  r.WebhookURL,
+
+		DiscordWebhookURL: r.DiscordWebhookURL,
+		DiscordChannelID:  r.DiscordChannelID,
+		Phone:             r.Phone,
+		MatrixRoomID:      r.MatrixRoomID,
+		NtfyTopicURL:      r.NtfyTopicURL,
+		PushoverUserKey:   r.PushoverUserKey,
+		MutedEvents:       r.MutedEvents,
+		MinAmount:         r.MinAmount,
+
+		SubscriberChallenge: r.SubscriberChallenge,
+		SubscriberSignature: r.SubscriberSignature,
+	}
+}
+
+// CancelRequestV2 represents the JSON body for canceling notifications in API v2
+type CancelRequestV2 struct {
+	Address  string `json:"address" binding:"required"`
+	OriginID string `json:"origin_id" binding:"required"`
+}
+
+// v1 converts the request to its v1 equivalent
+func (r *CancelRequestV2) v1() *CancelRequest {
+	return &CancelRequest{
+		Destination: r.Address,
+		OriginID:    r.OriginID,
+	}
+}
+
+// v2Fields makes validation errors of the shared v1 handler logic report v2 field names
+func v2Fields() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(fieldNamesKey, v2FieldNames)
+		c.Next()
+	}
+}
+
+// fieldName returns the name of a request field in the API version of the current request
+func fieldName(c *gin.Context, name string) string {
+	if names, ok := c.Get(fieldNamesKey); ok {
+		if translated, ok := names.(map[string]string)[name]; ok {
+			return translated
+		}
+	}
+	return name
+}
+
+// deprecationMiddleware marks v1 endpoints as deprecated and points clients to the v2 successor
+func deprecationMiddleware(sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		if !sunset.IsZero() {
+			c.Header("Sunset", sunset.UTC().Format(http.TimeFormat))
+		}
+		if path := c.FullPath(); len(path) > len("/api/v1") {
+			c.Header("Link", fmt.Sprintf(`</api/v2%s>; rel="successor-version"`, path[len("/api/v1"):]))
+		}
+		c.Next()
+	}
+}
+
+// registerV2 is a handler for the v2 /subscription endpoint.
+func (s *HTTPServer) registerV2(c *gin.Context) {
+	var req RegisterRequestV2
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	s.registerWallet(c, req.v1())
+}
+
+// cancelV2 is a handler for the v2 /cancel endpoint.
+func (s *HTTPServer) cancelV2(c *gin.Context) {
+	var req CancelRequestV2
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	s.cancelWallet(c, req.v1())
+}
@@ -0,0 +1,146 @@
+package http_api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Machine-readable validation error codes (address codes come from pkg/validation)
+const (
+	CodeInvalidJSON   = "invalid_json"
+	CodeBodyTooLarge  = "body_too_large"
+	CodeInvalidType   = "invalid_type"
+	CodeInvalidEmail  = "invalid_email"
+	CodeInvalidValue  = "invalid_value"
+	CodeTooShort      = "too_short"
+	CodeTooLong       = "too_long"
+	CodeInvalid       = "invalid"
+	CodeMissingMethod = "missing_notification_method"
+	// CodeUpgradeRequired is returned with 426 when the client app is older than the supported minimum
+	CodeUpgradeRequired = "upgrade_required"
+	// CodeOwnershipNotProven is returned with 403 when a feature requires the wallet's ownership to be proven
+	CodeOwnershipNotProven = "ownership_not_proven"
+	// CodeSubscriberNotProven is returned with 409 when a wallet joins the subscription address of another user's
+	// wallets without proving the ownership of the address
+	CodeSubscriberNotProven = "subscriber_not_proven"
+)
+
+// FieldError is a validation error of a single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationErrorResponse is returned with 400 when request validation fails
+type ValidationErrorResponse struct {
+	Success bool         `json:"success"`
+	Error   string       `json:"error"`
+	Errors  []FieldError `json:"errors"`
+}
+
+// registerJSONFieldNames makes binding errors report JSON field names instead of Go struct field names
+func registerJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// respondValidationErrors writes a 400 response with field-level validation errors
+func respondValidationErrors(c *gin.Context, message string, errs ...FieldError) {
+	c.JSON(http.StatusBadRequest, ValidationErrorResponse{
+		Success: false,
+		Error:   message,
+		Errors:  errs,
+	})
+}
+
+// bindingErrors converts a request binding error into field-level validation errors
+func bindingErrors(err error) []FieldError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		errs := make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			errs = append(errs, validationFieldError(fe))
+		}
+		return errs
+	}
+
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return []FieldError{{Field: "body", Code: CodeBodyTooLarge, Message: "request body too large"}}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{Field: typeErr.Field, Code: CodeInvalidType, Message: typeErr.Field + " must be a " + typeErr.Type.String()}}
+	}
+
+	return []FieldError{{Field: "body", Code: CodeInvalidJSON, Message: err.Error()}}
+}
+
+// validationFieldError maps a failed validator tag to a validation error code and message
+func validationFieldError(fe validator.FieldError) FieldError {
+	field := fe.Field()
+	switch fe.Tag() {
+	case "required":
+		return FieldError{Field: field, Code: validation.CodeRequired, Message: field + " is required"}
+	case "email":
+		return FieldError{Field: field, Code: CodeInvalidEmail, Message: field + " must be a valid email address"}
+	case "oneof":
+		return FieldError{Field: field, Code: CodeInvalidValue, Message: field + " must be one of: " + fe.Param()}
+	case "min":
+		return FieldError{Field: field, Code: CodeTooShort, Message: field + " must have at least " + fe.Param() + " " + lengthUnit(fe)}
+	case "max":
+		return FieldError{Field: field, Code: CodeTooLong, Message: field + " must have at most " + fe.Param() + " " + lengthUnit(fe)}
+	default:
+		return FieldError{Field: field, Code: CodeInvalid, Message: field + " is invalid"}
+	}
+}
+
+// lengthUnit names what min/max constraints count for the field's type
+func lengthUnit(fe validator.FieldError) string {
+	switch fe.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	default:
+		return "characters"
+	}
+}
+
+// addressError converts an address validation error of the given field into a field-level error
+func addressError(field string, err error) FieldError {
+	code := CodeInvalid
+	var addrErr *validation.AddressError
+	if errors.As(err, &addrErr) {
+		code = addrErr.Code
+	}
+	return FieldError{Field: field, Code: code, Message: err.Error()}
+}
+
+// addressLabel returns a human-readable name of an address field ("destination" -> "destination address")
+func addressLabel(field string) string {
+	if strings.HasSuffix(field, "address") {
+		return strings.ReplaceAll(field, "_", " ")
+	}
+	return field + " address"
+}
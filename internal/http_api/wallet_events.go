@@ -0,0 +1,79 @@
+package http_api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// listWalletEvents is a handler for the GET /admin/wallets/:address/events endpoint.
+// It returns the state transitions of a wallet up to the until timestamp (default now), oldest first.
+func (s *HTTPServer) listWalletEvents(c *gin.Context) {
+	address := c.Param("address")
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
+		return
+	}
+	until, fieldErr := unixQuery(c, "until", time.Now().Unix())
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	events, err := s.nuntiare.GetWalletEvents(validation.NormalizeAddress(address), until)
+	if err != nil {
+		s.logger.Error("Failed to get wallet events", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get wallet events"})
+		return
+	}
+	if events == nil {
+		events = []*models.WalletEvent{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "events": events})
+}
+
+// walletState is a handler for the GET /admin/wallets/:address/state endpoint.
+// It derives the state of a wallet at the at timestamp (default now) from its events. The current state
+// is compared with the stored wallet, a mismatch means a transition wasn't recorded.
+func (s *HTTPServer) walletState(c *gin.Context) {
+	address := c.Param("address")
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
+		return
+	}
+	address = validation.NormalizeAddress(address)
+	now := time.Now().Unix()
+	at, fieldErr := unixQuery(c, "at", now)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	state, err := s.nuntiare.GetWalletState(address, at)
+	if err != nil {
+		s.logger.Error("Failed to derive wallet state", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to derive wallet state"})
+		return
+	}
+	if at < now {
+		c.JSON(http.StatusOK, gin.H{"success": true, "state": state})
+		return
+	}
+
+	wallets, err := s.nuntiare.GetWallets([]string{address})
+	if err != nil {
+		s.logger.Error("Failed to get wallet", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get wallet"})
+		return
+	}
+	var wallet *models.Wallet
+	if len(wallets) > 0 {
+		wallet = wallets[0]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "state": state, "consistent": state.Matches(wallet)})
+}
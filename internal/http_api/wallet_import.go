@@ -0,0 +1,123 @@
+package http_api
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// walletImportColumns maps accepted CSV header names to the row field they set
+var walletImportColumns = map[string]func(row *models.WalletImportRow, value string){
+	"address":    func(row *models.WalletImportRow, value string) { row.Address = value },
+	"subscriber": func(row *models.WalletImportRow, value string) { row.SubscriptionAddress = value },
+	"email":      func(row *models.WalletImportRow, value string) { row.Email = value },
+	"telegram":   func(row *models.WalletImportRow, value string) { row.Telegram = value },
+	"origin":     func(row *models.WalletImportRow, value string) { row.Origin = value },
+	"origin_id":  func(row *models.WalletImportRow, value string) { row.OriginID = value },
+	"network":    func(row *models.WalletImportRow, value string) { row.Network = value },
+}
+
+// requiredWalletImportColumns must be present in the CSV header
+var requiredWalletImportColumns = []string{"address", "subscriber", "origin"}
+
+// importWallets is a handler for the /admin/wallets/import endpoint.
+// It registers the wallets of a CSV user list (with a header row) and reports the result of each row.
+func (s *HTTPServer) importWallets(c *gin.Context) {
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			respondValidationErrors(c, "dry_run must be a boolean",
+				FieldError{Field: "dry_run", Code: CodeInvalidType, Message: "dry_run must be a boolean"})
+			return
+		}
+		dryRun = b
+	}
+
+	rows, fieldErr := parseWalletImportCSV(c.Request.Body)
+	if fieldErr != nil {
+		respondValidationErrors(c, fieldErr.Message, *fieldErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, s.nuntiare.ImportWallets(rows, dryRun))
+}
+
+// parseWalletImportCSV reads the rows of a wallet import CSV. Columns are matched by header name.
+func parseWalletImportCSV(body io.Reader) ([]*models.WalletImportRow, *FieldError) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, &FieldError{Field: "body", Code: validation.CodeRequired, Message: "CSV header row is required"}
+		}
+		return nil, csvError(err)
+	}
+
+	setters := make([]func(row *models.WalletImportRow, value string), len(header))
+	present := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "originid" {
+			name = "origin_id"
+		}
+		setter, ok := walletImportColumns[name]
+		if !ok {
+			return nil, &FieldError{Field: "body", Code: CodeInvalidValue, Message: "unknown CSV column: " + name}
+		}
+		setters[i] = setter
+		present[name] = true
+	}
+	for _, name := range requiredWalletImportColumns {
+		if !present[name] {
+			return nil, &FieldError{Field: "body", Code: validation.CodeRequired, Message: "CSV column " + name + " is required"}
+		}
+	}
+
+	var rows []*models.WalletImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+		if len(rows) == models.MaxWalletImportRows {
+			return nil, &FieldError{Field: "body", Code: CodeTooLong, Message: "CSV must have at most " + strconv.Itoa(models.MaxWalletImportRows) + " rows"}
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := &models.WalletImportRow{Line: line}
+		for i, value := range record {
+			if i < len(setters) {
+				setters[i](row, strings.TrimSpace(value))
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, &FieldError{Field: "body", Code: CodeTooShort, Message: "CSV must have at least 1 row"}
+	}
+	return rows, nil
+}
+
+// csvError converts a CSV read error into a validation error
+func csvError(err error) *FieldError {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return &FieldError{Field: "body", Code: CodeBodyTooLarge, Message: "request body too large"}
+	}
+	return &FieldError{Field: "body", Code: CodeInvalid, Message: "invalid CSV: " + err.Error()}
+}
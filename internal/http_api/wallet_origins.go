@@ -0,0 +1,154 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// LinkTokenTTL is how long a link token can be used to link another app to the wallet
+const LinkTokenTTL = 10 * time.Minute
+
+// LinkTokenRequest represents the JSON body for issuing a link token
+type LinkTokenRequest struct {
+	Address string `json:"address" binding:"required"`
+}
+
+// LinkOriginRequest represents the JSON body for linking an app to a wallet registered by another app
+type LinkOriginRequest struct {
+	Address   string `json:"address" binding:"required"`
+	Origin    string `json:"origin" binding:"required,max=64"`
+	OriginID  string `json:"origin_id" binding:"required,min=16,max=128"`
+	LinkToken string `json:"link_token" binding:"required"`
+}
+
+// WalletOriginsResponse represents the apps of a wallet
+type WalletOriginsResponse struct {
+	Success bool                   `json:"success"`
+	Origin  string                 `json:"origin"` // App that registered the wallet
+	Linked  []*models.WalletOrigin `json:"linked"`
+}
+
+// createLinkToken is a handler for the POST /wallet/link_token endpoint.
+// It issues a short-lived token the user hands to another wallet app (e.g. as QR code) to link it to the wallet.
+func (s *HTTPServer) createLinkToken(c *gin.Context) {
+	var req LinkTokenRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	token, expiresAt := s.sessions.issueFor(tokenPurposeLink, wallet.Address, time.Now().Add(LinkTokenTTL))
+	c.JSON(http.StatusCreated, SessionResponse{Success: true, Token: token, ExpiresAt: expiresAt})
+}
+
+// linkOrigin is a handler for the POST /wallet/origins endpoint.
+// The app being linked sends its own origin and OriginID along with a link token issued to an app of the wallet.
+// Afterwards its OriginID authenticates requests for the wallet.
+func (s *HTTPServer) linkOrigin(c *gin.Context) {
+	var req LinkOriginRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	address, err := s.sessions.verifyFor(tokenPurposeLink, req.LinkToken, time.Now())
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid or expired link_token"})
+		return
+	}
+	wallet, err := s.nuntiare.GetWallet(address)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Wallet not found"})
+		return
+	}
+	if wallet.Address != validation.NormalizeAddress(req.Address) {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid or expired link_token"})
+		return
+	}
+
+	origin, err := s.nuntiare.LinkWalletOrigin(wallet, req.Origin, req.OriginID)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidOriginLink) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "origin", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to link origin", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to link origin"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "origin": origin})
+}
+
+// listWalletOrigins is a handler for the GET /wallet/origins endpoint.
+// It returns the app that registered the wallet and the linked apps.
+func (s *HTTPServer) listWalletOrigins(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	origins, err := s.nuntiare.GetWalletOrigins(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to get wallet origins", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get wallet origins"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WalletOriginsResponse{Success: true, Origin: wallet.Originator, Linked: origins})
+}
+
+// unlinkOrigin is a handler for the DELETE /wallet/origins/:origin endpoint.
+// Any app of the wallet can unlink a linked app; the app that registered the wallet can't be unlinked.
+func (s *HTTPServer) unlinkOrigin(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	removed, err := s.nuntiare.UnlinkWalletOrigin(wallet.Address, c.Param("origin"))
+	if err != nil {
+		s.logger.Error("Failed to unlink origin", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to unlink origin"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Origin not linked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// unlinkCancellingOrigin handles a cancel request of a linked app: the app is unlinked and the wallet
+// stays active for the app that registered it and the other linked apps
+func (s *HTTPServer) unlinkCancellingOrigin(c *gin.Context, wallet *models.Wallet, originID string) {
+	origin, err := s.nuntiare.GetWalletOriginByID(wallet.Address, originID)
+	if err == nil && origin != nil {
+		_, err = s.nuntiare.UnlinkWalletOrigin(wallet.Address, origin.Origin)
+	}
+	if err != nil {
+		s.logger.Error("Failed to unlink cancelling origin", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to cancel notifications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "App unlinked, notifications continue for the other apps of the wallet",
+	})
+}
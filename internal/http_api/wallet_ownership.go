@@ -0,0 +1,155 @@
+package http_api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// OwnershipChallengeTTL is how long an ownership challenge can be signed and submitted
+const OwnershipChallengeTTL = 10 * time.Minute
+
+// OwnershipChallengeRequest represents the JSON body for issuing an ownership challenge
+type OwnershipChallengeRequest struct {
+	Address string `json:"address" binding:"required"`
+}
+
+// OwnershipChallengeResponse represents an issued ownership challenge and the message to sign
+type OwnershipChallengeResponse struct {
+	Success   bool   `json:"success"`
+	Challenge string `json:"challenge"`
+	Message   string `json:"message"`    // Message to sign with the key of the wallet
+	ExpiresAt int64  `json:"expires_at"` // Unix timestamp
+}
+
+// OwnershipProofRequest represents the JSON body for proving the ownership of a wallet
+type OwnershipProofRequest struct {
+	Address   string `json:"address" binding:"required"`
+	Challenge string `json:"challenge" binding:"required"`
+	Signature string `json:"signature" binding:"required,max=512"` // Hex encoded signature of the challenge message
+}
+
+// ownershipMessage returns the message the wallet signs to prove its ownership
+func ownershipMessage(address, challenge string) string {
+	return fmt.Sprintf("Sign this message to prove you own the wallet %s.\n\nChallenge: %s", address, challenge)
+}
+
+// createOwnershipChallenge is a handler for the POST /wallet/ownership/challenge endpoint.
+// It issues a short-lived challenge whose message the user signs with the key of the wallet.
+func (s *HTTPServer) createOwnershipChallenge(c *gin.Context) {
+	var req OwnershipChallengeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	challenge, expiresAt := s.sessions.issueFor(tokenPurposeOwnership, wallet.Address, time.Now().Add(OwnershipChallengeTTL))
+	c.JSON(http.StatusCreated, OwnershipChallengeResponse{
+		Success:   true,
+		Challenge: challenge,
+		Message:   ownershipMessage(wallet.Address, challenge),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// createSubscriberChallenge is a handler for the POST /subscription_address/challenge endpoint.
+// It issues a challenge whose message the user signs with the key of a subscription address, to register a
+// wallet with the subscription address of another user's wallets. The address needn't be a registered wallet.
+func (s *HTTPServer) createSubscriberChallenge(c *gin.Context) {
+	var req OwnershipChallengeRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+	address, err := validation.ValidateAndNormalizeAddress(req.Address)
+	if err != nil {
+		respondValidationErrors(c, "Invalid address: "+err.Error(), addressError("address", err))
+		return
+	}
+
+	challenge, expiresAt := s.sessions.issueFor(tokenPurposeOwnership, address, time.Now().Add(OwnershipChallengeTTL))
+	c.JSON(http.StatusCreated, OwnershipChallengeResponse{
+		Success:   true,
+		Challenge: challenge,
+		Message:   ownershipMessage(address, challenge),
+		ExpiresAt: expiresAt,
+	})
+}
+
+// proveOwnership is a handler for the POST /wallet/ownership endpoint.
+// It checks the signature of the challenge message and flags the wallet as proven.
+func (s *HTTPServer) proveOwnership(c *gin.Context) {
+	var req OwnershipProofRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	address, err := s.sessions.verifyFor(tokenPurposeOwnership, req.Challenge, time.Now())
+	if err != nil || address != wallet.Address {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "error": "Invalid or expired challenge"})
+		return
+	}
+
+	provenAt, err := s.nuntiare.ProveWalletOwnership(wallet, ownershipMessage(wallet.Address, req.Challenge), req.Signature)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidSignature) {
+			s.logger.Debug("Invalid ownership proof", "error", err, "address", wallet.Address)
+			respondValidationErrors(c, err.Error(), FieldError{Field: "signature", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to prove wallet ownership", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to prove wallet ownership"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "ownership_proven": true, "ownership_proven_at": provenAt})
+}
+
+// exportWalletData is a handler for the GET /wallet/export endpoint.
+// It returns the data stored about a wallet whose ownership was proven.
+func (s *HTTPServer) exportWalletData(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	export, err := s.nuntiare.ExportWalletData(wallet)
+	if err != nil {
+		if errors.Is(err, models.ErrOwnershipNotProven) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Prove the ownership of the wallet to export its data",
+				"code":    CodeOwnershipNotProven,
+			})
+			return
+		}
+		s.logger.Error("Failed to export wallet data", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to export wallet data"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="nuntiare-%s.json"`, wallet.Address))
+	c.JSON(http.StatusOK, gin.H{"success": true, "export": export})
+}
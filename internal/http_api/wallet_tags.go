@@ -0,0 +1,97 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+	"github.com/gin-gonic/gin"
+)
+
+// listWalletTags is a handler for the GET /admin/wallets/:address/tags endpoint.
+// It returns the tags of a wallet.
+func (s *HTTPServer) listWalletTags(c *gin.Context) {
+	address := c.Param("address")
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
+		return
+	}
+
+	tags, err := s.nuntiare.GetWalletTags(validation.NormalizeAddress(address))
+	if err != nil {
+		s.logger.Error("Failed to get wallet tags", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to get wallet tags"})
+		return
+	}
+	if tags == nil {
+		tags = []*models.WalletTag{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "tags": tags})
+}
+
+// addWalletTag is a handler for the PUT /admin/wallets/:address/tags/:tag endpoint.
+// It attaches a tag to a registered wallet.
+func (s *HTTPServer) addWalletTag(c *gin.Context) {
+	address := c.Param("address")
+	if err := validation.ValidateAddress(address); err != nil {
+		respondValidationErrors(c, "invalid address format: "+err.Error(), addressError("address", err))
+		return
+	}
+
+	tag, err := s.nuntiare.AddWalletTag(validation.NormalizeAddress(address), c.Param("tag"))
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidWalletTag):
+			respondValidationErrors(c, err.Error(), FieldError{Field: "tag", Code: CodeInvalid, Message: err.Error()})
+		case strings.Contains(err.Error(), "record not found"):
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "wallet not found"})
+		default:
+			s.logger.Error("Failed to add wallet tag", "error", err, "address", address)
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to add wallet tag"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "tag": tag})
+}
+
+// removeWalletTag is a handler for the DELETE /admin/wallets/:address/tags/:tag endpoint.
+// It detaches a tag from a wallet.
+func (s *HTTPServer) removeWalletTag(c *gin.Context) {
+	address := validation.NormalizeAddress(c.Param("address"))
+	removed, err := s.nuntiare.RemoveWalletTag(address, c.Param("tag"))
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidWalletTag) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "tag", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to remove wallet tag", "error", err, "address", address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to remove wallet tag"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "wallet tag not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// listTags is a handler for the GET /admin/tags endpoint.
+// It returns the tags in use with the number of wallets carrying each.
+func (s *HTTPServer) listTags(c *gin.Context) {
+	counts, err := s.nuntiare.GetWalletTagCounts()
+	if err != nil {
+		s.logger.Error("Failed to count wallet tags", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "failed to count wallet tags"})
+		return
+	}
+	if counts == nil {
+		counts = []*models.WalletTagCount{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "tags": counts})
+}
@@ -0,0 +1,116 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// WebPushSubscriptionRequest represents the JSON body for subscribing a browser.
+// Endpoint and keys are the fields of PushSubscription.toJSON().
+type WebPushSubscriptionRequest struct {
+	Address  string `json:"address" binding:"required"`
+	Endpoint string `json:"endpoint" binding:"required,max=2048"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required,max=128"`
+		Auth   string `json:"auth" binding:"required,max=64"`
+	} `json:"keys"`
+}
+
+// WebPushSubscriptionsResponse represents the browser push subscriptions of a wallet
+type WebPushSubscriptionsResponse struct {
+	Success       bool                          `json:"success"`
+	Subscriptions []*models.WebPushSubscription `json:"subscriptions"`
+}
+
+// webPushKey is a handler for the GET /webpush/key endpoint.
+// It returns the VAPID public key browsers pass as applicationServerKey when subscribing.
+func (s *HTTPServer) webPushKey(c *gin.Context) {
+	key := s.nuntiare.WebPushPublicKey()
+	if key == "" {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Web Push is not enabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "public_key": key})
+}
+
+// subscribeWebPush is a handler for the PUT /webpush/subscriptions endpoint.
+// It stores the push subscription of a browser or refreshes its keys.
+func (s *HTTPServer) subscribeWebPush(c *gin.Context) {
+	var req WebPushSubscriptionRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	subscription := &models.WebPushSubscription{
+		WalletAddress: wallet.Address,
+		Endpoint:      req.Endpoint,
+		P256dh:        req.Keys.P256dh,
+		Auth:          req.Keys.Auth,
+	}
+	if err := s.nuntiare.AddWebPushSubscription(subscription); err != nil {
+		if errors.Is(err, models.ErrInvalidWebPushSubscription) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "subscription", Code: CodeInvalid, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to add web push subscription", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to add web push subscription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "subscription": subscription})
+}
+
+// listWebPushSubscriptions is a handler for the GET /webpush/subscriptions endpoint.
+// It returns the browser push subscriptions of the wallet.
+func (s *HTTPServer) listWebPushSubscriptions(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	subscriptions, err := s.nuntiare.GetWebPushSubscriptions(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to get web push subscriptions", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get web push subscriptions"})
+		return
+	}
+	if subscriptions == nil {
+		subscriptions = []*models.WebPushSubscription{}
+	}
+
+	c.JSON(http.StatusOK, WebPushSubscriptionsResponse{Success: true, Subscriptions: subscriptions})
+}
+
+// unsubscribeWebPush is a handler for the DELETE /webpush/subscriptions endpoint.
+// It removes the subscription with the endpoint given as query parameter, e.g. when the user turns notifications off.
+func (s *HTTPServer) unsubscribeWebPush(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	removed, err := s.nuntiare.RemoveWebPushSubscription(wallet.Address, c.Query("endpoint"))
+	if err != nil {
+		s.logger.Error("Failed to remove web push subscription", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to remove web push subscription"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
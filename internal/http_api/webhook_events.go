@@ -0,0 +1,61 @@
+package http_api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// getWebhookEvent is a handler for the GET /wallet/webhook/events/:id endpoint.
+// It returns the delivery state of a webhook event of the wallet.
+func (s *HTTPServer) getWebhookEvent(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	event, err := s.nuntiare.GetWebhookEvent(wallet.Address, c.Param("id"))
+	if err != nil {
+		s.logger.Error("Failed to get webhook event", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get webhook event"})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Webhook event not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "event": event})
+}
+
+// replayWebhookEvent is a handler for the POST /wallet/webhook/events/:id/replay endpoint.
+// It sends a webhook event of the wallet to its webhook once more and returns the delivery result.
+func (s *HTTPServer) replayWebhookEvent(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	replay, err := s.nuntiare.ReplayWebhookEvent(wallet.Address, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, models.ErrNoWebhook) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": "The wallet has no webhook URL"})
+			return
+		}
+		if errors.Is(err, models.ErrNoAutomationHook) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "error": "The automation hook was removed"})
+			return
+		}
+		s.logger.Error("Failed to replay webhook event", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to replay webhook event"})
+		return
+	}
+	if replay == nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Webhook event not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": replay.Error == "", "replay": replay})
+}
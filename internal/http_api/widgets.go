@@ -0,0 +1,155 @@
+package http_api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// WidgetFeedCacheMaxAge is how long browsers and CDNs may reuse a widget feed response
+const WidgetFeedCacheMaxAge = 60 * time.Second
+
+// jsonpCallbackPattern matches the JSONP callback names accepted by widget feeds, e.g. "cb" or "widget.render"
+var jsonpCallbackPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// WidgetFeedRequest represents the JSON body for enabling a widget feed
+type WidgetFeedRequest struct {
+	Address string   `json:"address" binding:"required"`
+	Fields  []string `json:"fields"` // Published fields, amount and time when empty
+	Rotate  bool     `json:"rotate"` // Replace the signing key, revoking the URLs handed out before
+}
+
+// WidgetFeedResponse represents the widget feed of a wallet and its signed URL
+type WidgetFeedResponse struct {
+	Success bool               `json:"success"`
+	Widget  *models.WidgetFeed `json:"widget"`
+	URL     string             `json:"url"`
+}
+
+// WidgetItemsResponse represents the transfers of a public widget feed
+type WidgetItemsResponse struct {
+	Success bool                 `json:"success"`
+	Items   []*models.WidgetItem `json:"items"`
+}
+
+// setWidgetFeed is a handler for the PUT /wallet/widget endpoint.
+// It enables the public widget feed of the wallet or changes its published fields.
+func (s *HTTPServer) setWidgetFeed(c *gin.Context) {
+	var req WidgetFeedRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.logger.Debug("Invalid request body", "error", err)
+		respondValidationErrors(c, "Invalid request body: "+err.Error(), bindingErrors(err)...)
+		return
+	}
+
+	wallet := s.authorizedWallet(c, req.Address)
+	if wallet == nil {
+		return
+	}
+
+	feed, err := s.nuntiare.SetWidgetFeed(wallet.Address, req.Fields, req.Rotate)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidWidgetFeed) {
+			respondValidationErrors(c, err.Error(), FieldError{Field: "fields", Code: CodeInvalidValue, Message: err.Error()})
+			return
+		}
+		s.logger.Error("Failed to set widget feed", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to set widget feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WidgetFeedResponse{Success: true, Widget: feed, URL: s.nuntiare.WidgetFeedURL(feed)})
+}
+
+// getWidgetFeed is a handler for the GET /wallet/widget endpoint.
+// It returns the widget feed of the wallet and its signed URL.
+func (s *HTTPServer) getWidgetFeed(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	feed, err := s.nuntiare.GetWidgetFeed(wallet.Address)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Widget feed not enabled"})
+			return
+		}
+		s.logger.Error("Failed to get widget feed", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get widget feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, WidgetFeedResponse{Success: true, Widget: feed, URL: s.nuntiare.WidgetFeedURL(feed)})
+}
+
+// removeWidgetFeed is a handler for the DELETE /wallet/widget endpoint.
+// It disables the widget feed of the wallet.
+func (s *HTTPServer) removeWidgetFeed(c *gin.Context) {
+	wallet := s.authorizedWallet(c, c.Query("address"))
+	if wallet == nil {
+		return
+	}
+
+	removed, err := s.nuntiare.RemoveWidgetFeed(wallet.Address)
+	if err != nil {
+		s.logger.Error("Failed to remove widget feed", "error", err, "address", wallet.Address)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to remove widget feed"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Widget feed not enabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// widgetFeed is a handler for the public /widget/:address endpoint.
+// It returns the latest transfers of a wallet that enabled its widget feed, redacted to the published
+// fields, as JSON or as JSONP with a callback parameter. The URL must carry the feed's signature.
+func (s *HTTPServer) widgetFeed(c *gin.Context) {
+	callback := c.Query("callback")
+	if callback != "" && (len(callback) > 64 || !jsonpCallbackPattern.MatchString(callback)) {
+		message := "callback must be a JavaScript identifier of at most 64 characters"
+		respondValidationErrors(c, message, FieldError{Field: "callback", Code: CodeInvalidValue, Message: message})
+		return
+	}
+	limit := 0
+	if limitStr := c.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l < 1 {
+			respondValidationErrors(c, "limit must be a positive integer",
+				FieldError{Field: "limit", Code: CodeInvalidValue, Message: "limit must be a positive integer"})
+			return
+		}
+		limit = l
+	}
+
+	items, err := s.nuntiare.GetWidgetItems(c.Param("address"), c.Query("sig"), limit)
+	if err != nil {
+		// Unsigned requests can't tell whether a wallet enabled its feed
+		if errors.Is(err, models.ErrUnauthorized) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "error": "Widget feed not found"})
+			return
+		}
+		s.logger.Error("Failed to get widget feed", "error", err, "address", c.Param("address"))
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "error": "Failed to get widget feed"})
+		return
+	}
+
+	// Widgets are embedded on any site
+	c.Header("Access-Control-Allow-Origin", "*")
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(WidgetFeedCacheMaxAge.Seconds())))
+	c.Header("X-Content-Type-Options", "nosniff")
+	// Wrapped in the callback when given
+	c.JSONP(http.StatusOK, WidgetItemsResponse{Success: true, Items: items})
+}
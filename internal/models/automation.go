@@ -0,0 +1,91 @@
+package models
+
+import "time"
+
+// AutomationHook is a REST hook subscription of an automation platform (Zapier, IFTTT, Make, ...). Notifications
+// of the wallet are POSTed to the target URL as AutomationItem, so automations run without polling.
+type AutomationHook struct {
+	// ID is the random public identifier of the subscription, used to unsubscribe.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// WalletAddress is the wallet whose notifications are sent to the hook.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;index"`
+	// TargetURL is the URL the platform receives the notifications at.
+	TargetURL string `json:"target_url" gorm:"column:target_url;not null"`
+	// EventType limits the hook to notifications of one event type, empty for all.
+	EventType string `json:"event" gorm:"column:event_type"`
+	// CreatedAt is the Unix timestamp when the platform subscribed.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+	// Secret signs the deliveries to the hook. It is only returned when the platform subscribes.
+	Secret string `json:"-" gorm:"column:secret"`
+}
+
+// TableName specifies the table name for GORM
+func (AutomationHook) TableName() string {
+	return "automation_hooks"
+}
+
+// Matches reports whether the hook receives the notification
+func (h *AutomationHook) Matches(notification *Notification) bool {
+	return h.EventType == "" || h.EventType == notification.EventType
+}
+
+// AutomationItem is a notification flattened for automation platforms: a single level of readable values,
+// so every field maps directly to a spreadsheet column or a message placeholder
+type AutomationItem struct {
+	ID             string `json:"id"`
+	Wallet         string `json:"wallet"`
+	EventType      string `json:"event_type"`
+	Message        string `json:"message"`
+	From           string `json:"from"`
+	Amount         string `json:"amount"` // Decimal amount without scientific notation
+	Currency       string `json:"currency"`
+	TokenAddress   string `json:"token_address"`
+	TokenType      string `json:"token_type"`
+	TokenID        string `json:"token_id"` // Decimal NFT token ID
+	TxHash         string `json:"tx_hash"`
+	TxLink         string `json:"tx_link"`
+	Reference      string `json:"reference"`
+	Internal       bool   `json:"internal"`
+	Confirmed      bool   `json:"confirmed"`
+	VerifiedSender string `json:"verified_sender"`
+	CreatedAt      string `json:"created_at"` // RFC 3339 timestamp
+	// Meta carries the ID and timestamp in the shape IFTTT triggers expect
+	Meta AutomationItemMeta `json:"meta"`
+}
+
+// AutomationItemMeta identifies an item for IFTTT's deduplication
+type AutomationItemMeta struct {
+	ID        string `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// NewAutomationItem flattens a notification for automation platforms
+func NewAutomationItem(notification *Notification) *AutomationItem {
+	item := &AutomationItem{
+		ID:             notification.ID,
+		Wallet:         notification.Wallet,
+		EventType:      notification.EventType,
+		Message:        notification.Text(notification.TxLink()),
+		From:           notification.From,
+		Currency:       notification.Currency,
+		TokenAddress:   notification.TokenAddress,
+		TokenType:      notification.TokenType,
+		Reference:      notification.Reference,
+		Internal:       notification.Internal,
+		Confirmed:      notification.Confirmed,
+		VerifiedSender: notification.VerifiedSender,
+		CreatedAt:      time.Unix(notification.CreatedAt, 0).UTC().Format(time.RFC3339),
+		Meta:           AutomationItemMeta{ID: notification.ID, Timestamp: notification.CreatedAt},
+	}
+	if notification.CustomMessage == "" && !notification.MixedTransfers {
+		item.Amount = notification.FormattedAmount()
+	}
+	if notification.TokenID != "" {
+		item.TokenID = notification.DisplayTokenID()
+	}
+	if notification.TxHash != "" {
+		item.TxHash = notification.TxHash
+		item.TxLink = notification.TxLink()
+	}
+	return item
+}
@@ -4,6 +4,7 @@ import (
 	"math/big"
 
 	"github.com/core-coin/go-core/v2"
+	"github.com/core-coin/go-core/v2/common"
 	"github.com/core-coin/go-core/v2/core/types"
 )
 
@@ -11,8 +12,27 @@ import (
 type BlockchainService interface {
 	Run() error
 	NewHeaderSubscription() (core.Subscription, <-chan *types.Header, error)
+	NewPendingTransactionSubscription() (core.Subscription, <-chan common.Hash, error)
+	GetBlockNumber() (uint64, error)
 	GetBlockByNumber(number uint64) (*types.Block, error)
+	// GetTransaction returns the transaction and whether it is still pending (not mined yet)
+	GetTransaction(txHash string) (*types.Transaction, bool, error)
 	GetAddressCTNBalance(address string) (*big.Int, error)
 	GetTransactionReceipt(txHash string) (*types.Receipt, error)
+	GetCBC721TokenURI(tokenAddress string, tokenID *big.Int) (string, error)
+	CallUint256(contractAddress, method string) (*big.Int, error)
+	// TraceBlockCalls returns the call trace of each transaction in the block, in block order
+	TraceBlockCalls(block *types.Block) ([]*CallFrame, error)
 	Close() error
 }
+
+// CallFrame is a call traced by the node's callTracer. The top-level frame is the transaction itself,
+// Calls are the calls it made to other accounts (internal transactions).
+type CallFrame struct {
+	Type  string       `json:"type"` // CALL, STATICCALL, CREATE, SELFDESTRUCT, ...
+	From  string       `json:"from"`
+	To    string       `json:"to"`
+	Value string       `json:"value,omitempty"` // Hex encoded, in wei
+	Error string       `json:"error,omitempty"` // Set when the call and all its calls were reverted
+	Calls []*CallFrame `json:"calls,omitempty"`
+}
@@ -0,0 +1,76 @@
+package models
+
+// DeliveryJob is a notification that couldn't be delivered through one channel and is retried with the
+// channel's backoff until it is delivered or runs out of attempts
+type DeliveryJob struct {
+	// ID is the random identifier of the job, kept when it is moved to the dead-letter queue.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// Wallet is the wallet the notification was sent for.
+	Wallet string `json:"wallet" gorm:"column:wallet;index"`
+	// Channel is the channel the delivery failed on (telegram, email, push, ...).
+	Channel string `json:"channel" gorm:"column:channel;index"`
+	// Notification is the notification to deliver, as it was sent the first time.
+	Notification Notification `json:"notification" gorm:"column:notification;serializer:json"`
+	// Attempts is the number of delivery attempts so far, including the first one.
+	Attempts int `json:"attempts" gorm:"column:attempts"`
+	// LastError is the reason the latest attempt failed.
+	LastError string `json:"last_error" gorm:"column:last_error"`
+	// NextAttemptAt is the Unix timestamp the delivery is retried at.
+	NextAttemptAt int64 `json:"next_attempt_at" gorm:"column:next_attempt_at;index"`
+	// CreatedAt is the Unix timestamp of the first failed attempt.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// TableName specifies the table name for GORM
+func (DeliveryJob) TableName() string {
+	return "delivery_queue"
+}
+
+// DeadLetter is a delivery that ran out of attempts or whose channel was removed before it could be retried.
+// It stays in the dead-letter queue until an operator replays or discards it.
+type DeadLetter struct {
+	// ID is the identifier of the delivery job the dead letter was created from.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// Wallet is the wallet the notification was sent for.
+	Wallet string `json:"wallet" gorm:"column:wallet;index"`
+	// Channel is the channel the delivery failed on.
+	Channel string `json:"channel" gorm:"column:channel;index"`
+	// Notification is the notification that wasn't delivered.
+	Notification Notification `json:"notification" gorm:"column:notification;serializer:json"`
+	// Attempts is the number of delivery attempts, including replays.
+	Attempts int `json:"attempts" gorm:"column:attempts"`
+	// LastError is the reason the latest attempt failed.
+	LastError string `json:"last_error" gorm:"column:last_error"`
+	// CreatedAt is the Unix timestamp of the first failed attempt.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+	// FailedAt is the Unix timestamp the delivery was moved to the dead-letter queue.
+	FailedAt int64 `json:"failed_at" gorm:"column:failed_at;index"`
+	// ReplayedAt is the Unix timestamp an operator replay delivered the notification (0 = not delivered yet).
+	ReplayedAt int64 `json:"replayed_at" gorm:"column:replayed_at;default:0"`
+}
+
+// TableName specifies the table name for GORM
+func (DeadLetter) TableName() string {
+	return "delivery_dead_letters"
+}
+
+// NewDeadLetter returns the dead letter of a delivery job that gave up at the timestamp
+func NewDeadLetter(job *DeliveryJob, failedAt int64) *DeadLetter {
+	return &DeadLetter{
+		ID:           job.ID,
+		Wallet:       job.Wallet,
+		Channel:      job.Channel,
+		Notification: job.Notification,
+		Attempts:     job.Attempts,
+		LastError:    job.LastError,
+		CreatedAt:    job.CreatedAt,
+		FailedAt:     failedAt,
+	}
+}
+
+// DeliveryReplay is the result of replaying a dead letter
+type DeliveryReplay struct {
+	DeadLetter *DeadLetter `json:"dead_letter"`
+	// Error is the reason the replay wasn't delivered, if any.
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// Device is an app installation that receives notifications for a wallet.
+// A wallet can be used from several devices; each is tracked separately for push routing.
+type Device struct {
+	// ID is the auto-incremented identifier of the device record.
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// WalletAddress is the wallet the device receives notifications for.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;uniqueIndex:idx_devices_wallet_device"`
+	// DeviceID is the app-generated installation identifier, unique per wallet.
+	DeviceID string `json:"device_id" gorm:"column:device_id;not null;uniqueIndex:idx_devices_wallet_device"`
+	// OS is the operating system of the device (ios, android, web, etc.)
+	OS string `json:"os" gorm:"column:os"`
+	// PushToken is the platform push token (APNs/FCM). Empty until the app grants push permission.
+	PushToken string `json:"push_token" gorm:"column:push_token"`
+	// AppVersion is the version of the wallet app installed on the device.
+	AppVersion string `json:"app_version" gorm:"column:app_version"`
+	// Notify controls whether notifications are routed to this device.
+	Notify bool `json:"notify" gorm:"column:notify;not null"`
+	// CreatedAt is the Unix timestamp when the device was first registered.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+	// LastSeenAt is the Unix timestamp of the last registration refresh from the device.
+	// Devices not seen for DEVICE_STALE_DAYS are removed.
+	LastSeenAt int64 `json:"last_seen_at" gorm:"column:last_seen_at;index"`
+}
+
+// TableName specifies the table name for GORM
+func (Device) TableName() string {
+	return "devices"
+}
@@ -0,0 +1,38 @@
+package models
+
+// Email delivery event types reported by provider webhooks
+const (
+	EmailEventDelivered  = "delivered"
+	EmailEventBounced    = "bounced"
+	EmailEventDeferred   = "deferred"
+	EmailEventDropped    = "dropped"
+	EmailEventComplained = "complained"
+)
+
+// EmailEvent is a delivery/bounce event reported by an email provider webhook
+type EmailEvent struct {
+	// ID is the unique identifier for the event.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// Provider is the email provider that reported the event (sendgrid, ses, mailgun).
+	Provider string `json:"provider" gorm:"column:provider;index"`
+	// Email is the recipient address the event refers to.
+	Email string `json:"email" gorm:"column:email;index"`
+	// Event is the normalized event type (delivered, bounced, deferred, dropped, complained).
+	Event string `json:"event" gorm:"column:event;index"`
+	// MessageID is the provider message ID, if reported.
+	MessageID string `json:"message_id" gorm:"column:message_id"`
+	// Reason is the provider supplied reason/diagnostic, if any.
+	Reason string `json:"reason" gorm:"column:reason"`
+	// Timestamp is the Unix timestamp of the event.
+	Timestamp int64 `json:"timestamp" gorm:"column:timestamp;index"`
+}
+
+// TableName specifies the table name for GORM
+func (EmailEvent) TableName() string {
+	return "email_events"
+}
+
+// IsPermanentFailure reports whether the event means the address should no longer receive emails
+func (e *EmailEvent) IsPermanentFailure() bool {
+	return e.Event == EmailEventBounced || e.Event == EmailEventComplained || e.Event == EmailEventDropped
+}
@@ -0,0 +1,23 @@
+package models
+
+// EmailVerification is a pending double opt-in of a wallet's email. Notifications are only emailed
+// once the link sent to the address was opened.
+type EmailVerification struct {
+	// ID is the auto-incremented identifier of the verification.
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// TokenHash is the SHA-256 hash of the token in the verification link. The token itself is not stored.
+	TokenHash string `json:"-" gorm:"column:token_hash;size:64;not null;uniqueIndex"`
+	// WalletAddress is the wallet whose email is verified.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;index"`
+	// Email is the address the link was sent to. Changing the wallet's email invalidates the link.
+	Email string `json:"email" gorm:"column:email;not null"`
+	// CreatedAt is the Unix timestamp when the link was sent.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+	// ExpiresAt is the Unix timestamp after which the link can't be used.
+	ExpiresAt int64 `json:"expires_at" gorm:"column:expires_at"`
+}
+
+// TableName specifies the table name for GORM
+func (EmailVerification) TableName() string {
+	return "email_verifications"
+}
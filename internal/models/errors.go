@@ -0,0 +1,81 @@
+package models
+
+import "errors"
+
+var (
+	// ErrUnauthorized is returned when a request fails authentication
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInvalidBlockRange is returned when a requested block range is empty or too large
+	ErrInvalidBlockRange = errors.New("invalid block range")
+	// ErrInvalidCursor is returned when a list cursor is malformed or doesn't match the requested sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrUpgradeRequired is returned when the client app version is below the supported minimum
+	ErrUpgradeRequired = errors.New("upgrade required")
+	// ErrInvalidSchedule is returned when a scheduled notification has no message or is due too far in the future
+	ErrInvalidSchedule = errors.New("invalid schedule")
+	// ErrInvalidWebhookURL is returned when a webhook URL is not an absolute HTTPS URL
+	ErrInvalidWebhookURL = errors.New("invalid webhook URL")
+	// ErrInvalidEventType is returned when an event type is not one of EventTypes
+	ErrInvalidEventType = errors.New("invalid event type")
+	// ErrInvalidDiscord is returned when a Discord webhook URL or channel ID is malformed
+	ErrInvalidDiscord = errors.New("invalid discord destination")
+	// ErrInvalidPhone is returned when a phone number is not in E.164 format
+	ErrInvalidPhone = errors.New("invalid phone number")
+	// ErrInvalidMatrixRoom is returned when a Matrix room ID is malformed
+	ErrInvalidMatrixRoom = errors.New("invalid matrix room")
+	// ErrInvalidNtfyTopic is returned when an ntfy topic URL is not an HTTPS URL ending in a topic name
+	ErrInvalidNtfyTopic = errors.New("invalid ntfy topic")
+	// ErrInvalidPushoverUserKey is returned when a Pushover user key is malformed
+	ErrInvalidPushoverUserKey = errors.New("invalid pushover user key")
+	// ErrInvalidMinAmount is returned when a minimum notification amount has no currency or is not a positive number
+	ErrInvalidMinAmount = errors.New("invalid minimum amount")
+	// ErrInvalidTokenPreference is returned when a token preference names neither XCB nor a token contract address
+	ErrInvalidTokenPreference = errors.New("invalid token preference")
+	// ErrInvalidWalletTag is returned when a wallet tag is empty, too long or has characters other than a-z, 0-9, _ and -
+	ErrInvalidWalletTag = errors.New("invalid wallet tag")
+	// ErrInvalidMessageTemplate is returned when a message template override doesn't parse or render
+	ErrInvalidMessageTemplate = errors.New("invalid message template")
+	// ErrNoActiveSubscription is returned when a wallet without remaining subscription time transfers its subscription
+	ErrNoActiveSubscription = errors.New("no active subscription")
+	// ErrInvalidSubscriptionTransfer is returned when a subscription can't be transferred to the destination wallet
+	ErrInvalidSubscriptionTransfer = errors.New("invalid subscription transfer")
+	// ErrInvalidOriginLink is returned when an origin can't be linked to or unlinked from a wallet
+	ErrInvalidOriginLink = errors.New("invalid origin link")
+	// ErrInvalidUserLink is returned when a wallet can't join or leave a user
+	ErrInvalidUserLink = errors.New("invalid user link")
+	// ErrInvalidVerificationToken is returned when an email verification token is unknown, expired or outdated
+	ErrInvalidVerificationToken = errors.New("invalid verification token")
+	// ErrInvalidAutomationHook is returned when a wallet subscribes more automation hooks than allowed
+	ErrInvalidAutomationHook = errors.New("invalid automation hook")
+	// ErrInvalidWidgetFeed is returned when a widget feed publishes unknown fields
+	ErrInvalidWidgetFeed = errors.New("invalid widget feed")
+	// ErrInvalidPaymentRequest is returned when a payment request has an unknown token, a negative amount or too long a memo
+	ErrInvalidPaymentRequest = errors.New("invalid payment request")
+	// ErrNoWebhook is returned when a webhook event is replayed for a wallet without a webhook URL
+	ErrNoWebhook = errors.New("no webhook configured")
+	// ErrNoAutomationHook is returned when an event of an automation hook is replayed after the hook was removed
+	ErrNoAutomationHook = errors.New("automation hook was removed")
+	// ErrChannelDisabled is returned when a failed delivery is retried through a channel the wallet no longer uses
+	ErrChannelDisabled = errors.New("channel no longer enabled")
+	// ErrAlreadyReplayed is returned when a dead letter that was already delivered by a replay is replayed again
+	ErrAlreadyReplayed = errors.New("dead letter already replayed")
+	// ErrInvalidExchange is returned when an exchange, a bulk address registration or a credit is malformed
+	ErrInvalidExchange = errors.New("invalid exchange request")
+	// ErrDepositNotConfirmed is returned when an exchange deposit that isn't confirmed or was already credited is credited
+	ErrDepositNotConfirmed = errors.New("deposit not confirmed")
+	// ErrWalletExists is returned when a wallet is added whose address is already registered
+	ErrWalletExists = errors.New("wallet already registered")
+	// ErrOriginMismatch is returned when a registered wallet is registered again by an app not linked to it
+	ErrOriginMismatch = errors.New("wallet registered by another app")
+	// ErrSubscriptionAddressTaken is returned when a wallet is registered with the subscription address of another
+	// user's wallets without proving the ownership of the address
+	ErrSubscriptionAddressTaken = errors.New("subscription address used by another wallet")
+	// ErrInvalidSignature is returned when a signed message wasn't signed with the key of the wallet
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrOwnershipNotProven is returned when a feature requiring a proof of the wallet's ownership is used without one
+	ErrOwnershipNotProven = errors.New("wallet ownership not proven")
+	// ErrInvalidWebPushSubscription is returned when a Web Push subscription has a malformed endpoint or keys
+	ErrInvalidWebPushSubscription = errors.New("invalid web push subscription")
+	// ErrDiscordNotVerified is returned when the verification code of a Discord channel wasn't posted to the channel
+	ErrDiscordNotVerified = errors.New("discord channel not verified")
+)
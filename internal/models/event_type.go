@@ -0,0 +1,51 @@
+package models
+
+// Notification event types. Carried on every notification so preferences, templates
+// and metrics don't have to infer the kind of event from the token type.
+const (
+	EventIncomingXCB          = "incoming_xcb"          // Native XCB received
+	EventIncomingCBC20        = "incoming_cbc20"        // CBC20 tokens received
+	EventNFTReceived          = "nft_received"          // CBC721 token received
+	EventIncomingPending      = "incoming_pending"      // XCB or CBC20 transfer in the transaction pool, not mined yet
+	EventApproval             = "approval"              // Token spending approval of the wallet (reserved, not detected yet)
+	EventPaymentReceived      = "payment_received"      // Subscription payment received, the subscription is active
+	EventPaymentRequestPaid   = "payment_request_paid"  // Payment request of the wallet paid, or paid by the wallet
+	EventSubscriptionExpiring = "subscription_expiring" // Subscription about to expire (reserved, not sent yet)
+	EventAdminBroadcast       = "admin_broadcast"       // Message scheduled by an admin
+	EventAppUpgrade           = "app_upgrade"           // Wallet app version is no longer supported
+)
+
+// EventTypes lists all event types
+var EventTypes = []string{
+	EventIncomingXCB,
+	EventIncomingCBC20,
+	EventNFTReceived,
+	EventIncomingPending,
+	EventApproval,
+	EventPaymentReceived,
+	EventPaymentRequestPaid,
+	EventSubscriptionExpiring,
+	EventAdminBroadcast,
+	EventAppUpgrade,
+}
+
+// IsEventType reports whether the value is a known event type
+func IsEventType(value string) bool {
+	for _, eventType := range EventTypes {
+		if eventType == value {
+			return true
+		}
+	}
+	return false
+}
+
+// TransferEventType returns the event type of an incoming transfer of the token type (empty for XCB)
+func TransferEventType(tokenType string) string {
+	switch tokenType {
+	case "CBC20":
+		return EventIncomingCBC20
+	case "CBC721":
+		return EventNFTReceived
+	}
+	return EventIncomingXCB
+}
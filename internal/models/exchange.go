@@ -0,0 +1,186 @@
+package models
+
+import "math"
+
+// Exchange deposit statuses
+const (
+	ExchangeDepositPending   = "pending"   // Detected, waiting for the exchange's confirmations
+	ExchangeDepositConfirmed = "confirmed" // Confirmed, waiting for the webhook to acknowledge it
+	ExchangeDepositDelivered = "delivered" // Acknowledged by the exchange's webhook
+	ExchangeDepositCredited  = "credited"  // Reported credited to the customer by the exchange
+	ExchangeDepositOrphaned  = "orphaned"  // Its block left the canonical chain before it was confirmed
+	ExchangeDepositReverted  = "reverted"  // Its transaction failed, nothing was transferred
+)
+
+// ExchangeDepositStatuses lists the exchange deposit statuses
+var ExchangeDepositStatuses = []string{ExchangeDepositPending, ExchangeDepositConfirmed, ExchangeDepositDelivered,
+	ExchangeDepositCredited, ExchangeDepositOrphaned, ExchangeDepositReverted}
+
+// WebhookEventExchangeDeposit is the type of the webhook events of confirmed exchange deposits
+const WebhookEventExchangeDeposit = "exchange_deposit"
+
+// Exchange is an exchange integrated in deposit-confirmation mode. Transfers to its deposit addresses are
+// reported to its webhook once they have the required confirmations, in chain order per address.
+type Exchange struct {
+	// ID is the random public identifier of the exchange.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// Name is the display name of the exchange.
+	Name string `json:"name" gorm:"column:name;not null"`
+	// APIKeyHash is the SHA-256 hash of the API key the exchange authenticates with.
+	APIKeyHash string `json:"-" gorm:"column:api_key_hash;uniqueIndex;not null"`
+	// WebhookURL receives the confirmed deposits.
+	WebhookURL string `json:"webhook_url" gorm:"column:webhook_url;not null"`
+	// WebhookSecret signs the webhook requests.
+	WebhookSecret string `json:"-" gorm:"column:webhook_secret;not null"`
+	// Confirmations is the number of blocks, including the deposit's, before a deposit is confirmed.
+	Confirmations int `json:"confirmations" gorm:"column:confirmations"`
+	// CreatedAt is the Unix timestamp when the exchange was added.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Exchange) TableName() string {
+	return "exchanges"
+}
+
+// ExchangeCredentials is an exchange along with its API key and webhook secret, returned once when the
+// exchange is added
+type ExchangeCredentials struct {
+	Exchange      *Exchange `json:"exchange"`
+	APIKey        string    `json:"api_key"`
+	WebhookSecret string    `json:"webhook_secret"`
+}
+
+// ExchangeAddress is a deposit address registered by an exchange
+type ExchangeAddress struct {
+	// Address is the normalized deposit address, an address belongs to a single exchange.
+	Address string `json:"address" gorm:"column:address;primaryKey"`
+	// ExchangeID is the exchange the address belongs to.
+	ExchangeID string `json:"exchange_id" gorm:"column:exchange_id;index;not null"`
+	// Label is the exchange's reference of the address, e.g. its customer ID.
+	Label string `json:"label,omitempty" gorm:"column:label"`
+	// CreatedAt is the Unix timestamp when the address was registered.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ExchangeAddress) TableName() string {
+	return "exchange_addresses"
+}
+
+// ExchangeAddressRow is one address of a bulk registration
+type ExchangeAddressRow struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+}
+
+// Outcomes of a row of a bulk address registration
+const (
+	ExchangeAddressRegistered = "registered" // Added to the exchange's deposit addresses
+	ExchangeAddressUpdated    = "updated"    // Already registered by the exchange, the label was updated
+	ExchangeAddressInvalid    = "invalid"    // Not a valid address of the network
+	ExchangeAddressTaken      = "taken"      // Registered by another exchange
+)
+
+// ExchangeAddressResult is the outcome of one row of a bulk address registration
+type ExchangeAddressResult struct {
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ExchangeAddressReport summarizes a bulk address registration
+type ExchangeAddressReport struct {
+	Registered int                      `json:"registered"`
+	Updated    int                      `json:"updated"`
+	Failed     int                      `json:"failed"`
+	Results    []*ExchangeAddressResult `json:"results"`
+}
+
+// ExchangeDeposit is a transfer to a deposit address of an exchange
+type ExchangeDeposit struct {
+	// ID is the auto-incremented identifier of the deposit.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// ExchangeID is the exchange owning the deposit address.
+	ExchangeID string `json:"-" gorm:"column:exchange_id;index:idx_exchange_deposits_exchange_detected,priority:1;not null"`
+	// Address is the deposit address that received the transfer.
+	Address string `json:"address" gorm:"column:address;index:idx_exchange_deposits_address_block,priority:1;not null"`
+	// Label is the exchange's reference of the address at detection time.
+	Label string `json:"label,omitempty" gorm:"column:label"`
+	// From is the sender of the transfer.
+	From string `json:"from" gorm:"column:from"`
+	// Token is NativeTokenPreference for XCB or the normalized contract address of the token.
+	Token string `json:"token" gorm:"column:token;uniqueIndex:idx_exchange_deposits_transfer,priority:3"`
+	// Currency is the symbol of the token (e.g. XCB, CTN).
+	Currency string `json:"currency" gorm:"column:currency"`
+	// TokenType is CBC20, CBC721 or empty for XCB.
+	TokenType string `json:"token_type,omitempty" gorm:"column:token_type"`
+	// TokenID is the ID of a CBC721 token.
+	TokenID string `json:"token_id,omitempty" gorm:"column:token_id"`
+	// Amount is the transferred amount in token units, rounded to a float.
+	Amount float64 `json:"amount" gorm:"column:amount"`
+	// Value is the exact transferred amount in the token's base units as a decimal integer, empty for CBC721.
+	Value string `json:"value,omitempty" gorm:"column:value"`
+	// TxHash is the hash of the transaction.
+	TxHash string `json:"tx_hash" gorm:"column:tx_hash;uniqueIndex:idx_exchange_deposits_transfer,priority:1;not null"`
+	// TransferIndex is the position of the transfer among the transaction's detected transfers.
+	TransferIndex int `json:"transfer_index" gorm:"column:transfer_index;uniqueIndex:idx_exchange_deposits_transfer,priority:4"`
+	// BlockNumber and BlockHash identify the block the transfer was mined in.
+	BlockNumber uint64 `json:"block_number" gorm:"column:block_number;index:idx_exchange_deposits_address_block,priority:2"`
+	BlockHash   string `json:"block_hash" gorm:"column:block_hash;uniqueIndex:idx_exchange_deposits_transfer,priority:2"`
+	// Status is pending, confirmed, delivered, credited, orphaned or reverted.
+	Status string `json:"status" gorm:"column:status;index"`
+	// WebhookEventID is the ID of the webhook event reporting the deposit, the same for every attempt.
+	WebhookEventID string `json:"webhook_event_id,omitempty" gorm:"column:webhook_event_id"`
+	// Attempts is the number of webhook delivery attempts.
+	Attempts int `json:"attempts" gorm:"column:attempts"`
+	// LastError is the reason the latest webhook delivery failed.
+	LastError string `json:"last_error,omitempty" gorm:"column:last_error"`
+	// NextAttemptAt is the Unix timestamp the webhook delivery is retried at.
+	NextAttemptAt int64 `json:"-" gorm:"column:next_attempt_at"`
+	// CreditedAmount is the amount the exchange reported crediting.
+	CreditedAmount float64 `json:"credited_amount,omitempty" gorm:"column:credited_amount"`
+	// DetectedAt, ConfirmedAt, DeliveredAt and CreditedAt are the Unix timestamps of the status changes.
+	DetectedAt  int64 `json:"detected_at" gorm:"column:detected_at;index:idx_exchange_deposits_exchange_detected,priority:2"`
+	ConfirmedAt int64 `json:"confirmed_at,omitempty" gorm:"column:confirmed_at"`
+	DeliveredAt int64 `json:"delivered_at,omitempty" gorm:"column:delivered_at"`
+	CreditedAt  int64 `json:"credited_at,omitempty" gorm:"column:credited_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ExchangeDeposit) TableName() string {
+	return "exchange_deposits"
+}
+
+// CreditMismatch reports whether the exchange credited a different amount than was deposited. Amounts are
+// compared with a tolerance for the float conversion of token units.
+func (d *ExchangeDeposit) CreditMismatch() bool {
+	return d.Status == ExchangeDepositCredited && math.Abs(d.CreditedAmount-d.Amount) > 1e-9*math.Max(1, d.Amount)
+}
+
+// ExchangeReconciliationTotals are the deposits of one token in a reconciliation report
+type ExchangeReconciliationTotals struct {
+	Token    string `json:"token"`
+	Currency string `json:"currency"`
+	// Detected counts all deposits except orphaned and reverted ones, Confirmed those that reached the confirmations
+	// (delivered and credited included), Credited those the exchange reported credited.
+	Detected        int64   `json:"detected"`
+	DetectedAmount  float64 `json:"detected_amount"`
+	Confirmed       int64   `json:"confirmed"`
+	ConfirmedAmount float64 `json:"confirmed_amount"`
+	Credited        int64   `json:"credited"`
+	CreditedAmount  float64 `json:"credited_amount"`
+	Orphaned        int64   `json:"orphaned"`
+	Reverted        int64   `json:"reverted"`
+}
+
+// ExchangeReconciliation compares the deposits detected for an exchange with the ones it credited
+type ExchangeReconciliation struct {
+	From   int64                           `json:"from"`
+	To     int64                           `json:"to"`
+	Tokens []*ExchangeReconciliationTotals `json:"tokens"`
+	// Uncredited are confirmed deposits the exchange hasn't reported credited yet, oldest first.
+	Uncredited []*ExchangeDeposit `json:"uncredited"`
+	// Mismatched are credited deposits whose credited amount differs from the deposited amount.
+	Mismatched []*ExchangeDeposit `json:"mismatched"`
+}
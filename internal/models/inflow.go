@@ -0,0 +1,11 @@
+package models
+
+// PaymentInflow is the total of the subscription payments received in a time bucket
+type PaymentInflow struct {
+	// Bucket is the Unix timestamp of the bucket start (aligned to UTC)
+	Bucket int64 `json:"bucket"`
+	// Amount is the total CTN received in the bucket
+	Amount float64 `json:"amount"`
+	// Payments is the number of payments received in the bucket
+	Payments int64 `json:"payments"`
+}
@@ -0,0 +1,203 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+const (
+	// DefaultListLimit is the page size used when a list request doesn't specify one
+	DefaultListLimit = 50
+	// MaxListLimit caps the page size of list requests
+	MaxListLimit = 200
+)
+
+// FilterType determines how a list filter value is parsed and validated
+type FilterType int
+
+const (
+	FilterString FilterType = iota
+	FilterBool
+	FilterAddress
+)
+
+// ListFilter maps a filter query parameter to a database column
+type ListFilter struct {
+	Column string
+	Type   FilterType
+	// Condition replaces the "Column = ?" match, e.g. for filters on other tables. Has one placeholder for the value.
+	Condition string
+}
+
+// ListFields describes the sort and filter parameters a list endpoint accepts
+type ListFields struct {
+	// Sort maps sort parameter names to database columns
+	Sort map[string]string
+	// DefaultSort is used when the request has no sort parameter ("-" prefix = descending)
+	DefaultSort string
+	// Filters maps filter parameter names to database columns
+	Filters map[string]ListFilter
+	// KeyColumn is the unique column used as a tie-breaker for cursors
+	KeyColumn string
+}
+
+// ListOptions is a validated list request
+type ListOptions struct {
+	// Limit is the maximum number of items in the page
+	Limit int
+	// Sort is the sort parameter name (key of ListFields.Sort)
+	Sort string
+	// Desc sorts in descending order
+	Desc bool
+	// Filters holds the parsed filter values (string or bool) by parameter name
+	Filters map[string]any
+	// Cursor is the position after which the page starts (nil = first page)
+	Cursor *Cursor
+}
+
+// Cursor points to the last item of the previous page
+type Cursor struct {
+	// Sort is the sort the cursor was issued for, including the "-" prefix when descending
+	Sort string `json:"s"`
+	// Value is the sort column value of the last item
+	Value string `json:"v"`
+	// Key is the key column value of the last item
+	Key string `json:"k"`
+}
+
+// Encode returns the opaque string representation of the cursor
+func (c *Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor returned by Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// Page is a single page of a list result
+type Page[T any] struct {
+	Items      []*T
+	NextCursor string // Empty when there are no more items
+}
+
+// WalletListFields are the sort and filter parameters of the wallet list
+var WalletListFields = ListFields{
+	Sort: map[string]string{
+		"created_at":              "created_at",
+		"address":                 "address",
+		"subscription_expires_at": "subscription_expires_at",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]ListFilter{
+		"originator":  {Column: "originator", Type: FilterString},
+		"network":     {Column: "network", Type: FilterString},
+		"paid":        {Column: "paid", Type: FilterBool},
+		"active":      {Column: "active", Type: FilterBool},
+		"whitelisted": {Column: "whitelisted", Type: FilterBool},
+		"tag":         {Condition: "address IN (SELECT wallet_address FROM wallet_tags WHERE tag = LOWER(?))", Type: FilterString},
+	},
+	KeyColumn: "address",
+}
+
+// NotificationListFields are the sort and filter parameters of the notification list
+var NotificationListFields = ListFields{
+	Sort: map[string]string{
+		"created_at": "created_at",
+		"amount":     "amount",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]ListFilter{
+		"wallet":     {Column: "wallet", Type: FilterAddress},
+		"currency":   {Column: "currency", Type: FilterString},
+		"token_type": {Column: "token_type", Type: FilterString},
+		"tx_hash":    {Column: "tx_hash", Type: FilterString},
+		"internal":   {Column: "internal", Type: FilterBool},
+		"reference":  {Column: "reference", Type: FilterString},
+		"event_type": {Column: "event_type", Type: FilterString},
+	},
+	KeyColumn: "id",
+}
+
+// NotificationHistoryListFields are the sort and filter parameters of a wallet's notification history
+var NotificationHistoryListFields = ListFields{
+	Sort: map[string]string{
+		"created_at": "created_at",
+	},
+	DefaultSort: "-created_at",
+	Filters: map[string]ListFilter{
+		"channel":    {Column: "channel", Type: FilterString},
+		"status":     {Column: "status", Type: FilterString},
+		"tx_hash":    {Column: "tx_hash", Type: FilterString},
+		"event_type": {Column: "event_type", Type: FilterString},
+	},
+	KeyColumn: "id",
+}
+
+// DeliveryJobListFields are the sort and filter parameters of the delivery queue
+var DeliveryJobListFields = ListFields{
+	Sort: map[string]string{
+		"next_attempt_at": "next_attempt_at",
+		"created_at":      "created_at",
+	},
+	DefaultSort: "next_attempt_at",
+	Filters: map[string]ListFilter{
+		"wallet":  {Column: "wallet", Type: FilterAddress},
+		"channel": {Column: "channel", Type: FilterString},
+	},
+	KeyColumn: "id",
+}
+
+// DeadLetterListFields are the sort and filter parameters of the dead-letter queue
+var DeadLetterListFields = ListFields{
+	Sort: map[string]string{
+		"failed_at":  "failed_at",
+		"created_at": "created_at",
+	},
+	DefaultSort: "-failed_at",
+	Filters: map[string]ListFilter{
+		"wallet":   {Column: "wallet", Type: FilterAddress},
+		"channel":  {Column: "channel", Type: FilterString},
+		"replayed": {Condition: "(replayed_at > 0) = ?", Type: FilterBool},
+	},
+	KeyColumn: "id",
+}
+
+// ExchangeDepositListFields are the sort and filter parameters of an exchange's deposit list
+var ExchangeDepositListFields = ListFields{
+	Sort: map[string]string{
+		"detected_at":  "detected_at",
+		"block_number": "block_number",
+	},
+	DefaultSort: "-detected_at",
+	Filters: map[string]ListFilter{
+		"status":  {Column: "status", Type: FilterString},
+		"address": {Column: "address", Type: FilterAddress},
+		"token":   {Column: "token", Type: FilterString},
+		"tx_hash": {Column: "tx_hash", Type: FilterString},
+	},
+	KeyColumn: "id",
+}
+
+// PaymentListFields are the sort and filter parameters of the subscription payment list
+var PaymentListFields = ListFields{
+	Sort: map[string]string{
+		"timestamp": "timestamp",
+		"amount":    "amount",
+	},
+	DefaultSort: "-timestamp",
+	Filters: map[string]ListFilter{
+		"address": {Column: "address", Type: FilterAddress},
+		"wallet":  {Column: "wallet", Type: FilterAddress},
+	},
+	KeyColumn: "id",
+}
@@ -0,0 +1,14 @@
+package models
+
+// MaintenanceMode is the read-only maintenance mode shared by all instances, stored in a single row
+type MaintenanceMode struct {
+	ID       int  `gorm:"column:id;primaryKey"`
+	ReadOnly bool `gorm:"column:read_only;not null"`
+	// UpdatedAt is the Unix timestamp of the latest switch
+	UpdatedAt int64 `gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (MaintenanceMode) TableName() string {
+	return "maintenance_mode"
+}
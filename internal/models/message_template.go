@@ -0,0 +1,19 @@
+package models
+
+// MessageTemplate overrides the embedded message template of a language
+type MessageTemplate struct {
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// Lang is the language the template is used for (en, es, ...)
+	Lang string `json:"lang" gorm:"column:lang;uniqueIndex:idx_message_template_lang_name"`
+	// Name is the template that is overridden (telegram, email or email_subject)
+	Name string `json:"name" gorm:"column:name;uniqueIndex:idx_message_template_lang_name"`
+	// Body is the Go text/template source
+	Body string `json:"body" gorm:"column:body;type:text"`
+	// UpdatedAt is the Unix timestamp of the latest change
+	UpdatedAt int64 `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (MessageTemplate) TableName() string {
+	return "message_templates"
+}
@@ -0,0 +1,19 @@
+package models
+
+// NFTImage is the image of a CBC721 token resolved from its metadata, shown on notification detail pages.
+// Images are resolved once in the background, detail pages only read them.
+type NFTImage struct {
+	// TokenAddress is the contract address of the token.
+	TokenAddress string `json:"token_address" gorm:"column:token_address;primaryKey;size:64"`
+	// TokenID is the hex encoded ID of the token.
+	TokenID string `json:"token_id" gorm:"column:token_id;primaryKey;size:80"`
+	// ImageURL is the http(s) URL of the image, empty when it couldn't be resolved.
+	ImageURL string `json:"image_url" gorm:"column:image_url"`
+	// ResolvedAt is the Unix timestamp of the latest resolution attempt.
+	ResolvedAt int64 `json:"resolved_at" gorm:"column:resolved_at"`
+}
+
+// TableName specifies the table name for GORM
+func (NFTImage) TableName() string {
+	return "nft_images"
+}
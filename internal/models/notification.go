@@ -1,14 +1,45 @@
 package models
 
+import "strings"
+
 type NotificationProvider struct {
 	// ID is the unique identifier for the notification provider.
 	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
 	// Address is the address of the wallet.
 	Address string `json:"address" gorm:"column:address;unique;not null"`
+	// MutedEventTypes is a comma-separated list of event types the wallet is not notified about.
+	MutedEventTypes string `json:"muted_event_types" gorm:"column:muted_event_types"`
 	// TelegramProvider is the telegram provider associated with the notification provider.
 	TelegramProvider TelegramProvider `json:"telegram_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
 	// EmailProvider is the email provider associated with the notification provider.
 	EmailProvider EmailProvider `json:"email_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// FCMProvider is the Firebase Cloud Messaging provider associated with the notification provider.
+	FCMProvider FCMProvider `json:"fcm_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// WebhookProvider is the outbound webhook provider associated with the notification provider.
+	WebhookProvider WebhookProvider `json:"webhook_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// DiscordProvider is the Discord provider associated with the notification provider.
+	DiscordProvider DiscordProvider `json:"discord_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// PhoneProvider is the SMS provider associated with the notification provider.
+	PhoneProvider PhoneProvider `json:"phone_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// MatrixProvider is the Matrix provider associated with the notification provider.
+	MatrixProvider MatrixProvider `json:"matrix_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// NtfyProvider is the ntfy provider associated with the notification provider.
+	NtfyProvider NtfyProvider `json:"ntfy_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+	// PushoverProvider is the Pushover provider associated with the notification provider.
+	PushoverProvider PushoverProvider `json:"pushover_provider" gorm:"foreignKey:NotificationProviderID;constraint:OnDelete:CASCADE"`
+}
+
+// Mutes reports whether the wallet muted notifications of the event type
+func (p *NotificationProvider) Mutes(eventType string) bool {
+	if eventType == "" || p.MutedEventTypes == "" {
+		return false
+	}
+	for _, muted := range strings.Split(p.MutedEventTypes, ",") {
+		if muted == eventType {
+			return true
+		}
+	}
+	return false
 }
 
 type TelegramProvider struct {
@@ -20,6 +51,13 @@ type TelegramProvider struct {
 	Username string `json:"username" gorm:"column:username;not null"`
 	// ChatID is the chat ID in the telegram. Empty until user sends /start command.
 	ChatID string `json:"chat_id" gorm:"column:chat_id"`
+	// Disabled is set when Telegram reports the bot was blocked or the chat no longer exists.
+	// Cleared when the user sends /start again or updates the username.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the Telegram error that caused the provider to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+	// DisabledAt is the Unix timestamp when the provider was disabled.
+	DisabledAt int64 `json:"disabled_at" gorm:"column:disabled_at"`
 }
 
 type EmailProvider struct {
@@ -29,4 +67,152 @@ type EmailProvider struct {
 	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id"`
 	// Email is the email address of the user. Optional.
 	Email string `json:"email" gorm:"column:email"`
+	// Bounced is set when the provider reported a permanent failure (bounce, complaint) for the email.
+	// Bounced emails are skipped until the user updates the address.
+	Bounced bool `json:"bounced" gorm:"column:bounced;default:false"`
+	// Verified is set once the user opened the verification link sent to the email (double opt-in).
+	// Unverified emails don't receive notifications. Cleared when the email changes.
+	Verified bool `json:"verified" gorm:"column:verified;default:false"`
+}
+
+type FCMProvider struct {
+	// ID is the unique identifier for the FCM provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id"`
+	// Token is the FCM registration token of the wallet app. Optional.
+	Token string `json:"token" gorm:"column:token;index"`
+	// Disabled is set when FCM reports the token as unregistered or invalid.
+	// Cleared when the app registers a new token.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the FCM error that caused the provider to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+}
+
+// TableName specifies the table name for GORM
+func (FCMProvider) TableName() string {
+	return "fcm_providers"
+}
+
+type WebhookProvider struct {
+	// ID is the unique identifier for the webhook provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id;uniqueIndex"`
+	// URL is the endpoint notifications are POSTed to.
+	URL string `json:"url" gorm:"column:url"`
+	// Secret is the HMAC-SHA256 key used to sign the webhook payloads.
+	Secret string `json:"-" gorm:"column:secret"`
+}
+
+// TableName specifies the table name for GORM
+func (WebhookProvider) TableName() string {
+	return "webhook_providers"
+}
+
+type DiscordProvider struct {
+	// ID is the unique identifier for the Discord provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id;uniqueIndex"`
+	// WebhookURL is the Discord channel webhook messages are posted to. Takes precedence over ChannelID.
+	WebhookURL string `json:"-" gorm:"column:webhook_url"`
+	// ChannelID is the Discord channel the bot posts to when no webhook URL is set.
+	ChannelID string `json:"channel_id" gorm:"column:channel_id"`
+	// VerificationCode must be posted to the channel, mentioning the bot, before the bot posts notifications there.
+	// Webhook URLs need no verification, only the channel's members can create them.
+	VerificationCode string `json:"-" gorm:"column:verification_code"`
+	// ChannelVerified is set once the verification code was found in the channel.
+	ChannelVerified bool `json:"channel_verified" gorm:"column:channel_verified;default:false"`
+	// Disabled is set when Discord reports the webhook or channel as deleted or inaccessible.
+	// Cleared when the wallet registers Discord again.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the Discord error that caused the provider to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+}
+
+// TableName specifies the table name for GORM
+func (DiscordProvider) TableName() string {
+	return "discord_providers"
+}
+
+type PhoneProvider struct {
+	// ID is the unique identifier for the phone provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id;uniqueIndex"`
+	// Phone is the E.164 phone number SMS notifications are sent to.
+	Phone string `json:"phone" gorm:"column:phone"`
+	// Disabled is set when the SMS provider reports the number as invalid or unreachable.
+	// Cleared when the wallet registers a phone number again.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the SMS provider error that caused the phone to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+}
+
+// TableName specifies the table name for GORM
+func (PhoneProvider) TableName() string {
+	return "phone_providers"
+}
+
+type MatrixProvider struct {
+	// ID is the unique identifier for the Matrix provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id;uniqueIndex"`
+	// RoomID is the Matrix room the bot posts notifications to (e.g. !abc:example.org).
+	RoomID string `json:"room_id" gorm:"column:room_id"`
+	// Disabled is set when the bot can't join or post to the room.
+	// Cleared when the wallet registers a room again.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the Matrix error that caused the provider to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+	// InvitedBy is the Matrix user whose invite the bot accepted, the consent to post to the room.
+	// Cleared when the wallet registers another room.
+	InvitedBy string `json:"invited_by" gorm:"column:invited_by"`
+	// JoinedAt is the unix timestamp the bot joined the room, 0 until it joins.
+	JoinedAt int64 `json:"joined_at" gorm:"column:joined_at;default:0"`
+}
+
+// TableName specifies the table name for GORM
+func (MatrixProvider) TableName() string {
+	return "matrix_providers"
+}
+
+type NtfyProvider struct {
+	// ID is the unique identifier for the ntfy provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id;uniqueIndex"`
+	// TopicURL is the ntfy topic notifications are published to (e.g. https://ntfy.sh/my-wallet).
+	TopicURL string `json:"topic_url" gorm:"column:topic_url"`
+	// Disabled is set when the server refuses to publish to the topic (reserved or access protected).
+	// Cleared when the wallet registers a topic again.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the ntfy error that caused the provider to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+}
+
+// TableName specifies the table name for GORM
+func (NtfyProvider) TableName() string {
+	return "ntfy_providers"
+}
+
+type PushoverProvider struct {
+	// ID is the unique identifier for the Pushover provider.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// NotificationProviderID is the foreign key to the NotificationProvider.
+	NotificationProviderID int64 `json:"notification_provider_id" gorm:"column:notification_provider_id;uniqueIndex"`
+	// UserKey is the Pushover user or group key notifications are sent to.
+	UserKey string `json:"user_key" gorm:"column:user_key"`
+	// Disabled is set when Pushover rejects the user key (invalid, deleted or disabled user).
+	// Cleared when the wallet registers a user key again.
+	Disabled bool `json:"disabled" gorm:"column:disabled;default:false"`
+	// DisabledReason is the Pushover error that caused the provider to be disabled.
+	DisabledReason string `json:"disabled_reason" gorm:"column:disabled_reason"`
+}
+
+// TableName specifies the table name for GORM
+func (PushoverProvider) TableName() string {
+	return "pushover_providers"
 }
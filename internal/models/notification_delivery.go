@@ -0,0 +1,38 @@
+package models
+
+// Notification delivery statuses
+const (
+	NotificationDeliverySent   = "sent"   // Accepted by the channel's provider
+	NotificationDeliveryQueued = "queued" // Failed, queued for a retry from the delivery queue
+	NotificationDeliveryFailed = "failed" // Failed without further retries, see the dead-letter queue
+)
+
+// NotificationDelivery records a notification sent to a wallet through one channel, for the wallet's
+// notification history
+type NotificationDelivery struct {
+	// ID is the auto-incremented identifier of the delivery.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// Wallet is the wallet the notification was sent for.
+	Wallet string `json:"wallet" gorm:"column:wallet;index:idx_notification_deliveries_wallet_created,priority:1"`
+	// NotificationID is the ID of the stored notification (empty for custom messages).
+	NotificationID string `json:"notification_id,omitempty" gorm:"column:notification_id;index"`
+	// TxHash is the hash of the notified transaction (empty for custom messages).
+	TxHash string `json:"tx_hash,omitempty" gorm:"column:tx_hash"`
+	// EventType is the kind of event notified, see EventTypes.
+	EventType string `json:"event_type" gorm:"column:event_type"`
+	// Reference is the short ID shown in every channel of the notification.
+	Reference string `json:"reference,omitempty" gorm:"column:reference"`
+	// Channel is the channel the notification was sent through (telegram, email, push, ...).
+	Channel string `json:"channel" gorm:"column:channel"`
+	// Status is sent, queued (failed, retried later) or failed.
+	Status string `json:"status" gorm:"column:status"`
+	// Error is the reason a delivery failed.
+	Error string `json:"error,omitempty" gorm:"column:error"`
+	// CreatedAt is the Unix timestamp when the notification was sent.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index:idx_notification_deliveries_wallet_created,priority:2"`
+}
+
+// TableName specifies the table name for GORM
+func (NotificationDelivery) TableName() string {
+	return "notification_deliveries"
+}
@@ -4,52 +4,368 @@ import (
 	"fmt"
 	"math/big"
 	"strings"
+	"time"
 )
 
 type NotificationService interface {
 	SendNotification(notification *Notification)
+	// SendOpsAlert sends an alert to the configured ops destinations, if any
+	SendOpsAlert(alert *OpsAlert)
+	// SendEmailVerification emails the double opt-in link of a wallet's email
+	SendEmailVerification(to, address, link string, expiresIn time.Duration)
+	// ReloadMessageTemplates loads the stored message template overrides
+	ReloadMessageTemplates() error
+	// ReloadLanguagePacks reloads the message templates of the language pack directory
+	ReloadLanguagePacks()
+	// ReplayWebhookEvent sends a stored webhook event to the URL once more
+	ReplayWebhookEvent(url, secret string, event *WebhookEvent) *WebhookReplay
+	// SendWebhookEvent sends a stored webhook event to the URL once, without retries
+	SendWebhookEvent(url, secret string, event *WebhookEvent) error
+	// RetryDelivery retries a queued delivery, removing it from the queue once delivered or out of attempts
+	RetryDelivery(job *DeliveryJob)
+	// ReplayDeadLetter sends a dead letter through its channel once more. Returns ErrChannelDisabled if the
+	// wallet no longer uses the channel.
+	ReplayDeadLetter(letter *DeadLetter) (*DeliveryReplay, error)
+	// ValidateMessageTemplate returns ErrInvalidMessageTemplate unless the override parses and renders
+	ValidateMessageTemplate(lang, name, body string) error
+	// RenderNotice renders a notice template (e.g. the app upgrade notice) in the wallet language, falling back to English
+	RenderNotice(lang, name string, data any) (string, error)
+	// FindDiscordCode reports whether a recent message of the Discord channel contains the code
+	FindDiscordCode(channelID, code string) (bool, error)
+}
+
+// Ops alert types
+const (
+	// OpsAlertReceivingBalance is raised when the receiving address balance exceeds the sweep threshold
+	OpsAlertReceivingBalance = "receiving_balance"
+	// OpsAlertDeliveryFailure is raised when the delivery failure rate of a channel exceeds the threshold
+	OpsAlertDeliveryFailure = "delivery_failure"
+	// OpsAlertBlockLag is raised when no new block was processed for longer than the threshold
+	OpsAlertBlockLag = "block_lag"
+	// OpsAlertLockContention is raised when a distributed lock can't be acquired or was held past its TTL
+	OpsAlertLockContention = "lock_contention"
+	// OpsAlertPanic is raised when a goroutine recovered from a panic with a new stack signature
+	OpsAlertPanic = "panic"
+	// OpsAlertPanicSummary periodically summarizes the panics repeating known stack signatures
+	OpsAlertPanicSummary = "panic_summary"
+)
+
+// Ops alert severities, as defined by PagerDuty
+const (
+	OpsSeverityCritical = "critical"
+	OpsSeverityError    = "error"
+	OpsSeverityWarning  = "warning"
+	OpsSeverityInfo     = "info"
+)
+
+// MaxOpsAlertStackLength limits the stack trace attached to panic alerts
+const MaxOpsAlertStackLength = 4096
+
+// OpsAlert is an operator alert, posted to the ops alert webhook as JSON
+type OpsAlert struct {
+	Type      string  `json:"type"`
+	Key       string  `json:"key,omitempty"` // What the alert is about within its type, e.g. the lock name
+	Severity  string  `json:"severity"`
+	Resolved  bool    `json:"resolved"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	Message   string  `json:"message"`
+	Details   string  `json:"details,omitempty"` // E.g. the stack trace of a panic
+}
+
+// DedupKey identifies the alert across its trigger and resolve messages
+func (a *OpsAlert) DedupKey() string {
+	if a.Key == "" {
+		return a.Type
+	}
+	return a.Type + ":" + a.Key
+}
+
+// NewPanicAlert returns the alert reporting a recovered panic in the named goroutine or job
+func NewPanicAlert(where string, recovered interface{}, stack string) *OpsAlert {
+	if len(stack) > MaxOpsAlertStackLength {
+		stack = stack[:MaxOpsAlertStackLength]
+	}
+	return &OpsAlert{
+		Type:     OpsAlertPanic,
+		Key:      where,
+		Severity: OpsSeverityError,
+		Message:  fmt.Sprintf("PANIC in %s: %v", where, recovered),
+		Details:  stack,
+	}
 }
 
 type Notification struct {
-	Wallet        string  `json:"wallet"` // Recipient address
-	From          string  `json:"from"`   // Sender address
-	Amount        float64 `json:"amount"`
-	Currency      string  `json:"currency"`       // Token symbol (e.g., CTN, USDT, XCB)
-	TokenAddress  string  `json:"token_address"`  // Contract address (empty for XCB)
-	TokenType     string  `json:"token_type"`     // CBC20, CBC721, or empty for native XCB
-	TokenID       string  `json:"token_id"`       // For NFT transfers (CBC721)
-	TxHash        string  `json:"tx_hash"`        // Transaction hash
-	NetworkID     int64   `json:"network_id"`     // Network ID (1 for mainnet, 3 for devnet)
-	CustomMessage string  `json:"custom_message"` // Custom message overrides default formatting
+	ID            string  `json:"id" gorm:"column:id;primaryKey;size:32"` // Random public ID used in detail page links
+	Wallet        string  `json:"wallet" gorm:"column:wallet;index"`      // Recipient address
+	From          string  `json:"from" gorm:"column:from"`                // Sender address
+	Amount        float64 `json:"amount" gorm:"column:amount"`
+	Currency      string  `json:"currency" gorm:"column:currency"`             // Token symbol (e.g., CTN, USDT, XCB)
+	TokenAddress  string  `json:"token_address" gorm:"column:token_address"`   // Contract address (empty for XCB)
+	TokenType     string  `json:"token_type" gorm:"column:token_type"`         // CBC20, CBC721, or empty for native XCB
+	TokenID       string  `json:"token_id" gorm:"column:token_id"`             // For NFT transfers (CBC721)
+	TxHash        string  `json:"tx_hash" gorm:"column:tx_hash;index"`         // Transaction hash
+	NetworkID     int64   `json:"network_id" gorm:"column:network_id"`         // Network ID (1 for mainnet, 3 for devnet)
+	CustomMessage string  `json:"custom_message" gorm:"column:custom_message"` // Custom message overrides default formatting
+	Internal      bool    `json:"internal" gorm:"column:internal"`             // Transfer between addresses of the same user
+	CreatedAt     int64   `json:"created_at" gorm:"column:created_at;index"`   // Unix timestamp when the notification was stored
+	ReadAt        int64   `json:"read_at" gorm:"column:read_at;default:0"`     // Unix timestamp when the user read it in the app (0 = unread)
+	Channels      string  `json:"channels" gorm:"column:channels"`             // Comma-separated channels it was sent to (telegram, email)
+	Reference     string  `json:"reference" gorm:"column:reference;index"`     // Short ID shown in every channel to correlate the deliveries
+	EventType     string  `json:"event_type" gorm:"column:event_type;index"`   // Kind of event (incoming_xcb, nft_received, ...), see EventTypes
+	// Confirmed is set on the notification of a mined transfer that was notified as pending before
+	Confirmed bool `json:"confirmed" gorm:"column:confirmed"`
+	// VerifiedSender is the name of the trusted sender the transfer came from, empty if the sender isn't trusted
+	VerifiedSender string `json:"verified_sender" gorm:"column:verified_sender"`
+	// LookalikeToken is set when the token imitates the symbol of a verified token without being it
+	LookalikeToken bool `json:"lookalike_token" gorm:"column:lookalike_token"`
+	// RiskScore is the compliance screening score of the sender, nil if the sender wasn't screened
+	RiskScore *float64 `json:"risk_score,omitempty" gorm:"column:risk_score"`
+	// HighRisk is set when the risk score reaches SCREENING_HIGH_RISK_SCORE
+	HighRisk bool `json:"high_risk,omitempty" gorm:"column:high_risk"`
+
+	// Transfers lists all transfers to the wallet when the transaction contained several of them.
+	// Amount is then their total, From and TokenID are only set when all transfers share them, and the
+	// other fields above describe the first one.
+	Transfers []NotificationTransfer `json:"transfers,omitempty" gorm:"column:transfers;serializer:json"`
+	// MixedTransfers is set when the transfers are of different tokens. Amount is 0 then, there is no total.
+	MixedTransfers bool `json:"mixed_transfers,omitempty" gorm:"column:mixed_transfers"`
+}
+
+// NotificationTransfer is a single transfer of a notification combining several transfers of one transaction
+type NotificationTransfer struct {
+	From         string  `json:"from"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	TokenAddress string  `json:"token_address"`
+	TokenType    string  `json:"token_type"`
+	TokenID      string  `json:"token_id"`
+	Internal     bool    `json:"internal"`
+
+	VerifiedSender string   `json:"verified_sender"`
+	LookalikeToken bool     `json:"lookalike_token"`
+	RiskScore      *float64 `json:"risk_score,omitempty"`
+	HighRisk       bool     `json:"high_risk,omitempty"`
+}
+
+// FormattedAmount returns the amount without scientific notation and trailing zeros
+func (t NotificationTransfer) FormattedAmount() string {
+	return (&Notification{Amount: t.Amount}).FormattedAmount()
+}
+
+// DisplayTokenID returns the NFT token ID in decimal for better readability
+func (t NotificationTransfer) DisplayTokenID() string {
+	return (&Notification{TokenID: t.TokenID}).DisplayTokenID()
+}
+
+// TableName specifies the table name for GORM
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// Pending reports whether the notification is about a transfer that is not mined yet
+func (n *Notification) Pending() bool {
+	return n.EventType == EventIncomingPending
+}
+
+// ReferenceTag returns the reference as a Telegram hashtag
+func (n *Notification) ReferenceTag() string {
+	if n.Reference == "" {
+		return ""
+	}
+	return "#" + n.Reference
+}
+
+// ExplorerURL returns the block explorer base URL for the notification's network
+func (n *Notification) ExplorerURL() string {
+	if n.NetworkID == 3 {
+		return "https://devin.blockindex.net"
+	}
+	// Default to mainnet (network ID 1)
+	return "https://blockindex.net"
+}
+
+// TxLink returns the block explorer link to the notification's transaction
+func (n *Notification) TxLink() string {
+	return n.ExplorerURL() + "/tx/" + n.TxHash
+}
+
+// AddressLink returns the block explorer link to the given address
+func (n *Notification) AddressLink(address string) string {
+	return n.ExplorerURL() + "/address/" + address
+}
+
+// FormattedAmount returns the amount without scientific notation and trailing zeros
+func (n *Notification) FormattedAmount() string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.18f", n.Amount), "0"), ".")
+}
+
+// DisplayTokenID returns the NFT token ID in decimal for better readability
+func (n *Notification) DisplayTokenID() string {
+	tokenIDStr := strings.TrimPrefix(n.TokenID, "0x")
+	if tokenIDBig, ok := new(big.Int).SetString(tokenIDStr, 16); ok {
+		return tokenIDBig.String() // Decimal representation
+	}
+	return n.TokenID
+}
+
+// GroupNotifications combines notifications to the same wallet into one notification per wallet,
+// keeping the order of first appearance. Used for the transfers of a single transaction.
+// The top-level fields of combined notifications summarize their transfers, see Notification.Transfers.
+func GroupNotifications(notifications []*Notification) []*Notification {
+	grouped := make([]*Notification, 0, len(notifications))
+	byWallet := make(map[string]*Notification, len(notifications))
+	for _, notification := range notifications {
+		first, ok := byWallet[notification.Wallet]
+		if !ok {
+			byWallet[notification.Wallet] = notification
+			grouped = append(grouped, notification)
+			continue
+		}
+		if len(first.Transfers) == 0 {
+			first.Transfers = []NotificationTransfer{first.transfer()}
+		}
+		first.Transfers = append(first.Transfers, notification.transfer())
+		// The combined notification has the highest risk score of its senders
+		if notification.RiskScore != nil && (first.RiskScore == nil || *notification.RiskScore > *first.RiskScore) {
+			first.RiskScore = notification.RiskScore
+		}
+		first.HighRisk = first.HighRisk || notification.HighRisk
+	}
+	for _, notification := range grouped {
+		if len(notification.Transfers) > 0 {
+			notification.summarizeTransfers()
+		}
+	}
+	return grouped
+}
+
+// summarizeTransfers sets the amount of a combined notification to the total of its transfers, or marks it as
+// mixed when they are of different tokens, and clears the sender and token ID unless all transfers share them
+func (n *Notification) summarizeTransfers() {
+	n.Amount = 0
+	for _, transfer := range n.Transfers {
+		if transfer.Currency != n.Currency || transfer.TokenAddress != n.TokenAddress {
+			n.MixedTransfers = true
+		}
+		if transfer.From != n.From {
+			n.From, n.VerifiedSender = "", ""
+		}
+		if transfer.TokenID != n.TokenID {
+			n.TokenID = ""
+		}
+		n.Amount += transfer.Amount
+	}
+	if n.MixedTransfers {
+		n.Amount = 0
+	}
+}
+
+// transfer returns the transfer described by the notification's top-level fields
+func (n *Notification) transfer() NotificationTransfer {
+	return NotificationTransfer{
+		From:         n.From,
+		Amount:       n.Amount,
+		Currency:     n.Currency,
+		TokenAddress: n.TokenAddress,
+		TokenType:    n.TokenType,
+		TokenID:      n.TokenID,
+		Internal:     n.Internal,
+
+		VerifiedSender: n.VerifiedSender,
+		LookalikeToken: n.LookalikeToken,
+		RiskScore:      n.RiskScore,
+		HighRisk:       n.HighRisk,
+	}
 }
 
 func (n *Notification) String() string {
+	return n.Text(n.TxLink())
+}
+
+// Text formats the notification message using the given transaction link
+// (e.g. a short link for compact channels)
+func (n *Notification) Text(txLink string) string {
 	// If custom message is set, use it instead of default formatting
 	if n.CustomMessage != "" {
 		return n.CustomMessage
 	}
 
-	// Determine explorer base URL based on network ID
-	var explorerURL string
-	if n.NetworkID == 3 {
-		explorerURL = "https://devin.blockindex.net/tx/"
-	} else {
-		// Default to mainnet (network ID 1)
-		explorerURL = "https://blockindex.net/tx/"
+	status := ""
+	switch {
+	case n.Pending():
+		status = PendingTransferNotice + "\n"
+	case n.Confirmed:
+		status = ConfirmedTransferNotice + "\n"
 	}
 
-	txLink := explorerURL + n.TxHash
-
-	if n.TokenType == "CBC721" {
-		// Convert hex token ID to decimal for better readability
-		tokenID := n.TokenID
-		tokenIDStr := strings.TrimPrefix(tokenID, "0x")
-		if tokenIDBig, ok := new(big.Int).SetString(tokenIDStr, 16); ok {
-			tokenID = tokenIDBig.String() // Decimal representation
+	if len(n.Transfers) > 1 {
+		lines := make([]string, 0, len(n.Transfers)+2)
+		lines = append(lines, status+fmt.Sprintf("Received %d transfers in one transaction to address %v:", len(n.Transfers), n.Wallet))
+		for _, transfer := range n.Transfers {
+			lines = append(lines, "- "+n.transferLine(transfer))
 		}
-		return fmt.Sprintf("Received NFT %v (ID: %v) from %v to address %v\nTransaction: %v", n.Currency, tokenID, n.From, n.Wallet, txLink)
+		lines = append(lines, fmt.Sprintf("Transaction: %v", txLink))
+		return strings.Join(lines, "\n")
+	}
+
+	from := senderText(n.From, n.VerifiedSender)
+
+	var text string
+	switch {
+	case n.Internal && n.TokenType == "CBC721":
+		text = fmt.Sprintf("Internal transfer of NFT %v (ID: %v) from your address %v to your address %v", n.Currency, n.DisplayTokenID(), from, n.Wallet)
+	case n.Internal:
+		text = fmt.Sprintf("Internal transfer of %v %v from your address %v to your address %v", n.FormattedAmount(), n.Currency, from, n.Wallet)
+	case n.TokenType == "CBC721":
+		text = fmt.Sprintf("Received NFT %v (ID: %v) from %v to address %v", n.Currency, n.DisplayTokenID(), from, n.Wallet)
+	default:
+		text = fmt.Sprintf("Received %v %v from %v to address %v", n.FormattedAmount(), n.Currency, from, n.Wallet)
+	}
+	if n.LookalikeToken {
+		text += "\n" + fmt.Sprintf(LookalikeTokenCaution, n.Currency)
+	}
+	return fmt.Sprintf("%v%v\nTransaction: %v", status, text, txLink)
+}
+
+// Status lines of transfers notified before they are mined
+const (
+	PendingTransferNotice   = "⏳ Incoming payment pending, not confirmed yet. It may still be dropped or replaced."
+	ConfirmedTransferNotice = "✅ Confirmed: the pending payment was mined."
+)
+
+// LookalikeTokenCaution is the notice added to notifications of tokens imitating a verified token's symbol
+const LookalikeTokenCaution = "⚠️ Caution: this %v is not the verified token with this symbol. Check the token contract before trusting it."
+
+// senderText returns how the sender is shown: the address, preceded by the name and a verified marker
+// for trusted senders. The sender may be unknown if it couldn't be recovered from the transaction signature.
+func senderText(from, verifiedSender string) string {
+	switch {
+	case from == "":
+		return "unknown sender"
+	case verifiedSender != "":
+		return fmt.Sprintf("%v ✓ (%v)", verifiedSender, from)
+	}
+	return from
+}
+
+// transferLine formats one transfer of a grouped notification
+func (n *Notification) transferLine(transfer NotificationTransfer) string {
+	from := senderText(transfer.From, transfer.VerifiedSender)
+
+	var line string
+	switch {
+	case transfer.Internal && transfer.TokenType == "CBC721":
+		line = fmt.Sprintf("Internal transfer of NFT %v (ID: %v) from your address %v", transfer.Currency, transfer.DisplayTokenID(), from)
+	case transfer.Internal:
+		line = fmt.Sprintf("Internal transfer of %v %v from your address %v", transfer.FormattedAmount(), transfer.Currency, from)
+	case transfer.TokenType == "CBC721":
+		line = fmt.Sprintf("NFT %v (ID: %v) from %v", transfer.Currency, transfer.DisplayTokenID(), from)
+	default:
+		line = fmt.Sprintf("%v %v from %v", transfer.FormattedAmount(), transfer.Currency, from)
+	}
+	if transfer.LookalikeToken {
+		line += " ⚠️ not the verified " + transfer.Currency
 	}
-	// Format amount to avoid scientific notation and strip trailing zeros
-	amountStr := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.18f", n.Amount), "0"), ".")
-	return fmt.Sprintf("Received %v %v from %v to address %v\nTransaction: %v", amountStr, n.Currency, n.From, n.Wallet, txLink)
+	return line
 }
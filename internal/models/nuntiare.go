@@ -1,5 +1,7 @@
 package models
 
+import tgModels "github.com/go-telegram/bot/models"
+
 type NuntiareI interface {
 	// Start starts the application
 	Start()
@@ -7,16 +9,152 @@ type NuntiareI interface {
 	// Stop gracefully stops the application and waits for goroutines to finish
 	Stop()
 
-	// RegisterNewWallet adds a new wallet to the repository
-	RegisterNewWallet(*Wallet) error
+	// RegisterNewWallet adds a new wallet to the repository and returns it with true. Registering an address
+	// again returns the stored wallet with false, or ErrOriginMismatch unless the OriginID may manage it.
+	// Joining the subscription address of another user's wallets requires a proof of owning the address.
+	RegisterNewWallet(wallet *Wallet, subscriberProof *OwnershipProof) (*Wallet, bool, error)
 	// GetWallet returns a wallet from the repository
 	GetWallet(address string) (*Wallet, error)
+	// GetWallets returns the registered wallets among the given addresses
+	GetWallets(addresses []string) ([]*Wallet, error)
+	// GetNotificationProvider returns the notification providers of a wallet
+	GetNotificationProvider(address string) (*NotificationProvider, error)
 	// UpdateNotificationProvider updates notification providers for an existing wallet
-	UpdateNotificationProvider(address, telegram, email string) error
+	UpdateNotificationProvider(address, telegram, email, fcmToken string) error
 	// UpdateNotificationProviderAndReactivate updates notification providers and sets Active=true
-	UpdateNotificationProviderAndReactivate(address, telegram, email string) error
+	UpdateNotificationProviderAndReactivate(address, telegram, email, fcmToken string) error
+	// SetMutedEventTypes sets the event types the wallet is not notified about
+	SetMutedEventTypes(address string, eventTypes []string) error
+	// SetMinAmounts sets the minimum amount per currency of transfers the wallet is notified about (empty removes all)
+	SetMinAmounts(address string, minAmounts map[string]float64) error
+	// ValidateMinAmounts returns ErrInvalidMinAmount unless every currency has a positive minimum amount
+	ValidateMinAmounts(minAmounts map[string]float64) error
+	// SetWebhook sets the webhook URL of a wallet and returns the secret payloads are signed with
+	SetWebhook(address, webhookURL string) (string, error)
+	// ValidateWebhookURL returns ErrInvalidWebhookURL unless the URL is an absolute HTTPS URL
+	ValidateWebhookURL(webhookURL string) error
+	// SetDiscord sets the Discord webhook URL or bot channel of a wallet and returns the channel's verification code
+	SetDiscord(address, webhookURL, channelID string) (string, error)
+	// VerifyDiscord checks the verification code was posted to the wallet's Discord channel
+	VerifyDiscord(address string) error
+	// ValidateDiscord returns ErrInvalidDiscord unless the webhook URL or channel ID can be used
+	ValidateDiscord(webhookURL, channelID string) error
+	// SetPhone sets the phone number SMS notifications of a wallet are sent to
+	SetPhone(address, phone string) error
+	// ValidatePhone returns ErrInvalidPhone unless the phone number is in E.164 format
+	ValidatePhone(phone string) error
+	// SetMatrix sets the Matrix room notifications of a wallet are posted to
+	SetMatrix(address, roomID string) error
+	// ValidateMatrixRoom returns ErrInvalidMatrixRoom unless the room ID is a Matrix room ID
+	ValidateMatrixRoom(roomID string) error
+	// SetNtfy sets the ntfy topic notifications of a wallet are published to
+	SetNtfy(address, topicURL string) error
+	// ValidateNtfyTopic returns ErrInvalidNtfyTopic unless the URL is an ntfy topic URL
+	ValidateNtfyTopic(topicURL string) error
+	// SetPushover sets the Pushover user key notifications of a wallet are sent to
+	SetPushover(address, userKey string) error
+	// ValidatePushoverUserKey returns ErrInvalidPushoverUserKey unless the key is a Pushover user or group key
+	ValidatePushoverUserKey(userKey string) error
+	// UpdateWalletMetadata updates the OS, language and app version of a wallet (empty values are kept)
+	UpdateWalletMetadata(address, os, lang, appVersion string) error
+	// CheckAppVersion returns ErrUpgradeRequired if the app version is below the minimum for the OS
+	CheckAppVersion(os, appVersion string) error
 	// CancelWallet deactivates notifications while keeping subscription active
 	CancelWallet(address string) error
+	// TransferSubscription moves the remaining subscription time of the wallet to another wallet of the same user
+	TransferSubscription(from *Wallet, toAddress, clientIP string) (*SubscriptionTransfer, error)
+	// GetSubscriptionTransfers returns the subscription transfers from or to the wallet
+	GetSubscriptionTransfers(address string) ([]*SubscriptionTransfer, error)
+	// GetWalletEvents returns the state transitions of the wallet up to the timestamp, oldest first
+	GetWalletEvents(address string, until int64) ([]*WalletEvent, error)
+	// GetWalletState derives the state of the wallet at the timestamp from its events
+	GetWalletState(address string, at int64) (*WalletState, error)
+	// ProveWalletOwnership checks the ownership challenge message was signed with the key of the wallet and flags it as proven
+	ProveWalletOwnership(wallet *Wallet, message, signature string) (int64, error)
+	// ExportWalletData returns the data stored about the wallet, requires its ownership to be proven
+	ExportWalletData(wallet *Wallet) (*WalletExport, error)
+
+	// VerifyEmail confirms the wallet email of an email verification token
+	VerifyEmail(token string) (*EmailVerification, error)
+
+	// IsWalletOrigin reports whether the OriginID is the wallet's own or the one of a linked app
+	IsWalletOrigin(wallet *Wallet, originID string) bool
+	// LinkWalletOrigin links another wallet app to the wallet
+	LinkWalletOrigin(wallet *Wallet, origin, originID string) (*WalletOrigin, error)
+	// GetWalletOrigins returns the apps linked to the wallet
+	GetWalletOrigins(address string) ([]*WalletOrigin, error)
+	// GetWalletOriginByID returns the linked app of the wallet with the OriginID, or nil if it isn't linked
+	GetWalletOriginByID(address, originID string) (*WalletOrigin, error)
+	// UnlinkWalletOrigin removes the link of an app from the wallet. Returns false if the app isn't linked.
+	UnlinkWalletOrigin(address, origin string) (bool, error)
+	// JoinUser adds the wallet to the user of the primary wallet, so it is notified through the primary wallet's channels
+	JoinUser(primary, wallet *Wallet) (*User, error)
+	// LeaveUser removes the wallet from its user. Returns false if the wallet has no user.
+	LeaveUser(wallet *Wallet) (bool, error)
+	// GetUserWallets returns the user of the wallet and all of its wallets, or nil if the wallet has no user
+	GetUserWallets(wallet *Wallet) (*User, []*Wallet, error)
+	// SetTokenPreference opts the wallet in or out of notifications about a token ("xcb" or a contract address)
+	SetTokenPreference(address, token string, notify bool) (*WalletTokenPreference, error)
+	// GetTokenPreferences returns the token preferences of the wallet, oldest first
+	GetTokenPreferences(address string) ([]*WalletTokenPreference, error)
+	// RemoveTokenPreference removes the preference of the wallet for a token. Returns false if it has none.
+	RemoveTokenPreference(address, token string) (bool, error)
+	// GetAutomationItems returns the latest notifications of the wallet flattened for automation polling triggers
+	GetAutomationItems(address, eventType string, limit int) ([]*AutomationItem, error)
+	// AddAutomationHook subscribes an automation platform's REST hook to the notifications of the wallet
+	AddAutomationHook(address, targetURL, eventType string) (*AutomationHook, error)
+	// GetAutomationHooks returns the automation hooks of the wallet, oldest first
+	GetAutomationHooks(address string) ([]*AutomationHook, error)
+	// RemoveAutomationHook unsubscribes an automation hook of the wallet. Returns false if it doesn't exist.
+	RemoveAutomationHook(address, id string) (bool, error)
+	// SetWidgetFeed enables the public widget feed of the wallet or changes its fields, rotate revokes the URLs handed out before
+	SetWidgetFeed(address string, fields []string, rotate bool) (*WidgetFeed, error)
+	// GetWidgetFeed returns the widget feed of the wallet
+	GetWidgetFeed(address string) (*WidgetFeed, error)
+	// RemoveWidgetFeed disables the widget feed of the wallet. Returns false if it wasn't enabled.
+	RemoveWidgetFeed(address string) (bool, error)
+	// WidgetFeedURL returns the signed URL of the widget feed
+	WidgetFeedURL(feed *WidgetFeed) string
+	// GetWidgetItems returns the latest transfers of a wallet's widget feed, redacted to the published fields
+	GetWidgetItems(address, signature string, limit int) ([]*WidgetItem, error)
+	// CreatePaymentRequest creates a shareable request to pay the wallet amount (0 for any amount) of a token
+	CreatePaymentRequest(address, token string, amount float64, memo string, expiresIn int64) (*PaymentRequest, error)
+	// GetPaymentRequest returns a payment request by ID
+	GetPaymentRequest(id string) (*PaymentRequest, error)
+	// GetPaymentRequests returns the latest payment requests of the wallet, newest first
+	GetPaymentRequests(address string) ([]*PaymentRequest, error)
+	// CancelPaymentRequest cancels an open payment request of the wallet. Returns false if it doesn't exist or isn't open.
+	CancelPaymentRequest(address, id string) (bool, error)
+	// PaymentRequestURL returns the link of the payment request page
+	PaymentRequestURL(request *PaymentRequest) string
+	// GetWebhookEvent returns a webhook event of the wallet, or nil if the wallet has no event with the ID
+	GetWebhookEvent(address, id string) (*WebhookEvent, error)
+	// ReplayWebhookEvent sends a webhook event of the wallet to its webhook, or the automation hook it was sent to, once more. Returns nil if the wallet has no event with the ID.
+	ReplayWebhookEvent(address, id string) (*WebhookReplay, error)
+	// AddWalletTag attaches a segment tag (e.g. vip, beta) to a registered wallet
+	AddWalletTag(address, tag string) (*WalletTag, error)
+	// GetWalletTags returns the tags of the wallet in alphabetical order
+	GetWalletTags(address string) ([]*WalletTag, error)
+	// RemoveWalletTag detaches a tag from the wallet. Returns false if the wallet doesn't have it.
+	RemoveWalletTag(address, tag string) (bool, error)
+	// GetWalletTagCounts returns the number of wallets per tag
+	GetWalletTagCounts() ([]*WalletTagCount, error)
+
+	// RegisterDevice registers a device of a wallet or refreshes its details
+	RegisterDevice(device *Device) error
+	// GetDevices returns the devices registered for a wallet
+	GetDevices(address string) ([]*Device, error)
+	// RemoveDevice unregisters a device of a wallet. Returns false if the device doesn't exist.
+	RemoveDevice(address, deviceID string) (bool, error)
+
+	// WebPushPublicKey returns the VAPID public key browsers subscribe with (empty when Web Push is disabled)
+	WebPushPublicKey() string
+	// AddWebPushSubscription stores a browser push subscription of a wallet or refreshes its keys
+	AddWebPushSubscription(subscription *WebPushSubscription) error
+	// GetWebPushSubscriptions returns the browser push subscriptions of a wallet
+	GetWebPushSubscriptions(address string) ([]*WebPushSubscription, error)
+	// RemoveWebPushSubscription deletes a browser push subscription. Returns false if it doesn't exist.
+	RemoveWebPushSubscription(address, endpoint string) (bool, error)
 
 	// NewHeaderSubscription creates a new header subscription
 	WatchTransfers()
@@ -28,7 +166,124 @@ type NuntiareI interface {
 	// CheckWalletSubscription checks if the wallet is subscribed.
 	// Data is taken from the repository.
 	CheckWalletSubscription(wallet *Wallet) (bool, error)
+	// SubscriptionActive reports whether the wallet's subscription is active now, without updating its paid status
+	SubscriptionActive(wallet *Wallet) bool
+
+	// GetNotification returns a stored notification by its public ID
+	GetNotification(id string) (*Notification, error)
+
+	// ListWallets returns a page of registered wallets
+	ListWallets(opts ListOptions) (*Page[Wallet], error)
+	// ImportWallets validates and registers wallets from a partner's user list, reporting each row
+	ImportWallets(rows []*WalletImportRow, dryRun bool) *WalletImportReport
+	// SearchWallets finds wallets by partial address, originator, email or Telegram username
+	SearchWallets(query string, limit int) ([]*Wallet, error)
+	// ListNotifications returns a page of stored notifications
+	ListNotifications(opts ListOptions) (*Page[Notification], error)
+	// MarkNotificationRead marks a notification of the wallet as read. Returns false if it doesn't exist.
+	MarkNotificationRead(address, id string) (bool, error)
+	// CountUnreadNotifications returns the number of notifications of the wallet not read in the app yet
+	CountUnreadNotifications(address string) (int64, error)
+	// ListNotificationHistory returns a page of the notifications sent to the wallet, one entry per channel
+	ListNotificationHistory(address string, opts ListOptions) (*Page[NotificationDelivery], error)
+	// CompareShadowNotifications compares the notifications recorded by shadow instances with the ones production sent in [from, to)
+	CompareShadowNotifications(from, to int64) (*ShadowReport, error)
+	// GetPanicReports returns the panics recovered by this instance by stack signature, most frequent first
+	GetPanicReports() []*PanicReport
+	// PipelineStats returns the counters of the block pipeline stages of this instance since it started
+	PipelineStats() []*PipelineStageStats
+	// GetPaymentInflow returns the subscription payment totals of a period for payments in [from, to)
+	GetPaymentInflow(period string, from, to int64) ([]*PaymentInflow, error)
+	// GetReceivingBalance returns the current CTN balance of the receiving address
+	GetReceivingBalance() (float64, error)
+	// GetNotificationRollups returns the notification counts of a period and dimension for buckets in [from, to)
+	GetNotificationRollups(period, dimension string, from, to int64) ([]*NotificationRollup, error)
+	// GetOriginMetrics returns the delivery metrics of the originator's wallets for [from, to)
+	GetOriginMetrics(origin string, from, to int64) (*OriginMetrics, error)
+	// GetOriginQuota returns the consumption of the originator's monthly quota, nil if it has none
+	GetOriginQuota(origin string) (*OriginQuota, error)
+	// PartnerOrigin returns the originator the partner API key belongs to, false for unknown keys
+	PartnerOrigin(key string) (string, bool)
+	// ListSubscriptionPayments returns a page of subscription payments
+	ListSubscriptionPayments(opts ListOptions) (*Page[SubscriptionPayment], error)
+
+	// GetNFTImageURL returns the resolved image URL of a CBC721 token, empty until it was resolved
+	GetNFTImageURL(tokenAddress, tokenID string) (string, error)
+
+	// ResolveShortLink returns the target URL of a short link and counts the click
+	ResolveShortLink(code string) (string, error)
+
+	// GetMessageTemplates returns the stored message template overrides
+	GetMessageTemplates() ([]*MessageTemplate, error)
+	// SetMessageTemplate validates and stores a message template override of a language
+	SetMessageTemplate(lang, name, body string) (*MessageTemplate, error)
+	// DeleteMessageTemplate removes a message template override, returns false if it doesn't exist
+	DeleteMessageTemplate(lang, name string) (bool, error)
+	// GetTrustedSenders returns the verified sender addresses
+	GetTrustedSenders() ([]*TrustedSender, error)
+	// SetTrustedSender stores a verified sender address or updates its name and category
+	SetTrustedSender(address, name, category string) (*TrustedSender, error)
+	// DeleteTrustedSender removes a verified sender address, returns false if it isn't trusted
+	DeleteTrustedSender(address string) (bool, error)
+	// IsReadOnly reports whether the API is in read-only maintenance mode
+	IsReadOnly() bool
+	// SetReadOnly switches read-only maintenance mode of all instances
+	SetReadOnly(readOnly bool) error
+	// PreviewTemplate renders a message template with sample data for each channel and lints it
+	PreviewTemplate(text string, channels []string) *TemplatePreview
+
+	// ProcessEmailWebhook ingests delivery/bounce events from an email provider webhook
+	ProcessEmailWebhook(provider, token string, body []byte) error
+
+	// ReprocessBlocks schedules a background re-scan of a block range with notification dedup
+	ReprocessBlocks(from, to uint64, dryRun bool) (*ReprocessJob, error)
+	// GetReprocessJob returns a reprocess job by its ID
+	GetReprocessJob(id string) (*ReprocessJob, error)
+
+	// ScheduleNotification schedules a message for delivery at sendAt (empty wallet = all active wallets, or
+	// the active wallets with the tag)
+	ScheduleNotification(wallet, tag, message string, sendAt int64, source string) (*ScheduledNotification, error)
+	// GetScheduledNotification returns a scheduled notification by its ID
+	GetScheduledNotification(id string) (*ScheduledNotification, error)
+	// CancelScheduledNotification cancels a pending scheduled notification. Returns false if it isn't pending.
+	CancelScheduledNotification(id string) (bool, error)
+
+	// ListDeliveryJobs returns a page of the failed deliveries waiting for a retry
+	ListDeliveryJobs(opts ListOptions) (*Page[DeliveryJob], error)
+	// ListDeadLetters returns a page of the deliveries that ran out of attempts
+	ListDeadLetters(opts ListOptions) (*Page[DeadLetter], error)
+	// GetDeadLetter returns a dead letter by its ID, or nil if it doesn't exist
+	GetDeadLetter(id string) (*DeadLetter, error)
+	// ReplayDeadLetter sends a dead letter through its channel once more. Returns nil if it doesn't exist.
+	ReplayDeadLetter(id string) (*DeliveryReplay, error)
+	// RemoveDeadLetter discards a dead letter. Returns false if it doesn't exist.
+	RemoveDeadLetter(id string) (bool, error)
+
+	// AddExchange adds an exchange in deposit-confirmation mode and returns its API key and webhook secret
+	AddExchange(name, webhookURL string, confirmations int) (*ExchangeCredentials, error)
+	// ListExchanges returns the exchanges in deposit-confirmation mode
+	ListExchanges() ([]*Exchange, error)
+	// RemoveExchange removes an exchange and its deposit addresses. Returns false if it doesn't exist.
+	RemoveExchange(id string) (bool, error)
+	// ExchangeByAPIKey returns the exchange authenticating with the API key, or nil if there is none
+	ExchangeByAPIKey(key string) (*Exchange, error)
+	// RegisterExchangeAddresses registers deposit addresses of the exchange in bulk
+	RegisterExchangeAddresses(exchange *Exchange, rows []ExchangeAddressRow) (*ExchangeAddressReport, error)
+	// RemoveExchangeAddress removes a deposit address of the exchange. Returns false if it didn't register it.
+	RemoveExchangeAddress(exchange *Exchange, address string) (bool, error)
+	// ListExchangeDeposits returns a page of the deposits of the exchange
+	ListExchangeDeposits(exchange *Exchange, opts ListOptions) (*Page[ExchangeDeposit], error)
+	// CreditExchangeDeposit records that the exchange credited a confirmed deposit. Returns nil if it doesn't exist.
+	CreditExchangeDeposit(exchange *Exchange, id int64, amount float64) (*ExchangeDeposit, error)
+	// GetExchangeReconciliation compares the deposits of the exchange detected in from..to with the ones it credited
+	GetExchangeReconciliation(exchange *Exchange, from, to int64) (*ExchangeReconciliation, error)
+
+	// Status returns the coarse health of the service for client apps
+	Status() *ServiceStatus
+	// GetSubscriptionPricing returns the current subscription price of the network for client apps
+	GetSubscriptionPricing(network string) *SubscriptionPricing
 
-	// ProcessTelegramWebhook processes a Telegram webhook update
-	ProcessTelegramWebhook(update interface{}) error
+	// ProcessTelegramWebhook processes a Telegram webhook update.
+	// token is the X-Telegram-Bot-Api-Secret-Token header value.
+	ProcessTelegramWebhook(token string, update *tgModels.Update) error
 }
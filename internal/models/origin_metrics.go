@@ -0,0 +1,37 @@
+package models
+
+// OriginMetrics are the delivery metrics of the wallets registered by an originator (wallet app) in a time range
+type OriginMetrics struct {
+	// Wallets is the number of wallets the originator registered.
+	Wallets int64 `json:"wallets"`
+	// PaidWallets is the number of those wallets with an active subscription.
+	PaidWallets int64 `json:"paid_wallets"`
+	// Notifications is the number of notifications for the wallets in the range.
+	Notifications int64 `json:"notifications"`
+	// Undelivered is the number of those notifications that weren't sent to any channel.
+	Undelivered int64 `json:"undelivered"`
+	// Channels is the number of notifications sent per channel (telegram, email, push, ...).
+	Channels map[string]int64 `json:"channels"`
+	// EmailEvents is the number of email provider events per type (delivered, bounced, ...) in the range.
+	EmailEvents map[string]int64 `json:"email_events"`
+	// WebhookDeliveries is the number of webhook delivery attempts in the range.
+	WebhookDeliveries int64 `json:"webhook_deliveries"`
+	// WebhookFailures is the number of those attempts that didn't get a 2xx response.
+	WebhookFailures int64 `json:"webhook_failures"`
+	// DisabledChannels is the number of wallets per channel whose channel is currently disabled
+	// (bot blocked, token unregistered, email bounced, ...).
+	DisabledChannels map[string]int64 `json:"disabled_channels"`
+}
+
+// OriginQuota is the consumption of the notifications per calendar month agreed with an originator
+type OriginQuota struct {
+	// MonthlyLimit is the number of notifications per calendar month (UTC).
+	MonthlyLimit int64 `json:"monthly_limit"`
+	// Used is the number of notifications in the current month.
+	Used int64 `json:"used"`
+	// Remaining is the number of notifications left this month (0 when exceeded).
+	Remaining int64 `json:"remaining"`
+	// PeriodStart and PeriodEnd are the Unix timestamps of the current month's bounds.
+	PeriodStart int64 `json:"period_start"`
+	PeriodEnd   int64 `json:"period_end"`
+}
@@ -0,0 +1,19 @@
+package models
+
+// PanicReport aggregates the recovered panics of one instance with the same stack signature
+type PanicReport struct {
+	// Signature identifies where the panic happened: a hash of the goroutine description and the
+	// functions on the stack, independent of argument values and goroutine IDs.
+	Signature string `json:"signature"`
+	// Where is the goroutine or job that panicked (e.g. processTokenTransfers).
+	Where string `json:"where"`
+	// Panic is the value of the latest panic.
+	Panic string `json:"panic"`
+	// Stack is the stack trace of the first panic.
+	Stack string `json:"stack"`
+	// Count is the number of panics since the instance started.
+	Count int64 `json:"count"`
+	// FirstAt and LastAt are the Unix timestamps of the first and latest panic.
+	FirstAt int64 `json:"first_at"`
+	LastAt  int64 `json:"last_at"`
+}
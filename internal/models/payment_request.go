@@ -0,0 +1,70 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// Payment request statuses
+const (
+	PaymentRequestOpen      = "open"      // Waiting for the matching transfer
+	PaymentRequestFulfilled = "fulfilled" // Paid by a transfer detected on-chain
+	PaymentRequestCancelled = "cancelled" // Cancelled by the payee
+)
+
+// PaymentRequest is a shareable request to pay a wallet, e.g. a donation or invoice link. It is fulfilled
+// by the first transfer of the token to the wallet that matches the amount.
+type PaymentRequest struct {
+	// ID is the random public identifier used in the request link.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// WalletAddress is the payee, the registered wallet the request belongs to.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;index"`
+	// Token is NativeTokenPreference for XCB or the normalized contract address of the CBC20 token.
+	Token string `json:"token" gorm:"column:token;not null"`
+	// Currency is the symbol of the token (e.g. XCB, CTN).
+	Currency string `json:"currency" gorm:"column:currency"`
+	// Amount is the requested amount, 0 accepts any amount (e.g. donations).
+	Amount float64 `json:"amount" gorm:"column:amount"`
+	// Memo describes the payment to the payer.
+	Memo string `json:"memo" gorm:"column:memo"`
+	// Status is open, fulfilled or cancelled.
+	Status string `json:"status" gorm:"column:status;index"`
+	// TxHash, PaidBy, PaidAmount and PaidAt describe the transfer that fulfilled the request.
+	TxHash     string  `json:"tx_hash,omitempty" gorm:"column:tx_hash"`
+	PaidBy     string  `json:"paid_by,omitempty" gorm:"column:paid_by"`
+	PaidAmount float64 `json:"paid_amount,omitempty" gorm:"column:paid_amount"`
+	PaidAt     int64   `json:"paid_at,omitempty" gorm:"column:paid_at"`
+	// ExpiresAt is the Unix timestamp after which the request isn't fulfilled anymore, 0 for never.
+	ExpiresAt int64 `json:"expires_at" gorm:"column:expires_at"`
+	// CreatedAt is the Unix timestamp when the request was created.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// TableName specifies the table name for GORM
+func (PaymentRequest) TableName() string {
+	return "payment_requests"
+}
+
+// Expired reports whether an open request can't be fulfilled anymore
+func (r *PaymentRequest) Expired(now time.Time) bool {
+	return r.ExpiresAt > 0 && r.ExpiresAt <= now.Unix()
+}
+
+// Matches reports whether a transfer of the amount fulfills the request. Amounts are compared with a
+// tolerance for the float conversion of token units.
+func (r *PaymentRequest) Matches(amount float64) bool {
+	if r.Amount == 0 {
+		return amount > 0
+	}
+	return math.Abs(amount-r.Amount) <= 1e-9*math.Max(1, r.Amount)
+}
+
+// FormattedAmount returns the requested amount without trailing zeros, empty for any amount
+func (r *PaymentRequest) FormattedAmount() string {
+	if r.Amount == 0 {
+		return ""
+	}
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.18f", r.Amount), "0"), ".")
+}
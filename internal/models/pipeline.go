@@ -0,0 +1,29 @@
+package models
+
+// Block pipeline stages, in processing order
+const (
+	PipelineStageIntake   = "intake"   // Block headers (and missed blocks) queued for processing
+	PipelineStageFetch    = "fetch"    // Blocks fetched from the node
+	PipelineStageExtract  = "extract"  // Transfers detected in the blocks (receipts, traces)
+	PipelineStageDispatch = "dispatch" // Payments and notifications handed off in chain order
+)
+
+// PipelineStageStats are the counters of one block pipeline stage since the instance started
+type PipelineStageStats struct {
+	// Stage is the stage name (intake, fetch, extract, dispatch).
+	Stage string `json:"stage"`
+	// Workers is the number of goroutines working the stage.
+	Workers int `json:"workers"`
+	// Queued is the number of blocks waiting for the stage, QueueCapacity the bound of its queue.
+	Queued        int `json:"queued"`
+	QueueCapacity int `json:"queue_capacity"`
+	// Busy is the number of blocks the stage is working on.
+	Busy int64 `json:"busy"`
+	// Processed is the number of blocks that passed the stage, Failed the number that didn't.
+	Processed uint64 `json:"processed"`
+	Failed    uint64 `json:"failed"`
+	// AvgMs, MaxMs and LastMs are the average, longest and latest time a block spent in the stage.
+	AvgMs  float64 `json:"avg_ms"`
+	MaxMs  int64   `json:"max_ms"`
+	LastMs int64   `json:"last_ms"`
+}
@@ -0,0 +1,20 @@
+package models
+
+// SubscriptionPricing is the current subscription price apps show before the user pays
+type SubscriptionPricing struct {
+	// Network is the wallet network the price applies to (xcb or xab)
+	Network string `json:"network"`
+	// MonthCost is the CTN amount that buys one subscription month. Payments extend the
+	// subscription proportionally to the price at the time they are credited.
+	MonthCost float64 `json:"month_cost"`
+	// MonthDuration is the length of one subscription month in seconds
+	MonthDuration int64 `json:"month_duration"`
+	// Currency is the token subscriptions are paid in
+	Currency string `json:"currency"`
+	// Source is where the price comes from (static, api or contract)
+	Source string `json:"source"`
+	// UpdatedAt is the Unix timestamp of the last successful price fetch (0 for the static price)
+	UpdatedAt int64 `json:"updated_at"`
+	// ReceivingAddress is where subscription payments of wallets on the network are sent to
+	ReceivingAddress string `json:"receiving_address"`
+}
@@ -0,0 +1,14 @@
+package models
+
+// ProcessedBlock marks a block whose transfers and payments were handled by one of the instances,
+// so instances catching up missed blocks don't handle it again
+type ProcessedBlock struct {
+	Number      uint64 `gorm:"primaryKey;autoIncrement:false"`
+	InstanceID  string `gorm:"size:255;not null"`
+	ProcessedAt int64  `gorm:"not null"`
+}
+
+// TableName specifies the table name for GORM
+func (ProcessedBlock) TableName() string {
+	return "processed_blocks"
+}
@@ -1,26 +1,200 @@
 package models
 
+import (
+	"context"
+	"time"
+)
+
 type Repository interface {
 	AddNewWallet(*Wallet) error
 	CheckWalletExists(address string) (bool, error)
 	GetWallet(address string) (*Wallet, error)
-	GetWalletBySubscriptionAddress(subscriptionAddress string) (*Wallet, error)
+	LookupWallet(address string, cached bool) (*Wallet, error)
+	GetWallets(addresses []string) ([]*Wallet, error)
+	GetWalletsBySubscriptionAddress(subscriptionAddress string) ([]*Wallet, error)
+	GetRegisteredAddresses(since int64) ([]string, error)
 	UpdateWalletPaidStatus(address string, paid bool) error
 	UpdateWalletSubscriptionExpiration(address string, expiresAt int64) error
+	ListWallets(opts ListOptions) (*Page[Wallet], error)
+	SearchWallets(query string, limit int) ([]*Wallet, error)
+
+	AddSubscriptionPayment(subscriptionAddress, wallet string, amount, monthCost float64, timestamp int64) error
+	GetSubscriptionPayments(wallet string) ([]*SubscriptionPayment, error)
+	ListSubscriptionPayments(opts ListOptions) (*Page[SubscriptionPayment], error)
+	SumSubscriptionPayments(network string) (float64, error)
+	GetPaymentInflow(bucketSeconds, from, to int64) ([]*PaymentInflow, error)
+	GetUnpaidWalletsWithPayments() ([]*Wallet, error)
+	TransferSubscription(transfer *SubscriptionTransfer) error
+	GetSubscriptionTransfers(address string) ([]*SubscriptionTransfer, error)
+	WithWalletEvents(fn func(repo Repository) ([]*WalletEvent, error)) error
+	GetWalletEvents(address string, until int64) ([]*WalletEvent, error)
+
+	LinkWalletOrigin(origin *WalletOrigin) error
+	GetWalletOrigins(address string) ([]*WalletOrigin, error)
+	GetWalletOriginByID(address, originID string) (*WalletOrigin, error)
+	UnlinkWalletOrigin(address, origin string) (bool, error)
+	SetWalletTokenPreference(preference *WalletTokenPreference) error
+	GetWalletTokenPreferences(address string) ([]*WalletTokenPreference, error)
+	RemoveWalletTokenPreference(address, token string) (bool, error)
+	AddWalletTag(tag *WalletTag) error
+	GetWalletTags(address string) ([]*WalletTag, error)
+	RemoveWalletTag(address, tag string) (bool, error)
+	GetWalletTagCounts() ([]*WalletTagCount, error)
+	AddUser(user *User) error
+	GetUser(id string) (*User, error)
+	GetUserWallets(userID string) ([]*Wallet, error)
+	SetWalletUser(address, userID string) error
+	RemoveUser(id string) error
+	GetUserNotificationProvider(address string) (*NotificationProvider, error)
 
-	AddSubscriptionPayment(subscriptionAddress string, amount float64, timestamp int64) error
-	GetSubscriptionPayments(subscriptionAddress string) ([]*SubscriptionPayment, error)
+	AddEmailVerification(verification *EmailVerification) error
+	GetLatestEmailVerification(email string) (*EmailVerification, error)
+	VerifyEmail(tokenHash string, now int64) (*EmailVerification, error)
 
-	RemoveOldSubscriptionPayments(timestamp int64) error
-	RemoveUnpaidSubscriptions(timestamp int64) error
+	RemoveExpiredRecords(class string, before int64) (int64, error)
+	AddShadowNotification(notification *ShadowNotification) error
+	CompareShadowNotifications(from, to int64) (*ShadowReport, error)
+	RemoveUnpaidSubscriptions(timestamp int64) ([]string, error)
 
 	GetWalletsNotificationProvider(address string) (*NotificationProvider, error)
-	UpdateNotificationProvider(address, telegram, email string) error
-	UpdateWalletMetadata(address, os, lang string) error
+	UpdateNotificationProvider(address, telegram, email, fcmToken string) error
+	SetMutedEventTypes(address string, eventTypes []string) error
+	SetWalletMinAmounts(address string, minAmounts map[string]float64) error
+	UpdateWalletMetadata(address, os, lang, appVersion string) error
+	GetWalletsForUpgradeNotice(os, minVersion string) ([]*Wallet, error)
+	SetUpgradeNotifiedVersion(address, minVersion string) error
+	SetWalletLangIfEmpty(address, lang string) error
 	SetWalletActive(address string, active bool) error
+	SetWalletOwnershipProven(address string, provenAt int64) error
 
 	AddTelegramProviderChatID(username, chatID string) error
 	GetNotificationProvidersByTelegramUsername(username string) ([]*NotificationProvider, error)
+	GetNotificationProvidersByTelegramChatID(chatID string) ([]*NotificationProvider, error)
+	DisableTelegramProvider(chatID, reason string) ([]int64, error)
+	UpdateTelegramChatID(oldChatID, newChatID string) error
+	DisableFCMProvider(token, reason string) error
+	UpsertWebhookProvider(address, url, secret string) (*WebhookProvider, error)
+	AddWebhookDelivery(delivery *WebhookDelivery) error
+	AddWebhookEvent(event *WebhookEvent) error
+	UpdateWebhookEvent(event *WebhookEvent) error
+	GetWebhookEvent(id string) (*WebhookEvent, error)
+	UpsertDiscordProvider(address, webhookURL, channelID, verificationCode string) error
+	VerifyDiscordProvider(id int64) error
+	DisableDiscordProvider(id int64, reason string) error
+	UpsertPhoneProvider(address, phone string) error
+	DisablePhoneProvider(id int64, reason string) error
+	UpsertMatrixProvider(address, roomID string) error
+	DisableMatrixProvider(id int64, reason string) error
+	SetMatrixProviderJoined(id int64, invitedBy string, joinedAt int64) error
+	UpsertNtfyProvider(address, topicURL string) error
+	DisableNtfyProvider(id int64, reason string) error
+	UpsertPushoverProvider(address, userKey string) error
+	DisablePushoverProvider(id int64, reason string) error
+
+	AddNotification(notification *Notification) error
+	GetNotification(id string) (*Notification, error)
+	NotificationExists(notification *Notification) (bool, error)
+	PendingNotificationExists(wallet, txHash string) (bool, error)
+	ListNotifications(opts ListOptions) (*Page[Notification], error)
+	AddNotificationDelivery(delivery *NotificationDelivery) error
+	ListNotificationDeliveries(wallet string, opts ListOptions) (*Page[NotificationDelivery], error)
+	MarkNotificationRead(wallet, id string, readAt int64) (bool, error)
+	CountUnreadNotifications(wallet string) (int64, error)
+	RollupNotifications(period string, bucketSeconds, from int64) error
+	GetRollupWatermark(period string) (int64, error)
+	GetNotificationRollups(period, dimension string, from, to int64) ([]*NotificationRollup, error)
+	GetOriginMetrics(origin string, from, to int64) (*OriginMetrics, error)
+	CountOriginNotifications(origin string, from, to int64) (int64, error)
+
+	AddShortLink(link *ShortLink) error
+	GetShortLink(code string) (*ShortLink, error)
+	IncrementShortLinkClicks(code string) error
+
+	GetNFTImage(tokenAddress, tokenID string) (*NFTImage, error)
+	SaveNFTImage(image *NFTImage) error
+
+	AddEmailEvents(events []*EmailEvent) error
+	SetEmailBounced(email string, bounced bool) error
+
+	UpsertDevice(device *Device) error
+	GetDevices(walletAddress string) ([]*Device, error)
+	RemoveDevice(walletAddress, deviceID string) (bool, error)
+	RemoveStaleDevices(lastSeenBefore int64) (int64, error)
+
+	UpsertWebPushSubscription(subscription *WebPushSubscription) error
+	GetWebPushSubscriptions(walletAddress string) ([]*WebPushSubscription, error)
+	RemoveWebPushSubscription(walletAddress, endpoint string) (bool, error)
+
+	AddAutomationHook(hook *AutomationHook) error
+	GetAutomationHooks(walletAddress string) ([]*AutomationHook, error)
+	RemoveAutomationHook(walletAddress, id string) (bool, error)
+
+	SetWidgetFeed(feed *WidgetFeed) error
+	GetWidgetFeed(walletAddress string) (*WidgetFeed, error)
+	RemoveWidgetFeed(walletAddress string) (bool, error)
+	GetWidgetNotifications(walletAddress string, limit int) ([]*Notification, error)
+
+	AddPaymentRequest(request *PaymentRequest) error
+	GetPaymentRequest(id string) (*PaymentRequest, error)
+	GetPaymentRequests(walletAddress string, limit int) ([]*PaymentRequest, error)
+	GetOpenPaymentRequests(walletAddress, token string) ([]*PaymentRequest, error)
+	CountOpenPaymentRequests(walletAddress string) (int64, error)
+	FulfillPaymentRequest(id, txHash, paidBy string, paidAmount float64, paidAt int64) (bool, error)
+	CancelPaymentRequest(walletAddress, id string) (bool, error)
+
+	GetMessageTemplates() ([]*MessageTemplate, error)
+	UpsertMessageTemplate(messageTemplate *MessageTemplate) error
+	DeleteMessageTemplate(lang, name string) (bool, error)
+	GetTrustedSenders() ([]*TrustedSender, error)
+	UpsertTrustedSender(sender *TrustedSender) error
+	DeleteTrustedSender(address string) (bool, error)
+	GetReadOnlyMode() (bool, error)
+	SetReadOnlyMode(readOnly bool, updatedAt int64) error
+
+	AddScheduledNotification(notification *ScheduledNotification) error
+	FinishScheduledNotification(notification *ScheduledNotification) (bool, error)
+	GetScheduledNotification(id string) (*ScheduledNotification, error)
+	GetDueScheduledNotifications(now int64, limit int) ([]*ScheduledNotification, error)
+	CancelScheduledNotification(id string) (bool, error)
+	GetActiveWalletAddresses(tag string) ([]string, error)
+
+	AddDeliveryJob(job *DeliveryJob) error
+	UpdateDeliveryJob(job *DeliveryJob) error
+	RemoveDeliveryJob(id string) error
+	ClaimDueDeliveryJobs(now, claimedUntil int64, limit int) ([]*DeliveryJob, error)
+	ListDeliveryJobs(opts ListOptions) (*Page[DeliveryJob], error)
+	AddDeadLetter(letter *DeadLetter) error
+	MoveToDeadLetters(job *DeliveryJob, failedAt int64) (*DeadLetter, error)
+	UpdateDeadLetter(letter *DeadLetter) error
+	GetDeadLetter(id string) (*DeadLetter, error)
+	ListDeadLetters(opts ListOptions) (*Page[DeadLetter], error)
+	RemoveDeadLetter(id string) (bool, error)
+
+	AddExchange(exchange *Exchange) error
+	ListExchanges() ([]*Exchange, error)
+	GetExchange(id string) (*Exchange, error)
+	GetExchangeByAPIKeyHash(hash string) (*Exchange, error)
+	RemoveExchange(id string) (bool, error)
+	GetExchangeAddresses(addresses []string) ([]*ExchangeAddress, error)
+	SaveExchangeAddresses(addresses []*ExchangeAddress) error
+	RemoveExchangeAddress(exchangeID, address string) (bool, error)
+	AddExchangeDeposits(deposits []*ExchangeDeposit) error
+	UpdateExchangeDeposit(deposit *ExchangeDeposit) error
+	GetPendingExchangeDeposits(limit int) ([]*ExchangeDeposit, error)
+	GetDeliverableExchangeDeposits(now int64, limit int) ([]*ExchangeDeposit, error)
+	ListExchangeDeposits(exchangeID string, opts ListOptions) (*Page[ExchangeDeposit], error)
+	GetExchangeDeposit(exchangeID string, id int64) (*ExchangeDeposit, error)
+	CreditExchangeDeposit(exchangeID string, id int64, amount float64, now int64) (bool, error)
+	GetExchangeReconciliation(exchangeID string, from, to int64) (*ExchangeReconciliation, error)
+
+	AddReprocessJob(job *ReprocessJob) error
+	UpdateReprocessJob(job *ReprocessJob) error
+	GetReprocessJob(id string) (*ReprocessJob, error)
+
+	MarkBlockProcessed(number uint64, instanceID string) error
+	GetProcessedBlocks(from, to uint64) ([]uint64, error)
+	GetLastProcessedBlock() (uint64, error)
+	RemoveProcessedBlocksBefore(number uint64) error
 
 	// Distributed lock methods for HA
 	TryAcquireLock(lockName, instanceID string, ttlSeconds int) (bool, error)
@@ -28,5 +202,7 @@ type Repository interface {
 	CleanupExpiredLocks() error
 
 	// Lifecycle management
+	Ping(ctx context.Context) error
+	DatabaseTime() (time.Time, error)
 	Close() error
 }
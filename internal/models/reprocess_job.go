@@ -0,0 +1,44 @@
+package models
+
+// Reprocess job statuses
+const (
+	ReprocessJobPending   = "pending"
+	ReprocessJobRunning   = "running"
+	ReprocessJobCompleted = "completed"
+	ReprocessJobFailed    = "failed"
+	ReprocessJobCancelled = "cancelled"
+)
+
+// ReprocessJob is a background re-scan of a block range scheduled by an operator.
+// Used to deliver notifications missed because of a detection bug.
+type ReprocessJob struct {
+	// ID is the random public identifier of the job.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// FromBlock is the first block of the range (inclusive).
+	FromBlock uint64 `json:"from" gorm:"column:from_block"`
+	// ToBlock is the last block of the range (inclusive).
+	ToBlock uint64 `json:"to" gorm:"column:to_block"`
+	// DryRun reports matches without sending notifications.
+	DryRun bool `json:"dry_run" gorm:"column:dry_run"`
+	// Status is the job status (pending, running, completed, failed, cancelled).
+	Status string `json:"status" gorm:"column:status;index"`
+	// CurrentBlock is the last block that was scanned.
+	CurrentBlock uint64 `json:"current_block" gorm:"column:current_block"`
+	// Matched is the number of transfers to notifiable wallets found in the range.
+	Matched int64 `json:"matched" gorm:"column:matched"`
+	// Sent is the number of notifications sent.
+	Sent int64 `json:"sent" gorm:"column:sent"`
+	// Duplicates is the number of notifications skipped because they were already sent.
+	Duplicates int64 `json:"duplicates" gorm:"column:duplicates"`
+	// Error is the reason the job failed, if any.
+	Error string `json:"error,omitempty" gorm:"column:error"`
+	// CreatedAt is the Unix timestamp when the job was scheduled.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+	// FinishedAt is the Unix timestamp when the job finished.
+	FinishedAt int64 `json:"finished_at,omitempty" gorm:"column:finished_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ReprocessJob) TableName() string {
+	return "reprocess_jobs"
+}
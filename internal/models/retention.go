@@ -0,0 +1,14 @@
+package models
+
+// Data classes with a configurable retention period
+const (
+	RetentionNotifications = "notifications" // Stored notifications (inbox and detail pages) and shadow notifications
+	RetentionPayments      = "payments"      // Subscription payments
+	RetentionAudit         = "audit"         // Email delivery events, webhook events and delivery logs, dead letters, finished reprocess jobs and scheduled notifications
+)
+
+// RetentionRule removes records of a data class once they are older than MaxAgeDays
+type RetentionRule struct {
+	Class      string
+	MaxAgeDays int // 0 = keep forever
+}
@@ -0,0 +1,43 @@
+package models
+
+// Rollup periods
+const (
+	RollupHour = "hour"
+	RollupDay  = "day"
+)
+
+// Rollup dimensions notifications are counted by
+const (
+	RollupByChannel   = "channel"    // Delivery channel (telegram, email)
+	RollupByToken     = "token"      // Token symbol
+	RollupByOrigin    = "origin"     // Originator of the recipient wallet
+	RollupByEventType = "event_type" // Notification event type
+	RollupByTag       = "tag"        // Tags of the recipient wallet, untagged wallets aren't counted
+)
+
+// RollupPeriods maps rollup periods to their bucket size in seconds (buckets are aligned to UTC)
+var RollupPeriods = map[string]int64{
+	RollupHour: 3600,
+	RollupDay:  86400,
+}
+
+// NotificationRollup is the number of notifications sent in a time bucket for one dimension value
+type NotificationRollup struct {
+	// ID is the unique identifier for the rollup row.
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// Period is the bucket size (hour, day).
+	Period string `json:"period" gorm:"column:period;uniqueIndex:idx_notification_rollups_bucket"`
+	// BucketStart is the Unix timestamp of the start of the bucket.
+	BucketStart int64 `json:"bucket_start" gorm:"column:bucket_start;uniqueIndex:idx_notification_rollups_bucket"`
+	// Dimension is what the notifications are grouped by (channel, token, origin, event_type, tag).
+	Dimension string `json:"dimension" gorm:"column:dimension;uniqueIndex:idx_notification_rollups_bucket"`
+	// Value is the channel, token symbol, originator, event type or wallet tag.
+	Value string `json:"value" gorm:"column:value;uniqueIndex:idx_notification_rollups_bucket"`
+	// Total is the number of notifications in the bucket.
+	Total int64 `json:"total" gorm:"column:total"`
+}
+
+// TableName specifies the table name for GORM
+func (NotificationRollup) TableName() string {
+	return "notification_rollups"
+}
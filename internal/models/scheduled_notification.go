@@ -0,0 +1,41 @@
+package models
+
+// Scheduled notification statuses
+const (
+	ScheduledNotificationPending   = "pending"
+	ScheduledNotificationSent      = "sent"
+	ScheduledNotificationFailed    = "failed"
+	ScheduledNotificationCancelled = "cancelled"
+)
+
+// ScheduledNotification is a message delivered at a later time, e.g. a subscription expiry
+// reminder or a maintenance notice. Scheduled by operators or internal subsystems.
+type ScheduledNotification struct {
+	// ID is the random public identifier of the scheduled notification.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// Wallet is the recipient address. Empty sends the message to all active wallets.
+	Wallet string `json:"wallet" gorm:"column:wallet;index"`
+	// Tag limits a notification without a wallet to the active wallets with the tag.
+	Tag string `json:"tag,omitempty" gorm:"column:tag"`
+	// Message is the text delivered through the wallet's notification channels.
+	Message string `json:"message" gorm:"column:message;not null"`
+	// SendAt is the Unix timestamp the notification is due at.
+	SendAt int64 `json:"send_at" gorm:"column:send_at;index"`
+	// Source identifies who scheduled the notification (admin, or the subsystem name).
+	Source string `json:"source" gorm:"column:source"`
+	// Status is the delivery status (pending, sent, failed, cancelled).
+	Status string `json:"status" gorm:"column:status;index"`
+	// Recipients is the number of wallets the notification was sent to.
+	Recipients int64 `json:"recipients" gorm:"column:recipients"`
+	// Error is the reason the delivery failed, if any.
+	Error string `json:"error,omitempty" gorm:"column:error"`
+	// CreatedAt is the Unix timestamp when the notification was scheduled.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+	// SentAt is the Unix timestamp when the notification was dispatched.
+	SentAt int64 `json:"sent_at,omitempty" gorm:"column:sent_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ScheduledNotification) TableName() string {
+	return "scheduled_notifications"
+}
@@ -0,0 +1,24 @@
+package models
+
+// ComplianceAlertHighRiskTransfer is the type of compliance alerts of transfers from high-risk senders
+const ComplianceAlertHighRiskTransfer = "high_risk_transfer"
+
+// ComplianceAlert is posted to the compliance webhook when a registered wallet receives a transfer from a
+// sender whose risk score reaches the high-risk score
+type ComplianceAlert struct {
+	Type         string  `json:"type"`
+	Wallet       string  `json:"wallet"`
+	From         string  `json:"from"`
+	RiskScore    float64 `json:"risk_score"`
+	Threshold    float64 `json:"threshold"`
+	Amount       float64 `json:"amount"`
+	Currency     string  `json:"currency"`
+	TokenAddress string  `json:"token_address,omitempty"`
+	TokenType    string  `json:"token_type,omitempty"`
+	TokenID      string  `json:"token_id,omitempty"`
+	TxHash       string  `json:"tx_hash"`
+	NetworkID    int64   `json:"network_id"`
+	// Withheld is set when the wallet isn't notified of the transfer
+	Withheld   bool  `json:"withheld"`
+	DetectedAt int64 `json:"detected_at"`
+}
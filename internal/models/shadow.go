@@ -0,0 +1,67 @@
+package models
+
+// ShadowNotification is a notification a shadow instance would have sent.
+// Shadow instances process blocks like production but only record their notifications here.
+type ShadowNotification struct {
+	// ID is the unique identifier for the record.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// InstanceID is the ID of the shadow instance that recorded the notification.
+	InstanceID string `json:"instance_id" gorm:"column:instance_id"`
+	// Wallet is the recipient address.
+	Wallet string `json:"wallet" gorm:"column:wallet;index"`
+	// From is the sender address.
+	From string `json:"from" gorm:"column:from"`
+	// Amount is the transferred amount.
+	Amount float64 `json:"amount" gorm:"column:amount"`
+	// Currency is the token symbol (e.g., CTN, USDT, XCB).
+	Currency string `json:"currency" gorm:"column:currency"`
+	// TokenAddress is the contract address (empty for XCB).
+	TokenAddress string `json:"token_address" gorm:"column:token_address"`
+	// TokenType is CBC20, CBC721, or empty for native XCB.
+	TokenType string `json:"token_type" gorm:"column:token_type"`
+	// TokenID is the NFT token ID for CBC721 transfers.
+	TokenID string `json:"token_id" gorm:"column:token_id"`
+	// TxHash is the transaction hash.
+	TxHash string `json:"tx_hash" gorm:"column:tx_hash;index"`
+	// NetworkID is the network the transaction is on (1 for mainnet, 3 for devin).
+	NetworkID int64 `json:"network_id" gorm:"column:network_id"`
+	// Internal reports a transfer between addresses of the same user.
+	Internal bool `json:"internal" gorm:"column:internal"`
+	// CreatedAt is the Unix timestamp when the notification was recorded.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// TableName specifies the table name for GORM
+func (ShadowNotification) TableName() string {
+	return "shadow_notifications"
+}
+
+// NewShadowNotification records what a shadow instance would have sent for the notification
+func NewShadowNotification(notification *Notification, instanceID string, createdAt int64) *ShadowNotification {
+	return &ShadowNotification{
+		InstanceID:   instanceID,
+		Wallet:       notification.Wallet,
+		From:         notification.From,
+		Amount:       notification.Amount,
+		Currency:     notification.Currency,
+		TokenAddress: notification.TokenAddress,
+		TokenType:    notification.TokenType,
+		TokenID:      notification.TokenID,
+		TxHash:       notification.TxHash,
+		NetworkID:    notification.NetworkID,
+		Internal:     notification.Internal,
+		CreatedAt:    createdAt,
+	}
+}
+
+// ShadowReport compares the notifications recorded by shadow instances with the ones
+// production sent in a time range. Transfers are matched by wallet, transaction, token and token ID.
+type ShadowReport struct {
+	From         int64                 `json:"from"`
+	To           int64                 `json:"to"`
+	Matched      int64                 `json:"matched"`       // Recorded by the shadow and sent by production
+	ExtraCount   int64                 `json:"extra_count"`   // Recorded by the shadow only
+	MissingCount int64                 `json:"missing_count"` // Sent by production only
+	Extra        []*ShadowNotification `json:"extra"`         // Sample of the extra notifications
+	Missing      []*Notification       `json:"missing"`       // Sample of the missing notifications
+}
@@ -0,0 +1,23 @@
+package models
+
+// ShortLink is a compact redirect link served by the API.
+// Used to keep explorer URLs short in Telegram and SMS messages and to count clicks.
+type ShortLink struct {
+	// Code is the short random code used in the /s/{code} URL.
+	Code string `json:"code" gorm:"column:code;primaryKey;size:16"`
+	// URL is the target URL of the redirect.
+	URL string `json:"url" gorm:"column:url;not null"`
+	// NotificationID links the short link to the notification it was generated for.
+	NotificationID string `json:"notification_id" gorm:"column:notification_id;index"`
+	// Clicks is the number of times the link was followed.
+	Clicks int64 `json:"clicks" gorm:"column:clicks;default:0"`
+	// CreatedAt is the Unix timestamp when the link was created.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+	// LastClickedAt is the Unix timestamp of the most recent click.
+	LastClickedAt int64 `json:"last_clicked_at" gorm:"column:last_clicked_at"`
+}
+
+// TableName specifies the table name for GORM
+func (ShortLink) TableName() string {
+	return "short_links"
+}
@@ -0,0 +1,25 @@
+package models
+
+const (
+	// ServiceStatusOK means all subsystems are healthy
+	ServiceStatusOK = "ok"
+	// ServiceStatusDegraded means at least one subsystem is unhealthy
+	ServiceStatusDegraded = "degraded"
+
+	// SubsystemBlockchain is reported when the node connection is down or blocks lag behind
+	SubsystemBlockchain = "blockchain"
+	// SubsystemDatabase is reported when the database can't be reached
+	SubsystemDatabase = "database"
+)
+
+// ServiceStatus is the coarse, public health of the notification service
+type ServiceStatus struct {
+	// Status is "ok" or "degraded"
+	Status string `json:"status"`
+	// BlockHeight is the number of the last block header received
+	BlockHeight uint64 `json:"block_height"`
+	// LagSeconds is the time since the timestamp of the last block received
+	LagSeconds int64 `json:"lag_seconds"`
+	// Degraded lists the unhealthy subsystems
+	Degraded []string `json:"degraded"`
+}
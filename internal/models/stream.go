@@ -0,0 +1,27 @@
+package models
+
+// StreamTransfer is a detected transfer as published to the transfer topic of the event stream. Consumers
+// identify transfers by TxHash and TransferIndex, a transfer may be published more than once.
+type StreamTransfer struct {
+	NetworkID   int64  `json:"network_id"`
+	BlockNumber uint64 `json:"block_number"`
+	BlockHash   string `json:"block_hash"`
+	TxHash      string `json:"tx_hash"`
+	// TransferIndex is the position of the transfer among the transfers of the transaction.
+	TransferIndex int    `json:"transfer_index"`
+	From          string `json:"from"`
+	To            string `json:"to"`
+	// Token is the normalized token contract address, or "xcb" for XCB.
+	Token      string  `json:"token"`
+	Currency   string  `json:"currency"`
+	TokenType  string  `json:"token_type,omitempty"`
+	TokenID    string  `json:"token_id,omitempty"`
+	Amount     float64 `json:"amount"`
+	DetectedAt int64   `json:"detected_at"`
+}
+
+// StreamWalletEvent is a wallet event as published to the subscription topic of the event stream
+type StreamWalletEvent struct {
+	*WalletEvent
+	NetworkID int64 `json:"network_id"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// SubscriptionTransfer records the remaining subscription time a user moved from one of their wallets to another
+// (e.g. after rotating wallets). Payments stay linked to the subscription address they were paid from;
+// the transfers are replayed together with them when subscriptions are verified.
+type SubscriptionTransfer struct {
+	// ID is the auto-incremented identifier of the transfer.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// FromAddress is the wallet the remaining time was taken from.
+	FromAddress string `json:"from_address" gorm:"column:from_address;not null;index"`
+	// ToAddress is the wallet the remaining time was added to.
+	ToAddress string `json:"to_address" gorm:"column:to_address;not null;index"`
+	// Seconds is the remaining subscription time that was moved.
+	Seconds int64 `json:"seconds" gorm:"column:seconds"`
+	// FromExpiresAt is the expiration of the source wallet before the transfer.
+	FromExpiresAt int64 `json:"from_expires_at" gorm:"column:from_expires_at"`
+	// ToExpiresAt is the expiration of the destination wallet after the transfer.
+	ToExpiresAt int64 `json:"to_expires_at" gorm:"column:to_expires_at"`
+	// ClientIP is the IP address the transfer was requested from.
+	ClientIP string `json:"client_ip" gorm:"column:client_ip"`
+	// Timestamp is the Unix timestamp of the transfer.
+	Timestamp int64 `json:"timestamp" gorm:"column:timestamp;index"`
+}
+
+// TableName specifies the table name for GORM
+func (SubscriptionTransfer) TableName() string {
+	return "subscription_transfers"
+}
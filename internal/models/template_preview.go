@@ -0,0 +1,22 @@
+package models
+
+// TemplatePreview is the result of rendering and linting a message template
+type TemplatePreview struct {
+	// Valid is false if the template fails to parse or to render for any sample
+	Valid bool `json:"valid"`
+	// Errors lists syntax and execution errors
+	Errors []string `json:"errors"`
+	// Renders contains the rendered output per channel and sample notification
+	Renders []*TemplateRender `json:"renders"`
+}
+
+// TemplateRender is a template rendered for one channel and sample notification
+type TemplateRender struct {
+	Channel string `json:"channel"`
+	Sample  string `json:"sample"`
+	// Parts are the messages actually sent after applying the channel length limit
+	Parts []string `json:"parts"`
+	// Length is the rendered length in characters before applying the channel limit
+	Length   int      `json:"length"`
+	Warnings []string `json:"warnings"`
+}
@@ -0,0 +1,38 @@
+package models
+
+// NativeTokenPreference is the token of XCB transfers in token preferences, other tokens are identified
+// by their contract address
+const NativeTokenPreference = "xcb"
+
+// WalletTokenPreference opts a wallet in or out of notifications about one token. A wallet that opted in to
+// any token is only notified about the tokens it opted in to; tokens it opted out of are never notified.
+type WalletTokenPreference struct {
+	// ID is the auto-incremented identifier of the preference.
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// WalletAddress is the wallet the preference belongs to.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;uniqueIndex:idx_wallet_token_preferences_wallet_token"`
+	// Token is the normalized contract address of the token, or NativeTokenPreference for XCB.
+	Token string `json:"token" gorm:"column:token;not null;uniqueIndex:idx_wallet_token_preferences_wallet_token"`
+	// Notify opts the wallet in (true) or out (false) of notifications about the token.
+	Notify bool `json:"notify" gorm:"column:notify;not null"`
+	// UpdatedAt is the Unix timestamp when the preference was last set.
+	UpdatedAt int64 `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WalletTokenPreference) TableName() string {
+	return "wallet_token_preferences"
+}
+
+// TokenNotified reports whether notifications about the token (NativeTokenPreference or a normalized
+// contract address) are sent under the wallet's preferences
+func TokenNotified(preferences []*WalletTokenPreference, token string) bool {
+	optedIn := false
+	for _, preference := range preferences {
+		if preference.Token == token {
+			return preference.Notify
+		}
+		optedIn = optedIn || preference.Notify
+	}
+	return !optedIn
+}
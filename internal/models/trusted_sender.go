@@ -0,0 +1,30 @@
+package models
+
+// Trusted sender categories
+const (
+	TrustedSenderExchange = "exchange" // Exchange hot wallets paying out withdrawals
+	TrustedSenderContract = "contract" // Official contracts; token contracts make their symbol a verified symbol
+	TrustedSenderService  = "service"  // Other services (payroll, faucets, ...)
+)
+
+// TrustedSenderCategories lists the valid trusted sender categories
+var TrustedSenderCategories = []string{TrustedSenderExchange, TrustedSenderContract, TrustedSenderService}
+
+// TrustedSender is a verified sender address. Notifications of transfers from it show the name with a
+// verified marker, and tokens imitating the symbol of a trusted token contract get a caution notice.
+type TrustedSender struct {
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// Address is the normalized sender address (lowercase, no 0x prefix)
+	Address string `json:"address" gorm:"column:address;uniqueIndex;not null"`
+	// Name is shown in notifications (e.g. the exchange name)
+	Name string `json:"name" gorm:"column:name;not null"`
+	// Category is exchange, contract or service
+	Category string `json:"category" gorm:"column:category"`
+	// UpdatedAt is the Unix timestamp of the latest change
+	UpdatedAt int64 `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TrustedSender) TableName() string {
+	return "trusted_senders"
+}
@@ -0,0 +1,18 @@
+package models
+
+// User is a notification identity owning several wallets, e.g. all wallets of a person. The wallets of a user
+// are notified through the notification channels and preferences of the user's primary wallet, so one Telegram
+// chat or email address serves all of them. Wallets without a user are notified through their own channels.
+type User struct {
+	// ID is the random identifier of the user.
+	ID string `json:"id" gorm:"column:id;primaryKey"`
+	// PrimaryWallet is the wallet whose notification channels and preferences apply to all wallets of the user.
+	PrimaryWallet string `json:"primary_wallet" gorm:"column:primary_wallet;not null;uniqueIndex"`
+	// CreatedAt is the Unix timestamp when the first wallet joined the primary wallet.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (User) TableName() string {
+	return "users"
+}
@@ -1,5 +1,7 @@
 package models
 
+import "strings"
+
 // Wallet represents a wallet in the system.
 type Wallet struct {
 	// Originator is the company name who is issuing it
@@ -8,17 +10,24 @@ type Wallet struct {
 	Address string `json:"address" gorm:"column:address;primaryKey"`
 	// SubscriptionAddress is the subscriber/payer address that sends payment to RECEIVING_ADDRESS.
 	// We watch for payments FROM this address TO the shared RECEIVING_ADDRESS (from config).
-	// This identifies which wallet's subscription is being paid.
-	SubscriptionAddress string `json:"subscription_address" gorm:"column:subscription_address;index;unique"`
+	// This identifies which wallet's subscription is being paid. Several wallets can share a subscription address,
+	// its payments are then attributed to the wallet designated in the transaction or split between them.
+	SubscriptionAddress string `json:"subscription_address" gorm:"column:subscription_address;index"`
 	// OriginID is a unique identifier for authentication of update/cancel operations.
 	// Format: alphanumeric string, 32 characters (from crypto.randomUUID())
 	OriginID string `json:"originid" gorm:"column:originid;index;not null"`
+	// UserID is the user the wallet belongs to, empty for wallets that don't share notification channels.
+	UserID string `json:"user_id,omitempty" gorm:"column:user_id;index"`
 	// Network is the network the wallet is on. (xcb, btc etc.)
 	Network string `json:"network" gorm:"column:network"`
 	// OS is the operating system of the user (ios, android, web, etc.)
 	OS string `json:"os" gorm:"column:os"`
 	// Lang is the language preference of the user (en, es, fr, etc.)
 	Lang string `json:"lang" gorm:"column:lang"`
+	// AppVersion is the version of the wallet app reported at the latest registration.
+	AppVersion string `json:"app_version" gorm:"column:app_version"`
+	// UpgradeNotifiedVersion is the minimum app version the user was last notified to upgrade to.
+	UpgradeNotifiedVersion string `json:"upgrade_notified_version" gorm:"column:upgrade_notified_version"`
 	// CreatedAt is the date when the wallet was created.
 	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
 	// Active indicates if notifications are enabled. User can cancel notifications while keeping subscription.
@@ -29,18 +38,41 @@ type Wallet struct {
 	Paid bool `json:"paid" gorm:"column:paid;index"`
 	// SubscriptionExpiresAt is the Unix timestamp when the subscription expires.
 	SubscriptionExpiresAt int64 `json:"subscription_expires_at" gorm:"column:subscription_expires_at"`
+	// OwnershipProven is set once the user signed an ownership challenge with the key of the wallet, it enables the
+	// features exposing the wallet's data such as the data export.
+	OwnershipProven bool `json:"ownership_proven" gorm:"column:ownership_proven"`
+	// OwnershipProvenAt is the Unix timestamp of the latest ownership proof, 0 if ownership was never proven.
+	OwnershipProvenAt int64 `json:"ownership_proven_at,omitempty" gorm:"column:ownership_proven_at"`
+	// MinAmounts is the minimum amount per currency (uppercase symbol) of transfers the wallet is notified about.
+	MinAmounts map[string]float64 `json:"min_amount,omitempty" gorm:"column:min_amounts;serializer:json"`
 	// NotificationProvider is the associated notification provider for the wallet.
 	NotificationProvider NotificationProvider `json:"notification_provider" gorm:"foreignKey:Address;references:Address;constraint:OnDelete:CASCADE"`
 }
 
+// OwnershipProof is an ownership challenge message signed with the key of an address
+type OwnershipProof struct {
+	Message   string
+	Signature string
+}
+
+// BelowMinAmount reports whether a transfer of the amount is below the wallet's minimum for the currency
+func (w *Wallet) BelowMinAmount(currency string, amount float64) bool {
+	minAmount, ok := w.MinAmounts[strings.ToUpper(currency)]
+	return ok && amount < minAmount
+}
+
 type SubscriptionPayment struct {
 	// ID is the unique identifier for the payment.
 	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
 	// Address is the subscriber/payer address that sent the payment.
 	// This matches Wallet.SubscriptionAddress to identify which wallet paid.
 	Address string `json:"address" gorm:"column:address;index"`
+	// Wallet is the wallet the payment was credited to, payments split between wallets are stored once per wallet.
+	Wallet string `json:"wallet" gorm:"column:wallet;index;not null;default:''"`
 	// Amount is the amount of CTN paid for the subscription.
 	Amount float64 `json:"amount" gorm:"column:amount"`
+	// MonthCost is the subscription price in CTN the payment was credited at (0 for payments recorded before dynamic pricing).
+	MonthCost float64 `json:"month_cost" gorm:"column:month_cost"`
 	// Timestamp is the date when the payment was made.
 	Timestamp int64 `json:"timestamp" gorm:"column:timestamp"`
 }
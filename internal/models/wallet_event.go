@@ -0,0 +1,120 @@
+package models
+
+// Wallet event types, one per wallet/subscription state transition
+const (
+	WalletEventRegistered  = "registered"
+	WalletEventPaid        = "paid"     // A payment started a new subscription
+	WalletEventExtended    = "extended" // A payment or transfer extended an active subscription
+	WalletEventExpired     = "expired"
+	WalletEventCancelled   = "cancelled"
+	WalletEventReactivated = "reactivated"
+	WalletEventRemoved     = "removed" // The unpaid wallet was removed after the registration grace period
+)
+
+// Reasons of wallet events
+const (
+	WalletEventReasonRegistration   = "registration"
+	WalletEventReasonImport         = "import"
+	WalletEventReasonPayment        = "payment"
+	WalletEventReasonResubscription = "resubscription" // Restored from the stored payments
+	WalletEventReasonTransfer       = "transfer"
+	WalletEventReasonLapsed         = "lapsed"
+	WalletEventReasonUser           = "user"
+	WalletEventReasonCleanup        = "cleanup"
+	WalletEventReasonBackfill       = "backfill" // Recorded from the wallet state when the event stream was introduced
+)
+
+// WalletEvent is an entry of the append-only stream of wallet state transitions. Replaying the events of a
+// wallet up to a point in time yields its state at that time; the events are never updated or removed.
+type WalletEvent struct {
+	// ID is the auto-incremented identifier of the event, ordering events with the same timestamp.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// Wallet is the wallet address the event belongs to.
+	Wallet string `json:"wallet" gorm:"column:wallet;not null;index:idx_wallet_events_wallet_timestamp"`
+	// Type is the transition (one of the WalletEvent* constants).
+	Type string `json:"type" gorm:"column:type;not null;index"`
+	// Reason is what caused the transition (one of the WalletEventReason* constants).
+	Reason string `json:"reason" gorm:"column:reason"`
+	// SubscriptionExpiresAt is the subscription expiration after the event.
+	SubscriptionExpiresAt int64 `json:"subscription_expires_at" gorm:"column:subscription_expires_at"`
+	// Amount is the payment amount of paid and extended events caused by a payment.
+	Amount float64 `json:"amount,omitempty" gorm:"column:amount"`
+	// Reference identifies what caused the event, e.g. the counterpart wallet of a subscription transfer.
+	Reference string `json:"reference,omitempty" gorm:"column:reference"`
+	// Timestamp is the Unix timestamp when the transition happened.
+	Timestamp int64 `json:"timestamp" gorm:"column:timestamp;not null;index:idx_wallet_events_wallet_timestamp"`
+	// RecordedAt is the Unix timestamp when the event was recorded. Expirations are detected lazily,
+	// so they are recorded after they happened.
+	RecordedAt int64 `json:"recorded_at" gorm:"column:recorded_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WalletEvent) TableName() string {
+	return "wallet_events"
+}
+
+// WalletState is the state of a wallet derived from its events
+type WalletState struct {
+	Wallet string `json:"wallet"`
+	// At is the Unix timestamp the state was derived for.
+	At int64 `json:"at"`
+	// Registered is false before the first registration and after the wallet was removed.
+	Registered            bool  `json:"registered"`
+	RegisteredAt          int64 `json:"registered_at,omitempty"`
+	Active                bool  `json:"active"`
+	Paid                  bool  `json:"paid"`
+	SubscriptionExpiresAt int64 `json:"subscription_expires_at"`
+	// LastEventID is the last event applied (0 = none).
+	LastEventID int64 `json:"last_event_id"`
+	// LastEventAt is the timestamp of the last event applied.
+	LastEventAt int64 `json:"last_event_at,omitempty"`
+}
+
+// ReplayWalletEvents derives the state of a wallet at the given timestamp from its events ordered by
+// timestamp. Events after the timestamp are ignored. A subscription whose expiration passed counts as unpaid
+// even when its expiration wasn't recorded yet.
+func ReplayWalletEvents(wallet string, events []*WalletEvent, at int64) *WalletState {
+	state := &WalletState{Wallet: wallet, At: at}
+	for _, event := range events {
+		if event.Timestamp > at {
+			break
+		}
+		switch event.Type {
+		case WalletEventRegistered:
+			state.Registered = true
+			state.RegisteredAt = event.Timestamp
+			state.Active = true
+			state.Paid = event.SubscriptionExpiresAt > event.Timestamp
+		case WalletEventPaid, WalletEventExtended:
+			state.Paid = true
+		case WalletEventExpired:
+			state.Paid = false
+		case WalletEventCancelled:
+			state.Active = false
+		case WalletEventReactivated:
+			state.Active = true
+		case WalletEventRemoved:
+			*state = WalletState{Wallet: wallet, At: at}
+		}
+		if event.Type != WalletEventRemoved {
+			state.SubscriptionExpiresAt = event.SubscriptionExpiresAt
+		}
+		state.LastEventID = event.ID
+		state.LastEventAt = event.Timestamp
+	}
+	if state.SubscriptionExpiresAt <= at {
+		state.Paid = false
+	}
+	return state
+}
+
+// Matches reports whether the derived state agrees with the stored wallet (nil = not registered)
+func (s *WalletState) Matches(wallet *Wallet) bool {
+	if wallet == nil {
+		return !s.Registered
+	}
+	return s.Registered &&
+		s.Active == wallet.Active &&
+		s.SubscriptionExpiresAt == wallet.SubscriptionExpiresAt &&
+		s.Paid == (wallet.SubscriptionExpiresAt > s.At)
+}
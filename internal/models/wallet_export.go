@@ -0,0 +1,19 @@
+package models
+
+// MaxWalletExportNotifications limits the notifications included in a wallet data export, newest first
+const MaxWalletExportNotifications = 10000
+
+// WalletExport is the data stored about a wallet, exported for its proven owner
+type WalletExport struct {
+	// Wallet is the wallet with its notification channels
+	Wallet                *Wallet                  `json:"wallet"`
+	Origins               []*WalletOrigin          `json:"origins"`
+	TokenPreferences      []*WalletTokenPreference `json:"token_preferences"`
+	Devices               []*Device                `json:"devices"`
+	Payments              []*SubscriptionPayment   `json:"payments"`
+	SubscriptionTransfers []*SubscriptionTransfer  `json:"subscription_transfers"`
+	Events                []*WalletEvent           `json:"events"`
+	// Notifications are the stored notifications of the wallet, newest first
+	Notifications []*Notification `json:"notifications"`
+	ExportedAt    int64           `json:"exported_at"`
+}
@@ -0,0 +1,40 @@
+package models
+
+// MaxWalletImportRows limits the number of wallets imported in one request
+const MaxWalletImportRows = 10000
+
+// WalletImportRow is a wallet of a partner's existing user list to import
+type WalletImportRow struct {
+	// Line is the line of the row in the imported file, reported back in the result
+	Line                int
+	Address             string
+	SubscriptionAddress string
+	Email               string
+	Telegram            string
+	Origin              string
+	// OriginID authenticates later updates of the wallet. Generated when empty.
+	OriginID string
+	// Network is xcb or xab. Defaults to the network the service runs on.
+	Network string
+}
+
+// WalletImportResult is the outcome of importing a single row
+type WalletImportResult struct {
+	Line    int    `json:"line"`
+	Address string `json:"address"`
+	Success bool   `json:"success"`
+	// OriginID is the generated OriginID to hand over to the user's app (only if the row had none)
+	OriginID string `json:"origin_id,omitempty"`
+	// Errors lists why the row was rejected
+	Errors []string `json:"errors,omitempty"`
+}
+
+// WalletImportReport is the per-row report of a wallet import
+type WalletImportReport struct {
+	// DryRun validates the rows without registering any wallet
+	DryRun   bool                  `json:"dry_run"`
+	Total    int                   `json:"total"`
+	Imported int                   `json:"imported"`
+	Failed   int                   `json:"failed"`
+	Results  []*WalletImportResult `json:"results"`
+}
@@ -0,0 +1,22 @@
+package models
+
+// WalletOrigin is an additional wallet app linked to a wallet registered by another app. The OriginID of a
+// linked origin authenticates requests for the wallet like the wallet's own OriginID, so both apps manage
+// the same registration and the user gets each notification once.
+type WalletOrigin struct {
+	// ID is the auto-incremented identifier of the link.
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// WalletAddress is the wallet the origin is linked to.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;uniqueIndex:idx_wallet_origins_wallet_origin_id"`
+	// Origin is the name of the linked wallet app. An app has at most one linked OriginID per wallet.
+	Origin string `json:"origin" gorm:"column:origin;not null"`
+	// OriginID authenticates the linked app. Never returned by the API.
+	OriginID string `json:"-" gorm:"column:origin_id;not null;uniqueIndex:idx_wallet_origins_wallet_origin_id"`
+	// LinkedAt is the Unix timestamp when the origin was linked.
+	LinkedAt int64 `json:"linked_at" gorm:"column:linked_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WalletOrigin) TableName() string {
+	return "wallet_origins"
+}
@@ -0,0 +1,25 @@
+package models
+
+// WalletTag is a segment label (e.g. vip, beta, partner-acme) an operator attached to a wallet. Tags filter
+// the wallet list and broadcasts and group the notification stats.
+type WalletTag struct {
+	// ID is the auto-incremented identifier of the tag assignment.
+	ID int64 `json:"-" gorm:"column:id;primaryKey;autoIncrement"`
+	// WalletAddress is the tagged wallet.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;uniqueIndex:idx_wallet_tags_wallet_tag"`
+	// Tag is the lowercase tag name.
+	Tag string `json:"tag" gorm:"column:tag;not null;uniqueIndex:idx_wallet_tags_wallet_tag;index"`
+	// CreatedAt is the Unix timestamp when the tag was attached.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WalletTag) TableName() string {
+	return "wallet_tags"
+}
+
+// WalletTagCount is the number of wallets carrying a tag
+type WalletTagCount struct {
+	Tag     string `json:"tag"`
+	Wallets int64  `json:"wallets"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// WebPushSubscription is a browser push subscription (PushSubscription.toJSON()) that receives notifications for a wallet.
+// A wallet can be subscribed from several browsers or extension installs.
+type WebPushSubscription struct {
+	// ID is the auto-incremented identifier of the subscription record.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// WalletAddress is the wallet the subscription receives notifications for.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;not null;uniqueIndex:idx_web_push_wallet_endpoint"`
+	// Endpoint is the push service URL messages are posted to, unique per browser subscription.
+	Endpoint string `json:"endpoint" gorm:"column:endpoint;not null;uniqueIndex:idx_web_push_wallet_endpoint"`
+	// P256dh is the base64url encoded P-256 public key of the browser payloads are encrypted for.
+	P256dh string `json:"-" gorm:"column:p256dh;not null"`
+	// Auth is the base64url encoded authentication secret of the subscription.
+	Auth string `json:"-" gorm:"column:auth;not null"`
+	// CreatedAt is the Unix timestamp when the subscription was registered.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WebPushSubscription) TableName() string {
+	return "web_push_subscriptions"
+}
@@ -0,0 +1,32 @@
+package models
+
+// WebhookDelivery is a single attempt to deliver a notification to a wallet's webhook
+type WebhookDelivery struct {
+	// ID is the auto-incremented identifier of the delivery attempt.
+	ID int64 `json:"id" gorm:"column:id;primaryKey;autoIncrement"`
+	// DeliveryID is the ID of the delivered webhook event; retries and replays of the event share it.
+	DeliveryID string `json:"delivery_id" gorm:"column:delivery_id;index;size:32"`
+	// Wallet is the wallet the notification was sent for.
+	Wallet string `json:"wallet" gorm:"column:wallet;index"`
+	// NotificationID is the ID of the stored notification (empty for custom messages).
+	NotificationID string `json:"notification_id" gorm:"column:notification_id"`
+	// URL is the webhook URL the payload was POSTed to.
+	URL string `json:"url" gorm:"column:url"`
+	// Attempt is the attempt number, starting at 1.
+	Attempt int `json:"attempt" gorm:"column:attempt"`
+	// StatusCode is the HTTP status code of the response (0 if no response was received).
+	StatusCode int `json:"status_code" gorm:"column:status_code"`
+	// Success is set when the webhook responded with a 2xx status code.
+	Success bool `json:"success" gorm:"column:success"`
+	// Error is the transport error or the beginning of the error response body.
+	Error string `json:"error,omitempty" gorm:"column:error"`
+	// DurationMs is how long the request took in milliseconds.
+	DurationMs int64 `json:"duration_ms" gorm:"column:duration_ms"`
+	// CreatedAt is the Unix timestamp of the attempt.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+}
+
+// TableName specifies the table name for GORM
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
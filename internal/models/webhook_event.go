@@ -0,0 +1,53 @@
+package models
+
+import "encoding/json"
+
+// WebhookEvent is an event sent to a wallet's webhook. The event is stored so the receiver can have it
+// replayed with the same ID, e.g. after an outage of its endpoint.
+type WebhookEvent struct {
+	// ID is the random public identifier of the event, sent as envelope id and delivery header.
+	ID string `json:"id" gorm:"column:id;primaryKey;size:32"`
+	// Type is the event type of the notification (incoming_xcb, nft_received, ...).
+	Type string `json:"type" gorm:"column:type"`
+	// Wallet is the wallet the event was sent for.
+	Wallet string `json:"wallet" gorm:"column:wallet;index"`
+	// Data is the JSON event payload, the notification for notification events.
+	Data string `json:"-" gorm:"column:data;type:text"`
+	// Attempts is the number of delivery attempts, including replays.
+	Attempts int `json:"attempts" gorm:"column:attempts"`
+	// Delivered is set once the webhook acknowledged the event with a 2xx response.
+	Delivered bool `json:"delivered" gorm:"column:delivered"`
+	// CreatedAt is the Unix timestamp when the event was created, sent as envelope timestamp.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at;index"`
+	// LastAttemptAt is the Unix timestamp of the latest delivery attempt.
+	LastAttemptAt int64 `json:"last_attempt_at" gorm:"column:last_attempt_at"`
+	// HookID is the automation hook the event was sent to, empty for events of the wallet's webhook.
+	HookID string `json:"hook_id,omitempty" gorm:"column:hook_id"`
+}
+
+// TableName specifies the table name for GORM
+func (WebhookEvent) TableName() string {
+	return "webhook_events"
+}
+
+// WebhookEnvelope is the body of every webhook request
+type WebhookEnvelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Timestamp int64           `json:"timestamp"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookReplay is the result of sending a stored webhook event once more
+type WebhookReplay struct {
+	Event *WebhookEvent `json:"event"`
+	// StatusCode is the HTTP status code of the response (0 if no response was received).
+	StatusCode int `json:"status_code"`
+	// Error is the reason the replay wasn't acknowledged, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Envelope returns the JSON request body of the event
+func (e *WebhookEvent) Envelope() ([]byte, error) {
+	return json.Marshal(&WebhookEnvelope{ID: e.ID, Type: e.Type, Timestamp: e.CreatedAt, Data: json.RawMessage(e.Data)})
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"slices"
+	"time"
+)
+
+// Fields a widget feed can publish. The rest of a notification (recipient channels, reference, detail
+// page ID) is never shown.
+const (
+	WidgetFieldAmount = "amount" // Amount and token symbol
+	WidgetFieldToken  = "token"  // Token contract address and NFT token ID
+	WidgetFieldFrom   = "from"   // Sender address and verified sender name
+	WidgetFieldTx     = "tx"     // Transaction hash and explorer link
+	WidgetFieldTime   = "time"   // Time the transfer was notified
+)
+
+// WidgetFields lists the fields a widget feed can publish
+var WidgetFields = []string{WidgetFieldAmount, WidgetFieldToken, WidgetFieldFrom, WidgetFieldTx, WidgetFieldTime}
+
+// DefaultWidgetFields are published when a wallet enables its widget feed without choosing fields
+var DefaultWidgetFields = []string{WidgetFieldAmount, WidgetFieldTime}
+
+// WidgetEventTypes are the notifications shown in widget feeds: received transfers
+var WidgetEventTypes = []string{EventIncomingXCB, EventIncomingCBC20, EventNFTReceived}
+
+// WidgetFeed opts a wallet in to a public feed of its recent incoming transfers, e.g. for a
+// "recent donations" widget on the owner's site
+type WidgetFeed struct {
+	// WalletAddress is the wallet the feed belongs to.
+	WalletAddress string `json:"wallet_address" gorm:"column:wallet_address;primaryKey"`
+	// Key signs the feed URL. Rotating it revokes the URLs handed out before.
+	Key string `json:"-" gorm:"column:key;not null"`
+	// Fields are the published fields, see WidgetFields.
+	Fields []string `json:"fields" gorm:"column:fields;serializer:json"`
+	// CreatedAt is the Unix timestamp when the feed was enabled.
+	CreatedAt int64 `json:"created_at" gorm:"column:created_at"`
+	// UpdatedAt is the Unix timestamp when the fields or key last changed.
+	UpdatedAt int64 `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WidgetFeed) TableName() string {
+	return "widget_feeds"
+}
+
+// Signature returns the signature of the feed URL
+func (f *WidgetFeed) Signature() string {
+	mac := hmac.New(sha256.New, []byte(f.Key))
+	mac.Write([]byte(f.WalletAddress))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature reports whether the signature of a feed URL is valid
+func (f *WidgetFeed) VerifySignature(signature string) bool {
+	return hmac.Equal([]byte(signature), []byte(f.Signature()))
+}
+
+// Shows reports whether the feed publishes the field
+func (f *WidgetFeed) Shows(field string) bool {
+	return slices.Contains(f.Fields, field)
+}
+
+// WidgetItem is a notification redacted to the fields its widget feed publishes. Fields that aren't
+// published are omitted.
+type WidgetItem struct {
+	EventType      string `json:"event"`
+	Amount         string `json:"amount,omitempty"`
+	Currency       string `json:"currency,omitempty"`
+	TokenAddress   string `json:"token_address,omitempty"`
+	TokenID        string `json:"token_id,omitempty"` // Decimal NFT token ID
+	From           string `json:"from,omitempty"`
+	VerifiedSender string `json:"verified_sender,omitempty"`
+	TxHash         string `json:"tx_hash,omitempty"`
+	TxLink         string `json:"tx_link,omitempty"`
+	CreatedAt      string `json:"created_at,omitempty"` // RFC 3339 timestamp
+}
+
+// NewWidgetItem redacts a notification to the fields the feed publishes
+func NewWidgetItem(notification *Notification, feed *WidgetFeed) *WidgetItem {
+	item := &WidgetItem{EventType: notification.EventType}
+	if feed.Shows(WidgetFieldAmount) {
+		item.Currency = notification.Currency
+		if notification.TokenType != "CBC721" && !notification.MixedTransfers {
+			item.Amount = notification.FormattedAmount()
+		}
+	}
+	if feed.Shows(WidgetFieldToken) {
+		item.TokenAddress = notification.TokenAddress
+		if notification.TokenID != "" {
+			item.TokenID = notification.DisplayTokenID()
+		}
+	}
+	if feed.Shows(WidgetFieldFrom) {
+		item.From = notification.From
+		item.VerifiedSender = notification.VerifiedSender
+	}
+	if feed.Shows(WidgetFieldTx) && notification.TxHash != "" {
+		item.TxHash = notification.TxHash
+		item.TxLink = notification.TxLink()
+	}
+	if feed.Shows(WidgetFieldTime) {
+		item.CreatedAt = time.Unix(notification.CreatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	return item
+}
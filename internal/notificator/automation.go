@@ -0,0 +1,65 @@
+package notificator
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// sendAutomationHooks POSTs the notification to the automation hooks of the wallet subscribed to its event type
+func (n *Notificator) sendAutomationHooks(notification *models.Notification) {
+	hooks, err := n.db.GetAutomationHooks(notification.Wallet)
+	if err != nil {
+		n.logger.Error("Failed to get automation hooks", "error", err, "wallet", notification.Wallet)
+		return
+	}
+	var item *models.AutomationItem
+	for _, hook := range hooks {
+		if !hook.Matches(notification) {
+			continue
+		}
+		if item == nil {
+			item = models.NewAutomationItem(notification)
+		}
+		h := hook
+		n.safeCall(func() { n.WebhookNotificator.SendAutomationHook(h, item) }, "automationHook")
+	}
+}
+
+// SendAutomationHook POSTs the item to an automation platform's hook as a signed event, like webhooks: it is
+// retried, stored for replays and its attempts are logged (see sendEvent). A 410 Gone response means the
+// platform unsubscribed (e.g. the Zap was turned off), so the hook is removed.
+func (w *WebhookNotificator) SendAutomationHook(hook *models.AutomationHook, item *models.AutomationItem) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		w.logger.Error("Failed to marshal automation hook payload", "error", err, "hook", hook.ID)
+		return
+	}
+	event := &models.WebhookEvent{
+		ID:        newNotificationID(),
+		Type:      item.EventType,
+		Wallet:    hook.WalletAddress,
+		HookID:    hook.ID,
+		Data:      string(data),
+		CreatedAt: time.Now().Unix(),
+	}
+	if event.Type == "" {
+		event.Type = WebhookEventNotification
+	}
+
+	statusCode, err := w.sendEvent(hook.TargetURL, hook.Secret, event, item.ID)
+	if err == nil {
+		w.logger.Debug("Automation hook delivered", "hook", hook.ID, "wallet", hook.WalletAddress, "event", event.ID)
+		return
+	}
+	if statusCode == http.StatusGone {
+		w.logger.Info("Automation hook unsubscribed by the platform", "hook", hook.ID, "wallet", hook.WalletAddress)
+		if _, err := w.db.RemoveAutomationHook(hook.WalletAddress, hook.ID); err != nil {
+			w.logger.Error("Failed to remove automation hook", "error", err, "hook", hook.ID)
+		}
+		return
+	}
+	w.logger.Error("Failed to deliver automation hook", "hook", hook.ID, "wallet", hook.WalletAddress, "event", event.ID, "error", err)
+}
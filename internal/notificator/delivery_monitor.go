@@ -0,0 +1,157 @@
+package notificator
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// DeliveryBucketSize is the granularity of the sliding failure rate window
+const DeliveryBucketSize = 1 * time.Minute
+
+// deliveryBucket counts delivery attempts of a channel in one DeliveryBucketSize interval
+type deliveryBucket struct {
+	start    time.Time
+	attempts int
+	failures int
+}
+
+// DeliveryAlert is the JSON payload posted to the ops alert webhook
+type DeliveryAlert struct {
+	Channel       string  `json:"channel"`
+	Resolved      bool    `json:"resolved"`
+	FailureRate   float64 `json:"failure_rate"`
+	Attempts      int     `json:"attempts"`
+	Failures      int     `json:"failures"`
+	WindowSeconds int     `json:"window_seconds"`
+	LastError     string  `json:"last_error,omitempty"`
+	Message       string  `json:"message"`
+}
+
+// DeliveryMonitor tracks per-channel delivery failure rates over a sliding window and alerts
+// the ops destinations when a channel's failure rate exceeds the threshold
+type DeliveryMonitor struct {
+	ops *OpsNotifier
+
+	window      time.Duration
+	threshold   float64
+	minAttempts int
+
+	mu      sync.Mutex
+	buckets map[string][]deliveryBucket
+	alerted map[string]bool
+}
+
+// NewDeliveryMonitor creates a delivery monitor alerting through ops. Returns nil when ops is nil.
+func NewDeliveryMonitor(cfg *config.Config, ops *OpsNotifier) *DeliveryMonitor {
+	if ops == nil {
+		return nil
+	}
+	return &DeliveryMonitor{
+		ops:         ops,
+		window:      time.Duration(cfg.DeliveryAlertWindowMinutes) * time.Minute,
+		threshold:   cfg.DeliveryAlertThreshold,
+		minAttempts: cfg.DeliveryAlertMinAttempts,
+		buckets:     make(map[string][]deliveryBucket),
+		alerted:     make(map[string]bool),
+	}
+}
+
+// Record counts a delivery attempt of the channel (err == nil on success) and alerts
+// when the failure rate crosses the threshold in either direction. Safe to call on a nil monitor.
+func (m *DeliveryMonitor) Record(channel string, err error) {
+	if m == nil {
+		return
+	}
+
+	alert := m.record(channel, err != nil, time.Now())
+	if alert == nil {
+		return
+	}
+	if err != nil {
+		alert.LastError = err.Error()
+	}
+	m.sendAlert(alert)
+}
+
+// record updates the sliding window and returns the alert to send, if any
+func (m *DeliveryMonitor) record(channel string, failed bool, now time.Time) *DeliveryAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Drop buckets that left the window
+	buckets := m.buckets[channel]
+	cutoff := now.Add(-m.window)
+	for len(buckets) > 0 && !buckets[0].start.After(cutoff) {
+		buckets = buckets[1:]
+	}
+
+	start := now.Truncate(DeliveryBucketSize)
+	if len(buckets) == 0 || !buckets[len(buckets)-1].start.Equal(start) {
+		buckets = append(buckets, deliveryBucket{start: start})
+	}
+	current := &buckets[len(buckets)-1]
+	current.attempts++
+	if failed {
+		current.failures++
+	}
+	m.buckets[channel] = buckets
+
+	var attempts, failures int
+	for _, bucket := range buckets {
+		attempts += bucket.attempts
+		failures += bucket.failures
+	}
+	if attempts < m.minAttempts {
+		return nil
+	}
+
+	rate := float64(failures) / float64(attempts)
+	alerted := m.alerted[channel]
+	switch {
+	case !alerted && rate >= m.threshold:
+	// Resolve only well below the threshold so a rate hovering around it doesn't flap
+	case alerted && rate < m.threshold/2:
+	default:
+		return nil
+	}
+	exceeded := !alerted
+	m.alerted[channel] = exceeded
+
+	alert := &DeliveryAlert{
+		Channel:       channel,
+		Resolved:      !exceeded,
+		FailureRate:   rate,
+		Attempts:      attempts,
+		Failures:      failures,
+		WindowSeconds: int(m.window.Seconds()),
+	}
+	if exceeded {
+		alert.Message = fmt.Sprintf("ALERT: %s delivery failure rate is %.0f%% (%d of %d) over the last %s",
+			channel, rate*100, failures, attempts, m.window)
+	} else {
+		alert.Message = fmt.Sprintf("RESOLVED: %s delivery failure rate is back to %.0f%% (%d of %d) over the last %s",
+			channel, rate*100, failures, attempts, m.window)
+	}
+	return alert
+}
+
+// sendAlert delivers an alert to the ops destinations. The webhook receives the DeliveryAlert.
+func (m *DeliveryMonitor) sendAlert(alert *DeliveryAlert) {
+	message := alert.Message
+	if alert.LastError != "" {
+		message += "\nLast error: " + alert.LastError
+	}
+	m.ops.send(&models.OpsAlert{
+		Type:      models.OpsAlertDeliveryFailure,
+		Key:       alert.Channel,
+		Severity:  models.OpsSeverityError,
+		Resolved:  alert.Resolved,
+		Value:     alert.FailureRate,
+		Threshold: m.threshold,
+		Message:   message,
+	}, alert)
+}
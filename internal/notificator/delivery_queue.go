@@ -0,0 +1,165 @@
+package notificator
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+)
+
+// MaxDeliveryRetryDelay caps the delay between two attempts of a queued delivery
+const MaxDeliveryRetryDelay = 6 * time.Hour
+
+// retryable reports whether a failed delivery through the channel is queued for a retry. Webhook events are
+// retried by the webhook sender and replayed from the wallet's webhook event log instead, and disabled
+// Telegram chats are notified through the fallback channels.
+func retryable(channel string, err error) bool {
+	return channel != templates.ChannelWebhook && !errors.Is(err, errTelegramChatUnavailable)
+}
+
+// retryDelay returns the delay before the next attempt of a delivery that failed attempts times: the
+// channel's first retry delay, doubled after every further attempt
+func retryDelay(delay time.Duration, attempts int) time.Duration {
+	for i := 1; i < attempts && delay < MaxDeliveryRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxDeliveryRetryDelay)
+}
+
+// queueRetry queues a failed delivery for its retries, or moves it to the dead-letter queue right away when
+// the channel has a single attempt. Returns the delivery status for the notification history.
+func (n *Notificator) queueRetry(notification *models.Notification, channel string, err error) string {
+	if !retryable(channel, err) {
+		return models.NotificationDeliveryFailed
+	}
+
+	now := time.Now()
+	job := &models.DeliveryJob{
+		ID:           newNotificationID(),
+		Wallet:       notification.Wallet,
+		Channel:      channel,
+		Notification: *notification,
+		Attempts:     1,
+		LastError:    err.Error(),
+		CreatedAt:    now.Unix(),
+	}
+	attempts, delay := n.retryPolicy(channel)
+	if attempts <= 1 {
+		if err := n.db.AddDeadLetter(models.NewDeadLetter(job, now.Unix())); err != nil {
+			n.logger.Error("Failed to add dead letter", "error", err, "wallet", notification.Wallet, "channel", channel)
+		}
+		return models.NotificationDeliveryFailed
+	}
+
+	job.NextAttemptAt = now.Add(retryDelay(delay, job.Attempts)).Unix()
+	if err := n.db.AddDeliveryJob(job); err != nil {
+		n.logger.Error("Failed to queue delivery retry", "error", err, "wallet", notification.Wallet, "channel", channel)
+		return models.NotificationDeliveryFailed
+	}
+	n.logger.Debug("Delivery failed, queued for a retry", "id", job.ID, "wallet", notification.Wallet, "channel", channel,
+		"next_attempt_at", job.NextAttemptAt)
+	return models.NotificationDeliveryQueued
+}
+
+// RetryDelivery retries a queued delivery. The job is removed from the queue once delivered, and moved to the
+// dead-letter queue when the channel is out of attempts or the wallet no longer uses it.
+func (n *Notificator) RetryDelivery(job *models.DeliveryJob) {
+	notification := &job.Notification
+	now := time.Now()
+
+	send, context, err := n.resend(notification, job.Channel)
+	if errors.Is(err, models.ErrChannelDisabled) {
+		job.LastError = err.Error()
+		n.deadLetter(job, now)
+		return
+	}
+	if err != nil {
+		// Most likely the database is unavailable, the job is retried once its claim expires
+		n.logger.Error("Failed to prepare delivery retry", "error", err, "id", job.ID, "wallet", job.Wallet)
+		return
+	}
+
+	job.Attempts++
+	err = n.attempt(send, context)
+	if err == nil {
+		if err := n.db.RemoveDeliveryJob(job.ID); err != nil {
+			n.logger.Error("Failed to remove delivered job from the delivery queue", "error", err, "id", job.ID)
+		}
+		n.logger.Info("Queued delivery sent", "id", job.ID, "wallet", job.Wallet, "channel", job.Channel, "attempts", job.Attempts)
+		n.recordDelivery(notification, job.Channel, models.NotificationDeliverySent, nil)
+		return
+	}
+
+	job.LastError = err.Error()
+	attempts, delay := n.retryPolicy(job.Channel)
+	if job.Attempts >= attempts || !retryable(job.Channel, err) {
+		n.deadLetter(job, now)
+		n.recordDelivery(notification, job.Channel, models.NotificationDeliveryFailed, err)
+		return
+	}
+	job.NextAttemptAt = now.Add(retryDelay(delay, job.Attempts)).Unix()
+	if err := n.db.UpdateDeliveryJob(job); err != nil {
+		n.logger.Error("Failed to reschedule delivery retry", "error", err, "id", job.ID)
+	}
+	n.recordDelivery(notification, job.Channel, models.NotificationDeliveryQueued, err)
+}
+
+// deadLetter moves a delivery job that won't be retried to the dead-letter queue
+func (n *Notificator) deadLetter(job *models.DeliveryJob, now time.Time) {
+	if _, err := n.db.MoveToDeadLetters(job, now.Unix()); err != nil {
+		n.logger.Error("Failed to move delivery job to the dead-letter queue", "error", err, "id", job.ID)
+		return
+	}
+	n.logger.Warn("Delivery moved to the dead-letter queue", "id", job.ID, "wallet", job.Wallet, "channel", job.Channel,
+		"attempts", job.Attempts, "error", job.LastError)
+}
+
+// ReplayDeadLetter sends a dead letter through its channel once more, with the wallet's current settings of the
+// channel. Returns models.ErrChannelDisabled if the wallet no longer uses the channel.
+func (n *Notificator) ReplayDeadLetter(letter *models.DeadLetter) (*models.DeliveryReplay, error) {
+	notification := &letter.Notification
+	send, context, err := n.resend(notification, letter.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	letter.Attempts++
+	replay := &models.DeliveryReplay{DeadLetter: letter}
+	status := models.NotificationDeliverySent
+	if err := n.attempt(send, context); err != nil {
+		letter.LastError = err.Error()
+		replay.Error = err.Error()
+		status = models.NotificationDeliveryFailed
+		n.recordDelivery(notification, letter.Channel, status, err)
+	} else {
+		letter.ReplayedAt = time.Now().Unix()
+		n.recordDelivery(notification, letter.Channel, status, nil)
+	}
+	if err := n.db.UpdateDeadLetter(letter); err != nil {
+		n.logger.Error("Failed to update dead letter", "error", err, "id", letter.ID)
+	}
+	return replay, nil
+}
+
+// resend returns the sender of a notification through the channel, rendered for the wallet's current
+// settings. Returns models.ErrChannelDisabled if the wallet no longer uses the channel or muted the event.
+func (n *Notificator) resend(notification *models.Notification, channel string) (func() error, string, error) {
+	provider, err := n.db.GetUserNotificationProvider(notification.Wallet)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get notification provider: %w", err)
+	}
+	if provider == nil || provider.Mutes(notification.EventType) {
+		return nil, "", models.ErrChannelDisabled
+	}
+
+	out := n.newOutgoing(notification, provider)
+	if !slices.Contains(n.channels(out), channel) {
+		return nil, "", models.ErrChannelDisabled
+	}
+	n.prepare(out, []string{channel})
+	send, context := n.sender(out, channel)
+	return send, context, nil
+}
@@ -0,0 +1,275 @@
+package notificator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// Discord sending retry settings
+	MaxDiscordRetries   = 3
+	DiscordRetryBackoff = 2 * time.Second
+	DiscordTimeout      = 10 * time.Second
+	// maxDiscordRetryAfter caps the wait requested by Discord rate limit responses
+	maxDiscordRetryAfter = 30 * time.Second
+
+	// DiscordAPIBase is the base URL of the Discord bot API
+	DiscordAPIBase = "https://discord.com/api/v10"
+	// DiscordMaxDescriptionLength is the maximum length of an embed description
+	DiscordMaxDescriptionLength = 4096
+	// DiscordVerificationMessages is how many recent channel messages are searched for a verification code
+	DiscordVerificationMessages = 50
+	// discordEmbedColor is the accent color of notification embeds
+	discordEmbedColor = 0x2563eb
+)
+
+// discordEmbed is a Discord message embed
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	URL         string              `json:"url,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// discordError is an unsuccessful Discord API response
+type discordError struct {
+	status     int
+	body       string
+	retryAfter time.Duration
+}
+
+func (e *discordError) Error() string {
+	return fmt.Sprintf("discord error %d: %s", e.status, e.body)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *discordError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// DiscordNotificator posts notifications as embeds to Discord channel webhooks or, with a bot token, to channels
+type DiscordNotificator struct {
+	logger   *logger.Logger
+	db       models.Repository
+	client   *http.Client
+	botToken string
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewDiscordNotificator creates a Discord notificator. Without a bot token only webhook URLs can be used.
+func NewDiscordNotificator(logger *logger.Logger, botToken string, db models.Repository) *DiscordNotificator {
+	return &DiscordNotificator{
+		logger:   logger,
+		db:       db,
+		client:   &http.Client{Timeout: DiscordTimeout},
+		botToken: botToken,
+	}
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (d *DiscordNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	d.monitor = monitor
+}
+
+// CanSend reports whether the provider has a destination this notificator can post to.
+// Bot channels must be verified first.
+func (d *DiscordNotificator) CanSend(provider *models.DiscordProvider) bool {
+	if provider.Disabled {
+		return false
+	}
+	return provider.WebhookURL != "" || (provider.ChannelID != "" && provider.ChannelVerified && d.botToken != "")
+}
+
+// FindCode reports whether one of the channel's recent messages contains the code. Without the message content
+// intent the bot only sees the content of messages mentioning it, so the code has to be posted with a mention.
+func (d *DiscordNotificator) FindCode(channelID, code string) (bool, error) {
+	if d.botToken == "" {
+		return false, errors.New("discord bot token not configured")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DiscordTimeout)
+	defer cancel()
+	endpoint := fmt.Sprintf("%s/channels/%s/messages?limit=%d", DiscordAPIBase, channelID, DiscordVerificationMessages)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bot "+d.botToken)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden:
+		// The channel doesn't exist or the bot isn't allowed to read it
+		return false, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, &discordError{status: resp.StatusCode, body: string(body)}
+	}
+	var messages []struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&messages); err != nil {
+		return false, fmt.Errorf("failed to decode messages: %w", err)
+	}
+	for _, message := range messages {
+		if strings.Contains(message.Content, code) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SendNotification posts the notification embed to the provider's webhook or channel, retrying
+// rate limits and server errors. Providers whose webhook or channel is gone are disabled.
+func (d *DiscordNotificator) SendNotification(provider *models.DiscordProvider, notification *models.Notification, text string) error {
+	payload, err := json.Marshal(map[string]interface{}{"embeds": []discordEmbed{newDiscordEmbed(notification, text)}})
+	if err != nil {
+		d.logger.Error("Failed to marshal discord payload", "error", err, "wallet", notification.Wallet)
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < MaxDiscordRetries; attempt++ {
+		err := d.send(provider, payload)
+		if err == nil {
+			d.logger.Debug("Discord notification sent successfully", "wallet", notification.Wallet, "attempt", attempt+1)
+			d.monitor.Record(templates.ChannelDiscord, nil)
+			return nil
+		}
+		lastErr = err
+
+		var apiErr *discordError
+		ok := errors.As(err, &apiErr)
+		if ok && d.destinationGone(provider, apiErr) {
+			d.logger.Warn("Discord destination no longer available, disabling provider", "wallet", notification.Wallet, "status", apiErr.status)
+			if err := d.db.DisableDiscordProvider(provider.ID, apiErr.Error()); err != nil {
+				d.logger.Error("Failed to disable discord provider", "error", err)
+			}
+			return apiErr
+		}
+		if ok && !apiErr.retryable() {
+			break
+		}
+		d.logger.Warn("Failed to send discord notification", "wallet", notification.Wallet, "attempt", attempt+1, "error", err)
+
+		if attempt < MaxDiscordRetries-1 {
+			wait := DiscordRetryBackoff * time.Duration(attempt+1)
+			if ok && apiErr.retryAfter > 0 {
+				wait = min(apiErr.retryAfter, maxDiscordRetryAfter)
+			}
+			time.Sleep(wait)
+		}
+	}
+
+	d.logger.Error("Failed to send discord notification", "wallet", notification.Wallet, "error", lastErr)
+	d.monitor.Record(templates.ChannelDiscord, lastErr)
+	return lastErr
+}
+
+// destinationGone reports whether Discord rejected the webhook or channel itself.
+// A 401 from the bot API means our bot token is wrong, which is not the wallet's fault.
+func (d *DiscordNotificator) destinationGone(provider *models.DiscordProvider, apiErr *discordError) bool {
+	switch apiErr.status {
+	case http.StatusNotFound, http.StatusForbidden:
+		return true
+	case http.StatusUnauthorized:
+		return provider.WebhookURL != ""
+	}
+	return false
+}
+
+// send makes a single request to the webhook or the bot API
+func (d *DiscordNotificator) send(provider *models.DiscordProvider, payload []byte) error {
+	endpoint := provider.WebhookURL
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("%s/channels/%s/messages", DiscordAPIBase, provider.ChannelID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DiscordTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if provider.WebhookURL == "" {
+		req.Header.Set("Authorization", "Bot "+d.botToken)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	apiErr := &discordError{status: resp.StatusCode, body: string(body)}
+	if seconds, err := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64); err == nil {
+		apiErr.retryAfter = time.Duration(seconds * float64(time.Second))
+	}
+	return apiErr
+}
+
+// newDiscordEmbed builds the embed of a notification. The title links to the transaction.
+func newDiscordEmbed(notification *models.Notification, text string) discordEmbed {
+	limit := MessageLimit{MaxLength: DiscordMaxDescriptionLength, Overflow: OverflowTruncate}
+	embed := discordEmbed{
+		Title:       pushTitle(notification),
+		Description: FitMessage(text, limit, "")[0],
+		Color:       discordEmbedColor,
+	}
+	if notification.TxHash != "" {
+		embed.URL = notification.TxLink()
+	}
+	if notification.CustomMessage == "" && len(notification.Transfers) <= 1 {
+		if notification.TokenType == "CBC721" {
+			embed.Fields = append(embed.Fields, discordEmbedField{Name: "Token", Value: notification.Currency + " #" + notification.DisplayTokenID(), Inline: true})
+		} else {
+			embed.Fields = append(embed.Fields, discordEmbedField{Name: "Amount", Value: notification.FormattedAmount() + " " + notification.Currency, Inline: true})
+		}
+		if notification.From != "" {
+			embed.Fields = append(embed.Fields, discordEmbedField{Name: "From", Value: notification.From, Inline: true})
+		}
+	}
+	if notification.CreatedAt > 0 {
+		embed.Timestamp = time.Unix(notification.CreatedAt, 0).UTC().Format(time.RFC3339)
+	}
+	if notification.Reference != "" {
+		embed.Footer = &discordEmbedFooter{Text: "Reference " + notification.Reference}
+	}
+	return embed
+}
@@ -0,0 +1,183 @@
+package notificator
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+)
+
+// dkimSignedHeaders are the headers covered by the DKIM signature. From is listed twice so a second From header
+// can't be added without breaking the signature.
+var dkimSignedHeaders = []string{"From", "To", "Subject", "Date", "MIME-Version", "Content-Type", "From"}
+
+// DKIMSigner adds a DKIM-Signature header (RFC 6376, relaxed/relaxed canonicalization) to outgoing emails, so
+// receivers can verify emails sent directly over SMTP came from the domain. RSA and Ed25519 (RFC 8463) keys are supported.
+type DKIMSigner struct {
+	domain    string
+	selector  string
+	key       crypto.Signer
+	algorithm string // rsa-sha256 or ed25519-sha256
+}
+
+// NewDKIMSigner loads the DKIM private key. Returns nil when no key file is configured.
+func NewDKIMSigner(cfg *config.Config) (*DKIMSigner, error) {
+	if cfg.DKIMPrivateKeyFile == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(cfg.DKIMPrivateKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DKIM private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("DKIM private key is not PEM encoded")
+	}
+
+	var key interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse DKIM private key: %w", err)
+	}
+
+	signer := &DKIMSigner{domain: cfg.DKIMSigningDomain(), selector: cfg.DKIMSelector}
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < 1024 {
+			return nil, fmt.Errorf("DKIM RSA key must have at least 1024 bits, got %d", key.N.BitLen())
+		}
+		signer.key, signer.algorithm = key, "rsa-sha256"
+	case ed25519.PrivateKey:
+		signer.key, signer.algorithm = key, "ed25519-sha256"
+	default:
+		return nil, fmt.Errorf("DKIM private key must be an RSA or Ed25519 key, got %T", key)
+	}
+	return signer, nil
+}
+
+// Sign returns the message with a DKIM-Signature header prepended. The message must use CRLF line endings.
+func (d *DKIMSigner) Sign(msg []byte) ([]byte, error) {
+	headerEnd := bytes.Index(msg, []byte("\r\n\r\n"))
+	if headerEnd < 0 {
+		return nil, fmt.Errorf("failed to sign email: message has no body")
+	}
+	headers := parseHeaders(string(msg[:headerEnd+2]))
+	body := msg[headerEnd+4:]
+
+	bodyHash := sha256.Sum256(dkimCanonicalBody(body))
+
+	// Headers are signed bottom-up; a header listed more often than it occurs is signed as empty
+	var signed bytes.Buffer
+	used := make(map[string]int)
+	names := make([]string, 0, len(dkimSignedHeaders))
+	for _, name := range dkimSignedHeaders {
+		key := strings.ToLower(name)
+		names = append(names, key)
+		values := headers[key]
+		if n := used[key]; n < len(values) {
+			signed.WriteString(dkimCanonicalHeader(key, values[len(values)-1-n]))
+			signed.WriteString("\r\n")
+		}
+		used[key]++
+	}
+
+	signature := fmt.Sprintf("v=1; a=%s; c=relaxed/relaxed; d=%s; s=%s;\r\n t=%d; h=%s;\r\n bh=%s;\r\n b=",
+		d.algorithm, d.domain, d.selector, time.Now().Unix(), strings.Join(names, ":"),
+		base64.StdEncoding.EncodeToString(bodyHash[:]))
+	signed.WriteString(dkimCanonicalHeader("dkim-signature", signature))
+
+	hash := sha256.Sum256(signed.Bytes())
+	var sig []byte
+	var err error
+	if d.algorithm == "ed25519-sha256" {
+		// RFC 8463 signs the SHA-256 hash with PureEdDSA
+		sig, err = d.key.Sign(rand.Reader, hash[:], crypto.Hash(0))
+	} else {
+		sig, err = d.key.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign email: %w", err)
+	}
+
+	var out bytes.Buffer
+	out.Grow(len(msg) + len(signature) + 512)
+	out.WriteString("DKIM-Signature: ")
+	out.WriteString(signature)
+	out.WriteString(base64.StdEncoding.EncodeToString(sig))
+	out.WriteString("\r\n")
+	out.Write(msg)
+	return out.Bytes(), nil
+}
+
+// parseHeaders returns the raw values (including folding) of the header block by lowercase name, in order
+func parseHeaders(block string) map[string][]string {
+	headers := make(map[string][]string)
+	var name, value string
+	flush := func() {
+		if name != "" {
+			headers[name] = append(headers[name], value)
+		}
+	}
+	for _, line := range strings.SplitAfter(block, "\r\n") {
+		if line == "" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			value += line
+			continue
+		}
+		flush()
+		key, rest, _ := strings.Cut(line, ":")
+		name, value = strings.ToLower(strings.TrimSpace(key)), rest
+	}
+	flush()
+	return headers
+}
+
+// dkimCanonicalHeader applies the relaxed header canonicalization: lowercase name, unfolded value with
+// whitespace runs reduced to one space and no whitespace around the value. The trailing CRLF is not included.
+func dkimCanonicalHeader(name, value string) string {
+	value = strings.NewReplacer("\r\n", "").Replace(value)
+	return name + ":" + strings.Join(strings.FieldsFunc(value, isWSP), " ")
+}
+
+// dkimCanonicalBody applies the relaxed body canonicalization: whitespace runs reduced to one space, no whitespace
+// at line ends and no empty lines at the end
+func dkimCanonicalBody(body []byte) []byte {
+	lines := strings.Split(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		fields := strings.FieldsFunc(line, isWSP)
+		canonical := strings.Join(fields, " ")
+		if len(fields) > 0 && isWSP(rune(line[0])) {
+			canonical = " " + canonical
+		}
+		lines[i] = canonical
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func isWSP(r rune) bool {
+	return r == ' ' || r == '\t'
+}
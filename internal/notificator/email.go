@@ -1,14 +1,21 @@
 package notificator
 
 import (
-	"crypto/tls"
+	"bytes"
 	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
 	"net"
 	"net/smtp"
+	"net/textproto"
 	"strconv"
 	"time"
 
 	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/faults"
 	"github.com/core-coin/nuntiare/pkg/logger"
 )
 
@@ -31,6 +38,20 @@ type EmailNotificator struct {
 
 	SMTPAuth smtp.Auth
 
+	// apiSender delivers emails through a provider API instead of SMTP when set
+	apiSender EmailSender
+	// dkim signs emails sent over SMTP (nil = unsigned)
+	dkim *DKIMSigner
+	// pool keeps authenticated SMTP connections open between emails
+	pool *smtpPool
+	// routes send the emails of matching recipients through their own relay or provider, first match wins
+	routes []*emailRoute
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+	// faults injects SMTP failures in development (nil = disabled)
+	faults *faults.Injector
+
 	db models.Repository
 }
 
@@ -52,113 +73,165 @@ func NewEmailNotificator(logger *logger.Logger, SMTPHost string, SMTPPort int, S
 		SMTPUser:            SMTPUser,
 		SMTPPassword:        SMTPPassword,
 		SMTPSender:          SMTPSender,
+		pool:                newSMTPPool(SMTPHost, net.JoinHostPort(SMTPHost, strconv.Itoa(SMTPPort)), auth, DefaultSMTPPoolSize),
 	}
 }
 
-func (e *EmailNotificator) SendNotification(to, message string) {
-	addr := fmt.Sprintf("%s:%s", e.SMTPHost, strconv.Itoa(e.SMTPPort))
-	msg := fmt.Sprintf(
-		"From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s",
-		e.SMTPSender,   // From address
-		to,             // To address
-		"Notification", // Subject
-		message,        // Email body
-	)
+// SetSMTPPoolSize sets the number of SMTP connections kept open. Must be called before emails are sent.
+func (e *EmailNotificator) SetSMTPPoolSize(size int) {
+	e.pool = newSMTPPool(e.SMTPHost, net.JoinHostPort(e.SMTPHost, strconv.Itoa(e.SMTPPort)), e.SMTPAuth, size)
+}
 
-	// Retry logic for transient failures
-	var lastErr error
-	for attempt := 0; attempt < MaxEmailRetries; attempt++ {
-		if attempt > 0 {
-			// Wait before retrying
-			time.Sleep(EmailRetryBackoff * time.Duration(attempt))
-			e.logger.Debug("Retrying email send", "attempt", attempt+1, "to", to)
+// Close ends the idle SMTP sessions
+func (e *EmailNotificator) Close() {
+	e.pool.close()
+	for _, route := range e.routes {
+		if route.pool != nil {
+			route.pool.close()
 		}
+	}
+}
 
-		// Send email with timeout
-		err := e.sendMailWithTimeout(addr, e.SMTPAuth, e.SMTPSender, []string{to}, []byte(msg))
-		if err == nil {
-			e.logger.Debug("Email notification sent successfully", "to", to, "attempt", attempt+1)
-			return
-		}
+// SetAPISender switches email delivery from SMTP to a provider API
+func (e *EmailNotificator) SetAPISender(sender EmailSender) {
+	e.apiSender = sender
+}
 
-		lastErr = err
-		e.logger.Warn("Failed to send email", "to", to, "attempt", attempt+1, "error", err)
-	}
+// SetDKIMSigner signs emails sent over SMTP with the DKIM key
+func (e *EmailNotificator) SetDKIMSigner(signer *DKIMSigner) {
+	e.dkim = signer
+}
 
-	e.logger.Error("Failed to send email notification after retries", "to", to, "attempts", MaxEmailRetries, "error", lastErr)
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (e *EmailNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	e.monitor = monitor
 }
 
-// sendMailWithTimeout sends an email with a timeout and TLS support
-func (e *EmailNotificator) sendMailWithTimeout(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
-	// Create a dialer with timeout
-	dialer := &net.Dialer{
-		Timeout: EmailTimeout,
-	}
+// SetFaultInjector enables injected email delivery failures (development only)
+func (e *EmailNotificator) SetFaultInjector(injector *faults.Injector) {
+	e.faults = injector
+}
 
-	// Connect with timeout
-	conn, err := dialer.Dial("tcp", addr)
+// CheckSMTP connects and logs in to the SMTP server without sending an email
+func (e *EmailNotificator) CheckSMTP() error {
+	conn, err := e.pool.dial()
 	if err != nil {
-		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+		return err
 	}
-	defer conn.Close()
+	conn.close()
+	return nil
+}
 
-	// Set deadline for the entire operation
-	if err := conn.SetDeadline(time.Now().Add(EmailTimeout)); err != nil {
-		return fmt.Errorf("failed to set connection deadline: %w", err)
+// send delivers a single email through the provider API or SMTP of the route, or the default ones without a route
+func (e *EmailNotificator) send(route *emailRoute, to, subject, message, html string) error {
+	if err := e.faults.Inject(faults.SMTP); err != nil {
+		return err
+	}
+	sender, apiSender, pool, dkim := e.SMTPSender, e.apiSender, e.pool, e.dkim
+	if route != nil {
+		sender, apiSender, pool = route.sender, route.apiSender, route.pool
+		if sender != e.SMTPSender {
+			// The DKIM key signs for the domain of SMTP_SENDER
+			dkim = nil
+		}
+	}
+	if apiSender != nil {
+		return apiSender.Send(to, subject, message, html)
 	}
 
-	// Create SMTP client
-	client, err := smtp.NewClient(conn, e.SMTPHost)
+	msg, err := buildEmailMessage(sender, to, subject, message, html)
 	if err != nil {
-		return fmt.Errorf("failed to create SMTP client: %w", err)
+		return err
 	}
-	defer client.Close()
-
-	// Start TLS if the server supports it (STARTTLS for port 587)
-	if ok, _ := client.Extension("STARTTLS"); ok {
-		tlsConfig := &tls.Config{
-			ServerName: e.SMTPHost,
-			MinVersion: tls.VersionTLS12,
-		}
-		if err := client.StartTLS(tlsConfig); err != nil {
-			return fmt.Errorf("failed to start TLS: %w", err)
+	if dkim != nil {
+		if msg, err = dkim.Sign(msg); err != nil {
+			return err
 		}
 	}
+	return pool.send(sender, []string{to}, msg)
+}
 
-	// Authenticate if auth is provided
-	if auth != nil {
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("SMTP authentication failed: %w", err)
+// buildEmailMessage returns the MIME message of an email. With an HTML body the message is multipart/alternative
+// with the plain text part first, so clients that can't show HTML fall back to the text.
+func buildEmailMessage(from, to, subject, text, html string) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if html == "" {
+		buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
 		}
+		return buf.Bytes(), nil
 	}
 
-	// Set sender
-	if err := client.Mail(from); err != nil {
-		return fmt.Errorf("failed to set sender: %w", err)
-	}
-
-	// Set recipients
-	for _, recipient := range to {
-		if err := client.Rcpt(recipient); err != nil {
-			return fmt.Errorf("failed to set recipient %s: %w", recipient, err)
+	writer := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", writer.Boundary())
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=UTF-8", text},
+		{"text/html; charset=UTF-8", html},
+	} {
+		partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create email part: %w", err)
+		}
+		if err := writeQuotedPrintable(partWriter, part.body); err != nil {
+			return nil, err
 		}
 	}
-
-	// Send message body
-	writer, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("failed to open data writer: %w", err)
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close email message: %w", err)
 	}
+	return buf.Bytes(), nil
+}
 
-	if _, err := writer.Write(msg); err != nil {
-		writer.Close()
-		return fmt.Errorf("failed to write message: %w", err)
+// writeQuotedPrintable writes the body quoted-printable encoded, which keeps lines within the SMTP limit
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
 	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to encode email body: %w", err)
+	}
+	return nil
+}
 
-	if err := writer.Close(); err != nil {
-		return fmt.Errorf("failed to close data writer: %w", err)
+// SendNotification sends the email of the wallet, as multipart/alternative when html is not empty. The email
+// goes through the first route matching the recipient's domain or a tag of the wallet.
+func (e *EmailNotificator) SendNotification(wallet, to, subject, message, html string) error {
+	route := e.route(wallet, to)
+
+	// Retry logic for transient failures
+	var lastErr error
+	for attempt := 0; attempt < MaxEmailRetries; attempt++ {
+		if attempt > 0 {
+			// Wait before retrying
+			time.Sleep(EmailRetryBackoff * time.Duration(attempt))
+			e.logger.Debug("Retrying email send", "attempt", attempt+1, "to", to)
+		}
+
+		// Send email with timeout
+		err := e.send(route, to, subject, message, html)
+		if err == nil {
+			e.logger.Debug("Email notification sent successfully", "to", to, "attempt", attempt+1)
+			e.monitor.Record(templates.ChannelEmail, nil)
+			return nil
+		}
+
+		lastErr = err
+		e.logger.Warn("Failed to send email", "to", to, "route", route.Name(), "attempt", attempt+1, "error", err)
 	}
 
-	// Send QUIT command
-	return client.Quit()
+	e.logger.Error("Failed to send email notification after retries", "to", to, "route", route.Name(), "attempts", MaxEmailRetries, "error", lastErr)
+	e.monitor.Record(templates.ChannelEmail, lastErr)
+	return lastErr
 }
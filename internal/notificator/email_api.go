@@ -0,0 +1,221 @@
+package notificator
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+)
+
+const (
+	// Supported email providers
+	EmailProviderSMTP     = "smtp"
+	EmailProviderSendGrid = "sendgrid"
+	EmailProviderSES      = "ses"
+	EmailProviderMailgun  = "mailgun"
+
+	// EmailAPITimeout limits a single provider API request
+	EmailAPITimeout = 30 * time.Second
+)
+
+// EmailSender delivers a single email through a provider API. The HTML body is optional.
+type EmailSender interface {
+	Send(to, subject, body, html string) error
+}
+
+// NewEmailAPISender creates the API-based email sender selected by EMAIL_PROVIDER.
+// Returns nil for the SMTP provider.
+func NewEmailAPISender(cfg *config.Config) (EmailSender, error) {
+	client := &http.Client{Timeout: EmailAPITimeout}
+
+	switch cfg.EmailProvider {
+	case EmailProviderSMTP, "":
+		return nil, nil
+	case EmailProviderSendGrid:
+		return &SendGridSender{client: client, apiKey: cfg.EmailAPIKey, from: cfg.SMTPSender}, nil
+	case EmailProviderMailgun:
+		return &MailgunSender{client: client, apiKey: cfg.EmailAPIKey, domain: cfg.MailgunDomain, baseURL: cfg.MailgunAPIBase, from: cfg.SMTPSender}, nil
+	case EmailProviderSES:
+		return &SESSender{client: client, region: cfg.SESRegion, accessKeyID: cfg.SESAccessKeyID, secretAccessKey: cfg.SESSecretAccessKey, from: cfg.SMTPSender}, nil
+	}
+	return nil, fmt.Errorf("unsupported email provider: %s", cfg.EmailProvider)
+}
+
+// doEmailAPIRequest executes a provider API request and checks the response status
+func doEmailAPIRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// SendGridSender sends emails through the SendGrid v3 Mail Send API
+type SendGridSender struct {
+	client *http.Client
+	apiKey string
+	from   string
+}
+
+func (s *SendGridSender) Send(to, subject, body, html string) error {
+	// SendGrid requires text/plain before text/html
+	content := []map[string]string{{"type": "text/plain", "value": body}}
+	if html != "" {
+		content = append(content, map[string]string{"type": "text/html", "value": html})
+	}
+	payload := map[string]interface{}{
+		"personalizations": []map[string]interface{}{
+			{"to": []map[string]string{{"email": to}}},
+		},
+		"from":    map[string]string{"email": s.from},
+		"subject": subject,
+		"content": content,
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SendGrid payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create SendGrid request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	return doEmailAPIRequest(s.client, req)
+}
+
+// MailgunSender sends emails through the Mailgun Messages API
+type MailgunSender struct {
+	client  *http.Client
+	apiKey  string
+	domain  string
+	baseURL string
+	from    string
+}
+
+func (m *MailgunSender) Send(to, subject, body, html string) error {
+	form := url.Values{}
+	form.Set("from", m.from)
+	form.Set("to", to)
+	form.Set("subject", subject)
+	form.Set("text", body)
+	if html != "" {
+		form.Set("html", html)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/%s/messages", strings.TrimRight(m.baseURL, "/"), m.domain)
+	req, err := http.NewRequest(http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create Mailgun request: %w", err)
+	}
+	req.SetBasicAuth("api", m.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return doEmailAPIRequest(m.client, req)
+}
+
+// SESSender sends emails through the Amazon SES v2 API, signing requests with AWS Signature V4
+type SESSender struct {
+	client          *http.Client
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	from            string
+}
+
+func (s *SESSender) Send(to, subject, body, html string) error {
+	bodies := map[string]interface{}{
+		"Text": map[string]string{"Data": body},
+	}
+	if html != "" {
+		bodies["Html"] = map[string]string{"Data": html}
+	}
+	payload := map[string]interface{}{
+		"FromEmailAddress": s.from,
+		"Destination":      map[string][]string{"ToAddresses": {to}},
+		"Content": map[string]interface{}{
+			"Simple": map[string]interface{}{
+				"Subject": map[string]string{"Data": subject},
+				"Body":    bodies,
+			},
+		},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal SES payload: %w", err)
+	}
+
+	host := fmt.Sprintf("email.%s.amazonaws.com", s.region)
+	req, err := http.NewRequest(http.MethodPost, "https://"+host+"/v2/email/outbound-emails", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create SES request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.sign(req, host, data, time.Now().UTC())
+
+	return doEmailAPIRequest(s.client, req)
+}
+
+// sign adds AWS Signature Version 4 headers to the request
+func (s *SESSender) sign(req *http.Request, host string, payload []byte, now time.Time) {
+	const service = "ses"
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("Host", host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := "content-type;host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n",
+		req.Header.Get("Content-Type"), host, payloadHash, amzDate)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, s.region, service)
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
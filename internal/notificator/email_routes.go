@@ -0,0 +1,118 @@
+package notificator
+
+import (
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/config"
+)
+
+// emailRoute delivers the emails of recipients with one of its domains, or of wallets with one of its tags,
+// through its own SMTP relay or provider API
+type emailRoute struct {
+	name    string
+	domains map[string]bool
+	tags    map[string]bool
+	sender  string
+
+	// apiSender delivers through a provider API, pool through SMTP when it is nil
+	apiSender EmailSender
+	pool      *smtpPool
+}
+
+// Name returns the route name for logs, "default" for emails that aren't routed
+func (r *emailRoute) Name() string {
+	if r == nil {
+		return "default"
+	}
+	return r.name
+}
+
+// SetRoutes creates the email routes of EMAIL_ROUTES_FILE. Must be called before emails are sent.
+func (e *EmailNotificator) SetRoutes(cfg *config.Config) error {
+	routes := make([]*emailRoute, 0, len(cfg.EmailRoutes))
+	for _, routeCfg := range cfg.EmailRoutes {
+		route := &emailRoute{
+			name:    routeCfg.Name,
+			domains: make(map[string]bool, len(routeCfg.Domains)),
+			tags:    make(map[string]bool, len(routeCfg.Tags)),
+			sender:  routeCfg.Sender,
+		}
+		if route.sender == "" {
+			route.sender = e.SMTPSender
+		}
+		for _, domain := range routeCfg.Domains {
+			route.domains[domain] = true
+		}
+		for _, tag := range routeCfg.Tags {
+			route.tags[tag] = true
+		}
+
+		// The API senders are created from a copy of the configuration with the route's provider settings
+		providerCfg := *cfg
+		providerCfg.EmailProvider = routeCfg.Provider
+		providerCfg.EmailAPIKey = routeCfg.APIKey
+		providerCfg.MailgunDomain = routeCfg.MailgunDomain
+		providerCfg.MailgunAPIBase = routeCfg.MailgunAPIBase
+		providerCfg.SESRegion = routeCfg.SESRegion
+		providerCfg.SESAccessKeyID = routeCfg.SESAccessKeyID
+		providerCfg.SESSecretAccessKey = routeCfg.SESSecretAccessKey
+		providerCfg.SMTPSender = route.sender
+		apiSender, err := NewEmailAPISender(&providerCfg)
+		if err != nil {
+			return err
+		}
+		route.apiSender = apiSender
+		if apiSender == nil {
+			auth := smtp.PlainAuth("", routeCfg.SMTPUser, routeCfg.SMTPPassword, routeCfg.SMTPHost)
+			route.pool = newSMTPPool(routeCfg.SMTPHost, net.JoinHostPort(routeCfg.SMTPHost, strconv.Itoa(routeCfg.SMTPPort)), auth, cfg.SMTPPoolSize)
+		}
+		routes = append(routes, route)
+	}
+	e.routes = routes
+	return nil
+}
+
+// route returns the first route matching the recipient's email domain or a tag of the wallet, nil for the
+// default relay. The wallet's tags are only loaded when a route matches on tags.
+func (e *EmailNotificator) route(wallet, to string) *emailRoute {
+	if len(e.routes) == 0 {
+		return nil
+	}
+	domain := strings.ToLower(to[strings.LastIndex(to, "@")+1:])
+
+	var tags map[string]bool
+	for _, route := range e.routes {
+		if route.domains[domain] {
+			return route
+		}
+		if len(route.tags) == 0 || wallet == "" || e.db == nil {
+			continue
+		}
+		if tags == nil {
+			tags = e.walletTags(wallet)
+		}
+		for tag := range tags {
+			if route.tags[tag] {
+				return route
+			}
+		}
+	}
+	return nil
+}
+
+// walletTags returns the tags of the wallet. Emails are sent through the default relay when they can't be loaded.
+func (e *EmailNotificator) walletTags(wallet string) map[string]bool {
+	walletTags, err := e.db.GetWalletTags(wallet)
+	if err != nil {
+		e.logger.Error("Failed to get wallet tags for email routing", "error", err, "wallet", wallet)
+		return map[string]bool{}
+	}
+	tags := make(map[string]bool, len(walletTags))
+	for _, walletTag := range walletTags {
+		tags[walletTag.Tag] = true
+	}
+	return tags
+}
@@ -0,0 +1,198 @@
+package notificator
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// ParseEmailWebhook converts a provider webhook payload into delivery events.
+// For SES (via SNS), a subscription confirmation returns the URL to confirm in subscribeURL.
+func ParseEmailWebhook(provider string, body []byte) (events []*models.EmailEvent, subscribeURL string, err error) {
+	switch provider {
+	case EmailProviderSendGrid:
+		events, err = parseSendGridWebhook(body)
+	case EmailProviderMailgun:
+		events, err = parseMailgunWebhook(body)
+	case EmailProviderSES:
+		return parseSESWebhook(body)
+	default:
+		err = fmt.Errorf("unsupported email provider: %s", provider)
+	}
+	return events, "", err
+}
+
+// parseSendGridWebhook parses a SendGrid Event Webhook batch
+func parseSendGridWebhook(body []byte) ([]*models.EmailEvent, error) {
+	var payload []struct {
+		Email     string `json:"email"`
+		Event     string `json:"event"`
+		MessageID string `json:"sg_message_id"`
+		Reason    string `json:"reason"`
+		Type      string `json:"type"`
+		Timestamp int64  `json:"timestamp"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode SendGrid webhook: %w", err)
+	}
+
+	events := make([]*models.EmailEvent, 0, len(payload))
+	for _, item := range payload {
+		var event string
+		switch item.Event {
+		case "delivered":
+			event = models.EmailEventDelivered
+		case "bounce":
+			// SendGrid reports soft bounces as type "blocked"
+			if item.Type == "blocked" {
+				event = models.EmailEventDeferred
+			} else {
+				event = models.EmailEventBounced
+			}
+		case "dropped":
+			event = models.EmailEventDropped
+		case "spamreport":
+			event = models.EmailEventComplained
+		case "deferred":
+			event = models.EmailEventDeferred
+		default:
+			continue
+		}
+		events = append(events, &models.EmailEvent{
+			Provider:  EmailProviderSendGrid,
+			Email:     item.Email,
+			Event:     event,
+			MessageID: item.MessageID,
+			Reason:    item.Reason,
+			Timestamp: item.Timestamp,
+		})
+	}
+	return events, nil
+}
+
+// parseMailgunWebhook parses a Mailgun webhook event
+func parseMailgunWebhook(body []byte) ([]*models.EmailEvent, error) {
+	var payload struct {
+		EventData struct {
+			Event     string  `json:"event"`
+			Severity  string  `json:"severity"`
+			Recipient string  `json:"recipient"`
+			Timestamp float64 `json:"timestamp"`
+			Message   struct {
+				Headers struct {
+					MessageID string `json:"message-id"`
+				} `json:"headers"`
+			} `json:"message"`
+			DeliveryStatus struct {
+				Description string `json:"description"`
+				Message     string `json:"message"`
+			} `json:"delivery-status"`
+		} `json:"event-data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode Mailgun webhook: %w", err)
+	}
+
+	data := payload.EventData
+	var event string
+	switch data.Event {
+	case "delivered":
+		event = models.EmailEventDelivered
+	case "failed":
+		if data.Severity == "temporary" {
+			event = models.EmailEventDeferred
+		} else {
+			event = models.EmailEventBounced
+		}
+	case "complained":
+		event = models.EmailEventComplained
+	default:
+		return nil, nil
+	}
+
+	reason := data.DeliveryStatus.Description
+	if reason == "" {
+		reason = data.DeliveryStatus.Message
+	}
+	return []*models.EmailEvent{{
+		Provider:  EmailProviderMailgun,
+		Email:     data.Recipient,
+		Event:     event,
+		MessageID: data.Message.Headers.MessageID,
+		Reason:    reason,
+		Timestamp: int64(data.Timestamp),
+	}}, nil
+}
+
+// parseSESWebhook parses an SES notification delivered through Amazon SNS
+func parseSESWebhook(body []byte) ([]*models.EmailEvent, string, error) {
+	var envelope struct {
+		Type         string `json:"Type"`
+		Message      string `json:"Message"`
+		SubscribeURL string `json:"SubscribeURL"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, "", fmt.Errorf("failed to decode SNS envelope: %w", err)
+	}
+
+	if envelope.Type == "SubscriptionConfirmation" {
+		return nil, envelope.SubscribeURL, nil
+	}
+	if envelope.Type != "Notification" {
+		return nil, "", nil
+	}
+
+	type recipient struct {
+		EmailAddress   string `json:"emailAddress"`
+		DiagnosticCode string `json:"diagnosticCode"`
+	}
+	var message struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Mail             struct {
+			MessageID string `json:"messageId"`
+		} `json:"mail"`
+		Bounce struct {
+			BounceType        string      `json:"bounceType"`
+			BouncedRecipients []recipient `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			ComplainedRecipients []recipient `json:"complainedRecipients"`
+		} `json:"complaint"`
+		Delivery struct {
+			Recipients []string `json:"recipients"`
+		} `json:"delivery"`
+	}
+	if err := json.Unmarshal([]byte(envelope.Message), &message); err != nil {
+		return nil, "", fmt.Errorf("failed to decode SES notification: %w", err)
+	}
+
+	notificationType := message.NotificationType
+	if notificationType == "" {
+		notificationType = message.EventType
+	}
+
+	now := time.Now().Unix()
+	var events []*models.EmailEvent
+	switch notificationType {
+	case "Delivery":
+		for _, email := range message.Delivery.Recipients {
+			events = append(events, &models.EmailEvent{Provider: EmailProviderSES, Email: email, Event: models.EmailEventDelivered, MessageID: message.Mail.MessageID, Timestamp: now})
+		}
+	case "Bounce":
+		event := models.EmailEventBounced
+		if message.Bounce.BounceType == "Transient" {
+			event = models.EmailEventDeferred
+		}
+		for _, r := range message.Bounce.BouncedRecipients {
+			events = append(events, &models.EmailEvent{Provider: EmailProviderSES, Email: r.EmailAddress, Event: event, MessageID: message.Mail.MessageID, Reason: r.DiagnosticCode, Timestamp: now})
+		}
+	case "Complaint":
+		for _, r := range message.Complaint.ComplainedRecipients {
+			events = append(events, &models.EmailEvent{Provider: EmailProviderSES, Email: r.EmailAddress, Event: models.EmailEventComplained, MessageID: message.Mail.MessageID, Timestamp: now})
+		}
+	}
+	return events, "", nil
+}
@@ -0,0 +1,316 @@
+package notificator
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// FCM sending retry settings
+	MaxFCMRetries   = 3
+	FCMRetryBackoff = 2 * time.Second
+	FCMTimeout      = 30 * time.Second
+
+	// FCMScope is the OAuth2 scope required by the FCM HTTP v1 API
+	FCMScope = "https://www.googleapis.com/auth/firebase.messaging"
+	// FCMAPIBase is the base URL of the FCM HTTP v1 API
+	FCMAPIBase = "https://fcm.googleapis.com/v1"
+	// fcmTokenRefreshMargin refreshes the OAuth2 access token before it expires
+	fcmTokenRefreshMargin = 1 * time.Minute
+)
+
+// fcmCredentials is the subset of a Firebase service account key file used to authenticate
+type fcmCredentials struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmError is an FCM HTTP v1 API error response
+type fcmError struct {
+	status  int
+	code    string // FCM error code (UNREGISTERED, INVALID_ARGUMENT, QUOTA_EXCEEDED, ...)
+	message string
+}
+
+func (e *fcmError) Error() string {
+	return fmt.Sprintf("FCM error %d %s: %s", e.status, e.code, e.message)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *fcmError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// tokenInvalid reports whether FCM rejected the registration token itself
+func (e *fcmError) tokenInvalid() bool {
+	return e.code == "UNREGISTERED" || (e.code == "INVALID_ARGUMENT" && strings.Contains(strings.ToLower(e.message), "registration token"))
+}
+
+// FCMNotificator delivers push notifications through the Firebase Cloud Messaging HTTP v1 API
+type FCMNotificator struct {
+	logger *logger.Logger
+	db     models.Repository
+	client *http.Client
+
+	projectID   string
+	clientEmail string
+	privateKey  *rsa.PrivateKey
+	tokenURI    string
+
+	// Cached OAuth2 access token
+	tokenMu     sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewFCMNotificator creates an FCM notificator from a Firebase service account key file.
+// Returns nil when no credentials file is configured.
+func NewFCMNotificator(logger *logger.Logger, credentialsFile string, db models.Repository) (*FCMNotificator, error) {
+	if credentialsFile == "" {
+		logger.Warn("FCM credentials file not provided, push notifications will be disabled")
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FCM credentials: %w", err)
+	}
+	var creds fcmCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse FCM credentials: %w", err)
+	}
+	if creds.ProjectID == "" || creds.ClientEmail == "" || creds.TokenURI == "" {
+		return nil, errors.New("FCM credentials must contain project_id, client_email and token_uri")
+	}
+
+	key, err := parseRSAPrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FCMNotificator{
+		logger:      logger,
+		db:          db,
+		client:      &http.Client{Timeout: FCMTimeout},
+		projectID:   creds.ProjectID,
+		clientEmail: creds.ClientEmail,
+		privateKey:  key,
+		tokenURI:    creds.TokenURI,
+	}, nil
+}
+
+// parseRSAPrivateKey parses the PEM-encoded PKCS#8 (or PKCS#1) key of a service account
+func parseRSAPrivateKey(data string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("FCM credentials contain no PEM private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse FCM private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("FCM private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (f *FCMNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	f.monitor = monitor
+}
+
+// SendNotification delivers a push notification to the registration token, retrying transient failures.
+// Tokens FCM reports as unregistered or invalid are disabled.
+func (f *FCMNotificator) SendNotification(token, title, body string, data map[string]string) error {
+	var lastErr error
+	for attempt := 0; attempt < MaxFCMRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(FCMRetryBackoff * time.Duration(attempt))
+			f.logger.Debug("Retrying FCM send", "attempt", attempt+1)
+		}
+
+		err := f.send(token, title, body, data)
+		if err == nil {
+			f.logger.Debug("FCM notification sent successfully", "attempt", attempt+1)
+			f.monitor.Record(templates.ChannelPush, nil)
+			return nil
+		}
+		lastErr = err
+
+		var apiErr *fcmError
+		if errors.As(err, &apiErr) {
+			// Uninstalled apps and stale tokens are user decisions, not delivery failures
+			if apiErr.tokenInvalid() {
+				f.logger.Warn("FCM token no longer valid, disabling provider", "code", apiErr.code)
+				if err := f.db.DisableFCMProvider(token, apiErr.code); err != nil {
+					f.logger.Error("Failed to disable fcm provider", "error", err)
+				}
+				return apiErr
+			}
+			if !apiErr.retryable() {
+				break
+			}
+		}
+		f.logger.Warn("Failed to send FCM notification", "attempt", attempt+1, "error", err)
+	}
+
+	f.logger.Error("Failed to send FCM notification", "error", lastErr)
+	f.monitor.Record(templates.ChannelPush, lastErr)
+	return lastErr
+}
+
+// send delivers a single message through the FCM HTTP v1 API
+func (f *FCMNotificator) send(token, title, body string, data map[string]string) error {
+	accessToken, err := f.getAccessToken()
+	if err != nil {
+		return err
+	}
+
+	message := map[string]interface{}{
+		"token":        token,
+		"notification": map[string]string{"title": title, "body": body},
+	}
+	if len(data) > 0 {
+		message["data"] = data
+	}
+	payload, err := json.Marshal(map[string]interface{}{"message": message})
+	if err != nil {
+		return fmt.Errorf("failed to marshal FCM payload: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/projects/%s/messages:send", FCMAPIBase, f.projectID)
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create FCM request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	return parseFCMError(resp)
+}
+
+// parseFCMError extracts the FCM error code from an error response
+func parseFCMError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var errResp struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	apiErr := &fcmError{status: resp.StatusCode, message: string(body)}
+	if err := json.Unmarshal(body, &errResp); err == nil {
+		apiErr.code = errResp.Error.Status
+		apiErr.message = errResp.Error.Message
+		// The FCM specific error code is more precise than the generic status
+		for _, detail := range errResp.Error.Details {
+			if detail.ErrorCode != "" {
+				apiErr.code = detail.ErrorCode
+			}
+		}
+	}
+	return apiErr
+}
+
+// getAccessToken returns a cached OAuth2 access token, exchanging a signed JWT for a new one when needed
+func (f *FCMNotificator) getAccessToken() (string, error) {
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+
+	now := time.Now()
+	if f.accessToken != "" && now.Add(fcmTokenRefreshMargin).Before(f.tokenExpiry) {
+		return f.accessToken, nil
+	}
+
+	assertion, err := f.signJWT(now)
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:jwt-bearer")
+	form.Set("assertion", assertion)
+
+	resp, err := f.client.PostForm(f.tokenURI, form)
+	if err != nil {
+		return "", fmt.Errorf("failed to request FCM access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return "", fmt.Errorf("failed to get FCM access token: status %d: %s", resp.StatusCode, string(body))
+	}
+	var tokenResp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode FCM access token: %w", err)
+	}
+
+	f.accessToken = tokenResp.AccessToken
+	f.tokenExpiry = now.Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	return f.accessToken, nil
+}
+
+// signJWT creates the RS256-signed service account assertion for the OAuth2 token exchange
+func (f *FCMNotificator) signJWT(now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   f.clientEmail,
+		"scope": FCMScope,
+		"aud":   f.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, f.privateKey, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign FCM assertion: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
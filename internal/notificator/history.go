@@ -0,0 +1,49 @@
+package notificator
+
+import (
+	"errors"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// errSenderPanicked is recorded in the notification history when a channel's sender panicked
+var errSenderPanicked = errors.New("sender panicked")
+
+// deliver sends the notification through one channel and records the outcome in the wallet's notification
+// history. Failed deliveries are queued for a retry. context names the sender in panic reports.
+func (n *Notificator) deliver(notification *models.Notification, channel, context string, send func() error) {
+	err := n.attempt(send, context)
+	status := models.NotificationDeliverySent
+	if err != nil {
+		status = n.queueRetry(notification, channel, err)
+	}
+	n.recordDelivery(notification, channel, status, err)
+}
+
+// attempt runs a channel's sender, a panic counts as failed attempt
+func (n *Notificator) attempt(send func() error, context string) error {
+	err := errSenderPanicked
+	n.safeCall(func() { err = send() }, context)
+	return err
+}
+
+// recordDelivery adds a delivery attempt to the wallet's notification history
+func (n *Notificator) recordDelivery(notification *models.Notification, channel, status string, err error) {
+	delivery := &models.NotificationDelivery{
+		Wallet:         notification.Wallet,
+		NotificationID: notification.ID,
+		TxHash:         notification.TxHash,
+		EventType:      notification.EventType,
+		Reference:      notification.Reference,
+		Channel:        channel,
+		Status:         status,
+		CreatedAt:      time.Now().Unix(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if err := n.db.AddNotificationDelivery(delivery); err != nil {
+		n.logger.Error("Failed to record notification delivery", "error", err, "wallet", notification.Wallet, "channel", channel)
+	}
+}
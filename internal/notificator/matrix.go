@@ -0,0 +1,285 @@
+package notificator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// Matrix sending retry settings
+	MaxMatrixRetries   = 3
+	MatrixRetryBackoff = 2 * time.Second
+	MatrixTimeout      = 10 * time.Second
+	// maxMatrixRetryAfter caps the wait requested by homeserver rate limit responses
+	maxMatrixRetryAfter = 30 * time.Second
+)
+
+// errMatrixNotInvited is returned when the bot is not in the room and has no pending invite to it
+var errMatrixNotInvited = errors.New("matrix bot is not invited to the room")
+
+// matrixError is an unsuccessful Matrix client-server API response
+type matrixError struct {
+	status     int
+	code       string // Matrix error code (M_FORBIDDEN, M_NOT_FOUND, M_LIMIT_EXCEEDED, ...)
+	message    string
+	retryAfter time.Duration
+}
+
+func (e *matrixError) Error() string {
+	return fmt.Sprintf("matrix error %d %s: %s", e.status, e.code, e.message)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *matrixError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// MatrixNotificator posts notifications as plain text messages to Matrix rooms through a bot account.
+// Messages are sent unencrypted, clients show them in encrypted rooms too.
+type MatrixNotificator struct {
+	logger        *logger.Logger
+	db            models.Repository
+	client        *http.Client
+	homeserverURL string
+	accessToken   string
+
+	// userID is the bot's Matrix user ID, looked up on the first invite check
+	userIDMu sync.Mutex
+	userID   string
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewMatrixNotificator creates a Matrix notificator for the bot account. Returns nil when no homeserver is configured.
+func NewMatrixNotificator(logger *logger.Logger, homeserverURL, accessToken string, db models.Repository) *MatrixNotificator {
+	if homeserverURL == "" {
+		return nil
+	}
+	return &MatrixNotificator{
+		logger:        logger,
+		db:            db,
+		client:        &http.Client{Timeout: MatrixTimeout},
+		homeserverURL: homeserverURL,
+		accessToken:   accessToken,
+	}
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (m *MatrixNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	m.monitor = monitor
+}
+
+// SendNotification posts the message to the provider's room, retrying rate limits and server errors.
+// When the bot is not in the room it joins it only if it has a pending invite, records who invited it
+// and sends again. Providers whose room the bot wasn't invited to or can't join are disabled.
+func (m *MatrixNotificator) SendNotification(provider *models.MatrixProvider, wallet, message string) error {
+	payload, err := json.Marshal(map[string]string{"msgtype": "m.text", "body": message})
+	if err != nil {
+		m.logger.Error("Failed to marshal matrix payload", "error", err, "wallet", wallet)
+		return err
+	}
+	// The transaction ID makes retries idempotent on the homeserver
+	txnID := newNotificationID()
+
+	var lastErr error
+	joined := false
+	for attempt := 0; attempt < MaxMatrixRetries; attempt++ {
+		err := m.send(provider.RoomID, txnID, payload)
+		if err == nil {
+			m.logger.Debug("Matrix notification sent successfully", "wallet", wallet, "attempt", attempt+1)
+			m.monitor.Record(templates.ChannelMatrix, nil)
+			return nil
+		}
+		lastErr = err
+
+		var apiErr *matrixError
+		ok := errors.As(err, &apiErr)
+		if ok && apiErr.status == http.StatusForbidden && !joined {
+			joined = true
+			inviter, err := m.inviter(provider.RoomID)
+			if err != nil {
+				lastErr = err
+				break
+			}
+			if inviter == "" {
+				m.logger.Warn("Matrix bot not invited to the room, disabling provider", "wallet", wallet, "room", provider.RoomID)
+				if err := m.db.DisableMatrixProvider(provider.ID, errMatrixNotInvited.Error()); err != nil {
+					m.logger.Error("Failed to disable matrix provider", "error", err)
+				}
+				return errMatrixNotInvited
+			}
+			if err := m.join(provider.RoomID); err != nil {
+				var joinErr *matrixError
+				if errors.As(err, &joinErr) && (joinErr.status == http.StatusForbidden || joinErr.status == http.StatusNotFound) {
+					m.logger.Warn("Matrix room not accessible, disabling provider", "wallet", wallet, "code", joinErr.code)
+					if err := m.db.DisableMatrixProvider(provider.ID, joinErr.Error()); err != nil {
+						m.logger.Error("Failed to disable matrix provider", "error", err)
+					}
+					return joinErr
+				}
+				lastErr = err
+				break
+			}
+			m.logger.Info("Joined matrix room", "wallet", wallet, "room", provider.RoomID, "invited_by", inviter)
+			if err := m.db.SetMatrixProviderJoined(provider.ID, inviter, time.Now().Unix()); err != nil {
+				m.logger.Error("Failed to record matrix room join", "error", err)
+			}
+			continue
+		}
+		if ok && apiErr.status == http.StatusNotFound {
+			m.logger.Warn("Matrix room not found, disabling provider", "wallet", wallet, "code", apiErr.code)
+			if err := m.db.DisableMatrixProvider(provider.ID, apiErr.Error()); err != nil {
+				m.logger.Error("Failed to disable matrix provider", "error", err)
+			}
+			return apiErr
+		}
+		if ok && !apiErr.retryable() {
+			break
+		}
+		m.logger.Warn("Failed to send matrix notification", "wallet", wallet, "attempt", attempt+1, "error", err)
+
+		if attempt < MaxMatrixRetries-1 {
+			wait := MatrixRetryBackoff * time.Duration(attempt+1)
+			if ok && apiErr.retryAfter > 0 {
+				wait = min(apiErr.retryAfter, maxMatrixRetryAfter)
+			}
+			time.Sleep(wait)
+		}
+	}
+
+	m.logger.Error("Failed to send matrix notification", "wallet", wallet, "error", lastErr)
+	m.monitor.Record(templates.ChannelMatrix, lastErr)
+	return lastErr
+}
+
+// send sends a single m.room.message event to the room
+func (m *MatrixNotificator) send(roomID, txnID string, payload []byte) error {
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s", m.homeserverURL, url.PathEscape(roomID), txnID)
+	return m.do(http.MethodPut, endpoint, payload, nil)
+}
+
+// join joins the room, which accepts the pending invite of the bot
+func (m *MatrixNotificator) join(roomID string) error {
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/join/%s", m.homeserverURL, url.PathEscape(roomID))
+	return m.do(http.MethodPost, endpoint, []byte("{}"), nil)
+}
+
+// inviter returns the user that invited the bot to the room, empty when the bot has no pending invite.
+// Pending invites are only visible through sync, the filter limits it to the membership events of the room.
+func (m *MatrixNotificator) inviter(roomID string) (string, error) {
+	userID, err := m.botUserID()
+	if err != nil {
+		return "", err
+	}
+	filter, err := json.Marshal(map[string]any{
+		"presence":     map[string]any{"types": []string{}},
+		"account_data": map[string]any{"types": []string{}},
+		"room": map[string]any{
+			"rooms":        []string{roomID},
+			"timeline":     map[string]any{"limit": 0},
+			"state":        map[string]any{"types": []string{"m.room.member"}, "lazy_load_members": true},
+			"ephemeral":    map[string]any{"types": []string{}},
+			"account_data": map[string]any{"types": []string{}},
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal sync filter: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/_matrix/client/v3/sync?timeout=0&filter=%s", m.homeserverURL, url.QueryEscape(string(filter)))
+	var sync struct {
+		Rooms struct {
+			Invite map[string]struct {
+				InviteState struct {
+					Events []struct {
+						Type     string `json:"type"`
+						StateKey string `json:"state_key"`
+						Sender   string `json:"sender"`
+						Content  struct {
+							Membership string `json:"membership"`
+						} `json:"content"`
+					} `json:"events"`
+				} `json:"invite_state"`
+			} `json:"invite"`
+		} `json:"rooms"`
+	}
+	if err := m.do(http.MethodGet, endpoint, nil, &sync); err != nil {
+		return "", fmt.Errorf("failed to get matrix invites: %w", err)
+	}
+	for _, event := range sync.Rooms.Invite[roomID].InviteState.Events {
+		if event.Type == "m.room.member" && event.StateKey == userID && event.Content.Membership == "invite" {
+			return event.Sender, nil
+		}
+	}
+	return "", nil
+}
+
+// botUserID returns the Matrix user ID of the access token's account
+func (m *MatrixNotificator) botUserID() (string, error) {
+	m.userIDMu.Lock()
+	defer m.userIDMu.Unlock()
+	if m.userID != "" {
+		return m.userID, nil
+	}
+	var whoami struct {
+		UserID string `json:"user_id"`
+	}
+	if err := m.do(http.MethodGet, m.homeserverURL+"/_matrix/client/v3/account/whoami", nil, &whoami); err != nil {
+		return "", fmt.Errorf("failed to get matrix bot user: %w", err)
+	}
+	m.userID = whoami.UserID
+	return m.userID, nil
+}
+
+// do makes an authenticated client-server API request and decodes a successful response into result when set
+func (m *MatrixNotificator) do(method, endpoint string, payload []byte, result any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), MatrixTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.accessToken)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		if result == nil {
+			return nil
+		}
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &matrixError{status: resp.StatusCode, message: string(body)}
+	var errResp struct {
+		ErrCode      string `json:"errcode"`
+		Error        string `json:"error"`
+		RetryAfterMs int64  `json:"retry_after_ms"`
+	}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.ErrCode != "" {
+		apiErr.code = errResp.ErrCode
+		apiErr.message = errResp.Error
+		apiErr.retryAfter = time.Duration(errResp.RetryAfterMs) * time.Millisecond
+	}
+	return apiErr
+}
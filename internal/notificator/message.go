@@ -0,0 +1,103 @@
+package notificator
+
+import (
+	"strings"
+)
+
+const (
+	// OverflowSplit sends a long message as several consecutive messages
+	OverflowSplit = "split"
+	// OverflowTruncate cuts a long message and appends a "view full details" link
+	OverflowTruncate = "truncate"
+
+	// truncationMarker is appended to truncated messages
+	truncationMarker = "…"
+)
+
+// MessageLimit describes the maximum message length of a channel
+// and how messages exceeding it are handled
+type MessageLimit struct {
+	// MaxLength is the maximum number of characters in a single message (0 = unlimited)
+	MaxLength int
+	// Overflow is either OverflowSplit or OverflowTruncate
+	Overflow string
+}
+
+// FitMessage adapts a message to the channel limit.
+// It returns one or more parts, each not longer than limit.MaxLength characters.
+// detailsURL, if set, is appended to truncated messages and to the last part of split ones.
+func FitMessage(message string, limit MessageLimit, detailsURL string) []string {
+	if limit.MaxLength <= 0 || runeLen(message) <= limit.MaxLength {
+		return []string{message}
+	}
+
+	detailsLine := ""
+	if detailsURL != "" {
+		detailsLine = "\nView full details: " + detailsURL
+	}
+
+	if limit.Overflow == OverflowSplit {
+		parts := splitMessage(message, limit.MaxLength)
+		last := len(parts) - 1
+		if detailsLine != "" && runeLen(parts[last])+runeLen(detailsLine) <= limit.MaxLength {
+			parts[last] += detailsLine
+		}
+		return parts
+	}
+
+	// Truncate, leaving room for the marker and the details link.
+	// If the link alone doesn't fit, drop it rather than exceed the limit.
+	room := limit.MaxLength - runeLen(truncationMarker) - runeLen(detailsLine)
+	if room <= 0 {
+		detailsLine = ""
+		room = limit.MaxLength - runeLen(truncationMarker)
+	}
+	if room <= 0 {
+		return []string{string([]rune(message)[:limit.MaxLength])}
+	}
+	return []string{strings.TrimRight(string([]rune(message)[:room]), " \n") + truncationMarker + detailsLine}
+}
+
+// splitMessage splits a message into chunks of at most maxLength characters,
+// preferring line breaks, then spaces, as split points
+func splitMessage(message string, maxLength int) []string {
+	var parts []string
+	runes := []rune(message)
+
+	for len(runes) > maxLength {
+		cut := lastIndexRune(runes[:maxLength], '\n')
+		if cut <= 0 {
+			cut = lastIndexRune(runes[:maxLength], ' ')
+		}
+		if cut <= 0 {
+			cut = maxLength
+		}
+
+		parts = append(parts, strings.TrimRight(string(runes[:cut]), " \n"))
+		runes = runes[cut:]
+		// Don't start the next part with the separator we split on
+		for len(runes) > 0 && (runes[0] == '\n' || runes[0] == ' ') {
+			runes = runes[1:]
+		}
+	}
+
+	if len(runes) > 0 {
+		parts = append(parts, string(runes))
+	}
+	return parts
+}
+
+// lastIndexRune returns the index of the last occurrence of r in runes, or -1
+func lastIndexRune(runes []rune, r rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// runeLen returns the number of characters in s
+func runeLen(s string) int {
+	return len([]rune(s))
+}
@@ -0,0 +1,80 @@
+package notificator
+
+import (
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+)
+
+// ReloadMessageTemplates loads the stored message template overrides. Invalid overrides are skipped and
+// the embedded template is used instead.
+func (n *Notificator) ReloadMessageTemplates() error {
+	overrides, err := n.db.GetMessageTemplates()
+	if err != nil {
+		return err
+	}
+	if err := n.messages.Load(overrides); err != nil {
+		n.logger.Error("Skipped invalid message templates", "error", err)
+	}
+	return nil
+}
+
+// ReloadLanguagePacks reloads the language packs of LANGUAGE_PACK_DIR. Invalid packs are skipped and the
+// embedded templates of their language are used instead.
+func (n *Notificator) ReloadLanguagePacks() {
+	if err := n.messages.ReloadLanguagePacks(); err != nil {
+		n.logger.Error("Skipped invalid message templates", "error", err)
+	}
+}
+
+// ValidateMessageTemplate returns ErrInvalidMessageTemplate unless the override parses and renders
+func (n *Notificator) ValidateMessageTemplate(lang, name, body string) error {
+	return n.messages.Validate(lang, name, body)
+}
+
+// RenderNotice renders a notice template in the wallet language, falling back to English
+func (n *Notificator) RenderNotice(lang, name string, data any) (string, error) {
+	if lang == "" {
+		lang = templates.DefaultLang
+	}
+	return n.messages.Render(normalizeLanguageCode(lang), name, data)
+}
+
+// walletLang returns the language the wallet's messages are rendered in
+func (n *Notificator) walletLang(address string) string {
+	wallet, err := n.db.GetWallet(address)
+	if err != nil || wallet == nil || wallet.Lang == "" {
+		return templates.DefaultLang
+	}
+	return normalizeLanguageCode(wallet.Lang)
+}
+
+// renderMessage renders the named message template in the language. The English default text is returned
+// if rendering fails, so a broken override never drops a notification.
+func (n *Notificator) renderMessage(lang, name string, notification *models.Notification, link, detailsURL string) string {
+	data := &templates.Data{Notification: notification, Link: link, DetailsURL: detailsURL}
+	text, err := n.messages.Render(lang, name, data)
+	if err != nil || text == "" {
+		n.logger.Error("Failed to render message template, using the default text", "error", err, "lang", lang, "template", name)
+		if name == templates.MessageEmailSubject {
+			return DefaultEmailSubject
+		}
+		return notification.Text(link)
+	}
+	return text
+}
+
+// renderEmailHTML renders the HTML part of a notification email. Returns an empty string if rendering fails,
+// the email is then sent as plain text only.
+func (n *Notificator) renderEmailHTML(lang, subject, text string, notification *models.Notification, detailsURL string) string {
+	html, err := n.messages.RenderEmailHTML(&templates.EmailHTMLData{
+		Data:    &templates.Data{Notification: notification, Link: notification.TxLink(), DetailsURL: detailsURL},
+		Lang:    lang,
+		Subject: subject,
+		Text:    text,
+	})
+	if err != nil {
+		n.logger.Error("Failed to render HTML email, sending plain text only", "error", err, "wallet", notification.Wallet)
+		return ""
+	}
+	return html
+}
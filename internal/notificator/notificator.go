@@ -1,39 +1,347 @@
 package notificator
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
 	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/core-coin/nuntiare/internal/config"
 	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
 	"github.com/core-coin/nuntiare/pkg/logger"
 )
 
+const (
+	// ShortLinkCodeLength is the number of characters in a short link code
+	ShortLinkCodeLength = 8
+	// MaxShortLinkAttempts limits retries on short link code collisions
+	MaxShortLinkAttempts = 3
+	// ReferenceLength is the number of random characters in a notification reference
+	ReferenceLength = 8
+	// DefaultEmailSubject is the subject of notification emails
+	DefaultEmailSubject = "Notification"
+	// EmailVerificationSubject is the subject of email verification emails
+	EmailVerificationSubject = "Confirm your email for wallet notifications"
+)
+
 type Notificator struct {
 	logger *logger.Logger
 	db     models.Repository
 
+	// publicURL is the public base URL of the API used for "view full details" links
+	publicURL string
+	// shortLinks enables short redirect links for explorer URLs in compact channels
+	shortLinks bool
+	// telegramLimit is the message length handling for Telegram
+	telegramLimit MessageLimit
+	// smsLimit is the message length handling for SMS
+	smsLimit MessageLimit
+	// messages renders the localized Telegram and email messages
+	messages *templates.Messages
+	// tokenEmojis maps token symbols to the emoji prepended to Telegram messages
+	tokenEmojis map[string]string
+	// ops sends operator alerts (nil when no ops destination is configured)
+	ops *OpsNotifier
+	// retryPolicy returns the delivery attempts of a channel and the delay before its first retry
+	retryPolicy func(channel string) (int, time.Duration)
+
 	TelegramNotificator *TelegramNotificator
 	EmailNotificator    *EmailNotificator
+	FCMNotificator      *FCMNotificator // nil when push notifications are disabled
+	WebhookNotificator  *WebhookNotificator
+	DiscordNotificator  *DiscordNotificator
+	SMSNotificator      *SMSNotificator     // nil when SMS notifications are disabled
+	MatrixNotificator   *MatrixNotificator  // nil when Matrix notifications are disabled
+	WebPushNotificator  *WebPushNotificator // nil when Web Push notifications are disabled
+	NtfyNotificator     *NtfyNotificator
+	PushoverNotificator *PushoverNotificator // nil when Pushover notifications are disabled
+}
+
+func NewNotificator(logger *logger.Logger, cfg *config.Config, db models.Repository, telNotif *TelegramNotificator, emailNotif *EmailNotificator, fcmNotif *FCMNotificator, smsNotif *SMSNotificator, webPushNotif *WebPushNotificator) *Notificator {
+	n := &Notificator{
+		logger:     logger,
+		db:         db,
+		publicURL:  cfg.PublicURL,
+		shortLinks: cfg.ShortLinksEnabled && cfg.PublicURL != "",
+		telegramLimit: MessageLimit{
+			MaxLength: cfg.TelegramMaxMessageLength,
+			Overflow:  cfg.TelegramMessageOverflow,
+		},
+		smsLimit: MessageLimit{
+			MaxLength: cfg.SMSMaxMessageLength,
+			Overflow:  cfg.SMSMessageOverflow,
+		},
+		messages:            templates.NewMessages(cfg.LanguagePackDir),
+		tokenEmojis:         cfg.TelegramTokenEmojis,
+		retryPolicy:         cfg.DeliveryRetryPolicy,
+		TelegramNotificator: telNotif,
+		EmailNotificator:    emailNotif,
+		FCMNotificator:      fcmNotif,
+		WebhookNotificator:  NewWebhookNotificator(logger, db, cfg.Development),
+		DiscordNotificator:  NewDiscordNotificator(logger, cfg.DiscordBotToken, db),
+		SMSNotificator:      smsNotif,
+		MatrixNotificator:   NewMatrixNotificator(logger, cfg.MatrixHomeserverURL, cfg.MatrixAccessToken, db),
+		WebPushNotificator:  webPushNotif,
+		NtfyNotificator:     NewNtfyNotificator(logger, db, cfg.Development),
+		PushoverNotificator: NewPushoverNotificator(logger, cfg, db),
+	}
+	if telNotif != nil {
+		telNotif.SetChatUnavailableHandler(n.telegramFallback)
+	}
+	n.ops = NewOpsNotifier(logger, cfg, telNotif)
+	if monitor := NewDeliveryMonitor(cfg, n.ops); monitor != nil {
+		if telNotif != nil {
+			telNotif.SetDeliveryMonitor(monitor)
+		}
+		if emailNotif != nil {
+			emailNotif.SetDeliveryMonitor(monitor)
+		}
+		if fcmNotif != nil {
+			fcmNotif.SetDeliveryMonitor(monitor)
+		}
+		if smsNotif != nil {
+			smsNotif.SetDeliveryMonitor(monitor)
+		}
+		if webPushNotif != nil {
+			webPushNotif.SetDeliveryMonitor(monitor)
+		}
+		n.WebhookNotificator.SetDeliveryMonitor(monitor)
+		n.DiscordNotificator.SetDeliveryMonitor(monitor)
+		if n.MatrixNotificator != nil {
+			n.MatrixNotificator.SetDeliveryMonitor(monitor)
+		}
+		n.NtfyNotificator.SetDeliveryMonitor(monitor)
+		if n.PushoverNotificator != nil {
+			n.PushoverNotificator.SetDeliveryMonitor(monitor)
+		}
+	}
+	return n
+}
+
+// SendOpsAlert sends an alert to the ops Telegram chat, PagerDuty and/or webhook. Alerts are only logged when
+// none is configured.
+func (n *Notificator) SendOpsAlert(alert *models.OpsAlert) {
+	if n.ops == nil {
+		n.logger.Warn("Ops alert", "type", alert.Type, "key", alert.Key, "resolved", alert.Resolved, "message", alert.Message)
+		return
+	}
+	n.ops.Send(alert)
+}
+
+// telegramFallback delivers a message that couldn't be sent to Telegram through the email of the
+// wallets whose provider was just disabled, together with a notice on how to re-enable Telegram notifications
+func (n *Notificator) telegramFallback(chatID, reason, message string, notificationProviderIDs []int64) {
+	providers, err := n.db.GetNotificationProvidersByTelegramChatID(chatID)
+	if err != nil {
+		n.logger.Error("Failed to get notification providers for fallback", "error", err, "chat_id", chatID)
+		return
+	}
+
+	for _, provider := range providers {
+		if !slices.Contains(notificationProviderIDs, provider.ID) {
+			continue
+		}
+		email := provider.EmailProvider.Email
+		if email == "" || provider.EmailProvider.Bounced || !provider.EmailProvider.Verified {
+			n.logger.Debug("No fallback channel for disabled telegram provider", "address", provider.Address)
+			continue
+		}
+
+		notice := fmt.Sprintf("Telegram notifications for the address %s have been paused (%s). "+
+			"Send /start to the bot again to resume them.\n\n%s", provider.Address, reason, message)
+		n.safeCall(func() { n.EmailNotificator.SendNotification(provider.Address, email, DefaultEmailSubject, notice, "") }, "telegramFallbackEmail")
+	}
+}
+
+// SendEmailVerification emails the double opt-in link. It is sent regardless of the verified state,
+// which is what it establishes.
+func (n *Notificator) SendEmailVerification(to, address, link string, expiresIn time.Duration) {
+	message := fmt.Sprintf("Your email was registered for transfer notifications of the wallet %s.\n\n"+
+		"Open the link below to confirm you want to receive them:\n%s\n\n"+
+		"The link is valid for %d hours. If you didn't request this, ignore this email and you won't be contacted again.",
+		address, link, int(expiresIn.Hours()))
+	n.safeCall(func() { n.EmailNotificator.SendNotification(address, to, EmailVerificationSubject, message, "") }, "emailVerification")
+}
+
+// FindDiscordCode reports whether a recent message of the Discord channel contains the code
+func (n *Notificator) FindDiscordCode(channelID, code string) (bool, error) {
+	return n.DiscordNotificator.FindCode(channelID, code)
+}
+
+// ReplayWebhookEvent sends a stored webhook event to the URL once more
+func (n *Notificator) ReplayWebhookEvent(url, secret string, event *models.WebhookEvent) *models.WebhookReplay {
+	return n.WebhookNotificator.Replay(url, secret, event)
+}
+
+// SendWebhookEvent sends a stored webhook event to the URL once, without retries
+func (n *Notificator) SendWebhookEvent(url, secret string, event *models.WebhookEvent) error {
+	return n.WebhookNotificator.Send(url, secret, event)
+}
+
+// newNotificationID generates a random, unguessable notification ID
+func newNotificationID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		// Fallback to timestamp-based ID if random generation fails
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(bytes)
+}
+
+// storeNotification persists transfer notifications so they can be linked to from
+// short-form channels. Custom messages (e.g. subscription activation) are not stored.
+func (n *Notificator) storeNotification(notification *models.Notification) {
+	if notification.TxHash == "" || notification.ID != "" {
+		return
+	}
+
+	notification.ID = newNotificationID()
+	notification.CreatedAt = time.Now().Unix()
+	if err := n.db.AddNotification(notification); err != nil {
+		n.logger.Error("Failed to store notification", "error", err, "wallet", notification.Wallet)
+		notification.ID = ""
+	}
+}
+
+// newShortLinkCode generates a random base62 short link code
+func newShortLinkCode() (string, error) {
+	const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	bytes := make([]byte, ShortLinkCodeLength)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate short link code: %w", err)
+	}
+	for i, b := range bytes {
+		bytes[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return string(bytes), nil
+}
+
+// newReference generates a notification reference: "N" followed by uppercase letters and digits
+// without look-alikes (0/O, 1/I), so it is easy to read out to support and valid as a Telegram hashtag
+func newReference() string {
+	const alphabet = "23456789ABCDEFGHJKLMNPQRSTUVWXYZ"
+	bytes := make([]byte, ReferenceLength)
+	if _, err := rand.Read(bytes); err != nil {
+		// Fallback to a timestamp-based reference if random generation fails
+		return fmt.Sprintf("N%X", time.Now().UnixNano()&0xffffffff)
+	}
+	for i, b := range bytes {
+		bytes[i] = alphabet[int(b)%len(alphabet)]
+	}
+	return "N" + string(bytes)
+}
+
+// referenceSubject returns the email subject with the notification reference as suffix
+func referenceSubject(subject string, notification *models.Notification) string {
+	if notification.Reference == "" {
+		return subject
+	}
+	return fmt.Sprintf("%s [%s]", subject, notification.Reference)
+}
+
+// fitWithReference fits a message to the limit and appends the reference hashtag to its last part,
+// reserving room for it so truncation never drops the reference
+func fitWithReference(message string, limit MessageLimit, detailsURL, tag string) []string {
+	if tag == "" {
+		return FitMessage(message, limit, detailsURL)
+	}
+	tagLine := "\n" + tag
+	if limit.MaxLength > 0 {
+		limit.MaxLength -= runeLen(tagLine)
+	}
+	parts := FitMessage(message, limit, detailsURL)
+	parts[len(parts)-1] += tagLine
+	return parts
+}
+
+// shortTxLink returns a short redirect link for the notification's explorer URL.
+// Falls back to the full explorer URL if short links are disabled or creation fails.
+func (n *Notificator) shortTxLink(notification *models.Notification) string {
+	txLink := notification.TxLink()
+	if !n.shortLinks || notification.TxHash == "" {
+		return txLink
+	}
+
+	for attempt := 0; attempt < MaxShortLinkAttempts; attempt++ {
+		code, err := newShortLinkCode()
+		if err != nil {
+			n.logger.Error("Failed to generate short link code", "error", err)
+			return txLink
+		}
+
+		link := &models.ShortLink{
+			Code:           code,
+			URL:            txLink,
+			NotificationID: notification.ID,
+			CreatedAt:      time.Now().Unix(),
+		}
+		if err := n.db.AddShortLink(link); err != nil {
+			// Most likely a code collision, retry with a new code
+			n.logger.Debug("Failed to add short link, retrying", "attempt", attempt+1, "error", err)
+			continue
+		}
+		return fmt.Sprintf("%s/s/%s", n.publicURL, code)
+	}
+
+	n.logger.Error("Failed to create short link after retries", "tx", notification.TxHash)
+	return txLink
 }
 
-func NewNotificator(logger *logger.Logger, db models.Repository, telNotif *TelegramNotificator, emailNotif *EmailNotificator) *Notificator {
-	return &Notificator{logger: logger, db: db, TelegramNotificator: telNotif, EmailNotificator: emailNotif}
+// detailsURL returns the hosted detail page URL for a stored notification
+func (n *Notificator) detailsURL(notification *models.Notification) string {
+	if n.publicURL == "" || notification.ID == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/n/%s", n.publicURL, notification.ID)
+}
+
+// withTokenEmoji prepends the configured emoji for the notification's currency, if any. Notifications of
+// transfers of different tokens get none.
+func (n *Notificator) withTokenEmoji(notification *models.Notification, text string) string {
+	if notification.MixedTransfers {
+		return text
+	}
+	if emoji, ok := n.tokenEmojis[strings.ToUpper(notification.Currency)]; ok {
+		return emoji + " " + text
+	}
+	return text
 }
 
 // safeCall runs a function with panic recovery (synchronous, no goroutine spawning)
 func (n *Notificator) safeCall(fn func(), context string) {
 	defer func() {
 		if r := recover(); r != nil {
+			stack := string(debug.Stack())
 			n.logger.Error("Function panicked",
 				"context", context,
 				"panic", r,
-				"stack", string(debug.Stack()))
+				"stack", stack)
+			n.SendOpsAlert(models.NewPanicAlert(context, r, stack))
 		}
 	}()
 	fn()
 }
 
+// outgoing is a notification being sent through the channels of its wallet
+type outgoing struct {
+	notification *models.Notification
+	provider     *models.NotificationProvider
+	// webPushSubscriptions are the browsers of the wallet that receive web push notifications
+	webPushSubscriptions []*models.WebPushSubscription
+	// pushTokens are the FCM registration tokens that receive push notifications
+	pushTokens []string
+	// lang is the wallet's language of the Telegram and email messages
+	lang       string
+	detailsURL string
+}
+
 func (n *Notificator) SendNotification(notification *models.Notification) {
-	notificationProvider, err := n.db.GetWalletsNotificationProvider(notification.Wallet)
+	// Wallets of a user are notified through the channels of the user's primary wallet
+	notificationProvider, err := n.db.GetUserNotificationProvider(notification.Wallet)
 	if err != nil {
 		n.logger.Error("Failed to get notification provider: ", err)
 		return
@@ -42,19 +350,296 @@ func (n *Notificator) SendNotification(notification *models.Notification) {
 		n.logger.Error("Notification provider not found for wallet: ", notification.Wallet)
 		return
 	}
+	if notificationProvider.Mutes(notification.EventType) {
+		n.logger.Debug("Skipping muted event type", "wallet", notification.Wallet, "event_type", notification.EventType)
+		return
+	}
+
+	if notificationProvider.TelegramProvider.ChatID != "" && notificationProvider.TelegramProvider.Disabled {
+		n.logger.Debug("Skipping disabled telegram provider", "wallet", notification.Wallet, "reason", notificationProvider.TelegramProvider.DisabledReason)
+	}
+	if notificationProvider.EmailProvider.Email != "" && notificationProvider.EmailProvider.Bounced {
+		n.logger.Debug("Skipping bounced email", "wallet", notification.Wallet)
+	} else if notificationProvider.EmailProvider.Email != "" && !notificationProvider.EmailProvider.Verified {
+		n.logger.Debug("Skipping unverified email", "wallet", notification.Wallet)
+	}
+
+	out := n.newOutgoing(notification, notificationProvider)
+	// Record the delivery channels for the notification rollups
+	channels := n.channels(out)
+	notification.Channels = strings.Join(channels, ",")
+
+	// The same reference is shown in every channel so the deliveries of one event can be correlated
+	if notification.Reference == "" {
+		notification.Reference = newReference()
+	}
+
+	n.storeNotification(notification)
+	n.prepare(out, channels)
 
 	// Send notifications synchronously (we're already in a goroutine from nuntiare.safeGo)
 	// This prevents untracked goroutine spawning
-	if notificationProvider.TelegramProvider.ChatID != "" {
-		chatID := notificationProvider.TelegramProvider.ChatID
-		message := notification.String()
-		n.safeCall(func() { n.TelegramNotificator.SendNotification(chatID, message) }, "telegramNotification")
+	for _, channel := range channels {
+		send, context := n.sender(out, channel)
+		n.deliver(notification, channel, context, send)
+	}
+	n.sendAutomationHooks(notification)
+}
+
+// newOutgoing returns the notification to send through the channels of the provider
+func (n *Notificator) newOutgoing(notification *models.Notification, provider *models.NotificationProvider) *outgoing {
+	out := &outgoing{notification: notification, provider: provider}
+	if n.WebPushNotificator != nil {
+		subscriptions, err := n.db.GetWebPushSubscriptions(notification.Wallet)
+		if err != nil {
+			n.logger.Error("Failed to get web push subscriptions", "error", err, "wallet", notification.Wallet)
+		}
+		out.webPushSubscriptions = subscriptions
+	}
+	if n.FCMNotificator != nil {
+		devices, err := n.db.GetDevices(notification.Wallet)
+		if err != nil {
+			n.logger.Error("Failed to get devices", "error", err, "wallet", notification.Wallet)
+		}
+		out.pushTokens = pushTokens(provider, devices)
+	}
+	return out
+}
+
+// pushTokens returns the push tokens of the wallet's devices that are notified, and the registered FCM token
+// unless it is disabled or belongs to a device that isn't notified
+func pushTokens(provider *models.NotificationProvider, devices []*models.Device) []string {
+	var tokens []string
+	muted := make(map[string]bool)
+	for _, device := range devices {
+		if device.PushToken == "" {
+			continue
+		}
+		if !device.Notify {
+			muted[device.PushToken] = true
+			continue
+		}
+		if !slices.Contains(tokens, device.PushToken) {
+			tokens = append(tokens, device.PushToken)
+		}
+	}
+	fcm := provider.FCMProvider
+	if fcm.Token != "" && !fcm.Disabled && !muted[fcm.Token] && !slices.Contains(tokens, fcm.Token) {
+		tokens = append(tokens, fcm.Token)
+	}
+	return tokens
+}
+
+// channels returns the channels the notification is sent through, in delivery order
+func (n *Notificator) channels(out *outgoing) []string {
+	provider := out.provider
+	var channels []string
+	if provider.TelegramProvider.ChatID != "" && !provider.TelegramProvider.Disabled {
+		channels = append(channels, templates.ChannelTelegram)
+	}
+	// Only verified emails are used, so the service can't be used to mail arbitrary addresses
+	if provider.EmailProvider.Email != "" && !provider.EmailProvider.Bounced && provider.EmailProvider.Verified {
+		channels = append(channels, templates.ChannelEmail)
+	}
+	if n.FCMNotificator != nil && len(out.pushTokens) > 0 {
+		channels = append(channels, templates.ChannelPush)
+	}
+	if provider.WebhookProvider.URL != "" {
+		channels = append(channels, templates.ChannelWebhook)
+	}
+	if n.DiscordNotificator.CanSend(&provider.DiscordProvider) {
+		channels = append(channels, templates.ChannelDiscord)
+	}
+	if n.SMSNotificator != nil && provider.PhoneProvider.Phone != "" && !provider.PhoneProvider.Disabled {
+		channels = append(channels, templates.ChannelSMS)
+	}
+	if n.MatrixNotificator != nil && provider.MatrixProvider.RoomID != "" && !provider.MatrixProvider.Disabled {
+		channels = append(channels, templates.ChannelMatrix)
+	}
+	if len(out.webPushSubscriptions) > 0 {
+		channels = append(channels, templates.ChannelWebPush)
+	}
+	if provider.NtfyProvider.TopicURL != "" && !provider.NtfyProvider.Disabled {
+		channels = append(channels, templates.ChannelNtfy)
+	}
+	if n.PushoverNotificator != nil && provider.PushoverProvider.UserKey != "" && !provider.PushoverProvider.Disabled {
+		channels = append(channels, templates.ChannelPushover)
+	}
+	return channels
+}
+
+// prepare sets the details page URL and, if a channel needs it, the language of the stored notification
+func (n *Notificator) prepare(out *outgoing, channels []string) {
+	out.detailsURL = n.detailsURL(out.notification)
+	if slices.Contains(channels, templates.ChannelTelegram) || slices.Contains(channels, templates.ChannelEmail) {
+		out.lang = n.walletLang(out.notification.Wallet)
+	}
+}
+
+// sender returns the function sending the notification through the channel and the sender's name in panic reports
+func (n *Notificator) sender(out *outgoing, channel string) (func() error, string) {
+	notification := out.notification
+	provider := out.provider
+	detailsURL := out.detailsURL
+
+	switch channel {
+	case templates.ChannelTelegram:
+		chatID := provider.TelegramProvider.ChatID
+		text := n.withTokenEmoji(notification, n.renderMessage(out.lang, templates.MessageTelegram, notification, n.shortTxLink(notification), detailsURL))
+		parts := fitWithReference(text, n.telegramLimit, detailsURL, notification.ReferenceTag())
+		return func() error {
+			for _, part := range parts {
+				if err := n.TelegramNotificator.SendNotification(chatID, part); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, "telegramNotification"
+	case templates.ChannelEmail:
+		email := provider.EmailProvider.Email
+		heading := n.renderMessage(out.lang, templates.MessageEmailSubject, notification, notification.TxLink(), detailsURL)
+		subject := referenceSubject(heading, notification)
+		message := n.renderMessage(out.lang, templates.MessageEmail, notification, notification.TxLink(), detailsURL)
+		html := n.renderEmailHTML(out.lang, heading, message, notification, detailsURL)
+		return func() error {
+			return n.EmailNotificator.SendNotification(notification.Wallet, email, subject, message, html)
+		}, "emailNotification"
+	case templates.ChannelPush:
+		tokens := out.pushTokens
+		body := n.withTokenEmoji(notification, notification.Text(n.shortTxLink(notification)))
+		data := pushData(notification, detailsURL)
+		// The notification is delivered if any device of the wallet received it
+		return func() error {
+			var lastErr error
+			delivered := false
+			for _, token := range tokens {
+				if err := n.FCMNotificator.SendNotification(token, pushTitle(notification), body, data); err != nil {
+					lastErr = err
+				} else {
+					delivered = true
+				}
+			}
+			if delivered {
+				return nil
+			}
+			return lastErr
+		}, "pushNotification"
+	case templates.ChannelWebhook:
+		webhook := provider.WebhookProvider
+		return func() error {
+			return n.WebhookNotificator.SendNotification(webhook.URL, webhook.Secret, notification)
+		}, "webhookNotification"
+	case templates.ChannelDiscord:
+		discord := provider.DiscordProvider
+		text := notification.Text(notification.TxLink())
+		return func() error {
+			return n.DiscordNotificator.SendNotification(&discord, notification, text)
+		}, "discordNotification"
+	case templates.ChannelSMS:
+		phone := provider.PhoneProvider
+		text := notification.Text(n.shortTxLink(notification))
+		parts := fitWithReference(text, n.smsLimit, detailsURL, notification.ReferenceTag())
+		return func() error {
+			return n.SMSNotificator.SendNotification(&phone, notification.Wallet, parts)
+		}, "smsNotification"
+	case templates.ChannelMatrix:
+		matrix := provider.MatrixProvider
+		message := notification.Text(notification.TxLink())
+		if tag := notification.ReferenceTag(); tag != "" {
+			message += "\n" + tag
+		}
+		return func() error {
+			return n.MatrixNotificator.SendNotification(&matrix, notification.Wallet, message)
+		}, "matrixNotification"
+	case templates.ChannelWebPush:
+		subscriptions := out.webPushSubscriptions
+		body := n.withTokenEmoji(notification, notification.Text(n.shortTxLink(notification)))
+		data := pushData(notification, detailsURL)
+		// The notification is delivered if any browser of the wallet received it
+		return func() error {
+			var lastErr error
+			delivered := false
+			for _, subscription := range subscriptions {
+				if err := n.WebPushNotificator.SendNotification(subscription, pushTitle(notification), body, data); err != nil {
+					lastErr = err
+				} else {
+					delivered = true
+				}
+			}
+			if delivered {
+				return nil
+			}
+			return lastErr
+		}, "webPushNotification"
+	case templates.ChannelNtfy:
+		ntfy := provider.NtfyProvider
+		message := notification.Text(notification.TxLink())
+		if tag := notification.ReferenceTag(); tag != "" {
+			message += "\n" + tag
+		}
+		click := detailsURL
+		if click == "" {
+			click = notification.TxLink()
+		}
+		return func() error {
+			return n.NtfyNotificator.SendNotification(&ntfy, notification.Wallet, pushTitle(notification), message, click)
+		}, "ntfyNotification"
+	case templates.ChannelPushover:
+		pushover := provider.PushoverProvider
+		limit := MessageLimit{MaxLength: MaxPushoverMessageLength, Overflow: OverflowTruncate}
+		message := fitWithReference(notification.Text(n.shortTxLink(notification)), limit, detailsURL, notification.ReferenceTag())[0]
+		link := detailsURL
+		if link == "" {
+			link = notification.TxLink()
+		}
+		priority := n.PushoverNotificator.Priority(notification)
+		return func() error {
+			return n.PushoverNotificator.SendNotification(&pushover, notification.Wallet, pushTitle(notification), message, link, priority)
+		}, "pushoverNotification"
+	default:
+		return func() error {
+			return fmt.Errorf("unknown channel %q", channel)
+		}, "notification"
+	}
+}
+
+// pushTitle returns the title of a push notification
+func pushTitle(notification *models.Notification) string {
+	switch {
+	case notification.CustomMessage != "":
+		return "Nuntiare"
+	case notification.Pending():
+		return "Incoming payment pending"
+	case len(notification.Transfers) > 1:
+		return fmt.Sprintf("Received %d transfers", len(notification.Transfers))
+	case notification.Internal:
+		return "Internal transfer"
+	case notification.TokenType == "CBC721":
+		return "NFT received"
+	}
+	return fmt.Sprintf("Received %s %s", notification.FormattedAmount(), notification.Currency)
+}
+
+// pushData returns the data payload the wallet app uses to open the notification
+func pushData(notification *models.Notification, detailsURL string) map[string]string {
+	data := map[string]string{"wallet": notification.Wallet}
+	if notification.Reference != "" {
+		data["reference"] = notification.Reference
+	}
+	if notification.EventType != "" {
+		data["event_type"] = notification.EventType
+	}
+	if notification.ID != "" {
+		data["notification_id"] = notification.ID
+	}
+	if notification.TxHash != "" {
+		data["tx_hash"] = notification.TxHash
+		data["url"] = notification.TxLink()
 	}
-	if notificationProvider.EmailProvider.Email != "" {
-		email := notificationProvider.EmailProvider.Email
-		message := notification.String()
-		n.safeCall(func() { n.EmailNotificator.SendNotification(email, message) }, "emailNotification")
+	if detailsURL != "" {
+		data["url"] = detailsURL
 	}
+	return data
 }
 
 /*
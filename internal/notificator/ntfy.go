@@ -0,0 +1,162 @@
+package notificator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// ntfy publishing retry settings
+	MaxNtfyRetries   = 3
+	NtfyRetryBackoff = 2 * time.Second
+	NtfyTimeout      = 10 * time.Second
+)
+
+// ntfyError is an unsuccessful ntfy publish response
+type ntfyError struct {
+	status  int
+	code    int // ntfy error code (40301 topic reserved, 42901 rate limited, ...)
+	message string
+}
+
+func (e *ntfyError) Error() string {
+	return fmt.Sprintf("ntfy error %d %d: %s", e.status, e.code, e.message)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *ntfyError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// forbidden reports whether the server refuses to publish to the topic (reserved or access protected)
+func (e *ntfyError) forbidden() bool {
+	return e.status == http.StatusUnauthorized || e.status == http.StatusForbidden
+}
+
+// NtfyNotificator publishes notifications to ntfy topics on ntfy.sh or self-hosted servers
+type NtfyNotificator struct {
+	logger *logger.Logger
+	db     models.Repository
+	client *http.Client
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewNtfyNotificator creates an ntfy notificator. Unless allowPrivate is set (development),
+// topics on loopback, private and link-local addresses are refused.
+func NewNtfyNotificator(logger *logger.Logger, db models.Repository, allowPrivate bool) *NtfyNotificator {
+	return &NtfyNotificator{
+		logger: logger,
+		db:     db,
+		client: newPublicClient(NtfyTimeout, allowPrivate),
+	}
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (n *NtfyNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	n.monitor = monitor
+}
+
+// SendNotification publishes the message to the provider's topic, retrying rate limits and server errors.
+// Tapping the notification opens the click URL. Providers whose topic is reserved or protected are disabled.
+func (n *NtfyNotificator) SendNotification(provider *models.NtfyProvider, wallet, title, message, click string) error {
+	var lastErr error
+	for attempt := 0; attempt < MaxNtfyRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(NtfyRetryBackoff * time.Duration(attempt))
+			n.logger.Debug("Retrying ntfy publish", "attempt", attempt+1, "wallet", wallet)
+		}
+
+		err := n.publish(provider.TopicURL, title, message, click)
+		if err == nil {
+			n.logger.Debug("Ntfy notification sent successfully", "wallet", wallet, "attempt", attempt+1)
+			n.monitor.Record(templates.ChannelNtfy, nil)
+			return nil
+		}
+		lastErr = err
+		if errors.Is(err, errPrivateAddress) {
+			break
+		}
+
+		var apiErr *ntfyError
+		if errors.As(err, &apiErr) {
+			if apiErr.forbidden() {
+				n.logger.Warn("Ntfy topic not accessible, disabling provider", "wallet", wallet, "code", apiErr.code)
+				if err := n.db.DisableNtfyProvider(provider.ID, apiErr.Error()); err != nil {
+					n.logger.Error("Failed to disable ntfy provider", "error", err)
+				}
+				return apiErr
+			}
+			if !apiErr.retryable() {
+				break
+			}
+		}
+		n.logger.Warn("Failed to send ntfy notification", "wallet", wallet, "attempt", attempt+1, "error", err)
+	}
+
+	n.logger.Error("Failed to send ntfy notification", "wallet", wallet, "error", lastErr)
+	n.monitor.Record(templates.ChannelNtfy, lastErr)
+	return lastErr
+}
+
+// publish sends a single message as JSON to the server root, which keeps non-ASCII titles intact
+func (n *NtfyNotificator) publish(topicURL, title, message, click string) error {
+	parsed, err := url.Parse(topicURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse ntfy topic URL: %w", err)
+	}
+	topic := path.Base(parsed.Path)
+	parsed.Path = strings.TrimSuffix(parsed.Path, topic)
+
+	body := map[string]string{"topic": topic, "title": title, "message": message}
+	if click != "" {
+		body["click"] = click
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ntfy payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), NtfyTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, parsed.String(), bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &ntfyError{status: resp.StatusCode, message: string(respBody)}
+	var errResp struct {
+		Code  int    `json:"code"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Code != 0 {
+		apiErr.code = errResp.Code
+		apiErr.message = errResp.Error
+	}
+	return apiErr
+}
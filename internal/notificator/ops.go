@@ -0,0 +1,234 @@
+package notificator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/go-telegram/bot"
+)
+
+const (
+	// OpsAlertTimeout limits a single ops alert request
+	OpsAlertTimeout = 10 * time.Second
+	// OpsAlertRepeatInterval is how long repeats of an alert that doesn't resolve (e.g. panics of the same
+	// goroutine) are only logged, so a failure loop doesn't flood the ops destinations
+	OpsAlertRepeatInterval = 15 * time.Minute
+	// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+	PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// pagerDutyEvent is a PagerDuty Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes a triggered PagerDuty event
+type pagerDutyPayload struct {
+	Summary       string           `json:"summary"`
+	Source        string           `json:"source"`
+	Severity      string           `json:"severity"`
+	Component     string           `json:"component"`
+	Class         string           `json:"class"`
+	CustomDetails *models.OpsAlert `json:"custom_details"`
+}
+
+// OpsNotifier delivers operator alerts (block lag, delivery failures, lock contention, panics, ...) to the
+// ops Telegram chat, PagerDuty and/or the ops webhook. It is configured separately from the user-facing
+// channels and may use its own Telegram bot.
+type OpsNotifier struct {
+	logger *logger.Logger
+	source string // Instance the alerts come from, shown in PagerDuty
+
+	telegramBot  *bot.Bot             // Dedicated ops bot (nil to send through the user-facing bot)
+	telegram     *TelegramNotificator // User-facing bot, used without a dedicated ops bot
+	chatID       string
+	pagerDutyKey string
+	pagerDutyURL string
+	webhookURL   string
+	webhookToken string
+	client       *http.Client
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time // Last delivery of each unresolved alert by dedup key
+}
+
+// NewOpsNotifier creates an ops notifier. Returns nil when no ops destination is configured.
+func NewOpsNotifier(logger *logger.Logger, cfg *config.Config, telegram *TelegramNotificator) *OpsNotifier {
+	if cfg.OpsTelegramChatID == "" && cfg.OpsPagerDutyRoutingKey == "" && cfg.OpsAlertWebhookURL == "" {
+		return nil
+	}
+
+	source, err := os.Hostname()
+	if err != nil || source == "" {
+		source = "nuntiare"
+	}
+	o := &OpsNotifier{
+		logger:       logger,
+		source:       source,
+		telegram:     telegram,
+		chatID:       cfg.OpsTelegramChatID,
+		pagerDutyKey: cfg.OpsPagerDutyRoutingKey,
+		pagerDutyURL: PagerDutyEventsURL,
+		webhookURL:   cfg.OpsAlertWebhookURL,
+		webhookToken: cfg.OpsAlertWebhookToken,
+		client:       &http.Client{Timeout: OpsAlertTimeout},
+		lastSent:     make(map[string]time.Time),
+	}
+	if cfg.OpsTelegramChatID != "" && cfg.OpsTelegramBotToken != "" {
+		b, err := bot.New(cfg.OpsTelegramBotToken, bot.WithSkipGetMe())
+		if err != nil {
+			logger.Error("Failed to create ops Telegram bot, ops alerts are sent through the notification bot", "error", err)
+		} else {
+			o.telegramBot = b
+		}
+	}
+	return o
+}
+
+// Send delivers an alert to the configured ops destinations. Safe to call on a nil notifier.
+func (o *OpsNotifier) Send(alert *models.OpsAlert) {
+	if o == nil {
+		return
+	}
+	o.send(alert, alert)
+}
+
+// send delivers an alert, posting webhookPayload to the ops webhook
+func (o *OpsNotifier) send(alert *models.OpsAlert, webhookPayload interface{}) {
+	if alert.Severity == "" {
+		alert.Severity = models.OpsSeverityWarning
+	}
+	if !o.shouldSend(alert, time.Now()) {
+		o.logger.Debug("Repeated ops alert not sent", "type", alert.Type, "key", alert.Key)
+		return
+	}
+	o.logger.Warn("Ops alert", "type", alert.Type, "key", alert.Key, "severity", alert.Severity, "resolved", alert.Resolved,
+		"value", alert.Value, "threshold", alert.Threshold)
+
+	if o.chatID != "" {
+		if err := o.sendTelegram(alert.Message); err != nil {
+			o.logger.Error("Failed to send ops alert to Telegram", "error", err)
+		}
+	}
+
+	if o.pagerDutyKey != "" {
+		if err := o.sendPagerDuty(alert); err != nil {
+			o.logger.Error("Failed to send ops alert to PagerDuty", "error", err)
+		}
+	}
+
+	if o.webhookURL != "" {
+		headers := map[string]string{}
+		if o.webhookToken != "" {
+			headers["Authorization"] = "Bearer " + o.webhookToken
+		}
+		if err := o.post(o.webhookURL, headers, webhookPayload); err != nil {
+			o.logger.Error("Failed to send ops alert to webhook", "error", err)
+		}
+	}
+}
+
+// shouldSend reports whether the alert is delivered. Repeats of an unresolved alert within
+// OpsAlertRepeatInterval are dropped; resolving an alert lets the next one through.
+func (o *OpsNotifier) shouldSend(alert *models.OpsAlert, now time.Time) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	key := alert.DedupKey()
+	if alert.Resolved {
+		delete(o.lastSent, key)
+		return true
+	}
+	if last, ok := o.lastSent[key]; ok && now.Sub(last) < OpsAlertRepeatInterval {
+		return false
+	}
+	o.lastSent[key] = now
+	return true
+}
+
+// sendTelegram posts the message to the ops chat with the dedicated ops bot, or the notification bot
+func (o *OpsNotifier) sendTelegram(message string) error {
+	if o.telegramBot == nil {
+		if o.telegram == nil {
+			return errors.New("telegram bot unavailable")
+		}
+		return o.telegram.SendOpsMessage(o.chatID, message)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), OpsAlertTimeout)
+	defer cancel()
+	_, err := o.telegramBot.SendMessage(ctx, &bot.SendMessageParams{ChatID: o.chatID, Text: message})
+	return err
+}
+
+// sendPagerDuty triggers or resolves the PagerDuty incident of the alert, deduplicated by type and key
+func (o *OpsNotifier) sendPagerDuty(alert *models.OpsAlert) error {
+	event := &pagerDutyEvent{
+		RoutingKey:  o.pagerDutyKey,
+		EventAction: "trigger",
+		DedupKey:    "nuntiare:" + alert.DedupKey(),
+	}
+	if alert.Resolved {
+		event.EventAction = "resolve"
+	} else {
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncate(alert.Message, 1024),
+			Source:        o.source,
+			Severity:      alert.Severity,
+			Component:     "nuntiare",
+			Class:         alert.Type,
+			CustomDetails: alert,
+		}
+	}
+	return o.post(o.pagerDutyURL, nil, event)
+}
+
+// post sends the payload as JSON and expects a 2xx response
+func (o *OpsNotifier) post(url string, headers map[string]string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), OpsAlertTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// truncate shortens s to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,206 @@
+package notificator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// Pushover sending retry settings
+	MaxPushoverRetries   = 3
+	PushoverRetryBackoff = 2 * time.Second
+	PushoverTimeout      = 10 * time.Second
+
+	// Pushover message priorities
+	PushoverPriorityNormal    = 0
+	PushoverPriorityHigh      = 1 // Bypasses the user's quiet hours
+	PushoverPriorityEmergency = 2 // Repeats until the user acknowledges it
+
+	// Emergency alerts are repeated every pushoverEmergencyRetry until acknowledged, for at most pushoverEmergencyExpire
+	pushoverEmergencyRetry  = 60 * time.Second
+	pushoverEmergencyExpire = time.Hour
+
+	// Pushover rejects longer messages and titles
+	MaxPushoverMessageLength = 1024
+	maxPushoverTitleLength   = 250
+)
+
+// pushoverMessagesURL is the Pushover message API endpoint
+const pushoverMessagesURL = "https://api.pushover.net/1/messages.json"
+
+// pushoverError is an unsuccessful Pushover API response
+type pushoverError struct {
+	status  int
+	user    string // "invalid" when the user key is not valid
+	message string
+}
+
+func (e *pushoverError) Error() string {
+	return fmt.Sprintf("pushover error %d: %s", e.status, e.message)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *pushoverError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// invalidUser reports whether Pushover rejected the user key (unknown, deleted or disabled user or group)
+func (e *pushoverError) invalidUser() bool {
+	return e.status >= 400 && e.status < 500 && e.user == "invalid"
+}
+
+// PushoverNotificator sends notifications to the Pushover apps of users through the operator's Pushover application.
+// Transfers above the configured amounts are sent with high or emergency priority.
+type PushoverNotificator struct {
+	logger   *logger.Logger
+	db       models.Repository
+	client   *http.Client
+	appToken string
+
+	highPriorityAmounts map[string]float64 // Token symbol -> minimum amount of high priority alerts
+	emergencyAmounts    map[string]float64 // Token symbol -> minimum amount of emergency alerts
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewPushoverNotificator creates a Pushover notificator. Returns nil when no application token is configured.
+func NewPushoverNotificator(logger *logger.Logger, cfg *config.Config, db models.Repository) *PushoverNotificator {
+	if cfg.PushoverAppToken == "" {
+		return nil
+	}
+	return &PushoverNotificator{
+		logger:              logger,
+		db:                  db,
+		client:              &http.Client{Timeout: PushoverTimeout},
+		appToken:            cfg.PushoverAppToken,
+		highPriorityAmounts: cfg.PushoverHighPriorityAmounts,
+		emergencyAmounts:    cfg.PushoverEmergencyAmounts,
+	}
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (p *PushoverNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	p.monitor = monitor
+}
+
+// Priority returns the priority of the notification: the highest priority any of its transfers reaches
+func (p *PushoverNotificator) Priority(notification *models.Notification) int {
+	transfers := notification.Transfers
+	if len(transfers) == 0 {
+		transfers = []models.NotificationTransfer{{Amount: notification.Amount, Currency: notification.Currency}}
+	}
+
+	priority := PushoverPriorityNormal
+	for _, transfer := range transfers {
+		symbol := strings.ToUpper(transfer.Currency)
+		if amount, ok := p.emergencyAmounts[symbol]; ok && transfer.Amount >= amount {
+			return PushoverPriorityEmergency
+		}
+		if amount, ok := p.highPriorityAmounts[symbol]; ok && transfer.Amount >= amount {
+			priority = PushoverPriorityHigh
+		}
+	}
+	return priority
+}
+
+// SendNotification sends the message (at most MaxPushoverMessageLength characters) to the provider's user key,
+// retrying rate limits and server errors. Tapping the notification opens the link.
+// Providers whose user key Pushover rejects are disabled.
+func (p *PushoverNotificator) SendNotification(provider *models.PushoverProvider, wallet, title, message, link string, priority int) error {
+	var lastErr error
+	for attempt := 0; attempt < MaxPushoverRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(PushoverRetryBackoff * time.Duration(attempt))
+			p.logger.Debug("Retrying Pushover send", "attempt", attempt+1, "wallet", wallet)
+		}
+
+		err := p.send(provider.UserKey, title, message, link, priority)
+		if err == nil {
+			p.logger.Debug("Pushover notification sent successfully", "wallet", wallet, "priority", priority, "attempt", attempt+1)
+			p.monitor.Record(templates.ChannelPushover, nil)
+			return nil
+		}
+		lastErr = err
+
+		var apiErr *pushoverError
+		if errors.As(err, &apiErr) {
+			if apiErr.invalidUser() {
+				p.logger.Warn("Pushover user key rejected, disabling provider", "wallet", wallet)
+				if err := p.db.DisablePushoverProvider(provider.ID, apiErr.Error()); err != nil {
+					p.logger.Error("Failed to disable pushover provider", "error", err)
+				}
+				return apiErr
+			}
+			if !apiErr.retryable() {
+				break
+			}
+		}
+		p.logger.Warn("Failed to send Pushover notification", "wallet", wallet, "attempt", attempt+1, "error", err)
+	}
+
+	p.logger.Error("Failed to send Pushover notification", "wallet", wallet, "error", lastErr)
+	p.monitor.Record(templates.ChannelPushover, lastErr)
+	return lastErr
+}
+
+// send posts a single message to the Pushover message API
+func (p *PushoverNotificator) send(userKey, title, message, link string, priority int) error {
+	form := url.Values{}
+	form.Set("token", p.appToken)
+	form.Set("user", userKey)
+	form.Set("title", FitMessage(title, MessageLimit{MaxLength: maxPushoverTitleLength, Overflow: OverflowTruncate}, "")[0])
+	form.Set("message", message)
+	form.Set("priority", strconv.Itoa(priority))
+	if priority == PushoverPriorityEmergency {
+		form.Set("retry", strconv.Itoa(int(pushoverEmergencyRetry.Seconds())))
+		form.Set("expire", strconv.Itoa(int(pushoverEmergencyExpire.Seconds())))
+	}
+	if link != "" {
+		form.Set("url", link)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), PushoverTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pushoverMessagesURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &pushoverError{status: resp.StatusCode, message: string(respBody)}
+	var errResp struct {
+		User   string   `json:"user"`
+		Errors []string `json:"errors"`
+	}
+	if err := json.Unmarshal(respBody, &errResp); err == nil {
+		apiErr.user = errResp.User
+		if len(errResp.Errors) > 0 {
+			apiErr.message = strings.Join(errResp.Errors, "; ")
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,264 @@
+package notificator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// Supported SMS providers
+	SMSProviderTwilio = "twilio"
+
+	// SMS sending retry settings
+	MaxSMSRetries   = 3
+	SMSRetryBackoff = 2 * time.Second
+	SMSTimeout      = 15 * time.Second
+
+	// TwilioAPIBase is the base URL of the Twilio REST API
+	TwilioAPIBase = "https://api.twilio.com/2010-04-01"
+	// smsThrottleWindow is the period the SMS sending limits apply to
+	smsThrottleWindow = time.Hour
+)
+
+// twilioUnreachableCodes are Twilio error codes for numbers that will never receive our messages
+// (invalid number, landline, recipient replied STOP)
+var twilioUnreachableCodes = map[int]bool{21211: true, 21214: true, 21610: true, 21614: true}
+
+// errSMSLimitReached is returned when a notification is dropped because the wallet reached its SMS limit
+var errSMSLimitReached = errors.New("SMS limit reached")
+
+// SMSSender delivers a single text message through a provider API
+type SMSSender interface {
+	Send(to, body string) error
+}
+
+// smsError is an unsuccessful SMS provider API response
+type smsError struct {
+	status  int
+	code    int // Provider specific error code
+	message string
+	// unreachable is set when the provider rejected the recipient number itself
+	unreachable bool
+}
+
+func (e *smsError) Error() string {
+	return fmt.Sprintf("sms error %d %d: %s", e.status, e.code, e.message)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *smsError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// NewSMSSender creates the SMS sender selected by SMS_PROVIDER. Returns nil when SMS is disabled.
+func NewSMSSender(cfg *config.Config) (SMSSender, error) {
+	switch cfg.SMSProvider {
+	case "":
+		return nil, nil
+	case SMSProviderTwilio:
+		return &TwilioSender{
+			client:     &http.Client{Timeout: SMSTimeout},
+			accountSID: cfg.TwilioAccountSID,
+			authToken:  cfg.TwilioAuthToken,
+			from:       cfg.TwilioFromNumber,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported sms provider: %s", cfg.SMSProvider)
+}
+
+// TwilioSender sends text messages through the Twilio Programmable Messaging API
+type TwilioSender struct {
+	client     *http.Client
+	accountSID string
+	authToken  string
+	from       string // Phone number, or messaging service SID (MG...)
+}
+
+func (t *TwilioSender) Send(to, body string) error {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("Body", body)
+	if strings.HasPrefix(t.from, "MG") {
+		form.Set("MessagingServiceSid", t.from)
+	} else {
+		form.Set("From", t.from)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), SMSTimeout)
+	defer cancel()
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", TwilioAPIBase, t.accountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	apiErr := &smsError{status: resp.StatusCode, message: string(respBody)}
+	var errResp struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(respBody, &errResp); err == nil && errResp.Code != 0 {
+		apiErr.code = errResp.Code
+		apiErr.message = errResp.Message
+		apiErr.unreachable = twilioUnreachableCodes[errResp.Code]
+	}
+	return apiErr
+}
+
+// smsThrottle limits the number of messages sent per wallet and in total over a sliding window,
+// so a flood of transfers can't run up the SMS bill
+type smsThrottle struct {
+	mu           sync.Mutex
+	maxPerWallet int
+	maxTotal     int
+	perWallet    map[string][]time.Time
+	total        []time.Time
+}
+
+func newSMSThrottle(maxPerWallet, maxTotal int) *smsThrottle {
+	return &smsThrottle{
+		maxPerWallet: maxPerWallet,
+		maxTotal:     maxTotal,
+		perWallet:    make(map[string][]time.Time),
+	}
+}
+
+// allow reserves count messages for the wallet. Returns false, reserving nothing, if that would exceed a limit.
+func (t *smsThrottle) allow(wallet string, count int, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cutoff := now.Add(-smsThrottleWindow)
+	t.total = pruneBefore(t.total, cutoff)
+	for w, sent := range t.perWallet {
+		if sent = pruneBefore(sent, cutoff); len(sent) == 0 {
+			delete(t.perWallet, w)
+		} else {
+			t.perWallet[w] = sent
+		}
+	}
+
+	if len(t.total)+count > t.maxTotal || len(t.perWallet[wallet])+count > t.maxPerWallet {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		t.total = append(t.total, now)
+		t.perWallet[wallet] = append(t.perWallet[wallet], now)
+	}
+	return true
+}
+
+// pruneBefore drops the leading timestamps older than the cutoff
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// SMSNotificator sends notifications as text messages to wallet phone numbers
+type SMSNotificator struct {
+	logger   *logger.Logger
+	db       models.Repository
+	sender   SMSSender
+	throttle *smsThrottle
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewSMSNotificator creates an SMS notificator sending through the given provider
+func NewSMSNotificator(logger *logger.Logger, sender SMSSender, db models.Repository, maxPerWallet, maxTotal int) *SMSNotificator {
+	return &SMSNotificator{
+		logger:   logger,
+		db:       db,
+		sender:   sender,
+		throttle: newSMSThrottle(maxPerWallet, maxTotal),
+	}
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (s *SMSNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	s.monitor = monitor
+}
+
+// SendNotification sends the message parts to the phone provider's number, retrying transient failures.
+// Notifications over the hourly limits are dropped; numbers the provider can't reach are disabled.
+func (s *SMSNotificator) SendNotification(provider *models.PhoneProvider, wallet string, parts []string) error {
+	if !s.throttle.allow(wallet, len(parts), time.Now()) {
+		s.logger.Warn("SMS limit reached, dropping notification", "wallet", wallet, "parts", len(parts))
+		return errSMSLimitReached
+	}
+
+	for _, part := range parts {
+		if err := s.send(provider, wallet, part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send sends a single message. Returns false if the remaining parts should not be sent.
+func (s *SMSNotificator) send(provider *models.PhoneProvider, wallet, body string) error {
+	var lastErr error
+	for attempt := 0; attempt < MaxSMSRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(SMSRetryBackoff * time.Duration(attempt))
+			s.logger.Debug("Retrying SMS send", "attempt", attempt+1, "wallet", wallet)
+		}
+
+		err := s.sender.Send(provider.Phone, body)
+		if err == nil {
+			s.logger.Debug("SMS notification sent successfully", "wallet", wallet, "attempt", attempt+1)
+			s.monitor.Record(templates.ChannelSMS, nil)
+			return nil
+		}
+		lastErr = err
+
+		var apiErr *smsError
+		if errors.As(err, &apiErr) {
+			// Invalid numbers and opt-outs are not delivery failures
+			if apiErr.unreachable {
+				s.logger.Warn("Phone number unreachable, disabling provider", "wallet", wallet, "code", apiErr.code)
+				if err := s.db.DisablePhoneProvider(provider.ID, apiErr.Error()); err != nil {
+					s.logger.Error("Failed to disable phone provider", "error", err)
+				}
+				return apiErr
+			}
+			if !apiErr.retryable() {
+				break
+			}
+		}
+		s.logger.Warn("Failed to send SMS notification", "wallet", wallet, "attempt", attempt+1, "error", err)
+	}
+
+	s.logger.Error("Failed to send SMS notification", "wallet", wallet, "error", lastErr)
+	s.monitor.Record(templates.ChannelSMS, lastErr)
+	return lastErr
+}
@@ -0,0 +1,272 @@
+package notificator
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSMTPPoolSize is the number of SMTP connections kept open when SMTP_POOL_SIZE is not set
+	DefaultSMTPPoolSize = 4
+	// SMTPPoolIdleTimeout closes pooled connections unused for longer, before the server drops them
+	SMTPPoolIdleTimeout = 30 * time.Second
+	// SMTPPoolMaxMessages is the number of messages sent over a connection before it's replaced,
+	// servers commonly limit the messages per session
+	SMTPPoolMaxMessages = 100
+	// smtpHealthCheckAfter is the idle time after which a pooled connection is checked with NOOP before reuse
+	smtpHealthCheckAfter = 5 * time.Second
+)
+
+// smtpConn is an authenticated SMTP session that can send several messages
+type smtpConn struct {
+	conn       net.Conn
+	client     *smtp.Client
+	pipelining bool // The server supports PIPELINING (RFC 2920)
+	messages   int
+	lastUsed   time.Time
+}
+
+// close ends the session, the connection is closed even if QUIT fails
+func (c *smtpConn) close() {
+	_ = c.conn.SetDeadline(time.Now().Add(smtpHealthCheckAfter))
+	_ = c.client.Quit()
+	_ = c.conn.Close()
+}
+
+// discard closes the connection without ending the session, for sessions left in an unknown state by an error
+func (c *smtpConn) discard() {
+	_ = c.conn.Close()
+}
+
+// smtpPool keeps authenticated SMTP connections open so bursts of emails, e.g. a block with many transfers,
+// don't open a connection per email. At most size connections are open at a time; senders wait up to
+// EmailTimeout for a free one.
+type smtpPool struct {
+	host string // Server name for TLS verification
+	addr string
+	auth smtp.Auth
+
+	idle  chan *smtpConn
+	slots chan struct{}
+}
+
+func newSMTPPool(host, addr string, auth smtp.Auth, size int) *smtpPool {
+	if size <= 0 {
+		size = DefaultSMTPPoolSize
+	}
+	return &smtpPool{
+		host:  host,
+		addr:  addr,
+		auth:  auth,
+		idle:  make(chan *smtpConn, size),
+		slots: make(chan struct{}, size),
+	}
+}
+
+// send delivers the message over a pooled connection. Connections are dropped after any error, so a
+// retry always starts on a fresh or health-checked session.
+func (p *smtpPool) send(from string, to []string, msg []byte) error {
+	timer := time.NewTimer(EmailTimeout)
+	defer timer.Stop()
+	select {
+	case p.slots <- struct{}{}:
+	case <-timer.C:
+		return fmt.Errorf("no SMTP connection available after %s", EmailTimeout)
+	}
+	defer func() { <-p.slots }()
+
+	conn, err := p.get()
+	if err != nil {
+		return err
+	}
+	if err := conn.conn.SetDeadline(time.Now().Add(EmailTimeout)); err != nil {
+		conn.discard()
+		return fmt.Errorf("failed to set connection deadline: %w", err)
+	}
+	if err := conn.send(from, to, msg); err != nil {
+		conn.discard()
+		return err
+	}
+
+	conn.messages++
+	conn.lastUsed = time.Now()
+	if conn.messages >= SMTPPoolMaxMessages {
+		conn.close()
+		return nil
+	}
+	p.idle <- conn
+	return nil
+}
+
+// get returns an idle connection that is still usable, or a new one
+func (p *smtpPool) get() (*smtpConn, error) {
+	for {
+		select {
+		case conn := <-p.idle:
+			idle := time.Since(conn.lastUsed)
+			if idle > SMTPPoolIdleTimeout {
+				conn.close()
+				continue
+			}
+			if idle > smtpHealthCheckAfter {
+				_ = conn.conn.SetDeadline(time.Now().Add(EmailTimeout))
+				if err := conn.client.Noop(); err != nil {
+					conn.discard()
+					continue
+				}
+			}
+			return conn, nil
+		default:
+			return p.dial()
+		}
+	}
+}
+
+// dial opens a new connection, upgrading it to TLS when the server supports STARTTLS, and authenticates
+func (p *smtpPool) dial() (*smtpConn, error) {
+	dialer := &net.Dialer{Timeout: EmailTimeout}
+	conn, err := dialer.Dial("tcp", p.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(EmailTimeout)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set connection deadline: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
+	}
+
+	// Start TLS if the server supports it (STARTTLS for port 587)
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		tlsConfig := &tls.Config{
+			ServerName: p.host,
+			MinVersion: tls.VersionTLS12,
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if p.auth != nil {
+		if err := client.Auth(p.auth); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	pipelining, _ := client.Extension("PIPELINING")
+	return &smtpConn{conn: conn, client: client, pipelining: pipelining, lastUsed: time.Now()}, nil
+}
+
+// send runs one mail transaction. With PIPELINING the MAIL, RCPT and DATA commands are sent together and
+// their replies read afterwards, saving a round trip per command.
+func (c *smtpConn) send(from string, to []string, msg []byte) error {
+	if !c.pipelining {
+		return c.sendSequential(from, to, msg)
+	}
+
+	for _, address := range append([]string{from}, to...) {
+		if strings.ContainsAny(address, "\r\n") {
+			return fmt.Errorf("invalid address %q", address)
+		}
+	}
+
+	text := c.client.Text
+	ids := make([]uint, 0, len(to)+2)
+	id, err := text.Cmd("MAIL FROM:<%s>", from)
+	if err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	ids = append(ids, id)
+	for _, recipient := range to {
+		if id, err = text.Cmd("RCPT TO:<%s>", recipient); err != nil {
+			return fmt.Errorf("failed to set recipient %s: %w", recipient, err)
+		}
+		ids = append(ids, id)
+	}
+	if id, err = text.Cmd("DATA"); err != nil {
+		return fmt.Errorf("failed to open data writer: %w", err)
+	}
+	ids = append(ids, id)
+
+	for i, id := range ids {
+		expectCode := 25
+		switch i {
+		case 0:
+			expectCode = 250
+		case len(ids) - 1:
+			expectCode = 354
+		}
+		text.StartResponse(id)
+		_, _, err := text.ReadResponse(expectCode)
+		text.EndResponse(id)
+		if err != nil {
+			switch i {
+			case 0:
+				return fmt.Errorf("failed to set sender: %w", err)
+			case len(ids) - 1:
+				return fmt.Errorf("failed to open data writer: %w", err)
+			}
+			return fmt.Errorf("failed to set recipient %s: %w", to[i-1], err)
+		}
+	}
+
+	writer := text.DotWriter()
+	if _, err := writer.Write(msg); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close data writer: %w", err)
+	}
+	if _, _, err := text.ReadResponse(250); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	return nil
+}
+
+// sendSequential runs one mail transaction waiting for the reply to each command
+func (c *smtpConn) sendSequential(from string, to []string, msg []byte) error {
+	if err := c.client.Mail(from); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, recipient := range to {
+		if err := c.client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("failed to set recipient %s: %w", recipient, err)
+		}
+	}
+
+	writer, err := c.client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to open data writer: %w", err)
+	}
+	if _, err := writer.Write(msg); err != nil {
+		writer.Close()
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to close data writer: %w", err)
+	}
+	return nil
+}
+
+// close ends all idle sessions
+func (p *smtpPool) close() {
+	for {
+		select {
+		case conn := <-p.idle:
+			conn.close()
+		default:
+			return
+		}
+	}
+}
@@ -2,11 +2,15 @@ package notificator
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/faults"
 	"github.com/core-coin/nuntiare/pkg/logger"
 	"github.com/go-telegram/bot"
 	tgModels "github.com/go-telegram/bot/models"
@@ -17,8 +21,29 @@ const (
 	MaxWebhookRetries  = 5
 	BaseBackoffSeconds = 2
 	MaxBackoffSeconds  = 60
+
+	// Telegram send queue settings
+	MaxSendRetries       = 5
+	ChatQueueSize        = 100
+	ChatQueueIdleTimeout = 1 * time.Minute
+)
+
+var (
+	errTelegramUnavailable = errors.New("telegram bot unavailable")
+	errTelegramQueueFull   = errors.New("telegram chat queue is full")
+	errTelegramStopped     = errors.New("telegram bot stopped before sending")
+	errTelegramRateLimited = errors.New("rate limited by Telegram API")
+	// errTelegramChatUnavailable is returned when the chat was disabled, the message isn't retried
+	errTelegramChatUnavailable = errors.New("telegram chat unavailable")
 )
 
+// telegramMessage is a message in a chat's send queue
+type telegramMessage struct {
+	text string
+	// result receives the outcome of sending the message
+	result chan error
+}
+
 type TelegramNotificator struct {
 	logger      *logger.Logger
 	bot         *bot.Bot
@@ -26,6 +51,19 @@ type TelegramNotificator struct {
 	webhookMode bool
 	ctx         context.Context
 	cancel      context.CancelFunc
+
+	// Per-chat send queues preserve message order and honor Telegram's retry_after
+	queues   map[string]chan telegramMessage
+	queuesMu sync.Mutex
+	wg       sync.WaitGroup
+
+	// onChatUnavailable is called after a chat was disabled so fallback channels can be notified
+	onChatUnavailable func(chatID, reason, message string, notificationProviderIDs []int64)
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+	// faults injects Telegram API failures in development (nil = disabled)
+	faults *faults.Injector
 }
 
 func NewTelegramNotificator(logger *logger.Logger, token string, db models.Repository, webhookMode bool) *TelegramNotificator {
@@ -36,6 +74,7 @@ func NewTelegramNotificator(logger *logger.Logger, token string, db models.Repos
 		webhookMode: webhookMode,
 		ctx:         ctx,
 		cancel:      cancel,
+		queues:      make(map[string]chan telegramMessage),
 	}
 
 	// If no token provided, return provider with nil bot (disabled)
@@ -54,32 +93,165 @@ func NewTelegramNotificator(logger *logger.Logger, token string, db models.Repos
 		return provider
 	}
 
+	provider.bot = b
+
 	// Only start polling if not in webhook mode
 	if !webhookMode {
-		go b.Start(ctx)
+		provider.StartPolling()
 		logger.Info("Telegram bot initialized successfully (polling mode)")
 	} else {
 		logger.Info("Telegram bot initialized successfully (webhook mode)")
 	}
 
-	provider.bot = b
 	return provider
 }
 
-func (t *TelegramNotificator) SendNotification(chatId, message string) {
+// StartPolling removes any configured webhook and starts receiving updates via long polling.
+// Also used as a fallback when the webhook can't be configured.
+func (t *TelegramNotificator) StartPolling() {
 	if t.bot == nil {
-		t.logger.Warn("Telegram bot unavailable, skipping notification")
 		return
 	}
 
+	// Telegram rejects getUpdates while a webhook is set
+	if _, err := t.bot.DeleteWebhook(t.ctx, &bot.DeleteWebhookParams{}); err != nil {
+		t.logger.Warn("Failed to delete Telegram webhook before polling", "error", err)
+	}
+
+	go t.bot.Start(t.ctx)
+}
+
+// SendNotification queues the message for the chat and waits until the chat's queue worker sent it.
+// Returns the reason the message couldn't be queued or sent.
+func (t *TelegramNotificator) SendNotification(chatId, message string) error {
+	if t.bot == nil {
+		t.logger.Warn("Telegram bot unavailable, skipping notification")
+		return errTelegramUnavailable
+	}
+
+	result, err := t.enqueue(chatId, message)
+	if err != nil {
+		return err
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-t.ctx.Done():
+		return errTelegramStopped
+	}
+}
+
+// enqueue adds a message to the chat's send queue, starting a queue worker if needed. Returns the channel
+// receiving the outcome of sending it.
+func (t *TelegramNotificator) enqueue(chatID, message string) (chan error, error) {
+	t.queuesMu.Lock()
+	defer t.queuesMu.Unlock()
+
+	queue, ok := t.queues[chatID]
+	if !ok {
+		queue = make(chan telegramMessage, ChatQueueSize)
+		t.queues[chatID] = queue
+		t.wg.Add(1)
+		go t.processQueue(chatID, queue)
+	}
+
+	result := make(chan error, 1)
+	select {
+	case queue <- telegramMessage{text: message, result: result}:
+		return result, nil
+	default:
+		t.logger.Error("Telegram chat queue is full, dropping message", "chat_id", chatID)
+		return nil, errTelegramQueueFull
+	}
+}
+
+// processQueue sends queued messages for a chat one by one and exits once the queue is idle
+func (t *TelegramNotificator) processQueue(chatID string, queue chan telegramMessage) {
+	defer t.wg.Done()
+
+	for {
+		select {
+		case message := <-queue:
+			message.result <- t.send(chatID, message.text)
+		case <-time.After(ChatQueueIdleTimeout):
+			t.queuesMu.Lock()
+			// A message may have been enqueued right before we took the lock
+			if len(queue) > 0 {
+				t.queuesMu.Unlock()
+				continue
+			}
+			delete(t.queues, chatID)
+			t.queuesMu.Unlock()
+			return
+		case <-t.ctx.Done():
+			if len(queue) > 0 {
+				t.logger.Warn("Telegram bot stopped with pending messages", "chat_id", chatID, "pending", len(queue))
+			}
+			return
+		}
+	}
+}
+
+// send delivers a single message, waiting and retrying when Telegram responds with 429
+func (t *TelegramNotificator) send(chatID, message string) error {
 	params := &bot.SendMessageParams{
-		ChatID: chatId,
+		ChatID: chatID,
 		Text:   message,
 	}
-	_, err := t.bot.SendMessage(context.Background(), params)
-	if err != nil {
-		t.logger.Error("Failed to send notification: ", err)
+
+	for attempt := 0; attempt < MaxSendRetries; attempt++ {
+		err := t.faults.Inject(faults.Telegram)
+		if err == nil {
+			_, err = t.bot.SendMessage(t.ctx, params)
+		}
+		if err == nil {
+			t.monitor.Record(templates.ChannelTelegram, nil)
+			return nil
+		}
+
+		// Blocked bots and deleted chats are user decisions, not delivery failures
+		if reason, unavailable := chatUnavailableReason(err); unavailable {
+			t.disableChat(chatID, reason, message)
+			return fmt.Errorf("%w: %s", errTelegramChatUnavailable, reason)
+		}
+
+		// The group was upgraded to a supergroup, store the new chat ID and resend there
+		var migrateErr *bot.MigrateError
+		if errors.As(err, &migrateErr) {
+			newChatID := fmt.Sprint(migrateErr.MigrateToChatID)
+			t.migrateChat(chatID, newChatID)
+			chatID = newChatID
+			params.ChatID = newChatID
+			continue
+		}
+
+		var rateLimitErr *bot.TooManyRequestsError
+		if !errors.As(err, &rateLimitErr) {
+			t.logger.Error("Failed to send notification", "chat_id", chatID, "error", err)
+			t.monitor.Record(templates.ChannelTelegram, err)
+			return err
+		}
+
+		retryAfter := time.Duration(rateLimitErr.RetryAfter) * time.Second
+		if retryAfter <= 0 {
+			retryAfter = time.Second
+		}
+		t.logger.Warn("Rate limited by Telegram API, delaying send",
+			"chat_id", chatID,
+			"attempt", attempt+1,
+			"retry_after", retryAfter)
+
+		select {
+		case <-time.After(retryAfter):
+		case <-t.ctx.Done():
+			t.logger.Warn("Telegram bot stopped while waiting to retry send", "chat_id", chatID)
+			return errTelegramStopped
+		}
 	}
+
+	t.logger.Error("Failed to send notification after retries due to rate limiting", "chat_id", chatID, "attempts", MaxSendRetries)
+	t.monitor.Record(templates.ChannelTelegram, errTelegramRateLimited)
+	return errTelegramRateLimited
 }
 
 func (t *TelegramNotificator) handler(ctx context.Context, b *bot.Bot, update *tgModels.Update) {
@@ -87,12 +259,17 @@ func (t *TelegramNotificator) handler(ctx context.Context, b *bot.Bot, update *t
 		t.logger.Debug("Telegram update without message payload received")
 		return
 	}
-	t.logger.Debug("Telegram update: ", update.Message.From.Username, " ", update.Message.Text)
+	// Service message sent to the old group when it is upgraded to a supergroup
+	if update.Message.MigrateToChatID != 0 {
+		t.migrateChat(fmt.Sprint(update.Message.Chat.ID), fmt.Sprint(update.Message.MigrateToChatID))
+		return
+	}
 	user := update.Message.From
 	if user == nil {
 		t.logger.Error("User is nil")
 		return
 	}
+	t.logger.Debug("Telegram update: ", user.Username, " ", update.Message.Text)
 	if update.Message.Text == "/start" {
 		providers, err := t.db.GetNotificationProvidersByTelegramUsername(user.Username)
 		if err != nil {
@@ -110,9 +287,16 @@ func (t *TelegramNotificator) handler(ctx context.Context, b *bot.Bot, update *t
 			return
 		}
 		t.logger.Info("Telegram provider chat ID added successfully")
+		lang := normalizeLanguageCode(user.LanguageCode)
 		addresses := make([]string, 0, len(providers))
 		for _, provider := range providers {
 			addresses = append(addresses, provider.Address)
+			// Initialize wallet language from the Telegram client if it wasn't set at registration
+			if lang != "" {
+				if err := t.db.SetWalletLangIfEmpty(provider.Address, lang); err != nil {
+					t.logger.Error("Failed to set wallet language from Telegram", "error", err, "address", provider.Address)
+				}
+			}
 		}
 		message := "You have successfully subscribed to notifications."
 		if len(addresses) > 0 {
@@ -122,8 +306,91 @@ func (t *TelegramNotificator) handler(ctx context.Context, b *bot.Bot, update *t
 	}
 }
 
-// SetWebhook configures the Telegram webhook URL
-func (t *TelegramNotificator) SetWebhook(webhookURL string) error {
+// chatUnavailableReason reports whether a send error means the chat can no longer receive messages
+// (bot blocked by the user, user deactivated, chat deleted) and returns a short reason
+func chatUnavailableReason(err error) (string, bool) {
+	errMsg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, bot.ErrorForbidden) && strings.Contains(errMsg, "bot was blocked by the user"):
+		return "bot was blocked by the user", true
+	case errors.Is(err, bot.ErrorForbidden) && strings.Contains(errMsg, "user is deactivated"):
+		return "user is deactivated", true
+	case errors.Is(err, bot.ErrorForbidden) && strings.Contains(errMsg, "bot was kicked"):
+		return "bot was removed from the chat", true
+	case errors.Is(err, bot.ErrorBadRequest) && strings.Contains(errMsg, "chat not found"):
+		return "chat not found", true
+	}
+	return "", false
+}
+
+// disableChat marks the chat's telegram providers as disabled and notifies fallback channels of the wallets
+// it disabled. Failed sends queued to the chat before it was disabled don't notify them again.
+func (t *TelegramNotificator) disableChat(chatID, reason, message string) {
+	disabled, err := t.db.DisableTelegramProvider(chatID, reason)
+	if err != nil {
+		t.logger.Error("Failed to disable telegram provider", "error", err, "chat_id", chatID)
+		return
+	}
+	if len(disabled) == 0 {
+		t.logger.Debug("Telegram chat already disabled", "chat_id", chatID)
+		return
+	}
+	t.logger.Warn("Telegram chat unavailable, disabled provider", "chat_id", chatID, "reason", reason, "wallets", len(disabled))
+
+	if t.onChatUnavailable != nil {
+		t.onChatUnavailable(chatID, reason, message, disabled)
+	}
+}
+
+// migrateChat updates the stored chat ID after Telegram migrated a group to a supergroup
+func (t *TelegramNotificator) migrateChat(oldChatID, newChatID string) {
+	t.logger.Info("Telegram chat migrated", "old_chat_id", oldChatID, "new_chat_id", newChatID)
+
+	if err := t.db.UpdateTelegramChatID(oldChatID, newChatID); err != nil {
+		t.logger.Error("Failed to update migrated telegram chat ID", "error", err, "old_chat_id", oldChatID, "new_chat_id", newChatID)
+	}
+}
+
+// SetChatUnavailableHandler sets the callback invoked after a chat was disabled with the IDs of the
+// notification providers it was disabled for
+func (t *TelegramNotificator) SetChatUnavailableHandler(handler func(chatID, reason, message string, notificationProviderIDs []int64)) {
+	t.onChatUnavailable = handler
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (t *TelegramNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	t.monitor = monitor
+}
+
+// SetFaultInjector enables injected Telegram API failures when sending notifications (development only)
+func (t *TelegramNotificator) SetFaultInjector(injector *faults.Injector) {
+	t.faults = injector
+}
+
+// SendOpsMessage sends a message to an operator chat directly, bypassing the send queue
+// and the delivery monitor
+func (t *TelegramNotificator) SendOpsMessage(chatID, message string) error {
+	if t.bot == nil {
+		return errors.New("telegram bot unavailable")
+	}
+	ctx, cancel := context.WithTimeout(t.ctx, OpsAlertTimeout)
+	defer cancel()
+	_, err := t.bot.SendMessage(ctx, &bot.SendMessageParams{ChatID: chatID, Text: message})
+	return err
+}
+
+// normalizeLanguageCode converts an IETF language tag (e.g. "en-US") to its primary language subtag ("en")
+func normalizeLanguageCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	return code
+}
+
+// SetWebhook configures the Telegram webhook URL.
+// If secretToken is set, Telegram sends it in the X-Telegram-Bot-Api-Secret-Token header of every update.
+func (t *TelegramNotificator) SetWebhook(webhookURL, secretToken string) error {
 	if t.bot == nil {
 		return fmt.Errorf("telegram bot not initialized")
 	}
@@ -132,7 +399,8 @@ func (t *TelegramNotificator) SetWebhook(webhookURL string) error {
 
 	for attempt := 0; attempt < MaxWebhookRetries; attempt++ {
 		_, err := t.bot.SetWebhook(ctx, &bot.SetWebhookParams{
-			URL: webhookURL,
+			URL:         webhookURL,
+			SecretToken: secretToken,
 		})
 		if err == nil {
 			t.logger.Info("Telegram webhook configured successfully", "url", webhookURL)
@@ -196,10 +464,11 @@ func (t *TelegramNotificator) ProcessUpdate(update *tgModels.Update) error {
 	return nil
 }
 
-// Stop gracefully stops the Telegram bot (for polling mode)
+// Stop gracefully stops the Telegram bot (for polling mode) and its send queues
 func (t *TelegramNotificator) Stop() {
 	if t.cancel != nil {
 		t.logger.Info("Stopping Telegram bot")
 		t.cancel()
 	}
+	t.wg.Wait()
 }
@@ -0,0 +1,307 @@
+package notificator
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// Web Push sending retry settings
+	MaxWebPushRetries   = 3
+	WebPushRetryBackoff = 2 * time.Second
+	WebPushTimeout      = 15 * time.Second
+
+	// WebPushTTL is how long push services keep a message for a browser that is offline
+	WebPushTTL = 24 * time.Hour
+	// webPushJWTExpiry is the lifetime of the VAPID token, push services reject tokens valid for more than 24 hours
+	webPushJWTExpiry = 12 * time.Hour
+	// webPushRecordSize is the aes128gcm record size. The whole payload is sent as a single record.
+	webPushRecordSize = 4096
+	// maxWebPushPayload is the largest plaintext push services accept: their 4096 byte body limit
+	// minus the 86 byte header, the 16 byte tag and the padding delimiter
+	maxWebPushPayload = 4096 - 86 - 16 - 1
+)
+
+// webPushError is an unsuccessful push service response
+type webPushError struct {
+	status  int
+	message string
+}
+
+func (e *webPushError) Error() string {
+	return fmt.Sprintf("web push error %d: %s", e.status, e.message)
+}
+
+// retryable reports whether the request may succeed when sent again
+func (e *webPushError) retryable() bool {
+	return e.status == http.StatusTooManyRequests || e.status >= 500
+}
+
+// expired reports whether the push service dropped the subscription (browser unsubscribed or reinstalled)
+func (e *webPushError) expired() bool {
+	return e.status == http.StatusNotFound || e.status == http.StatusGone
+}
+
+// WebPushNotificator delivers notifications to browsers through the Web Push protocol (RFC 8030).
+// Payloads are encrypted for the browser (RFC 8291) and requests are signed with the VAPID key (RFC 8292).
+type WebPushNotificator struct {
+	logger *logger.Logger
+	db     models.Repository
+	client *http.Client
+
+	privateKey *ecdsa.PrivateKey
+	publicKey  string // Base64url encoded public key sent with every request
+	subject    string
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewWebPushNotificator creates a Web Push notificator from the VAPID keys.
+// Returns nil when no VAPID keys are configured. Unless in development, endpoints on loopback, private and
+// link-local addresses are refused.
+func NewWebPushNotificator(logger *logger.Logger, cfg *config.Config, db models.Repository) (*WebPushNotificator, error) {
+	if cfg.VAPIDPrivateKey == "" {
+		return nil, nil
+	}
+
+	raw, err := decodeWebPushKey(cfg.VAPIDPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse VAPID private key: %w", err)
+	}
+	public := key.PublicKey().Bytes()
+
+	return &WebPushNotificator{
+		logger: logger,
+		db:     db,
+		client: newPublicClient(WebPushTimeout, cfg.Development),
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		subject:   cfg.VAPIDSubject,
+	}, nil
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (w *WebPushNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	w.monitor = monitor
+}
+
+// SendNotification delivers the message to the browser subscription, retrying transient failures.
+// Subscriptions the push service no longer knows are removed.
+func (w *WebPushNotificator) SendNotification(subscription *models.WebPushSubscription, title, body string, data map[string]string) error {
+	payload, err := json.Marshal(map[string]interface{}{"title": title, "body": body, "data": data})
+	if err != nil {
+		w.logger.Error("Failed to marshal web push payload", "error", err, "wallet", subscription.WalletAddress)
+		return err
+	}
+	if len(payload) > maxWebPushPayload {
+		w.logger.Error("Web push payload too large", "wallet", subscription.WalletAddress, "size", len(payload))
+		return fmt.Errorf("web push payload of %d bytes too large", len(payload))
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < MaxWebPushRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(WebPushRetryBackoff * time.Duration(attempt))
+			w.logger.Debug("Retrying web push send", "attempt", attempt+1, "wallet", subscription.WalletAddress)
+		}
+
+		err := w.send(subscription, payload)
+		if err == nil {
+			w.logger.Debug("Web push notification sent successfully", "wallet", subscription.WalletAddress, "attempt", attempt+1)
+			w.monitor.Record(templates.ChannelWebPush, nil)
+			return nil
+		}
+		lastErr = err
+		if errors.Is(err, errPrivateAddress) {
+			break
+		}
+
+		var apiErr *webPushError
+		if errors.As(err, &apiErr) {
+			// Unsubscribed browsers are user decisions, not delivery failures
+			if apiErr.expired() {
+				w.logger.Info("Web push subscription expired, removing it", "wallet", subscription.WalletAddress, "status", apiErr.status)
+				if _, err := w.db.RemoveWebPushSubscription(subscription.WalletAddress, subscription.Endpoint); err != nil {
+					w.logger.Error("Failed to remove web push subscription", "error", err)
+				}
+				return apiErr
+			}
+			if !apiErr.retryable() {
+				break
+			}
+		}
+		w.logger.Warn("Failed to send web push notification", "wallet", subscription.WalletAddress, "attempt", attempt+1, "error", err)
+	}
+
+	w.logger.Error("Failed to send web push notification", "wallet", subscription.WalletAddress, "error", lastErr)
+	w.monitor.Record(templates.ChannelWebPush, lastErr)
+	return lastErr
+}
+
+// send encrypts the payload for the subscription and posts it to the push service
+func (w *WebPushNotificator) send(subscription *models.WebPushSubscription, payload []byte) error {
+	body, err := encryptWebPushPayload(subscription, payload)
+	if err != nil {
+		return err
+	}
+	endpoint, err := url.Parse(subscription.Endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to parse web push endpoint: %w", err)
+	}
+	token, err := w.signJWT(endpoint.Scheme+"://"+endpoint.Host, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, subscription.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create web push request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, w.publicKey))
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(WebPushTTL.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &webPushError{status: resp.StatusCode, message: string(message)}
+}
+
+// signJWT creates the ES256-signed VAPID token for the push service origin
+func (w *WebPushNotificator) signJWT(audience string, now time.Time) (string, error) {
+	header, _ := json.Marshal(map[string]string{"typ": "JWT", "alg": "ES256"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"aud": audience,
+		"exp": now.Add(webPushJWTExpiry).Unix(),
+		"sub": w.subject,
+	})
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	r, s, err := ecdsa.Sign(rand.Reader, w.privateKey, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	// JWS uses the fixed size r || s encoding instead of ASN.1
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	s.FillBytes(signature[32:])
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// encryptWebPushPayload encrypts the payload for the browser as a single aes128gcm record (RFC 8291)
+func encryptWebPushPayload(subscription *models.WebPushSubscription, payload []byte) ([]byte, error) {
+	p256dh, err := decodeWebPushKey(subscription.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode p256dh: %w", err)
+	}
+	authSecret, err := decodeWebPushKey(subscription.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode auth secret: %w", err)
+	}
+	browserKey, err := ecdh.P256().NewPublicKey(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse p256dh: %w", err)
+	}
+
+	// Every message is encrypted with a fresh key pair and salt
+	localKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate web push key: %w", err)
+	}
+	sharedSecret, err := localKey.ECDH(browserKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive web push secret: %w", err)
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate web push salt: %w", err)
+	}
+	localPublic := localKey.PublicKey().Bytes()
+
+	keyInfo := append([]byte("WebPush: info\x00"), p256dh...)
+	keyInfo = append(keyInfo, localPublic...)
+	ikm := hkdf(authSecret, sharedSecret, keyInfo, 32)
+	contentKey := hkdf(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	nonce := hkdf(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+
+	block, err := aes.NewCipher(contentKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web push cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create web push cipher: %w", err)
+	}
+
+	// Header: salt || record size || key id length || key id (the sender public key)
+	header := make([]byte, 0, 16+4+1+len(localPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(localPublic)))
+	header = append(header, localPublic...)
+
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// hkdf derives length (at most 32) bytes of key material with HKDF-SHA-256 (RFC 5869)
+func hkdf(salt, secret, info []byte, length int) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write(info)
+	expand.Write([]byte{0x01})
+	return expand.Sum(nil)[:length]
+}
+
+// decodeWebPushKey decodes a base64url key, with or without padding
+func decodeWebPushKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
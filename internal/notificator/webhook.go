@@ -0,0 +1,277 @@
+package notificator
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+const (
+	// Webhook delivery retry settings, the backoff doubles after every attempt
+	MaxWebhookDeliveryRetries = 5
+	WebhookRetryBackoff       = 2 * time.Second
+	WebhookTimeout            = 10 * time.Second
+
+	// Headers sent with every webhook delivery
+	WebhookSignatureHeader = "X-Nuntiare-Signature" // "sha256=" + hex HMAC-SHA256 of "<timestamp>.<body>"
+	WebhookTimestampHeader = "X-Nuntiare-Timestamp" // Unix timestamp of the attempt
+	WebhookDeliveryHeader  = "X-Nuntiare-Delivery"  // Event ID, the same for all retries and replays
+	WebhookEventHeader     = "X-Nuntiare-Event"     // Event type
+	WebhookReplayHeader    = "X-Nuntiare-Replay"    // "true" when the event is replayed on request
+)
+
+// WebhookEventNotification is the type of events of notifications without an event type (e.g. custom messages)
+const WebhookEventNotification = "notification"
+
+// errPrivateAddress is returned when a wallet-provided URL resolves to a non-public address
+var errPrivateAddress = errors.New("address is not public")
+
+// WebhookNotificator POSTs notifications as signed JSON to wallet-provided URLs
+type WebhookNotificator struct {
+	logger *logger.Logger
+	db     models.Repository
+	client *http.Client
+
+	// monitor tracks the delivery failure rate (nil when ops alerts are disabled)
+	monitor *DeliveryMonitor
+}
+
+// NewWebhookNotificator creates a webhook notificator. Unless allowPrivate is set (development),
+// requests to loopback, private and link-local addresses are refused.
+func NewWebhookNotificator(logger *logger.Logger, db models.Repository, allowPrivate bool) *WebhookNotificator {
+	return &WebhookNotificator{
+		logger: logger,
+		db:     db,
+		client: newPublicClient(WebhookTimeout, allowPrivate),
+	}
+}
+
+// newPublicClient creates a client for wallet-provided URLs. Unless allowPrivate is set (development),
+// requests to loopback, private and link-local addresses fail with errPrivateAddress. Redirects are not followed.
+func newPublicClient(timeout time.Duration, allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivate {
+		// Checked on the resolved address so DNS names pointing to internal hosts are refused too
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Redirects could point to internal addresses and would drop the signature semantics
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+}
+
+// SetDeliveryMonitor sets the monitor that tracks the delivery failure rate
+func (w *WebhookNotificator) SetDeliveryMonitor(monitor *DeliveryMonitor) {
+	w.monitor = monitor
+}
+
+// SignWebhookPayload returns the signature header value of a payload sent at the timestamp
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SendNotification POSTs the notification to the URL as a signed event, retried and stored for replays (see sendEvent)
+func (w *WebhookNotificator) SendNotification(url, secret string, notification *models.Notification) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		w.logger.Error("Failed to marshal webhook payload", "error", err, "wallet", notification.Wallet)
+		return err
+	}
+	event := &models.WebhookEvent{
+		ID:        newNotificationID(),
+		Type:      notification.EventType,
+		Wallet:    notification.Wallet,
+		Data:      string(data),
+		CreatedAt: time.Now().Unix(),
+	}
+	if event.Type == "" {
+		event.Type = WebhookEventNotification
+	}
+
+	if _, err := w.sendEvent(url, secret, event, notification.ID); err != nil {
+		w.logger.Error("Failed to deliver webhook notification", "wallet", notification.Wallet, "event", event.ID, "error", err)
+		w.monitor.Record(templates.ChannelWebhook, err)
+		return err
+	}
+	w.monitor.Record(templates.ChannelWebhook, nil)
+	return nil
+}
+
+// sendEvent POSTs a new event to the URL, retrying network errors, 429 and 5xx responses with exponential
+// backoff. The event is stored for replays and every attempt is recorded in the webhook delivery log.
+// Returns the status code of the last attempt.
+func (w *WebhookNotificator) sendEvent(url, secret string, event *models.WebhookEvent, notificationID string) (int, error) {
+	body, err := event.Envelope()
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal webhook envelope: %w", err)
+	}
+	// The event is delivered even if it can't be stored, it just can't be replayed
+	stored := true
+	if err := w.db.AddWebhookEvent(event); err != nil {
+		w.logger.Error("Failed to store webhook event", "error", err, "wallet", event.Wallet)
+		stored = false
+	}
+
+	var statusCode int
+	var lastErr error
+	for attempt := 1; attempt <= MaxWebhookDeliveryRetries; attempt++ {
+		if attempt > 1 {
+			time.Sleep(WebhookRetryBackoff << (attempt - 2))
+			w.logger.Debug("Retrying webhook delivery", "attempt", attempt, "wallet", event.Wallet)
+		}
+
+		var retry bool
+		statusCode, retry, err = w.deliver(url, secret, event, notificationID, body, event.Attempts+1, false)
+		event.Attempts++
+		event.Delivered = err == nil
+		event.LastAttemptAt = time.Now().Unix()
+		if err == nil {
+			w.logger.Debug("Webhook event delivered", "wallet", event.Wallet, "event", event.ID, "attempt", attempt)
+			break
+		}
+		lastErr = err
+		w.logger.Warn("Failed to deliver webhook", "wallet", event.Wallet, "event", event.ID, "attempt", attempt, "status", statusCode, "error", err)
+		if !retry {
+			break
+		}
+	}
+	if stored {
+		if err := w.db.UpdateWebhookEvent(event); err != nil {
+			w.logger.Error("Failed to update webhook event", "error", err, "event", event.ID)
+		}
+	}
+	if event.Delivered {
+		return statusCode, nil
+	}
+	return statusCode, lastErr
+}
+
+// Replay sends a stored event to the URL once more, with its original ID and timestamp, without retries
+func (w *WebhookNotificator) Replay(url, secret string, event *models.WebhookEvent) *models.WebhookReplay {
+	replay := &models.WebhookReplay{Event: event}
+	statusCode, err := w.sendStored(url, secret, event, true)
+	replay.StatusCode = statusCode
+	if err != nil {
+		replay.Error = err.Error()
+	}
+	return replay
+}
+
+// Send delivers a stored event to the URL once, without retries. Retries of the caller use the same event,
+// so the receiver can deduplicate them by its ID.
+func (w *WebhookNotificator) Send(url, secret string, event *models.WebhookEvent) error {
+	_, err := w.sendStored(url, secret, event, false)
+	return err
+}
+
+// sendStored sends a single attempt of a stored event and updates its delivery state
+func (w *WebhookNotificator) sendStored(url, secret string, event *models.WebhookEvent, replay bool) (int, error) {
+	body, err := event.Envelope()
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal webhook envelope: %w", err)
+	}
+	var notification struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal([]byte(event.Data), &notification)
+
+	statusCode, _, err := w.deliver(url, secret, event, notification.ID, body, event.Attempts+1, replay)
+	event.Attempts++
+	event.LastAttemptAt = time.Now().Unix()
+	if err == nil {
+		event.Delivered = true
+	}
+	if err := w.db.UpdateWebhookEvent(event); err != nil {
+		w.logger.Error("Failed to update webhook event", "error", err, "event", event.ID)
+	}
+	return statusCode, err
+}
+
+// deliver sends a single attempt and logs it. Returns the response status code and whether a failure may be retried.
+func (w *WebhookNotificator) deliver(url, secret string, event *models.WebhookEvent, notificationID string, body []byte, attempt int, replay bool) (int, bool, error) {
+	start := time.Now()
+	delivery := &models.WebhookDelivery{
+		DeliveryID:     event.ID,
+		Wallet:         event.Wallet,
+		NotificationID: notificationID,
+		URL:            url,
+		Attempt:        attempt,
+		CreatedAt:      start.Unix(),
+	}
+	defer func() {
+		delivery.DurationMs = time.Since(start).Milliseconds()
+		if err := w.db.AddWebhookDelivery(delivery); err != nil {
+			w.logger.Error("Failed to log webhook delivery", "error", err, "wallet", event.Wallet)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), WebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Nuntiare-Webhook")
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(start.Unix(), 10))
+	req.Header.Set(WebhookDeliveryHeader, event.ID)
+	req.Header.Set(WebhookEventHeader, event.Type)
+	if replay {
+		req.Header.Set(WebhookReplayHeader, "true")
+	}
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(secret, start.Unix(), body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		delivery.Error = err.Error()
+		return 0, !errors.Is(err, errPrivateAddress), fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Success = true
+		return resp.StatusCode, false, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	delivery.Error = string(respBody)
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return resp.StatusCode, retry, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+}
@@ -0,0 +1,95 @@
+package nuntiare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/templates"
+	"github.com/core-coin/nuntiare/pkg/version"
+)
+
+const (
+	// UpgradeNotificationInterval is how often wallets using an outdated app are looked up and notified
+	UpgradeNotificationInterval = 1 * time.Hour
+	// upgradeNotificationLock ensures a single instance notifies the wallets using an outdated app
+	upgradeNotificationLock = "upgrade_notifications"
+	// UpgradeNotificationLockTTL is the upgrade notification lock TTL in seconds
+	UpgradeNotificationLockTTL = 300
+)
+
+// CheckAppVersion returns ErrUpgradeRequired if the app version is below the minimum configured for the OS.
+// Clients that don't report their OS or app version are allowed.
+func (n *Nuntiare) CheckAppVersion(os, appVersion string) error {
+	minVersion, ok := n.config.MinAppVersions[strings.ToLower(os)]
+	if !ok || minVersion == "" || appVersion == "" {
+		return nil
+	}
+	if version.Compare(appVersion, minVersion) < 0 {
+		return fmt.Errorf("%w: minimum app version for %s is %s", models.ErrUpgradeRequired, os, minVersion)
+	}
+	return nil
+}
+
+// UpdateWalletMetadata updates the OS, language and app version of a wallet (empty values are kept)
+func (n *Nuntiare) UpdateWalletMetadata(address, os, lang, appVersion string) error {
+	return n.repo.UpdateWalletMetadata(address, os, lang, appVersion)
+}
+
+// notifyOutdatedApps sends a one-time upgrade notification, in the wallet's language, to wallets whose
+// app version is below the minimum configured for their OS
+func (n *Nuntiare) notifyOutdatedApps() {
+	acquired, err := n.tryAcquireLock(upgradeNotificationLock, UpgradeNotificationLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for upgrade notifications", "error", err)
+		return
+	}
+	if !acquired {
+		// Another instance is notifying
+		return
+	}
+	acquiredAt := time.Now()
+	defer func() {
+		n.checkLockOverrun(upgradeNotificationLock, acquiredAt, UpgradeNotificationLockTTL)
+		if err := n.repo.ReleaseLock(upgradeNotificationLock, n.instanceID); err != nil {
+			n.logger.Error("Failed to release upgrade notification lock", "error", err)
+		}
+	}()
+
+	for os, minVersion := range n.config.MinAppVersions {
+		wallets, err := n.repo.GetWalletsForUpgradeNotice(os, minVersion)
+		if err != nil {
+			n.logger.Error("Failed to get wallets for upgrade notice", "os", os, "error", err)
+			continue
+		}
+
+		for _, wallet := range wallets {
+			if version.Compare(wallet.AppVersion, minVersion) >= 0 {
+				continue
+			}
+			message, err := n.notificator.RenderNotice(wallet.Lang, templates.MessageAppUpgrade,
+				&templates.AppUpgradeData{AppVersion: wallet.AppVersion, MinVersion: minVersion})
+			if err != nil {
+				n.logger.Error("Failed to render upgrade notification", "address", wallet.Address, "error", err)
+				continue
+			}
+
+			if err := n.repo.SetUpgradeNotifiedVersion(wallet.Address, minVersion); err != nil {
+				n.logger.Error("Failed to set upgrade notified version", "address", wallet.Address, "error", err)
+				continue
+			}
+
+			n.logger.Info("Sending upgrade notification", "address", wallet.Address, "app_version", wallet.AppVersion, "min_version", minVersion)
+			notification := &models.Notification{
+				Wallet:        wallet.Address,
+				CustomMessage: message,
+				NetworkID:     n.config.NetworkID.Int64(),
+				EventType:     models.EventAppUpgrade,
+			}
+			n.safeGo(func() {
+				n.notificator.SendNotification(notification)
+			}, "upgradeNotification")
+		}
+	}
+}
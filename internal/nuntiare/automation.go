@@ -0,0 +1,98 @@
+package nuntiare
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// MaxAutomationHooks limits the automation hooks (e.g. Zaps) of a wallet
+	MaxAutomationHooks = 10
+	// DefaultAutomationPollLimit is the number of notifications returned to polling triggers by default
+	DefaultAutomationPollLimit = 50
+	// MaxAutomationPollLimit caps the number of notifications returned to polling triggers
+	MaxAutomationPollLimit = 100
+)
+
+// GetAutomationItems returns the latest notifications of the wallet, newest first, flattened for polling
+// triggers of automation platforms. eventType limits them to one event type (empty for all).
+func (n *Nuntiare) GetAutomationItems(address, eventType string, limit int) ([]*models.AutomationItem, error) {
+	if eventType != "" && !models.IsEventType(eventType) {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidEventType, eventType)
+	}
+	if limit <= 0 {
+		limit = DefaultAutomationPollLimit
+	}
+
+	filters := map[string]any{"wallet": address}
+	if eventType != "" {
+		filters["event_type"] = eventType
+	}
+	page, err := n.repo.ListNotifications(models.ListOptions{
+		Limit:   min(limit, MaxAutomationPollLimit),
+		Sort:    "created_at",
+		Desc:    true,
+		Filters: filters,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*models.AutomationItem, 0, len(page.Items))
+	for _, notification := range page.Items {
+		items = append(items, models.NewAutomationItem(notification))
+	}
+	return items, nil
+}
+
+// AddAutomationHook subscribes an automation platform's REST hook to the notifications of the wallet.
+// eventType limits the hook to one event type (empty for all). The hook gets its own signing secret.
+func (n *Nuntiare) AddAutomationHook(address, targetURL, eventType string) (*models.AutomationHook, error) {
+	if err := n.ValidateWebhookURL(targetURL); err != nil {
+		return nil, err
+	}
+	if eventType != "" && !models.IsEventType(eventType) {
+		return nil, fmt.Errorf("%w: %q", models.ErrInvalidEventType, eventType)
+	}
+
+	hooks, err := n.repo.GetAutomationHooks(address)
+	if err != nil {
+		return nil, err
+	}
+	if len(hooks) >= MaxAutomationHooks {
+		return nil, fmt.Errorf("%w: at most %d hooks per wallet", models.ErrInvalidAutomationHook, MaxAutomationHooks)
+	}
+
+	id, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	hook := &models.AutomationHook{
+		ID:            id,
+		WalletAddress: address,
+		TargetURL:     targetURL,
+		EventType:     eventType,
+		CreatedAt:     time.Now().Unix(),
+		Secret:        secret,
+	}
+	if err := n.repo.AddAutomationHook(hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// GetAutomationHooks returns the automation hooks of the wallet, oldest first
+func (n *Nuntiare) GetAutomationHooks(address string) ([]*models.AutomationHook, error) {
+	return n.repo.GetAutomationHooks(address)
+}
+
+// RemoveAutomationHook unsubscribes an automation hook of the wallet. Returns false if it doesn't exist.
+func (n *Nuntiare) RemoveAutomationHook(address, id string) (bool, error) {
+	return n.repo.RemoveAutomationHook(address, id)
+}
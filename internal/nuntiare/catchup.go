@@ -0,0 +1,171 @@
+package nuntiare
+
+import (
+	"fmt"
+
+	"github.com/core-coin/go-core/v2/core/types"
+)
+
+const (
+	// MaxCatchUpBlocks limits the missed blocks caught up when the header subscription resumes. Longer gaps
+	// are caught up partially, the older blocks need a reprocess job.
+	MaxCatchUpBlocks = 20000
+	// ProcessedBlockPruneInterval is how many blocks pass between removing processed block marks that are
+	// too old to be caught up
+	ProcessedBlockPruneInterval = 1000
+)
+
+// fetchedBlock is the result of fetching one block
+type fetchedBlock struct {
+	block *types.Block
+	err   error
+}
+
+// fetchBlocks fetches the blocks with up to BLOCK_PROCESSING_CONCURRENCY requests in flight and passes them
+// to handle in the given order, so subscription payments are still queued in chain order. Stops when handle
+// returns false, a block can't be fetched or the instance shuts down.
+func (n *Nuntiare) fetchBlocks(numbers []uint64, handle func(*types.Block) bool) error {
+	concurrency := max(n.config.BlockProcessingConcurrency, 1)
+	slots := make(chan struct{}, concurrency)
+	pending := make(chan chan fetchedBlock, concurrency)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go func() {
+		defer close(pending)
+		for _, number := range numbers {
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			case <-n.ctx.Done():
+				return
+			}
+			result := make(chan fetchedBlock, 1)
+			go func(number uint64) {
+				block, err := n.gocore.GetBlockByNumber(number)
+				if err != nil {
+					err = fmt.Errorf("failed to get block %d: %w", number, err)
+				}
+				result <- fetchedBlock{block: block, err: err}
+			}(number)
+			select {
+			case pending <- result:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for result := range pending {
+		fetched := <-result
+		<-slots
+		if fetched.err != nil {
+			return fetched.err
+		}
+		if !handle(fetched.block) {
+			return nil
+		}
+	}
+	return n.ctx.Err()
+}
+
+// backfill submits the blocks mined since the last processed block while the instance was down to the
+// pipeline, before the header subscription starts, so they are scanned in parallel and dispatched in chain
+// order. Blocks that can't be fetched are retried with the next head. Returns the last submitted block.
+func (n *Nuntiare) backfill(lastProcessed uint64) uint64 {
+	if lastProcessed == 0 {
+		return 0
+	}
+	head, err := n.gocore.GetBlockNumber()
+	if err != nil {
+		n.logger.Warn("Failed to get the head block, missed blocks are caught up once the next head arrives", "error", err)
+		return lastProcessed
+	}
+	if head <= lastProcessed {
+		return lastProcessed
+	}
+
+	numbers := n.missedRange(lastProcessed, head)
+	n.logger.Info("Backfilling blocks missed while down", "last_processed", lastProcessed, "head", head, "blocks", len(numbers))
+	for _, number := range numbers {
+		if !n.submitBlock(&blockJob{number: number}) {
+			return lastProcessed
+		}
+	}
+	return head
+}
+
+// missedRange returns the blocks in lastProcessed+1..to that no instance processed, at most the latest
+// MaxCatchUpBlocks. When the processed blocks can't be loaded, the range is spilled and nothing is returned.
+func (n *Nuntiare) missedRange(lastProcessed, to uint64) []uint64 {
+	from := lastProcessed + 1
+	if to-from+1 > MaxCatchUpBlocks {
+		n.logger.Warn("Too many missed blocks to catch up, schedule a reprocess job for the older ones",
+			"missed_from", from, "missed_to", to-MaxCatchUpBlocks, "max", MaxCatchUpBlocks)
+		from = to - MaxCatchUpBlocks + 1
+	}
+
+	numbers, err := n.missedBlocks(from, to)
+	if err != nil {
+		n.logger.Error("Failed to get the missed blocks, not catching up", "error", err, "from", from, "to", to)
+		missed := make([]uint64, 0, to-from+1)
+		for number := from; number <= to; number++ {
+			missed = append(missed, number)
+		}
+		n.spillBlocks(missed...)
+		return nil
+	}
+	return numbers
+}
+
+// missedBlocks returns the blocks in from..to that no instance processed. Shadow instances process every block themselves.
+func (n *Nuntiare) missedBlocks(from, to uint64) ([]uint64, error) {
+	processed := make(map[uint64]bool)
+	if !n.config.ShadowMode {
+		numbers, err := n.repo.GetProcessedBlocks(from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, number := range numbers {
+			processed[number] = true
+		}
+	}
+
+	var missed []uint64
+	for number := from; number <= to; number++ {
+		if !processed[number] {
+			missed = append(missed, number)
+		}
+	}
+	return missed, nil
+}
+
+// lastProcessedBlock returns the block the instance catches up from after starting
+func (n *Nuntiare) lastProcessedBlock() uint64 {
+	if n.config.ShadowMode {
+		return 0
+	}
+	number, err := n.repo.GetLastProcessedBlock()
+	if err != nil {
+		n.logger.Error("Failed to get the last processed block, blocks missed while down are not caught up", "error", err)
+		return 0
+	}
+	return number
+}
+
+// markBlockProcessed records that the block was handled and regularly removes marks that are too old to matter
+func (n *Nuntiare) markBlockProcessed(number uint64) {
+	if n.config.ShadowMode {
+		return
+	}
+	if err := n.repo.MarkBlockProcessed(number, n.instanceID); err != nil {
+		n.logger.Error("Failed to mark block processed", "block", number, "error", err)
+		return
+	}
+	if number%ProcessedBlockPruneInterval == 0 && number > 2*MaxCatchUpBlocks {
+		if err := n.repo.RemoveProcessedBlocksBefore(number - 2*MaxCatchUpBlocks); err != nil {
+			n.logger.Error("Failed to remove old processed blocks", "error", err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package nuntiare
+
+import (
+	"time"
+)
+
+const (
+	// ClockSyncInterval is how often the offset of the database clock is measured
+	ClockSyncInterval = time.Minute
+	// ClockSkewWarning is the offset of the local clock from the database clock that is logged as a warning
+	ClockSkewWarning = 5 * time.Second
+)
+
+// syncClock measures the offset of the database server clock from the local clock. Subscription timing
+// uses the database clock, so instances with drifting clocks agree on who is subscribed. The previous offset
+// is kept when the database is unreachable.
+func (n *Nuntiare) syncClock() {
+	before := time.Now()
+	dbTime, err := n.repo.DatabaseTime()
+	if err != nil {
+		n.logger.Warn("Failed to get database time, keeping the previous clock offset", "error", err)
+		return
+	}
+	after := time.Now()
+
+	// The database read its clock about halfway through the round trip
+	offset := dbTime.Sub(before.Add(after.Sub(before) / 2))
+	n.clockOffset.Store(int64(offset))
+	if offset > ClockSkewWarning || offset < -ClockSkewWarning {
+		n.logger.Warn("Local clock is off from the database clock", "offset", offset.String(), "instance_id", n.instanceID)
+	}
+}
+
+// now returns the current time on the database clock
+func (n *Nuntiare) now() time.Time {
+	return time.Now().Add(time.Duration(n.clockOffset.Load()))
+}
+
+// subscriptionActive reports whether a subscription expiring at expiresAt is active at the timestamp. It stays
+// active for SUBSCRIPTION_CLOCK_TOLERANCE_SECONDS after it expired, so timestamps taken on slightly different
+// clocks (block timestamps, instances) don't disagree about an expiration that just happened.
+func (n *Nuntiare) subscriptionActive(expiresAt, timestamp int64) bool {
+	return expiresAt > timestamp-int64(n.config.SubscriptionClockTolerance)
+}
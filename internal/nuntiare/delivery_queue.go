@@ -0,0 +1,103 @@
+package nuntiare
+
+import (
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// DeliveryRetryInterval is how often due deliveries of the delivery queue are retried
+	DeliveryRetryInterval = 30 * time.Second
+	// DeliveryRetryBatchSize limits the deliveries retried per run
+	DeliveryRetryBatchSize = 200
+	// deliveryRetryLock ensures a single instance retries the queued deliveries
+	deliveryRetryLock = "delivery_retries"
+	// DeliveryRetryLockTTL is the delivery retry lock TTL in seconds
+	DeliveryRetryLockTTL = 120
+	// DeliveryJobClaim is how long a claimed delivery job isn't retried by another run, so a job whose retry
+	// outlives the retry lock isn't sent twice
+	DeliveryJobClaim = 10 * time.Minute
+)
+
+// retryDeliveries retries the queued deliveries that are due
+func (n *Nuntiare) retryDeliveries() {
+	acquired, err := n.tryAcquireLock(deliveryRetryLock, DeliveryRetryLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for delivery retries", "error", err)
+		return
+	}
+	if !acquired {
+		// Another instance is retrying
+		return
+	}
+	acquiredAt := time.Now()
+	defer func() {
+		n.checkLockOverrun(deliveryRetryLock, acquiredAt, DeliveryRetryLockTTL)
+		if err := n.repo.ReleaseLock(deliveryRetryLock, n.instanceID); err != nil {
+			n.logger.Error("Failed to release delivery retry lock", "error", err)
+		}
+	}()
+
+	now := time.Now()
+	due, err := n.repo.ClaimDueDeliveryJobs(now.Unix(), now.Add(DeliveryJobClaim).Unix(), DeliveryRetryBatchSize)
+	if err != nil {
+		n.logger.Error("Failed to claim due delivery jobs", "error", err)
+		return
+	}
+
+	for _, job := range due {
+		if n.ctx.Err() != nil {
+			return
+		}
+		n.notificator.RetryDelivery(job)
+	}
+}
+
+// ListDeliveryJobs returns a page of the failed deliveries waiting for a retry
+func (n *Nuntiare) ListDeliveryJobs(opts models.ListOptions) (*models.Page[models.DeliveryJob], error) {
+	return n.repo.ListDeliveryJobs(opts)
+}
+
+// ListDeadLetters returns a page of the deliveries that ran out of attempts
+func (n *Nuntiare) ListDeadLetters(opts models.ListOptions) (*models.Page[models.DeadLetter], error) {
+	return n.repo.ListDeadLetters(opts)
+}
+
+// GetDeadLetter returns a dead letter by its ID, or nil if it doesn't exist
+func (n *Nuntiare) GetDeadLetter(id string) (*models.DeadLetter, error) {
+	letter, err := n.repo.GetDeadLetter(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return letter, nil
+}
+
+// ReplayDeadLetter sends a dead letter once more through its channel, with the wallet's current settings of
+// the channel. Returns nil if it doesn't exist, models.ErrAlreadyReplayed if a replay already delivered it and
+// models.ErrChannelDisabled if the wallet no longer uses the channel.
+func (n *Nuntiare) ReplayDeadLetter(id string) (*models.DeliveryReplay, error) {
+	letter, err := n.GetDeadLetter(id)
+	if err != nil || letter == nil {
+		return nil, err
+	}
+	if letter.ReplayedAt > 0 {
+		return nil, models.ErrAlreadyReplayed
+	}
+
+	replay, err := n.notificator.ReplayDeadLetter(letter)
+	if err != nil {
+		return nil, err
+	}
+	n.logger.Info("Dead letter replayed", "id", letter.ID, "wallet", letter.Wallet, "channel", letter.Channel, "error", replay.Error)
+	return replay, nil
+}
+
+// RemoveDeadLetter discards a dead letter. Returns false if it doesn't exist.
+func (n *Nuntiare) RemoveDeadLetter(id string) (bool, error) {
+	return n.repo.RemoveDeadLetter(id)
+}
@@ -0,0 +1,41 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// StaleDeviceCleanupInterval is how often devices that haven't been seen recently are removed
+const StaleDeviceCleanupInterval = 1 * time.Hour
+
+// RegisterDevice registers a device of a wallet or refreshes its details, marking it as seen now
+func (n *Nuntiare) RegisterDevice(device *models.Device) error {
+	now := time.Now().Unix()
+	device.CreatedAt = now
+	device.LastSeenAt = now
+	return n.repo.UpsertDevice(device)
+}
+
+// GetDevices returns the devices registered for a wallet, most recently seen first
+func (n *Nuntiare) GetDevices(address string) ([]*models.Device, error) {
+	return n.repo.GetDevices(address)
+}
+
+// RemoveDevice unregisters a device of a wallet. Returns false if the device doesn't exist.
+func (n *Nuntiare) RemoveDevice(address, deviceID string) (bool, error) {
+	return n.repo.RemoveDevice(address, deviceID)
+}
+
+// removeStaleDevices deletes devices that haven't refreshed their registration for DEVICE_STALE_DAYS
+func (n *Nuntiare) removeStaleDevices() {
+	cutoff := time.Now().AddDate(0, 0, -n.config.DeviceStaleDays).Unix()
+	removed, err := n.repo.RemoveStaleDevices(cutoff)
+	if err != nil {
+		n.logger.Error("Failed to remove stale devices", "error", err)
+		return
+	}
+	if removed > 0 {
+		n.logger.Info("Removed stale devices", "count", removed)
+	}
+}
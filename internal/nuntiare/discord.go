@@ -0,0 +1,102 @@
+package nuntiare
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// discordChannelIDRegex matches a Discord snowflake ID
+var discordChannelIDRegex = regexp.MustCompile(`^[0-9]{17,20}$`)
+
+// discordWebhookHosts are the hosts Discord issues channel webhook URLs for
+var discordWebhookHosts = map[string]bool{
+	"discord.com":        true,
+	"ptb.discord.com":    true,
+	"canary.discord.com": true,
+	"discordapp.com":     true,
+}
+
+// SetDiscord sets the Discord webhook URL or bot channel notifications of the wallet are posted to.
+// A bot channel is only posted to once its returned verification code was posted to it, so a wallet can't
+// make the bot post to channels of other servers. Webhook URLs return no code.
+func (n *Nuntiare) SetDiscord(address, webhookURL, channelID string) (string, error) {
+	if err := n.ValidateDiscord(webhookURL, channelID); err != nil {
+		return "", err
+	}
+	var code string
+	if channelID != "" && webhookURL == "" {
+		var err error
+		if code, err = newDiscordVerificationCode(); err != nil {
+			return "", err
+		}
+	}
+	if err := n.repo.UpsertDiscordProvider(address, webhookURL, channelID, code); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// VerifyDiscord marks the wallet's bot channel as verified when its verification code was posted to the channel.
+// Returns ErrDiscordNotVerified if the code isn't among the channel's recent messages.
+func (n *Nuntiare) VerifyDiscord(address string) error {
+	provider, err := n.repo.GetWalletsNotificationProvider(address)
+	if err != nil {
+		return err
+	}
+	discord := provider.DiscordProvider
+	if discord.ChannelVerified || discord.WebhookURL != "" {
+		return nil
+	}
+	if discord.ChannelID == "" || discord.VerificationCode == "" {
+		return fmt.Errorf("%w: no discord channel registered", models.ErrDiscordNotVerified)
+	}
+
+	found, err := n.notificator.FindDiscordCode(discord.ChannelID, discord.VerificationCode)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: post %s to the channel mentioning the bot", models.ErrDiscordNotVerified, discord.VerificationCode)
+	}
+	if err := n.repo.VerifyDiscordProvider(discord.ID); err != nil {
+		return err
+	}
+	n.logger.Info("Discord channel verified", "address", address)
+	return nil
+}
+
+// newDiscordVerificationCode returns a random code to post to a Discord channel
+func newDiscordVerificationCode() (string, error) {
+	bytes := make([]byte, 5)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate discord verification code: %w", err)
+	}
+	return "nuntiare-" + hex.EncodeToString(bytes), nil
+}
+
+// ValidateDiscord returns ErrInvalidDiscord unless the webhook URL is a Discord channel webhook
+// and the channel ID is a Discord snowflake. Channel IDs require DISCORD_BOT_TOKEN.
+func (n *Nuntiare) ValidateDiscord(webhookURL, channelID string) error {
+	if webhookURL != "" {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || parsed.Scheme != "https" || !discordWebhookHosts[parsed.Host] ||
+			!strings.HasPrefix(parsed.Path, "/api/webhooks/") || parsed.User != nil {
+			return fmt.Errorf("%w: webhook URL must be a https://discord.com/api/webhooks/... URL", models.ErrInvalidDiscord)
+		}
+	}
+	if channelID != "" {
+		if !discordChannelIDRegex.MatchString(channelID) {
+			return fmt.Errorf("%w: channel ID must be a numeric Discord ID", models.ErrInvalidDiscord)
+		}
+		if n.config.DiscordBotToken == "" && webhookURL == "" {
+			return fmt.Errorf("%w: channel IDs are not supported, use a webhook URL", models.ErrInvalidDiscord)
+		}
+	}
+	return nil
+}
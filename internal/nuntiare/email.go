@@ -0,0 +1,73 @@
+package nuntiare
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/notificator"
+)
+
+// SNSConfirmTimeout limits the SNS subscription confirmation request
+const SNSConfirmTimeout = 10 * time.Second
+
+// ProcessEmailWebhook ingests delivery/bounce events from an email provider webhook.
+// Permanent failures (bounces, complaints) mark the email as bounced so it is no longer used.
+func (n *Nuntiare) ProcessEmailWebhook(provider, token string, body []byte) error {
+	secret := n.config.EmailWebhookSecret
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return models.ErrUnauthorized
+	}
+
+	events, subscribeURL, err := notificator.ParseEmailWebhook(provider, body)
+	if err != nil {
+		return err
+	}
+
+	if subscribeURL != "" {
+		return n.confirmSNSSubscription(subscribeURL)
+	}
+
+	if err := n.repo.AddEmailEvents(events); err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		n.logger.Debug("Email event received", "provider", provider, "email", event.Email, "event", event.Event)
+		if !event.IsPermanentFailure() {
+			continue
+		}
+		n.logger.Info("Email permanently failed, disabling address", "email", event.Email, "event", event.Event, "reason", event.Reason)
+		if err := n.repo.SetEmailBounced(event.Email, true); err != nil {
+			n.logger.Error("Failed to mark email as bounced", "error", err, "email", event.Email)
+		}
+	}
+
+	return nil
+}
+
+// confirmSNSSubscription confirms an Amazon SNS subscription for SES notifications
+func (n *Nuntiare) confirmSNSSubscription(subscribeURL string) error {
+	parsed, err := url.Parse(subscribeURL)
+	if err != nil || parsed.Scheme != "https" || !strings.HasSuffix(parsed.Hostname(), ".amazonaws.com") {
+		return fmt.Errorf("invalid SNS subscribe URL: %s", subscribeURL)
+	}
+
+	client := &http.Client{Timeout: SNSConfirmTimeout}
+	resp, err := client.Get(subscribeURL)
+	if err != nil {
+		return fmt.Errorf("failed to confirm SNS subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to confirm SNS subscription: unexpected status code %d", resp.StatusCode)
+	}
+
+	n.logger.Info("SNS subscription confirmed for SES notifications")
+	return nil
+}
@@ -0,0 +1,84 @@
+package nuntiare
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// EmailVerificationTTL is how long an email verification link can be used
+	EmailVerificationTTL = 24 * time.Hour
+	// EmailVerificationResendInterval is the minimum time between two verification emails to the same address,
+	// so repeated registrations, of one wallet or many, can't be used to flood it
+	EmailVerificationResendInterval = 10 * time.Minute
+)
+
+// requestEmailVerification emails a verification link to the wallet's email unless it is verified already.
+// Notifications are only emailed once the link was opened.
+func (n *Nuntiare) requestEmailVerification(address string) {
+	provider, err := n.repo.GetWalletsNotificationProvider(address)
+	if err != nil {
+		n.logger.Error("Failed to get notification provider for email verification", "error", err, "address", address)
+		return
+	}
+	email := provider.EmailProvider.Email
+	if email == "" || provider.EmailProvider.Verified {
+		return
+	}
+	if n.config.PublicURL == "" {
+		n.logger.Error("PUBLIC_URL is not set, can't send the email verification link", "address", address)
+		return
+	}
+
+	now := time.Now()
+	latest, err := n.repo.GetLatestEmailVerification(email)
+	if err != nil {
+		n.logger.Error("Failed to get email verification", "error", err, "address", address)
+		return
+	}
+	if latest != nil && now.Unix()-latest.CreatedAt < int64(EmailVerificationResendInterval.Seconds()) {
+		n.logger.Debug("Email verification sent recently, not resending", "address", address)
+		return
+	}
+
+	token, err := newSecret()
+	if err != nil {
+		n.logger.Error("Failed to generate email verification token", "error", err)
+		return
+	}
+	verification := &models.EmailVerification{
+		TokenHash:     hashVerificationToken(token),
+		WalletAddress: address,
+		Email:         email,
+		CreatedAt:     now.Unix(),
+		ExpiresAt:     now.Add(EmailVerificationTTL).Unix(),
+	}
+	if err := n.repo.AddEmailVerification(verification); err != nil {
+		n.logger.Error("Failed to add email verification", "error", err, "address", address)
+		return
+	}
+
+	link := n.config.PublicURL + "/api/v1/email/verify?token=" + token
+	n.notificator.SendEmailVerification(email, address, link, EmailVerificationTTL)
+	n.logger.Info("Email verification sent", "address", address)
+}
+
+// VerifyEmail confirms the email of the verification link's token. Returns ErrInvalidVerificationToken if the
+// token is unknown or expired, or the wallet's email changed after the link was sent.
+func (n *Nuntiare) VerifyEmail(token string) (*models.EmailVerification, error) {
+	verification, err := n.repo.VerifyEmail(hashVerificationToken(token), time.Now().Unix())
+	if err != nil {
+		return nil, err
+	}
+	n.logger.Info("Email verified", "address", verification.WalletAddress)
+	return verification, nil
+}
+
+// hashVerificationToken returns the stored form of a verification token
+func hashVerificationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,505 @@
+package nuntiare
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// DefaultExchangeConfirmations is the number of confirmations of exchanges added without one
+	DefaultExchangeConfirmations = 12
+	// MaxExchangeConfirmations caps the confirmations an exchange can require
+	MaxExchangeConfirmations = 1000
+	// MaxExchangeNameLength caps the display name of an exchange
+	MaxExchangeNameLength = 100
+	// MaxExchangeAddressBatch caps the addresses of a bulk registration
+	MaxExchangeAddressBatch = 1000
+	// MaxExchangeAddressLabelLength caps the label of a deposit address
+	MaxExchangeAddressLabelLength = 100
+
+	// ExchangeDepositInterval is how often deposits are confirmed and delivered to the exchanges' webhooks
+	ExchangeDepositInterval = 15 * time.Second
+	// ExchangeDepositBatchSize limits the deposits confirmed and delivered per run
+	ExchangeDepositBatchSize = 500
+	// ExchangeWebhookRetryDelay is the delay after the first failed webhook delivery of a deposit, doubled
+	// after every further failure
+	ExchangeWebhookRetryDelay = 30 * time.Second
+	// MaxExchangeWebhookRetryDelay caps the delay between two webhook deliveries of a deposit
+	MaxExchangeWebhookRetryDelay = time.Hour
+	// exchangeDepositLock ensures a single instance confirms and delivers the deposits
+	exchangeDepositLock = "exchange_deposits"
+	// ExchangeDepositLockTTL is the exchange deposit lock TTL in seconds
+	ExchangeDepositLockTTL = 120
+)
+
+// AddExchange adds an exchange in deposit-confirmation mode and returns its API key and webhook secret,
+// which are not shown again. confirmations = 0 uses DefaultExchangeConfirmations.
+func (n *Nuntiare) AddExchange(name, webhookURL string, confirmations int) (*models.ExchangeCredentials, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxExchangeNameLength {
+		return nil, fmt.Errorf("%w: name must have 1 to %d characters", models.ErrInvalidExchange, MaxExchangeNameLength)
+	}
+	if confirmations == 0 {
+		confirmations = DefaultExchangeConfirmations
+	}
+	if confirmations < 1 || confirmations > MaxExchangeConfirmations {
+		return nil, fmt.Errorf("%w: confirmations must be between 1 and %d", models.ErrInvalidExchange, MaxExchangeConfirmations)
+	}
+	if err := n.ValidateWebhookURL(webhookURL); err != nil {
+		return nil, err
+	}
+
+	apiKey, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	exchange := &models.Exchange{
+		ID:            newJobID(),
+		Name:          name,
+		APIKeyHash:    hashVerificationToken(apiKey),
+		WebhookURL:    webhookURL,
+		WebhookSecret: secret,
+		Confirmations: confirmations,
+		CreatedAt:     time.Now().Unix(),
+	}
+	if err := n.repo.AddExchange(exchange); err != nil {
+		return nil, err
+	}
+	n.logger.Info("Exchange added", "id", exchange.ID, "name", name, "confirmations", confirmations)
+	return &models.ExchangeCredentials{Exchange: exchange, APIKey: apiKey, WebhookSecret: secret}, nil
+}
+
+// ListExchanges returns the exchanges in deposit-confirmation mode
+func (n *Nuntiare) ListExchanges() ([]*models.Exchange, error) {
+	return n.repo.ListExchanges()
+}
+
+// RemoveExchange removes an exchange and its deposit addresses, its deposits are kept. Returns false if it
+// doesn't exist.
+func (n *Nuntiare) RemoveExchange(id string) (bool, error) {
+	removed, err := n.repo.RemoveExchange(id)
+	if err == nil && removed {
+		n.logger.Info("Exchange removed", "id", id)
+	}
+	return removed, err
+}
+
+// ExchangeByAPIKey returns the exchange authenticating with the API key, or nil if there is none
+func (n *Nuntiare) ExchangeByAPIKey(key string) (*models.Exchange, error) {
+	if key == "" {
+		return nil, nil
+	}
+	exchange, err := n.repo.GetExchangeByAPIKeyHash(hashVerificationToken(key))
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return exchange, nil
+}
+
+// RegisterExchangeAddresses registers deposit addresses of the exchange in bulk, or updates the labels of
+// addresses it already registered. Invalid rows and addresses of other exchanges are reported in the
+// results without failing the others.
+func (n *Nuntiare) RegisterExchangeAddresses(exchange *models.Exchange, rows []models.ExchangeAddressRow) (*models.ExchangeAddressReport, error) {
+	if len(rows) == 0 || len(rows) > MaxExchangeAddressBatch {
+		return nil, fmt.Errorf("%w: a registration must have 1 to %d addresses", models.ErrInvalidExchange, MaxExchangeAddressBatch)
+	}
+
+	report := &models.ExchangeAddressReport{Results: make([]*models.ExchangeAddressResult, len(rows))}
+	valid := make(map[string]*models.ExchangeAddress, len(rows))
+	addresses := make([]string, 0, len(rows))
+	now := time.Now().Unix()
+	for i, row := range rows {
+		result := &models.ExchangeAddressResult{Address: row.Address, Status: models.ExchangeAddressInvalid}
+		report.Results[i] = result
+		address, err := validation.ValidateAndNormalizeAddress(row.Address)
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case len(row.Label) > MaxExchangeAddressLabelLength:
+			result.Error = fmt.Sprintf("label must have at most %d characters", MaxExchangeAddressLabelLength)
+		case valid[address] != nil:
+			result.Error = "duplicate address"
+		default:
+			result.Address = address
+			valid[address] = &models.ExchangeAddress{Address: address, ExchangeID: exchange.ID, Label: row.Label, CreatedAt: now}
+			addresses = append(addresses, address)
+		}
+	}
+
+	owners := make(map[string]string)
+	if len(addresses) > 0 {
+		registered, err := n.repo.GetExchangeAddresses(addresses)
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range registered {
+			owners[address.Address] = address.ExchangeID
+		}
+	}
+
+	save := make([]*models.ExchangeAddress, 0, len(addresses))
+	for _, result := range report.Results {
+		address, ok := valid[result.Address]
+		if result.Error != "" || !ok {
+			report.Failed++
+			continue
+		}
+		switch owner := owners[address.Address]; owner {
+		case "":
+			result.Status = models.ExchangeAddressRegistered
+			report.Registered++
+		case exchange.ID:
+			result.Status = models.ExchangeAddressUpdated
+			report.Updated++
+		default:
+			result.Status = models.ExchangeAddressTaken
+			result.Error = "address is registered by another exchange"
+			report.Failed++
+			continue
+		}
+		save = append(save, address)
+	}
+	if len(save) > 0 {
+		if err := n.repo.SaveExchangeAddresses(save); err != nil {
+			return nil, err
+		}
+	}
+	n.logger.Info("Exchange addresses registered", "exchange", exchange.ID, "registered", report.Registered,
+		"updated", report.Updated, "failed", report.Failed)
+	return report, nil
+}
+
+// RemoveExchangeAddress removes a deposit address of the exchange. Returns false if the exchange didn't
+// register it.
+func (n *Nuntiare) RemoveExchangeAddress(exchange *models.Exchange, address string) (bool, error) {
+	return n.repo.RemoveExchangeAddress(exchange.ID, address)
+}
+
+// ListExchangeDeposits returns a page of the deposits of the exchange
+func (n *Nuntiare) ListExchangeDeposits(exchange *models.Exchange, opts models.ListOptions) (*models.Page[models.ExchangeDeposit], error) {
+	return n.repo.ListExchangeDeposits(exchange.ID, opts)
+}
+
+// CreditExchangeDeposit records that the exchange credited a confirmed deposit with the amount. Returns nil
+// if the deposit doesn't exist and models.ErrDepositNotConfirmed if it isn't confirmed or already credited.
+func (n *Nuntiare) CreditExchangeDeposit(exchange *models.Exchange, id int64, amount float64) (*models.ExchangeDeposit, error) {
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: credited amount must not be negative", models.ErrInvalidExchange)
+	}
+	deposit, err := n.repo.GetExchangeDeposit(exchange.ID, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	credited, err := n.repo.CreditExchangeDeposit(exchange.ID, id, amount, now)
+	if err != nil {
+		return nil, err
+	}
+	if !credited {
+		return nil, models.ErrDepositNotConfirmed
+	}
+	deposit.Status = models.ExchangeDepositCredited
+	deposit.CreditedAmount = amount
+	deposit.CreditedAt = now
+	if deposit.CreditMismatch() {
+		n.logger.Warn("Exchange deposit credited with another amount", "exchange", exchange.ID, "deposit", id,
+			"amount", deposit.Amount, "credited", amount)
+	}
+	return deposit, nil
+}
+
+// GetExchangeReconciliation compares the deposits of the exchange detected in from..to with the ones it credited
+func (n *Nuntiare) GetExchangeReconciliation(exchange *models.Exchange, from, to int64) (*models.ExchangeReconciliation, error) {
+	return n.repo.GetExchangeReconciliation(exchange.ID, from, to)
+}
+
+// recordExchangeDeposits stores the block's transfers to exchange deposit addresses as pending deposits.
+// Transfers are numbered per transaction, so a block processed again doesn't duplicate its deposits.
+func (n *Nuntiare) recordExchangeDeposits(scanned *scannedBlock) {
+	if n.config.ShadowMode {
+		return
+	}
+
+	now := time.Now().Unix()
+	indexes := make(map[string]int)
+	var deposits []*models.ExchangeDeposit
+	add := func(deposit *models.ExchangeDeposit) {
+		deposit.TransferIndex = indexes[deposit.TxHash]
+		indexes[deposit.TxHash]++
+		if deposit.Amount <= 0 && deposit.TokenType != "CBC721" {
+			return
+		}
+		deposit.Address = validation.NormalizeAddress(deposit.Address)
+		deposit.From = validation.NormalizeAddress(deposit.From)
+		deposit.BlockNumber = scanned.number
+		deposit.BlockHash = scanned.hash
+		deposit.Status = models.ExchangeDepositPending
+		deposit.DetectedAt = now
+		deposits = append(deposits, deposit)
+	}
+	for _, transfer := range n.blockTransfers(scanned) {
+		token := models.NativeTokenPreference
+		if transfer.TokenAddress != "" {
+			token = validation.NormalizeAddress(transfer.TokenAddress)
+		}
+		add(&models.ExchangeDeposit{
+			Address:   transfer.To,
+			From:      transfer.From,
+			Token:     token,
+			Currency:  transfer.TokenSymbol,
+			TokenType: transfer.TokenType,
+			TokenID:   transfer.TokenID,
+			Amount:    transfer.Amount,
+			Value:     transfer.Value,
+			TxHash:    transfer.TxHash,
+		})
+	}
+	if len(deposits) == 0 {
+		return
+	}
+
+	addresses := make([]string, len(deposits))
+	for i, deposit := range deposits {
+		addresses[i] = deposit.Address
+	}
+	registered, err := n.repo.GetExchangeAddresses(addresses)
+	if err != nil {
+		n.logger.Error("Failed to get exchange addresses", "error", err, "block", scanned.number)
+		return
+	}
+	if len(registered) == 0 {
+		return
+	}
+	byAddress := make(map[string]*models.ExchangeAddress, len(registered))
+	for _, address := range registered {
+		byAddress[address.Address] = address
+	}
+
+	matched := deposits[:0]
+	for _, deposit := range deposits {
+		if address, ok := byAddress[deposit.Address]; ok {
+			deposit.ExchangeID = address.ExchangeID
+			deposit.Label = address.Label
+			matched = append(matched, deposit)
+		}
+	}
+	if err := n.repo.AddExchangeDeposits(matched); err != nil {
+		n.logger.Error("Failed to add exchange deposits", "error", err, "block", scanned.number)
+		return
+	}
+	n.logger.Debug("Exchange deposits detected", "block", scanned.number, "deposits", len(matched))
+}
+
+// processExchangeDeposits confirms the pending exchange deposits and delivers the confirmed ones to the
+// exchanges' webhooks
+func (n *Nuntiare) processExchangeDeposits() {
+	acquired, err := n.tryAcquireLock(exchangeDepositLock, ExchangeDepositLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for exchange deposits", "error", err)
+		return
+	}
+	if !acquired {
+		// Another instance is processing the deposits
+		return
+	}
+	acquiredAt := time.Now()
+	defer func() {
+		n.checkLockOverrun(exchangeDepositLock, acquiredAt, ExchangeDepositLockTTL)
+		if err := n.repo.ReleaseLock(exchangeDepositLock, n.instanceID); err != nil {
+			n.logger.Error("Failed to release exchange deposit lock", "error", err)
+		}
+	}()
+
+	list, err := n.repo.ListExchanges()
+	if err != nil {
+		n.logger.Error("Failed to list exchanges", "error", err)
+		return
+	}
+	if len(list) == 0 {
+		return
+	}
+	exchanges := make(map[string]*models.Exchange, len(list))
+	for _, exchange := range list {
+		exchanges[exchange.ID] = exchange
+	}
+
+	n.confirmExchangeDeposits(exchanges)
+	n.deliverExchangeDeposits(exchanges)
+}
+
+// confirmExchangeDeposits confirms the pending deposits whose block has the confirmations of their exchange
+// and is still part of the chain, marks the deposits of replaced blocks orphaned and those of failed
+// transactions reverted. A reverted token transfer still looks like a transfer in the call data, so only
+// deposits whose transaction receipt reports success are confirmed.
+func (n *Nuntiare) confirmExchangeDeposits(exchanges map[string]*models.Exchange) {
+	pending, err := n.repo.GetPendingExchangeDeposits(ExchangeDepositBatchSize)
+	if err != nil {
+		n.logger.Error("Failed to get pending exchange deposits", "error", err)
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+	head, err := n.gocore.GetBlockNumber()
+	if err != nil {
+		n.logger.Error("Failed to get block number for exchange deposits", "error", err)
+		return
+	}
+
+	hashes := make(map[uint64]string)
+	statuses := make(map[string]uint64)
+	for _, deposit := range pending {
+		if n.ctx.Err() != nil {
+			return
+		}
+		exchange, ok := exchanges[deposit.ExchangeID]
+		if !ok || deposit.BlockNumber > head || head-deposit.BlockNumber+1 < uint64(exchange.Confirmations) {
+			continue
+		}
+
+		hash, ok := hashes[deposit.BlockNumber]
+		if !ok {
+			block, err := n.gocore.GetBlockByNumber(deposit.BlockNumber)
+			if err != nil {
+				n.logger.Error("Failed to get block of exchange deposit", "error", err, "block", deposit.BlockNumber)
+				continue
+			}
+			hash = block.Hash().String()
+			hashes[deposit.BlockNumber] = hash
+		}
+
+		now := time.Now().Unix()
+		if hash != deposit.BlockHash {
+			deposit.Status = models.ExchangeDepositOrphaned
+			n.logger.Warn("Exchange deposit orphaned by a reorganization", "deposit", deposit.ID, "exchange", deposit.ExchangeID,
+				"tx", deposit.TxHash, "block", deposit.BlockNumber)
+		} else if status, ok := n.exchangeDepositStatus(statuses, deposit); !ok {
+			continue
+		} else if status != types.ReceiptStatusSuccessful {
+			deposit.Status = models.ExchangeDepositReverted
+			n.logger.Warn("Exchange deposit transaction reverted", "deposit", deposit.ID, "exchange", deposit.ExchangeID,
+				"tx", deposit.TxHash, "block", deposit.BlockNumber)
+		} else {
+			deposit.Status = models.ExchangeDepositConfirmed
+			deposit.ConfirmedAt = now
+			deposit.NextAttemptAt = now
+		}
+		if err := n.repo.UpdateExchangeDeposit(deposit); err != nil {
+			n.logger.Error("Failed to update exchange deposit", "error", err, "deposit", deposit.ID)
+		}
+	}
+}
+
+// exchangeDepositStatus returns the receipt status of the deposit's transaction, cached per transaction.
+// Returns false when the receipt can't be fetched, the deposit is confirmed with a later run then.
+func (n *Nuntiare) exchangeDepositStatus(statuses map[string]uint64, deposit *models.ExchangeDeposit) (uint64, bool) {
+	if status, ok := statuses[deposit.TxHash]; ok {
+		return status, true
+	}
+	receipt, err := n.gocore.GetTransactionReceipt(deposit.TxHash)
+	if err != nil {
+		n.logger.Error("Failed to get receipt of exchange deposit", "error", err, "tx", deposit.TxHash)
+		return 0, false
+	}
+	statuses[deposit.TxHash] = receipt.Status
+	return receipt.Status, true
+}
+
+// deliverExchangeDeposits sends the confirmed deposits to the exchanges' webhooks, in chain order per
+// address. A deposit whose delivery fails holds back the later deposits of its address until it is delivered.
+func (n *Nuntiare) deliverExchangeDeposits(exchanges map[string]*models.Exchange) {
+	now := time.Now()
+	deliverable, err := n.repo.GetDeliverableExchangeDeposits(now.Unix(), ExchangeDepositBatchSize)
+	if err != nil {
+		n.logger.Error("Failed to get deliverable exchange deposits", "error", err)
+		return
+	}
+
+	for _, deposit := range deliverable {
+		if n.ctx.Err() != nil {
+			return
+		}
+		exchange, ok := exchanges[deposit.ExchangeID]
+		if !ok {
+			continue
+		}
+		event, err := n.exchangeDepositEvent(deposit)
+		if err != nil {
+			n.logger.Error("Failed to prepare exchange deposit webhook", "error", err, "deposit", deposit.ID)
+			continue
+		}
+
+		deposit.Attempts++
+		if err := n.notificator.SendWebhookEvent(exchange.WebhookURL, exchange.WebhookSecret, event); err != nil {
+			deposit.LastError = err.Error()
+			deposit.NextAttemptAt = now.Add(exchangeRetryDelay(deposit.Attempts)).Unix()
+			n.logger.Warn("Exchange deposit webhook failed", "error", err, "deposit", deposit.ID, "exchange", exchange.ID,
+				"attempts", deposit.Attempts)
+		} else {
+			deposit.Status = models.ExchangeDepositDelivered
+			deposit.DeliveredAt = time.Now().Unix()
+			deposit.LastError = ""
+		}
+		if err := n.repo.UpdateExchangeDeposit(deposit); err != nil {
+			n.logger.Error("Failed to update exchange deposit", "error", err, "deposit", deposit.ID)
+		}
+	}
+}
+
+// exchangeDepositEvent returns the webhook event of a deposit, stored with the first attempt so every
+// retry has the same event ID
+func (n *Nuntiare) exchangeDepositEvent(deposit *models.ExchangeDeposit) (*models.WebhookEvent, error) {
+	if deposit.WebhookEventID != "" {
+		event, err := n.repo.GetWebhookEvent(deposit.WebhookEventID)
+		// An event removed by the retention is sent again with a new ID
+		if err == nil || !strings.Contains(err.Error(), "record not found") {
+			return event, err
+		}
+	}
+
+	data, err := json.Marshal(deposit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal exchange deposit: %w", err)
+	}
+	// The event has no wallet, so the wallet webhook event log doesn't list it
+	event := &models.WebhookEvent{
+		ID:        newJobID(),
+		Type:      models.WebhookEventExchangeDeposit,
+		Data:      string(data),
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := n.repo.AddWebhookEvent(event); err != nil {
+		return nil, err
+	}
+	deposit.WebhookEventID = event.ID
+	if err := n.repo.UpdateExchangeDeposit(deposit); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// exchangeRetryDelay returns the delay before the next webhook delivery of a deposit that failed attempts times
+func exchangeRetryDelay(attempts int) time.Duration {
+	delay := ExchangeWebhookRetryDelay
+	for i := 1; i < attempts && delay < MaxExchangeWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, MaxExchangeWebhookRetryDelay)
+}
@@ -0,0 +1,22 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MarkNotificationRead marks a notification of the wallet as read. Returns false if it doesn't exist.
+func (n *Nuntiare) MarkNotificationRead(address, id string) (bool, error) {
+	return n.repo.MarkNotificationRead(address, id, time.Now().Unix())
+}
+
+// CountUnreadNotifications returns the number of notifications of the wallet not read in the app yet
+func (n *Nuntiare) CountUnreadNotifications(address string) (int64, error) {
+	return n.repo.CountUnreadNotifications(address)
+}
+
+// ListNotificationHistory returns a page of the notifications sent to the wallet, one entry per channel
+func (n *Nuntiare) ListNotificationHistory(address string, opts models.ListOptions) (*models.Page[models.NotificationDelivery], error) {
+	return n.repo.ListNotificationDeliveries(address, opts)
+}
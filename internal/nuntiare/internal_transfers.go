@@ -0,0 +1,89 @@
+package nuntiare
+
+import (
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// labelInternalTransfer marks the notification as internal when the sender belongs to the same user as the recipient wallet
+func (n *Nuntiare) labelInternalTransfer(wallet *models.Wallet, notification *models.Notification) {
+	if n.isInternalTransfer(wallet, notification.From) {
+		notification.Internal = true
+	}
+}
+
+// isInternalTransfer checks if the sender is the wallet's own subscription address or a registered
+// wallet (or subscription address) of the same user. Wallets belong to the same user if they joined
+// the same user, were registered from the same origin or share a Telegram username or email.
+func (n *Nuntiare) isInternalTransfer(wallet *models.Wallet, from string) bool {
+	sender := validation.NormalizeAddress(from)
+	if sender == "" {
+		return false
+	}
+	if sender == validation.NormalizeAddress(wallet.SubscriptionAddress) {
+		return true
+	}
+
+	for _, senderWallet := range n.findWalletsByAnyAddress(sender) {
+		if n.sameUserWallets(wallet, senderWallet) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameUserWallets checks if the sender wallet belongs to the same user as the recipient wallet
+func (n *Nuntiare) sameUserWallets(wallet, senderWallet *models.Wallet) bool {
+	if validation.NormalizeAddress(senderWallet.Address) == validation.NormalizeAddress(wallet.Address) || senderWallet.OriginID == wallet.OriginID {
+		return true
+	}
+	if wallet.UserID != "" && senderWallet.UserID == wallet.UserID {
+		return true
+	}
+
+	return n.shareNotificationProvider(wallet.Address, senderWallet.Address)
+}
+
+// findWalletsByAnyAddress returns the registered wallet with the given address, or the wallets paid for from it
+// when it is a subscription address
+func (n *Nuntiare) findWalletsByAnyAddress(address string) []*models.Wallet {
+	exists, err := n.repo.CheckWalletExists(address)
+	if err != nil {
+		n.logger.Error("Failed to check sender wallet", "error", err, "address", address)
+		return nil
+	}
+	if exists {
+		wallet, err := n.repo.GetWallet(address)
+		if err != nil {
+			n.logger.Error("Failed to get sender wallet", "error", err, "address", address)
+			return nil
+		}
+		return []*models.Wallet{wallet}
+	}
+
+	wallets, err := n.repo.GetWalletsBySubscriptionAddress(address)
+	if err != nil {
+		n.logger.Error("Failed to get sender wallets", "error", err, "address", address)
+		return nil
+	}
+	return wallets
+}
+
+// shareNotificationProvider checks if two wallets notify the same Telegram username or email
+func (n *Nuntiare) shareNotificationProvider(address, otherAddress string) bool {
+	provider, err := n.repo.GetWalletsNotificationProvider(address)
+	if err != nil {
+		return false
+	}
+	other, err := n.repo.GetWalletsNotificationProvider(otherAddress)
+	if err != nil {
+		return false
+	}
+
+	if provider.TelegramProvider.Username != "" && strings.EqualFold(provider.TelegramProvider.Username, other.TelegramProvider.Username) {
+		return true
+	}
+	return provider.EmailProvider.Email != "" && strings.EqualFold(provider.EmailProvider.Email, other.EmailProvider.Email)
+}
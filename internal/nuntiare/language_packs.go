@@ -0,0 +1,43 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/rjeczalik/notify"
+)
+
+// LanguagePackReloadDelay is how long the language pack directory has to be quiet before the packs are
+// reloaded, so a deployment replacing several files triggers a single reload
+const LanguagePackReloadDelay = 2 * time.Second
+
+// watchLanguagePacks reloads the language packs when files of LANGUAGE_PACK_DIR change. Changes the watcher
+// misses (e.g. on network filesystems) are still picked up by the periodic message template reload.
+func (n *Nuntiare) watchLanguagePacks() {
+	events := make(chan notify.EventInfo, 16)
+	if err := notify.Watch(n.config.LanguagePackDir, events, notify.All); err != nil {
+		n.logger.Error("Failed to watch the language pack directory, packs are reloaded periodically only",
+			"error", err, "dir", n.config.LanguagePackDir)
+		return
+	}
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		defer notify.Stop(events)
+		reload := time.NewTimer(LanguagePackReloadDelay)
+		reload.Stop()
+		defer reload.Stop()
+		for {
+			select {
+			case <-events:
+				reload.Reset(LanguagePackReloadDelay)
+			case <-reload.C:
+				n.logger.Info("Language packs changed, reloading", "dir", n.config.LanguagePackDir)
+				n.notificator.ReloadLanguagePacks()
+			case <-n.ctx.Done():
+				n.logger.Debug("Language pack watcher stopped")
+				return
+			}
+		}
+	}()
+}
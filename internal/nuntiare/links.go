@@ -0,0 +1,16 @@
+package nuntiare
+
+// ResolveShortLink returns the target URL of a short link and counts the click
+func (n *Nuntiare) ResolveShortLink(code string) (string, error) {
+	link, err := n.repo.GetShortLink(code)
+	if err != nil {
+		return "", err
+	}
+
+	// Click tracking must never block the redirect
+	if err := n.repo.IncrementShortLinkClicks(code); err != nil {
+		n.logger.Error("Failed to count short link click", "error", err, "code", code)
+	}
+
+	return link.URL, nil
+}
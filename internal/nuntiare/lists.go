@@ -0,0 +1,23 @@
+package nuntiare
+
+import "github.com/core-coin/nuntiare/internal/models"
+
+// ListWallets returns a page of registered wallets
+func (n *Nuntiare) ListWallets(opts models.ListOptions) (*models.Page[models.Wallet], error) {
+	return n.repo.ListWallets(opts)
+}
+
+// SearchWallets finds wallets by partial address, originator, email or Telegram username
+func (n *Nuntiare) SearchWallets(query string, limit int) ([]*models.Wallet, error) {
+	return n.repo.SearchWallets(query, limit)
+}
+
+// ListNotifications returns a page of stored notifications
+func (n *Nuntiare) ListNotifications(opts models.ListOptions) (*models.Page[models.Notification], error) {
+	return n.repo.ListNotifications(opts)
+}
+
+// ListSubscriptionPayments returns a page of subscription payments
+func (n *Nuntiare) ListSubscriptionPayments(opts models.ListOptions) (*models.Page[models.SubscriptionPayment], error) {
+	return n.repo.ListSubscriptionPayments(opts)
+}
@@ -0,0 +1,56 @@
+package nuntiare
+
+import (
+	"time"
+)
+
+// MaintenanceRefreshInterval is how often the read-only maintenance mode is reloaded, so a switch made
+// through another instance is picked up
+const MaintenanceRefreshInterval = 10 * time.Second
+
+// IsReadOnly reports whether the API is in read-only maintenance mode, switched for all instances or
+// started with READ_ONLY_MODE
+func (n *Nuntiare) IsReadOnly() bool {
+	return n.config.ReadOnlyMode || n.readOnly.Load()
+}
+
+// SetReadOnly switches read-only maintenance mode of all instances and applies it right away
+func (n *Nuntiare) SetReadOnly(readOnly bool) error {
+	if err := n.repo.SetReadOnlyMode(readOnly, time.Now().Unix()); err != nil {
+		return err
+	}
+	n.readOnly.Store(readOnly)
+	return nil
+}
+
+// reloadReadOnlyMode loads the read-only maintenance mode, keeping the current one on failure
+func (n *Nuntiare) reloadReadOnlyMode() {
+	readOnly, err := n.repo.GetReadOnlyMode()
+	if err != nil {
+		n.logger.Error("Failed to reload maintenance mode", "error", err)
+		return
+	}
+	if n.readOnly.Swap(readOnly) != readOnly {
+		n.logger.Warn("Read-only maintenance mode switched", "read_only", readOnly)
+	}
+}
+
+// watchReadOnlyMode loads the read-only maintenance mode and reloads it until the instance stops
+func (n *Nuntiare) watchReadOnlyMode() {
+	n.reloadReadOnlyMode()
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(MaintenanceRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.reloadReadOnlyMode()
+			case <-n.ctx.Done():
+				n.logger.Debug("Maintenance mode refresh stopped")
+				return
+			}
+		}
+	}()
+}
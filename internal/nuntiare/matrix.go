@@ -0,0 +1,34 @@
+package nuntiare
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// matrixRoomIDRegex matches a Matrix room ID: "!", an opaque local part and the server name
+var matrixRoomIDRegex = regexp.MustCompile(`^![A-Za-z0-9._=/+-]+:[A-Za-z0-9.-]+(:[0-9]{1,5})?$`)
+
+// MaxMatrixRoomIDLength is the maximum length of a Matrix identifier
+const MaxMatrixRoomIDLength = 255
+
+// SetMatrix sets the Matrix room notifications of the wallet are posted to
+func (n *Nuntiare) SetMatrix(address, roomID string) error {
+	if err := n.ValidateMatrixRoom(roomID); err != nil {
+		return err
+	}
+	return n.repo.UpsertMatrixProvider(address, roomID)
+}
+
+// ValidateMatrixRoom returns ErrInvalidMatrixRoom unless the room ID is a Matrix room ID.
+// Rooms are refused when no Matrix bot account is configured.
+func (n *Nuntiare) ValidateMatrixRoom(roomID string) error {
+	if len(roomID) > MaxMatrixRoomIDLength || !matrixRoomIDRegex.MatchString(roomID) {
+		return fmt.Errorf("%w: room ID must look like !abc123:example.org", models.ErrInvalidMatrixRoom)
+	}
+	if n.config.MatrixHomeserverURL == "" {
+		return fmt.Errorf("%w: Matrix notifications are not supported", models.ErrInvalidMatrixRoom)
+	}
+	return nil
+}
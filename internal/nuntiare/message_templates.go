@@ -0,0 +1,48 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MessageTemplateRefreshInterval is how often the message template overrides are reloaded,
+// so changes made through another instance are picked up
+const MessageTemplateRefreshInterval = 5 * time.Minute
+
+// GetMessageTemplates returns the stored message template overrides
+func (n *Nuntiare) GetMessageTemplates() ([]*models.MessageTemplate, error) {
+	return n.repo.GetMessageTemplates()
+}
+
+// SetMessageTemplate validates and stores a message template override and applies it right away
+func (n *Nuntiare) SetMessageTemplate(lang, name, body string) (*models.MessageTemplate, error) {
+	if err := n.notificator.ValidateMessageTemplate(lang, name, body); err != nil {
+		return nil, err
+	}
+
+	messageTemplate := &models.MessageTemplate{Lang: lang, Name: name, Body: body, UpdatedAt: time.Now().Unix()}
+	if err := n.repo.UpsertMessageTemplate(messageTemplate); err != nil {
+		return nil, err
+	}
+	n.reloadMessageTemplates()
+	return messageTemplate, nil
+}
+
+// DeleteMessageTemplate removes a message template override, the embedded template is used again.
+// Returns false if the override doesn't exist.
+func (n *Nuntiare) DeleteMessageTemplate(lang, name string) (bool, error) {
+	deleted, err := n.repo.DeleteMessageTemplate(lang, name)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	n.reloadMessageTemplates()
+	return true, nil
+}
+
+// reloadMessageTemplates loads the message template overrides, keeping the current ones on failure
+func (n *Nuntiare) reloadMessageTemplates() {
+	if err := n.notificator.ReloadMessageTemplates(); err != nil {
+		n.logger.Error("Failed to reload message templates", "error", err)
+	}
+}
@@ -0,0 +1,238 @@
+package nuntiare
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// NFTMetadataTimeout limits fetching of NFT metadata documents
+	NFTMetadataTimeout = 10 * time.Second
+	// MaxNFTMetadataSize limits the size of NFT metadata documents
+	MaxNFTMetadataSize = 1 << 20 // 1 MB
+
+	// IPFSGateway is used to resolve ipfs:// URIs
+	IPFSGateway = "https://ipfs.io/ipfs/"
+
+	// NFTImageQueueSize is the number of NFT images buffered for the image resolver, more are dropped
+	NFTImageQueueSize = 1000
+	// NFTImageRetryInterval is how long an image that couldn't be resolved isn't tried again
+	NFTImageRetryInterval = 24 * time.Hour
+)
+
+// errPrivateMetadataHost is returned when a metadata URI resolves to a non-public address
+var errPrivateMetadataHost = errors.New("metadata host is not public")
+
+// nftMetadata is the subset of CBC721 metadata we use
+type nftMetadata struct {
+	Image string `json:"image"`
+}
+
+// nftImageRequest is a token whose image the resolver should look up
+type nftImageRequest struct {
+	tokenAddress string
+	tokenID      string
+}
+
+// newNFTMetadataClient returns the client metadata documents are fetched with. Unless allowPrivate is set
+// (development), requests to loopback, private and link-local addresses are refused, also after redirects.
+func newNFTMetadataClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: NFTMetadataTimeout}
+	if !allowPrivate {
+		// Checked on the resolved address so DNS names pointing to internal hosts are refused too
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsUnspecified() {
+				return errPrivateMetadataHost
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{Timeout: NFTMetadataTimeout, Transport: transport}
+}
+
+// GetNotification returns a stored notification by its public ID
+func (n *Nuntiare) GetNotification(id string) (*models.Notification, error) {
+	return n.repo.GetNotification(id)
+}
+
+// GetNFTImageURL returns the image URL of a CBC721 token resolved by the image resolver, empty when it wasn't
+// resolved (yet). It doesn't call the chain or the metadata host, tokens never resolved (e.g. of notifications
+// stored before images were resolved) are queued for the resolver.
+func (n *Nuntiare) GetNFTImageURL(tokenAddress, tokenID string) (string, error) {
+	image, err := n.repo.GetNFTImage(tokenAddress, tokenID)
+	if err != nil {
+		return "", err
+	}
+	if image == nil {
+		n.queueNFTImages(&models.Notification{TokenAddress: tokenAddress, TokenType: "CBC721", TokenID: tokenID})
+		return "", nil
+	}
+	return image.ImageURL, nil
+}
+
+// queueNFTImages hands the CBC721 tokens of the notification to the image resolver. Tokens are dropped when
+// the resolver is behind, their detail pages are shown without an image.
+func (n *Nuntiare) queueNFTImages(notification *models.Notification) {
+	if n.config.ShadowMode {
+		return
+	}
+
+	transfers := notification.Transfers
+	if len(transfers) == 0 {
+		transfers = []models.NotificationTransfer{{TokenAddress: notification.TokenAddress, TokenType: notification.TokenType, TokenID: notification.TokenID}}
+	}
+	for _, transfer := range transfers {
+		if transfer.TokenType != "CBC721" {
+			continue
+		}
+		request := nftImageRequest{tokenAddress: transfer.TokenAddress, tokenID: transfer.TokenID}
+		select {
+		case n.nftImageQueue <- request:
+		default:
+			n.logger.Debug("NFT image queue full, image dropped", "token", request.tokenAddress, "token_id", request.tokenID)
+		}
+	}
+}
+
+// processNFTImages is the image resolver. It resolves queued NFT images one at a time until shutdown.
+func (n *Nuntiare) processNFTImages() {
+	defer n.wg.Done()
+	for {
+		select {
+		case request := <-n.nftImageQueue:
+			n.resolveNFTImage(request)
+		case <-n.ctx.Done():
+			n.logger.Debug("NFT image resolver stopped")
+			return
+		}
+	}
+}
+
+// resolveNFTImage looks up the image of a token from its metadata and stores it. Tokens resolved before are
+// skipped, those without an image only after NFTImageRetryInterval.
+func (n *Nuntiare) resolveNFTImage(request nftImageRequest) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			n.logger.Error("NFT image resolution panicked", "token", request.tokenAddress, "panic", r, "stack", stack)
+			n.reportPanic("resolveNFTImage", r, stack)
+		}
+	}()
+
+	existing, err := n.repo.GetNFTImage(request.tokenAddress, request.tokenID)
+	if err != nil {
+		n.logger.Error("Failed to get NFT image", "error", err, "token", request.tokenAddress)
+		return
+	}
+	now := n.now()
+	if existing != nil && (existing.ImageURL != "" || now.Sub(time.Unix(existing.ResolvedAt, 0)) < NFTImageRetryInterval) {
+		return
+	}
+
+	image := &models.NFTImage{TokenAddress: request.tokenAddress, TokenID: request.tokenID, ResolvedAt: now.Unix()}
+	if image.ImageURL, err = n.lookupNFTImage(request.tokenAddress, request.tokenID); err != nil {
+		n.logger.Debug("Failed to resolve NFT image", "error", err, "token", request.tokenAddress, "token_id", request.tokenID)
+	}
+	if err := n.repo.SaveNFTImage(image); err != nil {
+		n.logger.Error("Failed to save NFT image", "error", err, "token", request.tokenAddress)
+	}
+}
+
+// lookupNFTImage resolves the image URL of a CBC721 token from its metadata
+func (n *Nuntiare) lookupNFTImage(tokenAddress, tokenID string) (string, error) {
+	id, ok := new(big.Int).SetString(strings.TrimPrefix(tokenID, "0x"), 16)
+	if !ok {
+		return "", fmt.Errorf("invalid token ID: %s", tokenID)
+	}
+
+	uri, err := n.gocore.GetCBC721TokenURI(tokenAddress, id)
+	if err != nil {
+		return "", err
+	}
+
+	metadata, err := n.fetchNFTMetadata(uri)
+	if err != nil {
+		return "", err
+	}
+
+	image := resolveIPFS(metadata.Image)
+	if !strings.HasPrefix(image, "http://") && !strings.HasPrefix(image, "https://") {
+		return "", fmt.Errorf("unsupported image URI: %s", metadata.Image)
+	}
+	return image, nil
+}
+
+// fetchNFTMetadata loads the metadata document from an http(s), ipfs or data URI
+func (n *Nuntiare) fetchNFTMetadata(uri string) (*nftMetadata, error) {
+	var body []byte
+
+	if strings.HasPrefix(uri, "data:application/json;base64,") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(uri, "data:application/json;base64,"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode metadata: %w", err)
+		}
+		body = decoded
+	} else {
+		url := resolveIPFS(uri)
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return nil, fmt.Errorf("unsupported metadata URI: %s", uri)
+		}
+
+		ctx, cancel := context.WithTimeout(n.ctx, NFTMetadataTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create metadata request: %w", err)
+		}
+		resp, err := n.nftClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch metadata: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+
+		body, err = io.ReadAll(io.LimitReader(resp.Body, MaxNFTMetadataSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read metadata: %w", err)
+		}
+	}
+
+	var metadata nftMetadata
+	if err := json.Unmarshal(body, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+	return &metadata, nil
+}
+
+// resolveIPFS rewrites ipfs:// URIs to the public gateway
+func resolveIPFS(uri string) string {
+	if strings.HasPrefix(uri, "ipfs://") {
+		return IPFSGateway + strings.TrimPrefix(strings.TrimPrefix(uri, "ipfs://"), "ipfs/")
+	}
+	return uri
+}
@@ -0,0 +1,48 @@
+package nuntiare
+
+import (
+	"encoding/json"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/core-coin/nuntiare/pkg/nats"
+)
+
+// newNotificationBus returns the NATS client notifications are published with, or nil when it isn't
+// configured. Shadow instances don't publish.
+func newNotificationBus(cfg *config.Config, logger *logger.Logger) *nats.Client {
+	if cfg.NATSServers == "" || cfg.NATSNotificationSubject == "" || cfg.ShadowMode {
+		return nil
+	}
+	natsConfig := cfg.NATSConfig()
+	natsConfig.ErrorHandler = func(err error) {
+		logger.Warn("NATS connection lost, reconnecting", "error", err)
+	}
+	return nats.NewClient(natsConfig)
+}
+
+// busNotificator publishes every notification to NATS once it was sent through the channels of the wallet
+type busNotificator struct {
+	models.NotificationService
+	n *Nuntiare
+}
+
+func (b *busNotificator) SendNotification(notification *models.Notification) {
+	b.NotificationService.SendNotification(notification)
+	b.n.publishNotification(notification)
+}
+
+// publishNotification publishes the notification to <NATS_NOTIFICATION_SUBJECT>.<event type>. Notifications
+// are not queued while the connection is down.
+func (n *Nuntiare) publishNotification(notification *models.Notification) {
+	subject := n.config.NATSNotificationSubject + "." + notification.EventType
+	data, err := json.Marshal(notification)
+	if err != nil {
+		n.logger.Error("Failed to encode NATS notification", "error", err, "subject", subject)
+		return
+	}
+	if err := n.bus.Publish(subject, data); err != nil {
+		n.logger.Warn("Failed to publish notification to NATS", "error", err, "subject", subject, "wallet", notification.Wallet)
+	}
+}
@@ -0,0 +1,96 @@
+package nuntiare
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/amqp"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+// notificationQueue dispatches notifications through a RabbitMQ queue: block processing only publishes them,
+// the instances with AMQP_WORKERS > 0 consume the queue and deliver them through the channels. The connection
+// outlives the other goroutines of the instance so the notifications delivered during the shutdown are still
+// acknowledged.
+type notificationQueue struct {
+	client *amqp.Client
+	queue  string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// newNotificationQueue returns the RabbitMQ queue notifications are dispatched through, or nil when it isn't
+// configured. Shadow instances don't send notifications.
+func newNotificationQueue(cfg *config.Config, logger *logger.Logger) *notificationQueue {
+	if cfg.AMQPURL == "" || cfg.ShadowMode {
+		return nil
+	}
+	amqpConfig := cfg.AMQPConfig()
+	amqpConfig.ErrorHandler = func(err error) {
+		logger.Warn("RabbitMQ connection lost, reconnecting", "error", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &notificationQueue{client: amqp.NewClient(amqpConfig), queue: cfg.AMQPQueue, ctx: ctx, cancel: cancel}
+	q.client.Declare(q.queue)
+	return q
+}
+
+// run keeps the connection to the broker until stop is called
+func (q *notificationQueue) run() {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.client.Run(q.ctx)
+	}()
+}
+
+// stop closes the connection, deliveries that weren't acknowledged yet go back to the queue
+func (q *notificationQueue) stop() {
+	q.cancel()
+	q.wg.Wait()
+}
+
+// queueNotificator publishes notifications to the queue instead of delivering them. Notifications that can't
+// be queued are delivered right away, so a broker outage delays nothing.
+type queueNotificator struct {
+	models.NotificationService
+	n *Nuntiare
+}
+
+func (q *queueNotificator) SendNotification(notification *models.Notification) {
+	data, err := json.Marshal(notification)
+	if err == nil {
+		err = q.n.queue.client.Publish(context.Background(), q.n.queue.queue, data)
+	}
+	if err != nil {
+		q.n.logger.Warn("Failed to queue notification, delivering it directly", "error", err, "wallet", notification.Wallet, "tx_hash", notification.TxHash)
+		q.NotificationService.SendNotification(notification)
+	}
+}
+
+// handleDelivery delivers a queued notification. The delivery is acknowledged once the notification went
+// through the channels, or panicked doing so, so a crashed worker's notifications are delivered by another
+// one while a poisonous notification isn't delivered over and over.
+func (q *queueNotificator) handleDelivery(delivery *amqp.Delivery) {
+	q.n.safeGo(func() {
+		var notification models.Notification
+		if err := json.Unmarshal(delivery.Body, &notification); err != nil {
+			q.n.logger.Error("Failed to decode queued notification, dropped", "error", err)
+			q.n.queue.client.Reject(delivery, false)
+			return
+		}
+		defer func() {
+			if err := q.n.queue.client.Ack(delivery); err != nil {
+				q.n.logger.Warn("Failed to acknowledge queued notification, it will be delivered again", "error", err, "wallet", notification.Wallet, "tx_hash", notification.TxHash)
+			}
+		}()
+		if delivery.Redelivered {
+			q.n.logger.Debug("Delivering redelivered notification", "wallet", notification.Wallet, "tx_hash", notification.TxHash)
+		}
+		q.NotificationService.SendNotification(&notification)
+	}, "deliver queued notification")
+}
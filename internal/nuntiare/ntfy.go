@@ -0,0 +1,46 @@
+package nuntiare
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// ntfyTopicRegex matches an ntfy topic name
+var ntfyTopicRegex = regexp.MustCompile(`^[-_A-Za-z0-9]{1,64}$`)
+
+// MaxNtfyTopicURLLength limits the length of an ntfy topic URL
+const MaxNtfyTopicURLLength = 2048
+
+// SetNtfy sets the ntfy topic notifications of the wallet are published to
+func (n *Nuntiare) SetNtfy(address, topicURL string) error {
+	if err := n.ValidateNtfyTopic(topicURL); err != nil {
+		return err
+	}
+	return n.repo.UpsertNtfyProvider(address, topicURL)
+}
+
+// ValidateNtfyTopic returns ErrInvalidNtfyTopic unless the URL is an absolute HTTPS URL (HTTP is allowed in
+// development) whose last path segment is the topic, e.g. https://ntfy.sh/my-wallet or a self-hosted server
+func (n *Nuntiare) ValidateNtfyTopic(topicURL string) error {
+	if len(topicURL) > MaxNtfyTopicURLLength {
+		return fmt.Errorf("%w: must have at most %d characters", models.ErrInvalidNtfyTopic, MaxNtfyTopicURLLength)
+	}
+	parsed, err := url.Parse(topicURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: must be an absolute URL", models.ErrInvalidNtfyTopic)
+	}
+	if parsed.User != nil || parsed.RawQuery != "" || parsed.Fragment != "" {
+		return fmt.Errorf("%w: must not contain credentials, query or fragment", models.ErrInvalidNtfyTopic)
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && n.config.Development) {
+		return fmt.Errorf("%w: must use https", models.ErrInvalidNtfyTopic)
+	}
+	if !ntfyTopicRegex.MatchString(path.Base(parsed.Path)) {
+		return fmt.Errorf("%w: must end in a topic name of letters, digits, - and _", models.ErrInvalidNtfyTopic)
+	}
+	return nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
+	"net/http"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/core-coin/go-core/v2/core/types"
@@ -16,7 +19,10 @@ import (
 	"github.com/core-coin/nuntiare/internal/blockchain"
 	"github.com/core-coin/nuntiare/internal/config"
 	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/kafka"
 	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/core-coin/nuntiare/pkg/nats"
+	"github.com/core-coin/nuntiare/pkg/validation"
 )
 
 const (
@@ -53,10 +59,14 @@ type Nuntiare struct {
 	config     *config.Config
 	instanceID string // Unique identifier for this instance (for HA distributed locking)
 
+	// Offset of the database clock from the local clock in nanoseconds, see syncClock
+	clockOffset atomic.Int64
+
 	repo        models.Repository
 	gocore      models.BlockchainService
 	notificator models.NotificationService
 	tokenCache  TokenCache
+	telegram    TelegramUpdateProcessor
 
 	// Context for graceful shutdown
 	ctx    context.Context
@@ -65,6 +75,54 @@ type Nuntiare struct {
 
 	// Semaphore to limit concurrent notification goroutines (prevents goroutine explosion)
 	notificationSem chan struct{}
+	// Subscription payments waiting for the payment worker, which doesn't share the notification semaphore,
+	// and closed once the worker credited the payments queued before the shutdown, nil if it wasn't started
+	paymentQueue chan *blockchain.Transfer
+	paymentsDone chan struct{}
+	// CBC721 tokens waiting for the image resolver, and the client their metadata is fetched with
+	nftImageQueue chan nftImageRequest
+	nftClient     *http.Client
+
+	// Blocks not processed while the database was unavailable, nil without SPILL_JOURNAL_PATH
+	spill *spillJournal
+	// Queues and counters of the processing pipeline of new blocks
+	pipeline *blockPipeline
+
+	// Chain progress reported by the status endpoint
+	headersSubscribed atomic.Bool
+	lastBlockNumber   atomic.Uint64
+	lastBlockTime     atomic.Uint64
+
+	// Whether an alert for blocks not arriving is active
+	blockLagAlerted atomic.Bool
+	// Recovered panics by stack signature
+	panics *panicRecorder
+	// Consecutive failures to acquire a lock by lock kind
+	lockFailuresMu sync.Mutex
+	lockFailures   map[string]int
+
+	// Current subscription month cost (float64 bits, 0 until fetched) and the time it was fetched
+	monthCost          atomic.Uint64
+	monthCostUpdatedAt atomic.Int64
+
+	// Trusted senders by normalized address, nil until loaded
+	trustedSenders atomic.Pointer[map[string]*models.TrustedSender]
+	// readOnly is the read-only maintenance mode switched for all instances
+	readOnly atomic.Bool
+	// Registered wallet and subscription addresses blocks are filtered with, nil when not configured
+	registered *registeredAddresses
+
+	// Compliance screening scores by normalized sender address
+	screeningsMu sync.Mutex
+	screenings   map[string]senderScreening
+
+	// Kafka event stream, nil when not configured
+	stream      *kafka.Producer
+	streamQueue chan *kafka.Message
+	// NATS connection notifications are published to, nil when not configured
+	bus *nats.Client
+	// RabbitMQ queue notifications are dispatched through, nil when not configured
+	queue *notificationQueue
 }
 
 // generateInstanceID creates a unique identifier for this instance
@@ -83,6 +141,7 @@ func NewNuntiare(
 	gocore models.BlockchainService,
 	notificator models.NotificationService,
 	tokenCache TokenCache,
+	telegram TelegramUpdateProcessor,
 	logger *logger.Logger,
 	config *config.Config,
 ) models.NuntiareI {
@@ -91,18 +150,59 @@ func NewNuntiare(
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Nuntiare{
+	var spill *spillJournal
+	if config.SpillJournalPath != "" {
+		spill = &spillJournal{path: config.SpillJournalPath}
+	}
+
+	stream, streamQueue := newEventStream(&kafka.Config{
+		Brokers:  config.KafkaBrokers,
+		ClientID: config.KafkaClientID,
+		TLS:      config.KafkaTLS,
+		Username: config.KafkaUsername,
+		Password: config.KafkaPassword,
+		Timeout:  time.Duration(config.KafkaTimeoutSeconds) * time.Second,
+	}, config.ShadowMode)
+
+	n := &Nuntiare{
 		repo:            repo,
 		gocore:          gocore,
 		logger:          logger,
 		notificator:     notificator,
 		tokenCache:      tokenCache,
+		telegram:        telegram,
 		config:          config,
 		instanceID:      instanceID,
 		ctx:             ctx,
 		cancel:          cancel,
 		notificationSem: make(chan struct{}, MaxConcurrentNotifications),
+		paymentQueue:    make(chan *blockchain.Transfer, PaymentQueueSize),
+		nftImageQueue:   make(chan nftImageRequest, NFTImageQueueSize),
+		nftClient:       newNFTMetadataClient(config.Development),
+		lockFailures:    make(map[string]int),
+		screenings:      make(map[string]senderScreening),
+		panics:          newPanicRecorder(),
+		spill:           spill,
+		pipeline:        newBlockPipeline(config.BlockProcessingConcurrency),
+		stream:          stream,
+		streamQueue:     streamQueue,
+		bus:             newNotificationBus(config, logger),
+		queue:           newNotificationQueue(config, logger),
+	}
+	if n.bus != nil {
+		n.notificator = &busNotificator{NotificationService: n.notificator, n: n}
+	}
+	if n.queue != nil {
+		queued := &queueNotificator{NotificationService: n.notificator, n: n}
+		if config.AMQPWorkers > 0 {
+			n.queue.client.Consume(config.AMQPQueue, config.AMQPWorkers, queued.handleDelivery)
+		}
+		n.notificator = queued
 	}
+	if config.AddressSetRefreshSeconds > 0 {
+		n.registered = newRegisteredAddresses(time.Duration(config.AddressSetRefreshSeconds) * time.Second)
+	}
+	return n
 }
 
 // Stop gracefully stops the Nuntiare instance
@@ -110,6 +210,14 @@ func (n *Nuntiare) Stop() {
 	n.logger.Info("Stopping Nuntiare instance", "instance_id", n.instanceID)
 	n.cancel() // Signal all goroutines to stop
 	n.wg.Wait() // Wait for all goroutines to finish
+	if n.paymentsDone != nil {
+		// Nothing queues payments anymore, the worker credits the queued ones and stops
+		close(n.paymentQueue)
+		<-n.paymentsDone
+	}
+	if n.queue != nil {
+		n.queue.stop()
+	}
 	n.logger.Info("Nuntiare instance stopped", "instance_id", n.instanceID)
 }
 
@@ -120,10 +228,12 @@ func (n *Nuntiare) safeGo(fn func(), description string) {
 		defer n.wg.Done() // Always decrement WaitGroup counter when goroutine exits
 		defer func() {
 			if r := recover(); r != nil {
+				stack := string(debug.Stack())
 				n.logger.Error("Goroutine panicked",
 					"description", description,
 					"panic", r,
-					"stack", string(debug.Stack()))
+					"stack", stack)
+				n.reportPanic(description, r, stack)
 			}
 		}()
 
@@ -144,17 +254,24 @@ func (n *Nuntiare) safeGo(fn func(), description string) {
 // Returns the wallet and whether it should be notified
 // Optimization: Skips subscription check for whitelisted wallets
 func (n *Nuntiare) shouldNotifyWallet(address string) (*models.Wallet, bool, error) {
-	exists, err := n.IsRegistered(address)
+	wallet, err := n.repo.LookupWallet(address, true)
 	if err != nil {
-		return nil, false, fmt.Errorf("failed to check registration: %w", err)
+		return nil, false, fmt.Errorf("failed to look up wallet: %w", err)
 	}
-	if !exists {
+	if wallet == nil {
 		return nil, false, nil
 	}
 
-	wallet, err := n.repo.GetWallet(address)
-	if err != nil {
-		return nil, false, fmt.Errorf("failed to get wallet: %w", err)
+	// A cached wallet may miss the updates of other instances. Wallets are notified on the cached record, but
+	// one that looks cancelled or expired is read again, so a reactivation or renewal isn't missed and the
+	// expiration below isn't recorded on an outdated record.
+	if n.config.WalletCacheSize > 0 && (!wallet.Active || !wallet.Whitelisted && !n.subscriptionActive(wallet.SubscriptionExpiresAt, n.now().Unix())) {
+		if wallet, err = n.repo.LookupWallet(address, false); err != nil {
+			return nil, false, fmt.Errorf("failed to look up wallet: %w", err)
+		}
+		if wallet == nil {
+			return nil, false, nil
+		}
 	}
 
 	// Check if wallet is active (not cancelled)
@@ -186,6 +303,124 @@ func weiToXCB(wei *big.Int) float64 {
 
 // Start starts the Nuntiare application
 func (n *Nuntiare) Start() {
+	// Subscriptions are timed on the database clock, shadow instances check them as well
+	n.syncClock()
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(ClockSyncInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.syncClock()
+			case <-n.ctx.Done():
+				n.logger.Debug("Clock sync stopped")
+				return
+			}
+		}
+	}()
+
+	// Load the registered addresses before the first block, shadow instances filter blocks as well
+	n.watchRegisteredAddresses()
+	// Every instance serving the API follows the maintenance mode
+	n.watchReadOnlyMode()
+
+	// Shadow instances only watch blocks, maintenance jobs are left to production
+	if n.config.ShadowMode {
+		n.logger.Warn("Running in shadow mode, notifications are recorded but not sent", "instance_id", n.instanceID)
+		n.reloadTrustedSenders()
+		n.wg.Add(1)
+		go n.WatchTransfers()
+		return
+	}
+
+	// Publish detected transfers and wallet events to Kafka
+	if n.stream != nil {
+		n.wg.Add(1)
+		go n.runEventStream()
+	}
+	// Publish notifications to NATS
+	if n.bus != nil {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			n.bus.Run(n.ctx)
+		}()
+	}
+	// Dispatch notifications through RabbitMQ
+	if n.queue != nil {
+		n.queue.run()
+	}
+
+	// Fetch the subscription price before crediting payments, and refresh it periodically
+	if n.config.SubscriptionPriceSource != PriceSourceStatic {
+		n.refreshSubscriptionPrice()
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			ticker := time.NewTicker(time.Duration(n.config.SubscriptionPriceRefreshMinutes) * time.Minute)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					n.refreshSubscriptionPrice()
+				case <-n.ctx.Done():
+					n.logger.Debug("Subscription price refresh stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	// Load the message template overrides before the first notification, and reload them periodically
+	n.reloadMessageTemplates()
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(MessageTemplateRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.reloadMessageTemplates()
+			case <-n.ctx.Done():
+				n.logger.Debug("Message template refresh stopped")
+				return
+			}
+		}
+	}()
+	if n.config.LanguagePackDir != "" {
+		n.watchLanguagePacks()
+	}
+
+	// Load the trusted senders before the first notification, and reload them periodically
+	n.reloadTrustedSenders()
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(TrustedSenderRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.reloadTrustedSenders()
+			case <-n.ctx.Done():
+				n.logger.Debug("Trusted sender refresh stopped")
+				return
+			}
+		}
+	}()
+
+	// Subscription payments are credited on their own lane so notification backlog can't delay them. The
+	// worker outlives the other goroutines, which may still queue payments while stopping.
+	n.paymentsDone = make(chan struct{})
+	go n.processPayments()
+
+	// NFT images are resolved in the background so detail pages don't call the chain or metadata hosts
+	n.wg.Add(1)
+	go n.processNFTImages()
+
 	// Start a goroutine to clean up unpaid subscriptions
 	n.wg.Add(1)
 	go func() {
@@ -196,8 +431,22 @@ func (n *Nuntiare) Start() {
 			select {
 			case <-ticker.C:
 				n.logger.Debug("Cleaning up unpaid subscriptions")
-				gracePeriod := time.Now().Unix() - int64(UnpaidSubscriptionGracePeriod.Seconds())
-				err := n.repo.RemoveUnpaidSubscriptions(gracePeriod)
+				gracePeriod := n.now().Unix() - int64(UnpaidSubscriptionGracePeriod.Seconds())
+				err := n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+					removed, err := repo.RemoveUnpaidSubscriptions(gracePeriod)
+					if err != nil {
+						return nil, err
+					}
+					events := make([]*models.WalletEvent, len(removed))
+					for i, address := range removed {
+						events[i] = &models.WalletEvent{
+							Wallet: address,
+							Type:   models.WalletEventRemoved,
+							Reason: models.WalletEventReasonCleanup,
+						}
+					}
+					return events, nil
+				})
 				if err != nil {
 					n.logger.Error("Failed to remove unpaid subscriptions", "error", err)
 				}
@@ -208,6 +457,80 @@ func (n *Nuntiare) Start() {
 		}
 	}()
 
+	// Start a goroutine to restore subscriptions whose payment was missed, e.g. during downtime
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		// Run once right away, the instance may just be back from downtime
+		n.verifyUnpaidSubscriptions()
+		ticker := time.NewTicker(ResubscriptionSweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.verifyUnpaidSubscriptions()
+			case <-n.ctx.Done():
+				n.logger.Debug("Resubscription sweep stopped")
+				return
+			}
+		}
+	}()
+
+	// Start a goroutine to alert operators when the receiving address should be swept
+	if n.config.ReceivingBalanceThreshold > 0 {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			ticker := time.NewTicker(ReceivingBalanceCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					n.checkReceivingBalanceThreshold()
+				case <-n.ctx.Done():
+					n.logger.Debug("Receiving balance monitoring stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	// Start a goroutine to alert operators when blocks stop arriving
+	if n.config.BlockLagAlertSeconds > 0 {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			ticker := time.NewTicker(BlockLagCheckInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					n.checkBlockLag()
+				case <-n.ctx.Done():
+					n.logger.Debug("Block lag monitoring stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	// Start a goroutine to summarize repeated panics to the ops destinations
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(PanicSummaryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.summarizePanics()
+			case <-n.ctx.Done():
+				n.logger.Debug("Panic summaries stopped")
+				return
+			}
+		}
+	}()
+
 	// HA: Start a goroutine to cleanup expired locks
 	n.wg.Add(1)
 	go func() {
@@ -228,45 +551,321 @@ func (n *Nuntiare) Start() {
 		}
 	}()
 
+	// Start a goroutine to remove devices that haven't been seen for a long time
+	if n.config.DeviceStaleDays > 0 {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			ticker := time.NewTicker(StaleDeviceCleanupInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					n.removeStaleDevices()
+				case <-n.ctx.Done():
+					n.logger.Debug("Stale device cleanup stopped")
+					return
+				}
+			}
+		}()
+	}
+
+	// Start a goroutine to remove records past their retention period
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(RetentionInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.applyRetention()
+			case <-n.ctx.Done():
+				n.logger.Debug("Retention cleanup stopped")
+				return
+			}
+		}
+	}()
+
+	// Start a goroutine to keep the notification rollups up to date
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(RollupInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.aggregateRollups()
+			case <-n.ctx.Done():
+				n.logger.Debug("Notification rollups stopped")
+				return
+			}
+		}
+	}()
+
+	// Start a goroutine to dispatch scheduled notifications when they are due
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(ScheduledNotificationInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.dispatchScheduledNotifications()
+			case <-n.ctx.Done():
+				n.logger.Debug("Scheduled notifications stopped")
+				return
+			}
+		}
+	}()
+
+	// Start a goroutine to retry failed deliveries with the backoff of their channel
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(DeliveryRetryInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.retryDeliveries()
+			case <-n.ctx.Done():
+				n.logger.Debug("Delivery retries stopped")
+				return
+			}
+		}
+	}()
+
+	// Start a goroutine to confirm exchange deposits and deliver them to the exchanges' webhooks
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(ExchangeDepositInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.processExchangeDeposits()
+			case <-n.ctx.Done():
+				n.logger.Debug("Exchange deposits stopped")
+				return
+			}
+		}
+	}()
+
+	// Start a goroutine to ask wallets using an outdated app to upgrade
+	if n.config.SendUpgradeNotifications && len(n.config.MinAppVersions) > 0 {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			ticker := time.NewTicker(UpgradeNotificationInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					n.notifyOutdatedApps()
+				case <-n.ctx.Done():
+					n.logger.Debug("Upgrade notifications stopped")
+					return
+				}
+			}
+		}()
+	}
+
 	// Start watching for new transactions (handles connection retries internally)
 	n.wg.Add(1)
 	go n.WatchTransfers()
+
+	if n.config.PendingTransactionAlerts {
+		n.wg.Add(1)
+		go n.WatchPendingTransactions()
+	}
+
+	// Replay blocks spilled while the database was unavailable, including the ones journaled before a restart
+	if n.spill != nil {
+		n.wg.Add(1)
+		go func() {
+			defer n.wg.Done()
+			ticker := time.NewTicker(SpillReplayInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					n.replaySpilledBlocks()
+				case <-n.ctx.Done():
+					n.logger.Debug("Spilled block replay stopped")
+					return
+				}
+			}
+		}()
+	}
 }
 
-// RegisterNewWallet adds a new wallet to the repository
-func (n *Nuntiare) RegisterNewWallet(wallet *models.Wallet) error {
+// RegisterNewWallet adds a new wallet to the repository and returns it with true. Registering an address
+// again is a no-op returning the stored wallet with false when the OriginID is the wallet's or a linked app's,
+// and ErrOriginMismatch otherwise. Several wallets can be registered with the same subscription address, see
+// checkSubscriptionAddress.
+func (n *Nuntiare) RegisterNewWallet(wallet *models.Wallet, subscriberProof *models.OwnershipProof) (*models.Wallet, bool, error) {
 	// err := n.CheckWalletInitialSubscription(wallet.SubscriptionAddress)
 	// if err != nil {
 	// 	n.logger.Error("failed to check wallet initial subscription", "error", err)
 	// 	return fmt.Errorf("failed to check wallet initial subscription: %s", err) // todo:error2215 do we need to terminate the registration process if the initial subscription check fails?
 	// }
 
-	return n.repo.AddNewWallet(wallet)
+	if err := n.checkSubscriptionAddress(wallet, subscriberProof); err != nil {
+		return nil, false, err
+	}
+
+	// Registrations are timed on the database clock, the unpaid wallet cleanup compares them across instances
+	wallet.CreatedAt = n.now().Unix()
+	err := n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+		if err := repo.AddNewWallet(wallet); err != nil {
+			return nil, err
+		}
+		return []*models.WalletEvent{{
+			Wallet:                wallet.Address,
+			Type:                  models.WalletEventRegistered,
+			Reason:                models.WalletEventReasonRegistration,
+			SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+			Timestamp:             wallet.CreatedAt,
+		}}, nil
+	})
+	if errors.Is(err, models.ErrWalletExists) {
+		existing, err := n.repo.GetWallet(wallet.Address)
+		if err != nil {
+			return nil, false, err
+		}
+		if !n.IsWalletOrigin(existing, wallet.OriginID) {
+			return nil, false, models.ErrOriginMismatch
+		}
+		return existing, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	n.registered.add(wallet.Address, wallet.SubscriptionAddress)
+	if wallet.NotificationProvider.EmailProvider.Email != "" {
+		n.safeGo(func() { n.requestEmailVerification(wallet.Address) }, "requestEmailVerification")
+	}
+	return wallet, true, nil
+}
+
+// checkSubscriptionAddress checks that a new wallet may be paid for from its subscription address. Payments
+// from a shared address are split between its wallets, so a wallet only joins the wallets of another app or
+// user when the registrant proves owning the address. Returns ErrSubscriptionAddressTaken if one of the
+// wallets using the address isn't registered by the same app, an app linked to it or the same user, and
+// ErrInvalidSignature for a proof not signed with the key of the address.
+func (n *Nuntiare) checkSubscriptionAddress(wallet *models.Wallet, subscriberProof *models.OwnershipProof) error {
+	// A registered wallet registering again keeps its subscription address, its origin is checked instead
+	exists, err := n.repo.CheckWalletExists(wallet.Address)
+	if err != nil {
+		return fmt.Errorf("failed to check wallet exists: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	wallets, err := n.repo.GetWalletsBySubscriptionAddress(wallet.SubscriptionAddress)
+	if err != nil {
+		return fmt.Errorf("failed to get wallets by subscription address: %w", err)
+	}
+
+	foreign := false
+	for _, existing := range wallets {
+		if !n.IsWalletOrigin(existing, wallet.OriginID) && (wallet.UserID == "" || existing.UserID != wallet.UserID) {
+			foreign = true
+			break
+		}
+	}
+	if !foreign {
+		return nil
+	}
+	if subscriberProof == nil {
+		return models.ErrSubscriptionAddressTaken
+	}
+	return blockchain.VerifySignedMessage(wallet.SubscriptionAddress, subscriberProof.Message, subscriberProof.Signature)
+}
+
+// GetNotificationProvider returns the notification providers of a wallet
+func (n *Nuntiare) GetNotificationProvider(address string) (*models.NotificationProvider, error) {
+	return n.repo.GetWalletsNotificationProvider(address)
 }
 
 // UpdateNotificationProvider updates notification providers for an existing wallet
-func (n *Nuntiare) UpdateNotificationProvider(address, telegram, email string) error {
-	return n.repo.UpdateNotificationProvider(address, telegram, email)
+func (n *Nuntiare) UpdateNotificationProvider(address, telegram, email, fcmToken string) error {
+	return n.repo.UpdateNotificationProvider(address, telegram, email, fcmToken)
 }
 
 // UpdateNotificationProviderAndReactivate updates notification providers and reactivates wallet
-func (n *Nuntiare) UpdateNotificationProviderAndReactivate(address, telegram, email string) error {
-	// Update notification providers
-	if err := n.repo.UpdateNotificationProvider(address, telegram, email); err != nil {
+func (n *Nuntiare) UpdateNotificationProviderAndReactivate(address, telegram, email, fcmToken string) error {
+	wallet, err := n.repo.GetWallet(address)
+	if err != nil {
 		return err
 	}
 
-	// Reactivate wallet (in case it was cancelled)
-	if err := n.repo.SetWalletActive(address, true); err != nil {
+	err = n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+		// Update notification providers
+		if err := repo.UpdateNotificationProvider(address, telegram, email, fcmToken); err != nil {
+			return nil, err
+		}
+
+		// Reactivate wallet (in case it was cancelled)
+		if err := repo.SetWalletActive(address, true); err != nil {
+			return nil, err
+		}
+		if wallet.Active {
+			return nil, nil
+		}
+		return []*models.WalletEvent{{
+			Wallet:                wallet.Address,
+			Type:                  models.WalletEventReactivated,
+			Reason:                models.WalletEventReasonUser,
+			SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+		}}, nil
+	})
+	if err != nil {
 		return err
 	}
 
+	// A new email has to be verified; an unverified one gets a new link
+	if email != "" {
+		n.safeGo(func() { n.requestEmailVerification(address) }, "requestEmailVerification")
+	}
+
 	return nil
 }
 
+// SetMutedEventTypes sets the event types the wallet is not notified about (empty unmutes all)
+func (n *Nuntiare) SetMutedEventTypes(address string, eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !models.IsEventType(eventType) {
+			return fmt.Errorf("%w: %q", models.ErrInvalidEventType, eventType)
+		}
+	}
+	return n.repo.SetMutedEventTypes(address, eventTypes)
+}
+
 // CancelWallet deactivates notifications while keeping subscription active
 func (n *Nuntiare) CancelWallet(address string) error {
-	return n.repo.SetWalletActive(address, false)
+	wallet, err := n.repo.GetWallet(address)
+	if err != nil {
+		return err
+	}
+	return n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+		if err := repo.SetWalletActive(address, false); err != nil {
+			return nil, err
+		}
+		if !wallet.Active {
+			return nil, nil
+		}
+		return []*models.WalletEvent{{
+			Wallet:                wallet.Address,
+			Type:                  models.WalletEventCancelled,
+			Reason:                models.WalletEventReasonUser,
+			SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+		}}, nil
+	})
 }
 
 // IsRegistered checks if the given address is registered
@@ -305,6 +904,11 @@ func (n *Nuntiare) WatchTransfers() {
 		break
 	}
 
+	// Blocks missed while the instance was down are backfilled before following new heads, blocks missed while
+	// the node connection was down are caught up once the next head arrives
+	n.startPipeline()
+	lastSubmitted := n.backfill(n.lastProcessedBlock())
+
 	// Now start watching for transfers
 	for {
 		subscription, channel, err := n.gocore.NewHeaderSubscription()
@@ -325,10 +929,12 @@ func (n *Nuntiare) WatchTransfers() {
 		// Reset backoff on successful connection
 		backoff = InitialBackoff
 		n.logger.Info("Successfully subscribed to blockchain headers")
+		n.headersSubscribed.Store(true)
 
 		// Process headers with proper cleanup
 		func() {
 			defer subscription.Unsubscribe()
+			defer n.headersSubscribed.Store(false)
 
 			for {
 				select {
@@ -340,17 +946,13 @@ func (n *Nuntiare) WatchTransfers() {
 					}
 
 					n.logger.Debug("New block header received", "number", header.Number)
+					number := header.Number.Uint64()
+					n.lastBlockNumber.Store(number)
+					n.lastBlockTime.Store(header.Time)
 
-					// Check if the block has transactions
-					if !header.EmptyBody() {
-						n.logger.Debug("Block has transactions")
-						block, err := n.gocore.GetBlockByNumber(header.Number.Uint64())
-						if err != nil {
-							n.logger.Error("Failed to get block by number", "number", header.Number, "error", err)
-							continue
-						}
-						n.checkBlock(block)
-					}
+					// Blocks are fetched, scanned and dispatched by the pipeline, the header loop only waits
+					// while the pipeline is full
+					lastSubmitted = n.submitBlocks(lastSubmitted, header)
 
 				case err := <-subscription.Err():
 					// Subscription error (connection dropped, etc.)
@@ -393,149 +995,288 @@ func (n *Nuntiare) WatchTransfers() {
 
 }
 
+// checkBlock processes a block outside the pipeline (replaying spilled blocks) unless
+// another instance processes it
 func (n *Nuntiare) checkBlock(block *types.Block) {
-	// HA: Try to acquire distributed lock for this block processing
-	// Lock name includes block number to allow different instances to process different blocks
-	// TTL is 30 seconds - if processing takes longer, another instance can take over
-	lockName := fmt.Sprintf("block_processor_%d", block.NumberU64())
-	acquired, err := n.repo.TryAcquireLock(lockName, n.instanceID, 30)
-	if err != nil {
-		n.logger.Error("Failed to acquire lock for block processing", "block", block.NumberU64(), "error", err)
+	release, ok := n.claimBlock(block.NumberU64())
+	if !ok {
 		return
 	}
-	if !acquired {
-		// Another instance is processing this block, skip it
-		n.logger.Debug("Block already being processed by another instance", "block", block.NumberU64())
-		return
+	defer release()
+
+	n.processBlock(block)
+	n.markBlockProcessed(block.NumberU64())
+}
+
+// processBlock detects the transfers in the block and handles them in the background
+func (n *Nuntiare) processBlock(block *types.Block) {
+	n.dispatchTransfers(n.collectTransfers(block))
+}
+
+// collectTransfers detects the transfers in the block
+func (n *Nuntiare) collectTransfers(block *types.Block) *scannedBlock {
+	n.logger.Debug("Processing block", "block", block.NumberU64(), "instance", n.instanceID)
+
+	scanned := &scannedBlock{number: block.NumberU64(), hash: block.Hash().String()}
+	n.scanBlock(block, func(transfers []*blockchain.Transfer) {
+		scanned.tokenTransfers = append(scanned.tokenTransfers, transfers)
+	}, func(tx *types.Transaction) {
+		scanned.xcbTransfers = append(scanned.xcbTransfers, tx)
+	})
+	return scanned
+}
+
+// blockTransfers returns the transfers detected in the block: the token transfers and XCB sent by contracts,
+// followed by the plain XCB transfers
+func (n *Nuntiare) blockTransfers(scanned *scannedBlock) []*blockchain.Transfer {
+	var transfers []*blockchain.Transfer
+	for _, txTransfers := range scanned.tokenTransfers {
+		transfers = append(transfers, txTransfers...)
+	}
+	for _, tx := range scanned.xcbTransfers {
+		if transfer := n.xcbTransfer(tx); transfer != nil {
+			transfers = append(transfers, transfer)
+		}
+	}
+	return transfers
+}
+
+// xcbTransfer returns a plain XCB transfer in the format of token transfers, or nil for contract creations
+func (n *Nuntiare) xcbTransfer(tx *types.Transaction) *blockchain.Transfer {
+	if tx.To() == nil {
+		return nil
 	}
+	from, err := blockchain.TransactionSender(tx)
+	if err != nil {
+		n.logger.Warn("Failed to recover XCB transfer sender", "tx", tx.Hash().String(), "error", err)
+	}
+	return &blockchain.Transfer{
+		From:        from,
+		To:          validation.NormalizeAddress(tx.To().Hex()),
+		Amount:      weiToXCB(tx.Value()),
+		Value:       tx.Value().String(),
+		TokenSymbol: "XCB",
+		TxHash:      tx.Hash().String(),
+		NetworkID:   n.config.NetworkID.Int64(),
+	}
+}
 
-	// Release lock when done
-	defer func() {
-		if err := n.repo.ReleaseLock(lockName, n.instanceID); err != nil {
-			n.logger.Error("Failed to release lock", "block", block.NumberU64(), "error", err)
+// dispatchTransfers queues the subscription payments among the block's transfers, records the exchange
+// deposits, publishes the transfers to the event stream and sends the notifications and fulfills the payment
+// requests in the background. Exchange deposits are recorded in block order, as their webhooks are delivered
+// in chain order per address.
+func (n *Nuntiare) dispatchTransfers(scanned *scannedBlock) {
+	n.recordExchangeDeposits(scanned)
+	n.streamTransfers(scanned)
+	for _, transfers := range scanned.tokenTransfers {
+		n.enqueuePayments(transfers)
+		// Transactions involving no registered address notify nobody, they aren't looked up
+		if !n.registered.touches(transfers) {
+			continue
 		}
-	}()
+		n.safeGo(func() { n.processTokenTransfers(transfers) }, "processTokenTransfers")
+		n.safeGo(func() { n.processPaymentRequestTransfers(transfers) }, "processPaymentRequestTransfers")
+	}
+	for _, tx := range scanned.xcbTransfers {
+		// Only the recipient of an XCB transfer is notified, the sender isn't recovered for the check
+		if !n.registered.contains(tx.To().Hex()) {
+			continue
+		}
+		n.safeGo(func() { n.processXCBTransfer(tx) }, "processXCBTransfer")
+		n.safeGo(func() { n.processPaymentRequestXCBTransfer(tx) }, "processPaymentRequestXCBTransfer")
+	}
+}
 
-	n.logger.Debug("Processing block", "block", block.NumberU64(), "instance", n.instanceID)
+// scanBlock detects token and XCB transfers in the block's transactions and passes them to the handlers.
+// onTokenTransfers is called once per transaction with token transfers, onXCBTransfer for plain XCB transfers.
+// XCB sent by contracts is passed to onTokenTransfers along with the transaction's token transfers.
+func (n *Nuntiare) scanBlock(block *types.Block, onTokenTransfers func([]*blockchain.Transfer), onXCBTransfer func(*types.Transaction)) {
+	tokensByAddress := n.watchedTokens()
+	traces := n.traceBlock(block)
+	// Token transfers carry the block timestamp, subscription payments are timed by it
+	timestamp := int64(block.Time())
+	for i, tx := range block.Body().Transactions {
+		var transfers []*blockchain.Transfer
+		n.scanTransaction(tx, tokensByAddress, true, func(found []*blockchain.Transfer) {
+			transfers = append(transfers, found...)
+		}, onXCBTransfer)
+		if traces != nil {
+			if contractTransfers := blockchain.CheckForContractXCBTransfers(tx, traces[i], n.config.NetworkID.Int64()); len(contractTransfers) > 0 {
+				n.logger.Debug("Contract XCB transfers detected", "tx", tx.Hash().String(), "transfers", len(contractTransfers))
+				transfers = append(transfers, contractTransfers...)
+			}
+		}
+		if len(transfers) == 0 {
+			continue
+		}
+		for _, transfer := range transfers {
+			transfer.Timestamp = timestamp
+		}
+		onTokenTransfers(transfers)
+	}
+}
 
+// traceBlock returns the call traces of the block's transactions, or nil when contract transfers aren't traced
+// or the block couldn't be traced
+func (n *Nuntiare) traceBlock(block *types.Block) []*models.CallFrame {
+	if !n.config.TraceContractTransfers || len(block.Transactions()) == 0 {
+		return nil
+	}
+	traces, err := n.gocore.TraceBlockCalls(block)
+	if err != nil {
+		n.logger.Error("Failed to trace block, XCB sent by contracts is not notified", "block", block.NumberU64(), "error", err)
+		return nil
+	}
+	return traces
+}
+
+// watchedTokens returns the tokens of the registry by normalized contract address
+func (n *Nuntiare) watchedTokens() map[string]*models.Token {
 	// Get all watched tokens from in-memory cache
 	tokens := n.tokenCache.GetAllTokens()
 
 	// Build address->token map for O(1) lookup instead of O(n) iteration
-	// Normalize all addresses to lowercase for consistent lookups
+	// Normalize all addresses for consistent lookups
 	tokensByAddress := make(map[string]*models.Token, len(tokens))
 	for _, token := range tokens {
-		tokensByAddress[strings.ToLower(token.Address)] = token
+		tokensByAddress[validation.NormalizeAddress(token.Address)] = token
 	}
+	return tokensByAddress
+}
 
-	for _, tx := range block.Body().Transactions {
-		// Skip contract creation transactions
-		if tx.To() == nil {
-			continue
-		}
+// scanTransaction detects the token or XCB transfers of a transaction. CBC721 transfers are only visible in
+// the receipt, without mined (receipts false) they are not detected.
+func (n *Nuntiare) scanTransaction(tx *types.Transaction, tokensByAddress map[string]*models.Token, receipts bool,
+	onTokenTransfers func([]*blockchain.Transfer), onXCBTransfer func(*types.Transaction)) {
+	// Skip contract creation transactions
+	if tx.To() == nil {
+		return
+	}
 
-		receiver := tx.To().Hex()
-		// Normalize receiver address for lookups (remove 0x prefix and lowercase)
-		receiverNormalized := receiver
-		if len(receiverNormalized) > 2 && receiverNormalized[:2] == "0x" {
-			receiverNormalized = receiverNormalized[2:]
-		}
-		receiverNormalized = strings.ToLower(receiverNormalized)
+	// Normalize receiver address for lookups (remove 0x prefix and lowercase)
+	receiverNormalized := validation.NormalizeAddress(tx.To().Hex())
 
-		n.logger.Debug("Processing transaction", "tx", tx.Hash().String(), "to", receiverNormalized)
-		var allTransfers []*blockchain.Transfer
-		// Use cached normalized address for efficient comparison
-		isCTNContract := receiverNormalized == n.config.SmartContractAddressNormalized
+	n.logger.Debug("Processing transaction", "tx", tx.Hash().String(), "to", receiverNormalized)
+	var allTransfers []*blockchain.Transfer
+	// Use cached normalized address for efficient comparison
+	isCTNContract := receiverNormalized == n.config.SmartContractAddressNormalized
 
-		// Check for CTN transfers (for subscription payments)
-		if isCTNContract {
-			ctnTransfers, err := blockchain.CheckForCTNTransfer(tx, n.config.SmartContractAddress, n.config.NetworkID.Int64())
-			if err != nil {
-				n.logger.Error("Failed to check for CTN transfer", "error", err)
-			} else if len(ctnTransfers) > 0 {
-				n.logger.Debug("CTN transfer detected", "tx", tx.Hash().String())
-				allTransfers = append(allTransfers, ctnTransfers...)
-			}
+	// Check for CTN transfers (for subscription payments)
+	if isCTNContract {
+		ctnTransfers, err := blockchain.CheckForCTNTransfer(tx, n.config.SmartContractAddress, n.config.NetworkID.Int64())
+		if err != nil {
+			n.logger.Error("Failed to check for CTN transfer", "error", err)
+		} else if len(ctnTransfers) > 0 {
+			n.logger.Debug("CTN transfer detected", "tx", tx.Hash().String())
+			allTransfers = append(allTransfers, ctnTransfers...)
 		}
+	}
 
-		// O(1) lookup for token by address instead of O(n) iteration
-		// Skip if already processed as CTN contract to avoid duplicate notifications
-		if !isCTNContract {
-			if token, exists := tokensByAddress[receiverNormalized]; exists {
-				n.logger.Debug("Token found in cache", "token", token.Symbol, "type", token.Type, "address", token.Address)
-				var transfers []*blockchain.Transfer
-				var err error
-
-				if token.Type == "CBC20" {
-					transfers, err = blockchain.CheckForCBC20Transfer(tx, token.Address, token.Symbol, token.Decimals, n.config.NetworkID.Int64())
-				} else if token.Type == "CBC721" {
-					n.logger.Debug("Fetching receipt for CBC721 transfer", "tx", tx.Hash().String())
-					// CBC721 transfers emit events, so we need to fetch the receipt
-					receipt, receiptErr := n.gocore.GetTransactionReceipt(tx.Hash().Hex())
-					if receiptErr != nil {
-						n.logger.Error("Failed to get transaction receipt", "tx", tx.Hash().String(), "error", receiptErr)
-					} else {
-						n.logger.Debug("Receipt fetched, parsing events", "tx", tx.Hash().String(), "logs", len(receipt.Logs))
-						transfers, err = blockchain.CheckForCBC721TransferFromReceipt(receipt, token.Address, token.Symbol, tx.Hash().String(), n.config.NetworkID.Int64())
-						n.logger.Debug("CBC721 parsing complete", "tx", tx.Hash().String(), "transfers", len(transfers))
-					}
-				}
-
-				if err != nil {
-					n.logger.Error("Failed to check for token transfer", "token", token.Symbol, "error", err)
-				} else if len(transfers) > 0 {
-					n.logger.Debug("Token transfer detected", "token", token.Symbol, "type", token.Type, "tx", tx.Hash().String())
-					allTransfers = append(allTransfers, transfers...)
+	// O(1) lookup for token by address instead of O(n) iteration
+	// Skip if already processed as CTN contract to avoid duplicate notifications
+	if !isCTNContract {
+		if token, exists := tokensByAddress[receiverNormalized]; exists {
+			n.logger.Debug("Token found in cache", "token", token.Symbol, "type", token.Type, "address", token.Address)
+			var transfers []*blockchain.Transfer
+			var err error
+
+			if token.Type == "CBC20" {
+				transfers, err = blockchain.CheckForCBC20Transfer(tx, token.Address, token.Symbol, token.Decimals, n.config.NetworkID.Int64())
+			} else if token.Type == "CBC721" && receipts {
+				n.logger.Debug("Fetching receipt for CBC721 transfer", "tx", tx.Hash().String())
+				// CBC721 transfers emit events, so we need to fetch the receipt
+				receipt, receiptErr := n.gocore.GetTransactionReceipt(tx.Hash().Hex())
+				if receiptErr != nil {
+					n.logger.Error("Failed to get transaction receipt", "tx", tx.Hash().String(), "error", receiptErr)
 				} else {
-					n.logger.Debug("No transfers found", "token", token.Symbol, "type", token.Type)
+					n.logger.Debug("Receipt fetched, parsing events", "tx", tx.Hash().String(), "logs", len(receipt.Logs))
+					transfers, err = blockchain.CheckForCBC721TransferFromReceipt(receipt, token.Address, token.Symbol, tx.Hash().String(), n.config.NetworkID.Int64())
+					n.logger.Debug("CBC721 parsing complete", "tx", tx.Hash().String(), "transfers", len(transfers))
 				}
 			}
-		}
 
-		// If we found any token transfers, process them
-		if len(allTransfers) > 0 {
-			transfers := allTransfers // Capture for closure
-			n.safeGo(func() { n.processTokenTransfers(transfers) }, "processTokenTransfers")
-		} else {
-			// If no token transfers found, check if it's an XCB transfer
-			if tx.Value().Sign() > 0 {
-				n.logger.Debug("XCB transfer detected", "tx", tx.Hash().String())
-				transaction := tx // Capture for closure
-				n.safeGo(func() { n.processXCBTransfer(transaction) }, "processXCBTransfer")
+			if err != nil {
+				n.logger.Error("Failed to check for token transfer", "token", token.Symbol, "error", err)
+			} else if len(transfers) > 0 {
+				n.logger.Debug("Token transfer detected", "token", token.Symbol, "type", token.Type, "tx", tx.Hash().String())
+				allTransfers = append(allTransfers, transfers...)
+			} else {
+				n.logger.Debug("No transfers found", "token", token.Symbol, "type", token.Type)
 			}
 		}
 	}
+
+	// If we found any token transfers, process them
+	if len(allTransfers) > 0 {
+		onTokenTransfers(allTransfers)
+	} else {
+		// If no token transfers found, check if it's an XCB transfer
+		if tx.Value().Sign() > 0 {
+			n.logger.Debug("XCB transfer detected", "tx", tx.Hash().String())
+			onXCBTransfer(tx)
+		}
+	}
 }
 
-// processTokenTransfers processes all token transfers (CBC20, CBC721, etc.)
+// processTokenTransfers sends the user notifications of a transaction's token transfers (CBC20, CBC721, etc.).
+// Subscription payments are handled by the payment worker.
 func (n *Nuntiare) processTokenTransfers(transfers []*blockchain.Transfer) {
-	for _, transfer := range transfers {
-		// Handle user notifications
-		n.processUserNotification(transfer)
+	for _, notification := range n.transferNotifications(transfers) {
+		n.labelConfirmed(notification)
+		n.logger.Info("Sending notification", "wallet", notification.Wallet, "token", notification.Currency, "amount", notification.Amount, "transfers", max(len(notification.Transfers), 1))
+		n.sendNotification(notification)
+		n.queueNFTImages(notification)
+	}
+}
 
-		// Handle subscription payments (CTN only)
-		n.processSubscriptionPayment(transfer)
+// transferNotifications builds the notifications for the registered wallets receiving the transaction's
+// token transfers. Several transfers to the same wallet (e.g. a batch transfer) are combined into one notification.
+func (n *Nuntiare) transferNotifications(transfers []*blockchain.Transfer) []*models.Notification {
+	var notifications []*models.Notification
+	for _, transfer := range transfers {
+		if notification := n.userNotification(transfer); notification != nil {
+			notifications = append(notifications, notification)
+		}
 	}
+	return models.GroupNotifications(notifications)
 }
 
-// processUserNotification handles notifications for registered wallets
-func (n *Nuntiare) processUserNotification(transfer *blockchain.Transfer) {
+// userNotification returns the notification of a transfer, or nil if the receiving wallet should not be notified
+func (n *Nuntiare) userNotification(transfer *blockchain.Transfer) *models.Notification {
 	n.logger.Debug("Processing user notification", "to", transfer.To, "token", transfer.TokenSymbol, "type", transfer.TokenType)
 
 	wallet, shouldNotify, err := n.shouldNotifyWallet(transfer.To)
 	if err != nil {
 		n.logger.Error("Wallet check failed", "error", err, "address", transfer.To, "token", transfer.TokenSymbol)
-		return
+		return nil
 	}
 
 	if !shouldNotify {
 		n.logger.Debug("Wallet should not be notified", "address", transfer.To, "registered", wallet != nil)
-		return
+		return nil
 	}
 
-	n.logger.Info("Sending notification", "wallet", wallet.Address, "token", transfer.TokenSymbol, "amount", transfer.Amount)
+	notification := newTransferNotification(transfer)
+	if reason := n.suppressionReason(wallet, notification); reason != "" {
+		n.logger.Debug("Notification suppressed", "address", transfer.To, "from", transfer.From, "reason", reason)
+		return nil
+	}
 
-	notification := &models.Notification{
+	n.labelInternalTransfer(wallet, notification)
+	n.labelTrustedSender(notification)
+	if !n.screenSender(notification) {
+		return nil
+	}
+	if n.inQuietPeriod(wallet, notification) {
+		n.logger.Debug("Notification suppressed", "address", transfer.To, "from", transfer.From, "reason", "registration quiet period")
+		return nil
+	}
+	return notification
+}
+
+// newTransferNotification builds the notification for a token transfer
+func newTransferNotification(transfer *blockchain.Transfer) *models.Notification {
+	return &models.Notification{
 		Wallet:       transfer.To,
 		From:         transfer.From,
 		Amount:       transfer.Amount,
@@ -545,25 +1286,25 @@ func (n *Nuntiare) processUserNotification(transfer *blockchain.Transfer) {
 		TokenID:      transfer.TokenID,
 		TxHash:       transfer.TxHash,
 		NetworkID:    transfer.NetworkID,
+		EventType:    models.TransferEventType(transfer.TokenType),
 	}
-
-	n.safeGo(func() { n.notificator.SendNotification(notification) }, "sendNotification")
 }
 
-// processSubscriptionPayment handles CTN payments to the shared RECEIVING_ADDRESS
-// All subscription payments go TO RECEIVING_ADDRESS FROM subscriber addresses
-func (n *Nuntiare) processSubscriptionPayment(transfer *blockchain.Transfer) {
+// isSubscriptionPayment reports whether the transfer is a CTN payment to RECEIVING_ADDRESS or a per-network receiving address
+func (n *Nuntiare) isSubscriptionPayment(transfer *blockchain.Transfer) bool {
 	// Only CTN token can be used for subscriptions
 	if transfer.TokenAddress != n.config.SmartContractAddress {
-		return
+		return false
 	}
 
 	// Normalize addresses for comparison (lowercase, no 0x prefix)
-	transferToNormalized := strings.ToLower(strings.TrimPrefix(transfer.To, "0x"))
-	receivingAddrNormalized := n.config.ReceivingAddressNormalized
+	return n.config.IsReceivingAddress(validation.NormalizeAddress(transfer.To))
+}
 
-	// Check if payment is TO the shared RECEIVING_ADDRESS
-	if transferToNormalized != receivingAddrNormalized {
+// processSubscriptionPayment handles CTN payments to the shared RECEIVING_ADDRESS
+// All subscription payments go TO RECEIVING_ADDRESS FROM subscriber addresses
+func (n *Nuntiare) processSubscriptionPayment(transfer *blockchain.Transfer) {
+	if !n.isSubscriptionPayment(transfer) {
 		return
 	}
 
@@ -572,63 +1313,137 @@ func (n *Nuntiare) processSubscriptionPayment(transfer *blockchain.Transfer) {
 		"to", transfer.To,
 		"amount", transfer.Amount)
 
-	// Look up wallet by subscriber address (the FROM address)
-	// GetWalletBySubscriptionAddress looks up by subscription_address field
-	wallet, err := n.repo.GetWalletBySubscriptionAddress(transfer.From)
+	// Look up the wallets by subscriber address (the FROM address), several wallets can share one
+	wallets, err := n.repo.GetWalletsBySubscriptionAddress(transfer.From)
 	if err != nil {
-		n.logger.Debug("No registered wallet found for subscriber address",
+		n.logger.Error("Failed to get wallets of subscriber address",
 			"subscriber", transfer.From,
 			"error", err)
 		return
 	}
+	if len(wallets) == 0 {
+		n.logger.Debug("No registered wallet found for subscriber address", "subscriber", transfer.From)
+		return
+	}
 
-	n.logger.Info("Subscription payment detected",
-		"subscriber", transfer.From,
-		"destination_wallet", wallet.Address,
-		"amount", transfer.Amount)
+	// Payments must go to the receiving address of the wallet's network, so a nominal devin price can't buy mainnet months
+	paid := make([]*models.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		if receivingAddress := n.config.ReceivingAddressFor(wallet.Network); validation.NormalizeAddress(transfer.To) != receivingAddress {
+			n.logger.Warn("Subscription payment sent to the receiving address of another network, ignoring",
+				"subscriber", transfer.From,
+				"wallet", wallet.Address,
+				"network", wallet.Network,
+				"to", transfer.To,
+				"expected", receivingAddress)
+			continue
+		}
+		paid = append(paid, wallet)
+	}
+	if len(paid) == 0 {
+		return
+	}
 
-	if err := n.AddSubscriptionPaymentAndUpdatePaidStatus(wallet, transfer.Amount, time.Now().Unix()); err != nil {
-		n.logger.Error("Failed to process subscription payment",
-			"error", err,
-			"wallet", wallet.Address,
-			"subscriber", transfer.From)
+	// Payments are timed by their block, the same on every instance and when the payments are replayed
+	timestamp := transfer.Timestamp
+	if timestamp == 0 {
+		timestamp = n.now().Unix()
+	}
+	paid = n.attributeSubscriptionPayment(transfer, paid)
+	share := transfer.Amount / float64(len(paid))
+	for _, wallet := range paid {
+		n.logger.Info("Subscription payment detected",
+			"subscriber", transfer.From,
+			"destination_wallet", wallet.Address,
+			"amount", share)
+		if err := n.AddSubscriptionPaymentAndUpdatePaidStatus(wallet, share, timestamp); err != nil {
+			n.logger.Error("Failed to process subscription payment",
+				"error", err,
+				"wallet", wallet.Address,
+				"subscriber", transfer.From)
+		}
 	}
 }
 
+// attributeSubscriptionPayment returns the wallets a payment from a subscription address shared by the wallets
+// is credited to: the wallet designated in the transaction, or all of them in equal shares when the payment
+// designates none of them.
+func (n *Nuntiare) attributeSubscriptionPayment(transfer *blockchain.Transfer, wallets []*models.Wallet) []*models.Wallet {
+	if transfer.Designation == "" {
+		return wallets
+	}
+	for _, wallet := range wallets {
+		if validation.NormalizeAddress(wallet.Address) == transfer.Designation {
+			return []*models.Wallet{wallet}
+		}
+	}
+	n.logger.Warn("Subscription payment designates a wallet not paid for from the subscriber address, splitting it",
+		"subscriber", transfer.From,
+		"designation", transfer.Designation,
+		"wallets", len(wallets),
+		"tx_hash", transfer.TxHash)
+	return wallets
+}
+
 func (n *Nuntiare) processXCBTransfer(tx *types.Transaction) {
-	address := tx.To().String()
+	notification := n.xcbNotification(tx)
+	if notification == nil {
+		return
+	}
+	n.labelConfirmed(notification)
+	n.logger.Info("Sending notification", "wallet", notification.Wallet, "currency", "XCB", "amount", notification.Amount, "tx", notification.TxHash)
+
+	n.sendNotification(notification)
+}
+
+// xcbNotification returns the notification of an XCB transfer, or nil if the receiving wallet should not be notified
+func (n *Nuntiare) xcbNotification(tx *types.Transaction) *models.Notification {
+	address := validation.NormalizeAddress(tx.To().Hex())
 
 	wallet, shouldNotify, err := n.shouldNotifyWallet(address)
 	if err != nil {
 		n.logger.Error("Wallet check failed", "error", err, "address", address, "tx", tx.Hash().String())
-		return
+		return nil
 	}
 
 	if !shouldNotify {
-		return
+		return nil
 	}
 
-	amount := weiToXCB(tx.Value())
-	n.logger.Info("Sending notification", "wallet", wallet.Address, "currency", "XCB", "amount", amount, "tx", tx.Hash().String())
+	notification := n.newXCBNotification(tx)
+	if reason := n.suppressionReason(wallet, notification); reason != "" {
+		n.logger.Debug("Notification suppressed", "address", address, "from", notification.From, "reason", reason)
+		return nil
+	}
+	n.labelInternalTransfer(wallet, notification)
+	n.labelTrustedSender(notification)
+	if !n.screenSender(notification) {
+		return nil
+	}
+	if n.inQuietPeriod(wallet, notification) {
+		n.logger.Debug("Notification suppressed", "address", address, "from", notification.From, "reason", "registration quiet period")
+		return nil
+	}
+	return notification
+}
 
-	// Get sender address
-	signer := types.NewNucleusSigner(n.config.NetworkID)
-	sender, err := signer.Sender(tx)
-	fromAddr := ""
-	if err == nil {
-		fromAddr = sender.Hex()
+// newXCBNotification builds the notification for a native XCB transfer
+func (n *Nuntiare) newXCBNotification(tx *types.Transaction) *models.Notification {
+	// Recover the sender the same way as for token transfers
+	from, err := blockchain.TransactionSender(tx)
+	if err != nil {
+		n.logger.Warn("Failed to recover XCB transfer sender", "tx", tx.Hash().String(), "error", err)
 	}
 
-	notification := &models.Notification{
-		Wallet:    address,
-		From:      fromAddr,
-		Amount:    amount,
+	return &models.Notification{
+		Wallet:    validation.NormalizeAddress(tx.To().Hex()),
+		From:      from,
+		Amount:    weiToXCB(tx.Value()),
 		Currency:  "XCB",
 		TxHash:    tx.Hash().String(),
 		NetworkID: n.config.NetworkID.Int64(),
+		EventType: models.EventIncomingXCB,
 	}
-
-	n.safeGo(func() { n.notificator.SendNotification(notification) }, "sendNotification")
 }
 
 // CheckWalletSubscription check at the moment of call the CTN balance of the wallet.
@@ -651,32 +1466,55 @@ func (n *Nuntiare) processXCBTransfer(tx *types.Transaction) {
 // }
 
 // CheckWalletSubscription checks if the wallet is subscribed
-// It checks if the subscription expiration date is in the future
+// It checks if the subscription expiration date is in the future on the database clock, see subscriptionActive
 func (n *Nuntiare) CheckWalletSubscription(wallet *models.Wallet) (bool, error) {
-	now := time.Now().Unix()
+	now := n.now().Unix()
 
 	n.logger.Debug("Wallet subscription checked",
 		"subscriptionAddress", wallet.SubscriptionAddress,
 		"expiresAt", wallet.SubscriptionExpiresAt,
 		"now", now)
 
-	if wallet.SubscriptionExpiresAt > now {
+	if n.subscriptionActive(wallet.SubscriptionExpiresAt, now) {
 		// Subscription is still active
 		return true, nil
 	}
 
 	// Subscription has expired, update paid status to false
 	if wallet.Paid {
-		err := n.repo.UpdateWalletPaidStatus(wallet.Address, false)
+		err := n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+			if err := repo.UpdateWalletPaidStatus(wallet.Address, false); err != nil {
+				return nil, err
+			}
+			// The expiration is only noticed now, the event is dated when it happened
+			return []*models.WalletEvent{{
+				Wallet:                wallet.Address,
+				Type:                  models.WalletEventExpired,
+				Reason:                models.WalletEventReasonLapsed,
+				SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+				Timestamp:             wallet.SubscriptionExpiresAt,
+			}}, nil
+		})
 		if err != nil {
 			n.logger.Error("Failed to update wallet paid status", "error", err)
 			return false, err
 		}
+		wallet.Paid = false
 	}
 
 	return false, nil
 }
 
+// GetWallets returns the registered wallets among the given addresses
+func (n *Nuntiare) GetWallets(addresses []string) ([]*models.Wallet, error) {
+	return n.repo.GetWallets(addresses)
+}
+
+// SubscriptionActive reports whether the wallet's subscription is active now, without updating its paid status
+func (n *Nuntiare) SubscriptionActive(wallet *models.Wallet) bool {
+	return n.subscriptionActive(wallet.SubscriptionExpiresAt, n.now().Unix())
+}
+
 func (n *Nuntiare) GetWallet(address string) (*models.Wallet, error) {
 	wallet, err := n.repo.GetWallet(address)
 	if err != nil {
@@ -694,23 +1532,19 @@ func (n *Nuntiare) AddSubscriptionPaymentAndUpdatePaidStatus(
 	amount float64,
 	timestamp int64,
 ) error {
-	// Add payment record for tracking
-	err := n.repo.AddSubscriptionPayment(wallet.SubscriptionAddress, amount, timestamp)
-	if err != nil {
-		n.logger.Error("Failed to add subscription payment", "error", err)
-		return err
-	}
+	monthCost := n.SubscriptionMonthCostFor(wallet.Network)
 
 	// Calculate how many months this payment covers
-	monthsToAdd := amount / n.config.SubscriptionMonthCost
+	monthsToAdd := amount / monthCost
 	secondsToAdd := int64(monthsToAdd * n.config.SubscriptionMonthDuration)
 
-	now := time.Now().Unix()
 	var newExpiresAt int64
+	eventType := models.WalletEventPaid
 
-	// If subscription is still active, extend it from current expiration
-	// Otherwise, start from now
-	if wallet.SubscriptionExpiresAt > now {
+	// If subscription is still active when the payment was made, extend it from current expiration
+	// Otherwise, start from the payment
+	if wallet.SubscriptionExpiresAt > timestamp {
+		eventType = models.WalletEventExtended
 		newExpiresAt = wallet.SubscriptionExpiresAt + secondsToAdd
 		n.logger.Info("Extending active subscription",
 			"address", wallet.Address,
@@ -719,7 +1553,7 @@ func (n *Nuntiare) AddSubscriptionPaymentAndUpdatePaidStatus(
 			"currentExpires", wallet.SubscriptionExpiresAt,
 			"newExpires", newExpiresAt)
 	} else {
-		newExpiresAt = now + secondsToAdd
+		newExpiresAt = timestamp + secondsToAdd
 		n.logger.Info("Starting new subscription",
 			"address", wallet.Address,
 			"amount", amount,
@@ -727,16 +1561,31 @@ func (n *Nuntiare) AddSubscriptionPaymentAndUpdatePaidStatus(
 			"expiresAt", newExpiresAt)
 	}
 
-	// Update wallet's expiration date and paid status
-	err = n.repo.UpdateWalletSubscriptionExpiration(wallet.Address, newExpiresAt)
-	if err != nil {
-		n.logger.Error("Failed to update wallet subscription expiration", "error", err)
-		return err
-	}
+	err := n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+		// Add payment record for tracking
+		// The price is recorded with the payment, so replaying payments later credits them the same way
+		if err := repo.AddSubscriptionPayment(wallet.SubscriptionAddress, wallet.Address, amount, monthCost, timestamp); err != nil {
+			return nil, fmt.Errorf("failed to add subscription payment: %w", err)
+		}
 
-	err = n.repo.UpdateWalletPaidStatus(wallet.Address, true)
+		// Update wallet's expiration date and paid status
+		if err := repo.UpdateWalletSubscriptionExpiration(wallet.Address, newExpiresAt); err != nil {
+			return nil, fmt.Errorf("failed to update wallet subscription expiration: %w", err)
+		}
+		if err := repo.UpdateWalletPaidStatus(wallet.Address, true); err != nil {
+			return nil, fmt.Errorf("failed to update wallet paid status: %w", err)
+		}
+		return []*models.WalletEvent{{
+			Wallet:                wallet.Address,
+			Type:                  eventType,
+			Reason:                models.WalletEventReasonPayment,
+			SubscriptionExpiresAt: newExpiresAt,
+			Amount:                amount,
+			Timestamp:             timestamp,
+		}}, nil
+	})
 	if err != nil {
-		n.logger.Error("Failed to update wallet paid status", "error", err)
+		n.logger.Error("Failed to credit subscription payment", "error", err, "address", wallet.Address)
 		return err
 	}
 
@@ -754,6 +1603,8 @@ func (n *Nuntiare) AddSubscriptionPaymentAndUpdatePaidStatus(
 	notification := &models.Notification{
 		Wallet:        wallet.Address,
 		CustomMessage: activationMessage,
+		NetworkID:     n.config.NetworkID.Int64(),
+		EventType:     models.EventPaymentReceived,
 	}
 	n.safeGo(func() {
 		n.notificator.SendNotification(notification)
@@ -761,11 +1612,3 @@ func (n *Nuntiare) AddSubscriptionPaymentAndUpdatePaidStatus(
 
 	return nil
 }
-
-// ProcessTelegramWebhook processes a Telegram webhook update
-func (n *Nuntiare) ProcessTelegramWebhook(update interface{}) error {
-	n.logger.Debug("Received Telegram webhook update", "update", update)
-	// Webhook processing will be handled by the Telegram bot API
-	// This is a placeholder for now - actual implementation depends on bot library
-	return nil
-}
@@ -0,0 +1,103 @@
+package nuntiare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// BlockLagCheckInterval is how often the time since the last block is compared with BLOCK_LAG_ALERT_SECONDS
+	BlockLagCheckInterval = 30 * time.Second
+	// LockContentionAlertFailures is the number of consecutive failures to acquire a lock that raise an alert
+	LockContentionAlertFailures = 3
+)
+
+// checkBlockLag alerts operators once when no block arrived for BLOCK_LAG_ALERT_SECONDS, e.g. the node
+// stopped syncing or the header subscription silently died, and sends a resolved alert once blocks arrive again
+func (n *Nuntiare) checkBlockLag() {
+	blockTime := n.lastBlockTime.Load()
+	if blockTime == 0 {
+		// No block yet, the connection is still being established
+		return
+	}
+
+	lag := max(time.Now().Unix()-int64(blockTime), 0)
+	threshold := int64(n.config.BlockLagAlertSeconds)
+	lagging := lag >= threshold
+	if lagging == n.blockLagAlerted.Load() {
+		return
+	}
+	n.blockLagAlerted.Store(lagging)
+
+	message := fmt.Sprintf("ALERT: no new block for %s (last block %d), blocks are not being processed",
+		time.Duration(lag)*time.Second, n.lastBlockNumber.Load())
+	if !lagging {
+		message = fmt.Sprintf("RESOLVED: blocks are processed again (block %d, %s behind)",
+			n.lastBlockNumber.Load(), time.Duration(lag)*time.Second)
+	}
+	n.notificator.SendOpsAlert(&models.OpsAlert{
+		Type:      models.OpsAlertBlockLag,
+		Severity:  models.OpsSeverityCritical,
+		Resolved:  !lagging,
+		Value:     float64(lag),
+		Threshold: float64(threshold),
+		Message:   message,
+	})
+}
+
+// tryAcquireLock acquires a distributed lock and alerts operators when acquiring locks of the same kind
+// failed LockContentionAlertFailures times in a row, which means the lock table is contended or unreachable
+func (n *Nuntiare) tryAcquireLock(lockName string, ttlSeconds int) (bool, error) {
+	acquired, err := n.repo.TryAcquireLock(lockName, n.instanceID, ttlSeconds)
+
+	// Block locks are named per block, count them as one kind
+	kind := strings.TrimSuffix(strings.TrimRight(lockName, "0123456789"), "_")
+	n.lockFailuresMu.Lock()
+	failures := n.lockFailures[kind]
+	if err != nil {
+		n.lockFailures[kind] = failures + 1
+	} else {
+		delete(n.lockFailures, kind)
+	}
+	n.lockFailuresMu.Unlock()
+
+	switch {
+	case err != nil && failures+1 == LockContentionAlertFailures:
+		n.notificator.SendOpsAlert(&models.OpsAlert{
+			Type:      models.OpsAlertLockContention,
+			Key:       kind,
+			Value:     float64(failures + 1),
+			Threshold: LockContentionAlertFailures,
+			Message:   fmt.Sprintf("ALERT: acquiring the %s lock failed %d times in a row: %v", kind, failures+1, err),
+		})
+	case err == nil && failures >= LockContentionAlertFailures:
+		n.notificator.SendOpsAlert(&models.OpsAlert{
+			Type:     models.OpsAlertLockContention,
+			Key:      kind,
+			Resolved: true,
+			Message:  fmt.Sprintf("RESOLVED: the %s lock is acquired again", kind),
+		})
+	}
+	return acquired, err
+}
+
+// checkLockOverrun warns operators when work took longer than the TTL of the lock guarding it.
+// The lock expired while held, so another instance may have done the same work concurrently.
+func (n *Nuntiare) checkLockOverrun(lockName string, acquiredAt time.Time, ttlSeconds int) {
+	held := time.Since(acquiredAt)
+	ttl := time.Duration(ttlSeconds) * time.Second
+	if held <= ttl {
+		return
+	}
+	n.notificator.SendOpsAlert(&models.OpsAlert{
+		Type:      models.OpsAlertLockContention,
+		Key:       lockName + "_overrun",
+		Value:     held.Seconds(),
+		Threshold: ttl.Seconds(),
+		Message: fmt.Sprintf("The %s lock was held for %s, longer than its TTL of %s. Another instance may have run the same work concurrently.",
+			lockName, held.Round(time.Second), ttl),
+	})
+}
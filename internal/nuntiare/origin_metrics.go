@@ -0,0 +1,50 @@
+package nuntiare
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// PartnerOrigin returns the originator the partner API key belongs to, false for unknown keys.
+// All keys are compared so the time taken doesn't reveal which originator a key is close to.
+func (n *Nuntiare) PartnerOrigin(key string) (string, bool) {
+	var origin string
+	for partner, partnerKey := range n.config.PartnerAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(partnerKey)) == 1 {
+			origin = partner
+		}
+	}
+	return origin, origin != "" && key != ""
+}
+
+// GetOriginMetrics returns the delivery metrics of the originator's wallets for [from, to)
+func (n *Nuntiare) GetOriginMetrics(origin string, from, to int64) (*models.OriginMetrics, error) {
+	return n.repo.GetOriginMetrics(origin, from, to)
+}
+
+// GetOriginQuota returns the consumption of the originator's PARTNER_MONTHLY_QUOTAS entry in the current
+// calendar month (UTC), nil if the originator has no quota
+func (n *Nuntiare) GetOriginQuota(origin string) (*models.OriginQuota, error) {
+	limit, ok := n.config.PartnerMonthlyQuotas[origin]
+	if !ok {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	used, err := n.repo.CountOriginNotifications(origin, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OriginQuota{
+		MonthlyLimit: limit,
+		Used:         used,
+		Remaining:    max(limit-used, 0),
+		PeriodStart:  start.Unix(),
+		PeriodEnd:    end.Unix(),
+	}, nil
+}
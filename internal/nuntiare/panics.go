@@ -0,0 +1,179 @@
+package nuntiare
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// PanicSummaryInterval is how often panics repeating a known signature are summarized to the ops destinations
+	PanicSummaryInterval = 1 * time.Hour
+	// panicSignatureLength is the number of hex characters of a panic signature
+	panicSignatureLength = 12
+	// maxPanicSummaryLines limits the signatures listed in a panic summary
+	maxPanicSummaryLines = 10
+)
+
+// panicRecorder counts recovered panics by stack signature, so a panic repeating for every block is one
+// report with a count instead of an alert per occurrence
+type panicRecorder struct {
+	mu      sync.Mutex
+	reports map[string]*models.PanicReport
+	// summarized is the count of each signature at the last summary
+	summarized map[string]int64
+}
+
+func newPanicRecorder() *panicRecorder {
+	return &panicRecorder{
+		reports:    make(map[string]*models.PanicReport),
+		summarized: make(map[string]int64),
+	}
+}
+
+// record counts a panic and returns its report. first is true for the first panic with the signature.
+func (r *panicRecorder) record(where string, recovered interface{}, stack string, now time.Time) (report models.PanicReport, first bool) {
+	signature := panicSignature(where, stack)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	existing, ok := r.reports[signature]
+	if !ok {
+		existing = &models.PanicReport{
+			Signature: signature,
+			Where:     where,
+			Stack:     stack,
+			FirstAt:   now.Unix(),
+		}
+		r.reports[signature] = existing
+		// The first panic is alerted right away, summaries only cover the repeats
+		r.summarized[signature] = 1
+	}
+	existing.Panic = fmt.Sprint(recovered)
+	existing.Count++
+	existing.LastAt = now.Unix()
+	return *existing, !ok
+}
+
+// list returns the reports, most frequent first
+func (r *panicRecorder) list() []*models.PanicReport {
+	r.mu.Lock()
+	reports := make([]*models.PanicReport, 0, len(r.reports))
+	for _, report := range r.reports {
+		copied := *report
+		reports = append(reports, &copied)
+	}
+	r.mu.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Count != reports[j].Count {
+			return reports[i].Count > reports[j].Count
+		}
+		return reports[i].Signature < reports[j].Signature
+	})
+	return reports
+}
+
+// unsummarized returns the number of panics of each signature since the last summary, most frequent
+// first, and marks them summarized
+func (r *panicRecorder) unsummarized() ([]*models.PanicReport, []int64) {
+	reports := r.list()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []*models.PanicReport
+	var counts []int64
+	for _, report := range reports {
+		// The report may have been counted again since list, use the current count
+		current := r.reports[report.Signature]
+		if since := current.Count - r.summarized[report.Signature]; since > 0 {
+			pending = append(pending, report)
+			counts = append(counts, since)
+			r.summarized[report.Signature] = current.Count
+		}
+	}
+	return pending, counts
+}
+
+// panicSignature hashes the goroutine description and the functions on the stack below the panic.
+// Argument values, file offsets and goroutine IDs are left out, so the same bug always has the same signature.
+func panicSignature(where string, stack string) string {
+	lines := strings.Split(stack, "\n")
+	var functions []string
+	for _, line := range lines {
+		// Function lines aren't indented, file lines are; the first line is the goroutine header
+		if line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "goroutine ") {
+			continue
+		}
+		if i := strings.Index(line, " in goroutine "); i > 0 {
+			line = line[:i]
+		}
+		if strings.HasSuffix(line, ")") {
+			if i := strings.LastIndex(line, "("); i > 0 {
+				line = line[:i]
+			}
+		}
+		if line == "panic" {
+			// Frames above the panic are the recovery itself
+			functions = functions[:0]
+			continue
+		}
+		functions = append(functions, line)
+	}
+
+	sum := sha256.Sum256([]byte(where + "\n" + strings.Join(functions, "\n")))
+	return hex.EncodeToString(sum[:])[:panicSignatureLength]
+}
+
+// reportPanic counts a recovered panic and alerts the ops destinations on the first panic of its signature.
+// Repeats are included in the periodic panic summary.
+func (n *Nuntiare) reportPanic(where string, recovered interface{}, stack string) {
+	report, first := n.panics.record(where, recovered, stack, time.Now())
+	if !first {
+		return
+	}
+	alert := models.NewPanicAlert(where, recovered, stack)
+	alert.Key = where + ":" + report.Signature
+	alert.Message += fmt.Sprintf(" (signature %s, repeats are summarized every %s)", report.Signature, PanicSummaryInterval)
+	n.notificator.SendOpsAlert(alert)
+}
+
+// summarizePanics sends the panics that repeated since the last summary to the ops destinations
+func (n *Nuntiare) summarizePanics() {
+	reports, counts := n.panics.unsummarized()
+	if len(reports) == 0 {
+		return
+	}
+
+	var total int64
+	lines := make([]string, 0, min(len(reports), maxPanicSummaryLines)+2)
+	for i, report := range reports {
+		total += counts[i]
+		if i < maxPanicSummaryLines {
+			lines = append(lines, fmt.Sprintf("%dx %s [%s]: %s", counts[i], report.Where, report.Signature, report.Panic))
+		}
+	}
+	if len(reports) > maxPanicSummaryLines {
+		lines = append(lines, fmt.Sprintf("... and %d more signatures", len(reports)-maxPanicSummaryLines))
+	}
+	header := fmt.Sprintf("%d panics with %d known signatures in the last %s:", total, len(reports), PanicSummaryInterval)
+	lines = append([]string{header}, lines...)
+
+	n.notificator.SendOpsAlert(&models.OpsAlert{
+		Type:     models.OpsAlertPanicSummary,
+		Severity: models.OpsSeverityError,
+		Value:    float64(total),
+		Message:  strings.Join(lines, "\n"),
+	})
+}
+
+// GetPanicReports returns the panics recovered by this instance by stack signature, most frequent first
+func (n *Nuntiare) GetPanicReports() []*models.PanicReport {
+	return n.panics.list()
+}
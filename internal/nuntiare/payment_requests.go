@@ -0,0 +1,209 @@
+package nuntiare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// MaxOpenPaymentRequests limits the open payment requests of a wallet
+	MaxOpenPaymentRequests = 100
+	// MaxPaymentRequestMemoLength limits the memo of a payment request, in characters
+	MaxPaymentRequestMemoLength = 140
+	// PaymentRequestListLimit is the number of payment requests listed per wallet
+	PaymentRequestListLimit = 100
+)
+
+// CreatePaymentRequest creates a shareable request to pay the wallet amount (0 for any amount) of a token,
+// given as "xcb" or the contract address of a watched CBC20 token. expiresIn is in seconds, 0 for never.
+func (n *Nuntiare) CreatePaymentRequest(address, token string, amount float64, memo string, expiresIn int64) (*models.PaymentRequest, error) {
+	token, err := normalizeTokenPreference(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: token must be xcb or a token contract address", models.ErrInvalidPaymentRequest)
+	}
+	currency := "XCB"
+	if token != models.NativeTokenPreference {
+		watched, ok := n.watchedTokens()[token]
+		if !ok || watched.Type != "CBC20" {
+			return nil, fmt.Errorf("%w: token %s is not a watched CBC20 token", models.ErrInvalidPaymentRequest, token)
+		}
+		currency = watched.Symbol
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: amount must not be negative", models.ErrInvalidPaymentRequest)
+	}
+	memo = strings.TrimSpace(memo)
+	if utf8.RuneCountInString(memo) > MaxPaymentRequestMemoLength {
+		return nil, fmt.Errorf("%w: memo must be at most %d characters", models.ErrInvalidPaymentRequest, MaxPaymentRequestMemoLength)
+	}
+	if expiresIn < 0 {
+		return nil, fmt.Errorf("%w: expires_in must not be negative", models.ErrInvalidPaymentRequest)
+	}
+
+	open, err := n.repo.CountOpenPaymentRequests(address)
+	if err != nil {
+		return nil, err
+	}
+	if open >= MaxOpenPaymentRequests {
+		return nil, fmt.Errorf("%w: at most %d open payment requests per wallet", models.ErrInvalidPaymentRequest, MaxOpenPaymentRequests)
+	}
+
+	id, err := newSecret()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	request := &models.PaymentRequest{
+		ID:            id,
+		WalletAddress: address,
+		Token:         token,
+		Currency:      currency,
+		Amount:        amount,
+		Memo:          memo,
+		Status:        models.PaymentRequestOpen,
+		CreatedAt:     now,
+	}
+	if expiresIn > 0 {
+		request.ExpiresAt = now + expiresIn
+	}
+	if err := n.repo.AddPaymentRequest(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// GetPaymentRequest returns a payment request by ID
+func (n *Nuntiare) GetPaymentRequest(id string) (*models.PaymentRequest, error) {
+	return n.repo.GetPaymentRequest(id)
+}
+
+// GetPaymentRequests returns the latest payment requests of the wallet, newest first
+func (n *Nuntiare) GetPaymentRequests(address string) ([]*models.PaymentRequest, error) {
+	return n.repo.GetPaymentRequests(address, PaymentRequestListLimit)
+}
+
+// CancelPaymentRequest cancels an open payment request of the wallet. Returns false if it doesn't exist or isn't open.
+func (n *Nuntiare) CancelPaymentRequest(address, id string) (bool, error) {
+	return n.repo.CancelPaymentRequest(address, id)
+}
+
+// PaymentRequestURL returns the link of the payment request page, relative without PUBLIC_URL
+func (n *Nuntiare) PaymentRequestURL(request *models.PaymentRequest) string {
+	return fmt.Sprintf("%s/pay/%s", n.config.PublicURL, request.ID)
+}
+
+// processPaymentRequestTransfers fulfills the payment requests paid by a transaction's CBC20 transfers
+func (n *Nuntiare) processPaymentRequestTransfers(transfers []*blockchain.Transfer) {
+	for _, transfer := range transfers {
+		if transfer.TokenType != "CBC20" {
+			continue
+		}
+		n.fulfillPaymentRequest(transfer.To, validation.NormalizeAddress(transfer.TokenAddress), transfer.From, transfer.Amount, transfer.TxHash)
+	}
+}
+
+// processPaymentRequestXCBTransfer fulfills the payment request paid by an XCB transfer
+func (n *Nuntiare) processPaymentRequestXCBTransfer(tx *types.Transaction) {
+	from, err := blockchain.TransactionSender(tx)
+	if err != nil {
+		n.logger.Warn("Failed to recover XCB transfer sender", "tx", tx.Hash().String(), "error", err)
+	}
+	n.fulfillPaymentRequest(tx.To().Hex(), models.NativeTokenPreference, from, weiToXCB(tx.Value()), tx.Hash().String())
+}
+
+// fulfillPaymentRequest marks the oldest open request of the wallet the transfer matches fulfilled and
+// notifies the payee and, if registered, the payer. Transfers of failed transactions don't fulfill requests.
+func (n *Nuntiare) fulfillPaymentRequest(to, token, from string, amount float64, txHash string) {
+	// Payment requests are fulfilled by production, shadow instances must not change them
+	if n.config.ShadowMode {
+		return
+	}
+
+	requests, err := n.repo.GetOpenPaymentRequests(to, token)
+	if err != nil {
+		n.logger.Error("Failed to get open payment requests", "error", err, "wallet", to)
+		return
+	}
+
+	now := time.Now()
+	checked := false
+	for _, request := range requests {
+		if request.Expired(now) || !request.Matches(amount) {
+			continue
+		}
+		// A reverted transfer still has the call data or value of a payment, the receipt tells if it happened
+		if !checked {
+			if !n.transactionSucceeded(txHash) {
+				return
+			}
+			checked = true
+		}
+		fulfilled, err := n.repo.FulfillPaymentRequest(request.ID, txHash, from, amount, now.Unix())
+		if err != nil {
+			n.logger.Error("Failed to fulfill payment request", "error", err, "id", request.ID, "tx", txHash)
+			return
+		}
+		if !fulfilled {
+			continue
+		}
+
+		n.logger.Info("Payment request fulfilled", "id", request.ID, "wallet", request.WalletAddress, "tx", txHash)
+		paid := strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.18f", amount), "0"), ".") + " " + request.Currency
+		payee := fmt.Sprintf("Payment request paid: %s", paid)
+		payer := fmt.Sprintf("Your payment of %s to %s was received", paid, request.WalletAddress)
+		if request.Memo != "" {
+			payee += fmt.Sprintf(" for \"%s\"", request.Memo)
+			payer += fmt.Sprintf(" for \"%s\"", request.Memo)
+		}
+		if from != "" {
+			payee += " from " + validation.NormalizeAddress(from)
+		}
+		n.sendPaymentRequestNotification(request.WalletAddress, payee+".")
+		if from != "" {
+			n.sendPaymentRequestNotification(validation.NormalizeAddress(from), payer+".")
+		}
+		return
+	}
+}
+
+// transactionSucceeded reports whether the receipt of the mined transaction reports success. A receipt that
+// can't be fetched counts as failed.
+func (n *Nuntiare) transactionSucceeded(txHash string) bool {
+	receipt, err := n.gocore.GetTransactionReceipt(txHash)
+	if err != nil {
+		n.logger.Error("Failed to get transaction receipt", "error", err, "tx", txHash)
+		return false
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		n.logger.Debug("Transaction reverted", "tx", txHash)
+		return false
+	}
+	return true
+}
+
+// sendPaymentRequestNotification notifies a registered wallet about a fulfilled payment request
+func (n *Nuntiare) sendPaymentRequestNotification(address, message string) {
+	_, shouldNotify, err := n.shouldNotifyWallet(address)
+	if err != nil {
+		n.logger.Error("Wallet check failed", "error", err, "address", address)
+		return
+	}
+	if !shouldNotify {
+		return
+	}
+
+	notification := &models.Notification{
+		Wallet:        address,
+		CustomMessage: message,
+		NetworkID:     n.config.NetworkID.Int64(),
+		EventType:     models.EventPaymentRequestPaid,
+	}
+	n.sendNotification(notification)
+}
@@ -0,0 +1,51 @@
+package nuntiare
+
+import (
+	"runtime/debug"
+
+	"github.com/core-coin/nuntiare/internal/blockchain"
+)
+
+// PaymentQueueSize is the number of subscription payments buffered for the payment worker
+const PaymentQueueSize = 1000
+
+// enqueuePayments hands the subscription payments among a transaction's transfers to the payment worker.
+// When the queue is full it waits for the worker, so payments are credited one at a time in chain order.
+func (n *Nuntiare) enqueuePayments(transfers []*blockchain.Transfer) {
+	// Payments are recorded by production, shadow instances must not change subscriptions
+	if n.config.ShadowMode {
+		return
+	}
+
+	for _, transfer := range transfers {
+		if !n.isSubscriptionPayment(transfer) {
+			continue
+		}
+		n.paymentQueue <- transfer
+	}
+}
+
+// processPayments is the payment worker. It credits queued subscription payments one at a time until Stop
+// closes the queue, which it does once everything queueing payments stopped, so no detected payment is lost.
+func (n *Nuntiare) processPayments() {
+	defer close(n.paymentsDone)
+	for transfer := range n.paymentQueue {
+		n.processPayment(transfer)
+	}
+	n.logger.Debug("Payment worker stopped")
+}
+
+// processPayment credits a single payment with panic recovery, so one bad payment doesn't stop the worker
+func (n *Nuntiare) processPayment(transfer *blockchain.Transfer) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			n.logger.Error("Payment processing panicked",
+				"tx", transfer.TxHash,
+				"panic", r,
+				"stack", stack)
+			n.reportPanic("processPayment", r, stack)
+		}
+	}()
+	n.processSubscriptionPayment(transfer)
+}
@@ -0,0 +1,122 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/go-core/v2"
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/core/types"
+
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// PendingAlertLockTTL is how long (seconds) the pending alerts of a transaction are claimed by the instance that
+// sent them, so other instances and rebroadcasts of the transaction don't alert again
+const PendingAlertLockTTL = 3600
+
+// WatchPendingTransactions notifies incoming transfers as soon as they enter the node's transaction pool.
+// The regular notification follows, marked as confirmed, once the transaction is mined.
+func (n *Nuntiare) WatchPendingTransactions() {
+	defer n.wg.Done()
+
+	backoff := InitialBackoff
+	for {
+		subscription, hashes, err := n.gocore.NewPendingTransactionSubscription()
+		if err != nil {
+			n.logger.Warn("Failed to subscribe to pending transactions, will retry", "error", err, "retry_in", backoff)
+			select {
+			case <-time.After(backoff):
+			case <-n.ctx.Done():
+				return
+			}
+			backoff = min(backoff*2, MaxBackoff)
+			continue
+		}
+
+		backoff = InitialBackoff
+		n.logger.Info("Subscribed to pending transactions")
+		if !n.watchPending(subscription, hashes) {
+			return
+		}
+	}
+}
+
+// watchPending handles the pending transactions of a subscription. Returns false when the instance shuts down.
+func (n *Nuntiare) watchPending(subscription core.Subscription, hashes <-chan common.Hash) bool {
+	defer subscription.Unsubscribe()
+
+	for {
+		select {
+		case hash := <-hashes:
+			n.processPendingTransaction(hash.Hex())
+		case err := <-subscription.Err():
+			n.logger.Warn("Pending transaction subscription error, will restart", "error", err)
+			return true
+		case <-n.ctx.Done():
+			n.logger.Debug("Pending transaction watcher stopped")
+			return false
+		}
+	}
+}
+
+// processPendingTransaction sends the pending alerts of the transfers to registered wallets in the transaction.
+// Subscription payments are only credited once mined.
+func (n *Nuntiare) processPendingTransaction(txHash string) {
+	tx, pending, err := n.gocore.GetTransaction(txHash)
+	if err != nil {
+		// Dropped or replaced before it could be fetched
+		n.logger.Debug("Failed to get pending transaction", "tx", txHash, "error", err)
+		return
+	}
+	if !pending {
+		return
+	}
+
+	var notifications []*models.Notification
+	n.scanTransaction(tx, n.watchedTokens(), false, func(transfers []*blockchain.Transfer) {
+		if n.registered.touches(transfers) {
+			notifications = append(notifications, n.transferNotifications(transfers)...)
+		}
+	}, func(tx *types.Transaction) {
+		if !n.registered.contains(tx.To().Hex()) {
+			return
+		}
+		if notification := n.xcbNotification(tx); notification != nil {
+			notifications = append(notifications, notification)
+		}
+	})
+	if len(notifications) == 0 {
+		return
+	}
+
+	// The lock is kept until it expires, it marks the transaction as alerted
+	acquired, err := n.repo.TryAcquireLock("pending_alert_"+txHash, n.instanceID, PendingAlertLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to claim pending transaction alerts", "tx", txHash, "error", err)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	for _, notification := range notifications {
+		notification.EventType = models.EventIncomingPending
+		n.logger.Info("Sending pending transfer notification", "wallet", notification.Wallet, "currency", notification.Currency,
+			"amount", notification.Amount, "tx", notification.TxHash)
+		n.sendNotification(notification)
+	}
+}
+
+// labelConfirmed marks the notification of a mined transfer whose wallet was alerted while it was pending
+func (n *Nuntiare) labelConfirmed(notification *models.Notification) {
+	if !n.config.PendingTransactionAlerts {
+		return
+	}
+	alerted, err := n.repo.PendingNotificationExists(notification.Wallet, notification.TxHash)
+	if err != nil {
+		n.logger.Error("Failed to check for a pending transfer notification", "error", err, "tx", notification.TxHash)
+		return
+	}
+	notification.Confirmed = alerted
+}
@@ -0,0 +1,31 @@
+package nuntiare
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// e164Regex matches an E.164 phone number: a plus sign and up to 15 digits without a leading zero
+var e164Regex = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// SetPhone sets the phone number SMS notifications of the wallet are sent to
+func (n *Nuntiare) SetPhone(address, phone string) error {
+	if err := n.ValidatePhone(phone); err != nil {
+		return err
+	}
+	return n.repo.UpsertPhoneProvider(address, phone)
+}
+
+// ValidatePhone returns ErrInvalidPhone unless the phone number is in E.164 format.
+// Phone numbers are refused when no SMS provider is configured.
+func (n *Nuntiare) ValidatePhone(phone string) error {
+	if !e164Regex.MatchString(phone) {
+		return fmt.Errorf("%w: phone must be in E.164 format, e.g. +14155550123", models.ErrInvalidPhone)
+	}
+	if n.config.SMSProvider == "" {
+		return fmt.Errorf("%w: SMS notifications are not supported", models.ErrInvalidPhone)
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package nuntiare
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// BlockQueueSize bounds the blocks waiting between two pipeline stages, and so the blocks in flight
+const BlockQueueSize = 64
+
+// blockJob is a block moving through the pipeline: header intake → block fetch workers → transfer extraction
+// workers → notification dispatch. Blocks are fetched and scanned in parallel and dispatched in chain order.
+type blockJob struct {
+	number uint64
+	// empty is set for heads without transactions, they are only marked processed
+	empty bool
+	block *types.Block
+
+	// Set by the extraction stage before done is closed
+	scanned *scannedBlock
+	release func()
+	// skip is set when the block is not dispatched: it couldn't be fetched (failed), or another instance
+	// processes it or the lock failed (spilled)
+	skip   bool
+	failed bool
+	done   chan struct{}
+}
+
+// scannedBlock holds the transfers detected in a block, in transaction order
+type scannedBlock struct {
+	number         uint64
+	hash           string
+	tokenTransfers [][]*blockchain.Transfer
+	xcbTransfers   []*types.Transaction
+}
+
+// pipelineStage counts the blocks passing a pipeline stage
+type pipelineStage struct {
+	name      string
+	workers   int
+	busy      atomic.Int64
+	processed atomic.Uint64
+	failed    atomic.Uint64
+	total     atomic.Int64 // nanoseconds
+	longest   atomic.Int64
+	last      atomic.Int64
+}
+
+// begin marks a block entering the stage and returns the function marking it done
+func (s *pipelineStage) begin() func(failed bool) {
+	start := time.Now()
+	s.busy.Add(1)
+	return func(failed bool) {
+		elapsed := int64(time.Since(start))
+		s.busy.Add(-1)
+		if failed {
+			s.failed.Add(1)
+		} else {
+			s.processed.Add(1)
+		}
+		s.total.Add(elapsed)
+		s.last.Store(elapsed)
+		for {
+			longest := s.longest.Load()
+			if elapsed <= longest || s.longest.CompareAndSwap(longest, elapsed) {
+				break
+			}
+		}
+	}
+}
+
+func (s *pipelineStage) stats(queued, capacity int) *models.PipelineStageStats {
+	stats := &models.PipelineStageStats{
+		Stage:         s.name,
+		Workers:       s.workers,
+		Queued:        queued,
+		QueueCapacity: capacity,
+		Busy:          s.busy.Load(),
+		Processed:     s.processed.Load(),
+		Failed:        s.failed.Load(),
+		MaxMs:         time.Duration(s.longest.Load()).Milliseconds(),
+		LastMs:        time.Duration(s.last.Load()).Milliseconds(),
+	}
+	if count := stats.Processed + stats.Failed; count > 0 {
+		stats.AvgMs = float64(s.total.Load()) / float64(count) / float64(time.Millisecond)
+	}
+	return stats
+}
+
+// blockPipeline holds the queues and counters of the block pipeline
+type blockPipeline struct {
+	// fetch and extract feed the worker stages, ordered feeds the dispatcher in submission order.
+	// ordered bounds the blocks in flight, so the worker queues never fill up before it.
+	fetch   chan *blockJob
+	extract chan *blockJob
+	ordered chan *blockJob
+
+	intake, fetcher, extractor, dispatcher *pipelineStage
+
+	// Blocks that couldn't be fetched, resubmitted with the next head
+	failedMu sync.Mutex
+	failed   []uint64
+}
+
+func newBlockPipeline(workers int) *blockPipeline {
+	workers = max(workers, 1)
+	return &blockPipeline{
+		fetch:      make(chan *blockJob, BlockQueueSize),
+		extract:    make(chan *blockJob, BlockQueueSize),
+		ordered:    make(chan *blockJob, BlockQueueSize),
+		intake:     &pipelineStage{name: models.PipelineStageIntake, workers: 1},
+		fetcher:    &pipelineStage{name: models.PipelineStageFetch, workers: workers},
+		extractor:  &pipelineStage{name: models.PipelineStageExtract, workers: workers},
+		dispatcher: &pipelineStage{name: models.PipelineStageDispatch, workers: 1},
+	}
+}
+
+// takeFailed returns the blocks that couldn't be fetched since the last call
+func (p *blockPipeline) takeFailed() []uint64 {
+	p.failedMu.Lock()
+	defer p.failedMu.Unlock()
+	failed := p.failed
+	p.failed = nil
+	return failed
+}
+
+func (p *blockPipeline) addFailed(number uint64) {
+	p.failedMu.Lock()
+	defer p.failedMu.Unlock()
+	p.failed = append(p.failed, number)
+}
+
+// startPipeline starts the block fetch and transfer extraction workers (BLOCK_PROCESSING_CONCURRENCY each)
+// and the dispatcher
+func (n *Nuntiare) startPipeline() {
+	for i := 0; i < n.pipeline.fetcher.workers; i++ {
+		n.wg.Add(1)
+		go n.runPipelineWorker(n.pipeline.fetch, n.fetchJob)
+	}
+	for i := 0; i < n.pipeline.extractor.workers; i++ {
+		n.wg.Add(1)
+		go n.runPipelineWorker(n.pipeline.extract, n.extractJob)
+	}
+	n.wg.Add(1)
+	go n.dispatchBlocks()
+}
+
+// runPipelineWorker works the jobs of a stage's queue until the instance shuts down
+func (n *Nuntiare) runPipelineWorker(queue chan *blockJob, work func(*blockJob)) {
+	defer n.wg.Done()
+	for {
+		select {
+		case job := <-queue:
+			work(job)
+		case <-n.ctx.Done():
+			n.logger.Debug("Block pipeline worker stopped")
+			return
+		}
+	}
+}
+
+// submitBlocks hands the new head and the blocks missed since the last submitted block to the pipeline,
+// in chain order. Blocks that couldn't be fetched before are submitted again. Returns the new last
+// submitted block.
+func (n *Nuntiare) submitBlocks(lastSubmitted uint64, header *types.Header) uint64 {
+	head := header.Number.Uint64()
+	numbers := n.pipeline.takeFailed()
+	if lastSubmitted > 0 && head > lastSubmitted+1 {
+		numbers = append(numbers, n.missedRange(lastSubmitted, head-1)...)
+	}
+	slices.Sort(numbers)
+	numbers = slices.Compact(numbers)
+	if len(numbers) > 0 {
+		n.logger.Info("Catching up missed blocks", "from", numbers[0], "to", numbers[len(numbers)-1], "blocks", len(numbers))
+	}
+
+	for _, number := range numbers {
+		if number == head {
+			continue
+		}
+		if !n.submitBlock(&blockJob{number: number}) {
+			return lastSubmitted
+		}
+	}
+	if !n.submitBlock(&blockJob{number: head, empty: header.EmptyBody()}) {
+		return lastSubmitted
+	}
+	return max(lastSubmitted, head)
+}
+
+// submitBlock queues a block for the fetch workers and the dispatcher. Waits while the pipeline is full.
+// Returns false when the instance shuts down.
+func (n *Nuntiare) submitBlock(job *blockJob) bool {
+	job.done = make(chan struct{})
+	end := n.pipeline.intake.begin()
+	select {
+	case n.pipeline.ordered <- job:
+	case <-n.ctx.Done():
+		end(true)
+		return false
+	}
+	// The fetch queue is as large as the ordered queue, so this doesn't block for long
+	select {
+	case n.pipeline.fetch <- job:
+	case <-n.ctx.Done():
+		end(true)
+		return false
+	}
+	end(false)
+	return true
+}
+
+// fetchJob fetches the block of a job and queues it for extraction. Blocks that can't be fetched are
+// retried with the next head.
+func (n *Nuntiare) fetchJob(job *blockJob) {
+	end := n.pipeline.fetcher.begin()
+	if !job.empty {
+		block, err := n.gocore.GetBlockByNumber(job.number)
+		if err != nil {
+			n.logger.Error("Failed to get block by number", "number", job.number, "error", err)
+			end(true)
+			job.skip, job.failed = true, true
+			close(job.done)
+			return
+		}
+		job.block = block
+	}
+	end(false)
+
+	select {
+	case n.pipeline.extract <- job:
+	case <-n.ctx.Done():
+	}
+}
+
+// extractJob claims the block and detects its transfers
+func (n *Nuntiare) extractJob(job *blockJob) {
+	defer close(job.done)
+	if job.empty || len(job.block.Transactions()) == 0 {
+		job.scanned = &scannedBlock{}
+		return
+	}
+
+	end := n.pipeline.extractor.begin()
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			n.logger.Error("Block extraction panicked", "block", job.number, "panic", r, "stack", stack)
+			n.reportPanic("extractTransfers", r, stack)
+			if job.release != nil {
+				job.release()
+			}
+			job.skip = true
+			end(true)
+		}
+	}()
+
+	release, ok := n.claimBlock(job.number)
+	if !ok {
+		job.skip = true
+		end(false)
+		return
+	}
+	job.release = release
+	job.scanned = n.collectTransfers(job.block)
+	end(false)
+}
+
+// dispatchBlocks is the dispatcher. It hands the payments and notifications of the scanned blocks off in
+// chain order and marks the blocks processed, so a slow block only holds back the blocks after it.
+func (n *Nuntiare) dispatchBlocks() {
+	defer n.wg.Done()
+	for {
+		var job *blockJob
+		select {
+		case job = <-n.pipeline.ordered:
+		case <-n.ctx.Done():
+			n.logger.Debug("Block dispatcher stopped")
+			return
+		}
+		select {
+		case <-job.done:
+		case <-n.ctx.Done():
+			n.logger.Debug("Block dispatcher stopped")
+			return
+		}
+		n.dispatchJob(job)
+	}
+}
+
+func (n *Nuntiare) dispatchJob(job *blockJob) {
+	if job.failed {
+		n.pipeline.addFailed(job.number)
+	}
+	if job.skip {
+		return
+	}
+
+	end := n.pipeline.dispatcher.begin()
+	defer func() {
+		if job.release != nil {
+			job.release()
+		}
+	}()
+	n.dispatchTransfers(job.scanned)
+	n.markBlockProcessed(job.number)
+	end(false)
+}
+
+// PipelineStats returns the counters of the block pipeline stages since the instance started
+func (n *Nuntiare) PipelineStats() []*models.PipelineStageStats {
+	return []*models.PipelineStageStats{
+		n.pipeline.intake.stats(0, 0),
+		n.pipeline.fetcher.stats(len(n.pipeline.fetch), cap(n.pipeline.fetch)),
+		n.pipeline.extractor.stats(len(n.pipeline.extract), cap(n.pipeline.extract)),
+		n.pipeline.dispatcher.stats(len(n.pipeline.ordered), cap(n.pipeline.ordered)),
+	}
+}
+
+// claimBlock acquires the processing lock of a block, so only one instance processes it. Returns the
+// function releasing the lock, or false if another instance processes the block or the lock failed
+// (the block is spilled then). Shadow instances process every block.
+func (n *Nuntiare) claimBlock(number uint64) (func(), bool) {
+	if n.config.ShadowMode {
+		return func() {}, true
+	}
+
+	// Lock name includes block number to allow different instances to process different blocks
+	// TTL is 30 seconds - if processing takes longer, another instance can take over
+	lockName := fmt.Sprintf("block_processor_%d", number)
+	acquired, err := n.tryAcquireLock(lockName, BlockProcessLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for block processing", "block", number, "error", err)
+		n.spillBlocks(number)
+		return nil, false
+	}
+	if !acquired {
+		// Another instance is processing this block, skip it
+		n.logger.Debug("Block already being processed by another instance", "block", number)
+		return nil, false
+	}
+
+	return func() {
+		if err := n.repo.ReleaseLock(lockName, n.instanceID); err != nil {
+			n.logger.Error("Failed to release lock", "block", number, "error", err)
+		}
+	}, true
+}
@@ -0,0 +1,198 @@
+package nuntiare
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/core-coin/go-core/v2/common"
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/internal/blockchain/blockchaintest"
+	"github.com/core-coin/nuntiare/internal/blockchain/fixtures"
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// pipelineRepository records the block locks and processed blocks of the pipeline. Other repository methods
+// aren't used by the pipeline when no transfer touches a registered address, and panic.
+type pipelineRepository struct {
+	models.Repository
+
+	mu        sync.Mutex
+	locks     map[string]string
+	processed []uint64
+	deposits  []string // Addresses looked up as exchange deposit addresses
+}
+
+func (r *pipelineRepository) TryAcquireLock(lockName, instanceID string, ttlSeconds int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, held := r.locks[lockName]; held {
+		return false, nil
+	}
+	r.locks[lockName] = instanceID
+	return true, nil
+}
+
+func (r *pipelineRepository) ReleaseLock(lockName, instanceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.locks[lockName] == instanceID {
+		delete(r.locks, lockName)
+	}
+	return nil
+}
+
+func (r *pipelineRepository) MarkBlockProcessed(number uint64, instanceID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processed = append(r.processed, number)
+	return nil
+}
+
+func (r *pipelineRepository) GetProcessedBlocks(from, to uint64) ([]uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var numbers []uint64
+	for _, number := range r.processed {
+		if number >= from && number <= to {
+			numbers = append(numbers, number)
+		}
+	}
+	return numbers, nil
+}
+
+func (r *pipelineRepository) GetExchangeAddresses(addresses []string) ([]*models.ExchangeAddress, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deposits = append(r.deposits, addresses...)
+	return nil, nil
+}
+
+// waitProcessed waits until the blocks are marked processed and returns the processed blocks in order
+func (r *pipelineRepository) waitProcessed(t *testing.T, count int) []uint64 {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		processed := slices.Clone(r.processed)
+		r.mu.Unlock()
+		if len(processed) >= count {
+			return processed
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%d blocks not processed in time", count)
+	return nil
+}
+
+type staticTokens []*models.Token
+
+func (t staticTokens) GetAllTokens() []*models.Token {
+	return t
+}
+
+// newPipelineTest returns an instance following the fake chain with the block pipeline started, and the
+// fixture transfer its blocks are made of. The registered address set is loaded and empty, so transfers
+// are detected but notify nobody.
+func newPipelineTest(t *testing.T) (*Nuntiare, *blockchaintest.Service, *pipelineRepository, *fixtures.Fixture) {
+	fixture, err := fixtures.Load(filepath.Join("..", "blockchain", "fixtures", "testdata", "cbc20_transfer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	common.DefaultNetworkID = common.NetworkID(fixture.NetworkID)
+
+	log, err := logger.NewLogger(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{NetworkID: big.NewInt(fixture.NetworkID), BlockProcessingConcurrency: 4}
+	chain := blockchaintest.New()
+	repo := &pipelineRepository{locks: make(map[string]string)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	n := &Nuntiare{
+		repo:         repo,
+		gocore:       chain,
+		logger:       log,
+		config:       cfg,
+		instanceID:   "pipeline-test",
+		ctx:          ctx,
+		cancel:       cancel,
+		lockFailures: make(map[string]int),
+		panics:       newPanicRecorder(),
+		pipeline:     newBlockPipeline(cfg.BlockProcessingConcurrency),
+		registered:   newRegisteredAddresses(time.Hour),
+		tokenCache: staticTokens{{
+			Address:  fixture.Token.Address,
+			Symbol:   fixture.Token.Symbol,
+			Decimals: fixture.Token.Decimals,
+			Type:     fixture.Token.Type,
+		}},
+	}
+	n.registered.update(nil, time.Now().Unix(), false)
+	n.startPipeline()
+	t.Cleanup(func() {
+		n.cancel()
+		n.wg.Wait()
+	})
+	return n, chain, repo, fixture
+}
+
+func TestPipelineProcessesMissedBlocksInChainOrder(t *testing.T) {
+	n, chain, repo, fixture := newPipelineTest(t)
+
+	first := chain.AddBlock([]*types.Transaction{fixture.Transaction}, nil)
+	lastSubmitted := n.submitBlocks(0, first.Header())
+
+	// Blocks 2 and 3 are missed, they are caught up when block 4 arrives
+	var head *types.Block
+	for i := 0; i < 3; i++ {
+		head = chain.AddBlock([]*types.Transaction{fixture.Transaction}, nil)
+	}
+	if lastSubmitted = n.submitBlocks(lastSubmitted, head.Header()); lastSubmitted != 4 {
+		t.Fatalf("last submitted block = %d, want 4", lastSubmitted)
+	}
+
+	if processed := repo.waitProcessed(t, 4); !slices.Equal(processed, []uint64{1, 2, 3, 4}) {
+		t.Fatalf("processed blocks = %v, want [1 2 3 4]", processed)
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if len(repo.locks) != 0 {
+		t.Fatalf("block locks still held after dispatch: %v", repo.locks)
+	}
+	recipient := validation.NormalizeAddress(fixture.Expected[0].To)
+	if len(repo.deposits) != 4 || repo.deposits[0] != recipient {
+		t.Fatalf("transfers dispatched to %v, want the fixture recipient %s once per block", repo.deposits, recipient)
+	}
+}
+
+func TestPipelineRetriesBlocksNotFetched(t *testing.T) {
+	n, chain, repo, fixture := newPipelineTest(t)
+
+	// The node announces block 1 before serving it
+	lastSubmitted := n.submitBlocks(0, &types.Header{Number: big.NewInt(1)})
+	deadline := time.Now().Add(5 * time.Second)
+	for n.pipeline.fetcher.failed.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("block 1 fetch didn't fail")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	chain.AddBlock([]*types.Transaction{fixture.Transaction}, nil)
+	head := chain.AddBlock([]*types.Transaction{fixture.Transaction}, nil)
+	n.submitBlocks(lastSubmitted, head.Header())
+
+	if processed := repo.waitProcessed(t, 2); !slices.Equal(processed, []uint64{1, 2}) {
+		t.Fatalf("processed blocks = %v, want [1 2]", processed)
+	}
+}
@@ -0,0 +1,178 @@
+package nuntiare
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// Subscription price sources
+	PriceSourceStatic   = "static"
+	PriceSourceAPI      = "api"
+	PriceSourceContract = "contract"
+
+	// SubscriptionPriceTimeout bounds a single price API request
+	SubscriptionPriceTimeout = 10 * time.Second
+	// MaxSubscriptionPriceResponseSize limits the price API response read into memory
+	MaxSubscriptionPriceResponseSize = 64 * 1024
+)
+
+// SubscriptionMonthCost returns the current cost of one subscription month in CTN
+func (n *Nuntiare) SubscriptionMonthCost() float64 {
+	if bits := n.monthCost.Load(); bits != 0 {
+		return math.Float64frombits(bits)
+	}
+	return n.config.SubscriptionMonthCost
+}
+
+// SubscriptionMonthCostFor returns the cost of one subscription month for wallets on the network.
+// Networks without a SUBSCRIPTION_MONTH_COST_<NETWORK> override pay the current subscription price.
+func (n *Nuntiare) SubscriptionMonthCostFor(network string) float64 {
+	if cost, ok := n.config.NetworkMonthCosts[network]; ok {
+		return cost
+	}
+	return n.SubscriptionMonthCost()
+}
+
+// GetSubscriptionPricing returns the subscription price for wallets on the network and where it comes from.
+// An empty network returns the pricing of the network the service runs on.
+func (n *Nuntiare) GetSubscriptionPricing(network string) *models.SubscriptionPricing {
+	if network == "" {
+		network = n.config.GetNetworkName()
+	}
+	if cost, ok := n.config.NetworkMonthCosts[network]; ok {
+		return &models.SubscriptionPricing{
+			Network:          network,
+			MonthCost:        cost,
+			MonthDuration:    int64(n.config.SubscriptionMonthDuration),
+			Currency:         "CTN",
+			Source:           PriceSourceStatic,
+			ReceivingAddress: n.config.ReceivingAddressFor(network),
+		}
+	}
+	return &models.SubscriptionPricing{
+		Network:          network,
+		MonthCost:        n.SubscriptionMonthCost(),
+		MonthDuration:    int64(n.config.SubscriptionMonthDuration),
+		Currency:         "CTN",
+		Source:           n.config.SubscriptionPriceSource,
+		UpdatedAt:        n.monthCostUpdatedAt.Load(),
+		ReceivingAddress: n.config.ReceivingAddressFor(network),
+	}
+}
+
+// refreshSubscriptionPrice fetches the month cost from the configured source. The previous price is kept
+// when the source is unavailable or returns a price outside of SUBSCRIPTION_PRICE_MIN/MAX.
+func (n *Nuntiare) refreshSubscriptionPrice() {
+	var price float64
+	var err error
+	switch n.config.SubscriptionPriceSource {
+	case PriceSourceAPI:
+		price, err = n.fetchAPIPrice()
+	case PriceSourceContract:
+		price, err = n.fetchContractPrice()
+	default:
+		return
+	}
+	if err != nil {
+		n.logger.Error("Failed to fetch subscription price, keeping the current one",
+			"error", err,
+			"source", n.config.SubscriptionPriceSource,
+			"price", n.SubscriptionMonthCost())
+		return
+	}
+
+	if price <= 0 || math.IsNaN(price) || math.IsInf(price, 0) ||
+		(n.config.SubscriptionPriceMin > 0 && price < n.config.SubscriptionPriceMin) ||
+		(n.config.SubscriptionPriceMax > 0 && price > n.config.SubscriptionPriceMax) {
+		n.logger.Error("Fetched subscription price out of bounds, keeping the current one",
+			"fetched", price,
+			"min", n.config.SubscriptionPriceMin,
+			"max", n.config.SubscriptionPriceMax,
+			"price", n.SubscriptionMonthCost())
+		return
+	}
+
+	if previous := n.SubscriptionMonthCost(); previous != price {
+		n.logger.Info("Subscription price updated", "previous", previous, "price", price, "source", n.config.SubscriptionPriceSource)
+	}
+	n.monthCost.Store(math.Float64bits(price))
+	n.monthCostUpdatedAt.Store(time.Now().Unix())
+}
+
+// fetchAPIPrice reads the month cost in CTN from the SUBSCRIPTION_PRICE_API_FIELD of the pricing API response
+func (n *Nuntiare) fetchAPIPrice() (float64, error) {
+	client := &http.Client{Timeout: SubscriptionPriceTimeout}
+	resp, err := client.Get(n.config.SubscriptionPriceAPIURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxSubscriptionPriceResponseSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read price: %w", err)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return 0, fmt.Errorf("failed to decode price: %w", err)
+	}
+	return jsonNumberAt(document, n.config.SubscriptionPriceAPIField)
+}
+
+// fetchContractPrice calls the price view function, which returns the month cost in CTN base units
+func (n *Nuntiare) fetchContractPrice() (float64, error) {
+	decimals, ok := n.ctnDecimals()
+	if !ok {
+		return 0, fmt.Errorf("CTN token %s is not in the token cache", n.config.SmartContractAddress)
+	}
+
+	value, err := n.gocore.CallUint256(n.config.SubscriptionPriceContract, n.config.SubscriptionPriceMethod)
+	if err != nil {
+		return 0, err
+	}
+
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	price, _ := new(big.Float).Quo(new(big.Float).SetInt(value), divisor).Float64()
+	return price, nil
+}
+
+// jsonNumberAt returns the number at the dot separated path of a decoded JSON document.
+// Numeric strings are accepted, as price APIs often return decimals as strings.
+func jsonNumberAt(document interface{}, path string) (float64, error) {
+	value := document
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return 0, fmt.Errorf("%s: not an object at %q", path, key)
+		}
+		if value, ok = object[key]; !ok {
+			return 0, fmt.Errorf("%s: missing field %q", path, key)
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: invalid number %q", path, v)
+		}
+		return price, nil
+	}
+	return 0, fmt.Errorf("%s: not a number", path)
+}
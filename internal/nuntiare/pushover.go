@@ -0,0 +1,32 @@
+package nuntiare
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// pushoverUserKeyRegex matches a Pushover user or group key
+var pushoverUserKeyRegex = regexp.MustCompile(`^[A-Za-z0-9]{30}$`)
+
+// SetPushover sets the Pushover user key notifications of the wallet are sent to
+func (n *Nuntiare) SetPushover(address, userKey string) error {
+	if err := n.ValidatePushoverUserKey(userKey); err != nil {
+		return err
+	}
+	return n.repo.UpsertPushoverProvider(address, userKey)
+}
+
+// ValidatePushoverUserKey returns ErrInvalidPushoverUserKey unless the key has the format of a Pushover user or
+// group key (30 letters and digits) and Pushover is enabled. Whether the key exists is only known when the first
+// notification is sent.
+func (n *Nuntiare) ValidatePushoverUserKey(userKey string) error {
+	if !pushoverUserKeyRegex.MatchString(userKey) {
+		return fmt.Errorf("%w: must be 30 letters and digits", models.ErrInvalidPushoverUserKey)
+	}
+	if n.config.PushoverAppToken == "" {
+		return fmt.Errorf("%w: Pushover notifications are not supported", models.ErrInvalidPushoverUserKey)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package nuntiare
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// ReceivingBalanceCheckInterval is how often the receiving address balance is compared with the sweep threshold
+	ReceivingBalanceCheckInterval = 10 * time.Minute
+	// receivingBalanceCheckLock ensures a single instance checks the receiving address balance
+	receivingBalanceCheckLock = "receiving_balance_check"
+	// ReceivingBalanceCheckLockTTL is the receiving balance check lock TTL in seconds
+	ReceivingBalanceCheckLockTTL = 60
+	// receivingBalanceAlertLock is held while the balance is above the sweep threshold, it marks the alert as sent
+	// for all instances. It is owned by its name rather than an instance, so any instance can release it.
+	receivingBalanceAlertLock = "receiving_balance_alert"
+	// ReceivingBalanceAlertLockTTL is how long (seconds) the alert is marked as sent, it is repeated daily while
+	// the address isn't swept
+	ReceivingBalanceAlertLockTTL = 24 * 3600
+)
+
+// GetReceivingBalance returns the current CTN balance of the receiving address of the network the service runs on
+func (n *Nuntiare) GetReceivingBalance() (float64, error) {
+	decimals, ok := n.ctnDecimals()
+	if !ok {
+		return 0, fmt.Errorf("CTN token %s is not in the token cache", n.config.SmartContractAddress)
+	}
+
+	balance, err := n.gocore.GetAddressCTNBalance(n.receivingAddress())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get receiving address balance: %w", err)
+	}
+
+	divisor := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+	amount, _ := new(big.Float).Quo(new(big.Float).SetInt(balance), divisor).Float64()
+	return amount, nil
+}
+
+// GetPaymentInflow returns the subscription payment totals of a period for payments in [from, to)
+func (n *Nuntiare) GetPaymentInflow(period string, from, to int64) ([]*models.PaymentInflow, error) {
+	bucketSeconds, ok := models.RollupPeriods[period]
+	if !ok {
+		return nil, fmt.Errorf("unknown period: %s", period)
+	}
+	return n.repo.GetPaymentInflow(bucketSeconds, from, to)
+}
+
+// checkReceivingBalanceThreshold alerts operators once when the receiving address balance reaches the
+// sweep threshold, and sends a resolved alert once the funds were swept below it
+func (n *Nuntiare) checkReceivingBalanceThreshold() {
+	acquired, err := n.tryAcquireLock(receivingBalanceCheckLock, ReceivingBalanceCheckLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for the receiving balance check", "error", err)
+		return
+	}
+	if !acquired {
+		// Another instance is checking
+		return
+	}
+	defer func() {
+		if err := n.repo.ReleaseLock(receivingBalanceCheckLock, n.instanceID); err != nil {
+			n.logger.Error("Failed to release receiving balance check lock", "error", err)
+		}
+	}()
+
+	balance, err := n.GetReceivingBalance()
+	if err != nil {
+		n.logger.Error("Failed to check receiving address balance", "error", err)
+		return
+	}
+
+	threshold := n.config.ReceivingBalanceThreshold
+	above := balance >= threshold
+	// Acquiring the alert lock fails while it marks the alert as sent
+	unmarked, err := n.repo.TryAcquireLock(receivingBalanceAlertLock, receivingBalanceAlertLock, ReceivingBalanceAlertLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to check the receiving balance alert", "error", err)
+		return
+	}
+	if !above {
+		if err := n.repo.ReleaseLock(receivingBalanceAlertLock, receivingBalanceAlertLock); err != nil {
+			n.logger.Error("Failed to clear the receiving balance alert", "error", err)
+			return
+		}
+	}
+	if above != unmarked {
+		// Alerted already, or below the threshold without an alert to resolve
+		return
+	}
+
+	message := fmt.Sprintf("The receiving address %s holds %.2f CTN, above the sweep threshold of %.2f CTN. Time to sweep it to cold storage.",
+		n.receivingAddress(), balance, threshold)
+	if !above {
+		message = fmt.Sprintf("The receiving address %s holds %.2f CTN, back below the sweep threshold of %.2f CTN.",
+			n.receivingAddress(), balance, threshold)
+	}
+	n.notificator.SendOpsAlert(&models.OpsAlert{
+		Type:      models.OpsAlertReceivingBalance,
+		Resolved:  !above,
+		Value:     balance,
+		Threshold: threshold,
+		Message:   message,
+	})
+}
+
+// receivingAddress returns the receiving address of the network the service runs on
+func (n *Nuntiare) receivingAddress() string {
+	if address, ok := n.config.NetworkReceivingAddresses[n.config.GetNetworkName()]; ok {
+		return address
+	}
+	return n.config.ReceivingAddress
+}
+
+// ctnDecimals returns the decimals of the subscription token from the token cache
+func (n *Nuntiare) ctnDecimals() (int, bool) {
+	if n.tokenCache == nil {
+		return 0, false
+	}
+	for _, token := range n.tokenCache.GetAllTokens() {
+		if validation.NormalizeAddress(token.Address) == n.config.SmartContractAddressNormalized {
+			return token.Decimals, true
+		}
+	}
+	return 0, false
+}
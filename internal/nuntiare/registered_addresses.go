@@ -0,0 +1,159 @@
+package nuntiare
+
+import (
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// RegisteredAddressReloadInterval is how often the registered address set is reloaded in full, which drops the
+	// addresses of removed wallets
+	RegisteredAddressReloadInterval = 1 * time.Hour
+	// registeredAddressOverlap is how far before the previous refresh registrations are loaded again, so
+	// registrations committed a while after they were timed aren't missed
+	registeredAddressOverlap = time.Minute
+	// registeredAddressMaxRefreshFailures is the number of refresh intervals without a successful refresh after
+	// which blocks are no longer filtered, the set would miss too many registrations of other instances
+	registeredAddressMaxRefreshFailures = 3
+)
+
+// registeredAddresses is the set of registered wallet and subscription addresses, used to skip the transfers
+// that don't involve any of them without a database lookup. Registrations made through the instance are added
+// right away, those of other instances with the next refresh. Until it is loaded, or while refreshes keep
+// failing, the set filters nothing. A nil set filters nothing.
+type registeredAddresses struct {
+	maxAge time.Duration
+
+	mu        sync.RWMutex
+	addresses map[string]struct{}
+	// cursor is the database time the latest refresh started at, 0 until loaded
+	cursor int64
+	// refreshedAt is when the set was last refreshed, on the local clock
+	refreshedAt time.Time
+}
+
+func newRegisteredAddresses(refreshInterval time.Duration) *registeredAddresses {
+	return &registeredAddresses{maxAge: registeredAddressMaxRefreshFailures * refreshInterval}
+}
+
+// contains reports whether the address may be registered: it is in the set or the set can't tell
+func (s *registeredAddresses) contains(address string) bool {
+	if s == nil {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.cursor == 0 || time.Since(s.refreshedAt) > s.maxAge {
+		return true
+	}
+	_, ok := s.addresses[validation.NormalizeAddress(address)]
+	return ok
+}
+
+// touches reports whether any of a transaction's transfers is sent to or from an address that may be registered
+func (s *registeredAddresses) touches(transfers []*blockchain.Transfer) bool {
+	for _, transfer := range transfers {
+		if s.contains(transfer.To) || s.contains(transfer.From) {
+			return true
+		}
+	}
+	return false
+}
+
+// add adds the addresses of a wallet registered through the instance
+func (s *registeredAddresses) add(addresses ...string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.addresses == nil {
+		return
+	}
+	for _, address := range addresses {
+		if address != "" {
+			s.addresses[validation.NormalizeAddress(address)] = struct{}{}
+		}
+	}
+}
+
+// since returns the registration time from which the next incremental refresh loads wallets
+func (s *registeredAddresses) since() int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursor - int64(registeredAddressOverlap/time.Second)
+}
+
+// loaded reports whether the set was loaded in full
+func (s *registeredAddresses) loaded() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursor != 0
+}
+
+// update replaces the set with the addresses, or adds them to it with merge, as of the database time cursor
+func (s *registeredAddresses) update(addresses []string, cursor int64, merge bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !merge || s.addresses == nil {
+		s.addresses = make(map[string]struct{}, len(addresses))
+	}
+	for _, address := range addresses {
+		if address != "" {
+			s.addresses[validation.NormalizeAddress(address)] = struct{}{}
+		}
+	}
+	s.cursor = cursor
+	s.refreshedAt = time.Now()
+}
+
+// refreshRegisteredAddresses loads the addresses of the wallets registered since the previous refresh into the
+// registered address set, or all of them with full. The current set is kept when loading fails.
+func (n *Nuntiare) refreshRegisteredAddresses(full bool) {
+	full = full || !n.registered.loaded()
+	var since int64
+	if !full {
+		since = n.registered.since()
+	}
+	cursor := n.now().Unix()
+	addresses, err := n.repo.GetRegisteredAddresses(since)
+	if err != nil {
+		n.logger.Error("Failed to load registered addresses, keeping the current ones", "error", err, "full", full)
+		return
+	}
+	n.registered.update(addresses, cursor, !full)
+	if full {
+		n.logger.Debug("Registered addresses loaded", "addresses", len(addresses))
+	}
+}
+
+// watchRegisteredAddresses loads the registered address set before the first block is processed and keeps it
+// up to date with the registrations of other instances
+func (n *Nuntiare) watchRegisteredAddresses() {
+	if n.registered == nil {
+		return
+	}
+	n.refreshRegisteredAddresses(true)
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ticker := time.NewTicker(time.Duration(n.config.AddressSetRefreshSeconds) * time.Second)
+		defer ticker.Stop()
+		reload := time.NewTicker(RegisteredAddressReloadInterval)
+		defer reload.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.refreshRegisteredAddresses(false)
+			case <-reload.C:
+				n.refreshRegisteredAddresses(true)
+			case <-n.ctx.Done():
+				n.logger.Debug("Registered address refresh stopped")
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,163 @@
+package nuntiare
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/core-coin/go-core/v2/core/types"
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// MaxReprocessBlockRange limits the number of blocks a single reprocess job may scan
+	MaxReprocessBlockRange = 100000
+	// ReprocessProgressInterval is how often (in blocks) job progress is persisted
+	ReprocessProgressInterval = 100
+)
+
+// ReprocessBlocks schedules a background re-scan of the block range [from, to].
+// Transfers to notifiable wallets are notified again unless a notification for the
+// same transfer was already sent. Subscription payments are not reprocessed.
+func (n *Nuntiare) ReprocessBlocks(from, to uint64, dryRun bool) (*models.ReprocessJob, error) {
+	if from > to {
+		return nil, fmt.Errorf("%w: from must not be greater than to", models.ErrInvalidBlockRange)
+	}
+	if to-from >= MaxReprocessBlockRange {
+		return nil, fmt.Errorf("%w: at most %d blocks can be reprocessed at once", models.ErrInvalidBlockRange, MaxReprocessBlockRange)
+	}
+
+	job := &models.ReprocessJob{
+		ID:        newJobID(),
+		FromBlock: from,
+		ToBlock:   to,
+		DryRun:    dryRun,
+		Status:    models.ReprocessJobPending,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := n.repo.AddReprocessJob(job); err != nil {
+		return nil, err
+	}
+
+	n.logger.Info("Reprocess job scheduled", "job", job.ID, "from", from, "to", to, "dry_run", dryRun)
+
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		n.runReprocessJob(*job)
+	}()
+
+	return job, nil
+}
+
+// GetReprocessJob returns a reprocess job by its ID
+func (n *Nuntiare) GetReprocessJob(id string) (*models.ReprocessJob, error) {
+	return n.repo.GetReprocessJob(id)
+}
+
+// runReprocessJob scans the job's block range and persists progress as it goes
+func (n *Nuntiare) runReprocessJob(job models.ReprocessJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := string(debug.Stack())
+			n.logger.Error("Reprocess job panicked", "job", job.ID, "panic", r, "stack", stack)
+			n.reportPanic("runReprocessJob", r, stack)
+			n.finishReprocessJob(&job, models.ReprocessJobFailed, fmt.Sprint(r))
+		}
+	}()
+
+	job.Status = models.ReprocessJobRunning
+	n.saveReprocessJob(&job)
+
+	numbers := make([]uint64, 0, job.ToBlock-job.FromBlock+1)
+	for number := job.FromBlock; number <= job.ToBlock; number++ {
+		numbers = append(numbers, number)
+	}
+
+	// Blocks are fetched concurrently but scanned in order, so progress is always a contiguous range
+	err := n.fetchBlocks(numbers, func(block *types.Block) bool {
+		n.scanBlock(block, func(transfers []*blockchain.Transfer) {
+			for _, notification := range n.transferNotifications(transfers) {
+				n.reprocessNotification(&job, notification)
+			}
+		}, func(tx *types.Transaction) {
+			if notification := n.xcbNotification(tx); notification != nil {
+				n.reprocessNotification(&job, notification)
+			}
+		})
+
+		number := block.NumberU64()
+		job.CurrentBlock = number
+		if (number-job.FromBlock+1)%ReprocessProgressInterval == 0 {
+			n.saveReprocessJob(&job)
+		}
+		return true
+	})
+	switch {
+	case n.ctx.Err() != nil:
+		n.finishReprocessJob(&job, models.ReprocessJobCancelled, "application is shutting down")
+	case err != nil:
+		n.finishReprocessJob(&job, models.ReprocessJobFailed, err.Error())
+	default:
+		n.finishReprocessJob(&job, models.ReprocessJobCompleted, "")
+	}
+}
+
+// reprocessNotification sends the notification of a notifiable wallet unless it was already sent.
+// Grouped notifications are matched by their first transfer.
+func (n *Nuntiare) reprocessNotification(job *models.ReprocessJob, notification *models.Notification) {
+	job.Matched++
+
+	exists, err := n.repo.NotificationExists(notification)
+	if err != nil {
+		n.logger.Error("Failed to check for duplicate notification", "error", err, "tx", notification.TxHash, "job", job.ID)
+		return
+	}
+	if exists {
+		job.Duplicates++
+		return
+	}
+
+	if job.DryRun {
+		n.logger.Info("Reprocess dry run match", "job", job.ID, "wallet", notification.Wallet, "currency", notification.Currency, "tx", notification.TxHash)
+		return
+	}
+
+	n.logger.Info("Sending reprocessed notification", "job", job.ID, "wallet", notification.Wallet, "currency", notification.Currency, "tx", notification.TxHash)
+	n.notificator.SendNotification(notification)
+	job.Sent++
+}
+
+// finishReprocessJob stores the final state of the job
+func (n *Nuntiare) finishReprocessJob(job *models.ReprocessJob, status, reason string) {
+	job.Status = status
+	job.Error = reason
+	job.FinishedAt = time.Now().Unix()
+	n.saveReprocessJob(job)
+
+	n.logger.Info("Reprocess job finished",
+		"job", job.ID,
+		"status", status,
+		"matched", job.Matched,
+		"sent", job.Sent,
+		"duplicates", job.Duplicates,
+		"error", reason)
+}
+
+func (n *Nuntiare) saveReprocessJob(job *models.ReprocessJob) {
+	if err := n.repo.UpdateReprocessJob(job); err != nil {
+		n.logger.Error("Failed to update reprocess job", "error", err, "job", job.ID)
+	}
+}
+
+// newJobID returns a random 32 character hex identifier
+func newJobID() string {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(bytes)
+}
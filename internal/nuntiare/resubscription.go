@@ -0,0 +1,171 @@
+package nuntiare
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// ResubscriptionSweepInterval is how often unpaid wallets are verified against stored and on-chain payments
+	ResubscriptionSweepInterval = 15 * time.Minute
+	// resubscriptionSweepLock ensures a single instance runs the sweep
+	resubscriptionSweepLock = "resubscription_sweep"
+	// ResubscriptionSweepLockTTL is the sweep lock TTL in seconds
+	ResubscriptionSweepLockTTL = 300
+)
+
+// verifyUnpaidSubscriptions self-heals wallets marked unpaid although their stored payments still cover
+// the current time (e.g. the wallet update failed after the payment was recorded), and warns when the
+// receiving address holds more CTN than all recorded payments, which means payments were missed.
+func (n *Nuntiare) verifyUnpaidSubscriptions() {
+	acquired, err := n.tryAcquireLock(resubscriptionSweepLock, ResubscriptionSweepLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for resubscription sweep", "error", err)
+		return
+	}
+	if !acquired {
+		// Another instance is sweeping
+		return
+	}
+	acquiredAt := time.Now()
+	defer func() {
+		n.checkLockOverrun(resubscriptionSweepLock, acquiredAt, ResubscriptionSweepLockTTL)
+		if err := n.repo.ReleaseLock(resubscriptionSweepLock, n.instanceID); err != nil {
+			n.logger.Error("Failed to release resubscription sweep lock", "error", err)
+		}
+	}()
+
+	n.checkReceivingBalance()
+
+	wallets, err := n.repo.GetUnpaidWalletsWithPayments()
+	if err != nil {
+		n.logger.Error("Failed to get unpaid wallets", "error", err)
+		return
+	}
+
+	now := n.now().Unix()
+	restored := 0
+	for _, wallet := range wallets {
+		payments, err := n.repo.GetSubscriptionPayments(wallet.Address)
+		if err != nil {
+			n.logger.Error("Failed to get subscription payments", "error", err, "wallet", wallet.Address)
+			continue
+		}
+
+		transfers, err := n.repo.GetSubscriptionTransfers(wallet.Address)
+		if err != nil {
+			n.logger.Error("Failed to get subscription transfers", "error", err, "wallet", wallet.Address)
+			continue
+		}
+
+		expiresAt := n.paidUntil(wallet.Address, payments, transfers)
+		if !n.subscriptionActive(expiresAt, now) {
+			continue
+		}
+
+		n.logger.Warn("Restoring subscription from stored payments",
+			"wallet", wallet.Address,
+			"expiresAt", wallet.SubscriptionExpiresAt,
+			"paidUntil", expiresAt)
+		err = n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+			if expiresAt > wallet.SubscriptionExpiresAt {
+				if err := repo.UpdateWalletSubscriptionExpiration(wallet.Address, expiresAt); err != nil {
+					return nil, fmt.Errorf("failed to restore subscription expiration: %w", err)
+				}
+			}
+			if err := repo.UpdateWalletPaidStatus(wallet.Address, true); err != nil {
+				return nil, fmt.Errorf("failed to restore wallet paid status: %w", err)
+			}
+			return []*models.WalletEvent{{
+				Wallet:                wallet.Address,
+				Type:                  models.WalletEventPaid,
+				Reason:                models.WalletEventReasonResubscription,
+				SubscriptionExpiresAt: max(expiresAt, wallet.SubscriptionExpiresAt),
+			}}, nil
+		})
+		if err != nil {
+			n.logger.Error("Failed to restore subscription", "error", err, "wallet", wallet.Address)
+			continue
+		}
+		restored++
+	}
+
+	if restored > 0 {
+		n.logger.Info("Resubscription sweep restored subscriptions", "count", restored)
+	}
+}
+
+// paidUntil replays the payments and subscription transfers of the wallet in order, the way
+// AddSubscriptionPaymentAndUpdatePaidStatus and TransferSubscription apply them, and returns the resulting
+// expiration timestamp
+func (n *Nuntiare) paidUntil(address string, payments []*models.SubscriptionPayment, transfers []*models.SubscriptionTransfer) int64 {
+	type event struct {
+		timestamp int64
+		seconds   int64 // Time added by a payment or incoming transfer
+		outgoing  bool  // The remaining time was transferred to another wallet
+	}
+	events := make([]event, 0, len(payments)+len(transfers))
+	for _, payment := range payments {
+		// Payments recorded before dynamic pricing were credited at SUBSCRIPTION_MONTH_COST
+		monthCost := payment.MonthCost
+		if monthCost <= 0 {
+			monthCost = n.config.SubscriptionMonthCost
+		}
+		events = append(events, event{
+			timestamp: payment.Timestamp,
+			seconds:   int64(payment.Amount / monthCost * n.config.SubscriptionMonthDuration),
+		})
+	}
+	for _, transfer := range transfers {
+		if transfer.FromAddress == address {
+			events = append(events, event{timestamp: transfer.Timestamp, outgoing: true})
+		} else {
+			events = append(events, event{timestamp: transfer.Timestamp, seconds: transfer.Seconds})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].timestamp < events[j].timestamp
+	})
+
+	var expiresAt int64
+	for _, e := range events {
+		if e.outgoing {
+			expiresAt = min(expiresAt, e.timestamp)
+			continue
+		}
+		expiresAt = max(expiresAt, e.timestamp) + e.seconds
+	}
+	return expiresAt
+}
+
+// checkReceivingBalance compares the CTN balance of the receiving address with the recorded payments.
+// Withdrawals lower the balance, so only a balance above the recorded total proves payments were missed.
+// Missed payments can't be attributed to wallets from the balance alone.
+func (n *Nuntiare) checkReceivingBalance() {
+	onChain, err := n.GetReceivingBalance()
+	if err != nil {
+		n.logger.Error("Failed to get receiving address balance", "error", err)
+		return
+	}
+	// With per-network receiving addresses, only payments of this network's wallets end up on the address
+	network := ""
+	if len(n.config.NetworkReceivingAddresses) > 0 {
+		network = n.config.GetNetworkName()
+	}
+	recorded, err := n.repo.SumSubscriptionPayments(network)
+	if err != nil {
+		n.logger.Error("Failed to sum subscription payments", "error", err)
+		return
+	}
+
+	// Tolerate float rounding of the recorded amounts
+	if onChain-recorded > 1e-6 {
+		n.logger.Warn("Receiving address holds more CTN than the recorded payments, payments may have been missed",
+			"onChain", onChain,
+			"recorded", recorded,
+			"unrecorded", onChain-recorded)
+	}
+}
@@ -0,0 +1,37 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// RetentionInterval is how often records past their retention period are removed
+const RetentionInterval = 24 * time.Hour
+
+// retentionRules returns the configured retention period of every data class
+func (n *Nuntiare) retentionRules() []models.RetentionRule {
+	return []models.RetentionRule{
+		{Class: models.RetentionNotifications, MaxAgeDays: n.config.NotificationRetentionDays},
+		{Class: models.RetentionPayments, MaxAgeDays: n.config.PaymentRetentionDays},
+		{Class: models.RetentionAudit, MaxAgeDays: n.config.AuditRetentionDays},
+	}
+}
+
+// applyRetention removes records older than the retention period of their data class
+func (n *Nuntiare) applyRetention() {
+	for _, rule := range n.retentionRules() {
+		if rule.MaxAgeDays == 0 {
+			continue
+		}
+		cutoff := time.Now().AddDate(0, 0, -rule.MaxAgeDays).Unix()
+		removed, err := n.repo.RemoveExpiredRecords(rule.Class, cutoff)
+		if err != nil {
+			n.logger.Error("Failed to apply retention", "class", rule.Class, "error", err)
+			continue
+		}
+		if removed > 0 {
+			n.logger.Info("Removed expired records", "class", rule.Class, "count", removed, "max_age_days", rule.MaxAgeDays)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// RollupInterval is how often the notification rollups are brought up to date
+const RollupInterval = 5 * time.Minute
+
+// aggregateRollups recomputes the notification rollups from the latest rolled up bucket onwards.
+// Earlier buckets are complete, so raw history is only scanned once (and can be removed by retention).
+func (n *Nuntiare) aggregateRollups() {
+	for period, bucketSeconds := range models.RollupPeriods {
+		from, err := n.repo.GetRollupWatermark(period)
+		if err != nil {
+			n.logger.Error("Failed to get rollup watermark", "period", period, "error", err)
+			continue
+		}
+		if err := n.repo.RollupNotifications(period, bucketSeconds, from); err != nil {
+			n.logger.Error("Failed to roll up notifications", "period", period, "error", err)
+		}
+	}
+}
+
+// GetNotificationRollups returns the notification counts of a period and dimension for buckets in [from, to)
+func (n *Nuntiare) GetNotificationRollups(period, dimension string, from, to int64) ([]*models.NotificationRollup, error) {
+	return n.repo.GetNotificationRollups(period, dimension, from, to)
+}
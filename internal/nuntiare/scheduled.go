@@ -0,0 +1,191 @@
+package nuntiare
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// ScheduledNotificationInterval is how often due scheduled notifications are dispatched
+	ScheduledNotificationInterval = 1 * time.Minute
+	// ScheduledNotificationBatchSize limits the scheduled notifications dispatched per run
+	ScheduledNotificationBatchSize = 100
+	// ScheduledSendBatchSize is how many recipients of a scheduled notification are sent to at once
+	ScheduledSendBatchSize = 50
+	// MaxScheduleAhead limits how far in the future a notification can be scheduled
+	MaxScheduleAhead = 365 * 24 * time.Hour
+	// scheduledNotificationLock ensures a single instance dispatches scheduled notifications
+	scheduledNotificationLock = "scheduled_notifications"
+	// ScheduledNotificationLockTTL is the dispatcher lock TTL in seconds
+	ScheduledNotificationLockTTL = 60
+)
+
+// ScheduleNotification schedules a message for delivery at sendAt (Unix timestamp).
+// An empty wallet sends the message to all active wallets, or only to those with the tag if one is given.
+// source names the scheduler (admin or a subsystem).
+func (n *Nuntiare) ScheduleNotification(wallet, tag, message string, sendAt int64, source string) (*models.ScheduledNotification, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, fmt.Errorf("%w: message is required", models.ErrInvalidSchedule)
+	}
+	now := time.Now()
+	if sendAt > now.Add(MaxScheduleAhead).Unix() {
+		return nil, fmt.Errorf("%w: send_at must be within %d days", models.ErrInvalidSchedule, int(MaxScheduleAhead.Hours()/24))
+	}
+	if wallet != "" {
+		wallet = validation.NormalizeAddress(wallet)
+	}
+	if tag != "" {
+		if wallet != "" {
+			return nil, fmt.Errorf("%w: tag only applies to notifications without a wallet", models.ErrInvalidSchedule)
+		}
+		normalized, err := normalizeWalletTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidSchedule, err)
+		}
+		tag = normalized
+	}
+
+	notification := &models.ScheduledNotification{
+		ID:        newJobID(),
+		Wallet:    wallet,
+		Tag:       tag,
+		Message:   message,
+		SendAt:    sendAt,
+		Source:    source,
+		Status:    models.ScheduledNotificationPending,
+		CreatedAt: now.Unix(),
+	}
+	if err := n.repo.AddScheduledNotification(notification); err != nil {
+		return nil, err
+	}
+
+	n.logger.Info("Notification scheduled", "id", notification.ID, "wallet", wallet, "tag", tag, "send_at", sendAt, "source", source)
+	return notification, nil
+}
+
+// GetScheduledNotification returns a scheduled notification by its ID
+func (n *Nuntiare) GetScheduledNotification(id string) (*models.ScheduledNotification, error) {
+	return n.repo.GetScheduledNotification(id)
+}
+
+// CancelScheduledNotification cancels a pending scheduled notification.
+// Returns false if it doesn't exist or was already dispatched.
+func (n *Nuntiare) CancelScheduledNotification(id string) (bool, error) {
+	return n.repo.CancelScheduledNotification(id)
+}
+
+// dispatchScheduledNotifications sends the scheduled notifications that are due
+func (n *Nuntiare) dispatchScheduledNotifications() {
+	acquired, err := n.tryAcquireLock(scheduledNotificationLock, ScheduledNotificationLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to acquire lock for scheduled notifications", "error", err)
+		return
+	}
+	if !acquired {
+		// Another instance is dispatching
+		return
+	}
+	acquiredAt := time.Now()
+	defer func() {
+		n.checkLockOverrun(scheduledNotificationLock, acquiredAt, ScheduledNotificationLockTTL)
+		if err := n.repo.ReleaseLock(scheduledNotificationLock, n.instanceID); err != nil {
+			n.logger.Error("Failed to release scheduled notifications lock", "error", err)
+		}
+	}()
+
+	due, err := n.repo.GetDueScheduledNotifications(time.Now().Unix(), ScheduledNotificationBatchSize)
+	if err != nil {
+		n.logger.Error("Failed to get due scheduled notifications", "error", err)
+		return
+	}
+
+	for _, scheduled := range due {
+		n.dispatchScheduledNotification(scheduled)
+	}
+}
+
+// dispatchScheduledNotification marks a due notification as sent and delivers it to its recipients.
+// The status is saved before sending so a notification is never delivered twice, and notifications cancelled
+// meanwhile aren't sent.
+func (n *Nuntiare) dispatchScheduledNotification(scheduled *models.ScheduledNotification) {
+	recipients, err := n.scheduledRecipients(scheduled)
+	scheduled.SentAt = time.Now().Unix()
+	if err != nil {
+		scheduled.Status = models.ScheduledNotificationFailed
+		scheduled.Error = err.Error()
+	} else {
+		scheduled.Status = models.ScheduledNotificationSent
+		scheduled.Recipients = int64(len(recipients))
+	}
+	updated, err := n.repo.FinishScheduledNotification(scheduled)
+	if err != nil {
+		n.logger.Error("Failed to update scheduled notification", "id", scheduled.ID, "error", err)
+		return
+	}
+	if !updated {
+		n.logger.Info("Scheduled notification no longer pending, not sending it", "id", scheduled.ID)
+		return
+	}
+	if scheduled.Status == models.ScheduledNotificationFailed {
+		n.logger.Warn("Scheduled notification failed", "id", scheduled.ID, "error", scheduled.Error)
+		return
+	}
+
+	n.logger.Info("Sending scheduled notification", "id", scheduled.ID, "recipients", len(recipients))
+	n.safeGo(func() { n.sendScheduledNotification(scheduled, recipients) }, "scheduledNotification")
+}
+
+// sendScheduledNotification delivers a scheduled notification to its recipients, ScheduledSendBatchSize at a
+// time, so a broadcast to all wallets doesn't start a goroutine per wallet
+func (n *Nuntiare) sendScheduledNotification(scheduled *models.ScheduledNotification, recipients []string) {
+	for start := 0; start < len(recipients); start += ScheduledSendBatchSize {
+		if n.ctx.Err() != nil {
+			n.logger.Warn("Scheduled notification interrupted by shutdown", "id", scheduled.ID, "sent", start, "recipients", len(recipients))
+			return
+		}
+		var batch sync.WaitGroup
+		for _, address := range recipients[start:min(start+ScheduledSendBatchSize, len(recipients))] {
+			notification := &models.Notification{
+				Wallet:        address,
+				CustomMessage: scheduled.Message,
+				NetworkID:     n.config.NetworkID.Int64(),
+				EventType:     models.EventAdminBroadcast,
+			}
+			batch.Add(1)
+			go func() {
+				defer batch.Done()
+				defer func() {
+					if r := recover(); r != nil {
+						stack := string(debug.Stack())
+						n.logger.Error("Scheduled notification panicked", "id", scheduled.ID, "wallet", notification.Wallet, "panic", r, "stack", stack)
+						n.reportPanic("scheduledNotification", r, stack)
+					}
+				}()
+				n.notificator.SendNotification(notification)
+			}()
+		}
+		batch.Wait()
+	}
+}
+
+// scheduledRecipients returns the wallets a scheduled notification is delivered to
+func (n *Nuntiare) scheduledRecipients(scheduled *models.ScheduledNotification) ([]string, error) {
+	if scheduled.Wallet == "" {
+		return n.repo.GetActiveWalletAddresses(scheduled.Tag)
+	}
+
+	wallet, err := n.repo.GetWallet(scheduled.Wallet)
+	if err != nil {
+		return nil, err
+	}
+	if !wallet.Active {
+		return nil, fmt.Errorf("wallet notifications are disabled")
+	}
+	return []string{wallet.Address}, nil
+}
@@ -0,0 +1,209 @@
+package nuntiare
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// MaxScreeningResponseSize limits the risk API response read into memory
+	MaxScreeningResponseSize = 64 * 1024
+	// MaxScreeningCacheSize bounds the cached sender scores, expired scores are dropped beyond it
+	MaxScreeningCacheSize = 100000
+	// ComplianceWebhookTimeout bounds a single compliance webhook request
+	ComplianceWebhookTimeout = 10 * time.Second
+	// ComplianceAlertLockTTL is how long (seconds) the compliance alert of a transfer to a wallet is claimed, so
+	// the mined transfer after a pending alert, other instances and reprocessing don't alert again
+	ComplianceAlertLockTTL = 7 * 24 * 3600
+)
+
+// senderScreening is a cached risk score of a sender
+type senderScreening struct {
+	score     float64
+	expiresAt time.Time
+}
+
+// screenRequest is the body posted to the risk API
+type screenRequest struct {
+	Address string `json:"address"`
+	Network string `json:"network"`
+}
+
+// screenSender tags the notification with the risk score of its sender and reports high-risk transfers to the
+// compliance webhook. Returns false if the wallet isn't notified because the sender is high risk and
+// SCREENING_WITHHOLD_HIGH_RISK is set. Internal transfers and trusted senders aren't screened, and transfers
+// are notified unscreened when the risk API fails.
+func (n *Nuntiare) screenSender(notification *models.Notification) bool {
+	if n.config.ScreeningAPIURL == "" || notification.From == "" || notification.Internal || notification.VerifiedSender != "" {
+		return true
+	}
+
+	score, err := n.senderRiskScore(notification.From)
+	if err != nil {
+		n.logger.Warn("Failed to screen sender, notifying unscreened", "error", err, "from", notification.From, "tx", notification.TxHash)
+		return true
+	}
+	notification.RiskScore = &score
+	if score < n.config.ScreeningHighRiskScore {
+		return true
+	}
+
+	notification.HighRisk = true
+	withhold := n.config.ScreeningWithholdHighRisk
+	n.logger.Warn("Transfer from a high-risk sender", "wallet", notification.Wallet, "from", notification.From,
+		"risk_score", score, "tx", notification.TxHash, "withheld", withhold)
+	n.reportHighRiskTransfer(notification, withhold)
+	return !withhold
+}
+
+// senderRiskScore returns the risk score of the address, from the cache or the risk API
+func (n *Nuntiare) senderRiskScore(address string) (float64, error) {
+	address = validation.NormalizeAddress(address)
+	now := time.Now()
+
+	n.screeningsMu.Lock()
+	cached, ok := n.screenings[address]
+	n.screeningsMu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.score, nil
+	}
+
+	score, err := n.fetchRiskScore(address)
+	if err != nil {
+		return 0, err
+	}
+	if n.config.ScreeningCacheMinutes > 0 {
+		n.screeningsMu.Lock()
+		if len(n.screenings) >= MaxScreeningCacheSize {
+			for cachedAddress, screening := range n.screenings {
+				if !now.Before(screening.expiresAt) {
+					delete(n.screenings, cachedAddress)
+				}
+			}
+		}
+		if len(n.screenings) < MaxScreeningCacheSize {
+			n.screenings[address] = senderScreening{
+				score:     score,
+				expiresAt: now.Add(time.Duration(n.config.ScreeningCacheMinutes) * time.Minute),
+			}
+		}
+		n.screeningsMu.Unlock()
+	}
+	return score, nil
+}
+
+// fetchRiskScore posts the address to the risk API and reads the score from the SCREENING_API_FIELD of the response
+func (n *Nuntiare) fetchRiskScore(address string) (float64, error) {
+	body, err := json.Marshal(&screenRequest{Address: address, Network: n.config.GetNetworkName()})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal screening request: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, time.Duration(n.config.ScreeningTimeoutSeconds)*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.ScreeningAPIURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create screening request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.config.ScreeningAPIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+n.config.ScreeningAPIKey)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to screen address: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxScreeningResponseSize))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read screening response: %w", err)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return 0, fmt.Errorf("failed to decode screening response: %w", err)
+	}
+	return jsonNumberAt(document, n.config.ScreeningAPIField)
+}
+
+// reportHighRiskTransfer posts a high-risk transfer to the compliance webhook, once per transfer and wallet.
+// An alert that can't be posted is released, so the next screening of the transfer (e.g. a reprocess job)
+// reports it again. Shadow instances don't report.
+func (n *Nuntiare) reportHighRiskTransfer(notification *models.Notification, withheld bool) {
+	if n.config.ComplianceWebhookURL == "" || n.config.ShadowMode {
+		return
+	}
+
+	lockName := fmt.Sprintf("compliance_alert_%s_%s", notification.TxHash, validation.NormalizeAddress(notification.Wallet))
+	acquired, err := n.repo.TryAcquireLock(lockName, n.instanceID, ComplianceAlertLockTTL)
+	if err != nil {
+		n.logger.Error("Failed to claim compliance alert", "error", err, "tx", notification.TxHash)
+		return
+	}
+	if !acquired {
+		return
+	}
+
+	alert := &models.ComplianceAlert{
+		Type:         models.ComplianceAlertHighRiskTransfer,
+		Wallet:       validation.NormalizeAddress(notification.Wallet),
+		From:         validation.NormalizeAddress(notification.From),
+		RiskScore:    *notification.RiskScore,
+		Threshold:    n.config.ScreeningHighRiskScore,
+		Amount:       notification.Amount,
+		Currency:     notification.Currency,
+		TokenAddress: notification.TokenAddress,
+		TokenType:    notification.TokenType,
+		TokenID:      notification.TokenID,
+		TxHash:       notification.TxHash,
+		NetworkID:    notification.NetworkID,
+		Withheld:     withheld,
+		DetectedAt:   time.Now().Unix(),
+	}
+	if err := n.postComplianceAlert(alert); err != nil {
+		n.logger.Error("Failed to send compliance alert", "error", err, "wallet", alert.Wallet, "tx", alert.TxHash)
+		if err := n.repo.ReleaseLock(lockName, n.instanceID); err != nil {
+			n.logger.Error("Failed to release compliance alert", "error", err, "tx", alert.TxHash)
+		}
+	}
+}
+
+// postComplianceAlert posts an alert to the compliance webhook
+func (n *Nuntiare) postComplianceAlert(alert *models.ComplianceAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to marshal compliance alert: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(n.ctx, ComplianceWebhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.ComplianceWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create compliance alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.config.ComplianceWebhookToken != "" {
+		req.Header.Set("Authorization", "Bearer "+n.config.ComplianceWebhookToken)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post compliance alert: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}
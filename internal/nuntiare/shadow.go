@@ -0,0 +1,26 @@
+package nuntiare
+
+import (
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// sendNotification sends a transfer notification, or only records it when running in shadow mode
+func (n *Nuntiare) sendNotification(notification *models.Notification) {
+	if !n.config.ShadowMode {
+		n.safeGo(func() { n.notificator.SendNotification(notification) }, "sendNotification")
+		return
+	}
+
+	shadow := models.NewShadowNotification(notification, n.instanceID, time.Now().Unix())
+	if err := n.repo.AddShadowNotification(shadow); err != nil {
+		n.logger.Error("Failed to record shadow notification", "error", err, "wallet", notification.Wallet, "tx", notification.TxHash)
+	}
+}
+
+// CompareShadowNotifications compares the notifications recorded by shadow instances with
+// the ones production sent in [from, to)
+func (n *Nuntiare) CompareShadowNotifications(from, to int64) (*models.ShadowReport, error) {
+	return n.repo.CompareShadowNotifications(from, to)
+}
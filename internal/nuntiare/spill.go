@@ -0,0 +1,197 @@
+package nuntiare
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/core-coin/go-core/v2/core/types"
+)
+
+// SpillReplayInterval is how often the database is checked to replay the blocks spilled to the journal
+const SpillReplayInterval = 10 * time.Second
+
+// spillJournal is an append-only file of block numbers that couldn't be processed because the database was
+// unavailable. It survives restarts, so the blocks are processed once the database recovers.
+type spillJournal struct {
+	mu   sync.Mutex
+	path string
+}
+
+// append adds the blocks to the journal and syncs it to disk
+func (j *spillJournal) append(numbers ...uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	file, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open spill journal: %w", err)
+	}
+	defer file.Close()
+
+	var buf bytes.Buffer
+	for _, number := range numbers {
+		buf.WriteString(strconv.FormatUint(number, 10))
+		buf.WriteByte('\n')
+	}
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write spill journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync spill journal: %w", err)
+	}
+	return nil
+}
+
+// load returns the journaled blocks in journal order, including duplicates
+func (j *spillJournal) load() ([]uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.read()
+}
+
+func (j *spillJournal) read() ([]uint64, error) {
+	file, err := os.Open(j.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill journal: %w", err)
+	}
+	defer file.Close()
+
+	var numbers []uint64
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// A line torn by a crash while appending is skipped
+		number, err := strconv.ParseUint(string(bytes.TrimSpace(scanner.Bytes())), 10, 64)
+		if err != nil {
+			continue
+		}
+		numbers = append(numbers, number)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read spill journal: %w", err)
+	}
+	return numbers, nil
+}
+
+// remove drops one entry per replayed block. Blocks spilled again while they were replayed stay journaled.
+func (j *spillJournal) remove(replayed []uint64) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	numbers, err := j.read()
+	if err != nil {
+		return err
+	}
+	drop := make(map[uint64]int, len(replayed))
+	for _, number := range replayed {
+		drop[number]++
+	}
+	var buf bytes.Buffer
+	for _, number := range numbers {
+		if drop[number] > 0 {
+			drop[number]--
+			continue
+		}
+		buf.WriteString(strconv.FormatUint(number, 10))
+		buf.WriteByte('\n')
+	}
+
+	if buf.Len() == 0 {
+		if err := os.Remove(j.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove spill journal: %w", err)
+		}
+		return nil
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return fmt.Errorf("failed to write spill journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("failed to replace spill journal: %w", err)
+	}
+	return nil
+}
+
+// spillBlocks journals blocks that couldn't be processed because the database was unavailable.
+// Without SPILL_JOURNAL_PATH they are only logged and have to be reprocessed manually.
+func (n *Nuntiare) spillBlocks(numbers ...uint64) {
+	if len(numbers) == 0 {
+		return
+	}
+	if n.spill == nil {
+		n.logger.Error("Blocks not processed while the database is unavailable", "from", numbers[0], "to", numbers[len(numbers)-1])
+		return
+	}
+	if err := n.spill.append(numbers...); err != nil {
+		n.logger.Error("Failed to spill blocks, they have to be reprocessed manually", "error", err,
+			"from", numbers[0], "to", numbers[len(numbers)-1])
+		return
+	}
+	n.logger.Warn("Database unavailable, blocks spilled to the journal", "from", numbers[0], "to", numbers[len(numbers)-1])
+}
+
+// replaySpilledBlocks processes the journaled blocks in order once the database is reachable again.
+// Blocks processed in the meantime (e.g. by another instance) are skipped.
+func (n *Nuntiare) replaySpilledBlocks() {
+	journaled, err := n.spill.load()
+	if err != nil {
+		n.logger.Error("Failed to load spill journal", "error", err)
+		return
+	}
+	if len(journaled) == 0 {
+		return
+	}
+	if err := n.pingDatabase(); err != nil {
+		n.logger.Debug("Database still unavailable, spilled blocks not replayed yet", "blocks", len(journaled))
+		return
+	}
+
+	// Replay in chain order, once per block
+	numbers := slices.Clone(journaled)
+	slices.Sort(numbers)
+	numbers = slices.Compact(numbers)
+
+	processed, err := n.repo.GetProcessedBlocks(numbers[0], numbers[len(numbers)-1])
+	if err != nil {
+		n.logger.Error("Failed to get processed blocks, spilled blocks not replayed yet", "error", err)
+		return
+	}
+	skip := make(map[uint64]bool, len(processed))
+	for _, number := range processed {
+		skip[number] = true
+	}
+	numbers = slices.DeleteFunc(numbers, func(number uint64) bool { return skip[number] })
+
+	n.logger.Info("Replaying spilled blocks", "blocks", len(numbers), "journaled", len(journaled))
+	var replayed []uint64
+	err = n.fetchBlocks(numbers, func(block *types.Block) bool {
+		if len(block.Transactions()) > 0 {
+			n.checkBlock(block)
+		} else {
+			n.markBlockProcessed(block.NumberU64())
+		}
+		replayed = append(replayed, block.NumberU64())
+		return true
+	})
+	if err != nil {
+		n.logger.Error("Replaying spilled blocks stopped, the rest is retried", "error", err, "replayed", len(replayed))
+	}
+
+	// Journal entries of skipped blocks and duplicates are dropped as well
+	done := replayed
+	if err == nil {
+		done = journaled
+	}
+	if err := n.spill.remove(done); err != nil {
+		n.logger.Error("Failed to update spill journal", "error", err)
+	}
+}
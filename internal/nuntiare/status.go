@@ -0,0 +1,49 @@
+package nuntiare
+
+import (
+	"context"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+const (
+	// MaxHealthyBlockLag is how long after the last block's timestamp the blockchain is reported as degraded
+	MaxHealthyBlockLag = 2 * time.Minute
+	// DatabasePingTimeout bounds the database ping of health checks, a database that doesn't answer in time
+	// is reported as degraded instead of blocking the caller
+	DatabasePingTimeout = 2 * time.Second
+)
+
+// Status returns the coarse health of the service for client apps
+func (n *Nuntiare) Status() *models.ServiceStatus {
+	status := &models.ServiceStatus{
+		Status:      models.ServiceStatusOK,
+		BlockHeight: n.lastBlockNumber.Load(),
+		Degraded:    []string{},
+	}
+
+	if blockTime := n.lastBlockTime.Load(); blockTime > 0 {
+		status.LagSeconds = max(n.now().Unix()-int64(blockTime), 0)
+	}
+	if !n.headersSubscribed.Load() || status.BlockHeight == 0 || time.Duration(status.LagSeconds)*time.Second > MaxHealthyBlockLag {
+		status.Degraded = append(status.Degraded, models.SubsystemBlockchain)
+	}
+
+	if err := n.pingDatabase(); err != nil {
+		n.logger.Warn("Database ping failed", "error", err)
+		status.Degraded = append(status.Degraded, models.SubsystemDatabase)
+	}
+
+	if len(status.Degraded) > 0 {
+		status.Status = models.ServiceStatusDegraded
+	}
+	return status
+}
+
+// pingDatabase checks the database answers within DatabasePingTimeout
+func (n *Nuntiare) pingDatabase() error {
+	ctx, cancel := context.WithTimeout(n.ctx, DatabasePingTimeout)
+	defer cancel()
+	return n.repo.Ping(ctx)
+}
@@ -0,0 +1,172 @@
+package nuntiare
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/kafka"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// StreamQueueSize bounds the messages waiting to be published, further messages are dropped
+	StreamQueueSize = 10000
+	// StreamBatchSize is the maximum number of messages published in one request
+	StreamBatchSize = 500
+	// StreamLinger is how long messages are collected before a batch smaller than StreamBatchSize is published
+	StreamLinger = time.Second
+	// StreamMaxAttempts is the number of times a batch is published before its messages are dropped
+	StreamMaxAttempts = 5
+	// StreamRetryDelay is the delay before publishing a failed batch again, doubled after every attempt
+	StreamRetryDelay = 2 * time.Second
+	// StreamShutdownTimeout bounds publishing the queued messages on shutdown
+	StreamShutdownTimeout = 5 * time.Second
+)
+
+// newEventStream returns the Kafka producer of the event stream, or nil when it isn't configured. Shadow
+// instances don't publish.
+func newEventStream(cfg *kafka.Config, shadow bool) (*kafka.Producer, chan *kafka.Message) {
+	if cfg.Brokers == "" || shadow {
+		return nil, nil
+	}
+	return kafka.NewProducer(*cfg), make(chan *kafka.Message, StreamQueueSize)
+}
+
+// streamTransfers publishes the transfers detected in the block to the transfer topic, keyed by recipient so
+// the transfers of a wallet keep their order
+func (n *Nuntiare) streamTransfers(scanned *scannedBlock) {
+	if n.streamQueue == nil || n.config.KafkaTransferTopic == "" {
+		return
+	}
+
+	now := time.Now().Unix()
+	indexes := make(map[string]int)
+	for _, transfer := range n.blockTransfers(scanned) {
+		index := indexes[transfer.TxHash]
+		indexes[transfer.TxHash]++
+
+		token := models.NativeTokenPreference
+		if transfer.TokenAddress != "" {
+			token = validation.NormalizeAddress(transfer.TokenAddress)
+		}
+		to := validation.NormalizeAddress(transfer.To)
+		n.publish(n.config.KafkaTransferTopic, to, &models.StreamTransfer{
+			NetworkID:     n.config.NetworkID.Int64(),
+			BlockNumber:   scanned.number,
+			BlockHash:     scanned.hash,
+			TxHash:        transfer.TxHash,
+			TransferIndex: index,
+			From:          validation.NormalizeAddress(transfer.From),
+			To:            to,
+			Token:         token,
+			Currency:      transfer.TokenSymbol,
+			TokenType:     transfer.TokenType,
+			TokenID:       transfer.TokenID,
+			Amount:        transfer.Amount,
+			DetectedAt:    now,
+		})
+	}
+}
+
+// streamWalletEvent publishes a wallet event to the subscription topic, keyed by wallet
+func (n *Nuntiare) streamWalletEvent(event *models.WalletEvent) {
+	if n.streamQueue == nil || n.config.KafkaSubscriptionTopic == "" {
+		return
+	}
+	n.publish(n.config.KafkaSubscriptionTopic, event.Wallet, &models.StreamWalletEvent{
+		WalletEvent: event,
+		NetworkID:   n.config.NetworkID.Int64(),
+	})
+}
+
+// publish queues a message for the event stream without blocking, the message is dropped when the queue is full
+func (n *Nuntiare) publish(topic, key string, payload any) {
+	value, err := json.Marshal(payload)
+	if err != nil {
+		n.logger.Error("Failed to encode event stream message", "error", err, "topic", topic)
+		return
+	}
+	select {
+	case n.streamQueue <- &kafka.Message{Topic: topic, Key: []byte(key), Value: value, Time: time.Now()}:
+	default:
+		n.logger.Warn("Event stream queue full, message dropped", "topic", topic, "key", key)
+	}
+}
+
+// runEventStream publishes the queued messages in batches until the instance stops, then publishes the
+// messages still queued
+func (n *Nuntiare) runEventStream() {
+	defer n.wg.Done()
+	defer n.stream.Close()
+
+	ticker := time.NewTicker(StreamLinger)
+	defer ticker.Stop()
+	batch := make([]*kafka.Message, 0, StreamBatchSize)
+	for {
+		select {
+		case message := <-n.streamQueue:
+			batch = append(batch, message)
+			// The rest of a batch interrupted by the shutdown is published with the queued messages below
+			if len(batch) >= StreamBatchSize {
+				batch = append(batch[:0], n.publishBatch(n.ctx, batch)...)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				batch = append(batch[:0], n.publishBatch(n.ctx, batch)...)
+			}
+		case <-n.ctx.Done():
+			ctx, cancel := context.WithTimeout(context.Background(), StreamShutdownTimeout)
+			defer cancel()
+			for len(n.streamQueue) > 0 {
+				batch = append(batch, <-n.streamQueue)
+			}
+			for len(batch) > 0 {
+				size := min(len(batch), StreamBatchSize)
+				if remaining := n.publishBatch(ctx, batch[:size]); len(remaining) > 0 {
+					batch = append(remaining, batch[size:]...)
+					break
+				}
+				batch = batch[size:]
+			}
+			if len(batch) > 0 {
+				n.logger.Warn("Event stream messages dropped on shutdown", "count", len(batch))
+			}
+			n.logger.Debug("Event stream stopped")
+			return
+		}
+	}
+}
+
+// publishBatch publishes a batch, retrying the messages that weren't acknowledged with backoff. Messages that
+// still fail after StreamMaxAttempts are dropped. Returns the messages that weren't published or dropped
+// when ctx ended.
+func (n *Nuntiare) publishBatch(ctx context.Context, batch []*kafka.Message) []*kafka.Message {
+	delay := StreamRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := n.stream.Produce(ctx, batch)
+		if err == nil {
+			return nil
+		}
+		var produceErr *kafka.ProduceError
+		if errors.As(err, &produceErr) {
+			batch = produceErr.Failed
+		}
+		if ctx.Err() != nil {
+			return batch
+		}
+		if attempt >= StreamMaxAttempts {
+			n.logger.Error("Failed to publish event stream messages, dropped", "error", err, "count", len(batch), "attempts", attempt)
+			return nil
+		}
+		n.logger.Warn("Failed to publish event stream messages, retrying", "error", err, "count", len(batch), "attempt", attempt)
+		select {
+		case <-time.After(delay):
+			delay *= 2
+		case <-ctx.Done():
+			return batch
+		}
+	}
+}
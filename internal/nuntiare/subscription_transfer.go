@@ -0,0 +1,76 @@
+package nuntiare
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// TransferSubscription moves the remaining subscription time of the wallet to another registered wallet of the
+// same user (same or linked OriginID, or joined to the same user) on the same network, e.g. after the user rotated wallets. The source wallet's
+// subscription ends now; its payments keep their linkage and the transfer is recorded for auditing.
+func (n *Nuntiare) TransferSubscription(from *models.Wallet, toAddress, clientIP string) (*models.SubscriptionTransfer, error) {
+	to, err := n.repo.GetWallet(toAddress)
+	if err != nil {
+		return nil, err
+	}
+	if to.Address == from.Address {
+		return nil, fmt.Errorf("%w: destination is the same wallet", models.ErrInvalidSubscriptionTransfer)
+	}
+	sameUser := from.UserID != "" && from.UserID == to.UserID
+	if !sameUser && !n.IsWalletOrigin(to, from.OriginID) && !n.IsWalletOrigin(from, to.OriginID) {
+		return nil, fmt.Errorf("%w: destination wallet belongs to another user", models.ErrInvalidSubscriptionTransfer)
+	}
+	// Prices differ per network, time paid at the devin price can't be moved to mainnet
+	if to.Network != from.Network {
+		return nil, fmt.Errorf("%w: destination wallet is on another network", models.ErrInvalidSubscriptionTransfer)
+	}
+
+	transfer := &models.SubscriptionTransfer{
+		FromAddress: from.Address,
+		ToAddress:   to.Address,
+		ClientIP:    clientIP,
+		Timestamp:   n.now().Unix(),
+	}
+	err = n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+		if err := repo.TransferSubscription(transfer); err != nil {
+			return nil, err
+		}
+		// The destination is extended from its expiration if it was still subscribed, otherwise from now
+		toEventType := models.WalletEventPaid
+		if transfer.ToExpiresAt-transfer.Seconds > transfer.Timestamp {
+			toEventType = models.WalletEventExtended
+		}
+		return []*models.WalletEvent{{
+			Wallet:                transfer.FromAddress,
+			Type:                  models.WalletEventExpired,
+			Reason:                models.WalletEventReasonTransfer,
+			SubscriptionExpiresAt: transfer.Timestamp,
+			Reference:             transfer.ToAddress,
+			Timestamp:             transfer.Timestamp,
+		}, {
+			Wallet:                transfer.ToAddress,
+			Type:                  toEventType,
+			Reason:                models.WalletEventReasonTransfer,
+			SubscriptionExpiresAt: transfer.ToExpiresAt,
+			Reference:             transfer.FromAddress,
+			Timestamp:             transfer.Timestamp,
+		}}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	n.logger.Info("Subscription transferred",
+		"from", transfer.FromAddress,
+		"to", transfer.ToAddress,
+		"seconds", transfer.Seconds,
+		"toExpiresAt", transfer.ToExpiresAt,
+		"clientIP", clientIP)
+	return transfer, nil
+}
+
+// GetSubscriptionTransfers returns the subscription transfers from or to the wallet, oldest first
+func (n *Nuntiare) GetSubscriptionTransfers(address string) ([]*models.SubscriptionTransfer, error) {
+	return n.repo.GetSubscriptionTransfers(address)
+}
@@ -0,0 +1,74 @@
+package nuntiare
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// MaxMinAmountCurrencyLength limits the currency symbols of minimum amounts
+const MaxMinAmountCurrencyLength = 32
+
+// suppressionReason returns why a transfer notification for the wallet should be suppressed,
+// or an empty string if it should be sent
+func (n *Nuntiare) suppressionReason(wallet *models.Wallet, notification *models.Notification) string {
+	if n.config.SuppressSelfTransfers && notification.From != "" {
+		sender := validation.NormalizeAddress(notification.From)
+		if sender == validation.NormalizeAddress(wallet.Address) || sender == validation.NormalizeAddress(wallet.SubscriptionAddress) {
+			return "self transfer"
+		}
+	}
+
+	// NFT amounts are token counts, minimum amounts only apply to fungible tokens and XCB
+	if notification.TokenType != "CBC721" && wallet.BelowMinAmount(notification.Currency, notification.Amount) {
+		return "below minimum amount"
+	}
+
+	if !n.tokenNotified(wallet, notification) {
+		return "token not notified"
+	}
+
+	return ""
+}
+
+// inQuietPeriod reports whether the notification falls in the registration quiet period of the wallet.
+// Transfers from high-risk senders and of lookalike tokens are security events and are notified anyway, so this
+// is checked after the notification has been labeled and screened.
+func (n *Nuntiare) inQuietPeriod(wallet *models.Wallet, notification *models.Notification) bool {
+	if n.config.RegistrationQuietMinutes <= 0 || notification.HighRisk || notification.LookalikeToken {
+		return false
+	}
+	quietUntil := wallet.CreatedAt + int64(n.config.RegistrationQuietMinutes)*int64(time.Minute/time.Second)
+	return time.Now().Unix() < quietUntil
+}
+
+// SetMinAmounts sets the minimum amount per currency of transfers the wallet is notified about (empty removes all).
+// Currencies are matched by their uppercase symbol.
+func (n *Nuntiare) SetMinAmounts(address string, minAmounts map[string]float64) error {
+	if err := n.ValidateMinAmounts(minAmounts); err != nil {
+		return err
+	}
+	normalized := make(map[string]float64, len(minAmounts))
+	for currency, amount := range minAmounts {
+		normalized[strings.ToUpper(strings.TrimSpace(currency))] = amount
+	}
+	return n.repo.SetWalletMinAmounts(address, normalized)
+}
+
+// ValidateMinAmounts returns ErrInvalidMinAmount unless every currency has a positive minimum amount
+func (n *Nuntiare) ValidateMinAmounts(minAmounts map[string]float64) error {
+	for currency, amount := range minAmounts {
+		currency = strings.TrimSpace(currency)
+		if currency == "" || len(currency) > MaxMinAmountCurrencyLength {
+			return fmt.Errorf("%w: currency must be a token symbol of at most %d characters", models.ErrInvalidMinAmount, MaxMinAmountCurrencyLength)
+		}
+		if amount <= 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			return fmt.Errorf("%w: %s must be greater than 0", models.ErrInvalidMinAmount, currency)
+		}
+	}
+	return nil
+}
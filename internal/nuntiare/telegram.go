@@ -0,0 +1,29 @@
+package nuntiare
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	tgModels "github.com/go-telegram/bot/models"
+)
+
+// TelegramUpdateProcessor handles updates received through the Telegram webhook
+type TelegramUpdateProcessor interface {
+	ProcessUpdate(update *tgModels.Update) error
+}
+
+// ProcessTelegramWebhook routes a Telegram webhook update to the bot.
+// If TELEGRAM_WEBHOOK_SECRET is set, the update must carry the same secret token.
+func (n *Nuntiare) ProcessTelegramWebhook(token string, update *tgModels.Update) error {
+	if secret := n.config.TelegramWebhookSecret; secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return models.ErrUnauthorized
+	}
+
+	if n.telegram == nil {
+		return fmt.Errorf("telegram bot not configured")
+	}
+
+	n.logger.Debug("Received Telegram webhook update", "update_id", update.ID)
+	return n.telegram.ProcessUpdate(update)
+}
@@ -0,0 +1,101 @@
+package nuntiare
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/internal/notificator"
+	"github.com/core-coin/nuntiare/internal/templates"
+)
+
+// channelLimit returns the configured message length handling for a channel
+func (n *Nuntiare) channelLimit(channel string) notificator.MessageLimit {
+	switch channel {
+	case templates.ChannelTelegram:
+		return notificator.MessageLimit{MaxLength: n.config.TelegramMaxMessageLength, Overflow: n.config.TelegramMessageOverflow}
+	case templates.ChannelSMS:
+		return notificator.MessageLimit{MaxLength: n.config.SMSMaxMessageLength, Overflow: n.config.SMSMessageOverflow}
+	case templates.ChannelDiscord:
+		return notificator.MessageLimit{MaxLength: notificator.DiscordMaxDescriptionLength, Overflow: notificator.OverflowTruncate}
+	}
+	return notificator.MessageLimit{}
+}
+
+// PreviewTemplate renders a template with sample notifications for each channel and lints it
+func (n *Nuntiare) PreviewTemplate(text string, channels []string) *models.TemplatePreview {
+	preview := &models.TemplatePreview{Valid: true, Errors: []string{}, Renders: []*models.TemplateRender{}}
+
+	if strings.TrimSpace(text) == "" {
+		preview.Valid = false
+		preview.Errors = append(preview.Errors, "template is empty")
+		return preview
+	}
+
+	tmpl, err := templates.Parse("preview", text)
+	if err != nil {
+		preview.Valid = false
+		preview.Errors = append(preview.Errors, err.Error())
+		return preview
+	}
+
+	if len(channels) == 0 {
+		channels = templates.Channels
+	}
+
+	samples := templates.SampleNotifications()
+	sampleNames := make([]string, 0, len(samples))
+	for name := range samples {
+		sampleNames = append(sampleNames, name)
+	}
+	sort.Strings(sampleNames)
+
+	for _, channel := range channels {
+		limit := n.channelLimit(channel)
+		for _, name := range sampleNames {
+			notification := samples[name]
+			data := templates.NewData(notification)
+			detailsURL := ""
+			if n.config.PublicURL != "" {
+				detailsURL = fmt.Sprintf("%s/n/%s", n.config.PublicURL, notification.ID)
+				data.DetailsURL = detailsURL
+			}
+
+			output, err := templates.Render(tmpl, data)
+			if err != nil {
+				preview.Valid = false
+				preview.Errors = append(preview.Errors, fmt.Sprintf("%s/%s: %v", channel, name, err))
+				continue
+			}
+
+			render := &models.TemplateRender{
+				Channel:  channel,
+				Sample:   name,
+				Parts:    notificator.FitMessage(output, limit, detailsURL),
+				Length:   len([]rune(output)),
+				Warnings: []string{},
+			}
+			if output == "" {
+				render.Warnings = append(render.Warnings, "rendered message is empty")
+			}
+			if limit.MaxLength > 0 && render.Length > limit.MaxLength {
+				render.Warnings = append(render.Warnings, fmt.Sprintf("message is %d characters, exceeds %s limit of %d and will be %s", render.Length, channel, limit.MaxLength, overflowVerb(limit.Overflow)))
+			}
+			if !strings.Contains(output, data.Link) {
+				render.Warnings = append(render.Warnings, "message does not include the transaction link")
+			}
+			preview.Renders = append(preview.Renders, render)
+		}
+	}
+
+	return preview
+}
+
+// overflowVerb describes what happens to messages over the channel limit
+func overflowVerb(overflow string) string {
+	if overflow == notificator.OverflowSplit {
+		return "split"
+	}
+	return "truncated"
+}
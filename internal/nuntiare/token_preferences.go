@@ -0,0 +1,89 @@
+package nuntiare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// MaxTokenPreferences is the maximum number of token preferences of a wallet
+const MaxTokenPreferences = 100
+
+// SetTokenPreference opts the wallet in or out of notifications about a token, given as "xcb" or its contract address.
+// Once a wallet opted in to a token, it is only notified about the tokens it opted in to.
+func (n *Nuntiare) SetTokenPreference(address, token string, notify bool) (*models.WalletTokenPreference, error) {
+	token, err := normalizeTokenPreference(token)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences, err := n.repo.GetWalletTokenPreferences(address)
+	if err != nil {
+		return nil, err
+	}
+	exists := false
+	for _, preference := range preferences {
+		exists = exists || preference.Token == token
+	}
+	if !exists && len(preferences) >= MaxTokenPreferences {
+		return nil, fmt.Errorf("%w: at most %d tokens can have a preference", models.ErrInvalidTokenPreference, MaxTokenPreferences)
+	}
+
+	preference := &models.WalletTokenPreference{
+		WalletAddress: address,
+		Token:         token,
+		Notify:        notify,
+		UpdatedAt:     time.Now().Unix(),
+	}
+	if err := n.repo.SetWalletTokenPreference(preference); err != nil {
+		return nil, err
+	}
+	return preference, nil
+}
+
+// GetTokenPreferences returns the token preferences of the wallet, oldest first
+func (n *Nuntiare) GetTokenPreferences(address string) ([]*models.WalletTokenPreference, error) {
+	return n.repo.GetWalletTokenPreferences(address)
+}
+
+// RemoveTokenPreference removes the preference of the wallet for a token. Returns false if it has none.
+func (n *Nuntiare) RemoveTokenPreference(address, token string) (bool, error) {
+	token, err := normalizeTokenPreference(token)
+	if err != nil {
+		return false, err
+	}
+	return n.repo.RemoveWalletTokenPreference(address, token)
+}
+
+// tokenNotified reports whether the wallet's token preferences allow notifying about the notification's token.
+// Notifications are sent when the preferences can't be loaded.
+func (n *Nuntiare) tokenNotified(wallet *models.Wallet, notification *models.Notification) bool {
+	preferences, err := n.repo.GetWalletTokenPreferences(wallet.Address)
+	if err != nil {
+		n.logger.Error("Failed to get token preferences", "error", err, "address", wallet.Address)
+		return true
+	}
+
+	token := models.NativeTokenPreference
+	if notification.TokenAddress != "" {
+		token = validation.NormalizeAddress(notification.TokenAddress)
+	}
+	return models.TokenNotified(preferences, token)
+}
+
+// normalizeTokenPreference returns the token of a preference: NativeTokenPreference for XCB or the
+// normalized contract address
+func normalizeTokenPreference(token string) (string, error) {
+	token = strings.TrimSpace(token)
+	if strings.EqualFold(token, models.NativeTokenPreference) {
+		return models.NativeTokenPreference, nil
+	}
+	normalized, err := validation.ValidateAndNormalizeAddress(token)
+	if err != nil {
+		return "", fmt.Errorf("%w: token must be xcb or a token contract address: %v", models.ErrInvalidTokenPreference, err)
+	}
+	return normalized, nil
+}
@@ -0,0 +1,152 @@
+package nuntiare
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// TrustedSenderRefreshInterval is how often the trusted senders are reloaded,
+// so changes made through another instance are picked up
+const TrustedSenderRefreshInterval = 5 * time.Minute
+
+// symbolConfusables maps characters that look like latin capitals or are commonly swapped for them
+// (Cyrillic and Greek homoglyphs, digits) to the letter they imitate
+var symbolConfusables = map[rune]rune{
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'Х': 'X', 'У': 'Y',
+	'Ѕ': 'S', 'І': 'I', 'Ј': 'J', 'Ԁ': 'D',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T',
+	'Υ': 'Y', 'Χ': 'X',
+	'0': 'O', '1': 'I', 'L': 'I', '5': 'S', '8': 'B', '$': 'S',
+}
+
+// GetTrustedSenders returns the trusted senders
+func (n *Nuntiare) GetTrustedSenders() ([]*models.TrustedSender, error) {
+	return n.repo.GetTrustedSenders()
+}
+
+// SetTrustedSender stores a trusted sender and applies it right away. The address must be normalized.
+func (n *Nuntiare) SetTrustedSender(address, name, category string) (*models.TrustedSender, error) {
+	sender := &models.TrustedSender{
+		Address:   address,
+		Name:      strings.TrimSpace(name),
+		Category:  category,
+		UpdatedAt: time.Now().Unix(),
+	}
+	if err := n.repo.UpsertTrustedSender(sender); err != nil {
+		return nil, err
+	}
+	n.reloadTrustedSenders()
+	return sender, nil
+}
+
+// DeleteTrustedSender removes a trusted sender, returns false if the address isn't trusted
+func (n *Nuntiare) DeleteTrustedSender(address string) (bool, error) {
+	deleted, err := n.repo.DeleteTrustedSender(address)
+	if err != nil || !deleted {
+		return deleted, err
+	}
+	n.reloadTrustedSenders()
+	return true, nil
+}
+
+// reloadTrustedSenders loads the trusted senders, keeping the current ones on failure
+func (n *Nuntiare) reloadTrustedSenders() {
+	senders, err := n.repo.GetTrustedSenders()
+	if err != nil {
+		n.logger.Error("Failed to reload trusted senders", "error", err)
+		return
+	}
+	byAddress := make(map[string]*models.TrustedSender, len(senders))
+	for _, sender := range senders {
+		byAddress[sender.Address] = sender
+	}
+	n.trustedSenders.Store(&byAddress)
+}
+
+// trustedSender returns the trusted sender with the address, nil if the address isn't trusted
+func (n *Nuntiare) trustedSender(address string) *models.TrustedSender {
+	senders := n.trustedSenders.Load()
+	if senders == nil || address == "" {
+		return nil
+	}
+	return (*senders)[validation.NormalizeAddress(address)]
+}
+
+// labelTrustedSender sets the verified sender name of notifications from trusted senders, and marks tokens
+// imitating the symbol of a verified token
+func (n *Nuntiare) labelTrustedSender(notification *models.Notification) {
+	if sender := n.trustedSender(notification.From); sender != nil {
+		notification.VerifiedSender = sender.Name
+	}
+	notification.LookalikeToken = n.isLookalikeToken(notification.Currency, notification.TokenAddress)
+}
+
+// isLookalikeToken reports whether the token's symbol looks like the symbol of a verified token (XCB, CTN or a
+// token contract registered as trusted sender) while the token isn't that token.
+// Symbols are compared after removing separators and mapping homoglyphs, so "USDТ" (Cyrillic Т) matches "USDT".
+func (n *Nuntiare) isLookalikeToken(symbol, tokenAddress string) bool {
+	if tokenAddress == "" {
+		return false // Native XCB
+	}
+	address := validation.NormalizeAddress(tokenAddress)
+	if address == n.config.SmartContractAddressNormalized {
+		return false
+	}
+	if sender := n.trustedSender(address); sender != nil && sender.Category == models.TrustedSenderContract {
+		return false
+	}
+
+	skeleton := symbolSkeleton(symbol)
+	if skeleton == "" {
+		return false
+	}
+	for _, verified := range n.verifiedSymbols() {
+		if skeleton == symbolSkeleton(verified) {
+			return true
+		}
+	}
+	return false
+}
+
+// verifiedSymbols returns the symbols of XCB, CTN and the cached tokens whose contract is a trusted sender
+func (n *Nuntiare) verifiedSymbols() []string {
+	symbols := []string{"XCB", "CTN"}
+	if n.tokenCache == nil {
+		return symbols
+	}
+	for _, token := range n.tokenCache.GetAllTokens() {
+		address := validation.NormalizeAddress(token.Address)
+		if address == n.config.SmartContractAddressNormalized {
+			symbols = append(symbols, token.Symbol)
+			continue
+		}
+		if sender := n.trustedSender(address); sender != nil && sender.Category == models.TrustedSenderContract {
+			symbols = append(symbols, token.Symbol)
+		}
+	}
+	return symbols
+}
+
+// symbolSkeleton returns the uppercased letters and digits of a token symbol with homoglyphs replaced
+// by the latin letter they imitate
+func symbolSkeleton(symbol string) string {
+	var skeleton strings.Builder
+	for _, r := range symbol {
+		r = unicode.ToUpper(r)
+		if r >= 0xFF01 && r <= 0xFF5E {
+			r -= 0xFEE0 // Fullwidth forms
+			r = unicode.ToUpper(r)
+		}
+		if replacement, ok := symbolConfusables[r]; ok {
+			r = replacement
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			skeleton.WriteRune(r)
+		}
+	}
+	return skeleton.String()
+}
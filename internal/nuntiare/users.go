@@ -0,0 +1,99 @@
+package nuntiare
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// JoinUser adds the wallet to the user of the primary wallet, creating the user if the primary wallet has none.
+// Afterwards the wallet is notified through the notification channels and preferences of the user's primary wallet.
+// A wallet of another user leaves it first.
+func (n *Nuntiare) JoinUser(primary, wallet *models.Wallet) (*models.User, error) {
+	if primary.Address == wallet.Address {
+		return nil, fmt.Errorf("%w: a wallet can't join itself", models.ErrInvalidUserLink)
+	}
+	if primary.UserID != "" && primary.UserID == wallet.UserID {
+		return n.repo.GetUser(primary.UserID)
+	}
+	if _, err := n.LeaveUser(wallet); err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	if primary.UserID != "" {
+		existing, err := n.repo.GetUser(primary.UserID)
+		if err != nil {
+			return nil, err
+		}
+		user = existing
+	} else {
+		user = &models.User{
+			ID:            newJobID(),
+			PrimaryWallet: primary.Address,
+			CreatedAt:     time.Now().Unix(),
+		}
+		if err := n.repo.AddUser(user); err != nil {
+			return nil, err
+		}
+		primary.UserID = user.ID
+	}
+
+	if err := n.repo.SetWalletUser(wallet.Address, user.ID); err != nil {
+		return nil, err
+	}
+	wallet.UserID = user.ID
+	n.logger.Info("Wallet joined user", "address", wallet.Address, "user", user.ID, "primary_wallet", user.PrimaryWallet)
+	return user, nil
+}
+
+// LeaveUser removes the wallet from its user, afterwards it is notified through its own notification channels again.
+// The primary wallet can only leave once it is the last wallet of the user. Returns false if the wallet has no user.
+func (n *Nuntiare) LeaveUser(wallet *models.Wallet) (bool, error) {
+	if wallet.UserID == "" {
+		return false, nil
+	}
+	user, err := n.repo.GetUser(wallet.UserID)
+	if err != nil {
+		return false, err
+	}
+	wallets, err := n.repo.GetUserWallets(user.ID)
+	if err != nil {
+		return false, err
+	}
+
+	switch {
+	case user.PrimaryWallet == wallet.Address && len(wallets) > 1:
+		return false, fmt.Errorf("%w: the primary wallet can't leave while %d other wallets belong to the user",
+			models.ErrInvalidUserLink, len(wallets)-1)
+	case len(wallets) <= 2:
+		// A user needs a wallet besides the primary one
+		err = n.repo.RemoveUser(user.ID)
+	default:
+		err = n.repo.SetWalletUser(wallet.Address, "")
+	}
+	if err != nil {
+		return false, err
+	}
+
+	wallet.UserID = ""
+	n.logger.Info("Wallet left user", "address", wallet.Address, "user", user.ID)
+	return true, nil
+}
+
+// GetUserWallets returns the user of the wallet and all of its wallets, or nil if the wallet has no user
+func (n *Nuntiare) GetUserWallets(wallet *models.Wallet) (*models.User, []*models.Wallet, error) {
+	if wallet.UserID == "" {
+		return nil, nil, nil
+	}
+	user, err := n.repo.GetUser(wallet.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	wallets, err := n.repo.GetUserWallets(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, wallets, nil
+}
@@ -0,0 +1,46 @@
+package nuntiare
+
+import "github.com/core-coin/nuntiare/internal/models"
+
+// updateWallets applies wallet changes with fn and appends the state transitions it returns to the wallets'
+// event streams in the same database transaction, then publishes them to Kafka. Nothing is stored if fn or
+// recording the events fails.
+func (n *Nuntiare) updateWallets(fn func(repo models.Repository) ([]*models.WalletEvent, error)) error {
+	var recorded []*models.WalletEvent
+	err := n.repo.WithWalletEvents(func(repo models.Repository) ([]*models.WalletEvent, error) {
+		events, err := fn(repo)
+		if err != nil {
+			return nil, err
+		}
+		recordedAt := n.now().Unix()
+		for _, event := range events {
+			event.RecordedAt = recordedAt
+			if event.Timestamp == 0 {
+				event.Timestamp = recordedAt
+			}
+		}
+		recorded = events
+		return events, nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, event := range recorded {
+		n.streamWalletEvent(event)
+	}
+	return nil
+}
+
+// GetWalletEvents returns the events of the wallet that happened at or before the timestamp, oldest first
+func (n *Nuntiare) GetWalletEvents(address string, until int64) ([]*models.WalletEvent, error) {
+	return n.repo.GetWalletEvents(address, until)
+}
+
+// GetWalletState derives the state of the wallet at the timestamp from its events
+func (n *Nuntiare) GetWalletState(address string, at int64) (*models.WalletState, error) {
+	events, err := n.repo.GetWalletEvents(address, at)
+	if err != nil {
+		return nil, err
+	}
+	return models.ReplayWalletEvents(address, events, at), nil
+}
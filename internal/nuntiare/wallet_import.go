@@ -0,0 +1,189 @@
+package nuntiare
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// telegramUsernamePattern matches valid Telegram usernames (without @)
+var telegramUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{5,32}$`)
+
+// ImportWallets validates and registers wallets from a partner's user list.
+// Every row is reported separately; invalid rows don't stop the import of the others.
+func (n *Nuntiare) ImportWallets(rows []*models.WalletImportRow, dryRun bool) *models.WalletImportReport {
+	report := &models.WalletImportReport{DryRun: dryRun, Total: len(rows), Results: make([]*models.WalletImportResult, 0, len(rows))}
+	seen := make(map[string]int, len(rows))
+	var unverified []string // Imported wallets with an email to verify
+
+	for _, row := range rows {
+		result := &models.WalletImportResult{Line: row.Line, Address: row.Address}
+		report.Results = append(report.Results, result)
+
+		result.Errors = n.validateImportRow(row)
+		if len(result.Errors) == 0 {
+			result.Address = row.Address
+			if line, ok := seen[row.Address]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("address: duplicate of line %d", line))
+			} else if exists, err := n.repo.CheckWalletExists(row.Address); err != nil {
+				n.logger.Error("Failed to check wallet exists", "error", err, "address", row.Address)
+				result.Errors = append(result.Errors, "address: failed to check existing wallet")
+			} else if exists {
+				result.Errors = append(result.Errors, "address: wallet already registered")
+			}
+			seen[row.Address] = row.Line
+		}
+		if len(result.Errors) > 0 {
+			report.Failed++
+			continue
+		}
+
+		if row.OriginID == "" {
+			originID, err := newSecret()
+			if err != nil {
+				result.Errors = append(result.Errors, "origin_id: "+err.Error())
+				report.Failed++
+				continue
+			}
+			row.OriginID = originID
+			result.OriginID = originID
+		}
+
+		// Imports can't prove owning a subscription address, they only join the wallets of the same app
+		if err := n.checkSubscriptionAddress(&models.Wallet{Address: row.Address, SubscriptionAddress: row.SubscriptionAddress, OriginID: row.OriginID}, nil); err != nil {
+			if errors.Is(err, models.ErrSubscriptionAddressTaken) {
+				result.Errors = append(result.Errors, "subscriber: already used by another app's wallets")
+			} else {
+				n.logger.Error("Failed to check subscription address", "error", err, "address", row.Address)
+				result.Errors = append(result.Errors, "failed to register wallet")
+			}
+			report.Failed++
+			continue
+		}
+
+		if !dryRun {
+			wallet := importedWallet(row, n.now().Unix())
+			err := n.updateWallets(func(repo models.Repository) ([]*models.WalletEvent, error) {
+				if err := repo.AddNewWallet(wallet); err != nil {
+					return nil, err
+				}
+				return []*models.WalletEvent{{
+					Wallet:                wallet.Address,
+					Type:                  models.WalletEventRegistered,
+					Reason:                models.WalletEventReasonImport,
+					SubscriptionExpiresAt: wallet.SubscriptionExpiresAt,
+					Timestamp:             wallet.CreatedAt,
+				}}, nil
+			})
+			if err != nil {
+				switch {
+				case errors.Is(err, models.ErrWalletExists):
+					result.Errors = append(result.Errors, "address: wallet already registered")
+				default:
+					n.logger.Error("Failed to import wallet", "error", err, "address", row.Address)
+					result.Errors = append(result.Errors, "failed to register wallet")
+				}
+				report.Failed++
+				continue
+			}
+			n.registered.add(wallet.Address, wallet.SubscriptionAddress)
+			if row.Email != "" {
+				unverified = append(unverified, row.Address)
+			}
+		}
+		result.Success = true
+		report.Imported++
+	}
+
+	// Verification emails are sent one after another in the background instead of one goroutine per wallet
+	if len(unverified) > 0 {
+		go func() {
+			for _, address := range unverified {
+				n.requestEmailVerification(address)
+			}
+		}()
+	}
+
+	n.logger.Info("Wallet import finished", "total", report.Total, "imported", report.Imported, "failed", report.Failed, "dry_run", dryRun)
+	return report
+}
+
+// validateImportRow validates and normalizes an imported row, returning its errors
+func (n *Nuntiare) validateImportRow(row *models.WalletImportRow) []string {
+	var errs []string
+
+	if address, err := validation.ValidateAndNormalizeAddress(row.Address); err != nil {
+		errs = append(errs, "address: "+err.Error())
+	} else {
+		row.Address = address
+	}
+	if address, err := validation.ValidateAndNormalizeAddress(row.SubscriptionAddress); err != nil {
+		errs = append(errs, "subscriber: "+err.Error())
+	} else {
+		row.SubscriptionAddress = address
+	}
+
+	if row.Origin == "" {
+		errs = append(errs, "origin: is required")
+	}
+	if row.OriginID != "" && len(row.OriginID) != 32 {
+		errs = append(errs, "origin_id: must have 32 characters")
+	}
+
+	switch row.Network = strings.ToLower(row.Network); row.Network {
+	case "":
+		row.Network = n.config.GetNetworkName()
+	case "xcb", "xab":
+	default:
+		errs = append(errs, "network: must be one of: xcb xab")
+	}
+
+	row.Telegram = strings.TrimPrefix(row.Telegram, "@")
+	if row.Telegram == "" && row.Email == "" {
+		errs = append(errs, "at least one notification method (telegram or email) is required")
+	}
+	if row.Telegram != "" && !telegramUsernamePattern.MatchString(row.Telegram) {
+		errs = append(errs, "telegram: invalid username")
+	}
+	if row.Email != "" {
+		if parsed, err := mail.ParseAddress(row.Email); err != nil || parsed.Address != row.Email {
+			errs = append(errs, "email: must be a valid email address")
+		}
+	}
+
+	return errs
+}
+
+// importedWallet builds the wallet registered for a validated import row
+func importedWallet(row *models.WalletImportRow, createdAt int64) *models.Wallet {
+	return &models.Wallet{
+		Address:             row.Address,
+		SubscriptionAddress: row.SubscriptionAddress,
+		OriginID:            row.OriginID,
+		Originator:          row.Origin,
+		Network:             row.Network,
+		CreatedAt:           createdAt,
+		Active:              true,
+		NotificationProvider: models.NotificationProvider{
+			Address:          row.Address,
+			TelegramProvider: models.TelegramProvider{Username: row.Telegram},
+			EmailProvider:    models.EmailProvider{Email: row.Email},
+		},
+	}
+}
+
+// newSecret returns a random 32 character hex string for OriginIDs and signing secrets
+func newSecret() (string, error) {
+	bytes := make([]byte, 16)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(bytes), nil
+}
@@ -0,0 +1,86 @@
+package nuntiare
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MaxLinkedOrigins is the maximum number of apps that can be linked to a wallet besides the registering one
+const MaxLinkedOrigins = 5
+
+// IsWalletOrigin reports whether the OriginID authenticates requests for the wallet: the OriginID the wallet
+// was registered with or the OriginID of a linked app
+func (n *Nuntiare) IsWalletOrigin(wallet *models.Wallet, originID string) bool {
+	if originID == "" {
+		return false
+	}
+	if subtle.ConstantTimeCompare([]byte(originID), []byte(wallet.OriginID)) == 1 {
+		return true
+	}
+	origin, err := n.repo.GetWalletOriginByID(wallet.Address, originID)
+	if err != nil {
+		n.logger.Error("Failed to get wallet origin", "error", err, "address", wallet.Address)
+		return false
+	}
+	return origin != nil
+}
+
+// LinkWalletOrigin links another wallet app to the wallet, so the user can manage the registration from both apps
+// without registering the address twice. Linking an app again with a new OriginID replaces its previous link.
+// The registering app can't be linked, it keeps using the wallet's OriginID.
+func (n *Nuntiare) LinkWalletOrigin(wallet *models.Wallet, origin, originID string) (*models.WalletOrigin, error) {
+	if originID == wallet.OriginID {
+		return nil, fmt.Errorf("%w: origin_id is the wallet's own origin_id", models.ErrInvalidOriginLink)
+	}
+	if origin == wallet.Originator {
+		return nil, fmt.Errorf("%w: %s registered the wallet and can't be linked", models.ErrInvalidOriginLink, origin)
+	}
+
+	linked, err := n.repo.GetWalletOrigins(wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	others := 0
+	for _, link := range linked {
+		if link.Origin != origin {
+			others++
+		}
+	}
+	if others >= MaxLinkedOrigins {
+		return nil, fmt.Errorf("%w: at most %d apps can be linked", models.ErrInvalidOriginLink, MaxLinkedOrigins)
+	}
+
+	link := &models.WalletOrigin{
+		WalletAddress: wallet.Address,
+		Origin:        origin,
+		OriginID:      originID,
+		LinkedAt:      time.Now().Unix(),
+	}
+	if err := n.repo.LinkWalletOrigin(link); err != nil {
+		return nil, err
+	}
+	n.logger.Info("Origin linked to wallet", "address", wallet.Address, "origin", origin)
+	return link, nil
+}
+
+// GetWalletOrigins returns the apps linked to the wallet, oldest first
+func (n *Nuntiare) GetWalletOrigins(address string) ([]*models.WalletOrigin, error) {
+	return n.repo.GetWalletOrigins(address)
+}
+
+// GetWalletOriginByID returns the linked app of the wallet with the OriginID, or nil if it isn't linked
+func (n *Nuntiare) GetWalletOriginByID(address, originID string) (*models.WalletOrigin, error) {
+	return n.repo.GetWalletOriginByID(address, originID)
+}
+
+// UnlinkWalletOrigin removes the link of an app from the wallet. Returns false if the app isn't linked.
+func (n *Nuntiare) UnlinkWalletOrigin(address, origin string) (bool, error) {
+	removed, err := n.repo.UnlinkWalletOrigin(address, origin)
+	if err == nil && removed {
+		n.logger.Info("Origin unlinked from wallet", "address", address, "origin", origin)
+	}
+	return removed, err
+}
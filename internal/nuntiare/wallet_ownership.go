@@ -0,0 +1,77 @@
+package nuntiare
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// ProveWalletOwnership checks that the ownership challenge message was signed with the key of the wallet and
+// flags the wallet as proven. Returns the time of the proof, or ErrInvalidSignature.
+func (n *Nuntiare) ProveWalletOwnership(wallet *models.Wallet, message, signature string) (int64, error) {
+	if err := blockchain.VerifySignedMessage(wallet.Address, message, signature); err != nil {
+		return 0, err
+	}
+	provenAt := n.now().Unix()
+	if err := n.repo.SetWalletOwnershipProven(wallet.Address, provenAt); err != nil {
+		return 0, err
+	}
+	n.logger.Info("Wallet ownership proven", "address", wallet.Address)
+	return provenAt, nil
+}
+
+// ExportWalletData returns the data stored about the wallet, with its latest MaxWalletExportNotifications
+// notifications. Returns ErrOwnershipNotProven unless the ownership of the wallet was proven.
+func (n *Nuntiare) ExportWalletData(wallet *models.Wallet) (*models.WalletExport, error) {
+	if !wallet.OwnershipProven {
+		return nil, models.ErrOwnershipNotProven
+	}
+
+	export := &models.WalletExport{Wallet: wallet, ExportedAt: n.now().Unix()}
+	provider, err := n.repo.GetWalletsNotificationProvider(wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	export.Wallet.NotificationProvider = *provider
+	if export.Origins, err = n.repo.GetWalletOrigins(wallet.Address); err != nil {
+		return nil, err
+	}
+	if export.TokenPreferences, err = n.repo.GetWalletTokenPreferences(wallet.Address); err != nil {
+		return nil, err
+	}
+	if export.Devices, err = n.repo.GetDevices(wallet.Address); err != nil {
+		return nil, err
+	}
+	if export.Payments, err = n.repo.GetSubscriptionPayments(wallet.Address); err != nil {
+		return nil, err
+	}
+	if export.SubscriptionTransfers, err = n.repo.GetSubscriptionTransfers(wallet.Address); err != nil {
+		return nil, err
+	}
+	if export.Events, err = n.repo.GetWalletEvents(wallet.Address, export.ExportedAt); err != nil {
+		return nil, err
+	}
+
+	opts := models.ListOptions{
+		Limit:   models.MaxListLimit,
+		Sort:    "created_at",
+		Desc:    true,
+		Filters: map[string]any{"wallet": wallet.Address},
+	}
+	for len(export.Notifications) < models.MaxWalletExportNotifications {
+		page, err := n.repo.ListNotifications(opts)
+		if err != nil {
+			return nil, err
+		}
+		export.Notifications = append(export.Notifications, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+		if opts.Cursor, err = models.DecodeCursor(page.NextCursor); err != nil {
+			return nil, fmt.Errorf("failed to page notifications: %w", err)
+		}
+	}
+	export.Notifications = export.Notifications[:min(len(export.Notifications), models.MaxWalletExportNotifications)]
+	return export, nil
+}
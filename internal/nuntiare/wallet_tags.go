@@ -0,0 +1,79 @@
+package nuntiare
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MaxWalletTags is the maximum number of tags of a wallet
+const MaxWalletTags = 20
+
+// walletTagPattern matches tag names: lowercase letters, digits, underscores and dashes, starting with a letter or digit
+var walletTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// AddWalletTag attaches a tag to a registered wallet. Tags are case-insensitive and stored in lowercase.
+func (n *Nuntiare) AddWalletTag(address, tag string) (*models.WalletTag, error) {
+	tag, err := normalizeWalletTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := n.repo.GetWallet(address); err != nil {
+		return nil, err
+	}
+
+	tags, err := n.repo.GetWalletTags(address)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range tags {
+		if existing.Tag == tag {
+			return existing, nil
+		}
+	}
+	if len(tags) >= MaxWalletTags {
+		return nil, fmt.Errorf("%w: a wallet can have at most %d tags", models.ErrInvalidWalletTag, MaxWalletTags)
+	}
+
+	walletTag := &models.WalletTag{
+		WalletAddress: address,
+		Tag:           tag,
+		CreatedAt:     time.Now().Unix(),
+	}
+	if err := n.repo.AddWalletTag(walletTag); err != nil {
+		return nil, err
+	}
+	n.logger.Info("Wallet tagged", "address", address, "tag", tag)
+	return walletTag, nil
+}
+
+// GetWalletTags returns the tags of the wallet in alphabetical order
+func (n *Nuntiare) GetWalletTags(address string) ([]*models.WalletTag, error) {
+	return n.repo.GetWalletTags(address)
+}
+
+// RemoveWalletTag detaches a tag from the wallet. Returns false if the wallet doesn't have it.
+func (n *Nuntiare) RemoveWalletTag(address, tag string) (bool, error) {
+	tag, err := normalizeWalletTag(tag)
+	if err != nil {
+		return false, err
+	}
+	return n.repo.RemoveWalletTag(address, tag)
+}
+
+// GetWalletTagCounts returns the number of wallets per tag
+func (n *Nuntiare) GetWalletTagCounts() ([]*models.WalletTagCount, error) {
+	return n.repo.GetWalletTagCounts()
+}
+
+// normalizeWalletTag returns the lowercase tag name, or ErrInvalidWalletTag if it isn't a valid tag
+func normalizeWalletTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !walletTagPattern.MatchString(tag) {
+		return "", fmt.Errorf("%w: tags are 1-32 characters of a-z, 0-9, _ and -", models.ErrInvalidWalletTag)
+	}
+	return tag, nil
+}
@@ -0,0 +1,78 @@
+package nuntiare
+
+import (
+	"crypto/ecdh"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MaxWebPushSubscriptionsPerWallet limits the number of browsers a wallet can receive notifications in
+const MaxWebPushSubscriptionsPerWallet = 10
+
+// WebPushPublicKey returns the VAPID public key browsers subscribe with (empty when Web Push is disabled)
+func (n *Nuntiare) WebPushPublicKey() string {
+	return n.config.VAPIDPublicKey
+}
+
+// AddWebPushSubscription validates and stores a browser push subscription of a wallet, or refreshes
+// the keys of a known endpoint
+func (n *Nuntiare) AddWebPushSubscription(subscription *models.WebPushSubscription) error {
+	if err := n.validateWebPushSubscription(subscription); err != nil {
+		return err
+	}
+
+	existing, err := n.repo.GetWebPushSubscriptions(subscription.WalletAddress)
+	if err != nil {
+		return err
+	}
+	known := false
+	for _, s := range existing {
+		known = known || s.Endpoint == subscription.Endpoint
+	}
+	if !known && len(existing) >= MaxWebPushSubscriptionsPerWallet {
+		return fmt.Errorf("%w: at most %d subscriptions per wallet", models.ErrInvalidWebPushSubscription, MaxWebPushSubscriptionsPerWallet)
+	}
+
+	subscription.CreatedAt = time.Now().Unix()
+	return n.repo.UpsertWebPushSubscription(subscription)
+}
+
+// GetWebPushSubscriptions returns the browser push subscriptions of a wallet, newest first
+func (n *Nuntiare) GetWebPushSubscriptions(address string) ([]*models.WebPushSubscription, error) {
+	return n.repo.GetWebPushSubscriptions(address)
+}
+
+// RemoveWebPushSubscription deletes a browser push subscription, e.g. when the user turns notifications off.
+// Returns false if it doesn't exist.
+func (n *Nuntiare) RemoveWebPushSubscription(address, endpoint string) (bool, error) {
+	return n.repo.RemoveWebPushSubscription(address, endpoint)
+}
+
+// validateWebPushSubscription returns ErrInvalidWebPushSubscription unless the endpoint is an HTTPS URL
+// and the keys are the P-256 public key and 16 byte auth secret of the browser.
+// Subscriptions are refused when no VAPID keys are configured.
+func (n *Nuntiare) validateWebPushSubscription(subscription *models.WebPushSubscription) error {
+	if n.config.VAPIDPublicKey == "" {
+		return fmt.Errorf("%w: web push notifications are not supported", models.ErrInvalidWebPushSubscription)
+	}
+	if parsed, err := url.Parse(subscription.Endpoint); err != nil || parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an absolute HTTPS URL", models.ErrInvalidWebPushSubscription)
+	}
+	p256dh, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(subscription.P256dh, "="))
+	if err != nil {
+		return fmt.Errorf("%w: p256dh must be base64url encoded", models.ErrInvalidWebPushSubscription)
+	}
+	if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+		return fmt.Errorf("%w: p256dh is not a P-256 public key", models.ErrInvalidWebPushSubscription)
+	}
+	auth, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(subscription.Auth, "="))
+	if err != nil || len(auth) != 16 {
+		return fmt.Errorf("%w: auth must be a base64url encoded 16 byte secret", models.ErrInvalidWebPushSubscription)
+	}
+	return nil
+}
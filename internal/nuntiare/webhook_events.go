@@ -0,0 +1,66 @@
+package nuntiare
+
+import (
+	"strings"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// GetWebhookEvent returns a webhook event of the wallet, or nil if the wallet has no event with the ID
+func (n *Nuntiare) GetWebhookEvent(address, id string) (*models.WebhookEvent, error) {
+	event, err := n.repo.GetWebhookEvent(id)
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if event.Wallet != validation.NormalizeAddress(address) {
+		return nil, nil
+	}
+	return event, nil
+}
+
+// ReplayWebhookEvent sends a webhook event of the wallet once more to the webhook the wallet is currently
+// notified through, or to the automation hook it was sent to, with the original event ID so receivers can
+// deduplicate it. Returns nil if the wallet has no event with the ID.
+func (n *Nuntiare) ReplayWebhookEvent(address, id string) (*models.WebhookReplay, error) {
+	event, err := n.GetWebhookEvent(address, id)
+	if err != nil || event == nil {
+		return nil, err
+	}
+	if event.HookID != "" {
+		return n.replayAutomationHookEvent(event)
+	}
+
+	provider, err := n.repo.GetUserNotificationProvider(event.Wallet)
+	if err != nil {
+		return nil, err
+	}
+	webhook := provider.WebhookProvider
+	if webhook.URL == "" {
+		return nil, models.ErrNoWebhook
+	}
+
+	replay := n.notificator.ReplayWebhookEvent(webhook.URL, webhook.Secret, event)
+	n.logger.Info("Webhook event replayed", "event", event.ID, "wallet", event.Wallet, "status", replay.StatusCode, "error", replay.Error)
+	return replay, nil
+}
+
+// replayAutomationHookEvent sends an event of an automation hook once more to the hook
+func (n *Nuntiare) replayAutomationHookEvent(event *models.WebhookEvent) (*models.WebhookReplay, error) {
+	hooks, err := n.repo.GetAutomationHooks(event.Wallet)
+	if err != nil {
+		return nil, err
+	}
+	for _, hook := range hooks {
+		if hook.ID != event.HookID {
+			continue
+		}
+		replay := n.notificator.ReplayWebhookEvent(hook.TargetURL, hook.Secret, event)
+		n.logger.Info("Automation hook event replayed", "event", event.ID, "hook", hook.ID, "wallet", event.Wallet, "status", replay.StatusCode, "error", replay.Error)
+		return replay, nil
+	}
+	return nil, models.ErrNoAutomationHook
+}
@@ -0,0 +1,47 @@
+package nuntiare
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MaxWebhookURLLength limits the length of a webhook URL
+const MaxWebhookURLLength = 2048
+
+// SetWebhook sets the webhook URL notifications of the wallet are POSTed to and returns the
+// secret the payloads are signed with. The secret of an existing webhook is kept.
+func (n *Nuntiare) SetWebhook(address, webhookURL string) (string, error) {
+	if err := n.ValidateWebhookURL(webhookURL); err != nil {
+		return "", err
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return "", err
+	}
+	provider, err := n.repo.UpsertWebhookProvider(address, webhookURL, secret)
+	if err != nil {
+		return "", err
+	}
+	return provider.Secret, nil
+}
+
+// ValidateWebhookURL returns ErrInvalidWebhookURL unless the URL is an absolute HTTPS URL (HTTP is allowed in development)
+func (n *Nuntiare) ValidateWebhookURL(webhookURL string) error {
+	if len(webhookURL) > MaxWebhookURLLength {
+		return fmt.Errorf("%w: must have at most %d characters", models.ErrInvalidWebhookURL, MaxWebhookURLLength)
+	}
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("%w: must be an absolute URL", models.ErrInvalidWebhookURL)
+	}
+	if parsed.User != nil {
+		return fmt.Errorf("%w: must not contain credentials", models.ErrInvalidWebhookURL)
+	}
+	if parsed.Scheme != "https" && !(parsed.Scheme == "http" && n.config.Development) {
+		return fmt.Errorf("%w: must use https", models.ErrInvalidWebhookURL)
+	}
+	return nil
+}
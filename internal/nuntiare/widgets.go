@@ -0,0 +1,105 @@
+package nuntiare
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+const (
+	// DefaultWidgetFeedLimit is the number of transfers in a widget feed by default
+	DefaultWidgetFeedLimit = 10
+	// MaxWidgetFeedLimit caps the number of transfers in a widget feed
+	MaxWidgetFeedLimit = 50
+)
+
+// SetWidgetFeed enables the public widget feed of the wallet or changes its fields (DefaultWidgetFields
+// when empty). rotate replaces the signing key, revoking the URLs handed out before.
+func (n *Nuntiare) SetWidgetFeed(address string, fields []string, rotate bool) (*models.WidgetFeed, error) {
+	if len(fields) == 0 {
+		fields = models.DefaultWidgetFields
+	}
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(models.WidgetFields, field) {
+			return nil, fmt.Errorf("%w: unknown field %q, must be one of %s", models.ErrInvalidWidgetFeed, field,
+				strings.Join(models.WidgetFields, ", "))
+		}
+		if !slices.Contains(normalized, field) {
+			normalized = append(normalized, field)
+		}
+	}
+
+	now := time.Now().Unix()
+	feed, err := n.repo.GetWidgetFeed(address)
+	if err != nil {
+		if !strings.Contains(err.Error(), "record not found") {
+			return nil, err
+		}
+		feed = &models.WidgetFeed{WalletAddress: address, CreatedAt: now}
+		rotate = true
+	}
+	if rotate {
+		key, err := newSecret()
+		if err != nil {
+			return nil, err
+		}
+		feed.Key = key
+	}
+	feed.Fields = normalized
+	feed.UpdatedAt = now
+	if err := n.repo.SetWidgetFeed(feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// GetWidgetFeed returns the widget feed of the wallet
+func (n *Nuntiare) GetWidgetFeed(address string) (*models.WidgetFeed, error) {
+	return n.repo.GetWidgetFeed(address)
+}
+
+// RemoveWidgetFeed disables the widget feed of the wallet. Returns false if it wasn't enabled.
+func (n *Nuntiare) RemoveWidgetFeed(address string) (bool, error) {
+	return n.repo.RemoveWidgetFeed(address)
+}
+
+// WidgetFeedURL returns the signed URL of the widget feed, relative without PUBLIC_URL
+func (n *Nuntiare) WidgetFeedURL(feed *models.WidgetFeed) string {
+	return fmt.Sprintf("%s/widget/%s?sig=%s", n.config.PublicURL, feed.WalletAddress, url.QueryEscape(feed.Signature()))
+}
+
+// GetWidgetItems returns the latest transfers of a wallet's widget feed, newest first, redacted to the
+// published fields. Returns ErrUnauthorized when the wallet has no widget feed or the signature is invalid.
+func (n *Nuntiare) GetWidgetItems(address, signature string, limit int) ([]*models.WidgetItem, error) {
+	feed, err := n.repo.GetWidgetFeed(validation.NormalizeAddress(address))
+	if err != nil {
+		if strings.Contains(err.Error(), "record not found") {
+			return nil, models.ErrUnauthorized
+		}
+		return nil, err
+	}
+	if !feed.VerifySignature(signature) {
+		return nil, models.ErrUnauthorized
+	}
+	if limit <= 0 {
+		limit = DefaultWidgetFeedLimit
+	}
+
+	notifications, err := n.repo.GetWidgetNotifications(feed.WalletAddress, min(limit, MaxWidgetFeedLimit))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]*models.WidgetItem, 0, len(notifications))
+	for _, notification := range notifications {
+		items = append(items, models.NewWidgetItem(notification, feed))
+	}
+	return items, nil
+}
+
@@ -0,0 +1,141 @@
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/core-coin/nuntiare/internal/blockchain"
+	"github.com/core-coin/nuntiare/internal/config"
+	"github.com/core-coin/nuntiare/internal/notificator"
+	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/go-telegram/bot"
+)
+
+// CheckTimeout bounds a single check
+const CheckTimeout = 15 * time.Second
+
+// Integrations verified by the checks
+const (
+	Telegram = "telegram"
+	SMTP     = "smtp"
+	RPC      = "rpc"
+	Contract = "contract"
+)
+
+// ErrUnreachable marks checks that failed because the integration couldn't be reached, which may be temporary.
+// Other failures are misconfigurations.
+var ErrUnreachable = errors.New("unreachable")
+
+// Result is the outcome of checking one integration
+type Result struct {
+	Name    string
+	Detail  string // What was verified, or why the check was skipped
+	Skipped bool   // The integration is not configured
+	Err     error
+}
+
+// Run verifies that the external integrations accept the configuration: the Telegram bot token, the SMTP login,
+// the network of the RPC endpoint and the CTN contract. Misconfigurations are found at startup instead of
+// when the first notification silently fails.
+func Run(cfg *config.Config, log *logger.Logger) []Result {
+	results := []Result{checkTelegram(cfg), checkSMTP(cfg, log)}
+	return append(results, checkBlockchain(cfg, log)...)
+}
+
+// checkTelegram calls getMe with the bot token
+func checkTelegram(cfg *config.Config) Result {
+	result := Result{Name: Telegram}
+	if cfg.TelegramBotToken == "" {
+		result.Skipped, result.Detail = true, "TELEGRAM_BOT_TOKEN is not set"
+		return result
+	}
+
+	b, err := bot.New(cfg.TelegramBotToken, bot.WithSkipGetMe())
+	if err != nil {
+		result.Err = fmt.Errorf("invalid TELEGRAM_BOT_TOKEN: %w", err)
+		return result
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), CheckTimeout)
+	defer cancel()
+	me, err := b.GetMe(ctx)
+	switch {
+	case errors.Is(err, bot.ErrorUnauthorized), errors.Is(err, bot.ErrorNotFound):
+		result.Err = fmt.Errorf("TELEGRAM_BOT_TOKEN was rejected by Telegram (%v), copy the token from @BotFather again", err)
+	case err != nil:
+		result.Err = fmt.Errorf("%w: failed to call Telegram getMe: %v", ErrUnreachable, err)
+	default:
+		result.Detail = "bot @" + me.Username
+	}
+	return result
+}
+
+// checkSMTP logs in to the SMTP server
+func checkSMTP(cfg *config.Config, log *logger.Logger) Result {
+	result := Result{Name: SMTP}
+	if cfg.EmailProvider != "smtp" {
+		result.Skipped, result.Detail = true, "emails are sent through "+cfg.EmailProvider
+		return result
+	}
+	if cfg.SMTPSender == "" {
+		result.Skipped, result.Detail = true, "SMTP_SENDER is not set"
+		return result
+	}
+
+	addr := net.JoinHostPort(cfg.SMTPHost, fmt.Sprint(cfg.SMTPPort))
+	email := notificator.NewEmailNotificator(log, cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPAlternativePort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPSender, nil)
+	err := email.CheckSMTP()
+	var netErr net.Error
+	switch {
+	case errors.As(err, &netErr):
+		result.Err = fmt.Errorf("%w: failed to connect to %s, check SMTP_HOST and SMTP_PORT: %v", ErrUnreachable, addr, err)
+	case err != nil:
+		result.Err = fmt.Errorf("SMTP login as %q at %s failed, check SMTP_USER and SMTP_PASSWORD: %w", cfg.SMTPUser, addr, err)
+	default:
+		result.Detail = "logged in as " + cfg.SMTPUser + " at " + addr
+	}
+	return result
+}
+
+// checkBlockchain checks that the RPC endpoint serves NETWORK_ID and that SMART_CONTRACT_ADDRESS is a contract on it
+func checkBlockchain(cfg *config.Config, log *logger.Logger) []Result {
+	rpc := Result{Name: RPC}
+	contract := Result{Name: Contract}
+
+	gocore := blockchain.NewGocore(cfg.BlockchainServiceURL, log, cfg)
+	if err := gocore.ConnectToRPC(); err != nil {
+		rpc.Err = fmt.Errorf("%w: %v, check BLOCKCHAIN_SERVICE_URL", ErrUnreachable, err)
+		contract.Skipped, contract.Detail = true, "RPC endpoint unavailable"
+		return []Result{rpc, contract}
+	}
+	defer gocore.Close()
+
+	networkID, err := gocore.NetworkID()
+	switch {
+	case err != nil:
+		rpc.Err = fmt.Errorf("%w: %v", ErrUnreachable, err)
+	case networkID.Cmp(cfg.NetworkID) != 0:
+		rpc.Err = fmt.Errorf("BLOCKCHAIN_SERVICE_URL serves network %s but NETWORK_ID is %s, point it to a %s node or fix NETWORK_ID",
+			networkID, cfg.NetworkID, cfg.GetNetworkName())
+	default:
+		rpc.Detail = "network " + networkID.String()
+	}
+	if rpc.Err != nil {
+		contract.Skipped, contract.Detail = true, "RPC endpoint is not usable"
+		return []Result{rpc, contract}
+	}
+
+	hasCode, err := gocore.HasCode(cfg.SmartContractAddress)
+	switch {
+	case err != nil:
+		contract.Err = fmt.Errorf("%w: %v", ErrUnreachable, err)
+	case !hasCode:
+		contract.Err = fmt.Errorf("SMART_CONTRACT_ADDRESS %s has no contract code on network %s, check the address for this network",
+			cfg.SmartContractAddress, networkID)
+	default:
+		contract.Detail = "CTN contract " + cfg.SmartContractAddress
+	}
+	return []Result{rpc, contract}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddAutomationHook stores a REST hook subscription of an automation platform
+func (db *PostgresDB) AddAutomationHook(hook *models.AutomationHook) error {
+	hook.WalletAddress = validation.NormalizeAddress(hook.WalletAddress)
+	if err := db.Conn.Create(hook).Error; err != nil {
+		return fmt.Errorf("failed to add automation hook: %w", err)
+	}
+	return nil
+}
+
+// GetAutomationHooks returns the automation hooks of a wallet, oldest first
+func (db *PostgresDB) GetAutomationHooks(walletAddress string) ([]*models.AutomationHook, error) {
+	var hooks []*models.AutomationHook
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(walletAddress)).
+		Order("created_at ASC").
+		Find(&hooks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get automation hooks: %w", err)
+	}
+	return hooks, nil
+}
+
+// RemoveAutomationHook removes an automation hook of a wallet. Returns false if it doesn't exist.
+func (db *PostgresDB) RemoveAutomationHook(walletAddress, id string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ? AND id = ?", validation.NormalizeAddress(walletAddress), id).
+		Delete(&models.AutomationHook{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove automation hook: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
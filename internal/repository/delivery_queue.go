@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddDeliveryJob queues a failed delivery for its retries
+func (db *PostgresDB) AddDeliveryJob(job *models.DeliveryJob) error {
+	job.Wallet = validation.NormalizeAddress(job.Wallet)
+	if err := db.Conn.Create(job).Error; err != nil {
+		return fmt.Errorf("failed to add delivery job: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) UpdateDeliveryJob(job *models.DeliveryJob) error {
+	if err := db.Conn.Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update delivery job: %w", err)
+	}
+	return nil
+}
+
+// RemoveDeliveryJob removes a delivered job from the queue
+func (db *PostgresDB) RemoveDeliveryJob(id string) error {
+	if err := db.Conn.Where("id = ?", id).Delete(&models.DeliveryJob{}).Error; err != nil {
+		return fmt.Errorf("failed to remove delivery job: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueDeliveryJobs returns up to limit delivery jobs due for a retry at the timestamp, most overdue first,
+// and postpones their next attempt to claimedUntil, so no other run retries them while they are being sent.
+// A job whose retry isn't recorded before claimedUntil (e.g. the instance crashed) becomes due again.
+func (db *PostgresDB) ClaimDueDeliveryJobs(now, claimedUntil int64, limit int) ([]*models.DeliveryJob, error) {
+	var jobs []*models.DeliveryJob
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_attempt_at <= ?", now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&jobs).Error; err != nil {
+			return fmt.Errorf("failed to get due delivery jobs: %w", err)
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+		ids := make([]string, len(jobs))
+		for i, job := range jobs {
+			ids[i] = job.ID
+		}
+		if err := tx.Model(&models.DeliveryJob{}).Where("id IN ?", ids).
+			Update("next_attempt_at", claimedUntil).Error; err != nil {
+			return fmt.Errorf("failed to claim due delivery jobs: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		job.NextAttemptAt = claimedUntil
+	}
+	return jobs, nil
+}
+
+// ListDeliveryJobs returns a page of the delivery queue
+func (db *PostgresDB) ListDeliveryJobs(opts models.ListOptions) (*models.Page[models.DeliveryJob], error) {
+	page, err := paginate(db.Conn.Model(&models.DeliveryJob{}), opts, models.DeliveryJobListFields,
+		func(job *models.DeliveryJob, sort string) (string, string) {
+			if sort == "created_at" {
+				return formatInt(job.CreatedAt), job.ID
+			}
+			return formatInt(job.NextAttemptAt), job.ID
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list delivery jobs: %w", err)
+	}
+	return page, nil
+}
+
+// AddDeadLetter stores a delivery that won't be retried
+func (db *PostgresDB) AddDeadLetter(letter *models.DeadLetter) error {
+	letter.Wallet = validation.NormalizeAddress(letter.Wallet)
+	if err := db.Conn.Create(letter).Error; err != nil {
+		return fmt.Errorf("failed to add dead letter: %w", err)
+	}
+	return nil
+}
+
+// MoveToDeadLetters removes the job from the delivery queue and stores it as dead letter
+func (db *PostgresDB) MoveToDeadLetters(job *models.DeliveryJob, failedAt int64) (*models.DeadLetter, error) {
+	letter := models.NewDeadLetter(job, failedAt)
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", job.ID).Delete(&models.DeliveryJob{}).Error; err != nil {
+			return fmt.Errorf("failed to remove delivery job: %w", err)
+		}
+		if err := tx.Create(letter).Error; err != nil {
+			return fmt.Errorf("failed to add dead letter: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return letter, nil
+}
+
+func (db *PostgresDB) UpdateDeadLetter(letter *models.DeadLetter) error {
+	if err := db.Conn.Save(letter).Error; err != nil {
+		return fmt.Errorf("failed to update dead letter: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetDeadLetter(id string) (*models.DeadLetter, error) {
+	var letter models.DeadLetter
+	if err := db.Conn.Where("id = ?", id).First(&letter).Error; err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return &letter, nil
+}
+
+// ListDeadLetters returns a page of the dead-letter queue
+func (db *PostgresDB) ListDeadLetters(opts models.ListOptions) (*models.Page[models.DeadLetter], error) {
+	page, err := paginate(db.Conn.Model(&models.DeadLetter{}), opts, models.DeadLetterListFields,
+		func(letter *models.DeadLetter, sort string) (string, string) {
+			if sort == "created_at" {
+				return formatInt(letter.CreatedAt), letter.ID
+			}
+			return formatInt(letter.FailedAt), letter.ID
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	return page, nil
+}
+
+// RemoveDeadLetter discards a dead letter. Returns false if it doesn't exist.
+func (db *PostgresDB) RemoveDeadLetter(id string) (bool, error) {
+	result := db.Conn.Where("id = ?", id).Delete(&models.DeadLetter{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove dead letter: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertDevice registers a device or refreshes the stored details of an existing one
+func (db *PostgresDB) UpsertDevice(device *models.Device) error {
+	device.WalletAddress = validation.NormalizeAddress(device.WalletAddress)
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_address"}, {Name: "device_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"os", "push_token", "app_version", "notify", "last_seen_at"}),
+	}).Create(device).Error; err != nil {
+		return fmt.Errorf("failed to upsert device: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetDevices(walletAddress string) ([]*models.Device, error) {
+	var devices []*models.Device
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(walletAddress)).
+		Order("last_seen_at DESC").
+		Find(&devices).Error; err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+// RemoveDevice deletes a device of a wallet. Returns false if the device doesn't exist.
+func (db *PostgresDB) RemoveDevice(walletAddress, deviceID string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ? AND device_id = ?", validation.NormalizeAddress(walletAddress), deviceID).
+		Delete(&models.Device{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove device: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RemoveStaleDevices deletes devices not seen since the given Unix timestamp and returns how many were removed
+func (db *PostgresDB) RemoveStaleDevices(lastSeenBefore int64) (int64, error) {
+	result := db.Conn.Where("last_seen_at < ?", lastSeenBefore).Delete(&models.Device{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to remove stale devices: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertDiscordProvider sets the Discord webhook URL and channel of a wallet and re-enables the provider.
+// The channel is unverified until the verification code is found in it.
+func (db *PostgresDB) UpsertDiscordProvider(address, webhookURL, channelID, verificationCode string) error {
+	address = validation.NormalizeAddress(address)
+	var notificationProvider models.NotificationProvider
+	if err := db.Conn.Preload("DiscordProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+		return fmt.Errorf("failed to get notification provider: %w", err)
+	}
+
+	provider := notificationProvider.DiscordProvider
+	if provider.ID == 0 {
+		provider = models.DiscordProvider{NotificationProviderID: notificationProvider.ID, WebhookURL: webhookURL, ChannelID: channelID, VerificationCode: verificationCode}
+		if err := db.Conn.Create(&provider).Error; err != nil {
+			return fmt.Errorf("failed to create discord provider: %w", err)
+		}
+	} else if err := db.Conn.Model(&provider).Updates(map[string]interface{}{
+		"webhook_url":       webhookURL,
+		"channel_id":        channelID,
+		"verification_code": verificationCode,
+		"channel_verified":  false,
+		"disabled":          false,
+		"disabled_reason":   "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update discord provider: %w", err)
+	}
+
+	db.logger.Debug("Updated discord provider", "address", address)
+	return nil
+}
+
+// DisableDiscordProvider disables a Discord provider whose webhook or channel is no longer usable
+func (db *PostgresDB) DisableDiscordProvider(id int64, reason string) error {
+	if err := db.Conn.Model(&models.DiscordProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable discord provider: %w", err)
+	}
+
+	db.logger.Debug("Disabled discord provider", "id", id, "reason", reason)
+	return nil
+}
+
+// VerifyDiscordProvider marks the channel of a Discord provider as verified
+func (db *PostgresDB) VerifyDiscordProvider(id int64) error {
+	if err := db.Conn.Model(&models.DiscordProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"channel_verified":  true,
+		"verification_code": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to verify discord provider: %w", err)
+	}
+
+	db.logger.Debug("Verified discord provider", "id", id)
+	return nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+func (db *PostgresDB) AddEmailEvents(events []*models.EmailEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	if err := db.Conn.Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to add email events: %w", err)
+	}
+	return nil
+}
+
+// SetEmailBounced marks every email provider with the given address as bounced (or clears the flag)
+func (db *PostgresDB) SetEmailBounced(email string, bounced bool) error {
+	if err := db.Conn.Model(&models.EmailProvider{}).Where("lower(email) = lower(?)", email).Update("bounced", bounced).Error; err != nil {
+		return fmt.Errorf("failed to set email bounced status: %w", err)
+	}
+
+	db.logger.Debug("Updated email bounced status", "email", email, "bounced", bounced)
+	return nil
+}
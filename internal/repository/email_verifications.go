@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddEmailVerification stores a verification link of the wallet's email, replacing the previous ones
+func (db *PostgresDB) AddEmailVerification(verification *models.EmailVerification) error {
+	verification.WalletAddress = validation.NormalizeAddress(verification.WalletAddress)
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wallet_address = ?", verification.WalletAddress).Delete(&models.EmailVerification{}).Error; err != nil {
+			return fmt.Errorf("failed to remove previous email verifications: %w", err)
+		}
+		if err := tx.Create(verification).Error; err != nil {
+			return fmt.Errorf("failed to add email verification: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetLatestEmailVerification returns the latest pending verification sent to the email by any wallet, or nil
+// if there is none. Emails are compared case-insensitively.
+func (db *PostgresDB) GetLatestEmailVerification(email string) (*models.EmailVerification, error) {
+	var verifications []*models.EmailVerification
+	if err := db.Conn.Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&verifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get email verification: %w", err)
+	}
+	if len(verifications) == 0 {
+		return nil, nil
+	}
+	return verifications[0], nil
+}
+
+// VerifyEmail marks the email of the verification with the token hash as verified and removes the verification.
+// Returns ErrInvalidVerificationToken if the token is unknown or expired, or the wallet's email changed since.
+func (db *PostgresDB) VerifyEmail(tokenHash string, now int64) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		var verifications []*models.EmailVerification
+		if err := tx.Where("token_hash = ? AND expires_at > ?", tokenHash, now).Limit(1).Find(&verifications).Error; err != nil {
+			return fmt.Errorf("failed to get email verification: %w", err)
+		}
+		if len(verifications) == 0 {
+			return models.ErrInvalidVerificationToken
+		}
+		verification = *verifications[0]
+
+		result := tx.Model(&models.EmailProvider{}).
+			Where("email = ? AND notification_provider_id IN (?)", verification.Email,
+				tx.Model(&models.NotificationProvider{}).Select("id").Where("address = ?", verification.WalletAddress)).
+			Update("verified", true)
+		if result.Error != nil {
+			return fmt.Errorf("failed to verify email: %w", result.Error)
+		}
+		if err := tx.Delete(&models.EmailVerification{}, verification.ID).Error; err != nil {
+			return fmt.Errorf("failed to remove email verification: %w", err)
+		}
+		if result.RowsAffected == 0 {
+			return models.ErrInvalidVerificationToken
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// exchangeReconciliationLimit caps the deposits listed in each section of a reconciliation report
+const exchangeReconciliationLimit = 100
+
+func (db *PostgresDB) AddExchange(exchange *models.Exchange) error {
+	if err := db.Conn.Create(exchange).Error; err != nil {
+		return fmt.Errorf("failed to add exchange: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) ListExchanges() ([]*models.Exchange, error) {
+	var exchanges []*models.Exchange
+	if err := db.Conn.Order("created_at ASC").Find(&exchanges).Error; err != nil {
+		return nil, fmt.Errorf("failed to list exchanges: %w", err)
+	}
+	return exchanges, nil
+}
+
+func (db *PostgresDB) GetExchange(id string) (*models.Exchange, error) {
+	var exchange models.Exchange
+	if err := db.Conn.Where("id = ?", id).First(&exchange).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exchange: %w", err)
+	}
+	return &exchange, nil
+}
+
+func (db *PostgresDB) GetExchangeByAPIKeyHash(hash string) (*models.Exchange, error) {
+	var exchange models.Exchange
+	if err := db.Conn.Where("api_key_hash = ?", hash).First(&exchange).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exchange: %w", err)
+	}
+	return &exchange, nil
+}
+
+// RemoveExchange removes an exchange and its deposit addresses. Its deposits are kept for the reconciliation
+// of past periods. Returns false if it doesn't exist.
+func (db *PostgresDB) RemoveExchange(id string) (bool, error) {
+	var removed bool
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&models.Exchange{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove exchange: %w", result.Error)
+		}
+		removed = result.RowsAffected > 0
+		if err := tx.Where("exchange_id = ?", id).Delete(&models.ExchangeAddress{}).Error; err != nil {
+			return fmt.Errorf("failed to remove exchange addresses: %w", err)
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// GetExchangeAddresses returns the registered deposit addresses among the addresses
+func (db *PostgresDB) GetExchangeAddresses(addresses []string) ([]*models.ExchangeAddress, error) {
+	normalized := make([]string, len(addresses))
+	for i, address := range addresses {
+		normalized[i] = validation.NormalizeAddress(address)
+	}
+	var registered []*models.ExchangeAddress
+	if err := db.Conn.Where("address IN ?", normalized).Find(&registered).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exchange addresses: %w", err)
+	}
+	return registered, nil
+}
+
+// SaveExchangeAddresses registers deposit addresses, or updates their labels when the exchange already
+// registered them. Addresses registered by another exchange in the meantime are left untouched.
+func (db *PostgresDB) SaveExchangeAddresses(addresses []*models.ExchangeAddress) error {
+	for _, address := range addresses {
+		address.Address = validation.NormalizeAddress(address.Address)
+	}
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "exchange_addresses.exchange_id = excluded.exchange_id"}}},
+		DoUpdates: clause.AssignmentColumns([]string{"label"}),
+	}).CreateInBatches(addresses, 500).Error; err != nil {
+		return fmt.Errorf("failed to save exchange addresses: %w", err)
+	}
+	return nil
+}
+
+// RemoveExchangeAddress removes a deposit address of the exchange. Returns false if the exchange didn't
+// register it.
+func (db *PostgresDB) RemoveExchangeAddress(exchangeID, address string) (bool, error) {
+	result := db.Conn.Where("exchange_id = ? AND address = ?", exchangeID, validation.NormalizeAddress(address)).
+		Delete(&models.ExchangeAddress{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove exchange address: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AddExchangeDeposits stores detected deposits. Transfers already stored for the same block, e.g. when a
+// block is processed again, are skipped.
+func (db *PostgresDB) AddExchangeDeposits(deposits []*models.ExchangeDeposit) error {
+	for _, deposit := range deposits {
+		deposit.Address = validation.NormalizeAddress(deposit.Address)
+	}
+	if err := db.Conn.Clauses(clause.OnConflict{DoNothing: true}).Create(deposits).Error; err != nil {
+		return fmt.Errorf("failed to add exchange deposits: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) UpdateExchangeDeposit(deposit *models.ExchangeDeposit) error {
+	if err := db.Conn.Save(deposit).Error; err != nil {
+		return fmt.Errorf("failed to update exchange deposit: %w", err)
+	}
+	return nil
+}
+
+// GetPendingExchangeDeposits returns up to limit deposits of the current exchanges waiting for their
+// confirmations, oldest block first
+func (db *PostgresDB) GetPendingExchangeDeposits(limit int) ([]*models.ExchangeDeposit, error) {
+	var deposits []*models.ExchangeDeposit
+	if err := db.Conn.Where("status = ? AND exchange_id IN (SELECT id FROM exchanges)", models.ExchangeDepositPending).
+		Order("block_number ASC, id ASC").
+		Limit(limit).
+		Find(&deposits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get pending exchange deposits: %w", err)
+	}
+	return deposits, nil
+}
+
+// GetDeliverableExchangeDeposits returns up to limit confirmed deposits due for a webhook delivery at the
+// timestamp, in chain order. A deposit is only returned once every earlier deposit of its address is
+// delivered, so the webhook receives the deposits of an address in order.
+func (db *PostgresDB) GetDeliverableExchangeDeposits(now int64, limit int) ([]*models.ExchangeDeposit, error) {
+	var deposits []*models.ExchangeDeposit
+	if err := db.Conn.Where("status = ? AND next_attempt_at <= ? AND exchange_id IN (SELECT id FROM exchanges)",
+		models.ExchangeDepositConfirmed, now).
+		Where(`NOT EXISTS (SELECT 1 FROM exchange_deposits earlier WHERE earlier.address = exchange_deposits.address
+			AND earlier.status IN ? AND (earlier.block_number < exchange_deposits.block_number
+			OR (earlier.block_number = exchange_deposits.block_number AND earlier.id < exchange_deposits.id)))`,
+			[]string{models.ExchangeDepositPending, models.ExchangeDepositConfirmed}).
+		Order("block_number ASC, id ASC").
+		Limit(limit).
+		Find(&deposits).Error; err != nil {
+		return nil, fmt.Errorf("failed to get deliverable exchange deposits: %w", err)
+	}
+	return deposits, nil
+}
+
+// ListExchangeDeposits returns a page of the deposits of the exchange
+func (db *PostgresDB) ListExchangeDeposits(exchangeID string, opts models.ListOptions) (*models.Page[models.ExchangeDeposit], error) {
+	query := db.Conn.Model(&models.ExchangeDeposit{}).Where("exchange_id = ?", exchangeID)
+	page, err := paginate(query, opts, models.ExchangeDepositListFields,
+		func(deposit *models.ExchangeDeposit, sort string) (string, string) {
+			if sort == "block_number" {
+				return formatInt(int64(deposit.BlockNumber)), formatInt(deposit.ID)
+			}
+			return formatInt(deposit.DetectedAt), formatInt(deposit.ID)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exchange deposits: %w", err)
+	}
+	return page, nil
+}
+
+func (db *PostgresDB) GetExchangeDeposit(exchangeID string, id int64) (*models.ExchangeDeposit, error) {
+	var deposit models.ExchangeDeposit
+	if err := db.Conn.Where("exchange_id = ? AND id = ?", exchangeID, id).First(&deposit).Error; err != nil {
+		return nil, fmt.Errorf("failed to get exchange deposit: %w", err)
+	}
+	return &deposit, nil
+}
+
+// CreditExchangeDeposit marks a confirmed deposit of the exchange credited with the amount. Returns false if
+// the deposit isn't confirmed or was already credited.
+func (db *PostgresDB) CreditExchangeDeposit(exchangeID string, id int64, amount float64, now int64) (bool, error) {
+	result := db.Conn.Model(&models.ExchangeDeposit{}).
+		Where("exchange_id = ? AND id = ? AND status IN ?", exchangeID, id,
+			[]string{models.ExchangeDepositConfirmed, models.ExchangeDepositDelivered}).
+		Updates(map[string]any{
+			"status":          models.ExchangeDepositCredited,
+			"credited_amount": amount,
+			"credited_at":     now,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to credit exchange deposit: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetExchangeReconciliation returns the deposits of the exchange detected in from..to, summed per token,
+// along with the oldest confirmed deposits not credited yet and the deposits credited with another amount
+func (db *PostgresDB) GetExchangeReconciliation(exchangeID string, from, to int64) (*models.ExchangeReconciliation, error) {
+	report := &models.ExchangeReconciliation{From: from, To: to}
+	period := db.Conn.Model(&models.ExchangeDeposit{}).
+		Where("exchange_id = ? AND detected_at >= ? AND detected_at <= ?", exchangeID, from, to)
+
+	if err := period.Session(&gorm.Session{}).
+		Select(`token, MAX(currency) AS currency,
+			COUNT(*) FILTER (WHERE status NOT IN @dropped) AS detected,
+			COALESCE(SUM(amount) FILTER (WHERE status NOT IN @dropped), 0) AS detected_amount,
+			COUNT(*) FILTER (WHERE status IN @confirmed) AS confirmed,
+			COALESCE(SUM(amount) FILTER (WHERE status IN @confirmed), 0) AS confirmed_amount,
+			COUNT(*) FILTER (WHERE status = @credited) AS credited,
+			COALESCE(SUM(credited_amount) FILTER (WHERE status = @credited), 0) AS credited_amount,
+			COUNT(*) FILTER (WHERE status = @orphaned) AS orphaned,
+			COUNT(*) FILTER (WHERE status = @reverted) AS reverted`,
+			map[string]any{
+				"dropped":  []string{models.ExchangeDepositOrphaned, models.ExchangeDepositReverted},
+				"orphaned": models.ExchangeDepositOrphaned,
+				"reverted": models.ExchangeDepositReverted,
+				"credited": models.ExchangeDepositCredited,
+				"confirmed": []string{models.ExchangeDepositConfirmed, models.ExchangeDepositDelivered,
+					models.ExchangeDepositCredited},
+			}).
+		Group("token").
+		Order("token ASC").
+		Scan(&report.Tokens).Error; err != nil {
+		return nil, fmt.Errorf("failed to sum exchange deposits: %w", err)
+	}
+
+	if err := period.Session(&gorm.Session{}).
+		Where("status IN ?", []string{models.ExchangeDepositConfirmed, models.ExchangeDepositDelivered}).
+		Order("detected_at ASC, id ASC").
+		Limit(exchangeReconciliationLimit).
+		Find(&report.Uncredited).Error; err != nil {
+		return nil, fmt.Errorf("failed to get uncredited exchange deposits: %w", err)
+	}
+
+	if err := period.Session(&gorm.Session{}).
+		Where("status = ? AND ABS(credited_amount - amount) > 1e-9 * GREATEST(1, amount)", models.ExchangeDepositCredited).
+		Order("detected_at ASC, id ASC").
+		Limit(exchangeReconciliationLimit).
+		Find(&report.Mismatched).Error; err != nil {
+		return nil, fmt.Errorf("failed to get mismatched exchange deposits: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/pkg/faults"
+)
+
+// InjectFaults makes database operations fail and slow down according to the injector's DB faults (development only)
+func (db *PostgresDB) InjectFaults(injector *faults.Injector) error {
+	inject := func(tx *gorm.DB) {
+		if err := injector.Inject(faults.DB); err != nil {
+			_ = tx.AddError(err)
+		}
+	}
+
+	callbacks := db.Conn.Callback()
+	if err := errors.Join(
+		callbacks.Create().Before("gorm:create").Register("faults:create", inject),
+		callbacks.Query().Before("gorm:query").Register("faults:query", inject),
+		callbacks.Update().Before("gorm:update").Register("faults:update", inject),
+		callbacks.Delete().Before("gorm:delete").Register("faults:delete", inject),
+		callbacks.Row().Before("gorm:row").Register("faults:row", inject),
+		callbacks.Raw().Before("gorm:raw").Register("faults:raw", inject),
+	); err != nil {
+		return fmt.Errorf("failed to register fault callbacks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// maintenanceModeID is the ID of the single maintenance mode row
+const maintenanceModeID = 1
+
+// GetReadOnlyMode returns whether read-only maintenance mode is switched on, false if it was never switched
+func (db *PostgresDB) GetReadOnlyMode() (bool, error) {
+	var mode models.MaintenanceMode
+	if err := db.Conn.Where("id = ?", maintenanceModeID).First(&mode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get maintenance mode: %w", err)
+	}
+	return mode.ReadOnly, nil
+}
+
+// SetReadOnlyMode switches read-only maintenance mode of all instances
+func (db *PostgresDB) SetReadOnlyMode(readOnly bool, updatedAt int64) error {
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"read_only", "updated_at"}),
+	}).Create(&models.MaintenanceMode{ID: maintenanceModeID, ReadOnly: readOnly, UpdatedAt: updatedAt}).Error; err != nil {
+		return fmt.Errorf("failed to set maintenance mode: %w", err)
+	}
+	return nil
+}
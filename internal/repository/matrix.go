@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertMatrixProvider sets the Matrix room of a wallet and re-enables the provider
+func (db *PostgresDB) UpsertMatrixProvider(address, roomID string) error {
+	address = validation.NormalizeAddress(address)
+	var notificationProvider models.NotificationProvider
+	if err := db.Conn.Preload("MatrixProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+		return fmt.Errorf("failed to get notification provider: %w", err)
+	}
+
+	provider := notificationProvider.MatrixProvider
+	if provider.ID == 0 {
+		provider = models.MatrixProvider{NotificationProviderID: notificationProvider.ID, RoomID: roomID}
+		if err := db.Conn.Create(&provider).Error; err != nil {
+			return fmt.Errorf("failed to create matrix provider: %w", err)
+		}
+	} else {
+		updates := map[string]interface{}{
+			"room_id":         roomID,
+			"disabled":        false,
+			"disabled_reason": "",
+		}
+		// The invite was for the previous room
+		if provider.RoomID != roomID {
+			updates["invited_by"] = ""
+			updates["joined_at"] = 0
+		}
+		if err := db.Conn.Model(&provider).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to update matrix provider: %w", err)
+		}
+	}
+
+	db.logger.Debug("Updated matrix provider", "address", address)
+	return nil
+}
+
+// DisableMatrixProvider disables a Matrix provider whose room the bot can't post to
+func (db *PostgresDB) DisableMatrixProvider(id int64, reason string) error {
+	if err := db.Conn.Model(&models.MatrixProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable matrix provider: %w", err)
+	}
+
+	db.logger.Debug("Disabled matrix provider", "id", id, "reason", reason)
+	return nil
+}
+
+// SetMatrixProviderJoined records that the bot joined the provider's room on the invite of invitedBy
+func (db *PostgresDB) SetMatrixProviderJoined(id int64, invitedBy string, joinedAt int64) error {
+	if err := db.Conn.Model(&models.MatrixProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"invited_by": invitedBy,
+		"joined_at":  joinedAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update matrix provider: %w", err)
+	}
+
+	db.logger.Debug("Joined matrix room", "id", id, "invited_by", invitedBy)
+	return nil
+}
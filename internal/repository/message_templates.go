@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+func (db *PostgresDB) GetMessageTemplates() ([]*models.MessageTemplate, error) {
+	var messageTemplates []*models.MessageTemplate
+	if err := db.Conn.Order("lang, name").Find(&messageTemplates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get message templates: %w", err)
+	}
+
+	return messageTemplates, nil
+}
+
+// UpsertMessageTemplate stores a message template override or replaces the body of an existing one
+func (db *PostgresDB) UpsertMessageTemplate(messageTemplate *models.MessageTemplate) error {
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "lang"}, {Name: "name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"body", "updated_at"}),
+	}).Create(messageTemplate).Error; err != nil {
+		return fmt.Errorf("failed to upsert message template: %w", err)
+	}
+	return nil
+}
+
+// DeleteMessageTemplate removes a message template override. Returns false if the override doesn't exist.
+func (db *PostgresDB) DeleteMessageTemplate(lang, name string) (bool, error) {
+	result := db.Conn.Where("lang = ? AND name = ?", lang, name).Delete(&models.MessageTemplate{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete message template: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
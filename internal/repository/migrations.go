@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/logger"
+)
+
+// normalizedAddressSQL is the SQL equivalent of validation.NormalizeAddress
+const normalizedAddressSQL = "lower(regexp_replace(%[1]s, '^0[xX]', ''))"
+
+// addressColumns lists every column that stores a blockchain address used for lookups
+var addressColumns = []struct {
+	table  string
+	column string
+}{
+	{"wallets", "address"},
+	{"wallets", "subscription_address"},
+	{"notification_providers", "address"},
+	{"subscription_payments", "address"},
+	{"notifications", "wallet"},
+}
+
+// normalizeStoredAddresses rewrites addresses stored before normalization was enforced
+// to their canonical form (lowercase, no 0x prefix). It is a no-op once all rows are normalized.
+func normalizeStoredAddresses(conn *gorm.DB, logger *logger.Logger) error {
+	pending := false
+	for _, c := range addressColumns {
+		var count int64
+		if err := conn.Table(c.table).Where(fmt.Sprintf("%[1]s <> "+normalizedAddressSQL, c.column)).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to count unnormalized addresses in %s.%s: %w", c.table, c.column, err)
+		}
+		if count > 0 {
+			logger.Info("Normalizing stored addresses", "table", c.table, "column", c.column, "rows", count)
+			pending = true
+		}
+	}
+	if !pending {
+		return nil
+	}
+
+	return conn.Transaction(func(tx *gorm.DB) error {
+		// notification_providers.address references wallets.address without ON UPDATE CASCADE,
+		// so the constraint is dropped while both sides are rewritten
+		migrator := tx.Migrator()
+		if migrator.HasConstraint(&models.Wallet{}, "NotificationProvider") {
+			if err := migrator.DropConstraint(&models.Wallet{}, "NotificationProvider"); err != nil {
+				return fmt.Errorf("failed to drop notification provider constraint: %w", err)
+			}
+		}
+
+		for _, c := range addressColumns {
+			expr := fmt.Sprintf(normalizedAddressSQL, c.column)
+			if err := tx.Exec(fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s <> %s", c.table, c.column, expr, c.column, expr)).Error; err != nil {
+				return fmt.Errorf("failed to normalize addresses in %s.%s (duplicate addresses differing only in case must be merged manually): %w", c.table, c.column, err)
+			}
+		}
+
+		if err := migrator.CreateConstraint(&models.Wallet{}, "NotificationProvider"); err != nil {
+			return fmt.Errorf("failed to recreate notification provider constraint: %w", err)
+		}
+		return nil
+	})
+}
+
+// createAddressLookupIndexes creates functional indexes backing the case-insensitive wallet lookups
+func createAddressLookupIndexes(conn *gorm.DB) error {
+	indexes := []string{
+		"CREATE INDEX IF NOT EXISTS idx_wallets_address_lower ON wallets (lower(address))",
+		"CREATE INDEX IF NOT EXISTS idx_wallets_subscription_address_lower ON wallets (lower(subscription_address))",
+	}
+	for _, index := range indexes {
+		if err := conn.Exec(index).Error; err != nil {
+			return fmt.Errorf("failed to create index: %w", err)
+		}
+	}
+	return nil
+}
+
+// backfillNotificationEventTypes sets the event type of notifications stored before event types were
+// introduced. Only transfer notifications are stored, so the type follows from the token type.
+func backfillNotificationEventTypes(conn *gorm.DB) error {
+	if err := conn.Exec(`UPDATE notifications SET event_type = CASE token_type WHEN 'CBC20' THEN ? WHEN 'CBC721' THEN ? ELSE ? END
+		WHERE event_type IS NULL OR event_type = ''`,
+		models.EventIncomingCBC20, models.EventNFTReceived, models.EventIncomingXCB).Error; err != nil {
+		return fmt.Errorf("failed to backfill notification event types: %w", err)
+	}
+	return nil
+}
+
+// backfillWalletEvents seeds the wallet event stream with the state of the wallets registered before it was
+// introduced: their registration, the latest payment of paid subscriptions and the cancellation of inactive wallets
+func backfillWalletEvents(conn *gorm.DB, now int64) error {
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`INSERT INTO wallet_events (wallet, type, reason, subscription_expires_at, timestamp, recorded_at)
+			SELECT address, ?, ?, 0, created_at, ? FROM wallets`,
+			models.WalletEventRegistered, models.WalletEventReasonBackfill, now).Error; err != nil {
+			return fmt.Errorf("failed to backfill wallet registrations: %w", err)
+		}
+		if err := tx.Exec(`INSERT INTO wallet_events (wallet, type, reason, subscription_expires_at, timestamp, recorded_at)
+			SELECT w.address, ?, ?, w.subscription_expires_at,
+				GREATEST(w.created_at, COALESCE((SELECT MAX(p.timestamp) FROM subscription_payments p WHERE p.address = w.subscription_address), w.created_at)), ?
+			FROM wallets w WHERE w.subscription_expires_at > 0`,
+			models.WalletEventPaid, models.WalletEventReasonBackfill, now).Error; err != nil {
+			return fmt.Errorf("failed to backfill wallet subscriptions: %w", err)
+		}
+		if err := tx.Exec(`INSERT INTO wallet_events (wallet, type, reason, subscription_expires_at, timestamp, recorded_at)
+			SELECT address, ?, ?, subscription_expires_at, ?, ? FROM wallets WHERE active = ?`,
+			models.WalletEventCancelled, models.WalletEventReasonBackfill, now, now, false).Error; err != nil {
+			return fmt.Errorf("failed to backfill wallet cancellations: %w", err)
+		}
+		return nil
+	})
+}
+
+// dropSubscriptionAddressUniqueness drops the unique constraint subscription addresses had before several wallets
+// could share one. Depending on the GORM version that created the table, the constraint has Postgres' default name
+// or GORM's, so it is looked up by its column.
+func dropSubscriptionAddressUniqueness(conn *gorm.DB) error {
+	var constraints []string
+	if err := conn.Raw(`SELECT con.conname FROM pg_constraint con
+		JOIN pg_class rel ON rel.oid = con.conrelid
+		JOIN pg_attribute att ON att.attrelid = rel.oid AND att.attnum = con.conkey[1]
+		WHERE rel.relname = 'wallets' AND rel.relnamespace = to_regnamespace(current_schema())
+			AND con.contype = 'u' AND cardinality(con.conkey) = 1 AND att.attname = 'subscription_address'`).
+		Scan(&constraints).Error; err != nil {
+		return fmt.Errorf("failed to find subscription address constraints: %w", err)
+	}
+	for _, constraint := range constraints {
+		if err := conn.Exec("ALTER TABLE wallets DROP CONSTRAINT ?", clause.Table{Name: constraint}).Error; err != nil {
+			return fmt.Errorf("failed to drop subscription address constraint %s: %w", constraint, err)
+		}
+	}
+	return nil
+}
+
+// backfillPaymentWallets credits the payments stored before subscription addresses could be shared to the wallet
+// of their subscription address
+func backfillPaymentWallets(conn *gorm.DB) error {
+	if err := conn.Exec(`UPDATE subscription_payments p SET wallet = w.address
+		FROM wallets w WHERE w.subscription_address = p.address AND p.wallet = ''`).Error; err != nil {
+		return fmt.Errorf("failed to backfill subscription payment wallets: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// GetNFTImage returns the resolved image of a token, or nil when it wasn't resolved yet
+func (db *PostgresDB) GetNFTImage(tokenAddress, tokenID string) (*models.NFTImage, error) {
+	var image models.NFTImage
+	if err := db.Conn.Where("token_address = ? AND token_id = ?", validation.NormalizeAddress(tokenAddress), tokenID).First(&image).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get NFT image: %w", err)
+	}
+	return &image, nil
+}
+
+// SaveNFTImage stores the resolved image of a token, replacing a previous attempt
+func (db *PostgresDB) SaveNFTImage(image *models.NFTImage) error {
+	image.TokenAddress = validation.NormalizeAddress(image.TokenAddress)
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token_address"}, {Name: "token_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"image_url", "resolved_at"}),
+	}).Create(image).Error; err != nil {
+		return fmt.Errorf("failed to save NFT image: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+func (db *PostgresDB) AddNotification(notification *models.Notification) error {
+	notification.Wallet = validation.NormalizeAddress(notification.Wallet)
+	if err := db.Conn.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to add notification: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetNotification(id string) (*models.Notification, error) {
+	var notification models.Notification
+	if err := db.Conn.Where("id = ?", id).First(&notification).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	return &notification, nil
+}
+
+// NotificationExists checks if a notification for the same transfer was already stored.
+// Alerts sent while the transfer was pending don't count.
+func (db *PostgresDB) NotificationExists(notification *models.Notification) (bool, error) {
+	var count int64
+	if err := db.Conn.Model(&models.Notification{}).
+		Where("wallet = ? AND tx_hash = ? AND token_address = ? AND token_id = ? AND event_type <> ?",
+			validation.NormalizeAddress(notification.Wallet), notification.TxHash, notification.TokenAddress, notification.TokenID,
+			models.EventIncomingPending).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check notification existence: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// PendingNotificationExists reports whether the wallet was notified of the transaction while it was pending
+func (db *PostgresDB) PendingNotificationExists(wallet, txHash string) (bool, error) {
+	var count int64
+	if err := db.Conn.Model(&models.Notification{}).
+		Where("wallet = ? AND tx_hash = ? AND event_type = ?", validation.NormalizeAddress(wallet), txHash, models.EventIncomingPending).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check pending notification existence: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (db *PostgresDB) ListNotifications(opts models.ListOptions) (*models.Page[models.Notification], error) {
+	page, err := paginate(db.Conn.Model(&models.Notification{}), opts, models.NotificationListFields,
+		func(n *models.Notification, sort string) (string, string) {
+			if sort == "amount" {
+				return formatFloat(n.Amount), n.ID
+			}
+			return formatInt(n.CreatedAt), n.ID
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	return page, nil
+}
+
+// MarkNotificationRead sets the read timestamp of a notification of the wallet, keeping the first one
+// if it was already read. Returns false if the notification doesn't exist.
+func (db *PostgresDB) MarkNotificationRead(wallet, id string, readAt int64) (bool, error) {
+	result := db.Conn.Model(&models.Notification{}).
+		Where("id = ? AND wallet = ?", id, validation.NormalizeAddress(wallet)).
+		Update("read_at", gorm.Expr("CASE WHEN read_at = 0 THEN ? ELSE read_at END", readAt))
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to mark notification read: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (db *PostgresDB) CountUnreadNotifications(wallet string) (int64, error) {
+	var count int64
+	if err := db.Conn.Model(&models.Notification{}).
+		Where("wallet = ? AND read_at = 0", validation.NormalizeAddress(wallet)).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// AddNotificationDelivery records a notification sent through one channel
+func (db *PostgresDB) AddNotificationDelivery(delivery *models.NotificationDelivery) error {
+	delivery.Wallet = validation.NormalizeAddress(delivery.Wallet)
+	if err := db.Conn.Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to add notification delivery: %w", err)
+	}
+	return nil
+}
+
+// ListNotificationDeliveries returns a page of the notification history of a wallet
+func (db *PostgresDB) ListNotificationDeliveries(wallet string, opts models.ListOptions) (*models.Page[models.NotificationDelivery], error) {
+	query := db.Conn.Model(&models.NotificationDelivery{}).Where("wallet = ?", validation.NormalizeAddress(wallet))
+	page, err := paginate(query, opts, models.NotificationHistoryListFields,
+		func(d *models.NotificationDelivery, sort string) (string, string) {
+			return formatInt(d.CreatedAt), formatInt(d.ID)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification deliveries: %w", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertNtfyProvider sets the ntfy topic of a wallet and re-enables the provider
+func (db *PostgresDB) UpsertNtfyProvider(address, topicURL string) error {
+	address = validation.NormalizeAddress(address)
+	var notificationProvider models.NotificationProvider
+	if err := db.Conn.Preload("NtfyProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+		return fmt.Errorf("failed to get notification provider: %w", err)
+	}
+
+	provider := notificationProvider.NtfyProvider
+	if provider.ID == 0 {
+		provider = models.NtfyProvider{NotificationProviderID: notificationProvider.ID, TopicURL: topicURL}
+		if err := db.Conn.Create(&provider).Error; err != nil {
+			return fmt.Errorf("failed to create ntfy provider: %w", err)
+		}
+	} else if err := db.Conn.Model(&provider).Updates(map[string]interface{}{
+		"topic_url":       topicURL,
+		"disabled":        false,
+		"disabled_reason": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update ntfy provider: %w", err)
+	}
+
+	db.logger.Debug("Updated ntfy provider", "address", address)
+	return nil
+}
+
+// DisableNtfyProvider disables an ntfy provider whose topic the server refuses to publish to
+func (db *PostgresDB) DisableNtfyProvider(id int64, reason string) error {
+	if err := db.Conn.Model(&models.NtfyProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable ntfy provider: %w", err)
+	}
+
+	db.logger.Debug("Disabled ntfy provider", "id", id, "reason", reason)
+	return nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// disabledChannels are the provider tables and columns marking a wallet's channel as disabled
+var disabledChannels = []struct {
+	channel, table, column string
+}{
+	{"telegram", "telegram_providers", "disabled"},
+	{"email", "email_providers", "bounced"},
+	{"push", "fcm_providers", "disabled"},
+	{"discord", "discord_providers", "disabled"},
+	{"sms", "phone_providers", "disabled"},
+	{"matrix", "matrix_providers", "disabled"},
+	{"ntfy", "ntfy_providers", "disabled"},
+	{"pushover", "pushover_providers", "disabled"},
+}
+
+// originWallets selects the addresses of the wallets registered by the originator (compared case-insensitively)
+const originWallets = `SELECT address FROM wallets WHERE LOWER(originator) = ?`
+
+// GetOriginMetrics returns the delivery metrics of the wallets registered by the originator for [from, to)
+func (db *PostgresDB) GetOriginMetrics(origin string, from, to int64) (*models.OriginMetrics, error) {
+	metrics := &models.OriginMetrics{
+		Channels:         make(map[string]int64),
+		EmailEvents:      make(map[string]int64),
+		DisabledChannels: make(map[string]int64),
+	}
+
+	if err := db.Conn.Raw(`SELECT COUNT(*) AS wallets, COUNT(*) FILTER (WHERE paid) AS paid_wallets
+		FROM wallets WHERE LOWER(originator) = ?`, origin).Scan(metrics).Error; err != nil {
+		return nil, fmt.Errorf("failed to count origin wallets: %w", err)
+	}
+
+	if err := db.Conn.Raw(`SELECT COUNT(*) AS notifications, COUNT(*) FILTER (WHERE channels = '') AS undelivered
+		FROM notifications
+		WHERE wallet IN (`+originWallets+`) AND created_at >= ? AND created_at < ?`, origin, from, to).Scan(metrics).Error; err != nil {
+		return nil, fmt.Errorf("failed to count origin notifications: %w", err)
+	}
+
+	var counts []struct {
+		Value string
+		Total int64
+	}
+	if err := db.Conn.Raw(`SELECT channel AS value, COUNT(*) AS total
+		FROM notifications n, unnest(string_to_array(n.channels, ',')) AS channel
+		WHERE n.wallet IN (`+originWallets+`) AND n.created_at >= ? AND n.created_at < ? AND n.channels <> ''
+		GROUP BY 1`, origin, from, to).Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count origin notifications per channel: %w", err)
+	}
+	for _, count := range counts {
+		metrics.Channels[count.Value] = count.Total
+	}
+
+	counts = nil
+	if err := db.Conn.Raw(`SELECT event AS value, COUNT(*) AS total
+		FROM email_events
+		WHERE email IN (
+			SELECT e.email FROM email_providers e
+			JOIN notification_providers np ON np.id = e.notification_provider_id
+			WHERE np.address IN (`+originWallets+`) AND e.email <> ''
+		) AND timestamp >= ? AND timestamp < ?
+		GROUP BY 1`, origin, from, to).Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count origin email events: %w", err)
+	}
+	for _, count := range counts {
+		metrics.EmailEvents[count.Value] = count.Total
+	}
+
+	if err := db.Conn.Raw(`SELECT COUNT(*) AS webhook_deliveries, COUNT(*) FILTER (WHERE NOT success) AS webhook_failures
+		FROM webhook_deliveries
+		WHERE wallet IN (`+originWallets+`) AND created_at >= ? AND created_at < ?`, origin, from, to).Scan(metrics).Error; err != nil {
+		return nil, fmt.Errorf("failed to count origin webhook deliveries: %w", err)
+	}
+
+	for _, disabled := range disabledChannels {
+		var total int64
+		if err := db.Conn.Raw(`SELECT COUNT(*) FROM `+disabled.table+` p
+			JOIN notification_providers np ON np.id = p.notification_provider_id
+			WHERE p.`+disabled.column+` AND np.address IN (`+originWallets+`)`, origin).Scan(&total).Error; err != nil {
+			return nil, fmt.Errorf("failed to count disabled %s channels: %w", disabled.channel, err)
+		}
+		if total > 0 {
+			metrics.DisabledChannels[disabled.channel] = total
+		}
+	}
+
+	return metrics, nil
+}
+
+// CountOriginNotifications returns the number of notifications for wallets registered by the originator in [from, to)
+func (db *PostgresDB) CountOriginNotifications(origin string, from, to int64) (int64, error) {
+	var total int64
+	if err := db.Conn.Model(&models.Notification{}).
+		Where("wallet IN ("+originWallets+") AND created_at >= ? AND created_at < ?", origin, from, to).
+		Count(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to count origin notifications: %w", err)
+	}
+	return total, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"gorm.io/gorm"
+)
+
+// paginate applies the filters, sort and cursor of opts to query and loads a single page.
+// cursorOf returns the sort column value and key column value of an item.
+func paginate[T any](query *gorm.DB, opts models.ListOptions, fields models.ListFields, cursorOf func(item *T, sort string) (value, key string)) (*models.Page[T], error) {
+	column, ok := fields.Sort[opts.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort field %q", opts.Sort)
+	}
+
+	for name, value := range opts.Filters {
+		filter, ok := fields.Filters[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown filter %q", name)
+		}
+		if filter.Condition != "" {
+			query = query.Where(filter.Condition, value)
+			continue
+		}
+		query = query.Where(fmt.Sprintf("%s = ?", filter.Column), value)
+	}
+
+	order, comparison := "ASC", ">"
+	if opts.Desc {
+		order, comparison = "DESC", "<"
+	}
+	if opts.Cursor != nil {
+		query = query.Where(fmt.Sprintf("(%s, %s) %s (?, ?)", column, fields.KeyColumn, comparison), opts.Cursor.Value, opts.Cursor.Key)
+	}
+
+	// Fetch one extra row to know whether another page exists
+	var items []*T
+	if err := query.
+		Order(fmt.Sprintf("%s %s, %s %s", column, order, fields.KeyColumn, order)).
+		Limit(opts.Limit + 1).
+		Find(&items).Error; err != nil {
+		return nil, err
+	}
+
+	page := &models.Page[T]{Items: items}
+	if len(items) > opts.Limit {
+		page.Items = items[:opts.Limit]
+		value, key := cursorOf(page.Items[opts.Limit-1], opts.Sort)
+		sort := opts.Sort
+		if opts.Desc {
+			sort = "-" + sort
+		}
+		page.NextCursor = (&models.Cursor{Sort: sort, Value: value, Key: key}).Encode()
+	}
+
+	return page, nil
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func formatInt(i int64) string {
+	return strconv.FormatInt(i, 10)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddPaymentRequest stores a payment request
+func (db *PostgresDB) AddPaymentRequest(request *models.PaymentRequest) error {
+	request.WalletAddress = validation.NormalizeAddress(request.WalletAddress)
+	if err := db.Conn.Create(request).Error; err != nil {
+		return fmt.Errorf("failed to add payment request: %w", err)
+	}
+	return nil
+}
+
+// GetPaymentRequest returns a payment request by ID
+func (db *PostgresDB) GetPaymentRequest(id string) (*models.PaymentRequest, error) {
+	var request models.PaymentRequest
+	if err := db.Conn.Where("id = ?", id).First(&request).Error; err != nil {
+		return nil, fmt.Errorf("failed to get payment request: %w", err)
+	}
+	return &request, nil
+}
+
+// GetPaymentRequests returns the latest payment requests of a wallet, newest first
+func (db *PostgresDB) GetPaymentRequests(walletAddress string, limit int) ([]*models.PaymentRequest, error) {
+	var requests []*models.PaymentRequest
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(walletAddress)).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get payment requests: %w", err)
+	}
+	return requests, nil
+}
+
+// GetOpenPaymentRequests returns the open payment requests of a wallet for a token, oldest first
+func (db *PostgresDB) GetOpenPaymentRequests(walletAddress, token string) ([]*models.PaymentRequest, error) {
+	var requests []*models.PaymentRequest
+	if err := db.Conn.Where("wallet_address = ? AND token = ? AND status = ?",
+		validation.NormalizeAddress(walletAddress), token, models.PaymentRequestOpen).
+		Order("created_at ASC, id ASC").
+		Find(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to get open payment requests: %w", err)
+	}
+	return requests, nil
+}
+
+// CountOpenPaymentRequests returns the number of open payment requests of a wallet
+func (db *PostgresDB) CountOpenPaymentRequests(walletAddress string) (int64, error) {
+	var count int64
+	if err := db.Conn.Model(&models.PaymentRequest{}).
+		Where("wallet_address = ? AND status = ?", validation.NormalizeAddress(walletAddress), models.PaymentRequestOpen).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count open payment requests: %w", err)
+	}
+	return count, nil
+}
+
+// FulfillPaymentRequest marks an open payment request fulfilled by a transfer. Returns false if it isn't
+// open anymore, e.g. another transfer or instance fulfilled it first.
+func (db *PostgresDB) FulfillPaymentRequest(id, txHash, paidBy string, paidAmount float64, paidAt int64) (bool, error) {
+	result := db.Conn.Model(&models.PaymentRequest{}).
+		Where("id = ? AND status = ?", id, models.PaymentRequestOpen).
+		Updates(map[string]any{
+			"status":      models.PaymentRequestFulfilled,
+			"tx_hash":     txHash,
+			"paid_by":     validation.NormalizeAddress(paidBy),
+			"paid_amount": paidAmount,
+			"paid_at":     paidAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to fulfill payment request: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// CancelPaymentRequest cancels an open payment request of a wallet. Returns false if it doesn't exist or
+// isn't open.
+func (db *PostgresDB) CancelPaymentRequest(walletAddress, id string) (bool, error) {
+	result := db.Conn.Model(&models.PaymentRequest{}).
+		Where("id = ? AND wallet_address = ? AND status = ?", id, validation.NormalizeAddress(walletAddress), models.PaymentRequestOpen).
+		Update("status", models.PaymentRequestCancelled)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to cancel payment request: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertPhoneProvider sets the phone number of a wallet and re-enables the provider
+func (db *PostgresDB) UpsertPhoneProvider(address, phone string) error {
+	address = validation.NormalizeAddress(address)
+	var notificationProvider models.NotificationProvider
+	if err := db.Conn.Preload("PhoneProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+		return fmt.Errorf("failed to get notification provider: %w", err)
+	}
+
+	provider := notificationProvider.PhoneProvider
+	if provider.ID == 0 {
+		provider = models.PhoneProvider{NotificationProviderID: notificationProvider.ID, Phone: phone}
+		if err := db.Conn.Create(&provider).Error; err != nil {
+			return fmt.Errorf("failed to create phone provider: %w", err)
+		}
+	} else if err := db.Conn.Model(&provider).Updates(map[string]interface{}{
+		"phone":           phone,
+		"disabled":        false,
+		"disabled_reason": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update phone provider: %w", err)
+	}
+
+	db.logger.Debug("Updated phone provider", "address", address)
+	return nil
+}
+
+// DisablePhoneProvider disables a phone provider whose number the SMS provider can't deliver to
+func (db *PostgresDB) DisablePhoneProvider(id int64, reason string) error {
+	if err := db.Conn.Model(&models.PhoneProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable phone provider: %w", err)
+	}
+
+	db.logger.Debug("Disabled phone provider", "id", id, "reason", reason)
+	return nil
+}
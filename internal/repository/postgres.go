@@ -1,29 +1,45 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"strings"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormLogger "gorm.io/gorm/logger"
 
 	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/discovery"
 	"github.com/core-coin/nuntiare/pkg/logger"
+	"github.com/core-coin/nuntiare/pkg/validation"
 )
 
+// PostgresConnectTimeout bounds resolving the PostgreSQL hosts and connecting to a single server
+const PostgresConnectTimeout = 5 * time.Second
+
 type PostgresDB struct {
 	logger *logger.Logger
+	// wallets caches the wallets looked up for notifications, nil when disabled
+	wallets *walletCache
 
 	Conn *gorm.DB
 }
 
+// NewPostgresDB connects to the first healthy PostgreSQL server. The host is a comma-separated list of hosts,
+// host:port pairs or "srv:" SRV record names, tried in order until one accepts the connection.
 func NewPostgresDB(user, password, dbname, host string, port int, logger *logger.Logger) (models.Repository, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=disable",
-		host, user, password, dbname, port)
+	ctx, cancel := context.WithTimeout(context.Background(), PostgresConnectTimeout)
+	endpoints, err := discovery.ResolveHosts(ctx, host, port)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve PostgreSQL hosts: %w", err)
+	}
 
 	// Configure GORM logger to suppress "record not found" messages
 	gormLogger := gormLogger.New(
@@ -35,7 +51,19 @@ func NewPostgresDB(user, password, dbname, host string, port int, logger *logger
 			Colorful:                  true,                   // Enable colorful logs
 		},
 	)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger})
+	var db *gorm.DB
+	for _, endpoint := range endpoints {
+		endpointHost, endpointPort, _ := net.SplitHostPort(endpoint)
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=disable connect_timeout=%d",
+			endpointHost, user, password, dbname, endpointPort, int(PostgresConnectTimeout.Seconds()))
+		// gorm pings the server when opening, so only a reachable server is selected
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormLogger})
+		if err == nil {
+			logger.Info("Selected PostgreSQL server", "endpoint", endpoint, "candidates", len(endpoints))
+			break
+		}
+		logger.Warn("PostgreSQL server unavailable, trying the next one", "endpoint", endpoint, "error", err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
 	}
@@ -52,13 +80,67 @@ func NewPostgresDB(user, password, dbname, host string, port int, logger *logger
 	sqlDB.SetConnMaxLifetime(5 * time.Minute)  // Maximum lifetime of a connection
 	sqlDB.SetConnMaxIdleTime(10 * time.Minute) // Maximum idle time of a connection
 
-	if err := db.AutoMigrate(&models.Wallet{}, &models.SubscriptionPayment{}, &models.NotificationProvider{}, &models.TelegramProvider{}, &models.EmailProvider{}, &models.AppLock{}); err != nil {
+	// Emails registered before double opt-in keep receiving notifications
+	verifyExistingEmails := db.Migrator().HasTable(&models.EmailProvider{}) && !db.Migrator().HasColumn(&models.EmailProvider{}, "Verified")
+	// Wallets registered before the event stream get events derived from their current state
+	backfillEvents := db.Migrator().HasTable(&models.Wallet{}) && !db.Migrator().HasTable(&models.WalletEvent{})
+	// Payments stored before subscription addresses could be shared were credited to the only wallet of their address
+	backfillPayments := db.Migrator().HasTable(&models.SubscriptionPayment{}) && !db.Migrator().HasColumn(&models.SubscriptionPayment{}, "Wallet")
+	if db.Migrator().HasTable(&models.Wallet{}) {
+		if err := dropSubscriptionAddressUniqueness(db); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.AutoMigrate(&models.Wallet{}, &models.SubscriptionPayment{}, &models.SubscriptionTransfer{}, &models.WalletOrigin{}, &models.WalletTokenPreference{}, &models.WalletTag{}, &models.User{}, &models.NotificationProvider{}, &models.TelegramProvider{}, &models.EmailProvider{}, &models.FCMProvider{}, &models.WebhookProvider{}, &models.DiscordProvider{}, &models.PhoneProvider{}, &models.MatrixProvider{}, &models.NtfyProvider{}, &models.PushoverProvider{}, &models.AppLock{}, &models.Notification{}, &models.NotificationDelivery{}, &models.DeliveryJob{}, &models.DeadLetter{}, &models.ShortLink{}, &models.NFTImage{}, &models.EmailEvent{}, &models.ReprocessJob{}, &models.ProcessedBlock{}, &models.Device{}, &models.WebPushSubscription{}, &models.MessageTemplate{}, &models.NotificationRollup{}, &models.ShadowNotification{}, &models.ScheduledNotification{}, &models.WebhookDelivery{}, &models.WebhookEvent{}, &models.AutomationHook{}, &models.WidgetFeed{}, &models.PaymentRequest{}, &models.EmailVerification{}, &models.TrustedSender{}, &models.Exchange{}, &models.ExchangeAddress{}, &models.ExchangeDeposit{}, &models.WalletEvent{}, &models.MaintenanceMode{}); err != nil {
 		return nil, fmt.Errorf("failed to auto-migrate models: %w", err)
 	}
+	if verifyExistingEmails {
+		if err := db.Model(&models.EmailProvider{}).Where("email <> ''").Update("verified", true).Error; err != nil {
+			return nil, fmt.Errorf("failed to mark existing emails as verified: %w", err)
+		}
+	}
+	if err := normalizeStoredAddresses(db, logger); err != nil {
+		return nil, fmt.Errorf("failed to normalize stored addresses: %w", err)
+	}
+	if err := createAddressLookupIndexes(db); err != nil {
+		return nil, fmt.Errorf("failed to create address lookup indexes: %w", err)
+	}
+	if err := backfillNotificationEventTypes(db); err != nil {
+		return nil, err
+	}
+	if backfillEvents {
+		if err := backfillWalletEvents(db, time.Now().Unix()); err != nil {
+			return nil, err
+		}
+	}
+	if backfillPayments {
+		if err := backfillPaymentWallets(db); err != nil {
+			return nil, err
+		}
+	}
 	logger.Info("Successfully connected to PostgreSQL with connection pool configured!")
 	return &PostgresDB{Conn: db, logger: logger}, nil
 }
 
+// Ping checks a database connection is usable, it fails when ctx ends first
+func (db *PostgresDB) Ping(ctx context.Context) error {
+	sqlDB, err := db.Conn.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database connection: %w", err)
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// DatabaseTime returns the current time of the database server, the clock all instances share
+func (db *PostgresDB) DatabaseTime() (time.Time, error) {
+	var micros int64
+	if err := db.Conn.Raw("SELECT (EXTRACT(EPOCH FROM clock_timestamp()) * 1000000)::bigint").Scan(&micros).Error; err != nil {
+		return time.Time{}, fmt.Errorf("failed to get database time: %w", err)
+	}
+	return time.UnixMicro(micros), nil
+}
+
 func (db *PostgresDB) Close() error {
 	sqlDB, err := db.Conn.DB()
 	if err != nil {
@@ -67,17 +149,29 @@ func (db *PostgresDB) Close() error {
 	return sqlDB.Close()
 }
 
+// AddNewWallet adds a wallet with its notification providers. Returns ErrWalletExists if the address is
+// registered.
 func (db *PostgresDB) AddNewWallet(wallet *models.Wallet) error {
-	if err := db.Conn.Create(wallet).Error; err != nil {
+	wallet.Address = validation.NormalizeAddress(wallet.Address)
+	wallet.SubscriptionAddress = validation.NormalizeAddress(wallet.SubscriptionAddress)
+	wallet.NotificationProvider.Address = wallet.Address
+
+	// Conflicts are expected on concurrent registrations and returned as errors, they are not logged
+	if err := db.Conn.Session(&gorm.Session{Logger: quietDuplicates{db.Conn.Logger}}).Create(wallet).Error; err != nil {
+		if isDuplicateKey(err) {
+			return models.ErrWalletExists
+		}
 		return fmt.Errorf("failed to create new wallet: %w", err)
 	}
+	db.wallets.invalidate(wallet.Address)
 
 	return nil
 }
 
 func (db *PostgresDB) CheckWalletExists(address string) (bool, error) {
+	address = validation.NormalizeAddress(address)
 	var wallet models.Wallet
-	if err := db.Conn.Where("address = ?", address).First(&wallet).Error; err != nil {
+	if err := db.Conn.Where("lower(address) = ?", address).First(&wallet).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return false, nil
 		}
@@ -88,18 +182,70 @@ func (db *PostgresDB) CheckWalletExists(address string) (bool, error) {
 }
 
 func (db *PostgresDB) GetWallet(address string) (*models.Wallet, error) {
+	address = validation.NormalizeAddress(address)
 	var wallet models.Wallet
-	if err := db.Conn.Where("address = ?", address).First(&wallet).Error; err != nil {
+	if err := db.Conn.Where("lower(address) = ?", address).First(&wallet).Error; err != nil {
 		return nil, fmt.Errorf("failed to get wallet: %w", err)
 	}
 
 	return &wallet, nil
 }
 
-func (db *PostgresDB) AddSubscriptionPayment(subscriptionAddress string, amount float64, timestamp int64) error {
+func (db *PostgresDB) ListWallets(opts models.ListOptions) (*models.Page[models.Wallet], error) {
+	page, err := paginate(db.Conn.Model(&models.Wallet{}).Preload("NotificationProvider.TelegramProvider").Preload("NotificationProvider.EmailProvider"), opts, models.WalletListFields,
+		func(w *models.Wallet, sort string) (string, string) {
+			switch sort {
+			case "address":
+				return w.Address, w.Address
+			case "subscription_expires_at":
+				return formatInt(w.SubscriptionExpiresAt), w.Address
+			default:
+				return formatInt(w.CreatedAt), w.Address
+			}
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+	return page, nil
+}
+
+// SearchWallets returns wallets whose address, subscription address, originator,
+// email or Telegram username contains the query (case-insensitive), newest first
+func (db *PostgresDB) SearchWallets(query string, limit int) ([]*models.Wallet, error) {
+	pattern := "%" + escapeLike(strings.ToLower(strings.TrimPrefix(query, "@"))) + "%"
+	addressPattern := "%" + escapeLike(validation.NormalizeAddress(query)) + "%"
+
+	var wallets []*models.Wallet
+	if err := db.Conn.Preload("NotificationProvider.TelegramProvider").Preload("NotificationProvider.EmailProvider").
+		Where("address LIKE ? OR subscription_address LIKE ? OR lower(originator) LIKE ?", addressPattern, addressPattern, pattern).
+		Or(`address IN (SELECT notification_providers.address FROM notification_providers
+			JOIN email_providers ON email_providers.notification_provider_id = notification_providers.id
+			WHERE lower(email_providers.email) LIKE ?)`, pattern).
+		Or(`address IN (SELECT notification_providers.address FROM notification_providers
+			JOIN telegram_providers ON telegram_providers.notification_provider_id = notification_providers.id
+			WHERE lower(telegram_providers.username) LIKE ?)`, pattern).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to search wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// AddSubscriptionPayment records a payment from the subscription address credited to the wallet
+func (db *PostgresDB) AddSubscriptionPayment(subscriptionAddress, wallet string, amount, monthCost float64, timestamp int64) error {
+	subscriptionAddress = validation.NormalizeAddress(subscriptionAddress)
 	payment := models.SubscriptionPayment{
 		Address:   subscriptionAddress,
+		Wallet:    validation.NormalizeAddress(wallet),
 		Amount:    amount,
+		MonthCost: monthCost,
 		Timestamp: timestamp,
 	}
 	db.logger.Debug("Adding subscription payment ", "payment ", payment)
@@ -109,48 +255,149 @@ func (db *PostgresDB) AddSubscriptionPayment(subscriptionAddress string, amount
 	return nil
 }
 
-func (db *PostgresDB) GetSubscriptionPayments(subscriptionAddress string) ([]*models.SubscriptionPayment, error) {
+// GetSubscriptionPayments returns the subscription payments credited to the wallet
+func (db *PostgresDB) GetSubscriptionPayments(wallet string) ([]*models.SubscriptionPayment, error) {
+	wallet = validation.NormalizeAddress(wallet)
 	var payments []*models.SubscriptionPayment
-	if err := db.Conn.Where("address = ?", subscriptionAddress).Find(&payments).Error; err != nil {
+	if err := db.Conn.Where("wallet = ?", wallet).Find(&payments).Error; err != nil {
 		return nil, fmt.Errorf("failed to get subscription payments: %w", err)
 	}
 
 	return payments, nil
 }
 
-func (db *PostgresDB) RemoveOldSubscriptionPayments(timestamp int64) error {
-	if err := db.Conn.Where("timestamp < ?", timestamp).Delete(&models.SubscriptionPayment{}).Error; err != nil {
-		return fmt.Errorf("failed to remove old subscription payments: %w", err)
+func (db *PostgresDB) ListSubscriptionPayments(opts models.ListOptions) (*models.Page[models.SubscriptionPayment], error) {
+	page, err := paginate(db.Conn.Model(&models.SubscriptionPayment{}), opts, models.PaymentListFields,
+		func(p *models.SubscriptionPayment, sort string) (string, string) {
+			if sort == "amount" {
+				return formatFloat(p.Amount), formatInt(p.ID)
+			}
+			return formatInt(p.Timestamp), formatInt(p.ID)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscription payments: %w", err)
 	}
-
-	return nil
+	return page, nil
 }
 
-func (db *PostgresDB) RemoveUnpaidSubscriptions(timestamp int64) error {
+// RemoveUnpaidSubscriptions removes the unpaid wallets registered before the timestamp with their related rows
+// and returns the removed addresses, also when removing the related rows failed
+func (db *PostgresDB) RemoveUnpaidSubscriptions(timestamp int64) ([]string, error) {
 	// Only delete wallets that:
 	// 1. Were created before the grace period
 	// 2. Currently have paid = false
 	// 3. NEVER had any subscription payment (no entries in subscription_payments table)
 	// This ensures wallets that were once subscribed are kept forever and can be renewed
 	//
-	// Note: subscription_payments.address stores the subscription_address, not wallet address
-	// So we need to check wallet.subscription_address against subscription_payments.address
+	// Note: subscription_payments.wallet stores the wallet the payment was credited to, a payment of a shared
+	// subscription address doesn't keep the wallets it wasn't credited to
 
-	if err := db.Conn.Where(`
+	var removed []*models.Wallet
+	if err := db.Conn.Clauses(clause.Returning{Columns: []clause.Column{{Name: "address"}}}).Where(`
 		created_at < ?
 		AND paid = ?
-		AND subscription_address NOT IN (
-			SELECT DISTINCT address
+		AND address NOT IN (
+			SELECT DISTINCT wallet
 			FROM subscription_payments
 		)
-	`, timestamp, false).Delete(&models.Wallet{}).Error; err != nil {
-		return fmt.Errorf("failed to remove unpaid subscriptions: %w", err)
+	`, timestamp, false).Delete(&removed).Error; err != nil {
+		return nil, fmt.Errorf("failed to remove unpaid subscriptions: %w", err)
+	}
+	addresses := make([]string, len(removed))
+	for i, wallet := range removed {
+		addresses[i] = wallet.Address
 	}
+	db.wallets.invalidate(addresses...)
 
-	return nil
+	// Links of removed wallets must not authenticate a later registration of the same address
+	if err := db.Conn.Where("wallet_address NOT IN (SELECT address FROM wallets)").Delete(&models.WalletOrigin{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove origins of removed wallets: %w", err)
+	}
+	if err := db.Conn.Where("wallet_address NOT IN (SELECT address FROM wallets)").Delete(&models.WalletTokenPreference{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove token preferences of removed wallets: %w", err)
+	}
+	if err := db.Conn.Where("wallet_address NOT IN (SELECT address FROM wallets)").Delete(&models.WalletTag{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove tags of removed wallets: %w", err)
+	}
+	if err := db.Conn.Where("wallet_address NOT IN (SELECT address FROM wallets)").Delete(&models.AutomationHook{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove automation hooks of removed wallets: %w", err)
+	}
+	if err := db.Conn.Where("wallet_address NOT IN (SELECT address FROM wallets)").Delete(&models.WidgetFeed{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove widget feeds of removed wallets: %w", err)
+	}
+	if err := db.Conn.Where("wallet_address NOT IN (SELECT address FROM wallets)").Delete(&models.PaymentRequest{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove payment requests of removed wallets: %w", err)
+	}
+	if err := db.Conn.Where("wallet NOT IN (SELECT address FROM wallets)").Delete(&models.DeliveryJob{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove delivery jobs of removed wallets: %w", err)
+	}
+	// Users whose primary wallet was removed are dissolved, a later registration of the address must not
+	// receive the notifications of their other wallets
+	if err := db.Conn.Model(&models.Wallet{}).
+		Where("user_id IN (SELECT id FROM users WHERE primary_wallet NOT IN (SELECT address FROM wallets))").
+		Update("user_id", "").Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove wallets from users of removed wallets: %w", err)
+	}
+	db.wallets.clear()
+	if err := db.Conn.Where("primary_wallet NOT IN (SELECT address FROM wallets)").Delete(&models.User{}).Error; err != nil {
+		return addresses, fmt.Errorf("failed to remove users of removed wallets: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// GetPaymentInflow returns the subscription payment totals per bucket for payments in [from, to)
+func (db *PostgresDB) GetPaymentInflow(bucketSeconds, from, to int64) ([]*models.PaymentInflow, error) {
+	var inflow []*models.PaymentInflow
+	if err := db.Conn.Raw(`SELECT timestamp - timestamp % ? AS bucket, SUM(amount) AS amount, COUNT(*) AS payments
+		FROM subscription_payments
+		WHERE timestamp >= ? AND timestamp < ?
+		GROUP BY 1
+		ORDER BY 1`, bucketSeconds, from, to).Scan(&inflow).Error; err != nil {
+		return nil, fmt.Errorf("failed to get payment inflow: %w", err)
+	}
+
+	return inflow, nil
+}
+
+// GetUnpaidWalletsWithPayments returns the wallets marked unpaid that have at least one stored subscription payment
+// or received a subscription transfer
+func (db *PostgresDB) GetUnpaidWalletsWithPayments() ([]*models.Wallet, error) {
+	var wallets []*models.Wallet
+	if err := db.Conn.Where(`
+		paid = ?
+		AND (address IN (
+			SELECT DISTINCT wallet
+			FROM subscription_payments
+		) OR address IN (
+			SELECT DISTINCT to_address
+			FROM subscription_transfers
+		))
+	`, false).Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get unpaid wallets: %w", err)
+	}
+
+	return wallets, nil
+}
+
+// SumSubscriptionPayments returns the total amount of the stored subscription payments of wallets on the network,
+// or of all payments when network is empty
+func (db *PostgresDB) SumSubscriptionPayments(network string) (float64, error) {
+	var total float64
+	query := db.Conn.Model(&models.SubscriptionPayment{}).Select("COALESCE(SUM(subscription_payments.amount), 0)")
+	if network != "" {
+		query = query.Joins("JOIN wallets ON wallets.address = subscription_payments.wallet").
+			Where("wallets.network = ?", network)
+	}
+	if err := query.Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum subscription payments: %w", err)
+	}
+
+	return total, nil
 }
 
 func (db *PostgresDB) UpdateWalletPaidStatus(address string, paid bool) error {
+	address = validation.NormalizeAddress(address)
 	var wallet models.Wallet
 	if err := db.Conn.Where("address = ?", address).First(&wallet).Error; err != nil {
 		return fmt.Errorf("failed to get wallet: %w", err)
@@ -160,11 +407,13 @@ func (db *PostgresDB) UpdateWalletPaidStatus(address string, paid bool) error {
 	if err := db.Conn.Save(&wallet).Error; err != nil {
 		return fmt.Errorf("failed to update wallet paid status: %w", err)
 	}
+	db.wallets.invalidate(address)
 
 	return nil
 }
 
 func (db *PostgresDB) UpdateWalletSubscriptionExpiration(address string, expiresAt int64) error {
+	address = validation.NormalizeAddress(address)
 	var wallet models.Wallet
 	if err := db.Conn.Where("address = ?", address).First(&wallet).Error; err != nil {
 		return fmt.Errorf("failed to get wallet: %w", err)
@@ -174,32 +423,90 @@ func (db *PostgresDB) UpdateWalletSubscriptionExpiration(address string, expires
 	if err := db.Conn.Save(&wallet).Error; err != nil {
 		return fmt.Errorf("failed to update wallet subscription expiration: %w", err)
 	}
+	db.wallets.invalidate(address)
 
 	return nil
 }
 
-func (db *PostgresDB) GetWalletBySubscriptionAddress(subscriptionAddress string) (*models.Wallet, error) {
-	var wallet models.Wallet
-	if err := db.Conn.Where("subscription_address = ?", subscriptionAddress).First(&wallet).Error; err != nil {
-		return nil, fmt.Errorf("failed to get wallet by subscription address: %w", err)
+func (db *PostgresDB) GetWallets(addresses []string) ([]*models.Wallet, error) {
+	normalized := make([]string, len(addresses))
+	for i, address := range addresses {
+		normalized[i] = validation.NormalizeAddress(address)
+	}
+	var wallets []*models.Wallet
+	if err := db.Conn.Where("lower(address) IN ?", normalized).Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallets: %w", err)
 	}
 
-	return &wallet, nil
+	return wallets, nil
+}
+
+// GetRegisteredAddresses returns the addresses and subscription addresses of the wallets registered at or after
+// since, of all wallets when since is 0
+func (db *PostgresDB) GetRegisteredAddresses(since int64) ([]string, error) {
+	var wallets []*models.Wallet
+	if err := db.Conn.Select("address", "subscription_address").Where("created_at >= ?", since).Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get registered addresses: %w", err)
+	}
+
+	addresses := make([]string, 0, 2*len(wallets))
+	for _, wallet := range wallets {
+		addresses = append(addresses, wallet.Address, wallet.SubscriptionAddress)
+	}
+	return addresses, nil
+}
+
+// GetWalletsBySubscriptionAddress returns the wallets paid for from the subscription address, oldest
+// registration first
+func (db *PostgresDB) GetWalletsBySubscriptionAddress(subscriptionAddress string) ([]*models.Wallet, error) {
+	subscriptionAddress = validation.NormalizeAddress(subscriptionAddress)
+	var wallets []*models.Wallet
+	if err := db.Conn.Where("lower(subscription_address) = ?", subscriptionAddress).Order("created_at, address").Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallets by subscription address: %w", err)
+	}
+
+	return wallets, nil
 }
 
 func (db *PostgresDB) GetWalletsNotificationProvider(address string) (*models.NotificationProvider, error) {
+	address = validation.NormalizeAddress(address)
 	var notificationProvider models.NotificationProvider
-	if err := db.Conn.Preload("TelegramProvider").Preload("EmailProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+	if err := db.Conn.Preload("TelegramProvider").Preload("EmailProvider").Preload("FCMProvider").Preload("WebhookProvider").Preload("DiscordProvider").Preload("PhoneProvider").Preload("MatrixProvider").Preload("NtfyProvider").Preload("PushoverProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
 		return nil, fmt.Errorf("failed to get wallet's notification provider: %w", err)
 	}
 
 	return &notificationProvider, nil
 }
 
-func (db *PostgresDB) UpdateNotificationProvider(address, telegram, email string) error {
+// SetMutedEventTypes replaces the event types the wallet is not notified about
+func (db *PostgresDB) SetMutedEventTypes(address string, eventTypes []string) error {
+	address = validation.NormalizeAddress(address)
+	if err := db.Conn.Model(&models.NotificationProvider{}).Where("address = ?", address).
+		Update("muted_event_types", strings.Join(eventTypes, ",")).Error; err != nil {
+		return fmt.Errorf("failed to set muted event types: %w", err)
+	}
+	return nil
+}
+
+// SetWalletMinAmounts replaces the minimum amounts per currency of transfers the wallet is notified about
+func (db *PostgresDB) SetWalletMinAmounts(address string, minAmounts map[string]float64) error {
+	address = validation.NormalizeAddress(address)
+	if len(minAmounts) == 0 {
+		minAmounts = nil
+	}
+	if err := db.Conn.Model(&models.Wallet{}).Where("address = ?", address).
+		Select("min_amounts").Updates(&models.Wallet{MinAmounts: minAmounts}).Error; err != nil {
+		return fmt.Errorf("failed to set minimum amounts: %w", err)
+	}
+	db.wallets.invalidate(address)
+	return nil
+}
+
+func (db *PostgresDB) UpdateNotificationProvider(address, telegram, email, fcmToken string) error {
+	address = validation.NormalizeAddress(address)
 	// Get the notification provider
 	var notificationProvider models.NotificationProvider
-	if err := db.Conn.Preload("TelegramProvider").Preload("EmailProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+	if err := db.Conn.Preload("TelegramProvider").Preload("EmailProvider").Preload("FCMProvider").Preload("WebhookProvider").Preload("DiscordProvider").Preload("PhoneProvider").Preload("MatrixProvider").Preload("NtfyProvider").Preload("PushoverProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
 		return fmt.Errorf("failed to get notification provider: %w", err)
 	}
 
@@ -207,7 +514,7 @@ func (db *PostgresDB) UpdateNotificationProvider(address, telegram, email string
 	if telegram != "" {
 		if err := db.Conn.Model(&models.TelegramProvider{}).
 			Where("notification_provider_id = ?", notificationProvider.ID).
-			Update("username", telegram).Error; err != nil {
+			Updates(map[string]interface{}{"username": telegram, "disabled": false, "disabled_reason": "", "disabled_at": 0}).Error; err != nil {
 			return fmt.Errorf("failed to update telegram provider: %w", err)
 		}
 		db.logger.Debug("Updated telegram username", "address", address, "telegram", telegram)
@@ -217,16 +524,31 @@ func (db *PostgresDB) UpdateNotificationProvider(address, telegram, email string
 	if email != "" {
 		if err := db.Conn.Model(&models.EmailProvider{}).
 			Where("notification_provider_id = ?", notificationProvider.ID).
-			Update("email", email).Error; err != nil {
+			Updates(map[string]interface{}{"email": email, "bounced": false, "verified": gorm.Expr("verified AND email = ?", email)}).Error; err != nil {
 			return fmt.Errorf("failed to update email provider: %w", err)
 		}
 		db.logger.Debug("Updated email", "address", address, "email", email)
 	}
 
+	// Update FCM provider if provided. Wallets registered before FCM support have no provider row yet.
+	if fcmToken != "" {
+		if notificationProvider.FCMProvider.ID == 0 {
+			if err := db.Conn.Create(&models.FCMProvider{NotificationProviderID: notificationProvider.ID, Token: fcmToken}).Error; err != nil {
+				return fmt.Errorf("failed to create fcm provider: %w", err)
+			}
+		} else if err := db.Conn.Model(&models.FCMProvider{}).
+			Where("notification_provider_id = ?", notificationProvider.ID).
+			Updates(map[string]interface{}{"token": fcmToken, "disabled": false, "disabled_reason": ""}).Error; err != nil {
+			return fmt.Errorf("failed to update fcm provider: %w", err)
+		}
+		db.logger.Debug("Updated FCM token", "address", address)
+	}
+
 	return nil
 }
 
-func (db *PostgresDB) UpdateWalletMetadata(address, os, lang string) error {
+func (db *PostgresDB) UpdateWalletMetadata(address, os, lang, appVersion string) error {
+	address = validation.NormalizeAddress(address)
 	updates := make(map[string]interface{})
 	if os != "" {
 		updates["os"] = os
@@ -234,6 +556,9 @@ func (db *PostgresDB) UpdateWalletMetadata(address, os, lang string) error {
 	if lang != "" {
 		updates["lang"] = lang
 	}
+	if appVersion != "" {
+		updates["app_version"] = appVersion
+	}
 
 	if len(updates) == 0 {
 		return nil // Nothing to update
@@ -242,22 +567,85 @@ func (db *PostgresDB) UpdateWalletMetadata(address, os, lang string) error {
 	if err := db.Conn.Model(&models.Wallet{}).Where("address = ?", address).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to update wallet metadata: %w", err)
 	}
+	db.wallets.invalidate(address)
+
+	db.logger.Debug("Updated wallet metadata", "address", address, "os", os, "lang", lang, "app_version", appVersion)
+	return nil
+}
+
+// GetWalletsForUpgradeNotice returns active wallets on the OS that reported an app version
+// and haven't been notified about the given minimum version yet
+func (db *PostgresDB) GetWalletsForUpgradeNotice(os, minVersion string) ([]*models.Wallet, error) {
+	var wallets []*models.Wallet
+	if err := db.Conn.Where("lower(os) = ? AND app_version <> '' AND active = ? AND (upgrade_notified_version IS NULL OR upgrade_notified_version <> ?)",
+		strings.ToLower(os), true, minVersion).
+		Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallets for upgrade notice: %w", err)
+	}
+
+	return wallets, nil
+}
+
+func (db *PostgresDB) SetUpgradeNotifiedVersion(address, minVersion string) error {
+	address = validation.NormalizeAddress(address)
+	if err := db.Conn.Model(&models.Wallet{}).Where("address = ?", address).
+		Update("upgrade_notified_version", minVersion).Error; err != nil {
+		return fmt.Errorf("failed to set upgrade notified version: %w", err)
+	}
+	db.wallets.invalidate(address)
+	return nil
+}
 
-	db.logger.Debug("Updated wallet metadata", "address", address, "os", os, "lang", lang)
+// SetWalletLangIfEmpty sets the wallet language only if the user didn't provide one at registration
+func (db *PostgresDB) SetWalletLangIfEmpty(address, lang string) error {
+	address = validation.NormalizeAddress(address)
+	result := db.Conn.Model(&models.Wallet{}).
+		Where("address = ? AND (lang IS NULL OR lang = '')", address).
+		Update("lang", lang)
+	if result.Error != nil {
+		return fmt.Errorf("failed to set wallet language: %w", result.Error)
+	}
+	db.wallets.invalidate(address)
+
+	if result.RowsAffected > 0 {
+		db.logger.Debug("Initialized wallet language", "address", address, "lang", lang)
+	}
 	return nil
 }
 
 func (db *PostgresDB) SetWalletActive(address string, active bool) error {
+	address = validation.NormalizeAddress(address)
 	if err := db.Conn.Model(&models.Wallet{}).Where("address = ?", address).Update("active", active).Error; err != nil {
 		return fmt.Errorf("failed to set wallet active status: %w", err)
 	}
+	db.wallets.invalidate(address)
 
 	db.logger.Debug("Updated wallet active status", "address", address, "active", active)
 	return nil
 }
 
+// SetWalletOwnershipProven flags the wallet as proven to be owned by the user at the timestamp
+func (db *PostgresDB) SetWalletOwnershipProven(address string, provenAt int64) error {
+	address = validation.NormalizeAddress(address)
+	if err := db.Conn.Model(&models.Wallet{}).Where("address = ?", address).Updates(map[string]interface{}{
+		"ownership_proven":    true,
+		"ownership_proven_at": provenAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to set wallet ownership proven: %w", err)
+	}
+	db.wallets.invalidate(address)
+
+	return nil
+}
+
 func (db *PostgresDB) AddTelegramProviderChatID(username, chatID string) error {
-	if err := db.Conn.Model(&models.TelegramProvider{}).Where("username = ?", username).Update("chat_id", chatID).Error; err != nil {
+	// Binding a chat (again) re-enables a provider that was disabled because the bot was blocked
+	if err := db.Conn.Model(&models.TelegramProvider{}).Where("username = ?", username).Updates(map[string]interface{}{
+		"chat_id":         chatID,
+		"disabled":        false,
+		"disabled_reason": "",
+		"disabled_at":     0,
+	}).Error; err != nil {
 		return fmt.Errorf("failed to add telegram provider chat ID: %w", err)
 	}
 	return nil
@@ -269,6 +657,13 @@ func (db *PostgresDB) GetNotificationProvidersByTelegramUsername(username string
 		Where("telegram_providers.username = ?", username).
 		Preload("TelegramProvider").
 		Preload("EmailProvider").
+		Preload("FCMProvider").
+		Preload("WebhookProvider").
+		Preload("DiscordProvider").
+		Preload("PhoneProvider").
+		Preload("MatrixProvider").
+		Preload("NtfyProvider").
+		Preload("PushoverProvider").
 		Find(&notificationProviders).Error; err != nil {
 		return nil, fmt.Errorf("failed to get notification providers by telegram username: %w", err)
 	}
@@ -276,6 +671,74 @@ func (db *PostgresDB) GetNotificationProvidersByTelegramUsername(username string
 	return notificationProviders, nil
 }
 
+func (db *PostgresDB) GetNotificationProvidersByTelegramChatID(chatID string) ([]*models.NotificationProvider, error) {
+	var notificationProviders []*models.NotificationProvider
+	if err := db.Conn.Joins("JOIN telegram_providers ON telegram_providers.notification_provider_id = notification_providers.id").
+		Where("telegram_providers.chat_id = ?", chatID).
+		Preload("TelegramProvider").
+		Preload("EmailProvider").
+		Preload("FCMProvider").
+		Preload("WebhookProvider").
+		Preload("DiscordProvider").
+		Preload("PhoneProvider").
+		Preload("MatrixProvider").
+		Preload("NtfyProvider").
+		Preload("PushoverProvider").
+		Find(&notificationProviders).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification providers by telegram chat ID: %w", err)
+	}
+
+	return notificationProviders, nil
+}
+
+// DisableTelegramProvider disables the enabled telegram providers bound to the chat and returns the IDs of their
+// notification providers. Providers disabled already, e.g. by a concurrent send to the chat, are not returned.
+func (db *PostgresDB) DisableTelegramProvider(chatID, reason string) ([]int64, error) {
+	var disabled []*models.TelegramProvider
+	if err := db.Conn.Model(&disabled).Clauses(clause.Returning{Columns: []clause.Column{{Name: "notification_provider_id"}}}).
+		Where("chat_id = ? AND disabled = ?", chatID, false).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+		"disabled_at":     time.Now().Unix(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to disable telegram provider: %w", err)
+	}
+	ids := make([]int64, len(disabled))
+	for i, provider := range disabled {
+		ids[i] = provider.NotificationProviderID
+	}
+
+	db.logger.Debug("Disabled telegram provider", "chat_id", chatID, "reason", reason, "count", len(ids))
+	return ids, nil
+}
+
+// UpdateTelegramChatID moves all telegram providers from the old chat ID to the new one.
+// Telegram changes the chat ID when a group is upgraded to a supergroup.
+func (db *PostgresDB) UpdateTelegramChatID(oldChatID, newChatID string) error {
+	if err := db.Conn.Model(&models.TelegramProvider{}).Where("chat_id = ?", oldChatID).Update("chat_id", newChatID).Error; err != nil {
+		return fmt.Errorf("failed to update telegram chat ID: %w", err)
+	}
+
+	db.logger.Debug("Updated telegram chat ID", "old_chat_id", oldChatID, "new_chat_id", newChatID)
+	return nil
+}
+
+// DisableFCMProvider disables all FCM providers with the registration token and removes it from the devices
+func (db *PostgresDB) DisableFCMProvider(token, reason string) error {
+	if err := db.Conn.Model(&models.FCMProvider{}).Where("token = ? AND disabled = ?", token, false).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable fcm provider: %w", err)
+	}
+	if err := db.Conn.Model(&models.Device{}).Where("push_token = ?", token).Update("push_token", "").Error; err != nil {
+		return fmt.Errorf("failed to remove push token of devices: %w", err)
+	}
+
+	db.logger.Debug("Disabled FCM provider", "reason", reason)
+	return nil
+}
+
 // TryAcquireLock attempts to acquire a distributed lock
 // Returns true if lock was acquired, false if another instance holds it
 func (db *PostgresDB) TryAcquireLock(lockName, instanceID string, ttlSeconds int) (bool, error) {
@@ -303,8 +766,7 @@ func (db *PostgresDB) TryAcquireLock(lockName, instanceID string, ttlSeconds int
 	result := db.Conn.Session(&gorm.Session{Logger: silentLogger}).Create(lock)
 	if result.Error != nil {
 		// Lock already exists (someone else holds it or not expired yet)
-		if strings.Contains(result.Error.Error(), "duplicate key") ||
-			strings.Contains(result.Error.Error(), "UNIQUE constraint failed") {
+		if isDuplicateKey(result.Error) {
 			db.logger.Debug("Lock already held by another instance", "lock", lockName)
 			return false, nil
 		}
@@ -315,6 +777,28 @@ func (db *PostgresDB) TryAcquireLock(lockName, instanceID string, ttlSeconds int
 	return true, nil
 }
 
+// isDuplicateKey reports whether the insert failed on a primary key or unique constraint
+func isDuplicateKey(err error) bool {
+	return strings.Contains(err.Error(), "duplicate key") || strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// quietDuplicates is a GORM logger that doesn't log primary key and unique constraint violations, for inserts
+// where they are expected and handled. Other errors and slow queries are logged as usual.
+type quietDuplicates struct {
+	gormLogger.Interface
+}
+
+func (l quietDuplicates) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	return quietDuplicates{l.Interface.LogMode(level)}
+}
+
+func (l quietDuplicates) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if err != nil && isDuplicateKey(err) {
+		return
+	}
+	l.Interface.Trace(ctx, begin, fc, err)
+}
+
 // ReleaseLock releases a lock held by this instance
 func (db *PostgresDB) ReleaseLock(lockName, instanceID string) error {
 	result := db.Conn.Where("lock_name = ? AND instance_id = ?", lockName, instanceID).Delete(&models.AppLock{})
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// MarkBlockProcessed records that the block was handled. Blocks already marked by another instance are kept.
+func (db *PostgresDB) MarkBlockProcessed(number uint64, instanceID string) error {
+	block := &models.ProcessedBlock{Number: number, InstanceID: instanceID, ProcessedAt: time.Now().Unix()}
+	if err := db.Conn.Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error; err != nil {
+		return fmt.Errorf("failed to mark block processed: %w", err)
+	}
+	return nil
+}
+
+// GetProcessedBlocks returns the numbers of the processed blocks in from..to
+func (db *PostgresDB) GetProcessedBlocks(from, to uint64) ([]uint64, error) {
+	var numbers []uint64
+	if err := db.Conn.Model(&models.ProcessedBlock{}).
+		Where("number BETWEEN ? AND ?", from, to).
+		Pluck("number", &numbers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get processed blocks: %w", err)
+	}
+	return numbers, nil
+}
+
+// GetLastProcessedBlock returns the highest processed block, 0 if no block was processed yet
+func (db *PostgresDB) GetLastProcessedBlock() (uint64, error) {
+	var number uint64
+	if err := db.Conn.Model(&models.ProcessedBlock{}).
+		Select("COALESCE(MAX(number), 0)").
+		Scan(&number).Error; err != nil {
+		return 0, fmt.Errorf("failed to get last processed block: %w", err)
+	}
+	return number, nil
+}
+
+// RemoveProcessedBlocksBefore deletes the marks of the blocks below the number
+func (db *PostgresDB) RemoveProcessedBlocksBefore(number uint64) error {
+	if err := db.Conn.Where("number < ?", number).Delete(&models.ProcessedBlock{}).Error; err != nil {
+		return fmt.Errorf("failed to remove processed blocks: %w", err)
+	}
+	return nil
+}
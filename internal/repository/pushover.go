@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertPushoverProvider sets the Pushover user key of a wallet and re-enables the provider
+func (db *PostgresDB) UpsertPushoverProvider(address, userKey string) error {
+	address = validation.NormalizeAddress(address)
+	var notificationProvider models.NotificationProvider
+	if err := db.Conn.Preload("PushoverProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+		return fmt.Errorf("failed to get notification provider: %w", err)
+	}
+
+	provider := notificationProvider.PushoverProvider
+	if provider.ID == 0 {
+		provider = models.PushoverProvider{NotificationProviderID: notificationProvider.ID, UserKey: userKey}
+		if err := db.Conn.Create(&provider).Error; err != nil {
+			return fmt.Errorf("failed to create pushover provider: %w", err)
+		}
+	} else if err := db.Conn.Model(&provider).Updates(map[string]interface{}{
+		"user_key":        userKey,
+		"disabled":        false,
+		"disabled_reason": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update pushover provider: %w", err)
+	}
+
+	db.logger.Debug("Updated pushover provider", "address", address)
+	return nil
+}
+
+// DisablePushoverProvider disables a Pushover provider whose user key Pushover rejects
+func (db *PostgresDB) DisablePushoverProvider(id int64, reason string) error {
+	if err := db.Conn.Model(&models.PushoverProvider{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"disabled":        true,
+		"disabled_reason": reason,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to disable pushover provider: %w", err)
+	}
+
+	db.logger.Debug("Disabled pushover provider", "id", id, "reason", reason)
+	return nil
+}
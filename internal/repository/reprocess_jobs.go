@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+func (db *PostgresDB) AddReprocessJob(job *models.ReprocessJob) error {
+	if err := db.Conn.Create(job).Error; err != nil {
+		return fmt.Errorf("failed to add reprocess job: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) UpdateReprocessJob(job *models.ReprocessJob) error {
+	if err := db.Conn.Save(job).Error; err != nil {
+		return fmt.Errorf("failed to update reprocess job: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetReprocessJob(id string) (*models.ReprocessJob, error) {
+	var job models.ReprocessJob
+	if err := db.Conn.Where("id = ?", id).First(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to get reprocess job: %w", err)
+	}
+
+	return &job, nil
+}
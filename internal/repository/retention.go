@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// retentionBatchSize limits how many rows a single DELETE removes to keep table locks short
+const retentionBatchSize = 10000
+
+// RemoveExpiredRecords deletes records of a retention class created before the timestamp
+// and returns the number of deleted rows
+func (db *PostgresDB) RemoveExpiredRecords(class string, before int64) (int64, error) {
+	switch class {
+	case models.RetentionNotifications:
+		notifications, err := db.deleteInBatches(&models.Notification{}, "created_at < ?", before)
+		if err != nil {
+			return notifications, err
+		}
+		shadow, err := db.deleteInBatches(&models.ShadowNotification{}, "created_at < ?", before)
+		if err != nil {
+			return notifications + shadow, err
+		}
+		deliveries, err := db.deleteInBatches(&models.NotificationDelivery{}, "created_at < ?", before)
+		return notifications + shadow + deliveries, err
+	case models.RetentionPayments:
+		// The latest payment credited to every wallet is kept so that lapsed wallets
+		// are still recognized as once subscribed and never removed as unpaid
+		return db.deleteInBatches(&models.SubscriptionPayment{},
+			"timestamp < ? AND id NOT IN (SELECT MAX(id) FROM subscription_payments GROUP BY wallet)", before)
+	case models.RetentionAudit:
+		events, err := db.deleteInBatches(&models.EmailEvent{}, "timestamp < ?", before)
+		if err != nil {
+			return events, err
+		}
+		jobs, err := db.deleteInBatches(&models.ReprocessJob{}, "created_at < ? AND status IN ?", before,
+			[]string{models.ReprocessJobCompleted, models.ReprocessJobFailed, models.ReprocessJobCancelled})
+		if err != nil {
+			return events + jobs, err
+		}
+		scheduled, err := db.deleteInBatches(&models.ScheduledNotification{}, "created_at < ? AND status <> ?", before,
+			models.ScheduledNotificationPending)
+		if err != nil {
+			return events + jobs + scheduled, err
+		}
+		deliveries, err := db.deleteInBatches(&models.WebhookDelivery{}, "created_at < ?", before)
+		if err != nil {
+			return events + jobs + scheduled + deliveries, err
+		}
+		webhookEvents, err := db.deleteInBatches(&models.WebhookEvent{}, "created_at < ?", before)
+		if err != nil {
+			return events + jobs + scheduled + deliveries + webhookEvents, err
+		}
+		deadLetters, err := db.deleteInBatches(&models.DeadLetter{}, "failed_at < ?", before)
+		return events + jobs + scheduled + deliveries + webhookEvents + deadLetters, err
+	default:
+		return 0, fmt.Errorf("unknown retention class %q", class)
+	}
+}
+
+// deleteInBatches deletes the rows of the model matching the condition, retentionBatchSize rows at a time
+func (db *PostgresDB) deleteInBatches(model any, condition string, args ...any) (int64, error) {
+	var total int64
+	for {
+		batch := db.Conn.Model(model).Select("id").Where(condition, args...).Limit(retentionBatchSize)
+		result := db.Conn.Where("id IN (?)", batch).Delete(model)
+		if result.Error != nil {
+			return total, fmt.Errorf("failed to remove expired records: %w", result.Error)
+		}
+		total += result.RowsAffected
+		if result.RowsAffected < retentionBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// rollupQueries count notifications per bucket and dimension value.
+// The bucket size in seconds and the first timestamp to count are bound as parameters.
+var rollupQueries = map[string]string{
+	models.RollupByChannel: `SELECT n.created_at - n.created_at % ? AS bucket_start, channel AS value, COUNT(*) AS total
+		FROM notifications n, unnest(string_to_array(n.channels, ',')) AS channel
+		WHERE n.created_at >= ? AND n.channels <> ''
+		GROUP BY 1, 2`,
+	models.RollupByToken: `SELECT created_at - created_at % ? AS bucket_start, currency AS value, COUNT(*) AS total
+		FROM notifications
+		WHERE created_at >= ?
+		GROUP BY 1, 2`,
+	models.RollupByOrigin: `SELECT n.created_at - n.created_at % ? AS bucket_start, COALESCE(w.originator, '') AS value, COUNT(*) AS total
+		FROM notifications n LEFT JOIN wallets w ON w.address = n.wallet
+		WHERE n.created_at >= ?
+		GROUP BY 1, 2`,
+	models.RollupByEventType: `SELECT created_at - created_at % ? AS bucket_start, COALESCE(event_type, '') AS value, COUNT(*) AS total
+		FROM notifications
+		WHERE created_at >= ?
+		GROUP BY 1, 2`,
+	// Notifications count once per tag the wallet has when the bucket is rolled up
+	models.RollupByTag: `SELECT n.created_at - n.created_at % ? AS bucket_start, t.tag AS value, COUNT(*) AS total
+		FROM notifications n JOIN wallet_tags t ON t.wallet_address = n.wallet
+		WHERE n.created_at >= ?
+		GROUP BY 1, 2`,
+}
+
+// RollupNotifications recomputes the rollups of the period for all buckets starting at or after the timestamp
+func (db *PostgresDB) RollupNotifications(period string, bucketSeconds, from int64) error {
+	for dimension, query := range rollupQueries {
+		if err := db.Conn.Exec(`INSERT INTO notification_rollups (period, bucket_start, dimension, value, total)
+			SELECT ?, bucket_start, ?, value, total FROM (`+query+`) AS counts
+			ON CONFLICT (period, bucket_start, dimension, value) DO UPDATE SET total = EXCLUDED.total`,
+			period, dimension, bucketSeconds, from).Error; err != nil {
+			return fmt.Errorf("failed to roll up notifications by %s: %w", dimension, err)
+		}
+	}
+	return nil
+}
+
+// GetRollupWatermark returns the start of the latest rolled up bucket of the period (0 if none)
+func (db *PostgresDB) GetRollupWatermark(period string) (int64, error) {
+	var watermark int64
+	if err := db.Conn.Model(&models.NotificationRollup{}).Where("period = ?", period).
+		Select("COALESCE(MAX(bucket_start), 0)").Scan(&watermark).Error; err != nil {
+		return 0, fmt.Errorf("failed to get rollup watermark: %w", err)
+	}
+	return watermark, nil
+}
+
+func (db *PostgresDB) GetNotificationRollups(period, dimension string, from, to int64) ([]*models.NotificationRollup, error) {
+	var rollups []*models.NotificationRollup
+	if err := db.Conn.Where("period = ? AND dimension = ? AND bucket_start >= ? AND bucket_start < ?", period, dimension, from, to).
+		Order("bucket_start, value").
+		Find(&rollups).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification rollups: %w", err)
+	}
+	return rollups, nil
+}
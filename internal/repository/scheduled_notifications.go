@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+func (db *PostgresDB) AddScheduledNotification(notification *models.ScheduledNotification) error {
+	if err := db.Conn.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to add scheduled notification: %w", err)
+	}
+	return nil
+}
+
+// FinishScheduledNotification stores the outcome (status, recipients, error and sent time) of a pending
+// notification. Returns false if it isn't pending anymore, e.g. it was cancelled meanwhile.
+func (db *PostgresDB) FinishScheduledNotification(notification *models.ScheduledNotification) (bool, error) {
+	result := db.Conn.Model(&models.ScheduledNotification{}).
+		Where("id = ? AND status = ?", notification.ID, models.ScheduledNotificationPending).
+		Updates(map[string]interface{}{
+			"status":     notification.Status,
+			"recipients": notification.Recipients,
+			"error":      notification.Error,
+			"sent_at":    notification.SentAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to update scheduled notification: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (db *PostgresDB) GetScheduledNotification(id string) (*models.ScheduledNotification, error) {
+	var notification models.ScheduledNotification
+	if err := db.Conn.Where("id = ?", id).First(&notification).Error; err != nil {
+		return nil, fmt.Errorf("failed to get scheduled notification: %w", err)
+	}
+
+	return &notification, nil
+}
+
+// GetDueScheduledNotifications returns up to limit pending notifications due at the timestamp, oldest first
+func (db *PostgresDB) GetDueScheduledNotifications(now int64, limit int) ([]*models.ScheduledNotification, error) {
+	var notifications []*models.ScheduledNotification
+	if err := db.Conn.Where("status = ? AND send_at <= ?", models.ScheduledNotificationPending, now).
+		Order("send_at ASC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due scheduled notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+// CancelScheduledNotification cancels a pending notification. Returns false if it doesn't exist or isn't pending anymore.
+func (db *PostgresDB) CancelScheduledNotification(id string) (bool, error) {
+	result := db.Conn.Model(&models.ScheduledNotification{}).
+		Where("id = ? AND status = ?", id, models.ScheduledNotificationPending).
+		Update("status", models.ScheduledNotificationCancelled)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to cancel scheduled notification: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetActiveWalletAddresses returns the addresses of all wallets with notifications enabled, limited to the
+// wallets with the tag unless it is empty
+func (db *PostgresDB) GetActiveWalletAddresses(tag string) ([]string, error) {
+	var addresses []string
+	query := db.Conn.Model(&models.Wallet{}).Where("active = ?", true)
+	if tag != "" {
+		query = query.Where("address IN (SELECT wallet_address FROM wallet_tags WHERE tag = ?)", tag)
+	}
+	if err := query.Pluck("address", &addresses).Error; err != nil {
+		return nil, fmt.Errorf("failed to get active wallet addresses: %w", err)
+	}
+	return addresses, nil
+}
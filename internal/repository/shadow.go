@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// shadowReportSampleSize is the number of extra/missing notifications listed in a shadow report
+const shadowReportSampleSize = 100
+
+func (db *PostgresDB) AddShadowNotification(notification *models.ShadowNotification) error {
+	notification.Wallet = validation.NormalizeAddress(notification.Wallet)
+	if err := db.Conn.Create(notification).Error; err != nil {
+		return fmt.Errorf("failed to add shadow notification: %w", err)
+	}
+	return nil
+}
+
+// CompareShadowNotifications compares shadow and production notifications created in [from, to)
+func (db *PostgresDB) CompareShadowNotifications(from, to int64) (*models.ShadowReport, error) {
+	report := &models.ShadowReport{From: from, To: to}
+
+	var shadowTotal int64
+	shadow := db.Conn.Model(&models.ShadowNotification{}).Where("created_at >= ? AND created_at < ?", from, to)
+	if err := shadow.Count(&shadowTotal).Error; err != nil {
+		return nil, fmt.Errorf("failed to count shadow notifications: %w", err)
+	}
+
+	extra := db.Conn.Model(&models.ShadowNotification{}).Where("created_at >= ? AND created_at < ?", from, to).
+		Where(`NOT EXISTS (SELECT 1 FROM notifications n WHERE n.wallet = shadow_notifications.wallet
+			AND n.tx_hash = shadow_notifications.tx_hash AND n.token_address = shadow_notifications.token_address
+			AND n.token_id = shadow_notifications.token_id)`)
+	if err := extra.Count(&report.ExtraCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count extra shadow notifications: %w", err)
+	}
+	if err := extra.Order("created_at").Limit(shadowReportSampleSize).Find(&report.Extra).Error; err != nil {
+		return nil, fmt.Errorf("failed to get extra shadow notifications: %w", err)
+	}
+
+	missing := db.Conn.Model(&models.Notification{}).Where("created_at >= ? AND created_at < ?", from, to).
+		Where(`NOT EXISTS (SELECT 1 FROM shadow_notifications s WHERE s.wallet = notifications.wallet
+			AND s.tx_hash = notifications.tx_hash AND s.token_address = notifications.token_address
+			AND s.token_id = notifications.token_id)`)
+	if err := missing.Count(&report.MissingCount).Error; err != nil {
+		return nil, fmt.Errorf("failed to count missing shadow notifications: %w", err)
+	}
+	if err := missing.Order("created_at").Limit(shadowReportSampleSize).Find(&report.Missing).Error; err != nil {
+		return nil, fmt.Errorf("failed to get missing shadow notifications: %w", err)
+	}
+
+	report.Matched = shadowTotal - report.ExtraCount
+	return report, nil
+}
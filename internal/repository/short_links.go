@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+func (db *PostgresDB) AddShortLink(link *models.ShortLink) error {
+	if err := db.Conn.Create(link).Error; err != nil {
+		return fmt.Errorf("failed to add short link: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetShortLink(code string) (*models.ShortLink, error) {
+	var link models.ShortLink
+	if err := db.Conn.Where("code = ?", code).First(&link).Error; err != nil {
+		return nil, fmt.Errorf("failed to get short link: %w", err)
+	}
+
+	return &link, nil
+}
+
+// IncrementShortLinkClicks atomically counts a click on the short link
+func (db *PostgresDB) IncrementShortLinkClicks(code string) error {
+	if err := db.Conn.Model(&models.ShortLink{}).Where("code = ?", code).Updates(map[string]interface{}{
+		"clicks":          gorm.Expr("clicks + 1"),
+		"last_clicked_at": time.Now().Unix(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to increment short link clicks: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// TransferSubscription moves the remaining subscription time of the source wallet to the destination wallet
+// and records the transfer. Both wallets are locked, so a payment credited at the same time is not lost.
+// The source expires at the transfer time; the destination is extended from its expiration or from now.
+func (db *PostgresDB) TransferSubscription(transfer *models.SubscriptionTransfer) error {
+	transfer.FromAddress = validation.NormalizeAddress(transfer.FromAddress)
+	transfer.ToAddress = validation.NormalizeAddress(transfer.ToAddress)
+	defer db.wallets.invalidate(transfer.FromAddress, transfer.ToAddress)
+
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		var wallets []*models.Wallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("address IN ?", []string{transfer.FromAddress, transfer.ToAddress}).
+			Find(&wallets).Error; err != nil {
+			return fmt.Errorf("failed to lock wallets: %w", err)
+		}
+		var from, to *models.Wallet
+		for _, wallet := range wallets {
+			switch wallet.Address {
+			case transfer.FromAddress:
+				from = wallet
+			case transfer.ToAddress:
+				to = wallet
+			}
+		}
+		if from == nil || to == nil {
+			return fmt.Errorf("failed to get wallet: %w", gorm.ErrRecordNotFound)
+		}
+		if from.SubscriptionExpiresAt <= transfer.Timestamp {
+			return models.ErrNoActiveSubscription
+		}
+
+		transfer.Seconds = from.SubscriptionExpiresAt - transfer.Timestamp
+		transfer.FromExpiresAt = from.SubscriptionExpiresAt
+		transfer.ToExpiresAt = max(to.SubscriptionExpiresAt, transfer.Timestamp) + transfer.Seconds
+
+		if err := tx.Model(&models.Wallet{}).Where("address = ?", from.Address).
+			Updates(map[string]interface{}{"subscription_expires_at": transfer.Timestamp, "paid": false}).Error; err != nil {
+			return fmt.Errorf("failed to update source wallet: %w", err)
+		}
+		if err := tx.Model(&models.Wallet{}).Where("address = ?", to.Address).
+			Updates(map[string]interface{}{"subscription_expires_at": transfer.ToExpiresAt, "paid": true}).Error; err != nil {
+			return fmt.Errorf("failed to update destination wallet: %w", err)
+		}
+		if err := tx.Create(transfer).Error; err != nil {
+			return fmt.Errorf("failed to record subscription transfer: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetSubscriptionTransfers returns the subscription transfers from or to the wallet, oldest first
+func (db *PostgresDB) GetSubscriptionTransfers(address string) ([]*models.SubscriptionTransfer, error) {
+	address = validation.NormalizeAddress(address)
+	var transfers []*models.SubscriptionTransfer
+	if err := db.Conn.Where("from_address = ? OR to_address = ?", address, address).
+		Order("timestamp ASC, id ASC").
+		Find(&transfers).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subscription transfers: %w", err)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// SetWalletTokenPreference creates or replaces the preference of a wallet for a token
+func (db *PostgresDB) SetWalletTokenPreference(preference *models.WalletTokenPreference) error {
+	preference.WalletAddress = validation.NormalizeAddress(preference.WalletAddress)
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_address"}, {Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"notify", "updated_at"}),
+	}).Create(preference).Error; err != nil {
+		return fmt.Errorf("failed to set token preference: %w", err)
+	}
+	return nil
+}
+
+// GetWalletTokenPreferences returns the token preferences of a wallet, oldest first
+func (db *PostgresDB) GetWalletTokenPreferences(address string) ([]*models.WalletTokenPreference, error) {
+	var preferences []*models.WalletTokenPreference
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(address)).
+		Order("id ASC").
+		Find(&preferences).Error; err != nil {
+		return nil, fmt.Errorf("failed to get token preferences: %w", err)
+	}
+	return preferences, nil
+}
+
+// RemoveWalletTokenPreference deletes the preference of a wallet for a token. Returns false if it doesn't exist.
+func (db *PostgresDB) RemoveWalletTokenPreference(address, token string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ? AND token = ?", validation.NormalizeAddress(address), token).
+		Delete(&models.WalletTokenPreference{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove token preference: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
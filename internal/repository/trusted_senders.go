@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+func (db *PostgresDB) GetTrustedSenders() ([]*models.TrustedSender, error) {
+	var senders []*models.TrustedSender
+	if err := db.Conn.Order("name, address").Find(&senders).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trusted senders: %w", err)
+	}
+
+	return senders, nil
+}
+
+// UpsertTrustedSender stores a trusted sender or replaces the name and category of an existing one
+func (db *PostgresDB) UpsertTrustedSender(sender *models.TrustedSender) error {
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "category", "updated_at"}),
+	}).Create(sender).Error; err != nil {
+		return fmt.Errorf("failed to upsert trusted sender: %w", err)
+	}
+	return nil
+}
+
+// DeleteTrustedSender removes a trusted sender. Returns false if the address isn't trusted.
+func (db *PostgresDB) DeleteTrustedSender(address string) (bool, error) {
+	result := db.Conn.Where("address = ?", address).Delete(&models.TrustedSender{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to delete trusted sender: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
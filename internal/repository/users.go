@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddUser stores a new user and assigns its primary wallet to it
+func (db *PostgresDB) AddUser(user *models.User) error {
+	user.PrimaryWallet = validation.NormalizeAddress(user.PrimaryWallet)
+	defer db.wallets.invalidate(user.PrimaryWallet)
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return fmt.Errorf("failed to add user: %w", err)
+		}
+		if err := tx.Model(&models.Wallet{}).Where("address = ?", user.PrimaryWallet).Update("user_id", user.ID).Error; err != nil {
+			return fmt.Errorf("failed to assign primary wallet to user: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUser returns a user by its ID
+func (db *PostgresDB) GetUser(id string) (*models.User, error) {
+	var user models.User
+	if err := db.Conn.Where("id = ?", id).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// GetUserWallets returns the wallets of a user, oldest first
+func (db *PostgresDB) GetUserWallets(userID string) ([]*models.Wallet, error) {
+	var wallets []*models.Wallet
+	if err := db.Conn.Where("user_id = ?", userID).Order("created_at ASC, address ASC").Find(&wallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user wallets: %w", err)
+	}
+	return wallets, nil
+}
+
+// SetWalletUser assigns the wallet to a user, an empty user ID removes it from its user
+func (db *PostgresDB) SetWalletUser(address, userID string) error {
+	address = validation.NormalizeAddress(address)
+	if err := db.Conn.Model(&models.Wallet{}).Where("address = ?", address).
+		Update("user_id", userID).Error; err != nil {
+		return fmt.Errorf("failed to set wallet user: %w", err)
+	}
+	db.wallets.invalidate(address)
+	return nil
+}
+
+// RemoveUser deletes a user, its wallets are notified through their own notification providers again
+func (db *PostgresDB) RemoveUser(id string) error {
+	defer db.wallets.clear()
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Wallet{}).Where("user_id = ?", id).Update("user_id", "").Error; err != nil {
+			return fmt.Errorf("failed to remove wallets from user: %w", err)
+		}
+		if err := tx.Where("id = ?", id).Delete(&models.User{}).Error; err != nil {
+			return fmt.Errorf("failed to remove user: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetUserNotificationProvider returns the notification provider the wallet is notified through: the provider of
+// its user's primary wallet, or its own when it has no user or the primary wallet left the user
+func (db *PostgresDB) GetUserNotificationProvider(address string) (*models.NotificationProvider, error) {
+	address = validation.NormalizeAddress(address)
+	var primaryWallets []string
+	if err := db.Conn.Table("wallets").
+		Joins("JOIN users ON users.id = wallets.user_id").
+		Joins("JOIN wallets primary_wallets ON primary_wallets.address = users.primary_wallet AND primary_wallets.user_id = users.id").
+		Where("wallets.address = ?", address).
+		Pluck("users.primary_wallet", &primaryWallets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet's user: %w", err)
+	}
+
+	if len(primaryWallets) > 0 && primaryWallets[0] != address {
+		provider, err := db.GetWalletsNotificationProvider(primaryWallets[0])
+		if err == nil {
+			return provider, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	return db.GetWalletsNotificationProvider(address)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"maps"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// walletCache keeps the wallets looked up for notifications in memory. The least recently used wallets are
+// evicted beyond the size, and entries expire after the TTL, which bounds how long an update made by another
+// instance goes unnoticed. Updates made through the repository invalidate the wallet right away. A nil cache
+// caches nothing.
+type walletCache struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	// order lists the entries, most recently used first
+	order *list.List
+	// generation is incremented by every invalidation, a wallet read before it isn't cached
+	generation uint64
+}
+
+type walletCacheEntry struct {
+	address  string
+	wallet   *models.Wallet
+	cachedAt time.Time
+}
+
+func newWalletCache(size int, ttl time.Duration) *walletCache {
+	return &walletCache{size: size, ttl: ttl, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+// get returns a copy of the cached wallet, or nil when it isn't cached or expired
+func (c *walletCache) get(address string) *models.Wallet {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[address]
+	if !ok {
+		return nil
+	}
+	entry := element.Value.(*walletCacheEntry)
+	if time.Since(entry.cachedAt) > c.ttl {
+		c.order.Remove(element)
+		delete(c.entries, address)
+		return nil
+	}
+	c.order.MoveToFront(element)
+	return copyWallet(entry.wallet)
+}
+
+// currentGeneration returns the generation to pass to put for a wallet about to be read from the database
+func (c *walletCache) currentGeneration() uint64 {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// put caches a copy of the wallet read at the generation, unless a wallet was invalidated since, in which
+// case the read may predate the update
+func (c *walletCache) put(address string, wallet *models.Wallet, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	entry := &walletCacheEntry{address: address, wallet: copyWallet(wallet), cachedAt: time.Now()}
+	if element, ok := c.entries[address]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[address] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*walletCacheEntry).address)
+	}
+}
+
+// invalidate removes the wallets from the cache
+func (c *walletCache) invalidate(addresses ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, address := range addresses {
+		if element, ok := c.entries[address]; ok {
+			c.order.Remove(element)
+			delete(c.entries, address)
+		}
+	}
+}
+
+// clear removes all wallets from the cache, for updates of wallets selected by something else than the address
+func (c *walletCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+}
+
+// copyWallet copies a wallet so callers can't modify the cached one
+func copyWallet(wallet *models.Wallet) *models.Wallet {
+	copied := *wallet
+	copied.MinAmounts = maps.Clone(wallet.MinAmounts)
+	return &copied
+}
+
+// EnableWalletCache caches up to size wallets looked up with LookupWallet for the TTL. It must be called before
+// the repository is used.
+func (db *PostgresDB) EnableWalletCache(size int, ttl time.Duration) {
+	db.wallets = newWalletCache(size, ttl)
+}
+
+// LookupWallet returns the wallet, or nil when the address isn't registered. With cached set, the wallet is
+// served from the wallet cache when it is enabled, and may miss the updates of other instances for up to the
+// cache's TTL. Without, it is read from the database and the cache refreshed.
+func (db *PostgresDB) LookupWallet(address string, cached bool) (*models.Wallet, error) {
+	address = validation.NormalizeAddress(address)
+	if cached {
+		if wallet := db.wallets.get(address); wallet != nil {
+			return wallet, nil
+		}
+	}
+
+	generation := db.wallets.currentGeneration()
+	var wallet models.Wallet
+	if err := db.Conn.Where("lower(address) = ?", address).First(&wallet).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			db.wallets.invalidate(address)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up wallet: %w", err)
+	}
+	db.wallets.put(address, &wallet, generation)
+	return &wallet, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddWalletEvents appends events to the wallet event stream
+func (db *PostgresDB) AddWalletEvents(events []*models.WalletEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, event := range events {
+		event.Wallet = validation.NormalizeAddress(event.Wallet)
+	}
+	if err := db.Conn.Create(&events).Error; err != nil {
+		return fmt.Errorf("failed to add wallet events: %w", err)
+	}
+	return nil
+}
+
+// WithWalletEvents runs fn on a repository bound to a database transaction and appends the wallet events fn
+// returns in the same transaction, so wallet changes are never stored without their events or the other way
+// round. Nothing is stored if fn fails.
+func (db *PostgresDB) WithWalletEvents(fn func(repo models.Repository) ([]*models.WalletEvent, error)) error {
+	var events []*models.WalletEvent
+	err := db.Conn.Transaction(func(tx *gorm.DB) error {
+		txDB := &PostgresDB{logger: db.logger, wallets: db.wallets, Conn: tx}
+		var err error
+		if events, err = fn(txDB); err != nil {
+			return err
+		}
+		return txDB.AddWalletEvents(events)
+	})
+	if err != nil {
+		return err
+	}
+	// Wallets cached while the transaction was open may be stale
+	for _, event := range events {
+		db.wallets.invalidate(event.Wallet)
+	}
+	return nil
+}
+
+// GetWalletEvents returns the events of a wallet that happened at or before the timestamp, in the order they happened
+func (db *PostgresDB) GetWalletEvents(address string, until int64) ([]*models.WalletEvent, error) {
+	var events []*models.WalletEvent
+	if err := db.Conn.Where("wallet = ? AND timestamp <= ?", validation.NormalizeAddress(address), until).
+		Order("timestamp ASC, id ASC").
+		Find(&events).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// LinkWalletOrigin links an origin to a wallet. A previous link of the same app (e.g. before the app was
+// reinstalled with a new OriginID) is replaced.
+func (db *PostgresDB) LinkWalletOrigin(origin *models.WalletOrigin) error {
+	origin.WalletAddress = validation.NormalizeAddress(origin.WalletAddress)
+	return db.Conn.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("wallet_address = ? AND origin = ? AND origin_id <> ?", origin.WalletAddress, origin.Origin, origin.OriginID).
+			Delete(&models.WalletOrigin{}).Error; err != nil {
+			return fmt.Errorf("failed to replace wallet origin: %w", err)
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "wallet_address"}, {Name: "origin_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"origin"}),
+		}).Create(origin).Error; err != nil {
+			return fmt.Errorf("failed to link wallet origin: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetWalletOrigins returns the origins linked to a wallet, oldest first
+func (db *PostgresDB) GetWalletOrigins(address string) ([]*models.WalletOrigin, error) {
+	var origins []*models.WalletOrigin
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(address)).
+		Order("linked_at ASC, id ASC").
+		Find(&origins).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet origins: %w", err)
+	}
+	return origins, nil
+}
+
+// GetWalletOriginByID returns the linked origin of the wallet with the OriginID, or nil if it isn't linked
+func (db *PostgresDB) GetWalletOriginByID(address, originID string) (*models.WalletOrigin, error) {
+	var origins []*models.WalletOrigin
+	if err := db.Conn.Where("wallet_address = ? AND origin_id = ?", validation.NormalizeAddress(address), originID).
+		Limit(1).
+		Find(&origins).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet origin: %w", err)
+	}
+	if len(origins) == 0 {
+		return nil, nil
+	}
+	return origins[0], nil
+}
+
+// UnlinkWalletOrigin removes the link of an app from a wallet. Returns false if the app isn't linked.
+func (db *PostgresDB) UnlinkWalletOrigin(address, origin string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ? AND origin = ?", validation.NormalizeAddress(address), origin).
+		Delete(&models.WalletOrigin{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to unlink wallet origin: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
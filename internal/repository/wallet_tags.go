@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// AddWalletTag attaches a tag to a wallet. Attaching a tag the wallet already has keeps the original assignment.
+func (db *PostgresDB) AddWalletTag(tag *models.WalletTag) error {
+	tag.WalletAddress = validation.NormalizeAddress(tag.WalletAddress)
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_address"}, {Name: "tag"}},
+		DoNothing: true,
+	}).Create(tag).Error; err != nil {
+		return fmt.Errorf("failed to add wallet tag: %w", err)
+	}
+	return nil
+}
+
+// GetWalletTags returns the tags of a wallet in alphabetical order
+func (db *PostgresDB) GetWalletTags(address string) ([]*models.WalletTag, error) {
+	var tags []*models.WalletTag
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(address)).
+		Order("tag ASC").
+		Find(&tags).Error; err != nil {
+		return nil, fmt.Errorf("failed to get wallet tags: %w", err)
+	}
+	return tags, nil
+}
+
+// RemoveWalletTag detaches a tag from a wallet. Returns false if the wallet doesn't have it.
+func (db *PostgresDB) RemoveWalletTag(address, tag string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ? AND tag = ?", validation.NormalizeAddress(address), tag).
+		Delete(&models.WalletTag{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove wallet tag: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetWalletTagCounts returns the number of wallets per tag in alphabetical order
+func (db *PostgresDB) GetWalletTagCounts() ([]*models.WalletTagCount, error) {
+	var counts []*models.WalletTagCount
+	if err := db.Conn.Model(&models.WalletTag{}).
+		Select("tag, COUNT(*) AS wallets").
+		Group("tag").
+		Order("tag ASC").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count wallet tags: %w", err)
+	}
+	return counts, nil
+}
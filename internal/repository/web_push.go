@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertWebPushSubscription stores a browser push subscription or refreshes the keys of an existing one
+func (db *PostgresDB) UpsertWebPushSubscription(subscription *models.WebPushSubscription) error {
+	subscription.WalletAddress = validation.NormalizeAddress(subscription.WalletAddress)
+	if err := db.Conn.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "wallet_address"}, {Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"p256dh", "auth"}),
+	}).Create(subscription).Error; err != nil {
+		return fmt.Errorf("failed to upsert web push subscription: %w", err)
+	}
+	return nil
+}
+
+func (db *PostgresDB) GetWebPushSubscriptions(walletAddress string) ([]*models.WebPushSubscription, error) {
+	var subscriptions []*models.WebPushSubscription
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(walletAddress)).
+		Order("created_at DESC").
+		Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get web push subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+// RemoveWebPushSubscription deletes a push subscription of a wallet. Returns false if the subscription doesn't exist.
+func (db *PostgresDB) RemoveWebPushSubscription(walletAddress, endpoint string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ? AND endpoint = ?", validation.NormalizeAddress(walletAddress), endpoint).
+		Delete(&models.WebPushSubscription{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove web push subscription: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
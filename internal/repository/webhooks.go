@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// UpsertWebhookProvider sets the webhook URL of a wallet. The secret is only stored when the wallet
+// has no webhook yet, so re-registering keeps the existing signing key. Returns the stored provider.
+func (db *PostgresDB) UpsertWebhookProvider(address, url, secret string) (*models.WebhookProvider, error) {
+	address = validation.NormalizeAddress(address)
+	var notificationProvider models.NotificationProvider
+	if err := db.Conn.Preload("WebhookProvider").Where("address = ?", address).First(&notificationProvider).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification provider: %w", err)
+	}
+
+	provider := notificationProvider.WebhookProvider
+	if provider.ID == 0 {
+		provider = models.WebhookProvider{NotificationProviderID: notificationProvider.ID, URL: url, Secret: secret}
+		if err := db.Conn.Create(&provider).Error; err != nil {
+			return nil, fmt.Errorf("failed to create webhook provider: %w", err)
+		}
+	} else {
+		if err := db.Conn.Model(&provider).Update("url", url).Error; err != nil {
+			return nil, fmt.Errorf("failed to update webhook provider: %w", err)
+		}
+		provider.URL = url
+	}
+
+	db.logger.Debug("Updated webhook URL", "address", address)
+	return &provider, nil
+}
+
+func (db *PostgresDB) AddWebhookDelivery(delivery *models.WebhookDelivery) error {
+	if err := db.Conn.Create(delivery).Error; err != nil {
+		return fmt.Errorf("failed to add webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// AddWebhookEvent stores an event sent to a wallet's webhook
+func (db *PostgresDB) AddWebhookEvent(event *models.WebhookEvent) error {
+	event.Wallet = validation.NormalizeAddress(event.Wallet)
+	if err := db.Conn.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to add webhook event: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookEvent saves the delivery state of a webhook event
+func (db *PostgresDB) UpdateWebhookEvent(event *models.WebhookEvent) error {
+	if err := db.Conn.Model(event).Select("attempts", "delivered", "last_attempt_at").Updates(event).Error; err != nil {
+		return fmt.Errorf("failed to update webhook event: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookEvent returns a webhook event by its ID
+func (db *PostgresDB) GetWebhookEvent(id string) (*models.WebhookEvent, error) {
+	var event models.WebhookEvent
+	if err := db.Conn.Where("id = ?", id).First(&event).Error; err != nil {
+		return nil, fmt.Errorf("failed to get webhook event: %w", err)
+	}
+	return &event, nil
+}
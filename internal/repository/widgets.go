@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/core-coin/nuntiare/internal/models"
+	"github.com/core-coin/nuntiare/pkg/validation"
+)
+
+// SetWidgetFeed creates or replaces the widget feed of a wallet
+func (db *PostgresDB) SetWidgetFeed(feed *models.WidgetFeed) error {
+	feed.WalletAddress = validation.NormalizeAddress(feed.WalletAddress)
+	if err := db.Conn.Save(feed).Error; err != nil {
+		return fmt.Errorf("failed to set widget feed: %w", err)
+	}
+	return nil
+}
+
+// GetWidgetFeed returns the widget feed of a wallet
+func (db *PostgresDB) GetWidgetFeed(walletAddress string) (*models.WidgetFeed, error) {
+	var feed models.WidgetFeed
+	if err := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(walletAddress)).First(&feed).Error; err != nil {
+		return nil, fmt.Errorf("failed to get widget feed: %w", err)
+	}
+	return &feed, nil
+}
+
+// RemoveWidgetFeed removes the widget feed of a wallet. Returns false if it has none.
+func (db *PostgresDB) RemoveWidgetFeed(walletAddress string) (bool, error) {
+	result := db.Conn.Where("wallet_address = ?", validation.NormalizeAddress(walletAddress)).Delete(&models.WidgetFeed{})
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to remove widget feed: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetWidgetNotifications returns the latest notifications of a wallet shown in widget feeds: received
+// transfers that aren't internal or of lookalike tokens, newest first
+func (db *PostgresDB) GetWidgetNotifications(walletAddress string, limit int) ([]*models.Notification, error) {
+	var notifications []*models.Notification
+	if err := db.Conn.
+		Where("wallet = ? AND event_type IN ? AND internal = ? AND lookalike_token = ?",
+			validation.NormalizeAddress(walletAddress), models.WidgetEventTypes, false, false).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&notifications).Error; err != nil {
+		return nil, fmt.Errorf("failed to get widget notifications: %w", err)
+	}
+	return notifications, nil
+}
@@ -0,0 +1,91 @@
+package templates
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// emailHTML is the HTML part of notification emails
+//
+//go:embed email/notification.html
+var emailHTML string
+
+var emailHTMLTemplate = template.Must(template.New("email").Funcs(template.FuncMap{"shortAddress": ShortAddress}).
+	Option("missingkey=error").Parse(emailHTML))
+
+// EmailHTMLData is the value the HTML email template is executed with
+type EmailHTMLData struct {
+	*Data
+	// Lang is the language of the email
+	Lang string
+	// Subject is the email subject, also shown as heading
+	Subject string
+	// Text is the plain text part, shown instead of the transfer cards for custom messages
+	Text string
+
+	// label renders a translated label, set when rendering
+	label func(name string, data any) (string, error)
+}
+
+// T renders the translated label with the data, the labels are the "email_html_*" message templates
+func (d *EmailHTMLData) T(name string, data any) (string, error) {
+	return d.label(name, data)
+}
+
+// Cards returns one card per transfer of the notification
+func (d *EmailHTMLData) Cards() []models.NotificationTransfer {
+	if len(d.Transfers) > 0 {
+		return d.Transfers
+	}
+	return []models.NotificationTransfer{{
+		From:         d.From,
+		Amount:       d.Amount,
+		Currency:     d.Currency,
+		TokenAddress: d.TokenAddress,
+		TokenType:    d.TokenType,
+		TokenID:      d.TokenID,
+		Internal:     d.Internal,
+
+		VerifiedSender: d.VerifiedSender,
+		LookalikeToken: d.LookalikeToken,
+	}}
+}
+
+// RenderEmailHTML renders the HTML part of a notification email in data.Lang: a card per transfer and a
+// button linking to the transaction in the explorer. Labels the language doesn't define are English.
+func (m *Messages) RenderEmailHTML(data *EmailHTMLData) (string, error) {
+	m.mu.RLock()
+	set, fallback := m.sets[data.Lang], m.sets[DefaultLang]
+	m.mu.RUnlock()
+	if set == nil {
+		set = fallback
+	}
+	return renderEmailHTML(data, set, fallback)
+}
+
+// renderEmailHTML renders the HTML part of a notification email with the labels of the message templates
+func renderEmailHTML(data *EmailHTMLData, set, fallback *texttemplate.Template) (string, error) {
+	data.label = func(name string, labelData any) (string, error) {
+		labels := set
+		if labels.Lookup(name) == nil {
+			labels = fallback
+		}
+		var buf strings.Builder
+		if err := labels.ExecuteTemplate(&buf, name, labelData); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	var buf bytes.Buffer
+	if err := emailHTMLTemplate.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render email template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
@@ -0,0 +1,214 @@
+package templates
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// DefaultLang is the language used for wallets without a language or with a language that has no templates
+const DefaultLang = "en"
+
+// Message templates rendered for the channels
+const (
+	MessageTelegram     = "telegram"
+	MessageEmail        = "email"
+	MessageEmailSubject = "email_subject"
+)
+
+// MessageNames lists the message templates that can be overridden
+var MessageNames = []string{MessageTelegram, MessageEmail, MessageEmailSubject}
+
+// MessageAppUpgrade is the notice sent to wallets using an outdated app, rendered with AppUpgradeData.
+// Language packs can translate it.
+const MessageAppUpgrade = "app_upgrade"
+
+// AppUpgradeData is the value the app upgrade notice is executed with
+type AppUpgradeData struct {
+	// AppVersion is the version of the wallet's app
+	AppVersion string
+	// MinVersion is the minimum supported version
+	MinVersion string
+}
+
+// langRegex matches a primary language subtag (en, es, fil, ...)
+var langRegex = regexp.MustCompile(`^[a-z]{2,3}$`)
+
+// messageFiles contains one file per language defining all message templates of the language
+//
+//go:embed messages/*.tmpl
+var messageFiles embed.FS
+
+// embeddedMessages holds the parsed embedded templates per language
+var embeddedMessages = mustParseEmbeddedMessages()
+
+func mustParseEmbeddedMessages() map[string]*template.Template {
+	files, err := messageFiles.ReadDir("messages")
+	if err != nil {
+		panic(err)
+	}
+	sets := make(map[string]*template.Template, len(files))
+	for _, file := range files {
+		lang := strings.TrimSuffix(file.Name(), ".tmpl")
+		sets[lang] = template.Must(template.New(lang).Funcs(funcs).Option("missingkey=error").
+			ParseFS(messageFiles, path.Join("messages", file.Name())))
+	}
+	if _, ok := sets[DefaultLang]; !ok {
+		panic("missing " + DefaultLang + " message templates")
+	}
+	return sets
+}
+
+// Messages selects and renders the message templates of a language. The embedded templates can be
+// replaced by language packs and overridden per language and template name, and languages without
+// embedded templates can be added. Templates a language doesn't define fall back to English.
+type Messages struct {
+	mu        sync.RWMutex
+	sets      map[string]*template.Template
+	packDir   string
+	overrides []*models.MessageTemplate
+}
+
+// NewMessages creates the message templates from the embedded files and the language packs in packDir
+// (empty = embedded templates only)
+func NewMessages(packDir string) *Messages {
+	m := &Messages{packDir: packDir}
+	_ = m.Load(nil)
+	return m
+}
+
+// Load replaces the overrides and reloads the language packs. Invalid overrides and packs are skipped and
+// reported in the returned error, the other templates are loaded regardless.
+func (m *Messages) Load(overrides []*models.MessageTemplate) error {
+	sets := make(map[string]*template.Template, len(embeddedMessages))
+	for lang, set := range embeddedMessages {
+		sets[lang] = template.Must(set.Clone())
+	}
+
+	var errs []error
+	if m.packDir != "" {
+		errs = append(errs, loadLanguagePacks(sets, m.packDir)...)
+	}
+	for _, override := range overrides {
+		set, err := withOverride(sets, override.Lang, override.Name, override.Body)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s/%s: %w", override.Lang, override.Name, err))
+			continue
+		}
+		sets[override.Lang] = set
+	}
+
+	m.mu.Lock()
+	m.sets = sets
+	m.overrides = overrides
+	m.mu.Unlock()
+	return errors.Join(errs...)
+}
+
+// ReloadLanguagePacks reloads the language packs, keeping the current overrides
+func (m *Messages) ReloadLanguagePacks() error {
+	m.mu.RLock()
+	overrides := m.overrides
+	m.mu.RUnlock()
+	return m.Load(overrides)
+}
+
+// Render renders the named template in the language, falling back to English. Notification messages are
+// executed with *Data, notices with their own data type (e.g. AppUpgradeData).
+func (m *Messages) Render(lang, name string, data any) (string, error) {
+	m.mu.RLock()
+	set := m.sets[lang]
+	if set == nil || set.Lookup(name) == nil {
+		set = m.sets[DefaultLang]
+	}
+	m.mu.RUnlock()
+
+	var buf strings.Builder
+	if err := set.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Languages returns the languages with templates, sorted
+func (m *Messages) Languages() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	langs := make([]string, 0, len(m.sets))
+	for lang := range m.sets {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Validate returns ErrInvalidMessageTemplate unless the override parses and renders all sample notifications
+func (m *Messages) Validate(lang, name, body string) error {
+	if !langRegex.MatchString(lang) {
+		return fmt.Errorf("%w: language must be a lowercase language code like en", models.ErrInvalidMessageTemplate)
+	}
+	if !IsMessageName(name) {
+		return fmt.Errorf("%w: name must be one of %s", models.ErrInvalidMessageTemplate, strings.Join(MessageNames, ", "))
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("%w: template is empty", models.ErrInvalidMessageTemplate)
+	}
+
+	m.mu.RLock()
+	set, err := withOverride(m.sets, lang, name, body)
+	m.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidMessageTemplate, err)
+	}
+
+	if err := renderSamples(set, name); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrInvalidMessageTemplate, err)
+	}
+	return nil
+}
+
+// renderSamples returns an error unless the named template renders all sample notifications
+func renderSamples(set *template.Template, name string) error {
+	for sample, notification := range SampleNotifications() {
+		var buf strings.Builder
+		if err := set.ExecuteTemplate(&buf, name, NewData(notification)); err != nil {
+			return fmt.Errorf("%s: %w", sample, err)
+		}
+	}
+	return nil
+}
+
+// IsMessageName reports whether the name is a message template that can be overridden
+func IsMessageName(name string) bool {
+	for _, messageName := range MessageNames {
+		if name == messageName {
+			return true
+		}
+	}
+	return false
+}
+
+// withOverride returns a copy of the language's templates with the named template replaced by body.
+// Languages without templates start from the English ones so the shared building blocks are available.
+func withOverride(sets map[string]*template.Template, lang, name, body string) (*template.Template, error) {
+	base := sets[lang]
+	if base == nil {
+		base = sets[DefaultLang]
+	}
+	set, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := set.New(name).Parse(body); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return set, nil
+}
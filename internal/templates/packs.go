@@ -0,0 +1,80 @@
+package templates
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// languagePackExt is the file extension of language packs
+const languagePackExt = ".tmpl"
+
+// loadLanguagePacks parses the <lang>.tmpl files of dir into the templates of their language. Packs use the
+// format of the embedded files and may define only some templates: the others keep the embedded version, and
+// languages without embedded templates start from the English ones. Packs that don't parse or render the
+// sample notifications are skipped and reported, the language keeps its embedded templates.
+func loadLanguagePacks(sets map[string]*template.Template, dir string) []error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return []error{fmt.Errorf("failed to read language packs: %w", err)}
+	}
+
+	var errs []error
+	for _, file := range files {
+		// Hidden entries include the ..data links of Kubernetes ConfigMap volumes
+		lang, ok := strings.CutSuffix(file.Name(), languagePackExt)
+		if !ok || strings.HasPrefix(file.Name(), ".") || file.IsDir() {
+			continue
+		}
+		if !langRegex.MatchString(lang) {
+			errs = append(errs, fmt.Errorf("language pack %s: name must be a lowercase language code like es.tmpl", file.Name()))
+			continue
+		}
+
+		body, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("language pack %s: %w", file.Name(), err))
+			continue
+		}
+		set, err := withPack(sets, lang, string(body))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("language pack %s: %w", file.Name(), err))
+			continue
+		}
+		sets[lang] = set
+	}
+	return errs
+}
+
+// withPack returns a copy of the language's templates with the templates defined by the pack replaced
+func withPack(sets map[string]*template.Template, lang, body string) (*template.Template, error) {
+	base := sets[lang]
+	if base == nil {
+		base = sets[DefaultLang]
+	}
+	set, err := base.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := set.New(lang + languagePackExt).Parse(body); err != nil {
+		return nil, fmt.Errorf("failed to parse templates: %w", err)
+	}
+	for _, name := range MessageNames {
+		if err := renderSamples(set, name); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if err := set.ExecuteTemplate(io.Discard, MessageAppUpgrade, &AppUpgradeData{AppVersion: "1.0.0", MinVersion: "2.0.0"}); err != nil {
+		return nil, fmt.Errorf("%s: %w", MessageAppUpgrade, err)
+	}
+	for sample, notification := range SampleNotifications() {
+		data := &EmailHTMLData{Data: NewData(notification), Lang: lang, Subject: sample, Text: sample}
+		if _, err := renderEmailHTML(data, set, set); err != nil {
+			return nil, fmt.Errorf("email_html: %s: %w", sample, err)
+		}
+	}
+	return set, nil
+}
@@ -0,0 +1,144 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/core-coin/nuntiare/internal/models"
+)
+
+// Delivery channels a template can be rendered for
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelSMS      = "sms"
+	ChannelPush     = "push"
+	ChannelDiscord  = "discord"
+	ChannelMatrix   = "matrix"
+	ChannelNtfy     = "ntfy"
+	ChannelPushover = "pushover"
+	// ChannelWebPush shows the push title and text in browsers, it uses the push template
+	ChannelWebPush = "webpush"
+	// ChannelWebhook receives the notification as JSON, no template is rendered for it
+	ChannelWebhook = "webhook"
+)
+
+// Channels lists all channels in preview order
+var Channels = []string{ChannelTelegram, ChannelEmail, ChannelSMS, ChannelPush, ChannelDiscord, ChannelMatrix, ChannelNtfy, ChannelPushover}
+
+// Data is the value templates are executed with.
+// All Notification fields and methods are available (e.g. {{.Currency}}, {{.FormattedAmount}}, {{.EventType}}).
+type Data struct {
+	*models.Notification
+	// Link is the transaction link to show (a short link for compact channels)
+	Link string
+	// DetailsURL is the hosted detail page URL, empty if unavailable
+	DetailsURL string
+}
+
+// NewData builds template data for a notification using its full explorer link
+func NewData(notification *models.Notification) *Data {
+	return &Data{Notification: notification, Link: notification.TxLink()}
+}
+
+// funcs are the helper functions available in templates
+var funcs = template.FuncMap{
+	"upper":        strings.ToUpper,
+	"lower":        strings.ToLower,
+	"shortAddress": ShortAddress,
+}
+
+// Parse parses a message template. Unknown fields fail at execution time.
+func Parse(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Render executes a parsed template with the given data
+func Render(tmpl *template.Template, data *Data) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// ShortAddress abbreviates an address to its first and last characters
+func ShortAddress(address string) string {
+	if len(address) <= 12 {
+		return address
+	}
+	return address[:6] + "…" + address[len(address)-4:]
+}
+
+// SampleNotifications returns representative notifications used for previews and linting
+func SampleNotifications() map[string]*models.Notification {
+	return map[string]*models.Notification{
+		"xcb": {
+			ID:        "0123456789abcdef0123456789abcdef",
+			Wallet:    "cb57bbbb54cdf60fa666fd741be78f794d4608d67109",
+			From:      "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d",
+			Amount:    12.5,
+			Currency:  "XCB",
+			TxHash:    "0x5b3c2f6c1e8d8f5b9a2e1c4d7f6a3b2c1d0e9f8a7b6c5d4e3f2a1b0c9d8e7f6a",
+			NetworkID: 1,
+			EventType: models.EventIncomingXCB,
+		},
+		"cbc20": {
+			ID:           "1123456789abcdef0123456789abcdef",
+			Wallet:       "cb57bbbb54cdf60fa666fd741be78f794d4608d67109",
+			From:         "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d",
+			Amount:       200,
+			Currency:     "CTN",
+			TokenAddress: "cb19c7acc4c292d2943ba23c2eaa5d9c5a6652a8710c",
+			TokenType:    "CBC20",
+			TxHash:       "0x6c4d3a7d2f9e9a6cab3f2d5e8a7b4c3d2e1fa09b8c7d6e5f4a3b2c1d0e9f8a7b",
+			NetworkID:    1,
+			EventType:    models.EventIncomingCBC20,
+		},
+		"cbc721": {
+			ID:           "2123456789abcdef0123456789abcdef",
+			Wallet:       "cb57bbbb54cdf60fa666fd741be78f794d4608d67109",
+			From:         "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d",
+			Amount:       1,
+			Currency:     "PUNK",
+			TokenAddress: "cb81d3dd8ee1b1ebc9c2b3a5d5d1e5e6a7c8b9a0d1e2",
+			TokenType:    "CBC721",
+			TokenID:      "000000000000000000000000000000000000000000000000000000000000002a",
+			TxHash:       "0x7d5e4b8e3a0fab7dbc4a3e6f9b8c5d4e3f2ab10c9d8e7f6a5b4c3d2e1f0a9b8c",
+			NetworkID:    1,
+			EventType:    models.EventNFTReceived,
+		},
+		"pending": {
+			ID:        "4123456789abcdef0123456789abcdef",
+			Wallet:    "cb57bbbb54cdf60fa666fd741be78f794d4608d67109",
+			From:      "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d",
+			Amount:    12.5,
+			Currency:  "XCB",
+			TxHash:    "0x9f7a6d0a5c2bcd9fde6c5a8b1d0e7f6a5b4cd32e1f0a9b8c7d6e5f4a3b2c1d0e",
+			NetworkID: 1,
+			EventType: models.EventIncomingPending,
+		},
+		"batch": {
+			ID:           "3123456789abcdef0123456789abcdef",
+			Wallet:       "cb57bbbb54cdf60fa666fd741be78f794d4608d67109",
+			From:         "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d",
+			Amount:       100,
+			Currency:     "CTN",
+			TokenAddress: "cb19c7acc4c292d2943ba23c2eaa5d9c5a6652a8710c",
+			TokenType:    "CBC20",
+			TxHash:       "0x8e6f5c9f4b1abc8ecd5b4f7a0c9d6e5f4a3bc21d0e9f8a7b6c5d4e3f2a1b0c9d",
+			NetworkID:    1,
+			EventType:    models.EventIncomingCBC20,
+			Transfers: []models.NotificationTransfer{
+				{From: "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d", Amount: 100, Currency: "CTN", TokenAddress: "cb19c7acc4c292d2943ba23c2eaa5d9c5a6652a8710c", TokenType: "CBC20"},
+				{From: "cb22be9c6f5a5e2d4a8ff2e3a5a5c4d7f45e4a8b7c6d", Amount: 50, Currency: "CTN", TokenAddress: "cb19c7acc4c292d2943ba23c2eaa5d9c5a6652a8710c", TokenType: "CBC20"},
+			},
+		},
+	}
+}
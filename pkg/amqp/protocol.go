@@ -0,0 +1,228 @@
+package amqp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Frame types
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+	frameEnd       = 0xCE
+)
+
+// Classes and methods of the AMQP 0-9-1 spec the client uses
+const (
+	classConnection = 10
+	classChannel    = 20
+	classQueue      = 50
+	classBasic      = 60
+	classConfirm    = 85
+
+	methodConnectionStart   = 10
+	methodConnectionStartOk = 11
+	methodConnectionTune    = 30
+	methodConnectionTuneOk  = 31
+	methodConnectionOpen    = 40
+	methodConnectionOpenOk  = 41
+	methodConnectionClose   = 50
+	methodConnectionCloseOk = 51
+
+	methodChannelOpen    = 10
+	methodChannelOpenOk  = 11
+	methodChannelClose   = 40
+	methodChannelCloseOk = 41
+
+	methodQueueDeclare   = 10
+	methodQueueDeclareOk = 11
+
+	methodBasicQos       = 10
+	methodBasicQosOk     = 11
+	methodBasicConsume   = 20
+	methodBasicConsumeOk = 21
+	methodBasicCancel    = 30
+	methodBasicPublish   = 40
+	methodBasicDeliver   = 60
+	methodBasicAck       = 80
+	methodBasicNack      = 120
+
+	methodConfirmSelect   = 10
+	methodConfirmSelectOk = 11
+)
+
+// Basic property flags of published messages
+const (
+	propertyContentType  = 1 << 15
+	propertyDeliveryMode = 1 << 12
+
+	deliveryModePersistent = 2
+)
+
+// protocolHeader starts every connection, it selects AMQP 0-9-1
+var protocolHeader = []byte("AMQP\x00\x00\x09\x01")
+
+var errShortFrame = errors.New("amqp: truncated frame")
+
+// encoder appends the big-endian AMQP primitives to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) uint8(v uint8)   { e.buf = append(e.buf, v) }
+func (e *encoder) uint16(v uint16) { e.buf = binary.BigEndian.AppendUint16(e.buf, v) }
+func (e *encoder) uint32(v uint32) { e.buf = binary.BigEndian.AppendUint32(e.buf, v) }
+func (e *encoder) uint64(v uint64) { e.buf = binary.BigEndian.AppendUint64(e.buf, v) }
+
+func (e *encoder) shortString(s string) {
+	e.uint8(uint8(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) longString(s string) {
+	e.uint32(uint32(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+// bits packs consecutive bit arguments into one octet, the first one in the lowest bit
+func (e *encoder) bits(values ...bool) {
+	var octet uint8
+	for i, v := range values {
+		if v {
+			octet |= 1 << i
+		}
+	}
+	e.uint8(octet)
+}
+
+// table appends a field table of string values
+func (e *encoder) table(fields map[string]string) {
+	var t encoder
+	for name, value := range fields {
+		t.shortString(name)
+		t.uint8('S')
+		t.longString(value)
+	}
+	e.uint32(uint32(len(t.buf)))
+	e.buf = append(e.buf, t.buf...)
+}
+
+// method returns the payload of a method frame
+func method(classID, methodID uint16, args func(e *encoder)) []byte {
+	var e encoder
+	e.uint16(classID)
+	e.uint16(methodID)
+	if args != nil {
+		args(&e)
+	}
+	return e.buf
+}
+
+// appendFrame appends a frame with the payload to buf
+func appendFrame(buf []byte, frameType uint8, channel uint16, payload []byte) []byte {
+	buf = append(buf, frameType)
+	buf = binary.BigEndian.AppendUint16(buf, channel)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = append(buf, payload...)
+	return append(buf, frameEnd)
+}
+
+// decoder reads the AMQP primitives of a frame. The first read past the end sets err, later reads return
+// zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errShortFrame
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) uint8() uint8 {
+	if b := d.take(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *decoder) uint16() uint16 {
+	if b := d.take(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint32() uint32 {
+	if b := d.take(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *decoder) uint64() uint64 {
+	if b := d.take(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *decoder) shortString() string {
+	return string(d.take(int(d.uint8())))
+}
+
+func (d *decoder) longString() string {
+	return string(d.take(int(d.uint32())))
+}
+
+// skipTable skips a field table, the client doesn't use the tables the broker sends
+func (d *decoder) skipTable() {
+	d.take(int(d.uint32()))
+}
+
+// frame is a frame read from the broker
+type frame struct {
+	frameType uint8
+	channel   uint16
+	payload   []byte
+}
+
+// readFrame reads a frame, frames larger than maxSize are rejected
+func readFrame(reader *bufio.Reader, maxSize int) (*frame, error) {
+	var header [7]byte
+	if _, err := io.ReadFull(reader, header[:]); err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint32(header[3:]))
+	if size > maxSize {
+		return nil, fmt.Errorf("amqp: frame of %d bytes exceeds the maximum of %d bytes", size, maxSize)
+	}
+	payload := make([]byte, size+1)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, err
+	}
+	if payload[size] != frameEnd {
+		return nil, errors.New("amqp: invalid frame end")
+	}
+	return &frame{frameType: header[0], channel: binary.BigEndian.Uint16(header[1:]), payload: payload[:size]}, nil
+}
+
+// closeError returns the error of a Connection.Close or Channel.Close method
+func closeError(d *decoder) error {
+	code := d.uint16()
+	text := d.shortString()
+	return fmt.Errorf("amqp: closed by the broker: %d %s", code, text)
+}
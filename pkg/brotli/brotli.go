@@ -0,0 +1,252 @@
+// Package brotli is a minimal Brotli (RFC 7932) compressor for HTTP responses. It finds LZ77 matches with a hash
+// chain and encodes every meta-block with a single prefix code per alphabet: no context modeling, block
+// switching or static dictionary. Responses come out a few percent smaller than with gzip and larger than with
+// the reference encoder.
+package brotli
+
+const (
+	// windowBits is the size of the sliding window, 4 MB
+	windowBits = 22
+	// maxDistance is the largest backward distance the window allows
+	maxDistance = 1<<windowBits - 16
+	// maxMetaBlockSize is the amount of input compressed with one set of prefix codes
+	maxMetaBlockSize = 1 << 18
+
+	minMatchLength = 4
+	maxMatchLength = 1 << 16
+	hashBits       = 15
+	// maxChainLength limits the candidates compared for each position
+	maxChainLength = 32
+
+	// Alphabet sizes of literals, insert-and-copy lengths and distances (NPOSTFIX and NDIRECT are 0)
+	literalAlphabetSize  = 256
+	commandAlphabetSize  = 704
+	distanceAlphabetSize = 64
+)
+
+// lengthCode is the first length and the number of extra bits of an insert or copy length code
+type lengthCode struct {
+	base      int
+	extraBits uint
+}
+
+var insertLengthCodes = [24]lengthCode{
+	{0, 0}, {1, 0}, {2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 1}, {8, 1},
+	{10, 2}, {14, 2}, {18, 3}, {26, 3}, {34, 4}, {50, 4}, {66, 5}, {98, 5},
+	{130, 6}, {194, 7}, {322, 8}, {578, 9}, {1090, 10}, {2114, 12}, {6210, 14}, {22594, 24},
+}
+
+var copyLengthCodes = [24]lengthCode{
+	{2, 0}, {3, 0}, {4, 0}, {5, 0}, {6, 0}, {7, 0}, {8, 0}, {9, 0},
+	{10, 1}, {12, 1}, {14, 2}, {18, 2}, {22, 3}, {30, 3}, {38, 4}, {54, 4},
+	{70, 5}, {102, 5}, {134, 6}, {198, 7}, {326, 8}, {582, 9}, {1094, 10}, {2118, 24},
+}
+
+// commandCellBases are the first insert-and-copy codes with an explicit distance, by insert length code / 8
+// and copy length code / 8
+var commandCellBases = [3][3]int{
+	{128, 192, 384},
+	{256, 320, 512},
+	{448, 576, 640},
+}
+
+// command inserts literals and then copies bytes from a backward distance. The last command of a meta-block
+// may only insert, its copy length is 0.
+type command struct {
+	literals   int // Position of the first literal
+	insert     int
+	copyLength int
+	distance   int
+}
+
+// Encode compresses data into a Brotli stream
+func Encode(data []byte) []byte {
+	w := &bitWriter{buf: make([]byte, 0, len(data)/3+16)}
+	// WBITS: 1 followed by WBITS - 17 in 3 bits
+	w.write(1, 1)
+	w.write(3, windowBits-17)
+
+	m := newMatcher(data)
+	for start := 0; start < len(data); start += maxMetaBlockSize {
+		end := min(start+maxMetaBlockSize, len(data))
+		writeMetaBlock(w, data, start, end, m.commands(start, end))
+	}
+
+	// ISLAST and ISLASTEMPTY
+	w.write(1, 1)
+	w.write(1, 1)
+	return w.bytes()
+}
+
+// writeMetaBlock writes the compressed, not last meta-block of data[start:end]
+func writeMetaBlock(w *bitWriter, data []byte, start, end int, commands []command) {
+	length := end - start
+	nibbles := 4
+	for nibbles < 6 && length-1 >= 1<<(4*nibbles) {
+		nibbles++
+	}
+	w.write(1, 0) // ISLAST
+	w.write(2, uint64(nibbles-4))
+	w.write(uint(4*nibbles), uint64(length-1))
+	w.write(1, 0) // ISUNCOMPRESSED
+	w.write(3, 0) // NBLTYPESL, NBLTYPESI and NBLTYPESD of 1
+	w.write(2, 0) // NPOSTFIX
+	w.write(4, 0) // NDIRECT
+	w.write(2, 0) // Context mode of the literal block type
+	w.write(2, 0) // NTREESL and NTREESD of 1
+
+	literalHistogram := make([]uint32, literalAlphabetSize)
+	commandHistogram := make([]uint32, commandAlphabetSize)
+	distanceHistogram := make([]uint32, distanceAlphabetSize)
+	for _, cmd := range commands {
+		for _, b := range data[cmd.literals : cmd.literals+cmd.insert] {
+			literalHistogram[b]++
+		}
+		commandHistogram[cmd.code()]++
+		if cmd.copyLength > 0 {
+			code, _, _ := distanceCode(cmd.distance)
+			distanceHistogram[code]++
+		}
+	}
+	literals := w.writePrefixCode(literalHistogram, 8)
+	commandCodes := w.writePrefixCode(commandHistogram, 10)
+	distances := w.writePrefixCode(distanceHistogram, 6)
+
+	for _, cmd := range commands {
+		commandCodes.write(w, cmd.code())
+		insertCode, copyCode := cmd.lengthCodes()
+		w.write(insertLengthCodes[insertCode].extraBits, uint64(cmd.insert-insertLengthCodes[insertCode].base))
+		w.write(copyLengthCodes[copyCode].extraBits, uint64(max(cmd.copyLength, 2)-copyLengthCodes[copyCode].base))
+		for _, b := range data[cmd.literals : cmd.literals+cmd.insert] {
+			literals.write(w, int(b))
+		}
+		// The meta-block ends after the literals of an insert-only command, without a distance
+		if cmd.copyLength > 0 {
+			code, extraBits, extra := distanceCode(cmd.distance)
+			distances.write(w, code)
+			w.write(extraBits, extra)
+		}
+	}
+}
+
+// lengthCodes returns the insert and copy length codes of the command
+func (c command) lengthCodes() (int, int) {
+	insertCode, copyCode := 0, 0
+	for insertCode < len(insertLengthCodes)-1 && insertLengthCodes[insertCode+1].base <= c.insert {
+		insertCode++
+	}
+	for copyCode < len(copyLengthCodes)-1 && copyLengthCodes[copyCode+1].base <= c.copyLength {
+		copyCode++
+	}
+	return insertCode, copyCode
+}
+
+// code returns the insert-and-copy length code of the command, always one with an explicit distance
+func (c command) code() int {
+	insertCode, copyCode := c.lengthCodes()
+	return commandCellBases[insertCode>>3][copyCode>>3] + (insertCode&7)<<3 + copyCode&7
+}
+
+// distanceCode returns the code of a backward distance with its extra bits. The codes below 16, which refer to
+// earlier distances, aren't used.
+func distanceCode(distance int) (int, uint, uint64) {
+	offset := distance + 3
+	extraBits := uint(bitLength(uint64(offset)) - 2)
+	prefix := (offset >> extraBits) & 1
+	return 16 + 2*int(extraBits-1) + prefix, extraBits, uint64(offset - (2+prefix)<<extraBits)
+}
+
+func bitLength(v uint64) int {
+	n := 0
+	for ; v > 0; v >>= 1 {
+		n++
+	}
+	return n
+}
+
+// matcher finds LZ77 matches with hash chains over the whole input
+type matcher struct {
+	data []byte
+	// head is the latest position with the hash plus one, prev the previous one of each position plus one
+	head []int32
+	prev []int32
+	// next is the first position not inserted into the chains yet
+	next int
+}
+
+func newMatcher(data []byte) *matcher {
+	return &matcher{data: data, head: make([]int32, 1<<hashBits), prev: make([]int32, len(data))}
+}
+
+func (m *matcher) hash(pos int) uint32 {
+	d := m.data[pos:]
+	v := uint32(d[0]) | uint32(d[1])<<8 | uint32(d[2])<<16 | uint32(d[3])<<24
+	return (v * 0x1e35a7bd) >> (32 - hashBits)
+}
+
+// insert adds the positions before end to the hash chains
+func (m *matcher) insert(end int) {
+	for ; m.next < end; m.next++ {
+		if m.next+minMatchLength > len(m.data) {
+			continue
+		}
+		h := m.hash(m.next)
+		m.prev[m.next] = m.head[h]
+		m.head[h] = int32(m.next + 1)
+	}
+}
+
+// find returns the longest match of the position that ends before end, or a length of 0
+func (m *matcher) find(pos, end int) (int, int) {
+	limit := min(end-pos, maxMatchLength)
+	if limit < minMatchLength {
+		return 0, 0
+	}
+	m.insert(pos)
+
+	best, bestDistance := 0, 0
+	candidate := int(m.head[m.hash(pos)]) - 1
+	for chain := 0; candidate >= 0 && chain < maxChainLength; chain++ {
+		distance := pos - candidate
+		if distance > maxDistance {
+			break
+		}
+		if m.data[candidate+best] == m.data[pos+best] {
+			length := 0
+			for length < limit && m.data[candidate+length] == m.data[pos+length] {
+				length++
+			}
+			if length > best {
+				best, bestDistance = length, distance
+				if length == limit {
+					break
+				}
+			}
+		}
+		candidate = int(m.prev[candidate]) - 1
+	}
+	if best < minMatchLength {
+		return 0, 0
+	}
+	return best, bestDistance
+}
+
+// commands splits data[start:end] into commands
+func (m *matcher) commands(start, end int) []command {
+	var commands []command
+	literals := start
+	for pos := start; pos < end; {
+		length, distance := m.find(pos, end)
+		if length == 0 {
+			pos++
+			continue
+		}
+		commands = append(commands, command{literals: literals, insert: pos - literals, copyLength: length, distance: distance})
+		pos += length
+		literals = pos
+	}
+	if literals < end {
+		commands = append(commands, command{literals: literals, insert: end - literals})
+	}
+	return commands
+}
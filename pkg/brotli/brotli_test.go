@@ -0,0 +1,106 @@
+package brotli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	reference "github.com/andybalholm/brotli"
+)
+
+// decode decompresses the stream with the reference decoder
+func decode(t *testing.T, encoded []byte) []byte {
+	t.Helper()
+	decoded, err := io.ReadAll(reference.NewReader(bytes.NewReader(encoded)))
+	if err != nil {
+		t.Fatalf("reference decoder: %v", err)
+	}
+	return decoded
+}
+
+func randomBytes(rng *rand.Rand, n int) []byte {
+	data := make([]byte, n)
+	rng.Read(data)
+	return data
+}
+
+// jsonResponse is an API response like the compressed ones: repetitive keys with varying values
+func jsonResponse(rng *rand.Rand, items int) []byte {
+	type transfer struct {
+		Hash   string  `json:"tx_hash"`
+		From   string  `json:"from"`
+		To     string  `json:"to"`
+		Amount float64 `json:"amount"`
+		Symbol string  `json:"symbol"`
+	}
+	transfers := make([]transfer, items)
+	for i := range transfers {
+		transfers[i] = transfer{
+			Hash:   fmt.Sprintf("0x%x", randomBytes(rng, 32)),
+			From:   fmt.Sprintf("cb%x", randomBytes(rng, 21)),
+			To:     "cb57bbbb54cdf60fa666fd741be78f794d4608d67109",
+			Amount: rng.Float64() * 1000,
+			Symbol: []string{"CTN", "XCB", "USDT"}[rng.Intn(3)],
+		}
+	}
+	data, _ := json.Marshal(map[string]any{"success": true, "transfers": transfers})
+	return data
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	allBytes := make([]byte, 256)
+	for i := range allBytes {
+		allBytes[i] = byte(i)
+	}
+	// A random block repeated at the far end of the window, for distances close to maxDistance
+	farBlock := randomBytes(rng, 4096)
+	far := append(append(bytes.Clone(farBlock), randomBytes(rng, maxDistance-len(farBlock)-64)...), farBlock...)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "single byte", data: []byte{'a'}},
+		{name: "shorter than a match", data: []byte("abc")},
+		{name: "all byte values", data: bytes.Repeat(allBytes, 3)},
+		{name: "text", data: []byte("Nuntiare notifies wallets about incoming transfers. Nuntiare notifies wallets.")},
+		{name: "json", data: jsonResponse(rng, 500)},
+		{name: "random", data: randomBytes(rng, 100_000)},
+		{name: "run longer than the longest match", data: bytes.Repeat([]byte{0}, 3*maxMatchLength+7)},
+		{name: "several meta-blocks", data: jsonResponse(rng, 5000)},
+		{name: "meta-block boundary", data: bytes.Repeat([]byte("0123456789abcdef"), maxMetaBlockSize/16)},
+		{name: "far distance", data: far},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			encoded := Encode(test.data)
+			decoded := decode(t, encoded)
+			if !bytes.Equal(decoded, test.data) {
+				t.Fatalf("round trip of %d bytes returned %d different bytes", len(test.data), len(decoded))
+			}
+		})
+	}
+}
+
+func TestEncodeCompresses(t *testing.T) {
+	data := jsonResponse(rand.New(rand.NewSource(2)), 1000)
+	encoded := Encode(data)
+	if len(encoded) >= len(data)/2 {
+		t.Fatalf("encoded %d bytes of JSON into %d bytes, want less than half", len(data), len(encoded))
+	}
+}
+
+func FuzzEncodeRoundTrip(f *testing.F) {
+	f.Add([]byte("hello, hello, hello"))
+	f.Add(bytes.Repeat([]byte{0xff, 0x00}, 1000))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if decoded := decode(t, Encode(data)); !bytes.Equal(decoded, data) {
+			t.Fatalf("round trip of %d bytes returned %d different bytes", len(data), len(decoded))
+		}
+	})
+}
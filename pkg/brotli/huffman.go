@@ -0,0 +1,262 @@
+package brotli
+
+import "sort"
+
+const (
+	// maxCodeLength is the longest prefix code of a symbol
+	maxCodeLength = 15
+	// maxCodeLengthCodeLength is the longest prefix code of a code length symbol
+	maxCodeLengthCodeLength = 5
+
+	// Code length symbols repeating the previous non-zero length 3-6 times and a zero length 3-10 times
+	repeatPreviousLength = 16
+	repeatZeroLength     = 17
+)
+
+// codeLengthCodeOrder is the order the code lengths of the code length symbols are stored in
+var codeLengthCodeOrder = [18]int{1, 2, 3, 4, 0, 5, 17, 6, 16, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// Fixed code the code lengths of the code length symbols are stored with, by length
+var (
+	codeLengthCodeLengthBits  = [6]uint64{0, 7, 3, 2, 1, 15}
+	codeLengthCodeLengthSizes = [6]uint{2, 4, 3, 2, 2, 4}
+)
+
+// bitWriter packs values into bytes starting with the least significant bit
+type bitWriter struct {
+	buf   []byte
+	bits  uint64
+	nbits uint
+}
+
+// write appends the low n bits of the value, n is at most 32
+func (w *bitWriter) write(n uint, value uint64) {
+	w.bits |= value << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits >>= 8
+		w.nbits -= 8
+	}
+}
+
+// bytes returns the written bytes, the last one padded with zero bits
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		w.buf = append(w.buf, byte(w.bits))
+		w.bits, w.nbits = 0, 0
+	}
+	return w.buf
+}
+
+// prefixCode is the code of every symbol of an alphabet, bit-reversed to be written least significant bit first
+type prefixCode struct {
+	lengths []uint8
+	codes   []uint16
+}
+
+func (c *prefixCode) write(w *bitWriter, symbol int) {
+	w.write(uint(c.lengths[symbol]), uint64(c.codes[symbol]))
+}
+
+// writePrefixCode builds the prefix code of the symbol counts and writes its description. An alphabet with at
+// most one used symbol is written as a simple prefix code, whose symbol takes no bits.
+func (w *bitWriter) writePrefixCode(histogram []uint32, alphabetBits uint) *prefixCode {
+	used, symbol := 0, 0
+	for s, count := range histogram {
+		if count > 0 {
+			used++
+			symbol = s
+		}
+	}
+	if used <= 1 {
+		w.write(2, 1) // HSKIP of 1: simple prefix code
+		w.write(2, 0) // NSYM - 1
+		w.write(alphabetBits, uint64(symbol))
+		return &prefixCode{lengths: make([]uint8, len(histogram)), codes: make([]uint16, len(histogram))}
+	}
+
+	code := newPrefixCode(histogram, maxCodeLength)
+	w.writeCodeLengths(code.lengths)
+	return code
+}
+
+// codeLengthSymbol is a code length symbol with the value of its extra bits
+type codeLengthSymbol struct {
+	symbol    int
+	extraBits uint
+	extra     uint64
+}
+
+// writeCodeLengths writes the description of a complex prefix code with at least two used symbols. Runs are
+// stored with the repeat symbols, never two of the same in a row, whose repeat counts would combine.
+func (w *bitWriter) writeCodeLengths(lengths []uint8) {
+	last := len(lengths) - 1
+	for lengths[last] == 0 {
+		last--
+	}
+
+	var symbols []codeLengthSymbol
+	for i := 0; i <= last; {
+		length := lengths[i]
+		run := 1
+		for i+run <= last && lengths[i+run] == length {
+			run++
+		}
+		i += run
+
+		repeat, maxRepeat, extraBits := repeatZeroLength, 10, uint(3)
+		if length != 0 {
+			// The repeat symbol copies the previous non-zero length, which the run stores first
+			symbols = append(symbols, codeLengthSymbol{symbol: int(length)})
+			run--
+			repeat, maxRepeat, extraBits = repeatPreviousLength, 6, 2
+		}
+		for run > 0 {
+			if run < 3 {
+				symbols = append(symbols, codeLengthSymbol{symbol: int(length)})
+				run--
+				continue
+			}
+			count := min(run, maxRepeat)
+			symbols = append(symbols, codeLengthSymbol{symbol: repeat, extraBits: extraBits, extra: uint64(count - 3)})
+			run -= count
+			if run > 0 {
+				symbols = append(symbols, codeLengthSymbol{symbol: int(length)})
+				run--
+			}
+		}
+	}
+
+	histogram := make([]uint32, len(codeLengthCodeOrder))
+	for _, s := range symbols {
+		histogram[s.symbol]++
+	}
+	used := 0
+	for _, count := range histogram {
+		if count > 0 {
+			used++
+		}
+	}
+
+	var code *prefixCode
+	stored := len(codeLengthCodeOrder)
+	if used == 1 {
+		// A single code length symbol takes no bits, all code lengths of the code length symbols are stored
+		code = &prefixCode{lengths: make([]uint8, len(histogram)), codes: make([]uint16, len(histogram))}
+		for s, count := range histogram {
+			if count > 0 {
+				code.lengths[s] = 1
+			}
+		}
+	} else {
+		code = newPrefixCode(histogram, maxCodeLengthCodeLength)
+		// Reading stops once the code is complete, after the last non-zero length
+		for code.lengths[codeLengthCodeOrder[stored-1]] == 0 {
+			stored--
+		}
+	}
+
+	w.write(2, 0) // HSKIP of 0: complex prefix code storing all code lengths
+	for _, s := range codeLengthCodeOrder[:stored] {
+		length := code.lengths[s]
+		w.write(codeLengthCodeLengthSizes[length], codeLengthCodeLengthBits[length])
+	}
+	if used == 1 {
+		code.lengths = make([]uint8, len(histogram))
+	}
+	for _, s := range symbols {
+		code.write(w, s.symbol)
+		w.write(s.extraBits, s.extra)
+	}
+}
+
+// huffmanNode is a symbol or the combination of two nodes
+type huffmanNode struct {
+	count       uint64
+	symbol      int
+	left, right int
+}
+
+// newPrefixCode builds a canonical prefix code with at least two used symbols and codes of at most maxLength
+// bits. When the optimal code is too deep, the rare symbols' counts are raised until it fits.
+func newPrefixCode(histogram []uint32, maxLength uint8) *prefixCode {
+	lengths := make([]uint8, len(histogram))
+	for floor := uint64(1); ; floor *= 2 {
+		var nodes []huffmanNode
+		for symbol, count := range histogram {
+			if count > 0 {
+				nodes = append(nodes, huffmanNode{count: max(uint64(count), floor), symbol: symbol, left: -1, right: -1})
+			}
+		}
+		sort.Slice(nodes, func(i, j int) bool {
+			if nodes[i].count != nodes[j].count {
+				return nodes[i].count < nodes[j].count
+			}
+			return nodes[i].symbol < nodes[j].symbol
+		})
+
+		// Combined nodes are created in increasing count order, so the two smallest nodes are at the head of
+		// either the leaves or the combined nodes
+		leaves := len(nodes)
+		nextLeaf, nextCombined := 0, leaves
+		smallest := func() int {
+			if nextLeaf < leaves && (nextCombined >= len(nodes) || nodes[nextLeaf].count <= nodes[nextCombined].count) {
+				nextLeaf++
+				return nextLeaf - 1
+			}
+			nextCombined++
+			return nextCombined - 1
+		}
+		for len(nodes) < 2*leaves-1 {
+			left := smallest()
+			right := smallest()
+			nodes = append(nodes, huffmanNode{count: nodes[left].count + nodes[right].count, left: left, right: right})
+		}
+
+		depths := make([]uint8, len(nodes))
+		fits := true
+		for i := len(nodes) - 1; i >= leaves; i-- {
+			depths[nodes[i].left] = depths[i] + 1
+			depths[nodes[i].right] = depths[i] + 1
+		}
+		for i := 0; i < leaves; i++ {
+			if depths[i] > maxLength {
+				fits = false
+			}
+			lengths[nodes[i].symbol] = depths[i]
+		}
+		if fits {
+			return &prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+		}
+	}
+}
+
+// canonicalCodes assigns the codes of the lengths in increasing length and symbol order, bit-reversed
+func canonicalCodes(lengths []uint8) []uint16 {
+	var counts, next [maxCodeLength + 1]uint16
+	for _, length := range lengths {
+		if length > 0 {
+			counts[length]++
+		}
+	}
+	code := uint16(0)
+	for length := 1; length <= maxCodeLength; length++ {
+		code = (code + counts[length-1]) << 1
+		next[length] = code
+	}
+
+	codes := make([]uint16, len(lengths))
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		reversed := uint16(0)
+		for i, c := uint8(0), next[length]; i < length; i, c = i+1, c>>1 {
+			reversed = reversed<<1 | c&1
+		}
+		codes[symbol] = reversed
+		next[length]++
+	}
+	return codes
+}
@@ -0,0 +1,121 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SRVPrefix marks a host entry resolved through a DNS SRV record, e.g. "srv:_postgresql._tcp.db.internal"
+	SRVPrefix = "srv:"
+	// SRVSchemePrefix marks a URL entry resolved through a DNS SRV record, e.g. "srv+ws://_rpc._tcp.core.internal/ws"
+	SRVSchemePrefix = "srv+"
+)
+
+// lookupSRV resolves a full SRV record name. net.LookupSRV sorts the targets by priority and randomizes
+// them by weight within a priority.
+var lookupSRV = func(ctx context.Context, name string) ([]*net.SRV, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, "", "", name)
+	return records, err
+}
+
+// ResolveHosts returns the host:port endpoints of a comma-separated list of hosts. Entries are a host,
+// a host:port or an SRV record name prefixed with "srv:". Hosts without a port use the default port.
+// Endpoints are returned in the order they should be tried.
+func ResolveHosts(ctx context.Context, spec string, defaultPort int) ([]string, error) {
+	var endpoints []string
+	for _, entry := range splitList(spec) {
+		if name, ok := strings.CutPrefix(entry, SRVPrefix); ok {
+			records, err := lookupSRV(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve SRV record %s: %w", name, err)
+			}
+			for _, record := range records {
+				endpoints = append(endpoints, srvHostPort(record))
+			}
+			continue
+		}
+		if _, _, err := net.SplitHostPort(entry); err != nil {
+			entry = net.JoinHostPort(entry, strconv.Itoa(defaultPort))
+		}
+		endpoints = append(endpoints, entry)
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no endpoints in %q", spec)
+	}
+	return endpoints, nil
+}
+
+// ResolveURLs returns the URLs of a comma-separated list of URLs. The host of a URL whose scheme is prefixed
+// with "srv+" is an SRV record name, which is replaced by the record's targets. URLs are returned in the
+// order they should be tried.
+func ResolveURLs(ctx context.Context, spec string) ([]string, error) {
+	if err := ValidateURLs(spec); err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, entry := range splitList(spec) {
+		if !strings.HasPrefix(entry, SRVSchemePrefix) {
+			urls = append(urls, entry)
+			continue
+		}
+		parsed, _ := url.Parse(entry)
+		scheme := strings.TrimPrefix(parsed.Scheme, SRVSchemePrefix)
+		records, err := lookupSRV(ctx, parsed.Hostname())
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve SRV record %s: %w", parsed.Hostname(), err)
+		}
+		for _, record := range records {
+			target := *parsed
+			target.Scheme = scheme
+			target.Host = srvHostPort(record)
+			urls = append(urls, target.String())
+		}
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no endpoints in %q", spec)
+	}
+	return urls, nil
+}
+
+// ValidateURLs checks a comma-separated list of URLs without resolving it. Entries other than SRV URLs
+// are passed to the client as they are, so they may also be IPC paths.
+func ValidateURLs(spec string) error {
+	entries := splitList(spec)
+	if len(entries) == 0 {
+		return fmt.Errorf("no endpoints in %q", spec)
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry, SRVSchemePrefix) {
+			continue
+		}
+		parsed, err := url.Parse(entry)
+		if err != nil || parsed.Scheme == SRVSchemePrefix || parsed.Hostname() == "" {
+			return fmt.Errorf("invalid SRV endpoint URL %q", entry)
+		}
+		if parsed.Port() != "" {
+			return fmt.Errorf("invalid SRV endpoint URL %q: the port comes from the SRV record", entry)
+		}
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(spec string) []string {
+	var entries []string
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// srvHostPort returns the host:port of an SRV target
+func srvHostPort(record *net.SRV) string {
+	return net.JoinHostPort(strings.TrimSuffix(record.Target, "."), strconv.Itoa(int(record.Port)))
+}
@@ -0,0 +1,98 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// stubSRV replaces the SRV lookup for the test with fixed records per name, unknown names fail
+func stubSRV(t *testing.T, records map[string][]*net.SRV) {
+	previous := lookupSRV
+	lookupSRV = func(_ context.Context, name string) ([]*net.SRV, error) {
+		if targets, ok := records[name]; ok {
+			return targets, nil
+		}
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	t.Cleanup(func() { lookupSRV = previous })
+}
+
+func TestResolveHosts(t *testing.T) {
+	stubSRV(t, map[string][]*net.SRV{
+		"_postgresql._tcp.db.internal": {
+			{Target: "db-1.internal.", Port: 5433},
+			{Target: "db-2.internal.", Port: 5434},
+		},
+	})
+
+	endpoints, err := ResolveHosts(context.Background(), "primary, srv:_postgresql._tcp.db.internal,replica:6432,", 5432)
+	if err != nil {
+		t.Fatalf("ResolveHosts: %v", err)
+	}
+	want := []string{"primary:5432", "db-1.internal:5433", "db-2.internal:5434", "replica:6432"}
+	if !slices.Equal(endpoints, want) {
+		t.Fatalf("endpoints = %v, want %v", endpoints, want)
+	}
+}
+
+func TestResolveHostsLookupFailure(t *testing.T) {
+	stubSRV(t, nil)
+
+	_, err := ResolveHosts(context.Background(), "srv:_postgresql._tcp.missing.internal", 5432)
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) {
+		t.Fatalf("err = %v, want the wrapped DNS error", err)
+	}
+	if _, err := ResolveHosts(context.Background(), " , ", 5432); err == nil {
+		t.Fatal("ResolveHosts succeeded without endpoints")
+	}
+}
+
+func TestResolveURLs(t *testing.T) {
+	stubSRV(t, map[string][]*net.SRV{
+		"_rpc._tcp.core.internal": {
+			{Target: "node-1.internal.", Port: 8546},
+			{Target: "node-2.internal.", Port: 8547},
+		},
+	})
+
+	urls, err := ResolveURLs(context.Background(), "srv+ws://_rpc._tcp.core.internal/ws,http://fallback:8545,/var/run/gocore.ipc")
+	if err != nil {
+		t.Fatalf("ResolveURLs: %v", err)
+	}
+	want := []string{"ws://node-1.internal:8546/ws", "ws://node-2.internal:8547/ws", "http://fallback:8545", "/var/run/gocore.ipc"}
+	if !slices.Equal(urls, want) {
+		t.Fatalf("urls = %v, want %v", urls, want)
+	}
+
+	if _, err := ResolveURLs(context.Background(), "srv+ws://_rpc._tcp.missing.internal"); err == nil {
+		t.Fatal("ResolveURLs succeeded with an unresolvable SRV record")
+	}
+}
+
+func TestValidateURLs(t *testing.T) {
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "http://localhost:8545"},
+		{spec: "srv+wss://_rpc._tcp.core.internal/ws, http://localhost:8545"},
+		{spec: "", wantErr: "no endpoints"},
+		{spec: "srv+ws://_rpc._tcp.core.internal:8546", wantErr: "the port comes from the SRV record"},
+		{spec: "srv+://_rpc._tcp.core.internal", wantErr: "invalid SRV endpoint URL"},
+		{spec: "srv+ws:///ws", wantErr: "invalid SRV endpoint URL"},
+	}
+	for _, test := range tests {
+		err := ValidateURLs(test.spec)
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("ValidateURLs(%q) = %v, want nil", test.spec, err)
+		case test.wantErr != "" && (err == nil || !strings.Contains(err.Error(), test.wantErr)):
+			t.Errorf("ValidateURLs(%q) = %v, want an error containing %q", test.spec, err, test.wantErr)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package faults
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dependencies faults can be injected into
+const (
+	RPC      = "rpc"
+	DB       = "db"
+	SMTP     = "smtp"
+	Telegram = "telegram"
+)
+
+// ErrInjected is the error returned by injected failures
+var ErrInjected = errors.New("injected fault")
+
+// Fault describes the failures injected into calls to a dependency
+type Fault struct {
+	ErrorRate float64       // Probability in [0, 1] that a call fails
+	Latency   time.Duration // Delay added to every call
+}
+
+// Injector adds latency and errors to dependency calls so retries, fallbacks and alerts
+// can be exercised in development and integration tests. A nil Injector injects nothing.
+type Injector struct {
+	faults map[string]Fault
+}
+
+// NewInjector parses a comma-separated list of target=rate[:latency] entries,
+// e.g. "rpc=0.1:200ms,smtp=1,telegram=0:2s". Returns nil for an empty spec.
+func NewInjector(spec string) (*Injector, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	faults := make(map[string]Fault)
+	for _, entry := range strings.Split(spec, ",") {
+		target, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fault %q, expected target=rate[:latency]", entry)
+		}
+		switch target {
+		case RPC, DB, SMTP, Telegram:
+		default:
+			return nil, fmt.Errorf("unknown fault target %q", target)
+		}
+
+		var fault Fault
+		rate, latency, hasLatency := strings.Cut(value, ":")
+		if rate != "" {
+			r, err := strconv.ParseFloat(rate, 64)
+			if err != nil || r < 0 || r > 1 {
+				return nil, fmt.Errorf("invalid error rate %q for %s, expected a number in [0, 1]", rate, target)
+			}
+			fault.ErrorRate = r
+		}
+		if hasLatency {
+			d, err := time.ParseDuration(latency)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("invalid latency %q for %s", latency, target)
+			}
+			fault.Latency = d
+		}
+		faults[target] = fault
+	}
+
+	return &Injector{faults: faults}, nil
+}
+
+// Inject delays the call by the target's latency and returns an error with the target's error rate
+func (i *Injector) Inject(target string) error {
+	if i == nil {
+		return nil
+	}
+	fault, ok := i.faults[target]
+	if !ok {
+		return nil
+	}
+
+	if fault.Latency > 0 {
+		time.Sleep(fault.Latency)
+	}
+	if fault.ErrorRate > 0 && rand.Float64() < fault.ErrorRate {
+		return fmt.Errorf("%w: %s", ErrInjected, target)
+	}
+	return nil
+}
@@ -0,0 +1,477 @@
+// Package kafka is a minimal Kafka producer: it looks up partition leaders, partitions messages by key the way
+// the Java client does and produces uncompressed record batches, optionally over TLS and with SASL/PLAIN.
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/pkg/discovery"
+)
+
+const (
+	// DefaultPort is used for brokers listed without a port
+	DefaultPort = 9092
+	// DefaultTimeout is used when the config has no timeout
+	DefaultTimeout = 10 * time.Second
+)
+
+// Config configures a Producer
+type Config struct {
+	// Brokers is a comma-separated list of bootstrap brokers (host, host:port or srv: record names)
+	Brokers  string
+	ClientID string
+	TLS      bool
+	// Username and Password authenticate with SASL/PLAIN when Username is set
+	Username string
+	Password string
+	// Timeout bounds connecting, every request and the time the brokers wait for the replicas
+	Timeout time.Duration
+}
+
+// Message is a record to produce
+type Message struct {
+	Topic string
+	// Key selects the partition, messages with the same key keep their order. Nil keys are spread round-robin.
+	Key   []byte
+	Value []byte
+	// Time is the create time of the record (zero = now)
+	Time time.Time
+}
+
+// ProduceError is returned by Produce when only some of the messages were acknowledged
+type ProduceError struct {
+	// Failed are the messages of the partitions that failed, in the order they were passed to Produce
+	Failed []*Message
+	Err    error
+}
+
+func (e *ProduceError) Error() string {
+	return fmt.Sprintf("failed to produce %d messages: %v", len(e.Failed), e.Err)
+}
+
+func (e *ProduceError) Unwrap() error {
+	return e.Err
+}
+
+// topicPartition identifies a partition of a topic
+type topicPartition struct {
+	topic string
+	id    int32
+}
+
+type partition struct {
+	id     int32
+	leader int32
+}
+
+// Producer produces messages to a Kafka cluster with acks from all in-sync replicas. It is safe for
+// concurrent use, requests are sent one at a time.
+type Producer struct {
+	config Config
+
+	mu            sync.Mutex
+	correlationID int32
+	roundRobin    uint32
+	brokers       map[int32]string // Node ID -> host:port
+	conns         map[int32]net.Conn
+	topics        map[string][]partition
+}
+
+// NewProducer returns a producer for the cluster, it connects on the first Produce
+func NewProducer(config Config) *Producer {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Producer{
+		config:  config,
+		brokers: make(map[int32]string),
+		conns:   make(map[int32]net.Conn),
+		topics:  make(map[string][]partition),
+	}
+}
+
+// Produce writes the messages and returns once all of them were acknowledged. When the request of a leader
+// fails or a partition returns an error, the other partitions are still written and a *ProduceError lists
+// the messages to produce again. Other errors fail all messages.
+func (p *Producer) Produce(ctx context.Context, messages []*Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var missing []string
+	for _, message := range messages {
+		if _, ok := p.topics[message.Topic]; !ok && !slices.Contains(missing, message.Topic) {
+			missing = append(missing, message.Topic)
+		}
+	}
+	if len(missing) > 0 {
+		if err := p.refreshMetadata(ctx, missing); err != nil {
+			return err
+		}
+	}
+
+	// Leader -> topic -> partition -> messages
+	batches := make(map[int32]map[string]map[int32][]*Message)
+	for _, message := range messages {
+		partitions := p.topics[message.Topic]
+		if len(partitions) == 0 {
+			return fmt.Errorf("kafka: topic %s has no partitions", message.Topic)
+		}
+		var selected partition
+		if message.Key != nil {
+			selected = partitions[int(murmur2(message.Key)&0x7fffffff)%len(partitions)]
+		} else {
+			selected = partitions[int(p.roundRobin%uint32(len(partitions)))]
+			p.roundRobin++
+		}
+		if batches[selected.leader] == nil {
+			batches[selected.leader] = make(map[string]map[int32][]*Message)
+		}
+		if batches[selected.leader][message.Topic] == nil {
+			batches[selected.leader][message.Topic] = make(map[int32][]*Message)
+		}
+		batches[selected.leader][message.Topic][selected.id] = append(batches[selected.leader][message.Topic][selected.id], message)
+	}
+
+	failed := make(map[*Message]bool)
+	var firstErr error
+	for leader, topics := range batches {
+		errs, err := p.produce(ctx, leader, topics)
+		if err != nil {
+			p.closeConn(leader)
+		}
+		for topic, partitions := range topics {
+			for id, partitionMessages := range partitions {
+				partitionErr := err
+				if partitionErr == nil {
+					partitionErr = errs[topicPartition{topic, id}]
+				}
+				if partitionErr == nil {
+					continue
+				}
+				// The leader may have moved, it is looked up again before the next attempt
+				delete(p.topics, topic)
+				if firstErr == nil {
+					firstErr = partitionErr
+				}
+				for _, message := range partitionMessages {
+					failed[message] = true
+				}
+			}
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	produceErr := &ProduceError{Err: firstErr}
+	for _, message := range messages {
+		if failed[message] {
+			produceErr.Failed = append(produceErr.Failed, message)
+		}
+	}
+	return produceErr
+}
+
+// Close closes the broker connections
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for id := range p.conns {
+		p.closeConn(id)
+	}
+	return nil
+}
+
+// produce sends the batches of a leader in one request and returns the errors of the partitions that weren't
+// written. An error means the request failed and none of the partitions were written.
+func (p *Producer) produce(ctx context.Context, leader int32, topics map[string]map[int32][]*Message) (map[topicPartition]error, error) {
+	var body encoder
+	body.nullableString(nil) // Transactional ID
+	body.int16(-1)           // Acks from all in-sync replicas
+	body.int32(int32(p.config.Timeout.Milliseconds()))
+	body.int32(int32(len(topics)))
+	for topic, partitions := range topics {
+		body.string(topic)
+		body.int32(int32(len(partitions)))
+		for id, messages := range partitions {
+			for _, message := range messages {
+				message.Time = timestampOrNow(message.Time)
+			}
+			body.int32(id)
+			body.bytes(recordBatch(messages))
+		}
+	}
+
+	response, err := p.request(ctx, leader, apiProduce, produceVersion, body.buf)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{buf: response}
+	errs := make(map[topicPartition]error)
+	acknowledged := make(map[topicPartition]bool)
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		topic := d.string()
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			id := d.int32()
+			code := Error(d.int16())
+			d.int64() // Base offset
+			d.int64() // Log append time
+			if code != 0 {
+				errs[topicPartition{topic, id}] = fmt.Errorf("failed to produce to %s/%d: %w", topic, id, code)
+			} else {
+				acknowledged[topicPartition{topic, id}] = true
+			}
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	for topic, partitions := range topics {
+		for id := range partitions {
+			key := topicPartition{topic, id}
+			if !acknowledged[key] && errs[key] == nil {
+				errs[key] = fmt.Errorf("failed to produce to %s/%d: %w", topic, id, errShortResponse)
+			}
+		}
+	}
+	return errs, nil
+}
+
+// refreshMetadata looks up the brokers and the partition leaders of the topics. Brokers auto-create
+// missing topics if configured to.
+func (p *Producer) refreshMetadata(ctx context.Context, topics []string) error {
+	var body encoder
+	body.int32(int32(len(topics)))
+	for _, topic := range topics {
+		body.string(topic)
+	}
+
+	response, err := p.bootstrapRequest(ctx, apiMetadata, metadataVersion, body.buf)
+	if err != nil {
+		return err
+	}
+	d := decoder{buf: response}
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		if rack := d.int16(); rack > 0 {
+			d.take(int(rack))
+		}
+		if addr := net.JoinHostPort(host, strconv.Itoa(int(port))); p.brokers[id] != addr {
+			p.closeConn(id)
+			p.brokers[id] = addr
+		}
+	}
+	d.int32() // Controller ID
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		code := Error(d.int16())
+		topic := d.string()
+		d.int8() // Internal
+		var partitions []partition
+		for j, m := 0, d.arrayLen(); j < m; j++ {
+			partitionCode := Error(d.int16())
+			id := d.int32()
+			leader := d.int32()
+			for k, replicas := 0, d.arrayLen(); k < replicas; k++ {
+				d.int32()
+			}
+			for k, isr := 0, d.arrayLen(); k < isr; k++ {
+				d.int32()
+			}
+			if partitionCode == 0 && leader >= 0 {
+				partitions = append(partitions, partition{id: id, leader: leader})
+			}
+		}
+		if d.err != nil {
+			return d.err
+		}
+		if code != 0 {
+			return fmt.Errorf("failed to get metadata of topic %s: %w", topic, code)
+		}
+		if len(partitions) == 0 {
+			return fmt.Errorf("failed to get metadata of topic %s: %w", topic, Error(5))
+		}
+		p.topics[topic] = partitions
+	}
+	for _, topic := range topics {
+		if _, ok := p.topics[topic]; !ok {
+			return fmt.Errorf("failed to get metadata of topic %s: %w", topic, Error(3))
+		}
+	}
+	return d.err
+}
+
+// bootstrapRequest sends a request to any reachable broker, the known ones first and then the bootstrap list
+func (p *Producer) bootstrapRequest(ctx context.Context, apiKey, version int16, body []byte) ([]byte, error) {
+	var lastErr error
+	for id := range p.brokers {
+		response, err := p.request(ctx, id, apiKey, version, body)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+		p.closeConn(id)
+	}
+
+	endpoints, err := discovery.ResolveHosts(ctx, p.config.Brokers, DefaultPort)
+	if err != nil {
+		return nil, err
+	}
+	// Bootstrap connections get negative IDs so they don't clash with the node IDs from the metadata
+	for i, endpoint := range endpoints {
+		id := int32(-1 - i)
+		p.brokers[id] = endpoint
+		response, err := p.request(ctx, id, apiKey, version, body)
+		p.closeConn(id)
+		delete(p.brokers, id)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("kafka: no brokers configured")
+	}
+	return nil, fmt.Errorf("failed to reach a Kafka broker: %w", lastErr)
+}
+
+// request sends a request to the broker over its connection, connecting first if needed
+func (p *Producer) request(ctx context.Context, broker int32, apiKey, version int16, body []byte) ([]byte, error) {
+	conn, err := p.conn(ctx, broker)
+	if err != nil {
+		return nil, err
+	}
+	response, err := p.roundTrip(ctx, conn, apiKey, version, body)
+	if err != nil {
+		p.closeConn(broker)
+		return nil, err
+	}
+	return response, nil
+}
+
+// roundTrip writes a request and reads its response body
+func (p *Producer) roundTrip(ctx context.Context, conn net.Conn, apiKey, version int16, body []byte) ([]byte, error) {
+	deadline := time.Now().Add(p.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	p.correlationID++
+	correlationID := p.correlationID
+	var request encoder
+	request.int32(0) // Size, set below
+	request.int16(apiKey)
+	request.int16(version)
+	request.int32(correlationID)
+	request.string(p.config.ClientID)
+	request.buf = append(request.buf, body...)
+	binary.BigEndian.PutUint32(request.buf, uint32(len(request.buf)-4))
+	if _, err := conn.Write(request.buf); err != nil {
+		return nil, fmt.Errorf("failed to send Kafka request: %w", err)
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(conn, size[:]); err != nil {
+		return nil, fmt.Errorf("failed to read Kafka response: %w", err)
+	}
+	response := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, fmt.Errorf("failed to read Kafka response: %w", err)
+	}
+	d := decoder{buf: response}
+	if id := d.int32(); d.err != nil || id != correlationID {
+		return nil, fmt.Errorf("kafka: response %d doesn't match request %d", id, correlationID)
+	}
+	return d.buf, nil
+}
+
+// conn returns the connection to the broker, connecting and authenticating if there is none
+func (p *Producer) conn(ctx context.Context, broker int32) (net.Conn, error) {
+	if conn, ok := p.conns[broker]; ok {
+		return conn, nil
+	}
+	addr, ok := p.brokers[broker]
+	if !ok {
+		return nil, fmt.Errorf("kafka: unknown broker %d", broker)
+	}
+
+	dialer := &net.Dialer{Timeout: p.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Kafka broker %s: %w", addr, err)
+	}
+	if p.config.TLS {
+		host, _, _ := net.SplitHostPort(addr)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect to Kafka broker %s: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+	if p.config.Username != "" {
+		if err := p.authenticate(ctx, conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to authenticate with Kafka broker %s: %w", addr, err)
+		}
+	}
+	p.conns[broker] = conn
+	return conn, nil
+}
+
+// authenticate authenticates the connection with SASL/PLAIN
+func (p *Producer) authenticate(ctx context.Context, conn net.Conn) error {
+	var handshake encoder
+	handshake.string("PLAIN")
+	response, err := p.roundTrip(ctx, conn, apiSaslHandshake, saslHandshakeVersion, handshake.buf)
+	if err != nil {
+		return err
+	}
+	d := decoder{buf: response}
+	if code := Error(d.int16()); code != 0 {
+		return code
+	}
+
+	var authenticate encoder
+	authenticate.bytes([]byte("\x00" + p.config.Username + "\x00" + p.config.Password))
+	response, err = p.roundTrip(ctx, conn, apiSaslAuthenticate, saslAuthenticateVersion, authenticate.buf)
+	if err != nil {
+		return err
+	}
+	d = decoder{buf: response}
+	code := Error(d.int16())
+	message := d.string()
+	if d.err != nil {
+		return d.err
+	}
+	if code != 0 {
+		if message != "" {
+			return fmt.Errorf("%w: %s", code, message)
+		}
+		return code
+	}
+	return nil
+}
+
+func (p *Producer) closeConn(broker int32) {
+	if conn, ok := p.conns[broker]; ok {
+		conn.Close()
+		delete(p.conns, broker)
+	}
+}
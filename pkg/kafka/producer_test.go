@@ -0,0 +1,387 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testTopic = "events"
+
+// testBroker is a single-node cluster that leads all partitions of testTopic. It checks the requests the way
+// a broker does and records the produced values per partition.
+type testBroker struct {
+	t          *testing.T
+	listener   net.Listener
+	partitions int32
+	username   string
+	password   string
+
+	mu sync.Mutex
+	// produceErrors are the error codes the next produce requests return per partition
+	produceErrors map[int32][]int16
+	produced      map[int32][]string
+	metadata      int
+}
+
+// newTestBroker starts a broker, it requires SASL/PLAIN authentication when username is set
+func newTestBroker(t *testing.T, partitions int32, username, password string) *testBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &testBroker{
+		t:             t,
+		listener:      listener,
+		partitions:    partitions,
+		username:      username,
+		password:      password,
+		produceErrors: make(map[int32][]int16),
+		produced:      make(map[int32][]string),
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(conn)
+		}
+	}()
+	return b
+}
+
+// partition returns the values produced to a partition
+func (b *testBroker) partition(id int32) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return slices.Clone(b.produced[id])
+}
+
+func (b *testBroker) addr() string {
+	return b.listener.Addr().String()
+}
+
+func (b *testBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	authenticated := b.username == ""
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		request := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, request); err != nil {
+			return
+		}
+		d := decoder{buf: request}
+		apiKey := d.int16()
+		version := d.int16()
+		correlationID := d.int32()
+		d.string() // Client ID
+		if d.err != nil {
+			b.t.Errorf("truncated request header")
+			return
+		}
+
+		var response encoder
+		response.int32(correlationID)
+		switch {
+		case apiKey == apiSaslHandshake && version == saslHandshakeVersion:
+			if mechanism := d.string(); mechanism != "PLAIN" {
+				b.t.Errorf("SASL mechanism = %q, want PLAIN", mechanism)
+			}
+			response.int16(0)
+			response.int32(1)
+			response.string("PLAIN")
+		case apiKey == apiSaslAuthenticate && version == saslAuthenticateVersion:
+			n := d.int32()
+			token := string(d.take(int(n)))
+			if token == "\x00"+b.username+"\x00"+b.password {
+				authenticated = true
+				response.int16(0)
+				response.nullableString(nil)
+			} else {
+				message := "Authentication failed: Invalid username or password"
+				response.int16(58)
+				response.nullableString(&message)
+			}
+			response.bytes(nil)
+		case !authenticated:
+			b.t.Errorf("request %d before authentication", apiKey)
+			return
+		case apiKey == apiMetadata && version == metadataVersion:
+			b.metadataResponse(&d, &response)
+		case apiKey == apiProduce && version == produceVersion:
+			b.produceResponse(&d, &response)
+		default:
+			b.t.Errorf("unexpected request %d v%d", apiKey, version)
+			return
+		}
+		if d.err != nil {
+			b.t.Errorf("truncated request %d: %v", apiKey, d.err)
+			return
+		}
+
+		frame := binary.BigEndian.AppendUint32(nil, uint32(len(response.buf)))
+		if _, err := conn.Write(append(frame, response.buf...)); err != nil {
+			return
+		}
+	}
+}
+
+func (b *testBroker) metadataResponse(d *decoder, response *encoder) {
+	topics := make([]string, d.arrayLen())
+	for i := range topics {
+		topics[i] = d.string()
+	}
+	b.mu.Lock()
+	b.metadata++
+	b.mu.Unlock()
+
+	host, port, _ := net.SplitHostPort(b.addr())
+	portNumber, _ := strconv.Atoi(port)
+	response.int32(1) // Brokers
+	response.int32(0)
+	response.string(host)
+	response.int32(int32(portNumber))
+	response.int16(-1) // Rack
+	response.int32(0)  // Controller ID
+	response.int32(int32(len(topics)))
+	for _, topic := range topics {
+		if topic != testTopic {
+			response.int16(3)
+			response.string(topic)
+			response.int8(0)
+			response.int32(0)
+			continue
+		}
+		response.int16(0)
+		response.string(topic)
+		response.int8(0)
+		response.int32(b.partitions)
+		for id := int32(0); id < b.partitions; id++ {
+			response.int16(0)
+			response.int32(id)
+			response.int32(0) // Leader
+			response.int32(1) // Replicas
+			response.int32(0)
+			response.int32(1) // ISR
+			response.int32(0)
+		}
+	}
+}
+
+func (b *testBroker) produceResponse(d *decoder, response *encoder) {
+	d.string() // Transactional ID
+	if acks := d.int16(); acks != -1 {
+		b.t.Errorf("acks = %d, want -1", acks)
+	}
+	d.int32() // Timeout
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := d.arrayLen()
+	response.int32(int32(n))
+	for i := 0; i < n; i++ {
+		topic := d.string()
+		response.string(topic)
+		m := d.arrayLen()
+		response.int32(int32(m))
+		for j := 0; j < m; j++ {
+			id := d.int32()
+			size := d.int32()
+			batch := d.take(int(size))
+			code := int16(0)
+			if codes := b.produceErrors[id]; len(codes) > 0 {
+				code = codes[0]
+				b.produceErrors[id] = codes[1:]
+			}
+			if code == 0 {
+				values, err := readRecordBatch(batch)
+				if err != nil {
+					b.t.Errorf("partition %d: %v", id, err)
+					code = 2
+				}
+				b.produced[id] = append(b.produced[id], values...)
+			}
+			response.int32(id)
+			response.int16(code)
+			response.int64(int64(len(b.produced[id]))) // Base offset
+			response.int64(-1)                         // Log append time
+		}
+	}
+	response.int32(0) // Throttle time
+}
+
+// readRecordBatch checks a v2 record batch and returns the values of its records
+func readRecordBatch(batch []byte) ([]string, error) {
+	if len(batch) < 61 {
+		return nil, errors.New("batch too short")
+	}
+	if length := binary.BigEndian.Uint32(batch[8:]); int(length) != len(batch)-12 {
+		return nil, errors.New("wrong batch length")
+	}
+	if magic := batch[16]; magic != 2 {
+		return nil, errors.New("wrong magic")
+	}
+	if crc := binary.BigEndian.Uint32(batch[17:]); crc != crc32.Checksum(batch[21:], crc32.MakeTable(crc32.Castagnoli)) {
+		return nil, errors.New("wrong CRC")
+	}
+	lastOffsetDelta := int64(binary.BigEndian.Uint32(batch[23:]))
+	count := int(binary.BigEndian.Uint32(batch[57:]))
+	records := batch[61:]
+	varint := func() int64 {
+		v, n := binary.Varint(records)
+		if n <= 0 {
+			return -2
+		}
+		records = records[n:]
+		return v
+	}
+	var values []string
+	for i := 0; i < count; i++ {
+		length := varint()
+		if length < 0 || int(length) > len(records) {
+			return nil, errors.New("wrong record length")
+		}
+		end := len(records) - int(length)
+		records = records[1:] // Attributes
+		varint()              // Timestamp delta
+		if offsetDelta := varint(); offsetDelta != int64(i) {
+			return nil, errors.New("wrong offset delta")
+		}
+		if key := varint(); key > 0 {
+			records = records[key:]
+		}
+		value := varint()
+		values = append(values, string(records[:value]))
+		records = records[value:]
+		varint() // Headers
+		if len(records) != end {
+			return nil, errors.New("record length doesn't match its fields")
+		}
+	}
+	if len(records) != 0 || lastOffsetDelta != int64(count-1) {
+		return nil, errors.New("wrong record count")
+	}
+	return values, nil
+}
+
+func testMessages(keys ...string) []*Message {
+	messages := make([]*Message, len(keys))
+	for i, key := range keys {
+		messages[i] = &Message{Topic: testTopic, Key: []byte(key), Value: []byte(key), Time: time.Now()}
+	}
+	return messages
+}
+
+// partitionOf is the partition of the Java client's default partitioner
+func partitionOf(key string, partitions int32) int32 {
+	return (murmur2([]byte(key)) & 0x7fffffff) % partitions
+}
+
+func TestProduce(t *testing.T) {
+	broker := newTestBroker(t, 3, "nuntiare", "secret")
+	producer := NewProducer(Config{Brokers: broker.addr(), Username: "nuntiare", Password: "secret", Timeout: 5 * time.Second})
+	defer producer.Close()
+
+	keys := []string{"21", "foobar", "abc", "a-little-bit-long-string", "foobar"}
+	if err := producer.Produce(context.Background(), testMessages(keys...)); err != nil {
+		t.Fatal(err)
+	}
+	want := make(map[int32][]string)
+	for _, key := range keys {
+		id := partitionOf(key, 3)
+		want[id] = append(want[id], key)
+	}
+	for id := int32(0); id < 3; id++ {
+		if produced := broker.partition(id); !slices.Equal(produced, want[id]) {
+			t.Errorf("partition %d = %q, want %q", id, produced, want[id])
+		}
+	}
+}
+
+func TestProduceRetriesFailedPartitions(t *testing.T) {
+	broker := newTestBroker(t, 3, "", "")
+	producer := NewProducer(Config{Brokers: broker.addr(), Timeout: 5 * time.Second})
+	defer producer.Close()
+
+	keys := []string{"21", "foobar", "abc", "a-little-bit-long-string", "lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8"}
+	failedPartition := partitionOf("foobar", 3)
+	broker.mu.Lock()
+	broker.produceErrors[failedPartition] = []int16{6}
+	broker.mu.Unlock()
+	messages := testMessages(keys...)
+
+	err := producer.Produce(context.Background(), messages)
+	var produceErr *ProduceError
+	if !errors.As(err, &produceErr) {
+		t.Fatalf("Produce() = %v, want a *ProduceError", err)
+	}
+	if !errors.Is(err, Error(6)) {
+		t.Errorf("Produce() = %v, want %v", err, Error(6))
+	}
+	var wantFailed []*Message
+	for _, message := range messages {
+		if partitionOf(string(message.Key), 3) == failedPartition {
+			wantFailed = append(wantFailed, message)
+		}
+	}
+	if !slices.Equal(produceErr.Failed, wantFailed) {
+		t.Fatalf("failed messages = %d, want the %d messages of partition %d", len(produceErr.Failed), len(wantFailed), failedPartition)
+	}
+
+	if err := producer.Produce(context.Background(), produceErr.Failed); err != nil {
+		t.Fatal(err)
+	}
+	// Every message was written once and the leaders were looked up again after the failure
+	var produced []string
+	for id := int32(0); id < 3; id++ {
+		produced = append(produced, broker.partition(id)...)
+	}
+	slices.Sort(produced)
+	slices.Sort(keys)
+	if !slices.Equal(produced, keys) {
+		t.Errorf("produced %q, want %q", produced, keys)
+	}
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+	if broker.metadata != 2 {
+		t.Errorf("metadata requests = %d, want 2", broker.metadata)
+	}
+}
+
+func TestProduceAuthenticationFailed(t *testing.T) {
+	broker := newTestBroker(t, 1, "nuntiare", "secret")
+	producer := NewProducer(Config{Brokers: broker.addr(), Username: "nuntiare", Password: "wrong", Timeout: 5 * time.Second})
+	defer producer.Close()
+
+	err := producer.Produce(context.Background(), testMessages("key"))
+	if !errors.Is(err, Error(58)) {
+		t.Errorf("Produce() = %v, want %v", err, Error(58))
+	}
+	if _, ok := err.(*ProduceError); ok {
+		t.Errorf("Produce() = %T, want all messages to fail", err)
+	}
+}
+
+func TestProduceUnknownTopic(t *testing.T) {
+	broker := newTestBroker(t, 1, "", "")
+	producer := NewProducer(Config{Brokers: broker.addr(), Timeout: 5 * time.Second})
+	defer producer.Close()
+
+	err := producer.Produce(context.Background(), []*Message{{Topic: "missing", Value: []byte("v")}})
+	if !errors.Is(err, Error(3)) {
+		t.Errorf("Produce() = %v, want %v", err, Error(3))
+	}
+}
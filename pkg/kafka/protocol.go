@@ -0,0 +1,257 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// API keys and versions of the requests the producer sends. The versions are the oldest ones Kafka 4 still
+// accepts, Produce v3 is the first with the v2 record batch format.
+const (
+	apiProduce          = 0
+	apiMetadata         = 3
+	apiSaslHandshake    = 17
+	apiSaslAuthenticate = 36
+
+	produceVersion          = 3
+	metadataVersion         = 1
+	saslHandshakeVersion    = 1
+	saslAuthenticateVersion = 0
+)
+
+var errShortResponse = errors.New("kafka: truncated response")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// encoder appends the big-endian Kafka protocol primitives to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8)   { e.buf = append(e.buf, byte(v)) }
+func (e *encoder) int16(v int16) { e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v)) }
+func (e *encoder) int32(v int32) { e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v)) }
+func (e *encoder) int64(v int64) { e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v)) }
+func (e *encoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) nullableString(s *string) {
+	if s == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*s)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// varbytes appends record keys and values, nil is encoded as null
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.buf = append(e.buf, b...)
+}
+
+// decoder reads the Kafka protocol primitives of a response. The first read past the end sets err,
+// later reads return zero values.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) take(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = errShortResponse
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.take(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.take(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.take(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.take(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.take(int(n)))
+}
+
+// arrayLen returns the length of an array, null arrays are empty
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 || d.err != nil {
+		return 0
+	}
+	// Every element takes at least one byte, a larger length is a corrupt response
+	if int(n) > len(d.buf) {
+		d.err = errShortResponse
+		return 0
+	}
+	return int(n)
+}
+
+// recordBatch encodes messages as a v2 record batch without compression
+func recordBatch(messages []*Message) []byte {
+	first := messages[0].Time.UnixMilli()
+	maxTimestamp := first
+	var records encoder
+	for i, message := range messages {
+		timestamp := message.Time.UnixMilli()
+		maxTimestamp = max(maxTimestamp, timestamp)
+
+		var record encoder
+		record.int8(0) // Attributes
+		record.varint(timestamp - first)
+		record.varint(int64(i)) // Offset delta
+		record.varbytes(message.Key)
+		record.varbytes(message.Value)
+		record.varint(0) // Headers
+		records.varint(int64(len(record.buf)))
+		records.buf = append(records.buf, record.buf...)
+	}
+
+	// The CRC covers everything from the attributes to the end of the batch
+	var crcd encoder
+	crcd.int16(0) // Attributes: no compression, create time
+	crcd.int32(int32(len(messages) - 1))
+	crcd.int64(first)
+	crcd.int64(maxTimestamp)
+	crcd.int64(-1) // Producer ID, not idempotent
+	crcd.int16(-1) // Producer epoch
+	crcd.int32(-1) // Base sequence
+	crcd.int32(int32(len(messages)))
+	crcd.buf = append(crcd.buf, records.buf...)
+
+	var batch encoder
+	batch.int64(0)                                // Base offset, assigned by the broker
+	batch.int32(int32(4 + 1 + 4 + len(crcd.buf))) // Length after this field
+	batch.int32(-1)                               // Partition leader epoch
+	batch.int8(2)                                 // Magic
+	batch.buf = binary.BigEndian.AppendUint32(batch.buf, crc32.Checksum(crcd.buf, castagnoli))
+	batch.buf = append(batch.buf, crcd.buf...)
+	return batch.buf
+}
+
+// murmur2 is the hash the Java client's default partitioner uses for keys, so messages with the same key
+// land in the same partition regardless of the producer
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// Error is an error code returned by a broker
+type Error int16
+
+func (e Error) Error() string {
+	switch e {
+	case 2:
+		return "kafka: corrupt message"
+	case 3:
+		return "kafka: unknown topic or partition"
+	case 5:
+		return "kafka: leader not available"
+	case 6:
+		return "kafka: not leader for partition"
+	case 7:
+		return "kafka: request timed out"
+	case 10:
+		return "kafka: message too large"
+	case 17:
+		return "kafka: invalid topic"
+	case 19, 20:
+		return "kafka: not enough replicas"
+	case 29:
+		return "kafka: topic authorization failed"
+	case 33:
+		return "kafka: unsupported SASL mechanism"
+	case 35:
+		return "kafka: unsupported version"
+	case 58:
+		return "kafka: SASL authentication failed"
+	}
+	return fmt.Sprintf("kafka: broker error %d", int16(e))
+}
+
+// timestampOrNow returns the message time, messages without one are stamped when they are produced
+func timestampOrNow(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
+}
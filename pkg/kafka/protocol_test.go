@@ -0,0 +1,95 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/hex"
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+// The hashes of the Java client's Utils.murmur2 (UtilsTest.testMurmur2)
+func TestMurmur2(t *testing.T) {
+	tests := []struct {
+		key  string
+		hash int32
+	}{
+		{"21", -973932308},
+		{"foobar", -790332482},
+		{"a-little-bit-long-string", -985981536},
+		{"a-little-bit-longer-string", -1486304829},
+		{"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8", -58897971},
+		{"abc", 479470107},
+	}
+	for _, test := range tests {
+		if hash := murmur2([]byte(test.key)); hash != test.hash {
+			t.Errorf("murmur2(%q) = %d, want %d", test.key, hash, test.hash)
+		}
+	}
+}
+
+func TestCastagnoli(t *testing.T) {
+	if crc := crc32.Checksum([]byte("123456789"), castagnoli); crc != 0xe3069283 {
+		t.Errorf("crc32c = %#x, want 0xe3069283", crc)
+	}
+}
+
+func TestVarint(t *testing.T) {
+	tests := []struct {
+		value   int64
+		encoded string
+	}{
+		{0, "00"},
+		{-1, "01"},
+		{1, "02"},
+		{-65, "8101"},
+		{300, "d804"},
+		{1 << 40, "808080808040"},
+	}
+	for _, test := range tests {
+		var e encoder
+		e.varint(test.value)
+		if encoded := hex.EncodeToString(e.buf); encoded != test.encoded {
+			t.Errorf("varint(%d) = %s, want %s", test.value, encoded, test.encoded)
+		}
+	}
+}
+
+// The batch was encoded following the v2 record batch specification, independently of the producer
+func TestRecordBatch(t *testing.T) {
+	first := time.UnixMilli(1700000000000)
+	batch := recordBatch([]*Message{
+		{Key: []byte("k"), Value: []byte("v"), Time: first},
+		{Value: []byte("x"), Time: first.Add(300 * time.Millisecond)},
+	})
+	want, _ := hex.DecodeString("" +
+		"0000000000000000" + // Base offset
+		"00000043" + // Length
+		"ffffffff" + // Partition leader epoch
+		"02" + // Magic
+		"1348fb56" + // CRC-32C
+		"0000" + // Attributes
+		"00000001" + // Last offset delta
+		"0000018bcfe56800" + // First timestamp
+		"0000018bcfe5692c" + // Max timestamp
+		"ffffffffffffffff" + // Producer ID
+		"ffff" + // Producer epoch
+		"ffffffff" + // Base sequence
+		"00000002" + // Records
+		"10" + "00" + "00" + "00" + "026b" + "0276" + "00" + // Length, attributes, timestamp delta, offset delta, key, value, headers
+		"10" + "00" + "d804" + "02" + "01" + "0278" + "00")
+	if !bytes.Equal(batch, want) {
+		t.Errorf("record batch\n got %x\nwant %x", batch, want)
+	}
+}
+
+func TestDecoderTruncated(t *testing.T) {
+	d := decoder{buf: []byte{0, 0, 0, 5, 1}}
+	if n := d.arrayLen(); n != 0 || d.err != errShortResponse {
+		t.Errorf("arrayLen() = %d, %v, want 0, %v", n, d.err, errShortResponse)
+	}
+	d = decoder{buf: []byte{0, 3, 'a'}}
+	if s := d.string(); s != "" || d.err != errShortResponse {
+		t.Errorf("string() = %q, %v, want \"\", %v", s, d.err, errShortResponse)
+	}
+}
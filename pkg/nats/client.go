@@ -0,0 +1,427 @@
+// Package nats is a minimal NATS client: it publishes messages and consumes subscriptions, optionally in a
+// queue group, over the core NATS protocol with TLS and user/password or token authentication. Messages are
+// delivered at most once, JetStream is not supported.
+package nats
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/core-coin/nuntiare/pkg/discovery"
+)
+
+const (
+	// DefaultPort is used for servers listed without a port
+	DefaultPort = 4222
+	// DefaultTimeout is used when the config has no timeout
+	DefaultTimeout = 5 * time.Second
+	// PingInterval is how often the client pings the server. A connection without any traffic for two
+	// intervals is considered dead and replaced.
+	PingInterval = 30 * time.Second
+	// ReconnectDelay is the delay before connecting again after the connection was lost, doubled after
+	// every failed attempt up to MaxReconnectDelay
+	ReconnectDelay    = time.Second
+	MaxReconnectDelay = 30 * time.Second
+
+	// readBufferSize bounds the length of a protocol line, INFO messages list the servers of the cluster
+	readBufferSize = 64 * 1024
+)
+
+// ErrNotConnected is returned when publishing while the client has no connection
+var ErrNotConnected = errors.New("nats: not connected")
+
+// Config configures a Client
+type Config struct {
+	// Servers is a comma-separated list of servers (host, host:port or srv: record names)
+	Servers string
+	// Name identifies the connection in the server's monitoring
+	Name string
+	TLS  bool
+	// User and Password, or Token, authenticate the connection (optional)
+	User     string
+	Password string
+	Token    string
+	// Timeout bounds connecting and every write
+	Timeout time.Duration
+	// ErrorHandler is called with the errors that closed or prevented a connection (optional)
+	ErrorHandler func(err error)
+}
+
+// Msg is a message received on a subscription
+type Msg struct {
+	Subject string
+	// Reply is the subject the sender expects a response on (empty = no response)
+	Reply string
+	Data  []byte
+}
+
+// Handler handles the messages of a subscription
+type Handler func(msg *Msg)
+
+type subscription struct {
+	subject string
+	queue   string
+	handler Handler
+}
+
+// serverInfo is the part of the server's INFO message the client uses
+type serverInfo struct {
+	TLSRequired bool `json:"tls_required"`
+	MaxPayload  int  `json:"max_payload"`
+}
+
+// connectOptions is the CONNECT message. The server only acknowledges commands with +OK in verbose mode,
+// which the client doesn't use.
+type connectOptions struct {
+	Verbose     bool   `json:"verbose"`
+	Pedantic    bool   `json:"pedantic"`
+	TLSRequired bool   `json:"tls_required"`
+	Name        string `json:"name,omitempty"`
+	Lang        string `json:"lang"`
+	Version     string `json:"version"`
+	Protocol    int    `json:"protocol"`
+	User        string `json:"user,omitempty"`
+	Password    string `json:"pass,omitempty"`
+	Token       string `json:"auth_token,omitempty"`
+}
+
+// Client keeps a connection to a NATS cluster while Run is running. It is safe for concurrent use.
+type Client struct {
+	config Config
+	// subscriptions by subscription ID - 1, registered before Run
+	subscriptions []*subscription
+
+	mu         sync.Mutex
+	conn       net.Conn
+	maxPayload int
+}
+
+// NewClient returns a client for the cluster, it connects when Run is called
+func NewClient(config Config) *Client {
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	return &Client{config: config}
+}
+
+// Subscribe registers the handler of the messages published to the subject, it must be called before Run.
+// With a queue group every message is delivered to one member of the group only. Handlers run on the reader
+// of the connection one message at a time, so a slow handler delays the following messages.
+func (c *Client) Subscribe(subject, queue string, handler Handler) {
+	c.subscriptions = append(c.subscriptions, &subscription{subject: subject, queue: queue, handler: handler})
+}
+
+// Run connects to the cluster and keeps a connection until ctx is done, reconnecting with backoff when the
+// connection is lost. The subscriptions are renewed on every connection.
+func (c *Client) Run(ctx context.Context) {
+	delay := ReconnectDelay
+	for {
+		connected, err := c.serve(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if connected {
+			delay = ReconnectDelay
+		}
+		if c.config.ErrorHandler != nil {
+			c.config.ErrorHandler(err)
+		}
+		select {
+		case <-time.After(delay):
+			delay = min(delay*2, MaxReconnectDelay)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Publish sends a message to the subject without waiting for the server. Messages published while the
+// client is disconnected fail with ErrNotConnected.
+func (c *Client) Publish(subject string, data []byte) error {
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return fmt.Errorf("nats: invalid subject %q", subject)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return ErrNotConnected
+	}
+	if c.maxPayload > 0 && len(data) > c.maxPayload {
+		return fmt.Errorf("nats: message of %d bytes exceeds the maximum payload of %d bytes", len(data), c.maxPayload)
+	}
+	message := fmt.Appendf(nil, "PUB %s %d\r\n", subject, len(data))
+	message = append(message, data...)
+	message = append(message, "\r\n"...)
+	return c.write(c.conn, message)
+}
+
+// Respond publishes the response to a message's reply subject, messages without one are not answered
+func (c *Client) Respond(msg *Msg, data []byte) error {
+	if msg.Reply == "" {
+		return nil
+	}
+	return c.Publish(msg.Reply, data)
+}
+
+// write writes to the connection with c.mu held. A failed write closes the connection so the reader
+// reconnects.
+func (c *Client) write(conn net.Conn, data []byte) error {
+	if err := conn.SetWriteDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(data); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to write to NATS server: %w", err)
+	}
+	return nil
+}
+
+// serve connects and reads from the connection until it fails or ctx is done. Returns whether it connected.
+func (c *Client) serve(ctx context.Context) (bool, error) {
+	conn, reader, info, err := c.connect(ctx)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	c.conn = conn
+	c.maxPayload = info.MaxPayload
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.conn = nil
+		c.mu.Unlock()
+		conn.Close()
+	}()
+
+	// Closing the connection stops the reader when ctx is done
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+	done := make(chan struct{})
+	defer close(done)
+	go c.ping(conn, done)
+
+	return true, c.read(conn, reader)
+}
+
+// ping pings the server every PingInterval until done is closed, the PONGs keep the reader's deadline
+// from passing on an idle connection
+func (c *Client) ping(conn net.Conn, done chan struct{}) {
+	ticker := time.NewTicker(PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			c.write(conn, []byte("PING\r\n"))
+			c.mu.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
+
+// connect connects to the first reachable server, authenticates and subscribes
+func (c *Client) connect(ctx context.Context) (net.Conn, *bufio.Reader, *serverInfo, error) {
+	endpoints, err := discovery.ResolveHosts(ctx, c.config.Servers, DefaultPort)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var lastErr error
+	for _, endpoint := range endpoints {
+		conn, reader, info, err := c.dial(ctx, endpoint)
+		if err == nil {
+			return conn, reader, info, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = errors.New("nats: no servers configured")
+	}
+	return nil, nil, nil, lastErr
+}
+
+// dial performs the handshake with a server: the server sends INFO, the client upgrades to TLS if needed,
+// sends CONNECT and waits for the PONG of its PING, which confirms the server accepted the credentials
+func (c *Client) dial(ctx context.Context, addr string) (net.Conn, *bufio.Reader, *serverInfo, error) {
+	dialer := &net.Dialer{Timeout: c.config.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to connect to NATS server %s: %w", addr, err)
+	}
+	fail := func(err error) (net.Conn, *bufio.Reader, *serverInfo, error) {
+		conn.Close()
+		return nil, nil, nil, fmt.Errorf("failed to connect to NATS server %s: %w", addr, err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(c.config.Timeout)); err != nil {
+		return fail(err)
+	}
+
+	reader := bufio.NewReaderSize(conn, readBufferSize)
+	line, err := readLine(reader)
+	if err != nil {
+		return fail(err)
+	}
+	op, args, _ := strings.Cut(line, " ")
+	if !strings.EqualFold(op, "INFO") {
+		return fail(fmt.Errorf("nats: expected INFO, got %q", line))
+	}
+	var info serverInfo
+	if err := json.Unmarshal([]byte(args), &info); err != nil {
+		return fail(fmt.Errorf("nats: invalid INFO: %w", err))
+	}
+
+	if c.config.TLS || info.TLSRequired {
+		host, _, _ := net.SplitHostPort(addr)
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return fail(err)
+		}
+		conn = tlsConn
+		reader = bufio.NewReaderSize(conn, readBufferSize)
+	}
+
+	connect, err := json.Marshal(&connectOptions{
+		TLSRequired: c.config.TLS || info.TLSRequired,
+		Name:        c.config.Name,
+		Lang:        "go",
+		Version:     "1.0.0",
+		Protocol:    1,
+		User:        c.config.User,
+		Password:    c.config.Password,
+		Token:       c.config.Token,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	handshake := fmt.Appendf(nil, "CONNECT %s\r\nPING\r\n", connect)
+	if _, err := conn.Write(handshake); err != nil {
+		return fail(err)
+	}
+	for pong := false; !pong; {
+		line, err := readLine(reader)
+		if err != nil {
+			return fail(err)
+		}
+		op, args, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(op) {
+		case "PONG":
+			pong = true
+		case "-ERR":
+			return fail(fmt.Errorf("nats: %s", strings.Trim(args, "'")))
+		case "PING":
+			if _, err := conn.Write([]byte("PONG\r\n")); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	var subscribe []byte
+	for i, sub := range c.subscriptions {
+		if sub.queue == "" {
+			subscribe = fmt.Appendf(subscribe, "SUB %s %d\r\n", sub.subject, i+1)
+		} else {
+			subscribe = fmt.Appendf(subscribe, "SUB %s %s %d\r\n", sub.subject, sub.queue, i+1)
+		}
+	}
+	if len(subscribe) > 0 {
+		if _, err := conn.Write(subscribe); err != nil {
+			return fail(err)
+		}
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return fail(err)
+	}
+	return conn, reader, &info, nil
+}
+
+// read reads the messages of the connection and dispatches them to the subscriptions until the connection
+// fails. The deadline allows for one missed PONG.
+func (c *Client) read(conn net.Conn, reader *bufio.Reader) error {
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(2*PingInterval + c.config.Timeout)); err != nil {
+			return err
+		}
+		line, err := readLine(reader)
+		if err != nil {
+			return fmt.Errorf("failed to read from NATS server: %w", err)
+		}
+		op, args, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(op) {
+		case "MSG":
+			msg, sid, err := readMsg(reader, args)
+			if err != nil {
+				return err
+			}
+			if sid >= 1 && sid <= len(c.subscriptions) {
+				c.subscriptions[sid-1].handler(msg)
+			}
+		case "PING":
+			c.mu.Lock()
+			err := c.write(conn, []byte("PONG\r\n"))
+			c.mu.Unlock()
+			if err != nil {
+				return err
+			}
+		case "PONG", "+OK", "INFO":
+		case "-ERR":
+			// Permission violations reject a single publish or subscription, other errors close the connection
+			err := fmt.Errorf("nats: %s", strings.Trim(args, "'"))
+			if !strings.Contains(strings.ToLower(args), "permissions violation") {
+				return err
+			}
+			if c.config.ErrorHandler != nil {
+				c.config.ErrorHandler(err)
+			}
+		default:
+			return fmt.Errorf("nats: unexpected protocol message %q", line)
+		}
+	}
+}
+
+// readMsg reads the payload of a MSG with the arguments <subject> <sid> [reply] <size>
+func readMsg(reader *bufio.Reader, args string) (*Msg, int, error) {
+	fields := strings.Fields(args)
+	if len(fields) != 3 && len(fields) != 4 {
+		return nil, 0, fmt.Errorf("nats: invalid MSG arguments %q", args)
+	}
+	sid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return nil, 0, fmt.Errorf("nats: invalid MSG subscription ID %q", fields[1])
+	}
+	size, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil || size < 0 {
+		return nil, 0, fmt.Errorf("nats: invalid MSG size %q", fields[len(fields)-1])
+	}
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, 0, fmt.Errorf("failed to read from NATS server: %w", err)
+	}
+	if !bytes.HasSuffix(payload, []byte("\r\n")) {
+		return nil, 0, errors.New("nats: MSG payload not terminated")
+	}
+	msg := &Msg{Subject: fields[0], Data: payload[:size]}
+	if len(fields) == 4 {
+		msg.Reply = fields[2]
+	}
+	return msg, sid, nil
+}
+
+// readLine reads a protocol line without its CRLF. Lines longer than the reader's buffer are rejected.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadSlice('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
@@ -0,0 +1,274 @@
+package nats
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testServer accepts one connection at a time and lets the test script the protocol, the frames follow the
+// NATS client protocol documentation
+type testServer struct {
+	t        *testing.T
+	listener net.Listener
+	conns    chan *testConn
+}
+
+type testConn struct {
+	t      *testing.T
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func newTestServer(t *testing.T) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{t: t, listener: listener, conns: make(chan *testConn, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			// Reconnects the test doesn't wait for are refused
+			select {
+			case s.conns <- &testConn{t: t, conn: conn, reader: bufio.NewReader(conn)}:
+			default:
+				conn.Close()
+			}
+		}
+	}()
+	return s
+}
+
+func (s *testServer) accept() *testConn {
+	select {
+	case conn := <-s.conns:
+		s.t.Cleanup(func() { conn.conn.Close() })
+		return conn
+	case <-time.After(5 * time.Second):
+		s.t.Fatal("no connection")
+		return nil
+	}
+}
+
+func (c *testConn) send(frame string) {
+	if _, err := io.WriteString(c.conn, frame); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// expect reads a line and checks it
+func (c *testConn) expect(want string) string {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		c.t.Fatalf("reading %q: %v", want, err)
+	}
+	if !strings.HasSuffix(line, "\r\n") {
+		c.t.Fatalf("line %q not terminated with CRLF", line)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if !strings.HasPrefix(line, want) {
+		c.t.Fatalf("got %q, want %q", line, want)
+	}
+	return line
+}
+
+// handshake sends INFO, checks the CONNECT options and completes the handshake
+func (c *testConn) handshake(info string) *connectOptions {
+	c.send("INFO " + info + "\r\n")
+	line := c.expect("CONNECT ")
+	var options connectOptions
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "CONNECT ")), &options); err != nil {
+		c.t.Fatal(err)
+	}
+	c.expect("PING")
+	c.send("PONG\r\n")
+	return &options
+}
+
+func runClient(t *testing.T, config Config, subscribe func(c *Client)) (*Client, chan error) {
+	errs := make(chan error, 10)
+	config.ErrorHandler = func(err error) { errs <- err }
+	client := NewClient(config)
+	if subscribe != nil {
+		subscribe(client)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		client.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return client, errs
+}
+
+// waitConnected waits until the client finished the handshake
+func waitConnected(t *testing.T, client *Client) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		client.mu.Lock()
+		connected := client.conn != nil
+		client.mu.Unlock()
+		if connected {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatal("client didn't connect")
+}
+
+func TestSubscribeAndRespond(t *testing.T) {
+	server := newTestServer(t)
+	received := make(chan *Msg, 1)
+	client, _ := runClient(t, Config{Servers: server.listener.Addr().String(), Name: "nuntiare", User: "user", Password: "pass"}, func(c *Client) {
+		c.Subscribe("nuntiare.register", "workers", func(msg *Msg) { received <- msg })
+		c.Subscribe("nuntiare.ping", "", func(msg *Msg) {})
+	})
+
+	conn := server.accept()
+	options := conn.handshake(`{"server_id":"test","version":"2.10.0","proto":1,"max_payload":1048576}`)
+	if options.User != "user" || options.Password != "pass" || options.Name != "nuntiare" || options.Verbose || options.Protocol != 1 {
+		t.Errorf("CONNECT options = %+v", options)
+	}
+	conn.expect("SUB nuntiare.register workers 1")
+	conn.expect("SUB nuntiare.ping 2")
+
+	// A payload may contain CRLF, only the size delimits it
+	conn.send("MSG nuntiare.register 1 _INBOX.abc 7\r\nhi\r\nyou\r\n")
+	var msg *Msg
+	select {
+	case msg = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message not delivered")
+	}
+	if msg.Subject != "nuntiare.register" || msg.Reply != "_INBOX.abc" || string(msg.Data) != "hi\r\nyou" {
+		t.Errorf("message = %+v", msg)
+	}
+
+	if err := client.Respond(msg, []byte("ok")); err != nil {
+		t.Fatal(err)
+	}
+	conn.expect("PUB _INBOX.abc 2")
+	conn.expect("ok")
+
+	conn.send("PING\r\n")
+	conn.expect("PONG")
+}
+
+func TestPublish(t *testing.T) {
+	server := newTestServer(t)
+	client, _ := runClient(t, Config{Servers: server.listener.Addr().String(), Token: "secret"}, nil)
+	if err := client.Publish("nuntiare.transfers", []byte("{}")); !errors.Is(err, ErrNotConnected) {
+		t.Errorf("Publish() before connecting = %v, want %v", err, ErrNotConnected)
+	}
+
+	conn := server.accept()
+	if options := conn.handshake(`{"max_payload":8}`); options.Token != "secret" || options.User != "" {
+		t.Errorf("CONNECT options = %+v", options)
+	}
+	waitConnected(t, client)
+
+	if err := client.Publish("nuntiare.transfers", []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	conn.expect("PUB nuntiare.transfers 2")
+	conn.expect("{}")
+	if err := client.Publish("nuntiare.transfers", []byte("123456789")); err == nil {
+		t.Error("Publish() of a payload larger than max_payload succeeded")
+	}
+	if err := client.Publish("two words", nil); err == nil {
+		t.Error("Publish() to an invalid subject succeeded")
+	}
+}
+
+func TestAuthorizationViolation(t *testing.T) {
+	server := newTestServer(t)
+	_, errs := runClient(t, Config{Servers: server.listener.Addr().String(), User: "user", Password: "wrong"}, nil)
+
+	conn := server.accept()
+	conn.send("INFO {\"auth_required\":true}\r\n")
+	conn.expect("CONNECT ")
+	conn.expect("PING")
+	conn.send("-ERR 'Authorization Violation'\r\n")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "Authorization Violation") {
+			t.Errorf("error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("authorization error not reported")
+	}
+}
+
+func TestPermissionViolationKeepsConnection(t *testing.T) {
+	server := newTestServer(t)
+	client, errs := runClient(t, Config{Servers: server.listener.Addr().String()}, nil)
+
+	conn := server.accept()
+	conn.handshake(`{}`)
+	waitConnected(t, client)
+	conn.send("-ERR 'Permissions Violation for Publish to \"secret\"'\r\n")
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "Permissions Violation") {
+			t.Errorf("error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("permission error not reported")
+	}
+	// The connection is still used
+	if err := client.Publish("allowed", []byte("x")); err != nil {
+		t.Fatal(err)
+	}
+	conn.expect("PUB allowed 1")
+}
+
+func TestReadMsg(t *testing.T) {
+	tests := []struct {
+		args    string
+		payload string
+		msg     *Msg
+		sid     int
+		err     bool
+	}{
+		{args: "FOO.BAR 9 11", payload: "Hello World\r\n", msg: &Msg{Subject: "FOO.BAR", Data: []byte("Hello World")}, sid: 9},
+		{args: "FOO.BAR 9 GREETING.34 11", payload: "Hello World\r\n", msg: &Msg{Subject: "FOO.BAR", Reply: "GREETING.34", Data: []byte("Hello World")}, sid: 9},
+		{args: "FOO.BAR 9 0", payload: "\r\n", msg: &Msg{Subject: "FOO.BAR", Data: []byte{}}, sid: 9},
+		{args: "FOO.BAR 9 5", payload: "Hello World\r\n", err: true},
+		{args: "FOO.BAR 9 11", payload: "Hello", err: true},
+		{args: "FOO.BAR x 11", payload: "Hello World\r\n", err: true},
+		{args: "FOO.BAR 9 -1", payload: "\r\n", err: true},
+		{args: "FOO.BAR", payload: "", err: true},
+	}
+	for _, test := range tests {
+		msg, sid, err := readMsg(bufio.NewReader(strings.NewReader(test.payload)), test.args)
+		if test.err {
+			if err == nil {
+				t.Errorf("readMsg(%q) succeeded", test.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("readMsg(%q) = %v", test.args, err)
+			continue
+		}
+		if sid != test.sid || msg.Subject != test.msg.Subject || msg.Reply != test.msg.Reply || string(msg.Data) != string(test.msg.Data) {
+			t.Errorf("readMsg(%q) = %+v, %d, want %+v, %d", test.args, msg, sid, test.msg, test.sid)
+		}
+	}
+}
@@ -4,12 +4,33 @@ import (
 	"encoding/hex"
 	"fmt"
 	"strings"
+
+	"github.com/core-coin/go-core/v2/common"
+)
+
+// Machine-readable address validation error codes
+const (
+	CodeRequired        = "required"
+	CodeInvalidLength   = "invalid_length"
+	CodeInvalidHex      = "invalid_hex"
+	CodeInvalidChecksum = "invalid_checksum"
 )
 
-// ValidateAddress validates a blockchain address format
+// AddressError describes why an address failed validation
+type AddressError struct {
+	Code    string
+	Message string
+}
+
+func (e *AddressError) Error() string {
+	return e.Message
+}
+
+// ValidateAddress validates a blockchain address format and checksum.
+// Returned errors are of type *AddressError.
 func ValidateAddress(addr string) error {
 	if addr == "" {
-		return fmt.Errorf("address cannot be empty")
+		return &AddressError{Code: CodeRequired, Message: "address cannot be empty"}
 	}
 
 	// Remove 0x prefix if present
@@ -18,12 +39,21 @@ func ValidateAddress(addr string) error {
 
 	// Check length (44 hex characters = 22 bytes)
 	if len(normalized) != 44 {
-		return fmt.Errorf("invalid address length: expected 44 characters (without 0x), got %d", len(normalized))
+		return &AddressError{
+			Code:    CodeInvalidLength,
+			Message: fmt.Sprintf("invalid address length: expected 44 characters (without 0x), got %d", len(normalized)),
+		}
 	}
 
 	// Validate hex format
-	if _, err := hex.DecodeString(normalized); err != nil {
-		return fmt.Errorf("invalid hex address: %w", err)
+	raw, err := hex.DecodeString(normalized)
+	if err != nil {
+		return &AddressError{Code: CodeInvalidHex, Message: fmt.Sprintf("invalid hex address: %v", err)}
+	}
+
+	// Validate the checksum (second byte) against the network prefix and address body
+	if common.Bytes2Hex(raw[1:2]) != common.CalculateChecksum(raw[2:], raw[:1]) {
+		return &AddressError{Code: CodeInvalidChecksum, Message: "invalid address checksum"}
 	}
 
 	return nil
@@ -0,0 +1,60 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Compare compares two dotted numeric versions ("2.10.1" vs "2.9").
+// It returns -1 if a < b, 0 if equal and 1 if a > b. Missing components count as 0,
+// a leading "v" and pre-release/build suffixes ("-beta", "+42") are ignored.
+func Compare(a, b string) int {
+	pa, pb := parts(a), parts(b)
+	for i := 0; i < max(len(pa), len(pb)); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// IsValid reports whether v is a dotted numeric version
+func IsValid(v string) bool {
+	v = trim(v)
+	if v == "" {
+		return false
+	}
+	for _, part := range strings.Split(v, ".") {
+		if _, err := strconv.Atoi(part); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func trim(v string) string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+ "); i >= 0 {
+		v = v[:i]
+	}
+	return v
+}
+
+func parts(v string) []int {
+	var result []int
+	for _, part := range strings.Split(trim(v), ".") {
+		n, _ := strconv.Atoi(part)
+		result = append(result, n)
+	}
+	return result
+}